@@ -14,6 +14,12 @@ func ContextWithTrackingProps(ctx context.Context, props map[string]interface{})
 	return context.WithValue(ctx, trackingContextKey, props)
 }
 
+// Detach returns the context of background work outliving ctx: logs and events keep tracking props of ctx,
+// but the work isn't cancelled with ctx and its event props are collected separately
+func Detach(ctx context.Context) context.Context {
+	return ContextWithTrackingProps(context.Background(), getTrackingProps(ctx))
+}
+
 func getTrackingProps(ctx context.Context) map[string]interface{} {
 	tp := ctx.Value(trackingContextKey)
 	if tp == nil {
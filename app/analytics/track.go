@@ -12,6 +12,17 @@ type EventName string
 
 const EventPRChecked EventName = "PR checked"
 const EventRepoAnalyzed EventName = "Repo analyzed"
+const EventRepoHealthSnapshotted EventName = "Repo health snapshotted"
+const EventReleaseAnalyzed EventName = "Release analyzed"
+const EventIssueIgnored EventName = "Issue ignored"
+const EventIssueResolved EventName = "Issue resolved"
+const EventAnalysisCancelled EventName = "Analysis cancelled"
+const EventQueueStats EventName = "Queue stats"
+const EventExperimentExposure EventName = "Experiment exposure"
+const EventShadowRun EventName = "Shadow run"
+
+// systemUserID is used for events not related to any user, e.g. queue stats
+const systemUserID = "golangci-worker"
 
 type Tracker interface {
 	Track(ctx context.Context, event EventName)
@@ -21,7 +32,10 @@ type amplitudeMixpanelTracker struct{}
 
 func (t amplitudeMixpanelTracker) Track(ctx context.Context, eventName EventName) {
 	trackingProps := getTrackingProps(ctx)
-	userID := trackingProps["userIDString"].(string)
+	userID, _ := trackingProps["userIDString"].(string)
+	if userID == "" {
+		userID = systemUserID
+	}
 
 	eventProps := map[string]interface{}{}
 	for k, v := range trackingProps {
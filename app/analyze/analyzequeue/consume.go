@@ -1,18 +1,58 @@
 package analyzequeue
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync/atomic"
+	"time"
 
 	"github.com/golangci/golangci-shared/pkg/apperrors"
 	"github.com/golangci/golangci-shared/pkg/config"
 	"github.com/golangci/golangci-shared/pkg/logutil"
 	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/consumers"
+	"github.com/golangci/golangci-worker/app/analyze/cancellation"
+	"github.com/golangci/golangci-worker/app/analyze/health"
 	"github.com/golangci/golangci-worker/app/analyze/processors"
+	"github.com/golangci/golangci-worker/app/analyze/suppressions"
+	"github.com/golangci/golangci-worker/app/lib/buildinfo"
 	"github.com/golangci/golangci-worker/app/lib/experiments"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
 	"github.com/golangci/golangci-worker/app/lib/queue"
+	"github.com/golangci/golangci-worker/app/lib/selfupdate"
 )
 
-func RegisterTasks() {
+const (
+	taskAnalyzePR   = "analyzeV2"
+	taskAnalyzeRepo = "analyzeRepo"
+	taskAnalyzeCI   = "analyzeCI"
+
+	taskAnalyzeRelease = "analyzeRelease"
+
+	taskRepoHealthSnapshot = "repoHealthSnapshot"
+	taskIgnoreIssue        = "ignoreIssue"
+	taskCancelAnalysis     = "cancelAnalysis"
+)
+
+// TaskNames returns names of tasks the worker can consume
+func TaskNames() []string {
+	return []string{taskAnalyzePR, taskAnalyzeRepo, taskAnalyzeCI, taskAnalyzeRelease, taskRepoHealthSnapshot, taskIgnoreIssue,
+		taskCancelAnalysis}
+}
+
+type taskConsumers struct {
+	pr     *consumers.AnalyzePR
+	repo   *consumers.AnalyzeRepo
+	ci     *consumers.AnalyzeCI
+	rel    *consumers.AnalyzeRelease
+	health *consumers.RepoHealth
+	ignore *consumers.IgnoreIssue
+	cancel *consumers.CancelAnalysis
+	log    logutil.Log
+}
+
+func newTaskConsumers() *taskConsumers {
 	log := logutil.NewStderrLog("repo analysis")
 	log.SetLevel(logutil.LogLevelInfo)
 	cfg := config.NewEnvConfig(log)
@@ -22,22 +62,74 @@ func RegisterTasks() {
 	ec := experiments.NewChecker(cfg, trackedLog)
 
 	rpf := processors.NewRepoProcessorFactory(&processors.StaticRepoConfig{}, trackedLog)
-	repoAnalyzer := consumers.NewAnalyzeRepo(ec, rpf)
+	githubClient := github.NewCachingClient(github.NewMyClient(), github.DefaultCache())
+	return &taskConsumers{
+		pr:     consumers.NewAnalyzePR(),
+		repo:   consumers.NewAnalyzeRepo(ec, rpf),
+		ci:     consumers.NewAnalyzeCI(),
+		rel:    consumers.NewAnalyzeRelease(rpf, githubClient),
+		health: consumers.NewRepoHealth(rpf, health.NewAPIStorage(httputils.GrequestsClient{})),
+		ignore: consumers.NewIgnoreIssue(githubClient, suppressions.NewAPIStorage(httputils.GrequestsClient{})),
+		cancel: consumers.NewCancelAnalysis(cancellation.Default()),
+		log:    log,
+	}
+}
+
+func RegisterTasks() {
+	tc := newTaskConsumers()
 
 	server := queue.GetServer()
 	err := server.RegisterTasks(map[string]interface{}{
-		"analyzeV2":   consumers.NewAnalyzePR().Consume,
-		"analyzeRepo": repoAnalyzer.Consume,
+		taskAnalyzePR:   tc.pr.Consume,
+		taskAnalyzeRepo: tc.repo.Consume,
+		taskAnalyzeCI:   tc.ci.Consume,
+
+		taskAnalyzeRelease:     tc.rel.Consume,
+		taskRepoHealthSnapshot: tc.health.Consume,
+		taskIgnoreIssue:        tc.ignore.Consume,
+		taskCancelAnalysis:     tc.cancel.Consume,
 	})
 	if err != nil {
-		log.Fatalf("Can't register queue tasks: %s", err)
+		tc.log.Fatalf("Can't register queue tasks: %s", err)
 	}
 }
 
+// ErrWorkerOutdated is returned by RunWorker if the API requires a newer worker version:
+// the worker stopped consuming tasks after finishing running ones
+var ErrWorkerOutdated = errors.New("worker is outdated")
+
 func RunWorker() error {
+	log := logutil.NewStderrLog("self-update")
+	log.SetLevel(logutil.LogLevelInfo)
+	cfg := config.NewEnvConfig(log)
+	w := selfupdate.Watcher{
+		Fetcher:  selfupdate.NewAPIFetcher(httputils.GrequestsClient{}),
+		Current:  buildinfo.Version,
+		Interval: cfg.GetDuration("WORKER_VERSION_CHECK_INTERVAL", time.Minute),
+		Log:      log,
+	}
+	versionCheck := w.Interval > 0
+	if versionCheck && w.Check(context.Background()) {
+		return ErrWorkerOutdated // don't take tasks schema-incompatible results would be saved for
+	}
+
 	server := queue.GetServer()
 	worker := server.NewWorker("worker_name", 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var outdated int32
+	if versionCheck {
+		go w.Run(ctx, func() {
+			atomic.StoreInt32(&outdated, 1)
+			worker.Quit() // it waits for running tasks
+		})
+	}
+
 	err := worker.Launch()
+	if atomic.LoadInt32(&outdated) == 1 {
+		return ErrWorkerOutdated
+	}
 	if err != nil {
 		return fmt.Errorf("can't launch worker: %s", err)
 	}
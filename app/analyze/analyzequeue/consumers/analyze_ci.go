@@ -0,0 +1,90 @@
+package consumers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"github.com/golangci/golangci-worker/app/analyze/processors"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/pkg/errors"
+)
+
+var CIProcessorFactory = processors.NewGithubCIFactory()
+
+// AnalyzeCI consumes analyses requested by CI of customers: the patch is in the payload
+type AnalyzeCI struct {
+	baseConsumer
+}
+
+func NewAnalyzeCI() *AnalyzeCI {
+	return &AnalyzeCI{
+		baseConsumer: baseConsumer{
+			eventName:           analytics.EventPRChecked,
+			needSendToAnalytics: true,
+		},
+	}
+}
+
+func (c AnalyzeCI) Consume(ctx context.Context, repoOwner, repoName, githubAccessToken string,
+	pullRequestNumber int, commitSHA, branch, patch, analysisGUID string, optionalArgs ...interface{}) error {
+
+	t := &task.CIAnalysis{
+		Context: github.Context{
+			Repo: github.Repo{
+				Owner: repoOwner,
+				Name:  repoName,
+			},
+			GithubAccessToken: githubAccessToken,
+			PullRequestNumber: pullRequestNumber,
+		},
+		AnalysisGUID: analysisGUID,
+		CommitSHA:    commitSHA,
+		Branch:       branch,
+		Patch:        patch,
+	}
+
+	ctx = c.prepareContext(ctx, map[string]interface{}{
+		"repoName":     fmt.Sprintf("%s/%s", repoOwner, repoName),
+		"provider":     "github",
+		"source":       "ci",
+		"prNumber":     pullRequestNumber,
+		"analysisGUID": analysisGUID,
+	})
+	ctx, args := c.applyOptionalArgs(ctx, optionalArgs)
+	t.Features, t.Plan = args.Features, args.Plan
+
+	if err := c.checkOwner(ctx, repoOwner); err != nil {
+		return err
+	}
+
+	return c.wrapConsuming(ctx, func() error {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Minute)
+		defer cancel()
+
+		token, release, err := c.resolveToken(ctx, t.GithubAccessToken)
+		if err != nil {
+			return err
+		}
+		defer release()
+		t.GithubAccessToken = token
+
+		p, err := CIProcessorFactory.BuildProcessor(ctx, t)
+		if err != nil {
+			if berr, ok := err.(*errorutils.BadInputError); ok {
+				return errorutils.Permanent(err, berr.PublicDesc) // retries can't fix the payload
+			}
+			return fmt.Errorf("can't build processor for CI analysis %s: %s", analysisGUID, err)
+		}
+
+		if err = p.Process(ctx); err != nil {
+			return errors.Wrapf(err, "can't process CI analysis %s of %s/%s", analysisGUID, repoOwner, repoName)
+		}
+
+		return nil
+	})
+}
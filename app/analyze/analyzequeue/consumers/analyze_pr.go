@@ -8,8 +8,10 @@ import (
 
 	"github.com/golangci/golangci-worker/app/analytics"
 	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"github.com/golangci/golangci-worker/app/analyze/checkpoint"
 	"github.com/golangci/golangci-worker/app/analyze/processors"
 	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/pkg/errors"
 )
 
 var ProcessorFactory = processors.NewGithubFactory()
@@ -28,7 +30,7 @@ func NewAnalyzePR() *AnalyzePR {
 }
 
 func (c AnalyzePR) Consume(ctx context.Context, repoOwner, repoName, githubAccessToken string,
-	pullRequestNumber int, APIRequestID string, userID uint, analysisGUID string) error {
+	pullRequestNumber int, APIRequestID string, userID uint, analysisGUID string, optionalArgs ...interface{}) error {
 
 	t := &task.PRAnalysis{
 		Context: github.Context{
@@ -51,6 +53,20 @@ func (c AnalyzePR) Consume(ctx context.Context, repoOwner, repoName, githubAcces
 		"userIDString": strconv.Itoa(int(userID)),
 		"analysisGUID": analysisGUID,
 	})
+	ctx, args := c.applyOptionalArgs(ctx, optionalArgs)
+	t.Features, t.Plan, t.DeployKey, t.Path = args.Features, args.Plan, args.DeployKey, args.Path
+	t.WorkDirEncryption = args.WorkDirEncryption
+
+	if err := c.checkOwner(ctx, repoOwner); err != nil {
+		return err
+	}
+
+	ctx, finishCheckpoint, err := c.startCheckpoint(ctx, checkpoint.KindPR, analysisGUID, t)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Drop task of analysis %s: %s", analysisGUID, err)
+		return nil
+	}
+	defer finishCheckpoint()
 
 	return c.wrapConsuming(ctx, func() error {
 		var cancel context.CancelFunc
@@ -59,13 +75,20 @@ func (c AnalyzePR) Consume(ctx context.Context, repoOwner, repoName, githubAcces
 		ctx, cancel = context.WithTimeout(ctx, 10*time.Minute)
 		defer cancel()
 
+		token, release, err := c.resolveToken(ctx, t.GithubAccessToken)
+		if err != nil {
+			return err
+		}
+		defer release()
+		t.GithubAccessToken = token
+
 		p, err := ProcessorFactory.BuildProcessor(ctx, t)
 		if err != nil {
 			return fmt.Errorf("can't build processor for task %+v: %s", t, err)
 		}
 
 		if err = p.Process(ctx); err != nil {
-			return fmt.Errorf("can't process pr analysis of %+v: %s", t, err)
+			return errors.Wrapf(err, "can't process pr analysis of %+v", t)
 		}
 
 		return nil
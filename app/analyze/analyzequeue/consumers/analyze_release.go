@@ -0,0 +1,89 @@
+package consumers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/processors"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/pkg/errors"
+)
+
+// AnalyzeRelease analyzes pushed tags by the release profile and makes release readiness reports
+type AnalyzeRelease struct {
+	baseConsumer
+
+	rpf    *processors.RepoProcessorFactory
+	client github.Client
+}
+
+func NewAnalyzeRelease(rpf *processors.RepoProcessorFactory, client github.Client) *AnalyzeRelease {
+	return &AnalyzeRelease{
+		baseConsumer: baseConsumer{
+			eventName: analytics.EventReleaseAnalyzed,
+		},
+		rpf:    rpf,
+		client: client,
+	}
+}
+
+func (c AnalyzeRelease) Consume(ctx context.Context, repoOwner, repoName, githubAccessToken, tag, analysisGUID string,
+	optionalArgs ...interface{}) error {
+
+	ctx = c.prepareContext(ctx, map[string]interface{}{
+		"repoName":     fmt.Sprintf("%s/%s", repoOwner, repoName),
+		"provider":     "github",
+		"analysisGUID": analysisGUID,
+		"tag":          tag,
+	})
+	// the repo processor saves props of the repo analysis
+	ctx = analytics.ContextWithEventPropsCollector(ctx, analytics.EventRepoAnalyzed)
+	ctx, _ = c.applyOptionalArgs(ctx, optionalArgs)
+
+	if err := c.checkOwner(ctx, repoOwner); err != nil {
+		return err
+	}
+
+	return c.wrapConsuming(ctx, func() error {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 15*time.Minute) // govulncheck is slower than linters
+		defer cancel()
+
+		token, release, err := c.resolveToken(ctx, githubAccessToken)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		repo := &github.Repo{
+			Owner: repoOwner,
+			Name:  repoName,
+		}
+		repoCtx := &processors.RepoContext{
+			Ctx:          ctx,
+			AnalysisGUID: analysisGUID,
+			Branch:       tag,
+			Repo:         repo,
+		}
+		p, cleanup, err := c.rpf.BuildProcessor(repoCtx)
+		if err != nil {
+			return errors.Wrap(err, "failed to build repo processor")
+		}
+		defer cleanup()
+
+		rel := &processors.ReleaseContext{
+			Tag:    tag,
+			Client: c.client,
+		}
+		if token != "" {
+			rel.Github = &github.Context{
+				Repo:              *repo,
+				GithubAccessToken: token,
+			}
+		}
+		p.AnalyzeRelease(repoCtx, rel)
+		return nil
+	})
+}
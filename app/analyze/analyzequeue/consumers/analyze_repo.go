@@ -8,9 +8,13 @@ import (
 	"time"
 
 	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"github.com/golangci/golangci-worker/app/analyze/checkpoint"
 	"github.com/golangci/golangci-worker/app/analyze/processors"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
 	"github.com/golangci/golangci-worker/app/lib/experiments"
 	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/queue"
 	"github.com/pkg/errors"
 )
 
@@ -31,19 +35,40 @@ func NewAnalyzeRepo(ec *experiments.Checker, rpf *processors.RepoProcessorFactor
 	}
 }
 
-func (c AnalyzeRepo) Consume(ctx context.Context, repoName, analysisGUID, branch string) error {
+func (c AnalyzeRepo) Consume(ctx context.Context, repoName, analysisGUID, branch string, optionalArgs ...interface{}) error {
 	ctx = c.prepareContext(ctx, map[string]interface{}{
 		"repoName":     repoName,
 		"provider":     "github",
 		"analysisGUID": analysisGUID,
 		"branch":       branch,
 	})
+	ctx, args := c.applyOptionalArgs(ctx, optionalArgs)
 
 	if os.Getenv("DISABLE_REPO_ANALYSIS") == "1" {
 		analytics.Log(ctx).Warnf("Repo analysis is disabled, return error to try it later")
 		return errors.New("repo analysis is disabled")
 	}
 
+	if err := c.checkOwner(ctx, strings.Split(repoName, "/")[0]); err != nil {
+		return err
+	}
+
+	ctx, finishCheckpoint, err := c.startCheckpoint(ctx, checkpoint.KindRepo, analysisGUID, &task.RepoAnalysis{
+		Name:              repoName,
+		AnalysisGUID:      analysisGUID,
+		Branch:            branch,
+		Features:          args.Features,
+		Plan:              args.Plan,
+		DeployKey:         args.DeployKey,
+		Path:              args.Path,
+		WorkDirEncryption: args.WorkDirEncryption,
+	})
+	if err != nil {
+		analytics.Log(ctx).Warnf("Drop task of analysis %s: %s", analysisGUID, err)
+		return nil
+	}
+	defer finishCheckpoint()
+
 	return c.wrapConsuming(ctx, func() error {
 		var cancel context.CancelFunc
 		// If you change timeout value don't forget to change it
@@ -51,26 +76,31 @@ func (c AnalyzeRepo) Consume(ctx context.Context, repoName, analysisGUID, branch
 		ctx, cancel = context.WithTimeout(ctx, 10*time.Minute)
 		defer cancel()
 
-		return c.analyzeRepo(ctx, repoName, analysisGUID, branch)
+		return c.analyzeRepo(ctx, repoName, analysisGUID, branch, args)
 	})
 }
 
-func (c AnalyzeRepo) analyzeRepo(ctx context.Context, repoName, analysisGUID, branch string) error {
+func (c AnalyzeRepo) analyzeRepo(ctx context.Context, repoName, analysisGUID, branch string, args *queue.OptionalTaskArgs) error {
 	parts := strings.Split(repoName, "/")
 	repo := &github.Repo{
 		Owner: parts[0],
 		Name:  parts[1],
 	}
 	if len(parts) != 2 {
-		return fmt.Errorf("invalid repo name %s", repoName)
+		return errorutils.Permanent(fmt.Errorf("invalid repo name %s", repoName), "")
 	}
 
-	if c.ec.IsActiveForAnalysis("use_new_repo_analysis", repo, false) {
+	// only the new repo analysis supports deploy keys, paths and encrypted work dirs
+	if args.DeployKey != "" || args.Path != "" || args.WorkDirEncryption != "" ||
+		c.ec.IsActiveForAnalysis(ctx, "use_new_repo_analysis", repo, false) {
 		repoCtx := &processors.RepoContext{
-			Ctx:          ctx,
-			AnalysisGUID: analysisGUID,
-			Branch:       branch,
-			Repo:         repo,
+			Ctx:               ctx,
+			AnalysisGUID:      analysisGUID,
+			Branch:            branch,
+			Repo:              repo,
+			DeployKey:         args.DeployKey,
+			Path:              args.Path,
+			WorkDirEncryption: args.WorkDirEncryption,
 		}
 		p, cleanup, err := c.rpf.BuildProcessor(repoCtx)
 		if err != nil {
@@ -88,7 +118,7 @@ func (c AnalyzeRepo) analyzeRepo(ctx context.Context, repoName, analysisGUID, br
 	}
 
 	if err := p.Process(ctx); err != nil {
-		return fmt.Errorf("can't process repo analysis for %s and branch %s: %s", repoName, branch, err)
+		return errors.Wrapf(err, "can't process repo analysis for %s and branch %s", repoName, branch)
 	}
 
 	return nil
@@ -7,8 +7,35 @@ import (
 	"time"
 
 	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/checkpoint"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/experiments"
+	"github.com/golangci/golangci-worker/app/lib/queue"
+	"github.com/golangci/golangci-worker/app/lib/selfhosted"
+	"github.com/golangci/golangci-worker/app/lib/tokenvault"
+	"github.com/golangci/golangci-worker/app/lib/usage"
+	"github.com/pkg/errors"
 )
 
+// TokenResolver resolves token references of tasks
+var TokenResolver tokenvault.Resolver = tokenvault.NewDefaultVaultResolver()
+
+// Checkpoints stores checkpoints of running analyses, it's nil if checkpoints are disabled
+var Checkpoints = checkpoint.Default()
+
+type directCallCtxKey struct{}
+
+// ContextWithDirectCall marks tasks submitted bypassing the broker: errors of them are returned
+// to the caller even if the task isn't retried
+func ContextWithDirectCall(ctx context.Context) context.Context {
+	return context.WithValue(ctx, directCallCtxKey{}, true)
+}
+
+func isDirectCall(ctx context.Context) bool {
+	direct, _ := ctx.Value(directCallCtxKey{}).(bool)
+	return direct
+}
+
 type baseConsumer struct {
 	eventName           analytics.EventName
 	needSendToAnalytics bool
@@ -20,9 +47,74 @@ const statusFail = "fail"
 func (c baseConsumer) prepareContext(ctx context.Context, trackingProps map[string]interface{}) context.Context {
 	ctx = analytics.ContextWithEventPropsCollector(ctx, c.eventName)
 	ctx = analytics.ContextWithTrackingProps(ctx, trackingProps)
+	ctx = experiments.ContextWithEvaluations(ctx)
 	return ctx
 }
 
+// applyOptionalArgs puts trailing task args into the context
+func (c baseConsumer) applyOptionalArgs(ctx context.Context, optionalArgs []interface{}) (context.Context, *queue.OptionalTaskArgs) {
+	args, err := queue.ParseOptionalTaskArgs(optionalArgs)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Invalid optional args of %q task: %s", c.eventName, err)
+	}
+
+	ctx = queue.ContextWithEnqueuedAt(ctx, args.EnqueuedAt)
+	ctx = experiments.ContextWithOverrides(ctx, args.Features)
+	ctx = usage.ContextWithPlan(ctx, args.Plan)
+	return ctx, args
+}
+
+// resolveToken leases the token by the reference for the time of the task, the returned func releases
+// the lease. Raw tokens of old producers are returned as is.
+func (c baseConsumer) resolveToken(ctx context.Context, token string) (string, func(), error) {
+	if !tokenvault.IsRef(token) {
+		return token, func() {}, nil
+	}
+
+	lease, err := TokenResolver.Resolve(ctx, token)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "can't resolve github token") // vault may be unavailable: retry
+	}
+
+	release := func() {
+		// the task context may be already expired
+		if err := TokenResolver.Release(context.Background(), lease); err != nil {
+			analytics.Log(ctx).Warnf("Can't release the token lease: %s", err)
+		}
+	}
+	return lease.Token, release, nil
+}
+
+// startCheckpoint saves the checkpoint of the consumed analysis task, the returned func deletes it.
+// It returns checkpoint.ErrFailed for tasks of analyses failed after crashes of the worker.
+func (c baseConsumer) startCheckpoint(ctx context.Context, kind, analysisGUID string, t interface{}) (context.Context, func(), error) {
+	if Checkpoints == nil {
+		return ctx, func() {}, nil
+	}
+
+	tracker, err := checkpoint.Start(ctx, Checkpoints, kind, analysisGUID, t)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	return checkpoint.ContextWithTracker(ctx, tracker), func() {
+		tracker.Finish(ctx)
+	}, nil
+}
+
+// checkOwner returns an error for tasks of other organizations than the self-hosted worker's one.
+// It's called out of wrapConsuming: the error reaches the broker as is, so the task is requeued
+// by retries of the broker and never acked as processed.
+func (c baseConsumer) checkOwner(ctx context.Context, owner string) error {
+	if selfhosted.IsOwnerAllowed(owner) {
+		return nil
+	}
+
+	err := fmt.Errorf("task of %s isn't allowed for this self-hosted worker", owner)
+	analytics.Log(ctx).Warnf("Requeue %q task: %s", c.eventName, err)
+	return err
+}
+
 func (c baseConsumer) wrapConsuming(ctx context.Context, f func() error) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -46,6 +138,11 @@ func (c baseConsumer) wrapConsuming(ctx context.Context, f func() error) (err er
 		c.sendAnalytics(ctx, duration, err)
 	}
 
+	if err != nil && !errorutils.ShouldRetry(err) && !isDirectCall(ctx) {
+		analytics.Log(ctx).Warnf("Don't retry %q task: error kind is %s", c.eventName, errorutils.KindOf(err))
+		return nil
+	}
+
 	return err
 }
 
@@ -58,6 +155,8 @@ func (c baseConsumer) sendAnalytics(ctx context.Context, duration time.Duration,
 	} else {
 		props["status"] = statusFail
 		props["error"] = err.Error()
+		props["errorClass"] = string(errorutils.Classify(err))
+		props["errorKind"] = string(errorutils.KindOf(err))
 	}
 	analytics.SaveEventProps(ctx, c.eventName, props)
 
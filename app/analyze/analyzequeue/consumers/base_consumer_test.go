@@ -0,0 +1,57 @@
+package consumers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/tokenvault"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeResolver struct {
+	released []string
+}
+
+func (r *fakeResolver) Resolve(ctx context.Context, ref string) (*tokenvault.Lease, error) {
+	return &tokenvault.Lease{Token: "leased-" + ref, ID: "lease-" + ref}, nil
+}
+
+func (r *fakeResolver) Release(ctx context.Context, lease *tokenvault.Lease) error {
+	r.released = append(r.released, lease.ID)
+	return nil
+}
+
+func TestResolveToken(t *testing.T) {
+	r := &fakeResolver{}
+	prev := TokenResolver
+	TokenResolver = r
+	defer func() { TokenResolver = prev }()
+
+	c := baseConsumer{}
+	token, release, err := c.resolveToken(context.Background(), "raw")
+	assert.NoError(t, err)
+	assert.Equal(t, "raw", token)
+	release()
+	assert.Empty(t, r.released, "raw tokens aren't leased")
+
+	ref := tokenvault.Ref("github/42")
+	token, release, err = c.resolveToken(context.Background(), ref)
+	assert.NoError(t, err)
+	assert.Equal(t, "leased-"+ref, token)
+	release()
+	assert.Equal(t, []string{"lease-" + ref}, r.released)
+}
+
+func TestWrapConsumingNonRetryableError(t *testing.T) {
+	c := baseConsumer{}
+	failure := errorutils.UserConfig(errors.New("no go files"), "no go files")
+	fail := func() error { return failure }
+
+	assert.NoError(t, c.wrapConsuming(context.Background(), fail), "the broker mustn't retry it")
+	assert.Equal(t, failure, c.wrapConsuming(ContextWithDirectCall(context.Background()), fail))
+
+	transient := errors.New("network is down")
+	assert.Equal(t, transient, c.wrapConsuming(context.Background(), func() error { return transient }))
+}
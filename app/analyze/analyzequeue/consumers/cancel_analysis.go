@@ -0,0 +1,78 @@
+package consumers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"github.com/golangci/golangci-worker/app/analyze/cancellation"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/github"
+)
+
+// CancelAnalysis marks the analysis of a closed or merged pull request cancelled: the worker running it
+// aborts the analysis
+type CancelAnalysis struct {
+	baseConsumer
+
+	registry cancellation.Registry
+}
+
+func NewCancelAnalysis(registry cancellation.Registry) *CancelAnalysis {
+	return &CancelAnalysis{
+		baseConsumer: baseConsumer{
+			eventName: analytics.EventAnalysisCancelled,
+		},
+		registry: registry,
+	}
+}
+
+func (c CancelAnalysis) Consume(ctx context.Context, repoOwner, repoName string, pullRequestNumber int,
+	analysisGUID, reason string, optionalArgs ...interface{}) error {
+
+	t := &task.AnalysisCancellation{
+		Repo: github.Repo{
+			Owner: repoOwner,
+			Name:  repoName,
+		},
+		PullRequestNumber: pullRequestNumber,
+		AnalysisGUID:      analysisGUID,
+		Reason:            reason,
+	}
+
+	ctx = c.prepareContext(ctx, map[string]interface{}{
+		"repoName":     t.Repo.FullName(),
+		"provider":     "github",
+		"prNumber":     pullRequestNumber,
+		"analysisGUID": analysisGUID,
+	})
+	ctx, _ = c.applyOptionalArgs(ctx, optionalArgs)
+
+	return c.wrapConsuming(ctx, func() error {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+
+		return cancelAnalysis(ctx, c.registry, t)
+	})
+}
+
+func cancelAnalysis(ctx context.Context, registry cancellation.Registry, t *task.AnalysisCancellation) error {
+	reason := cancellation.Reason(t.Reason)
+	if reason != cancellation.ReasonClosed && reason != cancellation.ReasonMerged {
+		return errorutils.Permanent(fmt.Errorf("invalid reason %q of the cancellation", t.Reason), "")
+	}
+	if t.AnalysisGUID == "" {
+		return errorutils.Permanent(fmt.Errorf("no analysis guid of %s#%d to cancel", t.Repo.FullName(), t.PullRequestNumber), "")
+	}
+
+	if err := registry.Cancel(ctx, t.AnalysisGUID, reason); err != nil {
+		return err // the registry may be unavailable: retry
+	}
+
+	analytics.Log(ctx).Infof("Cancelled analysis %s of %s#%d: pull request was %s",
+		t.AnalysisGUID, t.Repo.FullName(), t.PullRequestNumber, reason)
+	return nil
+}
@@ -0,0 +1,33 @@
+package consumers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"github.com/golangci/golangci-worker/app/analyze/cancellation"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancelAnalysis(t *testing.T) {
+	ctx := context.Background()
+	r := cancellation.NewMemoryRegistry()
+	ct := &task.AnalysisCancellation{
+		Repo:              github.Repo{Owner: "golangci", Name: "worker"},
+		PullRequestNumber: 3,
+		AnalysisGUID:      "guid",
+		Reason:            "merged",
+	}
+
+	assert.NoError(t, cancelAnalysis(ctx, r, ct))
+	reason, err := r.Reason(ctx, "guid")
+	assert.NoError(t, err)
+	assert.Equal(t, cancellation.ReasonMerged, reason)
+
+	ct.Reason = "reopened"
+	err = cancelAnalysis(ctx, r, ct)
+	assert.Error(t, err)
+	assert.False(t, errorutils.ShouldRetry(err))
+}
@@ -0,0 +1,78 @@
+package consumers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"github.com/golangci/golangci-worker/app/analyze/processors"
+	"github.com/golangci/golangci-worker/app/analyze/suppressions"
+	"github.com/golangci/golangci-worker/app/lib/github"
+)
+
+// IgnoreIssue processes replies to review comments: maintainers ignore issues by "/golangci ignore"
+type IgnoreIssue struct {
+	baseConsumer
+
+	client  github.Client
+	storage suppressions.Storage
+}
+
+func NewIgnoreIssue(client github.Client, storage suppressions.Storage) *IgnoreIssue {
+	return &IgnoreIssue{
+		baseConsumer: baseConsumer{
+			eventName: analytics.EventIssueIgnored,
+		},
+		client:  client,
+		storage: storage,
+	}
+}
+
+func (c IgnoreIssue) Consume(ctx context.Context, repoOwner, repoName, githubAccessToken string,
+	pullRequestNumber int, commentID, inReplyToID int64, author, authorAssociation, body string,
+	optionalArgs ...interface{}) error {
+
+	t := &task.IssueIgnore{
+		Context: github.Context{
+			Repo: github.Repo{
+				Owner: repoOwner,
+				Name:  repoName,
+			},
+			GithubAccessToken: githubAccessToken,
+			PullRequestNumber: pullRequestNumber,
+		},
+		CommentID:         commentID,
+		InReplyToID:       inReplyToID,
+		Author:            author,
+		AuthorAssociation: authorAssociation,
+		Body:              body,
+	}
+
+	ctx = c.prepareContext(ctx, map[string]interface{}{
+		"repoName": fmt.Sprintf("%s/%s", repoOwner, repoName),
+		"provider": "github",
+		"prNumber": pullRequestNumber,
+	})
+	ctx, _ = c.applyOptionalArgs(ctx, optionalArgs)
+
+	if err := c.checkOwner(ctx, repoOwner); err != nil {
+		return err
+	}
+
+	return c.wrapConsuming(ctx, func() error {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+
+		token, release, err := c.resolveToken(ctx, t.GithubAccessToken)
+		if err != nil {
+			return err
+		}
+		defer release()
+		t.GithubAccessToken = token
+
+		return processors.RecordSuppression(ctx, c.client, c.storage, t)
+	})
+}
@@ -0,0 +1,84 @@
+package consumers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/health"
+	"github.com/golangci/golangci-worker/app/analyze/processors"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/pkg/errors"
+)
+
+// RepoHealth makes weekly health snapshots of repos
+type RepoHealth struct {
+	baseConsumer
+
+	rpf     *processors.RepoProcessorFactory
+	storage health.Storage
+}
+
+func NewRepoHealth(rpf *processors.RepoProcessorFactory, storage health.Storage) *RepoHealth {
+	return &RepoHealth{
+		baseConsumer: baseConsumer{
+			eventName: analytics.EventRepoHealthSnapshotted,
+		},
+		rpf:     rpf,
+		storage: storage,
+	}
+}
+
+func (c RepoHealth) Consume(ctx context.Context, repoName, branch string, optionalArgs ...interface{}) error {
+	ctx = c.prepareContext(ctx, map[string]interface{}{
+		"repoName": repoName,
+		"provider": "github",
+		"branch":   branch,
+	})
+	// the repo processor saves props of the repo analysis
+	ctx = analytics.ContextWithEventPropsCollector(ctx, analytics.EventRepoAnalyzed)
+	ctx, _ = c.applyOptionalArgs(ctx, optionalArgs)
+
+	if err := c.checkOwner(ctx, strings.Split(repoName, "/")[0]); err != nil {
+		return err
+	}
+
+	return c.wrapConsuming(ctx, func() error {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Minute) // the same as for repo analyses
+		defer cancel()
+
+		return c.snapshot(ctx, repoName, branch)
+	})
+}
+
+func (c RepoHealth) snapshot(ctx context.Context, repoName, branch string) error {
+	parts := strings.Split(repoName, "/")
+	if len(parts) != 2 {
+		return errorutils.Permanent(fmt.Errorf("invalid repo name %s", repoName), "")
+	}
+	repo := &github.Repo{
+		Owner: parts[0],
+		Name:  parts[1],
+	}
+
+	repoCtx := &processors.RepoContext{
+		Ctx:    ctx,
+		Branch: branch,
+		Repo:   repo,
+	}
+	p, cleanup, err := c.rpf.BuildProcessor(repoCtx)
+	if err != nil {
+		return errors.Wrap(err, "failed to build repo processor")
+	}
+	defer cleanup()
+
+	if err = p.SnapshotHealth(repoCtx, c.storage); err != nil {
+		return errors.Wrapf(err, "can't make health snapshot of %s and branch %s", repoName, branch)
+	}
+
+	return nil
+}
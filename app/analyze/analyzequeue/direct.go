@@ -0,0 +1,65 @@
+package analyzequeue
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-shared/pkg/config"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/consumers"
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"github.com/golangci/golangci-worker/app/analyze/submitapi"
+	"github.com/golangci/golangci-worker/app/lib/queue"
+)
+
+const defaultMaxDirectAnalyses = 2
+
+// DirectDispatcher runs submitted tasks by the same consumers as queued ones but without the broker
+type DirectDispatcher struct {
+	tc *taskConsumers
+
+	// sem bounds concurrency of direct analyses: unlike queued ones they aren't limited by workers of the broker
+	sem chan struct{}
+}
+
+var _ submitapi.Handler = &DirectDispatcher{}
+
+func NewDirectDispatcher() *DirectDispatcher {
+	n := config.NewEnvConfig(logutil.NewStderrLog("config")).GetInt("SUBMIT_API_MAX_CONCURRENCY", defaultMaxDirectAnalyses)
+	if n < 1 {
+		n = 1
+	}
+
+	return &DirectDispatcher{tc: newTaskConsumers(), sem: make(chan struct{}, n)}
+}
+
+// acquire waits for a free slot of analysis, the returned func releases it
+func (d DirectDispatcher) acquire(ctx context.Context) (func(), error) {
+	select {
+	case d.sem <- struct{}{}:
+		return func() { <-d.sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (d DirectDispatcher) AnalyzePR(ctx context.Context, t *task.PRAnalysis) error {
+	release, err := d.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return d.tc.pr.Consume(consumers.ContextWithDirectCall(ctx), t.Repo.Owner, t.Repo.Name, t.GithubAccessToken, t.PullRequestNumber,
+		t.APIRequestID, t.UserID, t.AnalysisGUID, queue.OptionalTaskArgsNow(t.Features, t.Plan, t.DeployKey, t.Path, t.WorkDirEncryption)...)
+}
+
+func (d DirectDispatcher) AnalyzeRepo(ctx context.Context, t *task.RepoAnalysis) error {
+	release, err := d.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return d.tc.repo.Consume(consumers.ContextWithDirectCall(ctx), t.Name, t.AnalysisGUID, t.Branch,
+		queue.OptionalTaskArgsNow(t.Features, t.Plan, t.DeployKey, t.Path, t.WorkDirEncryption)...)
+}
@@ -0,0 +1,27 @@
+package analyzequeue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectDispatcherAcquire(t *testing.T) {
+	d := DirectDispatcher{sem: make(chan struct{}, 1)}
+
+	release, err := d.acquire(context.Background())
+	assert.NoError(t, err)
+
+	// no free slot: the caller waits until its deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = d.acquire(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	release()
+	release, err = d.acquire(context.Background())
+	assert.NoError(t, err)
+	release()
+}
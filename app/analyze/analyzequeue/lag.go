@@ -0,0 +1,79 @@
+package analyzequeue
+
+import (
+	"context"
+	"time"
+
+	"github.com/golangci/golangci-shared/pkg/config"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/metrics"
+	"github.com/golangci/golangci-worker/app/lib/queue"
+)
+
+const (
+	queueDepthGauge     = "golangci_worker_queue_depth"
+	queueOldestAgeGauge = "golangci_worker_queue_oldest_age_seconds"
+	queueDelayedGauge   = "golangci_worker_queue_delayed"
+)
+
+// consumedQueues are all queues the worker consumes tasks from
+var consumedQueues = []string{queue.DefaultQueueName}
+
+type lagExporter struct {
+	interval time.Duration
+	log      logutil.Log
+}
+
+// RunLagExporter periodically measures backlog of every consumed queue
+// and exposes it in metrics and analytics to drive autoscaling.
+func RunLagExporter(ctx context.Context) {
+	log := logutil.NewStderrLog("queue lag")
+	log.SetLevel(logutil.LogLevelInfo)
+	cfg := config.NewEnvConfig(log)
+
+	e := lagExporter{
+		interval: cfg.GetDuration("QUEUE_STATS_INTERVAL", 30*time.Second),
+		log:      log,
+	}
+	go e.run(ctx)
+}
+
+func (e lagExporter) run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		for _, q := range consumedQueues {
+			e.export(ctx, q)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e lagExporter) export(ctx context.Context, queueName string) {
+	stats, err := queue.GetStats(queueName)
+	if err != nil {
+		e.log.Warnf("Failed to get stats of queue %s: %s", queueName, err)
+		return
+	}
+
+	labels := metrics.Labels{"queue": queueName}
+	metrics.SetGauge(queueDepthGauge, labels, float64(stats.Depth))
+	metrics.SetGauge(queueOldestAgeGauge, labels, stats.OldestAge.Seconds())
+	metrics.SetGauge(queueDelayedGauge, labels, float64(stats.Delayed))
+
+	ctx = analytics.ContextWithEventPropsCollector(ctx, analytics.EventQueueStats)
+	analytics.SaveEventProps(ctx, analytics.EventQueueStats, map[string]interface{}{
+		"queue":            queueName,
+		"depth":            stats.Depth,
+		"oldestAgeSeconds": int(stats.OldestAge / time.Second),
+		"delayed":          stats.Delayed,
+	})
+	analytics.GetTracker(ctx).Track(ctx, analytics.EventQueueStats)
+}
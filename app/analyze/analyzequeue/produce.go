@@ -2,6 +2,8 @@ package analyzequeue
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/RichardKnop/machinery/v1/tasks"
 	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
@@ -39,9 +41,12 @@ func SchedulePRAnalysis(t *task.PRAnalysis) error {
 			Value: t.AnalysisGUID,
 		},
 	}
+	// enqueue time, features, plan, deploy key and path: trailing args are optional for consumers
+	args = append(args, queue.BuildOptionalTaskArgs(t.Features, t.Plan, t.DeployKey, t.Path, t.WorkDirEncryption)...)
 	signature := &tasks.Signature{
-		Name:         "analyzeV2",
+		Name:         taskAnalyzePR,
 		Args:         args,
+		Headers:      buildHeaders(t.Repo.Owner),
 		RetryCount:   3,
 		RetryTimeout: 600, // 600 sec
 	}
@@ -69,9 +74,11 @@ func ScheduleRepoAnalysis(t *task.RepoAnalysis) error {
 			Value: t.Branch,
 		},
 	}
+	args = append(args, queue.BuildOptionalTaskArgs(t.Features, t.Plan, t.DeployKey, t.Path, t.WorkDirEncryption)...)
 	signature := &tasks.Signature{
-		Name:         "analyzeRepo",
+		Name:         taskAnalyzeRepo,
 		Args:         args,
+		Headers:      buildHeaders(repoOrg(t.Name)),
 		RetryCount:   3,
 		RetryTimeout: 600, // 600 sec
 	}
@@ -83,3 +90,232 @@ func ScheduleRepoAnalysis(t *task.RepoAnalysis) error {
 
 	return nil
 }
+
+func ScheduleRepoHealthSnapshot(t *task.RepoHealthSnapshot) error {
+	args := []tasks.Arg{
+		{
+			Type:  "string",
+			Value: t.Name,
+		},
+		{
+			Type:  "string",
+			Value: t.Branch,
+		},
+	}
+	args = append(args, queue.BuildOptionalTaskArgs(t.Features, t.Plan, "", "", "")...)
+	signature := &tasks.Signature{
+		Name:         taskRepoHealthSnapshot,
+		Args:         args,
+		Headers:      buildHeaders(repoOrg(t.Name)),
+		RetryCount:   3,
+		RetryTimeout: 600, // 600 sec
+	}
+
+	_, err := queue.GetServer().SendTask(signature)
+	if err != nil {
+		return fmt.Errorf("failed to send the repo health snapshot task of %s to analyze queue: %s", t.Name, err)
+	}
+
+	return nil
+}
+
+func ScheduleReleaseAnalysis(t *task.ReleaseAnalysis) error {
+	args := []tasks.Arg{
+		{
+			Type:  "string",
+			Value: t.Repo.Owner,
+		},
+		{
+			Type:  "string",
+			Value: t.Repo.Name,
+		},
+		{
+			Type:  "string",
+			Value: t.GithubAccessToken,
+		},
+		{
+			Type:  "string",
+			Value: t.Tag,
+		},
+		{
+			Type:  "string",
+			Value: t.AnalysisGUID,
+		},
+	}
+	args = append(args, queue.BuildOptionalTaskArgs(t.Features, t.Plan, "", "", "")...)
+	signature := &tasks.Signature{
+		Name:         taskAnalyzeRelease,
+		Args:         args,
+		Headers:      buildHeaders(t.Repo.Owner),
+		RetryCount:   3,
+		RetryTimeout: 600, // 600 sec
+	}
+
+	_, err := queue.GetServer().SendTask(signature)
+	if err != nil {
+		return fmt.Errorf("failed to send the release analysis task of tag %s to analyze queue: %s", t.Tag, err)
+	}
+
+	return nil
+}
+
+func ScheduleIssueIgnore(t *task.IssueIgnore) error {
+	args := []tasks.Arg{
+		{
+			Type:  "string",
+			Value: t.Repo.Owner,
+		},
+		{
+			Type:  "string",
+			Value: t.Repo.Name,
+		},
+		{
+			Type:  "string",
+			Value: t.GithubAccessToken,
+		},
+		{
+			Type:  "int",
+			Value: t.PullRequestNumber,
+		},
+		{
+			Type:  "int64",
+			Value: t.CommentID,
+		},
+		{
+			Type:  "int64",
+			Value: t.InReplyToID,
+		},
+		{
+			Type:  "string",
+			Value: t.Author,
+		},
+		{
+			Type:  "string",
+			Value: t.AuthorAssociation,
+		},
+		{
+			Type:  "string",
+			Value: t.Body,
+		},
+	}
+	args = append(args, queue.BuildOptionalTaskArgs(nil, nil, "", "", "")...)
+	signature := &tasks.Signature{
+		Name:         taskIgnoreIssue,
+		Args:         args,
+		Headers:      buildHeaders(t.Repo.Owner),
+		RetryCount:   3,
+		RetryTimeout: 60, // 60 sec
+	}
+
+	_, err := queue.GetServer().SendTask(signature)
+	if err != nil {
+		return fmt.Errorf("failed to send the issue ignore task of comment %d to analyze queue: %s", t.CommentID, err)
+	}
+
+	return nil
+}
+
+func ScheduleAnalysisCancellation(t *task.AnalysisCancellation) error {
+	args := []tasks.Arg{
+		{
+			Type:  "string",
+			Value: t.Repo.Owner,
+		},
+		{
+			Type:  "string",
+			Value: t.Repo.Name,
+		},
+		{
+			Type:  "int",
+			Value: t.PullRequestNumber,
+		},
+		{
+			Type:  "string",
+			Value: t.AnalysisGUID,
+		},
+		{
+			Type:  "string",
+			Value: t.Reason,
+		},
+	}
+	args = append(args, queue.BuildOptionalTaskArgs(nil, nil, "", "", "")...)
+	signature := &tasks.Signature{
+		Name:         taskCancelAnalysis,
+		Args:         args,
+		Headers:      buildHeaders(t.Repo.Owner),
+		RetryCount:   3,
+		RetryTimeout: 10, // 10 sec: the analysis is running
+	}
+
+	_, err := queue.GetServer().SendTask(signature)
+	if err != nil {
+		return fmt.Errorf("failed to send the cancellation task of analysis %s to analyze queue: %s", t.AnalysisGUID, err)
+	}
+
+	return nil
+}
+
+func ScheduleCIAnalysis(t *task.CIAnalysis) error {
+	args := []tasks.Arg{
+		{
+			Type:  "string",
+			Value: t.Repo.Owner,
+		},
+		{
+			Type:  "string",
+			Value: t.Repo.Name,
+		},
+		{
+			Type:  "string",
+			Value: t.GithubAccessToken,
+		},
+		{
+			Type:  "int",
+			Value: t.PullRequestNumber,
+		},
+		{
+			Type:  "string",
+			Value: t.CommitSHA,
+		},
+		{
+			Type:  "string",
+			Value: t.Branch,
+		},
+		{
+			Type:  "string",
+			Value: t.Patch,
+		},
+		{
+			Type:  "string",
+			Value: t.AnalysisGUID,
+		},
+	}
+	args = append(args, queue.BuildOptionalTaskArgs(t.Features, t.Plan, "", "", "")...)
+	signature := &tasks.Signature{
+		Name:         taskAnalyzeCI,
+		Args:         args,
+		Headers:      buildHeaders(t.Repo.Owner),
+		RetryCount:   3,
+		RetryTimeout: 600, // 600 sec
+	}
+
+	_, err := queue.GetServer().SendTask(signature)
+	if err != nil {
+		return fmt.Errorf("failed to send the CI analysis task of %s to analyze queue: %s", t.AnalysisGUID, err)
+	}
+
+	return nil
+}
+
+// buildHeaders returns headers of a task of the organization: the consumer interleaves tasks of organizations
+func buildHeaders(org string) tasks.Headers {
+	return tasks.Headers{
+		queue.EnqueuedAtHeader: time.Now().Unix(),
+		queue.OrgHeader:        org,
+	}
+}
+
+// repoOrg returns the owner of the repo by its full name (owner/name)
+func repoOrg(fullName string) string {
+	return strings.SplitN(fullName, "/", 2)[0]
+}
@@ -0,0 +1,196 @@
+package analyzequeue
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/consumers"
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"github.com/golangci/golangci-worker/app/analyze/checkpoint"
+	"github.com/golangci/golangci-worker/app/analyze/prstate"
+	"github.com/golangci/golangci-worker/app/analyze/repostate"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/golangci/golangci-worker/app/lib/queue"
+	"github.com/pkg/errors"
+)
+
+// maxRecoveries limits recoveries of an analysis: the analysis itself can crash the worker
+const maxRecoveries = 2
+
+// recoveredCheckpointTTL is the time to keep checkpoints of recovered analyses waiting in the queue
+const recoveredCheckpointTTL = 24 * time.Hour
+
+type recovery struct {
+	store      checkpoint.Store
+	redelivers bool
+
+	schedulePR   func(t *task.PRAnalysis) error
+	scheduleRepo func(t *task.RepoAnalysis) error
+
+	prState   prstate.Storage
+	repoState repostate.Storage
+	log       logutil.Log
+}
+
+// RecoverInterrupted handles analyses interrupted by a crash of the previous run of the worker:
+// they are found by checkpoints and re-enqueued, analyses crashing the worker again are failed.
+// It must be called before RunWorker.
+func RecoverInterrupted(ctx context.Context) {
+	if consumers.Checkpoints == nil {
+		return
+	}
+
+	log := logutil.NewStderrLog("recovery")
+	log.SetLevel(logutil.LogLevelInfo)
+	r := recovery{
+		store:        consumers.Checkpoints,
+		redelivers:   queue.RedeliversInterrupted(),
+		schedulePR:   SchedulePRAnalysis,
+		scheduleRepo: ScheduleRepoAnalysis,
+		prState:      prstate.NewAPIStorage(httputils.GrequestsClient{}),
+		repoState:    repostate.NewAPIStorage(httputils.GrequestsClient{}),
+		log:          log,
+	}
+	r.run(ctx)
+}
+
+func (r recovery) run(ctx context.Context) {
+	cps, err := r.store.List()
+	if err != nil {
+		r.log.Warnf("Can't list checkpoints: %s", err)
+		return
+	}
+
+	for _, cp := range cps {
+		r.log.Infof("Analysis %s (%s) was interrupted at stage %q", cp.AnalysisGUID, cp.Kind, cp.Stage)
+		removeLeftoverWorkDir(r.log, cp.WorkDir)
+
+		if err := r.recover(ctx, cp); err != nil {
+			r.log.Warnf("Can't recover analysis %s: %s", cp.AnalysisGUID, err)
+			continue // try it after the next restart
+		}
+	}
+}
+
+func (r recovery) recover(ctx context.Context, cp *checkpoint.Checkpoint) error {
+	if cp.Requeued || cp.Failed {
+		// the task wasn't consumed again yet, it could be consumed by another worker
+		if time.Since(cp.UpdatedAt) > recoveredCheckpointTTL {
+			return r.store.Delete(cp.AnalysisGUID)
+		}
+		return nil
+	}
+
+	if cp.Recoveries >= maxRecoveries {
+		if err := r.fail(ctx, cp); err != nil {
+			return err
+		}
+		if !r.redelivers {
+			return r.store.Delete(cp.AnalysisGUID)
+		}
+
+		cp.Failed = true // the consumer drops the redelivered task
+		cp.UpdatedAt = time.Now()
+		return r.store.Save(cp)
+	}
+
+	// the checkpoint is saved before enqueueing: a crash between them must not enqueue the analysis twice
+	cp.Recoveries++
+	cp.Requeued = true
+	cp.UpdatedAt = time.Now()
+	if err := r.store.Save(cp); err != nil {
+		return errors.Wrap(err, "can't save checkpoint")
+	}
+	if r.redelivers {
+		return nil // the task wasn't deleted from the queue
+	}
+
+	if err := r.reenqueue(cp); err != nil {
+		cp.Recoveries--
+		cp.Requeued = false
+		if saveErr := r.store.Save(cp); saveErr != nil {
+			r.log.Warnf("Can't revert checkpoint of analysis %s: %s", cp.AnalysisGUID, saveErr)
+		}
+		return err
+	}
+
+	r.log.Infof("Re-enqueued analysis %s (recovery %d/%d)", cp.AnalysisGUID, cp.Recoveries, maxRecoveries)
+	return nil
+}
+
+func (r recovery) reenqueue(cp *checkpoint.Checkpoint) error {
+	switch cp.Kind {
+	case checkpoint.KindPR:
+		var t task.PRAnalysis
+		if err := json.Unmarshal(cp.Task, &t); err != nil {
+			return errors.Wrap(err, "invalid pr analysis task")
+		}
+		return r.schedulePR(&t)
+	case checkpoint.KindRepo:
+		var t task.RepoAnalysis
+		if err := json.Unmarshal(cp.Task, &t); err != nil {
+			return errors.Wrap(err, "invalid repo analysis task")
+		}
+		return r.scheduleRepo(&t)
+	default:
+		return errors.Errorf("unknown kind of analysis %q", cp.Kind)
+	}
+}
+
+// fail sets the final error status of the analysis, otherwise it's pending forever
+func (r recovery) fail(ctx context.Context, cp *checkpoint.Checkpoint) error {
+	errClass := string(errorutils.ClassWorkerBug)
+	switch cp.Kind {
+	case checkpoint.KindPR:
+		var t task.PRAnalysis
+		if err := json.Unmarshal(cp.Task, &t); err != nil {
+			return errors.Wrap(err, "invalid pr analysis task")
+		}
+		s := &prstate.State{
+			Status:     "processed/" + string(github.StatusError),
+			ErrorClass: errClass,
+		}
+		return r.prState.UpdateState(ctx, t.Repo.Owner, t.Repo.Name, cp.AnalysisGUID, s)
+	case checkpoint.KindRepo:
+		var t task.RepoAnalysis
+		if err := json.Unmarshal(cp.Task, &t); err != nil {
+			return errors.Wrap(err, "invalid repo analysis task")
+		}
+		parts := strings.SplitN(t.Name, "/", 2)
+		if len(parts) != 2 {
+			return errors.Errorf("invalid repo name %q", t.Name)
+		}
+		s := &repostate.State{
+			Status:     string(github.StatusError),
+			ErrorClass: errClass,
+		}
+		return r.repoState.UpdateState(ctx, parts[0], parts[1], cp.AnalysisGUID, s)
+	default:
+		return errors.Errorf("unknown kind of analysis %q", cp.Kind)
+	}
+}
+
+// removeLeftoverWorkDir removes only local temp dirs of the worker: workspaces of remote shells
+// and containers are on other hosts
+func removeLeftoverWorkDir(log logutil.Log, wd string) {
+	if wd == "" || !strings.HasPrefix(filepath.Base(wd), "golangci.") {
+		return
+	}
+
+	if _, err := os.Stat(wd); err != nil {
+		return
+	}
+
+	if err := os.RemoveAll(wd); err != nil {
+		log.Warnf("Can't remove leftover workspace %s: %s", wd, err)
+		return
+	}
+	log.Infof("Removed leftover workspace %s", wd)
+}
@@ -0,0 +1,146 @@
+package analyzequeue
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"github.com/golangci/golangci-worker/app/analyze/checkpoint"
+	"github.com/golangci/golangci-worker/app/analyze/prstate"
+	"github.com/golangci/golangci-worker/app/analyze/repostate"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRecovery struct {
+	recovery
+	store     *checkpoint.FileStore
+	scheduled []string
+	tmpDir    string
+}
+
+func newTestRecovery(t *testing.T, ctrl *gomock.Controller) *testRecovery {
+	dir, err := ioutil.TempDir("", "recovery")
+	assert.NoError(t, err)
+
+	log := logutil.NewStderrLog("test")
+	tr := &testRecovery{
+		store:  checkpoint.NewFileStore(filepath.Join(dir, "cps")),
+		tmpDir: dir,
+	}
+	tr.recovery = recovery{
+		store: tr.store,
+		schedulePR: func(t *task.PRAnalysis) error {
+			tr.scheduled = append(tr.scheduled, t.AnalysisGUID)
+			return nil
+		},
+		scheduleRepo: func(t *task.RepoAnalysis) error {
+			tr.scheduled = append(tr.scheduled, t.AnalysisGUID)
+			return nil
+		},
+		prState:   prstate.NewMockStorage(ctrl),
+		repoState: repostate.NewMockStorage(ctrl),
+		log:       log,
+	}
+	return tr
+}
+
+func (tr *testRecovery) save(t *testing.T, cp *checkpoint.Checkpoint, tsk interface{}) {
+	var err error
+	cp.Task, err = json.Marshal(tsk)
+	assert.NoError(t, err)
+	cp.UpdatedAt = time.Now()
+	assert.NoError(t, tr.store.Save(cp))
+}
+
+func prTask(guid string) *task.PRAnalysis {
+	return &task.PRAnalysis{
+		Context:      github.Context{Repo: github.Repo{Owner: "owner", Name: "name"}, PullRequestNumber: 1},
+		AnalysisGUID: guid,
+	}
+}
+
+func TestRecoveryReenqueuesInterruptedAnalyses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tr := newTestRecovery(t, ctrl)
+	defer os.RemoveAll(tr.tmpDir)
+
+	wd := filepath.Join(tr.tmpDir, "golangci.123")
+	assert.NoError(t, os.Mkdir(wd, 0700))
+
+	tr.save(t, &checkpoint.Checkpoint{AnalysisGUID: "pr", Kind: checkpoint.KindPR, Stage: "lint", WorkDir: wd}, prTask("pr"))
+	tr.save(t, &checkpoint.Checkpoint{AnalysisGUID: "repo", Kind: checkpoint.KindRepo},
+		&task.RepoAnalysis{Name: "owner/name", AnalysisGUID: "repo"})
+	tr.run(context.Background())
+
+	assert.ElementsMatch(t, []string{"pr", "repo"}, tr.scheduled)
+	_, err := os.Stat(wd)
+	assert.True(t, os.IsNotExist(err))
+
+	cp, err := tr.store.Load("pr")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cp.Recoveries)
+	assert.True(t, cp.Requeued)
+
+	// a restart before consuming the task doesn't enqueue it again
+	tr.scheduled = nil
+	tr.run(context.Background())
+	assert.Empty(t, tr.scheduled)
+}
+
+func TestRecoveryFailsAnalysesCrashingTooOften(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tr := newTestRecovery(t, ctrl)
+	defer os.RemoveAll(tr.tmpDir)
+
+	tr.save(t, &checkpoint.Checkpoint{AnalysisGUID: "pr", Kind: checkpoint.KindPR, Recoveries: maxRecoveries}, prTask("pr"))
+	tr.save(t, &checkpoint.Checkpoint{AnalysisGUID: "repo", Kind: checkpoint.KindRepo, Recoveries: maxRecoveries},
+		&task.RepoAnalysis{Name: "owner/name", AnalysisGUID: "repo"})
+
+	tr.prState.(*prstate.MockStorage).EXPECT().UpdateState(gomock.Any(), "owner", "name", "pr", &prstate.State{
+		Status:     "processed/error",
+		ErrorClass: "worker_bug",
+	}).Return(nil)
+	tr.repoState.(*repostate.MockStorage).EXPECT().UpdateState(gomock.Any(), "owner", "name", "repo", &repostate.State{
+		Status:     "error",
+		ErrorClass: "worker_bug",
+	}).Return(nil)
+	tr.run(context.Background())
+
+	assert.Empty(t, tr.scheduled)
+	cps, err := tr.store.List()
+	assert.NoError(t, err)
+	assert.Empty(t, cps)
+}
+
+func TestRecoveryWithRedeliveringQueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tr := newTestRecovery(t, ctrl)
+	defer os.RemoveAll(tr.tmpDir)
+	tr.redelivers = true
+
+	tr.save(t, &checkpoint.Checkpoint{AnalysisGUID: "pr", Kind: checkpoint.KindPR}, prTask("pr"))
+	tr.save(t, &checkpoint.Checkpoint{AnalysisGUID: "crashing", Kind: checkpoint.KindPR, Recoveries: maxRecoveries}, prTask("crashing"))
+	tr.prState.(*prstate.MockStorage).EXPECT().UpdateState(gomock.Any(), "owner", "name", "crashing", gomock.Any()).Return(nil)
+	tr.run(context.Background())
+
+	assert.Empty(t, tr.scheduled)
+
+	// the redelivered task of the failed analysis is dropped by the consumer
+	_, err := checkpoint.Start(context.Background(), tr.store, checkpoint.KindPR, "crashing", prTask("crashing"))
+	assert.Equal(t, checkpoint.ErrFailed, err)
+
+	tracker, err := checkpoint.Start(context.Background(), tr.store, checkpoint.KindPR, "pr", prTask("pr"))
+	assert.NoError(t, err)
+	tracker.Finish(context.Background())
+}
@@ -1,16 +1,94 @@
 package task
 
-import "github.com/golangci/golangci-worker/app/lib/github"
+import (
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/usage"
+)
 
 type PRAnalysis struct {
 	github.Context
 	APIRequestID string
 	UserID       uint
 	AnalysisGUID string
+
+	// Features override experiments for the analysis, e.g. to debug an analysis of a customer
+	Features map[string]bool `json:",omitempty"`
+
+	// Plan is the compute budget of the organization, it's nil if the organization isn't limited
+	Plan *usage.Plan `json:",omitempty"`
 }
 
 type RepoAnalysis struct {
 	Name         string
 	AnalysisGUID string
 	Branch       string
+
+	Features map[string]bool `json:",omitempty"`
+	Plan     *usage.Plan     `json:",omitempty"`
+
+	// DeployKey is a sealed SSH key to clone the private repo by, see github.Context
+	DeployKey string `json:",omitempty"`
+
+	// Path restricts the analysis to a subdirectory, see github.Context
+	Path string `json:",omitempty"`
+
+	// WorkDirEncryption is a mode of the encrypted work dir, see github.Context
+	WorkDirEncryption string `json:",omitempty"`
+}
+
+// RepoHealthSnapshot is scheduled weekly by the API: the full repo is analyzed and its health snapshot is saved
+type RepoHealthSnapshot struct {
+	Name   string
+	Branch string
+
+	Features map[string]bool `json:",omitempty"`
+	Plan     *usage.Plan     `json:",omitempty"`
+}
+
+// ReleaseAnalysis is sent by the API for pushed tags: the tag is analyzed by the stricter release profile,
+// the token is used only to post the release readiness report
+type ReleaseAnalysis struct {
+	github.Context
+	AnalysisGUID string
+	Tag          string
+
+	Features map[string]bool `json:",omitempty"`
+	Plan     *usage.Plan     `json:",omitempty"`
+}
+
+// CIAnalysis is requested by CI of a customer: the diff is generated by CI, it's not fetched from GitHub
+type CIAnalysis struct {
+	github.Context // PullRequestNumber is zero for builds of pushes
+	AnalysisGUID   string
+
+	CommitSHA string
+	Branch    string // it's cloned, its head must be CommitSHA
+	Patch     string
+
+	// Private is the privacy of the repo by the API: builds of pushes have no pull request to get it from GitHub
+	Private bool `json:",omitempty"`
+
+	Features map[string]bool `json:",omitempty"`
+	Plan     *usage.Plan     `json:",omitempty"`
+}
+
+// AnalysisCancellation is sent by the API for closed and merged pull requests: the running analysis
+// of the pull request is aborted
+type AnalysisCancellation struct {
+	Repo              github.Repo
+	PullRequestNumber int
+	AnalysisGUID      string
+	Reason            string // closed or merged
+}
+
+// IssueIgnore is sent by the API for replies to review comments: "/golangci ignore" of a maintainer
+// suppresses the issue of the replied comment in next analyses
+type IssueIgnore struct {
+	github.Context
+	CommentID   int64 // the reply
+	InReplyToID int64 // the review comment of the issue
+
+	Author            string
+	AuthorAssociation string // OWNER, MEMBER and COLLABORATOR are maintainers
+	Body              string
 }
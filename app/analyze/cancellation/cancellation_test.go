@@ -0,0 +1,62 @@
+package cancellation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRegistry(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRegistry()
+
+	reason, err := r.Reason(ctx, "guid")
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+
+	require.NoError(t, r.Cancel(ctx, "guid", ReasonMerged))
+	reason, err = r.Reason(ctx, "guid")
+	require.NoError(t, err)
+	assert.Equal(t, ReasonMerged, reason)
+}
+
+func TestWatchCancels(t *testing.T) {
+	r := NewMemoryRegistry()
+	ctx, w := Watch(context.Background(), r, "guid", time.Millisecond)
+	defer w.Stop()
+	assert.Empty(t, w.Reason())
+
+	require.NoError(t, r.Cancel(context.Background(), "guid", ReasonClosed))
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context wasn't cancelled")
+	}
+	assert.Equal(t, ReasonClosed, w.Reason())
+}
+
+func TestWatchCancelledBeforeStart(t *testing.T) {
+	r := NewMemoryRegistry()
+	require.NoError(t, r.Cancel(context.Background(), "guid", ReasonMerged))
+
+	ctx, w := Watch(context.Background(), r, "guid", time.Hour)
+	defer w.Stop()
+	assert.Error(t, ctx.Err())
+	assert.Equal(t, ReasonMerged, w.Reason())
+}
+
+func TestWatchStop(t *testing.T) {
+	r := NewMemoryRegistry()
+	require.NoError(t, r.Cancel(context.Background(), "other", ReasonClosed))
+
+	ctx, w := Watch(context.Background(), r, "guid", time.Millisecond)
+	w.Stop()
+	<-ctx.Done() // the context is released by Stop
+	assert.Empty(t, w.Reason())
+
+	var nilWatcher *Watcher
+	assert.Empty(t, nilWatcher.Reason())
+}
@@ -0,0 +1,133 @@
+// Package cancellation cancels running analyses of closed and merged pull requests: the API enqueues
+// a cancelAnalysis task, its consumer marks the analysis cancelled in the registry shared by workers and
+// the worker running the analysis observes it by Watch.
+package cancellation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/pkg/errors"
+)
+
+// Reason of the cancellation, it's empty for analyses which aren't cancelled
+type Reason string
+
+const (
+	ReasonClosed Reason = "closed"
+	ReasonMerged Reason = "merged"
+)
+
+// ttl of cancellations is longer than any analysis can run
+const ttl = 24 * time.Hour
+
+// Registry keeps cancelled analyses by their GUIDs
+type Registry interface {
+	Cancel(ctx context.Context, analysisGUID string, reason Reason) error
+
+	// Reason returns an empty reason if the analysis isn't cancelled
+	Reason(ctx context.Context, analysisGUID string) (Reason, error)
+}
+
+// RedisRegistry is shared by worker instances
+type RedisRegistry struct {
+	pool *redis.Pool
+}
+
+func NewRedisRegistry(redisURL string) *RedisRegistry {
+	return &RedisRegistry{
+		pool: &redis.Pool{
+			MaxIdle:     2,
+			IdleTimeout: 4 * time.Minute,
+			Dial: func() (redis.Conn, error) {
+				return redis.DialURL(redisURL)
+			},
+		},
+	}
+}
+
+func redisKey(analysisGUID string) string {
+	return fmt.Sprintf("cancelled_analysis:%s", analysisGUID)
+}
+
+func (r RedisRegistry) Cancel(ctx context.Context, analysisGUID string, reason Reason) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	if _, err := conn.Do("SET", redisKey(analysisGUID), string(reason), "EX", int(ttl/time.Second)); err != nil {
+		return errors.Wrapf(err, "can't cancel analysis %s", analysisGUID)
+	}
+	return nil
+}
+
+func (r RedisRegistry) Reason(ctx context.Context, analysisGUID string) (Reason, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	reason, err := redis.String(conn.Do("GET", redisKey(analysisGUID)))
+	if err == redis.ErrNil {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "can't get cancellation of analysis %s", analysisGUID)
+	}
+
+	return Reason(reason), nil
+}
+
+// MemoryRegistry is used by single-process setups without Redis, e.g. the direct-ingest mode
+type MemoryRegistry struct {
+	mu        sync.Mutex
+	cancelled map[string]Reason
+}
+
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{cancelled: map[string]Reason{}}
+}
+
+func (r *MemoryRegistry) Cancel(ctx context.Context, analysisGUID string, reason Reason) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cancelled[analysisGUID] = reason
+	return nil
+}
+
+func (r *MemoryRegistry) Reason(ctx context.Context, analysisGUID string) (Reason, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.cancelled[analysisGUID], nil
+}
+
+// NopRegistry never cancels analyses
+type NopRegistry struct{}
+
+func (NopRegistry) Cancel(ctx context.Context, analysisGUID string, reason Reason) error {
+	return nil
+}
+
+func (NopRegistry) Reason(ctx context.Context, analysisGUID string) (Reason, error) {
+	return "", nil
+}
+
+var defaultRegistry Registry
+var defaultRegistryOnce sync.Once
+
+// Default returns the Redis registry if REDIS_URL is set and the in-memory one otherwise
+func Default() Registry {
+	defaultRegistryOnce.Do(func() {
+		if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+			defaultRegistry = NewRedisRegistry(redisURL)
+			return
+		}
+
+		defaultRegistry = NewMemoryRegistry()
+	})
+
+	return defaultRegistry
+}
@@ -0,0 +1,94 @@
+package cancellation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+)
+
+// Watcher polls the registry while the analysis runs
+type Watcher struct {
+	mu     sync.Mutex
+	reason Reason
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Watch returns ctx which is cancelled when the analysis is cancelled in the registry: commands
+// of the analysis are killed and the caller checks Reason to finish the analysis accordingly.
+// The first check is done before returning: analyses cancelled in the queue don't start.
+// Errors of the registry are logged: the analysis continues then.
+func Watch(ctx context.Context, r Registry, analysisGUID string, interval time.Duration) (context.Context, *Watcher) {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &Watcher{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	if w.check(ctx, r, analysisGUID) {
+		cancel()
+		close(w.done)
+		return ctx, w
+	}
+
+	go func() {
+		defer close(w.done)
+		defer cancel()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-w.stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+
+			if w.check(ctx, r, analysisGUID) {
+				return
+			}
+		}
+	}()
+
+	return ctx, w
+}
+
+// check returns true if the analysis is cancelled
+func (w *Watcher) check(ctx context.Context, r Registry, analysisGUID string) bool {
+	reason, err := r.Reason(ctx, analysisGUID)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't check cancellation of the analysis: %s", err)
+		return false
+	}
+	if reason == "" {
+		return false
+	}
+
+	analytics.Log(ctx).Infof("Analysis was cancelled: pull request was %s", reason)
+	w.mu.Lock()
+	w.reason = reason
+	w.mu.Unlock()
+	return true
+}
+
+// Reason is empty until the analysis is cancelled, it's safe to call on nil
+func (w *Watcher) Reason() Reason {
+	if w == nil {
+		return ""
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.reason
+}
+
+// Stop stops polling and cancels the context of Watch
+func (w *Watcher) Stop() {
+	close(w.stop)
+	<-w.done
+}
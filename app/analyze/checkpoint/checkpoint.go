@@ -0,0 +1,141 @@
+// Package checkpoint persists minimal state of running analyses: after a crash of the worker
+// interrupted analyses are found by their checkpoints and re-enqueued or failed by the recovery,
+// their statuses aren't left pending forever.
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/pkg/errors"
+)
+
+const (
+	KindPR   = "pr"
+	KindRepo = "repo"
+)
+
+// Checkpoint of a running analysis, it's deleted when the analysis is finished
+type Checkpoint struct {
+	AnalysisGUID string
+	Kind         string
+
+	// Task is the task as it was consumed: tokens are references or raw tokens like producers sent them
+	Task json.RawMessage
+
+	// Stage is the last started stage of the analysis pipeline
+	Stage string `json:",omitempty"`
+
+	// WorkDir is the workspace of the analysis, it isn't reused after crashes: its state is unknown.
+	// Leftover local temp dirs are removed by the recovery.
+	WorkDir string `json:",omitempty"`
+
+	StartedAt time.Time
+	UpdatedAt time.Time
+
+	// Recoveries is a count of recoveries of the analysis after crashes
+	Recoveries int `json:",omitempty"`
+
+	// Requeued is set by the recovery: the analysis waits in the queue, it isn't running
+	Requeued bool `json:",omitempty"`
+
+	// Failed is set by the recovery after too many crashes: the redelivered task must be dropped
+	Failed bool `json:",omitempty"`
+}
+
+// ErrFailed is returned by Start for analyses failed by the recovery
+var ErrFailed = errors.New("analysis was failed after crashes of the worker")
+
+// Tracker updates the checkpoint of the analysis, errors of the store are only logged:
+// checkpoints never fail analyses
+type Tracker struct {
+	store Store
+
+	mu sync.Mutex
+	cp Checkpoint
+}
+
+// Start saves the checkpoint of the consumed task, recoveries of the previous checkpoint of the analysis are kept
+func Start(ctx context.Context, store Store, kind, analysisGUID string, task interface{}) (*Tracker, error) {
+	t := &Tracker{store: store}
+	if store == nil {
+		return t, nil
+	}
+
+	taskJSON, err := json.Marshal(task)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't marshal task for checkpoint: %s", err)
+		return &Tracker{}, nil
+	}
+
+	now := time.Now()
+	t.cp = Checkpoint{
+		AnalysisGUID: analysisGUID,
+		Kind:         kind,
+		Task:         taskJSON,
+		StartedAt:    now,
+		UpdatedAt:    now,
+	}
+	if prev, err := store.Load(analysisGUID); err == nil && prev != nil {
+		if prev.Failed {
+			t.cp = *prev
+			t.Finish(ctx)
+			return nil, ErrFailed
+		}
+		t.cp.Recoveries = prev.Recoveries
+	}
+
+	t.save(ctx)
+	return t, nil
+}
+
+func (t *Tracker) save(ctx context.Context) {
+	if err := t.store.Save(&t.cp); err != nil {
+		analytics.Log(ctx).Warnf("Can't save checkpoint of analysis %s: %s", t.cp.AnalysisGUID, err)
+	}
+}
+
+// Reached records the started stage of the analysis and its workspace, empty workDir keeps the recorded one
+func (t *Tracker) Reached(ctx context.Context, stage, workDir string) {
+	if t == nil || t.store == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.cp.Stage = stage
+	if workDir != "" {
+		t.cp.WorkDir = workDir
+	}
+	t.cp.UpdatedAt = time.Now()
+	t.save(ctx)
+}
+
+// Finish deletes the checkpoint: the analysis was finished, failed analyses are retried by the queue
+func (t *Tracker) Finish(ctx context.Context) {
+	if t == nil || t.store == nil {
+		return
+	}
+
+	if err := t.store.Delete(t.cp.AnalysisGUID); err != nil {
+		analytics.Log(ctx).Warnf("Can't delete checkpoint of analysis %s: %s", t.cp.AnalysisGUID, err)
+	}
+}
+
+type trackerKeyType string
+
+const trackerKey trackerKeyType = "checkpoint tracker"
+
+func ContextWithTracker(ctx context.Context, t *Tracker) context.Context {
+	return context.WithValue(ctx, trackerKey, t)
+}
+
+// FromContext returns the tracker of the analysis, it's nil (and no-op) if the analysis isn't checkpointed
+func FromContext(ctx context.Context) *Tracker {
+	t, _ := ctx.Value(trackerKey).(*Tracker)
+	return t
+}
@@ -0,0 +1,101 @@
+package checkpoint
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStore(t *testing.T) (*FileStore, func()) {
+	dir, err := ioutil.TempDir("", "checkpoints")
+	assert.NoError(t, err)
+	return NewFileStore(filepath.Join(dir, "cps")), func() { os.RemoveAll(dir) }
+}
+
+type testTask struct {
+	Repo string
+}
+
+func TestTrackerLifecycle(t *testing.T) {
+	s, cleanup := newTestStore(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	tr, err := Start(ctx, s, KindPR, "guid-1", testTask{Repo: "a/b"})
+	assert.NoError(t, err)
+	tr.Reached(ctx, "lint", "/tmp/golangci.1")
+	tr.Reached(ctx, "report", "")
+
+	cp, err := s.Load("guid-1")
+	assert.NoError(t, err)
+	assert.Equal(t, KindPR, cp.Kind)
+	assert.Equal(t, "report", cp.Stage)
+	assert.Equal(t, "/tmp/golangci.1", cp.WorkDir)
+	assert.JSONEq(t, `{"Repo":"a/b"}`, string(cp.Task))
+
+	fi, err := os.Stat(filepath.Join(s.dir, "guid-1.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	tr.Finish(ctx)
+	cps, err := s.List()
+	assert.NoError(t, err)
+	assert.Empty(t, cps)
+}
+
+func TestStartKeepsRecoveries(t *testing.T) {
+	s, cleanup := newTestStore(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	assert.NoError(t, s.Save(&Checkpoint{AnalysisGUID: "guid-1", Kind: KindRepo, Recoveries: 1, Requeued: true}))
+
+	_, err := Start(ctx, s, KindRepo, "guid-1", testTask{})
+	assert.NoError(t, err)
+
+	cp, err := s.Load("guid-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cp.Recoveries)
+	assert.False(t, cp.Requeued)
+}
+
+func TestStartDropsFailedAnalyses(t *testing.T) {
+	s, cleanup := newTestStore(t)
+	defer cleanup()
+
+	assert.NoError(t, s.Save(&Checkpoint{AnalysisGUID: "guid-1", Kind: KindPR, Failed: true}))
+
+	tr, err := Start(context.Background(), s, KindPR, "guid-1", testTask{})
+	assert.Equal(t, ErrFailed, err)
+	assert.Nil(t, tr)
+
+	cp, err := s.Load("guid-1")
+	assert.NoError(t, err)
+	assert.Nil(t, cp)
+}
+
+func TestNilTrackerIsNop(t *testing.T) {
+	ctx := context.Background()
+	tr := FromContext(ctx)
+	assert.Nil(t, tr)
+	tr.Reached(ctx, "lint", "")
+	tr.Finish(ctx)
+}
+
+func TestFileStoreSkipsInvalidFiles(t *testing.T) {
+	s, cleanup := newTestStore(t)
+	defer cleanup()
+
+	assert.NoError(t, s.Save(&Checkpoint{AnalysisGUID: "guid-1"}))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(s.dir, "broken.json"), []byte("{"), 0600))
+
+	cps, err := s.List()
+	assert.NoError(t, err)
+	assert.Len(t, cps, 1)
+
+	assert.Error(t, s.Save(&Checkpoint{AnalysisGUID: "../escape"}))
+}
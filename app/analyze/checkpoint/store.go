@@ -0,0 +1,157 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type Store interface {
+	Save(cp *Checkpoint) error
+
+	// Load returns nil if there is no checkpoint of the analysis
+	Load(analysisGUID string) (*Checkpoint, error)
+	Delete(analysisGUID string) error
+	List() ([]*Checkpoint, error)
+}
+
+const fileExt = ".json"
+
+var guidRe = regexp.MustCompile(`^[0-9A-Za-z_-]+$`)
+
+// FileStore keeps checkpoints as files of the dir: the dir must belong to the only worker,
+// otherwise running analyses of other workers are recovered. Files are readable only by the worker:
+// tasks can contain raw tokens.
+type FileStore struct {
+	dir string
+
+	mkdirOnce sync.Once
+	mkdirErr  error
+}
+
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+func (s *FileStore) path(analysisGUID string) (string, error) {
+	if !guidRe.MatchString(analysisGUID) {
+		return "", errors.Errorf("invalid analysis guid %q", analysisGUID)
+	}
+
+	return filepath.Join(s.dir, analysisGUID+fileExt), nil
+}
+
+// Save writes the checkpoint atomically: a crash during saving doesn't leave a broken file
+func (s *FileStore) Save(cp *Checkpoint) error {
+	s.mkdirOnce.Do(func() {
+		s.mkdirErr = os.MkdirAll(s.dir, 0700)
+	})
+	if s.mkdirErr != nil {
+		return errors.Wrapf(s.mkdirErr, "can't make checkpoints dir %s", s.dir)
+	}
+
+	path, err := s.path(cp.AnalysisGUID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return errors.Wrap(err, "can't write checkpoint")
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return errors.Wrap(err, "can't rename checkpoint")
+	}
+
+	return nil
+}
+
+func (s *FileStore) Load(analysisGUID string) (*Checkpoint, error) {
+	path, err := s.path(analysisGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return readCheckpoint(path)
+}
+
+func readCheckpoint(path string) (*Checkpoint, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var cp Checkpoint
+	if err = json.Unmarshal(data, &cp); err != nil {
+		return nil, errors.Wrapf(err, "invalid checkpoint %s", path)
+	}
+
+	return &cp, nil
+}
+
+func (s *FileStore) Delete(analysisGUID string) error {
+	path, err := s.path(analysisGUID)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// List returns all checkpoints, broken files are skipped
+func (s *FileStore) List() ([]*Checkpoint, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var ret []*Checkpoint
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), fileExt) {
+			continue
+		}
+
+		cp, err := readCheckpoint(filepath.Join(s.dir, f.Name()))
+		if err != nil || cp == nil {
+			continue
+		}
+		ret = append(ret, cp)
+	}
+
+	return ret, nil
+}
+
+var defaultStore Store
+var defaultStoreOnce sync.Once
+
+// Default returns the store of CHECKPOINT_DIR, it's nil if checkpoints are disabled
+func Default() Store {
+	defaultStoreOnce.Do(func() {
+		if dir := os.Getenv("CHECKPOINT_DIR"); dir != "" {
+			defaultStore = NewFileStore(dir)
+		}
+	})
+
+	return defaultStore
+}
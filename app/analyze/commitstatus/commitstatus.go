@@ -0,0 +1,64 @@
+// Package commitstatus guards commit statuses against stale updates: a retried or duplicate task of a commit
+// can set "pending" after another analysis of the commit has already set the terminal status, e.g. when
+// a force-push and a retry interleave. Statuses set for commits are tracked by the state API: a status
+// is claimed there by compare-and-swap before it's set on GitHub.
+package commitstatus
+
+import (
+	"github.com/golangci/golangci-worker/app/lib/github"
+)
+
+// Record is the last status of a status context of a commit
+type Record struct {
+	Status       github.Status
+	AnalysisGUID string
+
+	// Seq orders analyses of the commit: it's the Version of the record set by the first claim
+	// of the analysis (see Reserve). Statuses of analyses with a smaller Seq are stale.
+	Seq int64
+
+	// Version is incremented by every change of the record, changes are compared and swapped by it
+	Version int64
+}
+
+func IsTerminal(s github.Status) bool {
+	return s == github.StatusSuccess || s == github.StatusFailure || s == github.StatusError
+}
+
+// ShouldSet returns false if the status of the analysis would overwrite the newer status of last:
+// pending never overwrites a terminal status, terminal statuses of analyses claimed before the analysis
+// of last are stale. seq is zero if the analysis hasn't claimed any status yet: it's older than last.
+// Analyses can always overwrite their own terminal statuses. last is nil if no status was set.
+func ShouldSet(last *Record, status github.Status, analysisGUID string, seq int64) bool {
+	if last == nil {
+		return true
+	}
+
+	if !IsTerminal(status) {
+		return !IsTerminal(last.Status)
+	}
+
+	return last.AnalysisGUID == analysisGUID || (seq != 0 && seq > last.Seq)
+}
+
+// Next returns the record replacing last by the status of the analysis
+func Next(last *Record, status github.Status, analysisGUID string, seq int64) *Record {
+	r := &Record{Status: status, AnalysisGUID: analysisGUID, Seq: seq, Version: 1}
+	if last != nil {
+		r.Version = last.Version + 1
+	}
+	if r.Seq == 0 {
+		r.Seq = r.Version
+	}
+
+	return r
+}
+
+// Reserve returns the record giving the analysis its sequence number without a status: it's its Version.
+// Pending of a new analysis doesn't overwrite the terminal status of last, but the final status of the
+// analysis must, e.g. of a re-run of the commit.
+func Reserve(last *Record) *Record {
+	r := *last
+	r.Version++
+	return &r
+}
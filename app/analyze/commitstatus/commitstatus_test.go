@@ -0,0 +1,47 @@
+package commitstatus
+
+import (
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldSet(t *testing.T) {
+	last := &Record{Status: github.StatusSuccess, AnalysisGUID: "new", Seq: 3, Version: 4}
+
+	assert.True(t, ShouldSet(nil, github.StatusPending, "any", 0))
+	// a retried task of an older analysis
+	assert.False(t, ShouldSet(last, github.StatusPending, "old", 1))
+	assert.False(t, ShouldSet(last, github.StatusFailure, "old", 1))
+	// an analysis which hasn't set statuses yet is older than the one which set the terminal status
+	assert.False(t, ShouldSet(last, github.StatusFailure, "dup", 0))
+	// a later analysis can't reset the terminal status to pending but can replace it
+	assert.False(t, ShouldSet(last, github.StatusPending, "later", 5))
+	assert.True(t, ShouldSet(last, github.StatusFailure, "later", 5))
+	// the analysis overwrites its own status, e.g. on a retried finalization
+	assert.True(t, ShouldSet(last, github.StatusError, "new", 3))
+	assert.False(t, ShouldSet(last, github.StatusPending, "new", 3))
+}
+
+func TestNext(t *testing.T) {
+	first := Next(nil, github.StatusPending, "a", 0)
+	assert.Equal(t, &Record{Status: github.StatusPending, AnalysisGUID: "a", Seq: 1, Version: 1}, first)
+
+	second := Next(first, github.StatusPending, "b", 0)
+	assert.Equal(t, &Record{Status: github.StatusPending, AnalysisGUID: "b", Seq: 2, Version: 2}, second)
+
+	// the analysis keeps its sequence number
+	assert.Equal(t, &Record{Status: github.StatusSuccess, AnalysisGUID: "a", Seq: 1, Version: 3},
+		Next(second, github.StatusSuccess, "a", 1))
+}
+
+func TestReserve(t *testing.T) {
+	last := &Record{Status: github.StatusFailure, AnalysisGUID: "old", Seq: 1, Version: 2}
+	reserved := Reserve(last)
+	assert.Equal(t, &Record{Status: github.StatusFailure, AnalysisGUID: "old", Seq: 1, Version: 3}, reserved)
+	assert.Equal(t, int64(2), last.Version)
+
+	// the re-run ordered by the reserved version replaces the terminal status
+	assert.True(t, ShouldSet(reserved, github.StatusSuccess, "rerun", reserved.Version))
+}
@@ -0,0 +1,71 @@
+package commitstatus
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/lib/apiclient"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+)
+
+//go:generate mockgen -package commitstatus -source storage.go -destination storage_mock.go
+
+type Storage interface {
+	// GetLast returns the last status of the status context of the commit, it's nil if it wasn't set
+	GetLast(ctx context.Context, repo *github.Repo, sha, statusContext string) (*Record, error)
+
+	// Swap atomically replaces the record of version r.Version-1 by r: it returns false
+	// if the record was changed by another analysis since it was read
+	Swap(ctx context.Context, repo *github.Repo, sha, statusContext string, r *Record) (bool, error)
+}
+
+type APIStorage struct {
+	api *apiclient.Client
+}
+
+func NewAPIStorage(client httputils.Client) *APIStorage {
+	return &APIStorage{
+		api: apiclient.New(client),
+	}
+}
+
+func (s APIStorage) GetLast(ctx context.Context, repo *github.Repo, sha, statusContext string) (*Record, error) {
+	var r Record
+	if err := s.api.GetCommitStatus(ctx, repo.Owner, repo.Name, sha, statusContext, &r); err != nil {
+		if apiclient.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+type swapRequest struct {
+	// PrevVersion is the version of the replaced record, it's zero if no record must exist
+	PrevVersion int64
+	Record      *Record
+}
+
+func (s APIStorage) Swap(ctx context.Context, repo *github.Repo, sha, statusContext string, r *Record) (bool, error) {
+	req := swapRequest{PrevVersion: r.Version - 1, Record: r}
+	if err := s.api.SwapCommitStatus(ctx, repo.Owner, repo.Name, sha, statusContext, req); err != nil {
+		if apiclient.IsConflict(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// NopStorage doesn't track statuses: nothing is guarded, e.g. in local analyses
+type NopStorage struct{}
+
+func (NopStorage) GetLast(ctx context.Context, repo *github.Repo, sha, statusContext string) (*Record, error) {
+	return nil, nil
+}
+
+func (NopStorage) Swap(ctx context.Context, repo *github.Repo, sha, statusContext string, r *Record) (bool, error) {
+	return true, nil
+}
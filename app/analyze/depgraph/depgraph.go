@@ -0,0 +1,139 @@
+// Package depgraph exports the module dependency graph of a repo: downstream tooling (SBOM generation,
+// license audit) consumes it from results of analyses without running builds again.
+package depgraph
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/pkg/errors"
+)
+
+// Module is a module of the build list
+type Module struct {
+	Path    string
+	Version string `json:",omitempty"` // empty for the main module
+
+	Main bool `json:",omitempty"`
+
+	// Indirect is set for requirements marked by "// indirect" in go.mod, it's false for modules
+	// missing in go.mod: use Direct to tell direct dependencies
+	Indirect bool `json:",omitempty"`
+
+	// Direct modules are required by go.mod of the main module and aren't marked indirect there
+	Direct bool `json:",omitempty"`
+
+	Replace *Module `json:",omitempty"`
+}
+
+// ID is path@version as in go mod graph, it's the path for the main module
+func (m Module) ID() string {
+	if m.Version == "" {
+		return m.Path
+	}
+
+	return m.Path + "@" + m.Version
+}
+
+// Edge is a requirement of a module by another one, modules are identified by their IDs
+type Edge struct {
+	From, To string
+}
+
+type Graph struct {
+	Modules []Module
+	Edges   []Edge `json:",omitempty"`
+}
+
+// Build lists modules and requirements in the work dir of exec, dependencies must be already fetched:
+// the graph is nil if the repo doesn't use go modules or they are disabled (GOPATH mode)
+func Build(ctx context.Context, exec executors.Executor) (*Graph, error) {
+	if _, err := executors.Unrestricted(exec).Run(ctx, "cat", "go.mod"); err != nil {
+		return nil, nil
+	}
+
+	// go.mod is ignored by the go command in GOPATH mode: GOMOD is empty then
+	if out, err := exec.Run(ctx, "go", "env", "GOMOD"); err != nil || strings.TrimSpace(out) == "" {
+		return nil, nil
+	}
+
+	out, err := exec.Run(ctx, "go", "list", "-m", "-json", "all")
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't list modules: %s", out)
+	}
+
+	modules, err := parseModules(out)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err = exec.Run(ctx, "go", "mod", "graph")
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get module graph: %s", out)
+	}
+
+	edges := parseModGraph(out)
+	markDirect(modules, edges)
+	return &Graph{Modules: modules, Edges: edges}, nil
+}
+
+// markDirect sets Direct of modules required by the main module: go mod graph has edges
+// from the main module only for requirements of its go.mod
+func markDirect(modules []Module, edges []Edge) {
+	var mainID string
+	for _, m := range modules {
+		if m.Main {
+			mainID = m.ID()
+		}
+	}
+
+	required := map[string]bool{}
+	for _, e := range edges {
+		if e.From == mainID {
+			required[e.To] = true
+		}
+	}
+
+	for i := range modules {
+		modules[i].Direct = required[modules[i].ID()] && !modules[i].Indirect
+	}
+}
+
+// parseModules parses concatenated json objects printed by go list -m -json
+func parseModules(out string) ([]Module, error) {
+	var ret []Module
+	dec := json.NewDecoder(strings.NewReader(out))
+	for {
+		var m Module
+		if err := dec.Decode(&m); err != nil {
+			if err == io.EOF {
+				return ret, nil
+			}
+			return nil, errors.Wrap(err, "can't parse go list output")
+		}
+		ret = append(ret, m)
+	}
+}
+
+// parseModGraph parses lines "from to" of go mod graph, edges are sorted
+func parseModGraph(out string) []Edge {
+	var ret []Edge
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			ret = append(ret, Edge{From: fields[0], To: fields[1]})
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].From != ret[j].From {
+			return ret[i].From < ret[j].From
+		}
+		return ret[i].To < ret[j].To
+	})
+	return ret
+}
@@ -0,0 +1,96 @@
+package depgraph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const goListOut = `{
+	"Path": "github.com/golangci/worker",
+	"Main": true,
+	"Dir": "/app"
+}
+{
+	"Path": "github.com/pkg/errors",
+	"Version": "v0.8.0",
+	"Time": "2016-09-29T01:48:01Z"
+}
+{
+	"Path": "golang.org/x/sync",
+	"Version": "v0.0.0-20181108010431-42b317875d0f",
+	"Indirect": true,
+	"Replace": {
+		"Path": "github.com/golang/sync",
+		"Version": "v0.1.0"
+	}
+}
+{
+	"Path": "golang.org/x/text",
+	"Version": "v0.3.0"
+}
+`
+
+const modGraphOut = `github.com/golangci/worker golang.org/x/sync@v0.0.0-20181108010431-42b317875d0f
+github.com/golangci/worker github.com/pkg/errors@v0.8.0
+github.com/pkg/errors@v0.8.0 golang.org/x/text@v0.3.0
+`
+
+func TestBuild(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	any := gomock.Any()
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Run(any, "cat", "go.mod").Return("module github.com/golangci/worker", nil)
+	exec.EXPECT().Run(any, "go", "env", "GOMOD").Return("/app/go.mod\n", nil)
+	exec.EXPECT().Run(any, "go", "list", "-m", "-json", "all").Return(goListOut, nil)
+	exec.EXPECT().Run(any, "go", "mod", "graph").Return(modGraphOut, nil)
+
+	g, err := Build(context.Background(), exec)
+	require.NoError(t, err)
+	assert.Equal(t, []Module{
+		{Path: "github.com/golangci/worker", Main: true},
+		{Path: "github.com/pkg/errors", Version: "v0.8.0", Direct: true},
+		{Path: "golang.org/x/sync", Version: "v0.0.0-20181108010431-42b317875d0f", Indirect: true,
+			Replace: &Module{Path: "github.com/golang/sync", Version: "v0.1.0"}},
+		// a transitive module missing in go.mod isn't marked indirect by go list
+		{Path: "golang.org/x/text", Version: "v0.3.0"},
+	}, g.Modules)
+	assert.Equal(t, []Edge{
+		{From: "github.com/golangci/worker", To: "github.com/pkg/errors@v0.8.0"},
+		{From: "github.com/golangci/worker", To: "golang.org/x/sync@v0.0.0-20181108010431-42b317875d0f"},
+		{From: "github.com/pkg/errors@v0.8.0", To: "golang.org/x/text@v0.3.0"},
+	}, g.Edges)
+	assert.Equal(t, g.Edges[0].To, g.Modules[1].ID())
+}
+
+func TestBuildWithoutModules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Run(gomock.Any(), "cat", "go.mod").Return("", errors.New("no such file"))
+
+	g, err := Build(context.Background(), exec)
+	assert.NoError(t, err)
+	assert.Nil(t, g)
+}
+
+func TestBuildInGOPATHMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Run(gomock.Any(), "cat", "go.mod").Return("module github.com/golangci/worker", nil)
+	exec.EXPECT().Run(gomock.Any(), "go", "env", "GOMOD").Return("\n", nil)
+
+	g, err := Build(context.Background(), exec)
+	assert.NoError(t, err)
+	assert.Nil(t, g)
+}
@@ -0,0 +1,129 @@
+// Package health computes weekly health snapshots of repos by full-repo analyses:
+// they are rendered as dashboards with trends by the API
+package health
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+)
+
+// LinterHealth is issues of a linter in the snapshot
+type LinterHealth struct {
+	Linter        string
+	Issues        int
+	IssuesPerKLOC float64
+
+	// Trend is a change of IssuesPerKLOC since the previous snapshot
+	Trend float64
+}
+
+// Snapshot is aggregated metrics of a full-repo analysis, there is one snapshot per week
+type Snapshot struct {
+	Week      string // ISO week, e.g. 2018-W47
+	CreatedAt time.Time
+	Branch    string
+
+	LOC           int // lines of go code, issues per KLOC are zero if it's unknown
+	Issues        int
+	IssuesPerKLOC float64
+	Trend         float64 // change of IssuesPerKLOC since the previous snapshot
+
+	// Linters are sorted by IssuesPerKLOC: the noisiest linter is the first.
+	// Linters of the previous snapshot without issues are kept with zero issues.
+	Linters []LinterHealth
+
+	// PreviousWeek is the week of the snapshot trends are computed by, it's empty for the first snapshot
+	PreviousWeek string `json:",omitempty"`
+
+	// Dependencies are informational issues of outdated and archived dependencies: they are checked
+	// only in weeks of dependencies (see IsDependenciesWeek) if the repo config enables it
+	Dependencies []result.Issue `json:",omitempty"`
+}
+
+// Week returns the ISO week of t, e.g. 2018-W47
+func Week(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// IsDependenciesWeek reports whether freshness of dependencies is checked in the week of t: it's
+// checked biweekly, in even ISO weeks
+func IsDependenciesWeek(t time.Time) bool {
+	_, week := t.ISOWeek()
+	return week%2 == 0
+}
+
+func perKLOC(issues, loc int) float64 {
+	if loc == 0 {
+		return 0
+	}
+
+	return round(float64(issues) * 1000 / float64(loc))
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Compute returns the snapshot of issues, trends are computed if prev isn't nil
+func Compute(issues []result.Issue, loc int, now time.Time, prev *Snapshot) *Snapshot {
+	counts := map[string]int{}
+	for _, i := range issues {
+		counts[i.FromLinter]++
+	}
+
+	prevByLinter := map[string]float64{}
+	if prev != nil {
+		for _, lh := range prev.Linters {
+			prevByLinter[lh.Linter] = lh.IssuesPerKLOC
+			if _, ok := counts[lh.Linter]; !ok {
+				counts[lh.Linter] = 0 // all issues of the linter were fixed: show the trend
+			}
+		}
+	}
+
+	s := &Snapshot{
+		Week:          Week(now),
+		CreatedAt:     now,
+		LOC:           loc,
+		Issues:        len(issues),
+		IssuesPerKLOC: perKLOC(len(issues), loc),
+	}
+	for linter, n := range counts {
+		lh := LinterHealth{
+			Linter:        linter,
+			Issues:        n,
+			IssuesPerKLOC: perKLOC(n, loc),
+		}
+		if prev != nil {
+			lh.Trend = round(lh.IssuesPerKLOC - prevByLinter[linter])
+		}
+		s.Linters = append(s.Linters, lh)
+	}
+	sort.Slice(s.Linters, func(i, j int) bool {
+		if s.Linters[i].IssuesPerKLOC != s.Linters[j].IssuesPerKLOC {
+			return s.Linters[i].IssuesPerKLOC > s.Linters[j].IssuesPerKLOC
+		}
+		return s.Linters[i].Linter < s.Linters[j].Linter
+	})
+
+	if prev != nil {
+		s.Trend = round(s.IssuesPerKLOC - prev.IssuesPerKLOC)
+		s.PreviousWeek = prev.Week
+	}
+
+	return s
+}
+
+// EventProps returns props of an analytics event
+func (s Snapshot) EventProps() map[string]interface{} {
+	return map[string]interface{}{
+		"healthIssues":        s.Issues,
+		"healthIssuesPerKLOC": s.IssuesPerKLOC,
+		"healthTrend":         s.Trend,
+	}
+}
@@ -0,0 +1,56 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeek(t *testing.T) {
+	assert.Equal(t, "2018-W47", Week(time.Date(2018, 11, 20, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2019-W01", Week(time.Date(2018, 12, 31, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestIsDependenciesWeek(t *testing.T) {
+	assert.True(t, IsDependenciesWeek(time.Date(2018, 11, 13, 0, 0, 0, 0, time.UTC)))  // W46
+	assert.False(t, IsDependenciesWeek(time.Date(2018, 11, 20, 0, 0, 0, 0, time.UTC))) // W47
+}
+
+func TestCompute(t *testing.T) {
+	now := time.Date(2018, 11, 20, 0, 0, 0, 0, time.UTC)
+	issues := []result.Issue{
+		{FromLinter: "govet"},
+		{FromLinter: "errcheck"},
+		{FromLinter: "errcheck"},
+	}
+
+	first := Compute(issues, 2000, now, nil)
+	assert.Equal(t, &Snapshot{
+		Week:          "2018-W47",
+		CreatedAt:     now,
+		LOC:           2000,
+		Issues:        3,
+		IssuesPerKLOC: 1.5,
+		Linters: []LinterHealth{
+			{Linter: "errcheck", Issues: 2, IssuesPerKLOC: 1},
+			{Linter: "govet", Issues: 1, IssuesPerKLOC: 0.5},
+		},
+	}, first)
+
+	second := Compute(issues[1:], 3000, now.Add(7*24*time.Hour), first)
+	assert.Equal(t, "2018-W47", second.PreviousWeek)
+	assert.Equal(t, 0.67, second.IssuesPerKLOC)
+	assert.Equal(t, -0.83, second.Trend)
+	assert.Equal(t, []LinterHealth{
+		{Linter: "errcheck", Issues: 2, IssuesPerKLOC: 0.67, Trend: -0.33},
+		{Linter: "govet", Trend: -0.5},
+	}, second.Linters)
+}
+
+func TestComputeWithoutLOC(t *testing.T) {
+	s := Compute([]result.Issue{{FromLinter: "govet"}}, 0, time.Now(), nil)
+	assert.Equal(t, 1, s.Issues)
+	assert.Zero(t, s.IssuesPerKLOC)
+}
@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/lib/apiclient"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+)
+
+//go:generate mockgen -package health -source storage.go -destination storage_mock.go
+
+type Storage interface {
+	// GetLast returns the last saved snapshot, it's nil if there are no snapshots
+	GetLast(ctx context.Context, owner, name string) (*Snapshot, error)
+	Put(ctx context.Context, owner, name string, s *Snapshot) error
+}
+
+type APIStorage struct {
+	api *apiclient.Client
+}
+
+func NewAPIStorage(client httputils.Client) *APIStorage {
+	return &APIStorage{
+		api: apiclient.New(client),
+	}
+}
+
+// Put saves the snapshot of the week: a snapshot of the same week is replaced
+func (s APIStorage) Put(ctx context.Context, owner, name string, snapshot *Snapshot) error {
+	return s.api.PutHealthSnapshot(ctx, owner, name, snapshot.Week, snapshot)
+}
+
+func (s APIStorage) GetLast(ctx context.Context, owner, name string) (*Snapshot, error) {
+	var resp struct {
+		Snapshot *Snapshot // nil if there are no snapshots
+	}
+	if err := s.api.GetLastHealthSnapshot(ctx, owner, name, &resp); err != nil {
+		if apiclient.IsNotFound(err) { // the repo isn't known by the API yet
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return resp.Snapshot, nil
+}
@@ -0,0 +1,133 @@
+// Package hooks is an extension SDK of pull request analyses: forks register plugins adding custom
+// behaviors at points of the analysis (e.g. compliance checks or metrics) without patching processors.
+//
+// A plugin registers itself in init of its package by Register, the package is linked into
+// the worker by a blank import. Registered plugins are enabled by the WORKER_PLUGINS env var.
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/github"
+)
+
+// Point of the analysis plugins are called at
+type Point string
+
+const (
+	// PreClone is called before the repo is cloned: the executor is ready
+	PreClone Point = "pre-clone"
+
+	// PostDeps is called after the repo is cloned and its dependencies are installed
+	PostDeps Point = "post-deps"
+
+	// PostLint is called after linters: plugins can add, change or drop issues
+	PostLint Point = "post-lint"
+
+	// PreReport is called before issues are reported to GitHub
+	PreReport Point = "pre-report"
+)
+
+// Analysis is a state of the analysis shared with plugins
+type Analysis struct {
+	Repo              github.Repo
+	PullRequestNumber int
+	CommitSHA         string
+	GUID              string
+
+	// Exec runs commands in the repo of the analysis
+	Exec executors.Executor
+
+	// Config is the merged config of the repo
+	Config *repoconfig.Config
+
+	// Patch is the diff of the pull request, it's set since PostDeps
+	Patch string
+
+	// Issues are set since PostLint, changes of them are saved and reported
+	Issues []result.Issue
+}
+
+type Plugin interface {
+	Name() string
+
+	// Hook is called at every point: plugins must ignore unknown points, new ones can be added.
+	// An error fails the analysis with an internal error.
+	Hook(ctx context.Context, point Point, a *Analysis) error
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]Plugin{}
+	enabled    []Plugin
+)
+
+// Register makes the plugin available by its name, it panics on duplicate names
+func Register(p Plugin) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[p.Name()]; ok {
+		panic(fmt.Sprintf("plugin %s is already registered", p.Name()))
+	}
+	registry[p.Name()] = p
+}
+
+// Registered returns sorted names of registered plugins
+func Registered() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	var ret []string
+	for name := range registry {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// Enable enables registered plugins by names: they are called in the order of names
+func Enable(names []string) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	var plugins []Plugin
+	for _, name := range names {
+		p, ok := registry[name]
+		if !ok {
+			return fmt.Errorf("plugin %s isn't registered", name)
+		}
+		plugins = append(plugins, p)
+	}
+
+	enabled = plugins
+	return nil
+}
+
+// EnableFromEnv enables plugins listed in the WORKER_PLUGINS env var, e.g. "compliance,issuemetrics"
+func EnableFromEnv() error {
+	var names []string
+	for _, name := range strings.Split(os.Getenv("WORKER_PLUGINS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return Enable(names)
+}
+
+// Enabled returns enabled plugins
+func Enabled() []Plugin {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	return enabled
+}
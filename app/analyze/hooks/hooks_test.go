@@ -0,0 +1,39 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type namedPlugin string
+
+func (p namedPlugin) Name() string {
+	return string(p)
+}
+
+func (p namedPlugin) Hook(context.Context, Point, *Analysis) error {
+	return nil
+}
+
+func TestEnableFromEnv(t *testing.T) {
+	Register(namedPlugin("first"))
+	Register(namedPlugin("second"))
+	assert.Panics(t, func() { Register(namedPlugin("first")) })
+	assert.Equal(t, []string{"first", "second"}, Registered())
+
+	defer os.Unsetenv("WORKER_PLUGINS")
+	os.Setenv("WORKER_PLUGINS", "second, first")
+	assert.NoError(t, EnableFromEnv())
+	assert.Equal(t, []Plugin{namedPlugin("second"), namedPlugin("first")}, Enabled())
+
+	os.Setenv("WORKER_PLUGINS", "first,unknown")
+	assert.Error(t, EnableFromEnv())
+	assert.Len(t, Enabled(), 2, "plugins aren't changed on errors")
+
+	os.Setenv("WORKER_PLUGINS", "")
+	assert.NoError(t, EnableFromEnv())
+	assert.Empty(t, Enabled())
+}
@@ -0,0 +1,34 @@
+// Package issuemetrics is a sample plugin: it counts reported issues by linters in metrics.
+// Link it by a blank import and enable it by WORKER_PLUGINS=issuemetrics.
+package issuemetrics
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/analyze/hooks"
+	"github.com/golangci/golangci-worker/app/lib/metrics"
+)
+
+const reportedIssuesGauge = "golangci_worker_reported_issues"
+
+type plugin struct{}
+
+func init() {
+	hooks.Register(plugin{})
+}
+
+func (plugin) Name() string {
+	return "issuemetrics"
+}
+
+func (plugin) Hook(_ context.Context, point hooks.Point, a *hooks.Analysis) error {
+	if point != hooks.PreReport {
+		return nil
+	}
+
+	for _, i := range a.Issues {
+		metrics.AddGauge(reportedIssuesGauge, metrics.Labels{"linter": i.FromLinter}, 1)
+	}
+
+	return nil
+}
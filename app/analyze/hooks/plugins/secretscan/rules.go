@@ -0,0 +1,241 @@
+package secretscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+// Rule is a gitleaks rule: gitleaks configs converted from TOML to JSON can be loaded as is
+type Rule struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Regex       string `json:"regex"`
+
+	// SecretGroup is a group of Regex matching the secret, the whole match is the secret if it's zero
+	SecretGroup int `json:"secretGroup,omitempty"`
+
+	// Entropy is the min Shannon entropy of the secret: it filters out placeholders of generic rules
+	Entropy float64 `json:"entropy,omitempty"`
+
+	// Keywords are checked before Regex: lines without any of them (case-insensitively) are skipped
+	Keywords []string `json:"keywords,omitempty"`
+
+	// Path is a regex of files the rule is applied to, all files are scanned if it's empty
+	Path string `json:"path,omitempty"`
+
+	Allowlist *Allowlist `json:"allowlist,omitempty"`
+}
+
+// Allowlist drops false positives of a rule
+type Allowlist struct {
+	Regexes   []string `json:"regexes,omitempty"`   // regexes of allowed secrets
+	Paths     []string `json:"paths,omitempty"`     // regexes of files
+	StopWords []string `json:"stopwords,omitempty"` // secrets containing them are allowed
+}
+
+// Config is a list of rules, it's the JSON form of a gitleaks config
+type Config struct {
+	Rules []Rule `json:"rules"`
+}
+
+// defaultRules is a subset of the default gitleaks rules: tokens of popular services and generic keys
+var defaultRules = []Rule{
+	{
+		ID:          "aws-access-token",
+		Description: "AWS access key",
+		Regex:       `\b((?:A3T[A-Z0-9]|AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16})\b`,
+		SecretGroup: 1,
+		Keywords:    []string{"a3t", "akia", "asia", "abia", "acca"},
+	},
+	{
+		ID:          "github-pat",
+		Description: "GitHub personal access token",
+		Regex:       `ghp_[0-9a-zA-Z]{36}`,
+		Keywords:    []string{"ghp_"},
+	},
+	{
+		ID:          "github-fine-grained-pat",
+		Description: "GitHub fine-grained personal access token",
+		Regex:       `github_pat_[0-9a-zA-Z_]{82}`,
+		Keywords:    []string{"github_pat_"},
+	},
+	{
+		ID:          "github-oauth",
+		Description: "GitHub OAuth access token",
+		Regex:       `gho_[0-9a-zA-Z]{36}`,
+		Keywords:    []string{"gho_"},
+	},
+	{
+		ID:          "slack-bot-token",
+		Description: "Slack bot token",
+		Regex:       `xoxb-[0-9]{10,13}-[0-9]{10,13}[a-zA-Z0-9-]*`,
+		Keywords:    []string{"xoxb"},
+	},
+	{
+		ID:          "stripe-access-token",
+		Description: "Stripe access token",
+		Regex:       `\b(?:sk|rk)_(?:test|live|prod)_[a-zA-Z0-9]{10,99}\b`,
+		Keywords:    []string{"sk_test", "sk_live", "sk_prod", "rk_test", "rk_live", "rk_prod"},
+	},
+	{
+		ID:          "gcp-api-key",
+		Description: "Google Cloud API key",
+		Regex:       `\b(AIza[0-9A-Za-z_-]{35})\b`,
+		SecretGroup: 1,
+		Keywords:    []string{"aiza"},
+	},
+	{
+		ID:          "private-key",
+		Description: "private key",
+		Regex:       `-----BEGIN[ A-Z0-9_-]{0,100}PRIVATE KEY( BLOCK)?-----`,
+		Keywords:    []string{"-----begin"},
+	},
+	{
+		ID:          "generic-api-key",
+		Description: "generic API key",
+		Regex: `(?i)(?:key|api|token|secret|passw(?:or)?d|auth)[\w.-]{0,20}\s*(?:=|:=|:|=>)\s*` +
+			"[\"'`]([0-9a-zA-Z_\\-.=+/]{16,})[\"'`]",
+		SecretGroup: 1,
+		Entropy:     3.5,
+		Keywords:    []string{"key", "api", "token", "secret", "passwd", "password", "auth"},
+		Allowlist: &Allowlist{
+			Paths:     []string{`(^|/)testdata/`, `_test\.go$`},
+			StopWords: []string{"example", "placeholder", "changeme", "xxxxxxxx"},
+		},
+	},
+}
+
+// LoadConfig loads rules from a JSON gitleaks config
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read secret scan rules: %s", err)
+	}
+
+	var c Config
+	if err = json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("can't parse secret scan rules %s: %s", path, err)
+	}
+
+	return &c, nil
+}
+
+type compiledRule struct {
+	Rule
+	re        *regexp.Regexp
+	path      *regexp.Regexp
+	allowed   []*regexp.Regexp
+	skipPaths []*regexp.Regexp
+	keywords  []string
+}
+
+func compileRule(r Rule) (*compiledRule, error) {
+	ret := &compiledRule{Rule: r}
+
+	var err error
+	if ret.re, err = regexp.Compile(r.Regex); err != nil {
+		return nil, fmt.Errorf("invalid regex of rule %s: %s", r.ID, err)
+	}
+	if r.SecretGroup > ret.re.NumSubexp() {
+		return nil, fmt.Errorf("rule %s: secret group %d doesn't exist", r.ID, r.SecretGroup)
+	}
+	if r.Path != "" {
+		if ret.path, err = regexp.Compile(r.Path); err != nil {
+			return nil, fmt.Errorf("invalid path of rule %s: %s", r.ID, err)
+		}
+	}
+	for _, k := range r.Keywords {
+		ret.keywords = append(ret.keywords, strings.ToLower(k))
+	}
+
+	if r.Allowlist != nil {
+		if ret.allowed, err = compileAll(r.Allowlist.Regexes); err != nil {
+			return nil, fmt.Errorf("invalid allowlist of rule %s: %s", r.ID, err)
+		}
+		if ret.skipPaths, err = compileAll(r.Allowlist.Paths); err != nil {
+			return nil, fmt.Errorf("invalid allowlist of rule %s: %s", r.ID, err)
+		}
+	}
+
+	return ret, nil
+}
+
+func compileAll(exprs []string) ([]*regexp.Regexp, error) {
+	var ret []*regexp.Regexp
+	for _, e := range exprs {
+		re, err := regexp.Compile(e)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, re)
+	}
+
+	return ret, nil
+}
+
+func matchAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *compiledRule) appliesTo(file string) bool {
+	if r.path != nil && !r.path.MatchString(file) {
+		return false
+	}
+
+	return !matchAny(r.skipPaths, file)
+}
+
+func (r *compiledRule) hasKeyword(lowerLine string) bool {
+	if len(r.keywords) == 0 {
+		return true
+	}
+
+	for _, k := range r.keywords {
+		if strings.Contains(lowerLine, k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matches returns true if the line contains a secret by the rule
+func (r *compiledRule) matches(line string) bool {
+	for _, m := range r.re.FindAllStringSubmatch(line, -1) {
+		secret := m[r.SecretGroup]
+		if secret == "" || matchAny(r.allowed, secret) || r.isStopWord(secret) {
+			continue
+		}
+		if r.Entropy != 0 && shannonEntropy(secret) < r.Entropy {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func (r *compiledRule) isStopWord(secret string) bool {
+	if r.Allowlist == nil {
+		return false
+	}
+
+	lower := strings.ToLower(secret)
+	for _, w := range r.Allowlist.StopWords {
+		if strings.Contains(lower, strings.ToLower(w)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,174 @@
+// Package secretscan is a plugin reporting potential leaked credentials in lines added by pull requests
+// as issues of the highest severity. Matched values are never put into issues or logs: they are posted to GitHub.
+//
+// Link it by a blank import and enable it by WORKER_PLUGINS=secretscan, repos opt in by SecretScan
+// of the repo config. Rules are gitleaks rules: built-in ones can be extended by a JSON gitleaks config
+// at the path of SECRET_SCAN_RULES.
+package secretscan
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/hooks"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/diffanchor"
+)
+
+// linterName is a linter of reported issues: the quality gate gives it the highest severity
+const linterName = "secretscan"
+
+// Scanner finds secrets in added lines of diffs
+type Scanner struct {
+	rules []*compiledRule
+}
+
+func NewScanner(rules []Rule) (*Scanner, error) {
+	s := &Scanner{}
+	for _, r := range rules {
+		cr, err := compileRule(r)
+		if err != nil {
+			return nil, err
+		}
+		s.rules = append(s.rules, cr)
+	}
+
+	return s, nil
+}
+
+// Scan returns issues of added lines with secrets: a line is reported once by the first matched rule
+func (s *Scanner) Scan(p *diffanchor.Patch) []result.Issue {
+	var ret []result.Issue
+	for _, f := range p.Files {
+		if f.Path == "" || f.Binary {
+			continue
+		}
+
+		rules := s.fileRules(f.Path)
+		if len(rules) == 0 {
+			continue
+		}
+
+		for _, h := range f.Hunks {
+			for _, l := range h.Lines {
+				if l.Kind != diffanchor.Added {
+					continue
+				}
+
+				if r := matchLine(rules, l.Text); r != nil {
+					ret = append(ret, result.Issue{
+						FromLinter: linterName,
+						Rule:       r.ID,
+						Text: fmt.Sprintf("Potential leaked credential: %s. Remove it and rotate it: "+
+							"it stays in the git history", r.Description),
+						File:       f.Path,
+						LineNumber: l.NewLine,
+						HunkPos:    l.Position,
+					})
+				}
+			}
+		}
+	}
+
+	return ret
+}
+
+func (s *Scanner) fileRules(file string) []*compiledRule {
+	var ret []*compiledRule
+	for _, r := range s.rules {
+		if r.appliesTo(file) {
+			ret = append(ret, r)
+		}
+	}
+
+	return ret
+}
+
+func matchLine(rules []*compiledRule, line string) *compiledRule {
+	lower := strings.ToLower(line)
+	for _, r := range rules {
+		if r.hasKeyword(lower) && r.matches(line) {
+			return r
+		}
+	}
+
+	return nil
+}
+
+func shannonEntropy(s string) float64 {
+	counts := map[rune]int{}
+	n := 0
+	for _, c := range s {
+		counts[c]++
+		n++
+	}
+
+	ret := 0.0
+	for _, count := range counts {
+		freq := float64(count) / float64(n)
+		ret -= freq * math.Log2(freq)
+	}
+
+	return ret
+}
+
+type plugin struct {
+	once    sync.Once
+	scanner *Scanner
+	err     error
+}
+
+func init() {
+	hooks.Register(&plugin{})
+}
+
+func (p *plugin) Name() string {
+	return "secretscan"
+}
+
+// load builds the scanner once: invalid rules of SECRET_SCAN_RULES fail analyses of opted-in repos
+func (p *plugin) load() (*Scanner, error) {
+	p.once.Do(func() {
+		rules := defaultRules
+		if path := os.Getenv("SECRET_SCAN_RULES"); path != "" {
+			var c *Config
+			if c, p.err = LoadConfig(path); p.err != nil {
+				return
+			}
+			rules = append(append([]Rule{}, defaultRules...), c.Rules...)
+		}
+
+		p.scanner, p.err = NewScanner(rules)
+	})
+
+	return p.scanner, p.err
+}
+
+func (p *plugin) Hook(ctx context.Context, point hooks.Point, a *hooks.Analysis) error {
+	if point != hooks.PreReport || !a.Config.GetSecretScan() {
+		return nil
+	}
+
+	scanner, err := p.load()
+	if err != nil {
+		return err
+	}
+
+	patch, err := diffanchor.Parse(a.Patch)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't parse the patch to scan for secrets: %s", err)
+		return nil
+	}
+
+	issues := scanner.Scan(patch)
+	if len(issues) != 0 {
+		analytics.Log(ctx).Infof("Found %d potential leaked credentials", len(issues))
+		a.Issues = append(a.Issues, issues...)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Package issueaging tracks issues of a pull request across its analyses: how long issues persist
+// until they are fixed or the PR is merged. Ages of resolved issues are sent to analytics
+// to find linters whose issues are really fixed and linters whose issues are just ignored.
+package issueaging
+
+import (
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+)
+
+const (
+	ResolutionFixed  = "fixed"  // the issue isn't reported anymore
+	ResolutionMerged = "merged" // the PR was merged with the issue
+	ResolutionClosed = "closed" // the PR was closed without merging
+)
+
+// Record is an issue reported by analyses of the PR, it's identified by its fingerprint
+type Record struct {
+	Fingerprint string
+	Linter      string
+	Rule        string `json:",omitempty"`
+	FirstSeen   time.Time
+
+	// Analyses is a count of analyses of different commits the issue was reported by
+	Analyses int
+}
+
+// Snapshot is issues of the last analysis of the PR
+type Snapshot struct {
+	CommitSHA  string
+	AnalyzedAt time.Time
+	Issues     []Record
+}
+
+// Resolved is an issue which isn't reported anymore
+type Resolved struct {
+	Record
+	Age        time.Duration
+	Resolution string
+}
+
+func (r Resolved) EventProps() map[string]interface{} {
+	return map[string]interface{}{
+		"linter":     r.Linter,
+		"rule":       r.Rule,
+		"ageSeconds": int(r.Age.Seconds()),
+		"analyses":   r.Analyses,
+		"resolution": r.Resolution,
+	}
+}
+
+// Track returns the snapshot of issues of the commit and issues of prev resolved by it.
+// Issues keep the time they were first seen while they are reported. A reanalysis of the commit
+// of prev doesn't change anything: prev is returned.
+func Track(prev *Snapshot, issues []result.Issue, commitSHA string, now time.Time) (*Snapshot, []Resolved) {
+	if prev != nil && prev.CommitSHA == commitSHA {
+		return prev, nil
+	}
+
+	prevByFingerprint := map[string]Record{}
+	if prev != nil {
+		for _, r := range prev.Issues {
+			prevByFingerprint[r.Fingerprint] = r
+		}
+	}
+
+	next := &Snapshot{
+		CommitSHA:  commitSHA,
+		AnalyzedAt: now,
+	}
+	for ind, fp := range result.Fingerprints(issues) {
+		r, ok := prevByFingerprint[fp]
+		if !ok {
+			r = Record{
+				Fingerprint: fp,
+				Linter:      issues[ind].FromLinter,
+				Rule:        issues[ind].Rule,
+				FirstSeen:   now,
+			}
+		}
+		delete(prevByFingerprint, fp)
+
+		r.Analyses++
+		next.Issues = append(next.Issues, r)
+	}
+
+	if prev == nil {
+		return next, nil
+	}
+
+	var resolved []Resolved
+	for _, r := range prev.Issues { // keep the order of prev
+		if _, ok := prevByFingerprint[r.Fingerprint]; ok {
+			resolved = append(resolved, Resolved{Record: r, Age: now.Sub(r.FirstSeen), Resolution: ResolutionFixed})
+		}
+	}
+
+	return next, resolved
+}
+
+// Close returns issues of prev resolved by merging or closing the PR
+func Close(prev *Snapshot, resolution string, now time.Time) []Resolved {
+	if prev == nil {
+		return nil
+	}
+
+	var resolved []Resolved
+	for _, r := range prev.Issues {
+		resolved = append(resolved, Resolved{Record: r, Age: now.Sub(r.FirstSeen), Resolution: resolution})
+	}
+
+	return resolved
+}
@@ -0,0 +1,51 @@
+package issueaging
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrack(t *testing.T) {
+	t0 := time.Date(2018, 11, 20, 10, 0, 0, 0, time.UTC)
+	issues := []result.Issue{
+		{FromLinter: "errcheck", File: "a.go", LineNumber: 10, Text: "unchecked error"},
+		{FromLinter: "gosec", Rule: "G104", File: "b.go", LineNumber: 5, Text: "errors unhandled"},
+	}
+
+	first, resolved := Track(nil, issues, "sha1", t0)
+	assert.Empty(t, resolved)
+	assert.Len(t, first.Issues, 2)
+	assert.Equal(t, t0, first.Issues[1].FirstSeen)
+	assert.Equal(t, "G104", first.Issues[1].Rule)
+
+	// the errcheck issue is moved by new code above it and the gosec issue is fixed
+	t1 := t0.Add(time.Hour)
+	moved := issues[0]
+	moved.LineNumber = 20
+	added := result.Issue{FromLinter: "golint", File: "c.go", LineNumber: 1, Text: "comment"}
+	second, resolved := Track(first, []result.Issue{moved, added}, "sha2", t1)
+
+	assert.Equal(t, []Resolved{{Record: first.Issues[1], Age: time.Hour, Resolution: ResolutionFixed}}, resolved)
+	assert.Equal(t, t0, second.Issues[0].FirstSeen)
+	assert.Equal(t, 2, second.Issues[0].Analyses)
+	assert.Equal(t, t1, second.Issues[1].FirstSeen)
+	assert.Equal(t, 1, second.Issues[1].Analyses)
+
+	resolved = Close(second, ResolutionMerged, t1.Add(time.Hour))
+	assert.Len(t, resolved, 2)
+	assert.Equal(t, 2*time.Hour, resolved[0].Age)
+	assert.Equal(t, ResolutionMerged, resolved[0].Resolution)
+}
+
+func TestTrackReanalysisOfCommit(t *testing.T) {
+	t0 := time.Date(2018, 11, 20, 10, 0, 0, 0, time.UTC)
+	issues := []result.Issue{{FromLinter: "errcheck", File: "a.go", LineNumber: 10, Text: "unchecked error"}}
+
+	first, _ := Track(nil, issues, "sha1", t0)
+	again, resolved := Track(first, nil, "sha1", t0.Add(time.Minute))
+	assert.Empty(t, resolved)
+	assert.Equal(t, first, again)
+}
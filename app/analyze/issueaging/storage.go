@@ -0,0 +1,53 @@
+package issueaging
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/lib/apiclient"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+)
+
+//go:generate mockgen -package issueaging -source storage.go -destination storage_mock.go
+
+type Storage interface {
+	// GetLast returns the snapshot of the last analysis of the PR, it's nil if the PR wasn't analyzed
+	GetLast(ctx context.Context, owner, name string, pull int) (*Snapshot, error)
+	Put(ctx context.Context, owner, name string, pull int, s *Snapshot) error
+}
+
+type APIStorage struct {
+	api *apiclient.Client
+}
+
+func NewAPIStorage(client httputils.Client) *APIStorage {
+	return &APIStorage{
+		api: apiclient.New(client),
+	}
+}
+
+func (s APIStorage) Put(ctx context.Context, owner, name string, pull int, snapshot *Snapshot) error {
+	return s.api.PutPRIssueAging(ctx, owner, name, pull, snapshot)
+}
+
+func (s APIStorage) GetLast(ctx context.Context, owner, name string, pull int) (*Snapshot, error) {
+	var snapshot Snapshot
+	if err := s.api.GetPRIssueAging(ctx, owner, name, pull, &snapshot); err != nil {
+		if apiclient.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// NopStorage doesn't keep snapshots: issues aren't tracked, e.g. by local analyses
+type NopStorage struct{}
+
+func (NopStorage) GetLast(ctx context.Context, owner, name string, pull int) (*Snapshot, error) {
+	return nil, nil
+}
+
+func (NopStorage) Put(ctx context.Context, owner, name string, pull int, s *Snapshot) error {
+	return nil
+}
@@ -0,0 +1,91 @@
+// Package issuecache keeps issues of the last analysis of a pull request with hashes of diffs of its files:
+// an analysis of the force-pushed pull request re-lints only packages changed since the cached commit,
+// issues of other packages are taken from the cache.
+package issuecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"sort"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/diffanchor"
+)
+
+// Entry is issues found by linters in the commit of the pull request
+type Entry struct {
+	CommitSHA string
+
+	// MergeBase is the base of the diff of the commit: diffs of other bases can't be compared
+	MergeBase string
+
+	// Key identifies settings of linters, e.g. their versions: issues found by other settings can't be reused
+	Key string
+
+	// Files are hashes of diffs of files by their paths, renamed files are keyed by both paths
+	Files map[string]string
+
+	Issues []result.Issue
+}
+
+// HashFiles returns hashes of diffs of files of the patch by their paths
+func HashFiles(patch *diffanchor.Patch) map[string]string {
+	ret := map[string]string{}
+	for _, f := range patch.Files {
+		data, err := json.Marshal(f)
+		if err != nil {
+			continue // never happens: files have only plain fields
+		}
+
+		h := sha256.Sum256(data)
+		hash := hex.EncodeToString(h[:])
+		for _, p := range []string{f.OldPath, f.Path} {
+			if p != "" {
+				ret[p] = hash
+			}
+		}
+	}
+
+	return ret
+}
+
+// Changed returns sorted paths of files changed since the commit of the entry: a file wasn't changed
+// if its diff from the same merge-base is the same. It's false if issues of the entry can't be reused:
+// there is no entry, it's the same commit (e.g. a retry is always a full analysis), the pull request
+// was rebased or linters were changed.
+func (e *Entry) Changed(commitSHA, mergeBase, key string, files map[string]string) ([]string, bool) {
+	if e == nil || e.CommitSHA == commitSHA || e.MergeBase == "" || e.MergeBase != mergeBase ||
+		e.Key == "" || e.Key != key {
+		return nil, false
+	}
+
+	var ret []string
+	for p, hash := range files {
+		if e.Files[p] != hash {
+			ret = append(ret, p)
+		}
+	}
+	for p := range e.Files {
+		if _, ok := files[p]; !ok {
+			ret = append(ret, p)
+		}
+	}
+
+	sort.Strings(ret)
+	return ret, true
+}
+
+// IssuesOutside returns issues of the entry not in dirs: dirs are paths of packages relative to the repo root,
+// "." is the root package
+func (e *Entry) IssuesOutside(dirs map[string]bool) []result.Issue {
+	var ret []result.Issue
+	for _, i := range e.Issues {
+		if !dirs[path.Dir(i.File)] {
+			ret = append(ret, i)
+		}
+	}
+
+	return ret
+}
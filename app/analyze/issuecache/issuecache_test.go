@@ -0,0 +1,56 @@
+package issuecache
+
+import (
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/diffanchor"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPatch = `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,1 +1,2 @@
+ package main
++var a = 1
+diff --git a/pkg/old.go b/pkg/new.go
+similarity index 100%
+rename from pkg/old.go
+rename to pkg/new.go
+`
+
+func TestChanged(t *testing.T) {
+	patch, err := diffanchor.Parse(testPatch)
+	if !assert.NoError(t, err) {
+		return
+	}
+	files := HashFiles(patch)
+	assert.Len(t, files, 3, "renamed files are keyed by both paths")
+
+	e := &Entry{CommitSHA: "sha1", MergeBase: "base", Key: "key", Files: files}
+	changed, ok := e.Changed("sha2", "base", "key", files)
+	assert.True(t, ok)
+	assert.Empty(t, changed)
+
+	next := map[string]string{"a.go": "other", "pkg/new.go": files["pkg/new.go"], "b.go": "new"}
+	changed, ok = e.Changed("sha2", "base", "key", next)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a.go", "b.go", "pkg/old.go"}, changed)
+
+	for _, tc := range []struct{ sha, mergeBase, key string }{
+		{"sha1", "base", "key"}, {"sha2", "rebased", "key"}, {"sha2", "base", "linters"},
+	} {
+		_, ok = e.Changed(tc.sha, tc.mergeBase, tc.key, files)
+		assert.False(t, ok, "%+v", tc)
+	}
+
+	_, ok = (*Entry)(nil).Changed("sha2", "base", "key", files)
+	assert.False(t, ok)
+}
+
+func TestIssuesOutside(t *testing.T) {
+	e := &Entry{Issues: []result.Issue{{File: "main.go"}, {File: "pkg/a.go"}, {File: "pkg/sub/b.go"}}}
+	assert.Equal(t, e.Issues[1:], e.IssuesOutside(map[string]bool{".": true}))
+	assert.Equal(t, []result.Issue{{File: "main.go"}, {File: "pkg/sub/b.go"}}, e.IssuesOutside(map[string]bool{"pkg": true}))
+}
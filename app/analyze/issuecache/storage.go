@@ -0,0 +1,53 @@
+package issuecache
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/lib/apiclient"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+)
+
+//go:generate mockgen -package issuecache -source storage.go -destination storage_mock.go
+
+type Storage interface {
+	// GetLast returns the entry of the last analysis of the PR, it's nil if issues of the PR weren't cached
+	GetLast(ctx context.Context, owner, name string, pull int) (*Entry, error)
+	Put(ctx context.Context, owner, name string, pull int, e *Entry) error
+}
+
+type APIStorage struct {
+	api *apiclient.Client
+}
+
+func NewAPIStorage(client httputils.Client) *APIStorage {
+	return &APIStorage{
+		api: apiclient.New(client),
+	}
+}
+
+func (s APIStorage) Put(ctx context.Context, owner, name string, pull int, e *Entry) error {
+	return s.api.PutPRIssueCache(ctx, owner, name, pull, e)
+}
+
+func (s APIStorage) GetLast(ctx context.Context, owner, name string, pull int) (*Entry, error) {
+	var e Entry
+	if err := s.api.GetPRIssueCache(ctx, owner, name, pull, &e); err != nil {
+		if apiclient.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// NopStorage doesn't cache issues: all packages are always linted, e.g. by local analyses
+type NopStorage struct{}
+
+func (NopStorage) GetLast(ctx context.Context, owner, name string, pull int) (*Entry, error) {
+	return nil, nil
+}
+
+func (NopStorage) Put(ctx context.Context, owner, name string, pull int, e *Entry) error {
+	return nil
+}
@@ -0,0 +1,102 @@
+package killswitch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golangci/golangci-shared/pkg/config"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/apiclient"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+)
+
+//go:generate mockgen -package killswitch -source fetcher.go -destination fetcher_mock.go
+
+type Fetcher interface {
+	Fetch(ctx context.Context) (*List, error)
+}
+
+type APIFetcher struct {
+	api *apiclient.Client
+}
+
+func NewAPIFetcher(client httputils.Client) *APIFetcher {
+	return &APIFetcher{
+		api: apiclient.New(client),
+	}
+}
+
+func (f APIFetcher) Fetch(ctx context.Context) (*List, error) {
+	var l List
+	if err := f.api.GetKillSwitches(ctx, &l); err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+// NopFetcher has no kill switches, e.g. for local analyses
+type NopFetcher struct{}
+
+func (NopFetcher) Fetch(ctx context.Context) (*List, error) {
+	return &List{}, nil
+}
+
+// CachingFetcher keeps the list in memory for ttl: every analysis checks it, the API isn't
+// requested for every one. The stale list is used if the API fails.
+type CachingFetcher struct {
+	fetcher Fetcher
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu        sync.Mutex
+	list      *List
+	fetchedAt time.Time
+}
+
+func NewCachingFetcher(fetcher Fetcher, ttl time.Duration) *CachingFetcher {
+	return &CachingFetcher{
+		fetcher: fetcher,
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+func (f *CachingFetcher) Fetch(ctx context.Context) (*List, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.list != nil && f.now().Sub(f.fetchedAt) < f.ttl {
+		return f.list, nil
+	}
+
+	l, err := f.fetcher.Fetch(ctx)
+	if err != nil {
+		if f.list == nil {
+			return nil, err
+		}
+
+		analytics.Log(ctx).Warnf("Can't fetch kill switches, use the list fetched at %s: %s", f.fetchedAt, err)
+		return f.list, nil
+	}
+
+	f.list, f.fetchedAt = l, f.now()
+	return l, nil
+}
+
+const defaultTTL = time.Minute
+
+var defaultFetcher *CachingFetcher
+var defaultFetcherOnce sync.Once
+
+// Default returns the fetcher shared by analyses of the worker, the list is cached for KILL_SWITCHES_TTL
+func Default() *CachingFetcher {
+	defaultFetcherOnce.Do(func() {
+		cfg := config.NewEnvConfig(logutil.NewStderrLog("killswitch"))
+		defaultFetcher = NewCachingFetcher(NewAPIFetcher(httputils.GrequestsClient{}), cfg.GetDuration("KILL_SWITCHES_TTL", defaultTTL))
+	})
+
+	return defaultFetcher
+}
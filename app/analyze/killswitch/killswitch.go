@@ -0,0 +1,72 @@
+// Package killswitch lets operators instantly disable analyses of abusive or broken repos and organizations:
+// the deny-list is managed in the API and checked by processors before any work.
+package killswitch
+
+import (
+	"context"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/github"
+)
+
+type Level string
+
+const (
+	// LevelSoft skips analyses with a success status explaining the reason
+	LevelSoft Level = "soft"
+
+	// LevelHard drops analyses without any requests to GitHub, e.g. for abusive repos
+	LevelHard Level = "hard"
+)
+
+// Entry disables analyses of a repo or of all repos of the owner if Repo is empty
+type Entry struct {
+	Owner  string
+	Repo   string `json:",omitempty"`
+	Level  Level
+	Reason string // public, it's shown in statuses of soft entries
+}
+
+type List struct {
+	Entries []Entry
+}
+
+// Find finds the entry of the repo: entries of repos take precedence over entries of owners
+func (l *List) Find(repo *github.Repo) *Entry {
+	if l == nil {
+		return nil
+	}
+
+	var ownerEntry *Entry
+	for i := range l.Entries {
+		e := &l.Entries[i]
+		if !strings.EqualFold(e.Owner, repo.Owner) {
+			continue
+		}
+
+		if e.Repo == "" {
+			ownerEntry = e
+		} else if strings.EqualFold(e.Repo, repo.Name) {
+			return e
+		}
+	}
+
+	return ownerEntry
+}
+
+// Check returns the entry disabling analyses of the repo or nil: errors of fetching
+// don't disable analyses, they are only logged
+func Check(ctx context.Context, f Fetcher, repo *github.Repo) *Entry {
+	if f == nil {
+		return nil
+	}
+
+	l, err := f.Fetch(ctx)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't fetch kill switches: %s", err)
+		return nil
+	}
+
+	return l.Find(repo)
+}
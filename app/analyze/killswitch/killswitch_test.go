@@ -0,0 +1,75 @@
+package killswitch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/stretchr/testify/assert"
+)
+
+var testList = &List{Entries: []Entry{
+	{Owner: "spammer", Level: LevelHard, Reason: "abuse"},
+	{Owner: "Org", Level: LevelSoft, Reason: "broken builds"},
+	{Owner: "org", Repo: "Huge", Level: LevelHard, Reason: "too large"},
+}}
+
+func TestFind(t *testing.T) {
+	assert.Equal(t, &testList.Entries[2], testList.Find(&github.Repo{Owner: "org", Name: "huge"}), "repo entry wins")
+	assert.Equal(t, &testList.Entries[1], testList.Find(&github.Repo{Owner: "org", Name: "other"}))
+	assert.Equal(t, &testList.Entries[0], testList.Find(&github.Repo{Owner: "spammer", Name: "x"}))
+	assert.Nil(t, testList.Find(&github.Repo{Owner: "good", Name: "huge"}))
+	assert.Nil(t, (*List)(nil).Find(&github.Repo{Owner: "org", Name: "huge"}))
+}
+
+func TestCheckIgnoresErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	f := NewMockFetcher(ctrl)
+	f.EXPECT().Fetch(ctx).Return(nil, errors.New("api is down"))
+	assert.Nil(t, Check(ctx, f, &github.Repo{Owner: "spammer", Name: "x"}))
+
+	f.EXPECT().Fetch(ctx).Return(testList, nil)
+	assert.Equal(t, LevelHard, Check(ctx, f, &github.Repo{Owner: "spammer", Name: "x"}).Level)
+}
+
+func TestCachingFetcher(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	now := time.Now()
+	f := NewMockFetcher(ctrl)
+	cf := NewCachingFetcher(f, time.Minute)
+	cf.now = func() time.Time { return now }
+
+	f.EXPECT().Fetch(ctx).Return(nil, errors.New("api is down"))
+	_, err := cf.Fetch(ctx)
+	assert.Error(t, err, "nothing was fetched before")
+
+	f.EXPECT().Fetch(ctx).Return(testList, nil)
+	l, err := cf.Fetch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, testList, l)
+
+	l, err = cf.Fetch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, testList, l, "cached")
+
+	now = now.Add(2 * time.Minute)
+	f.EXPECT().Fetch(ctx).Return(nil, errors.New("api is down"))
+	l, err = cf.Fetch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, testList, l, "stale list is used on errors")
+
+	newList := &List{}
+	f.EXPECT().Fetch(ctx).Return(newList, nil)
+	l, err = cf.Fetch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, newList, l)
+}
@@ -0,0 +1,76 @@
+package lintconfig
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Hints are limited to golangci-lint of the worker (1.12.3, see the Dockerfile): newer options
+// and linters can't be suggested, the config must work with the installed version.
+
+// deprecatedSettings are dotted paths of deprecated settings of golangci-lint and hints to replace them
+var deprecatedSettings = map[string]string{}
+
+// deprecatedLinters are deprecated linters and hints to replace them
+var deprecatedLinters = map[string]string{
+	"megacheck": "use `staticcheck`, `gosimple` and `unused`: they are separate linters",
+}
+
+var (
+	keyRe      = regexp.MustCompile(`^(\s*)([\w-]+)\s*:`)
+	listItemRe = regexp.MustCompile(`^\s*-\s*([\w-]+)\s*(?:#.*)?$`)
+)
+
+type yamlKey struct {
+	name   string
+	indent int
+}
+
+func dottedPath(path []yamlKey) string {
+	names := make([]string, 0, len(path))
+	for _, k := range path {
+		names = append(names, k.name)
+	}
+
+	return strings.Join(names, ".")
+}
+
+// FindDeprecated scans keys of the yaml config: it doesn't support flow style and anchors,
+// settings aren't found in them
+func FindDeprecated(content string) []Deprecation {
+	var ret []Deprecation
+	var path []yamlKey
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if m := listItemRe.FindStringSubmatch(line); m != nil {
+			if dottedPath(path) == "linters.enable" {
+				if hint, ok := deprecatedLinters[m[1]]; ok {
+					ret = append(ret, Deprecation{Setting: "linters.enable: " + m[1], Line: i + 1, Replacement: hint})
+				}
+			}
+			continue
+		}
+
+		m := keyRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		indent := len(m[1])
+		for len(path) != 0 && path[len(path)-1].indent >= indent {
+			path = path[:len(path)-1]
+		}
+		path = append(path, yamlKey{name: m[2], indent: indent})
+
+		setting := dottedPath(path)
+		if hint, ok := deprecatedSettings[setting]; ok {
+			ret = append(ret, Deprecation{Setting: setting, Line: i + 1, Replacement: hint})
+		}
+	}
+
+	return ret
+}
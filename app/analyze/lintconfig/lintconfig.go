@@ -0,0 +1,117 @@
+package lintconfig
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+)
+
+// configPaths are config files of golangci-lint in the order of its lookup
+var configPaths = []string{".golangci.yml", ".golangci.yaml", ".golangci.toml", ".golangci.json"}
+
+// Report is a result of the check of the golangci-lint config of the repo
+type Report struct {
+	// Path is the config path, it's empty if the repo has no config
+	Path string `json:",omitempty"`
+
+	// Suggested is a .golangci.yml suggested by traits of the project if the repo has no config
+	Suggested string `json:",omitempty"`
+
+	Deprecated []Deprecation `json:",omitempty"`
+}
+
+// Deprecation is a deprecated setting of the config
+type Deprecation struct {
+	Setting     string
+	Line        int
+	Replacement string
+}
+
+func (r *Report) IsEmpty() bool {
+	return r == nil || (r.Suggested == "" && len(r.Deprecated) == 0)
+}
+
+// Check checks the config in the work dir of the executor: it must be a checkout of the repo
+func Check(ctx context.Context, exec executors.Executor) (*Report, error) {
+	out, err := exec.Run(ctx, "git", "ls-files")
+	if err != nil {
+		return nil, fmt.Errorf("can't list files: %s, %s", err, out)
+	}
+	files := strings.Split(strings.TrimSpace(out), "\n")
+
+	path := findConfig(files)
+	if path == "" {
+		t := detectTraits(files)
+		if t.HasGoMod {
+			t.Module = readModulePath(ctx, exec)
+		}
+		return &Report{Suggested: Suggest(t)}, nil
+	}
+
+	ret := &Report{Path: path}
+	if !isYAML(path) {
+		return ret, nil // only yaml configs are checked for deprecated settings
+	}
+
+	content, err := executors.Unrestricted(exec).Run(ctx, "cat", path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read %s: %s, %s", path, err, content)
+	}
+	ret.Deprecated = FindDeprecated(content)
+	return ret, nil
+}
+
+func findConfig(files []string) string {
+	existing := map[string]bool{}
+	for _, f := range files {
+		existing[f] = true
+	}
+
+	for _, p := range configPaths {
+		if existing[p] {
+			return p
+		}
+	}
+
+	return ""
+}
+
+func isYAML(path string) bool {
+	return strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, ".yaml")
+}
+
+func readModulePath(ctx context.Context, exec executors.Executor) string {
+	out, err := executors.Unrestricted(exec).Run(ctx, "cat", "go.mod")
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+
+	return ""
+}
+
+// SummaryText is markdown for the summary comment printed by p, it's empty if there is nothing to offer
+func (r *Report) SummaryText(p i18n.Printer) string {
+	if r.IsEmpty() {
+		return ""
+	}
+
+	if r.Suggested != "" {
+		return p.Sprintf(i18n.SummaryNoLintConfig) + "\n\n" +
+			"<details><summary>.golangci.yml</summary>\n\n```yaml\n" + r.Suggested + "```\n</details>"
+	}
+
+	lines := []string{p.Sprintf(i18n.SummaryDeprecatedSettings, r.Path)}
+	for _, d := range r.Deprecated {
+		lines = append(lines, p.Sprintf(i18n.SummaryDeprecatedSetting, d.Setting, d.Line, d.Replacement))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,89 @@
+package lintconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDeprecated(t *testing.T) {
+	content := `run:
+  deadline: 5m # timeout isn't supported by golangci-lint of the worker
+  tests: false
+linters:
+  enable:
+    - megacheck
+    - gofmt
+  disable:
+    - maligned
+linters-settings:
+  govet:
+    check-shadowing: true
+`
+	exp := []Deprecation{
+		{Setting: "linters.enable: megacheck", Line: 6,
+			Replacement: "use `staticcheck`, `gosimple` and `unused`: they are separate linters"},
+	}
+	assert.Equal(t, exp, FindDeprecated(content))
+	assert.Empty(t, FindDeprecated("run:\n  timeout: 5m\n"))
+}
+
+func TestSuggest(t *testing.T) {
+	tr := detectTraits([]string{"go.mod", "cmd/app/main.go", "pkg/a.go", "pkg/a_test.go", "vendor/x/x.go"})
+	assert.Equal(t, Traits{HasGoMod: true, HasTests: true, HasMain: true, GoFiles: 3}, tr)
+
+	tr.Module = "github.com/owner/name"
+	exp := `run:
+  tests: true
+linters:
+  enable:
+    - gofmt
+    - goimports
+    - misspell
+    - unconvert
+    - unparam
+    - gosec
+    - gocyclo
+linters-settings:
+  gocyclo:
+    min-complexity: 15
+  goimports:
+    local-prefixes: github.com/owner/name
+`
+	assert.Equal(t, exp, Suggest(tr))
+}
+
+func TestCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Run(ctx, "git", "ls-files").Return("main.go\ngo.mod", nil)
+	exec.EXPECT().Run(ctx, "cat", "go.mod").Return("module github.com/owner/name\n\nrequire ()", nil)
+
+	r, err := Check(ctx, exec)
+	assert.NoError(t, err)
+	assert.Empty(t, r.Path)
+	assert.Contains(t, r.Suggested, "local-prefixes: github.com/owner/name")
+	assert.Contains(t, r.SummaryText(i18n.Printer{}), "This repo has no golangci-lint config")
+
+	exec.EXPECT().Run(ctx, "git", "ls-files").Return("main.go\n.golangci.yml", nil)
+	exec.EXPECT().Run(ctx, "cat", ".golangci.yml").Return("linters:\n  enable:\n    - megacheck", nil)
+
+	r, err = Check(ctx, exec)
+	assert.NoError(t, err)
+	assert.Equal(t, ".golangci.yml", r.Path)
+	assert.Empty(t, r.Suggested)
+	assert.Equal(t, "`.golangci.yml` has deprecated settings:\n"+
+		"- `linters.enable: megacheck` (line 3): use `staticcheck`, `gosimple` and `unused`: they are separate linters", r.SummaryText(i18n.Printer{}))
+
+	exec.EXPECT().Run(ctx, "git", "ls-files").Return("main.go\n.golangci.toml", nil)
+	r, err = Check(ctx, exec)
+	assert.NoError(t, err)
+	assert.True(t, r.IsEmpty())
+}
@@ -0,0 +1,71 @@
+package lintconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Traits of a project define the suggested config
+type Traits struct {
+	HasGoMod bool
+	Module   string // module path from go.mod, empty if it's unknown
+
+	HasTests bool
+
+	// HasMain is set for projects with commands: they are checked for security issues
+	HasMain bool
+
+	GoFiles int
+}
+
+// largeProjectFiles is a number of go files since which the project is considered large
+const largeProjectFiles = 200
+
+func detectTraits(files []string) Traits {
+	var t Traits
+	for _, f := range files {
+		switch {
+		case f == "go.mod":
+			t.HasGoMod = true
+		case strings.HasPrefix(f, "vendor/"):
+			continue
+		case strings.HasSuffix(f, "_test.go"):
+			t.HasTests = true
+			t.GoFiles++
+		case strings.HasSuffix(f, ".go"):
+			t.GoFiles++
+			if f == "main.go" || strings.HasSuffix(f, "/main.go") {
+				t.HasMain = true
+			}
+		}
+	}
+
+	return t
+}
+
+// Suggest returns a .golangci.yml for the project
+func Suggest(t Traits) string {
+	linters := []string{"gofmt", "goimports", "misspell", "unconvert", "unparam"}
+	if t.HasMain {
+		linters = append(linters, "gosec")
+	}
+	minComplexity := 15
+	if t.GoFiles >= largeProjectFiles {
+		linters = append(linters, "dupl")
+		minComplexity = 20 // legacy code of large projects is complex: don't be too noisy
+	}
+	linters = append(linters, "gocyclo")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "run:\n  tests: %t\n", t.HasTests)
+	b.WriteString("linters:\n  enable:\n")
+	for _, l := range linters {
+		fmt.Fprintf(&b, "    - %s\n", l)
+	}
+	fmt.Fprintf(&b, "linters-settings:\n  gocyclo:\n    min-complexity: %d\n", minComplexity)
+	if t.Module != "" {
+		fmt.Fprintf(&b, "  goimports:\n    local-prefixes: %s\n", t.Module)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,169 @@
+package golinters
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/pkg/errors"
+)
+
+// apiBaseWorktree is a checkout of the base revision inside the workspace, it's removed after the run
+const apiBaseWorktree = ".golangci-apibase"
+
+// APICompat reports breaking changes of the exported API of packages between the Base revision
+// and the workspace by apidiff. Removed declarations are located in the base revision: issues of them
+// have DeletedLine instead of LineNumber.
+type APICompat struct {
+	// Base is a revision to compare with, e.g. a merge-base of the pull request
+	Base string
+
+	// Packages are relative dirs of packages, e.g. ./pkg/client
+	Packages []string
+}
+
+func (a APICompat) Name() string {
+	return "apicompat"
+}
+
+// apiChange is a line of apidiff output, e.g. "- (*Client).Do: changed from func() to func(int)"
+type apiChange struct {
+	symbol string
+	desc   string
+}
+
+func (a APICompat) Run(ctx context.Context, exec executors.Executor) (*result.Result, error) {
+	if len(a.Packages) == 0 {
+		return &result.Result{}, nil
+	}
+
+	if out, err := exec.Run(ctx, "git", "worktree", "add", "--detach", apiBaseWorktree, a.Base); err != nil {
+		return nil, errors.Wrapf(err, "can't checkout base revision %s: %s", a.Base, out)
+	}
+	defer func() {
+		if out, err := exec.Run(ctx, "git", "worktree", "remove", "--force", apiBaseWorktree); err != nil {
+			analytics.Log(ctx).Warnf("Can't remove worktree of the base revision: %s, %s", err, out)
+		}
+	}()
+
+	baseExec := exec.WithWorkDir(path.Join(exec.WorkDir(), apiBaseWorktree))
+	var issues []result.Issue
+	for i, pkg := range a.Packages {
+		exportFile := fmt.Sprintf("api-%d.export", i)
+		if out, err := baseExec.Run(ctx, "apidiff", "-w", exportFile, pkg); err != nil {
+			// e.g. the package was added by the PR: it has no API to break
+			analytics.Log(ctx).Infof("Can't get exported API of %s at the base revision: %s, %s", pkg, err, out)
+			continue
+		}
+
+		out, err := exec.Run(ctx, "apidiff", "-incompatible", path.Join(apiBaseWorktree, exportFile), pkg)
+		if err != nil {
+			if !hasGoFiles(ctx, exec, pkg) { // the package was removed by the PR: all its API is broken
+				issues = append(issues, a.removedPackageIssue(pkg))
+				continue
+			}
+			return nil, errors.Wrapf(err, "can't compare exported API of %s: %s", pkg, out)
+		}
+
+		for _, c := range parseAPIChanges(out) {
+			issues = append(issues, a.buildIssue(ctx, exec, baseExec, pkg, c))
+		}
+	}
+
+	return &result.Result{Issues: issues}, nil
+}
+
+func (a APICompat) buildIssue(ctx context.Context, exec, baseExec executors.Executor, pkg string, c apiChange) result.Issue {
+	i := result.Issue{
+		FromLinter: a.Name(),
+		Text:       fmt.Sprintf("breaking change of the exported API: %s: %s", c.symbol, c.desc),
+		File:       path.Clean(pkg),
+	}
+
+	if c.desc == "removed" {
+		if file, line := findDeclaration(ctx, baseExec, pkg, c.symbol); line != 0 {
+			i.File, i.DeletedLine = file, line
+		}
+	} else if file, line := findDeclaration(ctx, exec, pkg, c.symbol); line != 0 {
+		i.File, i.LineNumber = file, line
+	}
+
+	return i
+}
+
+func (a APICompat) removedPackageIssue(pkg string) result.Issue {
+	return result.Issue{
+		FromLinter: a.Name(),
+		Text:       fmt.Sprintf("breaking change of the exported API: package %s was removed", pkg),
+		File:       path.Clean(pkg),
+	}
+}
+
+// hasGoFiles returns false if the dir of the package has no non-test go files or doesn't exist
+func hasGoFiles(ctx context.Context, exec executors.Executor, pkg string) bool {
+	out, err := exec.Run(ctx, "find", path.Clean(pkg), "-maxdepth", "1", "-name", "*.go", "-not", "-name", "*_test.go")
+	return err == nil && strings.TrimSpace(out) != ""
+}
+
+func parseAPIChanges(out string) []apiChange {
+	var ret []apiChange
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, "- ") {
+			continue // e.g. "Incompatible changes:" header
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(line, "- "), ": ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		ret = append(ret, apiChange{symbol: parts[0], desc: strings.TrimSpace(parts[1])})
+	}
+
+	return ret
+}
+
+// declarationPatterns returns grep patterns of a declaration of the symbol in the order of preference:
+// methods and fields (e.g. "(*T).M" or "T.F") fall back to the declaration of their type
+func declarationPatterns(symbol string) []string {
+	parts := strings.SplitN(symbol, ".", 2)
+	if len(parts) == 1 {
+		return []string{fmt.Sprintf(`^(func|type|var|const) %s\b`, regexp.QuoteMeta(symbol))}
+	}
+
+	typeName := strings.Trim(parts[0], "(*)")
+	return []string{
+		fmt.Sprintf(`^func \([^)]*\b%s\) %s\(`, regexp.QuoteMeta(typeName), regexp.QuoteMeta(parts[1])),
+		fmt.Sprintf(`^type %s\b`, regexp.QuoteMeta(typeName)),
+	}
+}
+
+// findDeclaration returns the file and the 1-based line of the symbol in the package or 0 if it's not found
+func findDeclaration(ctx context.Context, exec executors.Executor, pkg, symbol string) (string, int) {
+	dir := path.Clean(pkg)
+	for _, pattern := range declarationPatterns(symbol) {
+		out, err := executors.Unrestricted(exec).Run(ctx, "grep", "-n", "-E", "-r", "--include=*.go", "-e", pattern, dir)
+		if err != nil {
+			continue // grep exits with 1 if nothing was found
+		}
+
+		for _, line := range strings.Split(out, "\n") {
+			parts := strings.SplitN(line, ":", 3)
+			if len(parts) != 3 || path.Dir(parts[0]) != dir || strings.HasSuffix(parts[0], "_test.go") {
+				continue // declarations of subpackages and tests aren't a part of the package API
+			}
+
+			if n, convErr := strconv.Atoi(parts[1]); convErr == nil {
+				return path.Clean(parts[0]), n
+			}
+		}
+	}
+
+	return "", 0
+}
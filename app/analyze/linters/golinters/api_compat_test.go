@@ -0,0 +1,102 @@
+package golinters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAPIDiff = `Incompatible changes:
+- (*Client).Do: changed from func(string) error to func(context.Context, string) error
+- Timeout: removed
+`
+
+func TestAPICompat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	baseExec := executors.NewMockExecutor(ctrl)
+	grepErr := errors.New("exit status 1")
+
+	exec.EXPECT().Run(ctx, "git", "worktree", "add", "--detach", apiBaseWorktree, "base").Return("", nil)
+	exec.EXPECT().WorkDir().Return("/ws")
+	exec.EXPECT().WithWorkDir("/ws/" + apiBaseWorktree).Return(baseExec)
+
+	baseExec.EXPECT().Run(ctx, "apidiff", "-w", "api-0.export", "./client").Return("", nil)
+	exec.EXPECT().Run(ctx, "apidiff", "-incompatible", apiBaseWorktree+"/api-0.export", "./client").Return(testAPIDiff, nil)
+	exec.EXPECT().Run(ctx, "grep", "-n", "-E", "-r", "--include=*.go", "-e", `^func \([^)]*\bClient\) Do\(`, "client").
+		Return("client/sub/client.go:3:func (c *Client) Do(\nclient/client.go:12:func (c *Client) Do(ctx context.Context, s string) error {", nil)
+	baseExec.EXPECT().Run(ctx, "grep", "-n", "-E", "-r", "--include=*.go", "-e", `^(func|type|var|const) Timeout\b`, "client").
+		Return("", grepErr)
+
+	// added package: it has no API at the base revision
+	baseExec.EXPECT().Run(ctx, "apidiff", "-w", "api-1.export", "./added").Return("can't load", grepErr)
+
+	exec.EXPECT().Run(ctx, "git", "worktree", "remove", "--force", apiBaseWorktree).Return("", nil)
+
+	res, err := APICompat{Base: "base", Packages: []string{"./client", "./added"}}.Run(ctx, exec)
+	assert.NoError(t, err)
+	assert.Equal(t, []result.Issue{
+		{
+			FromLinter: "apicompat",
+			Text:       "breaking change of the exported API: (*Client).Do: changed from func(string) error to func(context.Context, string) error",
+			File:       "client/client.go",
+			LineNumber: 12,
+		},
+		{
+			FromLinter: "apicompat",
+			Text:       "breaking change of the exported API: Timeout: removed",
+			File:       "client",
+		},
+	}, res.Issues)
+}
+
+func TestAPICompatRemovedPackage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	baseExec := executors.NewMockExecutor(ctrl)
+
+	exec.EXPECT().Run(ctx, "git", "worktree", "add", "--detach", apiBaseWorktree, "base").Return("", nil)
+	exec.EXPECT().WorkDir().Return("/ws")
+	exec.EXPECT().WithWorkDir("/ws/" + apiBaseWorktree).Return(baseExec)
+
+	baseExec.EXPECT().Run(ctx, "apidiff", "-w", "api-0.export", "./old").Return("", nil)
+	exec.EXPECT().Run(ctx, "apidiff", "-incompatible", apiBaseWorktree+"/api-0.export", "./old").
+		Return("", errors.New("exit status 1"))
+	exec.EXPECT().Run(ctx, "find", "old", "-maxdepth", "1", "-name", "*.go", "-not", "-name", "*_test.go").
+		Return("", errors.New("no such file or directory"))
+
+	exec.EXPECT().Run(ctx, "git", "worktree", "remove", "--force", apiBaseWorktree).Return("", nil)
+
+	res, err := APICompat{Base: "base", Packages: []string{"./old"}}.Run(ctx, exec)
+	assert.NoError(t, err)
+	assert.Equal(t, []result.Issue{{
+		FromLinter: "apicompat",
+		Text:       "breaking change of the exported API: package ./old was removed",
+		File:       "old",
+	}}, res.Issues)
+}
+
+func TestAPICompatNoPackages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	res, err := APICompat{Base: "base"}.Run(context.Background(), executors.NewMockExecutor(ctrl))
+	assert.NoError(t, err)
+	assert.Empty(t, res.Issues)
+}
+
+func TestDeclarationPatterns(t *testing.T) {
+	assert.Equal(t, []string{`^(func|type|var|const) New\b`}, declarationPatterns("New"))
+	assert.Equal(t, []string{`^func \([^)]*\bT\) F\(`, `^type T\b`}, declarationPatterns("T.F"))
+}
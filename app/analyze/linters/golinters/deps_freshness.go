@@ -0,0 +1,171 @@
+package golinters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	gh "github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultMaxDepAge = 365 * 24 * time.Hour
+
+	// maxArchivedChecks limits anonymous GitHub requests: they are rate limited by 60 per hour
+	maxArchivedChecks = 20
+
+	// archivedCacheTTL is longer than TTLs of PR data: repos are rarely archived
+	archivedCacheTTL = time.Hour
+)
+
+// ArchivedChecker reports whether the upstream GitHub repo is archived
+type ArchivedChecker func(ctx context.Context, owner, name string) (bool, error)
+
+// DepsFreshness reports direct dependencies which are outdated for more than MaxAge, deprecated
+// or archived upstream. Issues are informational: they don't fail analysis.
+type DepsFreshness struct {
+	// MaxAge between the used version and the latest one, 1 year if it's zero
+	MaxAge time.Duration
+
+	// IsArchived is an anonymous GitHub client if it's nil
+	IsArchived ArchivedChecker
+}
+
+func (d DepsFreshness) Name() string {
+	return "deps-freshness"
+}
+
+type goListModule struct {
+	Path       string
+	Version    string
+	Time       *time.Time
+	Main       bool
+	Indirect   bool
+	Deprecated string
+	Update     *struct {
+		Version string
+		Time    *time.Time
+	}
+}
+
+func (d DepsFreshness) Run(ctx context.Context, exec executors.Executor) (*result.Result, error) {
+	goMod, err := executors.Unrestricted(exec).Run(ctx, "cat", "go.mod")
+	if err != nil {
+		return &result.Result{}, nil // not a go modules project: nothing to check
+	}
+
+	out, err := exec.Run(ctx, "go", "list", "-m", "-u", "-json", "all")
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't list modules: %s", out)
+	}
+
+	modules, err := parseGoListModules(out)
+	if err != nil {
+		return nil, err
+	}
+
+	isArchived := d.IsArchived
+	if isArchived == nil {
+		isArchived = isArchivedOnGithub
+	}
+	maxAge := d.MaxAge
+	if maxAge == 0 {
+		maxAge = defaultMaxDepAge
+	}
+
+	var issues []result.Issue
+	archivedChecks := 0
+	for _, m := range modules {
+		if m.Main || m.Indirect {
+			continue
+		}
+
+		var texts []string
+		if m.Deprecated != "" {
+			texts = append(texts, fmt.Sprintf("dependency %s is deprecated: %s", m.Path, m.Deprecated))
+		}
+		if m.Update != nil && m.Time != nil && m.Update.Time != nil && m.Update.Time.Sub(*m.Time) > maxAge {
+			texts = append(texts, fmt.Sprintf("dependency %s %s is outdated: %s was released %d days later",
+				m.Path, m.Version, m.Update.Version, int(m.Update.Time.Sub(*m.Time)/(24*time.Hour))))
+		}
+		if parts := strings.Split(m.Path, "/"); len(parts) >= 3 && parts[0] == "github.com" && archivedChecks < maxArchivedChecks {
+			archivedChecks++
+			archived, archErr := isArchived(ctx, parts[1], parts[2])
+			if archErr != nil {
+				analytics.Log(ctx).Infof("Can't check whether %s is archived: %s", m.Path, archErr)
+			} else if archived {
+				texts = append(texts, fmt.Sprintf("dependency %s is archived upstream", m.Path))
+			}
+		}
+
+		for _, text := range texts {
+			issues = append(issues, result.Issue{
+				FromLinter:    d.Name(),
+				Text:          text,
+				File:          "go.mod",
+				LineNumber:    findRequireLine(goMod, m.Path),
+				Informational: true,
+			})
+		}
+	}
+
+	return &result.Result{Issues: issues}, nil
+}
+
+// parseGoListModules parses concatenated json objects printed by go list -json
+func parseGoListModules(out string) ([]goListModule, error) {
+	var ret []goListModule
+	dec := json.NewDecoder(strings.NewReader(out))
+	for {
+		var m goListModule
+		if err := dec.Decode(&m); err != nil {
+			if err == io.EOF {
+				return ret, nil
+			}
+			return nil, errors.Wrap(err, "can't parse go list output")
+		}
+		ret = append(ret, m)
+	}
+}
+
+// findRequireLine returns the 1-based line of the module in go.mod or 0 if it's not found
+func findRequireLine(goMod, modulePath string) int {
+	for i, line := range strings.Split(goMod, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 0 && fields[0] == "require" {
+			fields = fields[1:]
+		}
+		if len(fields) != 0 && fields[0] == modulePath {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+func isArchivedOnGithub(ctx context.Context, owner, name string) (bool, error) {
+	var archived bool
+	key := fmt.Sprintf("github:archived:%s/%s", owner, name)
+	err := github.Cached(github.DefaultCache(), key, archivedCacheTTL, &archived, func() error {
+		// anonymous client: never send tokens of the worker to GitHub
+		client := gh.NewClient(&http.Client{Transport: httputils.Transport(), Timeout: 10 * time.Second})
+		repo, _, err := client.Repositories.Get(ctx, owner, name)
+		if err != nil {
+			return err
+		}
+
+		archived = repo.GetArchived()
+		return nil
+	})
+	return archived, err
+}
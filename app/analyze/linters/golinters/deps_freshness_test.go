@@ -0,0 +1,105 @@
+package golinters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+const testGoMod = `module github.com/golangci/example
+
+require (
+	github.com/old/dep v1.0.0
+	github.com/archived/dep v1.0.0
+	github.com/fresh/dep v1.2.0 // indirect
+)
+
+require github.com/deprecated/dep v0.1.0
+`
+
+const testGoList = `{
+	"Path": "github.com/golangci/example",
+	"Main": true
+}
+{
+	"Path": "github.com/old/dep",
+	"Version": "v1.0.0",
+	"Time": "2016-01-01T00:00:00Z",
+	"Update": {"Version": "v1.9.0", "Time": "2018-01-01T00:00:00Z"}
+}
+{
+	"Path": "github.com/archived/dep",
+	"Version": "v1.0.0",
+	"Time": "2018-01-01T00:00:00Z",
+	"Update": {"Version": "v1.0.1", "Time": "2018-02-01T00:00:00Z"}
+}
+{
+	"Path": "github.com/fresh/dep",
+	"Version": "v1.2.0",
+	"Indirect": true,
+	"Deprecated": "indirect deps aren't reported"
+}
+{
+	"Path": "github.com/deprecated/dep",
+	"Version": "v0.1.0",
+	"Deprecated": "use github.com/new/dep"
+}
+`
+
+func TestDepsFreshness(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Run(ctx, "cat", "go.mod").Return(testGoMod, nil)
+	exec.EXPECT().Run(ctx, "go", "list", "-m", "-u", "-json", "all").Return(testGoList, nil)
+
+	d := DepsFreshness{
+		IsArchived: func(ctx context.Context, owner, name string) (bool, error) {
+			return owner == "archived", nil
+		},
+	}
+	res, err := d.Run(ctx, exec)
+	assert.NoError(t, err)
+	assert.Equal(t, []result.Issue{
+		{
+			FromLinter:    "deps-freshness",
+			Text:          "dependency github.com/old/dep v1.0.0 is outdated: v1.9.0 was released 731 days later",
+			File:          "go.mod",
+			LineNumber:    4,
+			Informational: true,
+		},
+		{
+			FromLinter:    "deps-freshness",
+			Text:          "dependency github.com/archived/dep is archived upstream",
+			File:          "go.mod",
+			LineNumber:    5,
+			Informational: true,
+		},
+		{
+			FromLinter:    "deps-freshness",
+			Text:          "dependency github.com/deprecated/dep is deprecated: use github.com/new/dep",
+			File:          "go.mod",
+			LineNumber:    9,
+			Informational: true,
+		},
+	}, res.Issues)
+}
+
+func TestDepsFreshnessWithoutGoMod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Run(ctx, "cat", "go.mod").Return("", assert.AnError)
+
+	res, err := DepsFreshness{}.Run(ctx, exec)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Issues)
+}
@@ -0,0 +1,102 @@
+package golinters
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+)
+
+const duplLinter = "dupl"
+
+// duplTextRe matches texts of dupl issues: "10-20 lines are duplicate of `b.go:30-40`"
+var duplTextRe = regexp.MustCompile("^(\\d+)-(\\d+) lines are duplicate of `(.+):(\\d+)-(\\d+)`$")
+
+type duplPair struct {
+	from, to result.Location
+}
+
+func parseDuplIssue(i *result.Issue) (*duplPair, bool) {
+	if i.FromLinter != duplLinter {
+		return nil, false
+	}
+
+	m := duplTextRe.FindStringSubmatch(i.Text)
+	if m == nil {
+		return nil, false
+	}
+
+	nums := make([]int, 0, 4)
+	for _, s := range []string{m[1], m[2], m[4], m[5]} {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, false
+		}
+		nums = append(nums, n)
+	}
+
+	return &duplPair{
+		from: result.Location{File: filepath.Clean(i.File), Lines: result.Range{From: nums[0], To: nums[1]}},
+		to:   result.Location{File: filepath.Clean(m[3]), Lines: result.Range{From: nums[2], To: nums[3]}},
+	}, true
+}
+
+// key is the same for both directions of the pair
+func (p duplPair) key() [2]result.Location {
+	a, b := p.from, p.to
+	if b.File < a.File || (b.File == a.File && b.Lines.From < a.Lines.From) {
+		a, b = b, a
+	}
+	return [2]result.Location{a, b}
+}
+
+func inDiff(i *result.Issue) bool {
+	return i.HunkPos > 0 || i.DeletedLine > 0
+}
+
+// aggregateDuplicates merges symmetric dupl issues (a is a duplicate of b, b is a duplicate of a) into one issue
+// linking both copies: the copy in the diff is kept, the first one if both copies are in the diff.
+// The issue is commented if any copy is in the diff: the issue of the other copy is dropped by --new-from-patch.
+func aggregateDuplicates(issues []result.Issue) []result.Issue {
+	pairs := map[[2]result.Location][]int{} // indexes of issues of the pair
+	var keys [][2]result.Location
+	for ind := range issues {
+		p, ok := parseDuplIssue(&issues[ind])
+		if !ok {
+			continue
+		}
+
+		k := p.key()
+		if pairs[k] == nil {
+			keys = append(keys, k)
+		}
+		pairs[k] = append(pairs[k], ind)
+	}
+	if len(pairs) == 0 {
+		return issues
+	}
+
+	drop := map[int]bool{}
+	for _, k := range keys {
+		inds := pairs[k]
+		sort.SliceStable(inds, func(a, b int) bool { // the copy in the diff goes first
+			return inDiff(&issues[inds[a]]) && !inDiff(&issues[inds[b]])
+		})
+
+		for _, ind := range inds[1:] {
+			drop[ind] = true
+		}
+		p, _ := parseDuplIssue(&issues[inds[0]])
+		issues[inds[0]].Related = []result.Location{p.to}
+	}
+
+	ret := make([]result.Issue, 0, len(issues)-len(drop))
+	for ind, i := range issues {
+		if !drop[ind] {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,41 @@
+package golinters
+
+import (
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateDuplicates(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "dupl", File: "a.go", LineNumber: 10, Text: "10-20 lines are duplicate of `pkg/b.go:30-40`"},
+		{FromLinter: "errcheck", File: "a.go", LineNumber: 12, HunkPos: 3, Text: "unchecked error"},
+		{FromLinter: "dupl", File: "pkg/b.go", LineNumber: 30, HunkPos: 5, Text: "30-40 lines are duplicate of `a.go:10-20`"},
+		{FromLinter: "dupl", File: "c.go", LineNumber: 1, HunkPos: 1, Text: "1-9 lines are duplicate of `d.go:1-9`"},
+		{FromLinter: "dupl", File: "d.go", LineNumber: 1, HunkPos: 1, Text: "1-9 lines are duplicate of `c.go:1-9`"},
+	}
+
+	got := aggregateDuplicates(issues)
+	assert.Len(t, got, 3)
+	assert.Equal(t, "unchecked error", got[0].Text)
+
+	// the copy in the diff is kept
+	assert.Equal(t, "pkg/b.go", got[1].File)
+	assert.Equal(t, []result.Location{{File: "a.go", Lines: result.Range{From: 10, To: 20}}}, got[1].Related)
+
+	// both copies are in the diff: the first one is kept
+	assert.Equal(t, "c.go", got[2].File)
+	assert.Equal(t, []result.Location{{File: "d.go", Lines: result.Range{From: 1, To: 9}}}, got[2].Related)
+}
+
+func TestAggregateDuplicatesOneSide(t *testing.T) {
+	// the other copy isn't changed by the PR: its issue was dropped by --new-from-patch
+	issues := []result.Issue{
+		{FromLinter: "dupl", File: "a.go", LineNumber: 10, HunkPos: 2, Text: "10-20 lines are duplicate of `b.go:30-40`"},
+	}
+
+	got := aggregateDuplicates(issues)
+	assert.Len(t, got, 1)
+	assert.Equal(t, []result.Location{{File: "b.go", Lines: result.Range{From: 30, To: 40}}}, got[0].Related)
+}
@@ -0,0 +1,167 @@
+package golinters
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/diffanchor"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/pkg/errors"
+)
+
+// Formatters of formatting policies: they are bundled into the executor image
+const (
+	FormatterGofmt   = "gofmt"
+	FormatterGofumpt = "gofumpt"
+	FormatterGci     = "gci"
+)
+
+// Format reports code which isn't formatted by formatters of the policy: every issue has a replacement
+// with the formatted code. Formatters are run for every file separately, a file with syntax errors
+// is skipped: other linters report them.
+type Format struct {
+	Formatters []string
+
+	// LocalPrefix is an import path prefix of local packages: gci groups their imports separately
+	LocalPrefix string
+
+	// Files to check, e.g. go files changed by a pull request
+	Files []string
+}
+
+func (f Format) Name() string {
+	return "format"
+}
+
+// formatterCommand returns a command printing a unified diff of formatting of a file.
+// gci is v0.2.x pinned in the executor image: later versions replaced -d and -local by subcommands.
+func formatterCommand(formatter, localPrefix string) (string, []string, error) {
+	switch formatter {
+	case FormatterGofmt, FormatterGofumpt:
+		return formatter, []string{"-d"}, nil
+	case FormatterGci:
+		args := []string{"-d"}
+		if localPrefix != "" {
+			args = append(args, "-local", localPrefix)
+		}
+		return formatter, args, nil
+	}
+
+	return "", nil, fmt.Errorf("unknown formatter %q", formatter)
+}
+
+func (f Format) Run(ctx context.Context, exec executors.Executor) (*result.Result, error) {
+	var issues []result.Issue
+	for _, formatter := range f.Formatters {
+		name, args, err := formatterCommand(formatter, f.LocalPrefix)
+		if err != nil {
+			return nil, err
+		}
+
+		var lastErr error
+		failed := 0
+		for _, file := range f.Files {
+			out, runErr := exec.Run(ctx, name, append(args, file)...)
+			if runErr != nil {
+				analytics.Log(ctx).Infof("Can't run %s for %s: %s, %s", formatter, file, runErr, out)
+				lastErr = errors.Wrapf(runErr, "can't run %s: %s", formatter, out)
+				failed++
+				continue
+			}
+
+			p, parseErr := diffanchor.Parse(out)
+			if parseErr != nil {
+				return nil, errors.Wrapf(parseErr, "can't parse diff of %s", formatter)
+			}
+			for _, fd := range p.Files {
+				for ind := range fd.Hunks {
+					for _, fix := range hunkFixes(&fd.Hunks[ind]) {
+						issues = append(issues, fix.issue(formatter, file))
+					}
+				}
+			}
+		}
+		if failed != 0 && failed == len(f.Files) {
+			return nil, lastErr // e.g. the formatter isn't installed
+		}
+	}
+
+	return &result.Result{Issues: issues}, nil
+}
+
+// formatFix replaces lines since from to to of the current file by newLines
+type formatFix struct {
+	from, to int
+	newLines []string
+}
+
+func (fix formatFix) issue(formatter, file string) result.Issue {
+	i := result.Issue{
+		FromLinter:  formatter,
+		Text:        fmt.Sprintf("File is not `%s`-ed", formatter),
+		File:        file,
+		LineNumber:  fix.from,
+		Replacement: &result.Replacement{NewLines: fix.newLines},
+	}
+	if fix.to > fix.from {
+		i.LineRange = &result.Range{From: fix.from, To: fix.to}
+	}
+
+	return i
+}
+
+// hunkFixes splits the hunk into fixes by context lines: pure insertions replace
+// the neighbouring context line because suggestions can only replace existing lines
+func hunkFixes(h *diffanchor.Hunk) []formatFix {
+	var ret []formatFix
+	var cur *formatFix
+	var prev *diffanchor.Line // the last context line before the current fix
+
+	finish := func(next *diffanchor.Line) {
+		if cur == nil {
+			return
+		}
+		if cur.from == 0 { // only added lines
+			switch {
+			case prev != nil:
+				cur.from, cur.to = prev.OldLine, prev.OldLine
+				cur.newLines = append([]string{prev.Text}, cur.newLines...)
+			case next != nil:
+				cur.from, cur.to = next.OldLine, next.OldLine
+				cur.newLines = append(cur.newLines, next.Text)
+			default:
+				cur = nil
+				return
+			}
+		}
+		ret = append(ret, *cur)
+		cur = nil
+	}
+
+	for ind := range h.Lines {
+		l := &h.Lines[ind]
+		switch l.Kind {
+		case diffanchor.Context:
+			finish(l)
+			prev = l
+		case diffanchor.Deleted:
+			if cur == nil {
+				cur = &formatFix{}
+			}
+			if cur.from == 0 {
+				cur.from = l.OldLine
+			}
+			cur.to = l.OldLine
+		case diffanchor.Added:
+			if cur == nil {
+				cur = &formatFix{}
+			}
+			cur.newLines = append(cur.newLines, l.Text)
+		}
+	}
+	finish(nil)
+
+	return ret
+}
@@ -0,0 +1,75 @@
+package golinters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+const testGofmtDiff = `diff -u a.go.orig a.go
+--- a.go.orig	2018-10-01 10:00:00.000000000 +0000
++++ a.go	2018-10-01 10:00:00.000000000 +0000
+@@ -1,9 +1,9 @@
+ package a
+ 
+-func f()  {
+-	x:=1
++func f() {
++	x := 1
+ 	_ = x
+ }
+-
+ var y = 1
++
+ var z = 2`
+
+func TestFormat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Run(ctx, "gofmt", "-d", "a.go").Return(testGofmtDiff, nil)
+	exec.EXPECT().Run(ctx, "gofmt", "-d", "b.go").Return("", nil)
+	exec.EXPECT().Run(ctx, "gci", "-d", "-local", "github.com/golangci", "a.go").Return("", nil)
+	exec.EXPECT().Run(ctx, "gci", "-d", "-local", "github.com/golangci", "b.go").Return("syntax error", errors.New("exit status 2"))
+
+	f := Format{Formatters: []string{FormatterGofmt, FormatterGci}, LocalPrefix: "github.com/golangci", Files: []string{"a.go", "b.go"}}
+	res, err := f.Run(ctx, exec)
+	assert.NoError(t, err)
+	assert.Equal(t, []result.Issue{
+		{
+			FromLinter: "gofmt", Text: "File is not `gofmt`-ed", File: "a.go", LineNumber: 3,
+			LineRange:   &result.Range{From: 3, To: 4},
+			Replacement: &result.Replacement{NewLines: []string{"func f() {", "\tx := 1"}},
+		},
+		{
+			FromLinter: "gofmt", Text: "File is not `gofmt`-ed", File: "a.go", LineNumber: 7,
+			Replacement: &result.Replacement{},
+		},
+		{
+			FromLinter: "gofmt", Text: "File is not `gofmt`-ed", File: "a.go", LineNumber: 8,
+			Replacement: &result.Replacement{NewLines: []string{"var y = 1", ""}},
+		},
+	}, res.Issues)
+}
+
+func TestFormatFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Run(ctx, "gofumpt", "-d", "a.go").Return("gofumpt: not found", errors.New("exit status 127"))
+
+	_, err := Format{Formatters: []string{FormatterGofumpt}, Files: []string{"a.go"}}.Run(ctx, exec)
+	assert.Error(t, err)
+
+	_, err = Format{Formatters: []string{"prettier"}, Files: []string{"a.go"}}.Run(ctx, exec)
+	assert.Error(t, err)
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/lintcache"
 	"github.com/golangci/golangci-worker/app/analyze/linters/result"
 	"github.com/golangci/golangci-worker/app/lib/errorutils"
 	"github.com/golangci/golangci-worker/app/lib/executors"
@@ -14,20 +16,72 @@ import (
 	"github.com/golangci/golangci-lint/pkg/printers"
 )
 
+// ruleRe matches a rule in the text of an issue: staticcheck, gosec and others prefix the text with it
+var ruleRe = regexp.MustCompile(`^([A-Z]+[0-9]+): `)
+
+func issueRule(text string) string {
+	m := ruleRe.FindStringSubmatch(text)
+	if m == nil {
+		return ""
+	}
+
+	return m[1]
+}
+
 type GolangciLint struct {
 	PatchPath string
+
+	// EnabledLinters are enabled in addition to linters enabled by the repo config
+	EnabledLinters []string
+
+	// DisableAll disables linters of the repo config: only EnabledLinters are run
+	DisableAll bool
+
+	// Cache keeps caches of Repo between runs, caches aren't reused if it's nil
+	Cache *lintcache.Cache
+	Repo  string
+
+	// Packages to analyze (e.g. ./service/...), all packages are analyzed if it's empty
+	Packages []string
+
+	// Binary is a name of golangci-lint binary, e.g. of a canary version; it's golangci-lint if it's empty
+	Binary string
+
+	// Concurrency is a count of CPUs golangci-lint uses, it's the default (all CPUs of the host) if it's zero
+	Concurrency int
+
+	// SpellCheck enables misspell and golint, misspellings of words from SpellCheckDictionary are dropped
+	SpellCheck bool
+
+	// CPUProfilePath and MemProfilePath are paths golangci-lint writes its pprof profiles to,
+	// profiles aren't written if they are empty
+	CPUProfilePath string
+	MemProfilePath string
 }
 
 func (g GolangciLint) Name() string {
 	return "golangci-lint"
 }
 
-func (g GolangciLint) Run(ctx context.Context, exec executors.Executor) (*result.Result, error) {
+// prepareExec sets the env of golangci.com runs and the lint cache up
+func (g GolangciLint) prepareExec(ctx context.Context, exec executors.Executor) executors.Executor {
 	exec = exec.WithEnv("GOLANGCI_COM_RUN", "1")
+	if g.Cache != nil {
+		cachedExec, err := g.Cache.Prepare(ctx, exec, g.Repo)
+		if err != nil {
+			analytics.Log(ctx).Warnf("Can't prepare lint cache, run without it: %s", err)
+		} else {
+			exec = cachedExec
+		}
+	}
 
+	return exec
+}
+
+func (g GolangciLint) args(outFormat string) []string {
 	args := []string{
 		"run",
-		"--out-format=json",
+		"--out-format=" + outFormat,
 		"--issues-exit-code=0",
 		"--print-welcome=false",
 		"--timeout=5m",
@@ -35,34 +89,86 @@ func (g GolangciLint) Run(ctx context.Context, exec executors.Executor) (*result
 		"--new-from-rev=",
 		"--new-from-patch=" + g.PatchPath,
 	}
+	if g.DisableAll {
+		args = append(args, "--disable-all")
+	}
+	enabledLinters := g.EnabledLinters
+	if g.SpellCheck {
+		enabledLinters = withSpellCheckLinters(enabledLinters)
+	}
+	if len(enabledLinters) != 0 {
+		args = append(args, "--enable="+strings.Join(enabledLinters, ","))
+	}
+	if g.Concurrency != 0 {
+		args = append(args, fmt.Sprintf("--concurrency=%d", g.Concurrency))
+	}
+	if g.CPUProfilePath != "" {
+		args = append(args, "--cpu-profile-path="+g.CPUProfilePath)
+	}
+	if g.MemProfilePath != "" {
+		args = append(args, "--mem-profile-path="+g.MemProfilePath)
+	}
+	return append(args, g.Packages...)
+}
 
-	out, runErr := exec.Run(ctx, g.Name(), args...)
-	rawJSON := []byte(out)
+func (g GolangciLint) binary() string {
+	if g.Binary == "" {
+		return g.Name()
+	}
 
-	if runErr != nil {
-		var res printers.JSONResult
-		if jsonErr := json.Unmarshal(rawJSON, &res); jsonErr == nil && res.Report.Error != "" {
-			return nil, &errorutils.BadInputError{
-				PublicDesc: fmt.Sprintf("can't run golangci-lint: %s", res.Report.Error),
-			}
+	return g.Binary
+}
+
+// runError makes the error of the failed golangci-lint run public if it's caused by the repo
+func runError(runErr error, out string) error {
+	if executors.IsOutputTruncated(runErr) { // the json can't be parsed
+		return &errorutils.InternalError{
+			PublicDesc:  "can't run golangci-lint: too large output",
+			PrivateDesc: fmt.Sprintf("can't run golangci-lint: %s", runErr),
 		}
+	}
 
-		const badLoadStr = "failed to load program with go/packages"
-		if strings.Contains(runErr.Error(), badLoadStr) {
-			ind := strings.Index(runErr.Error(), badLoadStr)
-			if ind < len(runErr.Error())-1 {
-				return nil, &errorutils.BadInputError{
-					PublicDesc: runErr.Error()[ind:],
-				}
-			}
+	var res printers.JSONResult
+	if jsonErr := json.Unmarshal([]byte(out), &res); jsonErr == nil && res.Report != nil && res.Report.Error != "" {
+		return &errorutils.BadInputError{
+			PublicDesc: fmt.Sprintf("can't run golangci-lint: %s", res.Report.Error),
 		}
+	}
 
-		return nil, &errorutils.InternalError{
-			PublicDesc:  "can't run golangci-lint",
-			PrivateDesc: fmt.Sprintf("can't run golangci-lint: %s, %s", runErr, out),
+	const badLoadStr = "failed to load program with go/packages"
+	if strings.Contains(runErr.Error(), badLoadStr) {
+		ind := strings.Index(runErr.Error(), badLoadStr)
+		if ind < len(runErr.Error())-1 {
+			return &errorutils.BadInputError{
+				PublicDesc: runErr.Error()[ind:],
+				Class:      errorutils.ClassRepoBuild,
+			}
 		}
 	}
 
+	publicDesc := "can't run golangci-lint"
+	if reason, ok := errorutils.FlakyReason(runErr); ok && reason == "oom" {
+		// killed by the OOM killer: "signal: killed" says nothing to users
+		publicDesc = "can't run golangci-lint: out of memory"
+	}
+	return &errorutils.InternalError{
+		PublicDesc:  publicDesc,
+		PrivateDesc: fmt.Sprintf("can't run golangci-lint: %s, %s", runErr, out),
+	}
+}
+
+func (g GolangciLint) Run(ctx context.Context, exec executors.Executor) (*result.Result, error) {
+	return g.run(ctx, g.prepareExec(ctx, exec))
+}
+
+// run makes the json run with the prepared executor
+func (g GolangciLint) run(ctx context.Context, exec executors.Executor) (*result.Result, error) {
+	out, runErr := exec.Run(ctx, g.binary(), g.args("json")...)
+	rawJSON := []byte(out)
+	if runErr != nil {
+		return nil, runError(runErr, out)
+	}
+
 	var res printers.JSONResult
 	if jsonErr := json.Unmarshal(rawJSON, &res); jsonErr != nil {
 		return nil, &errorutils.InternalError{
@@ -83,8 +189,13 @@ func (g GolangciLint) Run(ctx context.Context, exec executors.Executor) (*result
 			Text:       i.Text,
 			FromLinter: i.FromLinter,
 			HunkPos:    i.HunkPos,
+			Rule:       issueRule(i.Text),
 		})
 	}
+	retIssues = aggregateDuplicates(retIssues)
+	if g.SpellCheck {
+		retIssues = filterDictionaryWords(retIssues, loadDictionary(ctx, exec))
+	}
 	return &result.Result{
 		Issues:     retIssues,
 		ResultJSON: json.RawMessage(rawJSON),
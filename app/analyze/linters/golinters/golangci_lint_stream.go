@@ -0,0 +1,175 @@
+package golinters
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/diffanchor"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+)
+
+// issueLineRe matches an issue printed in the line-number format: file:line[:column]: text (linter)
+var issueLineRe = regexp.MustCompile(`^(.+?):(\d+)(?::\d+)?: (.*) \(([\w-]+)\)$`)
+
+// streamedJSONResult is the json result of golangci-lint made of streamed issues: field names are
+// the same as in the json output of golangci-lint, the report isn't printed in the line-number format
+type streamedJSONResult struct {
+	Issues []streamedJSONIssue
+}
+
+type streamedJSONIssue struct {
+	FromLinter string
+	Text       string
+	Pos        struct {
+		Filename string
+		Line     int
+	}
+	HunkPos int
+}
+
+// RunStreaming emits issues while golangci-lint is running: the json output is printed only after all linters
+// finished, the line-number output has issues of every linter as soon as it finished. Emitting stops golangci-lint
+// on the first error of onIssue. Positions in the diff are found by the patch.
+//
+// The line-number output has no report, source lines and replacements and can't tell public errors (e.g. of
+// typechecking or of the config): it's used only for emitting. The result of the finished run is made by the json
+// run with warm caches, only the result of the stopped run is made of streamed issues.
+func (g GolangciLint) RunStreaming(ctx context.Context, exec executors.Executor, onIssue linters.IssueFunc) (*result.Result, error) {
+	exec = g.prepareExec(ctx, exec)
+	issues, stopped, err := g.stream(ctx, exec, onIssue)
+	if err != nil {
+		return nil, err
+	}
+	if !stopped {
+		return g.run(ctx, exec)
+	}
+
+	return &result.Result{
+		Issues:     aggregateDuplicates(issues),
+		ResultJSON: streamedResultJSON(issues),
+	}, nil
+}
+
+// stream runs golangci-lint with the line-number output and emits issues, stopped is set if onIssue stopped the run.
+// The error of the failed run isn't returned if the run context is alive: the json run returns it with details.
+func (g GolangciLint) stream(ctx context.Context, exec executors.Executor,
+	onIssue linters.IssueFunc) (issues []result.Issue, stopped bool, err error) {
+
+	patch := g.loadPatch(ctx, exec)
+	var dict map[string]bool
+	if g.SpellCheck {
+		dict = loadDictionary(ctx, exec)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	emitLine := func(line string) {
+		if stopped {
+			return
+		}
+
+		issue, ok := parseIssueLine(line, exec.WorkDir())
+		if !ok || len(filterDictionaryWords([]result.Issue{issue}, dict)) == 0 {
+			return
+		}
+		if patch != nil {
+			if loc, ok := patch.Locate(issue.File, issue.LineNumber); ok {
+				issue.HunkPos = loc.Line.Position
+			}
+		}
+
+		issues = append(issues, issue)
+		if err := onIssue(issue); err != nil {
+			stopped = true
+			cancel() // kill golangci-lint
+		}
+	}
+
+	streamed := false
+	lineCtx := executors.ContextWithOutputLines(runCtx, func(line []byte) {
+		streamed = true
+		emitLine(string(line))
+	})
+	out, runErr := exec.Run(lineCtx, g.binary(), append(g.args("line-number"), "--print-issued-lines=false")...)
+	if !streamed && !stopped { // the executor doesn't stream output
+		for _, line := range strings.Split(out, "\n") {
+			emitLine(line)
+		}
+	}
+
+	if runErr != nil && !stopped && ctx.Err() != nil {
+		return nil, false, runError(runErr, out)
+	}
+
+	return issues, stopped, nil
+}
+
+// loadPatch returns nil if the patch can't be read: issues aren't anchored to the diff then
+func (g GolangciLint) loadPatch(ctx context.Context, exec executors.Executor) *diffanchor.Patch {
+	if g.PatchPath == "" {
+		return nil
+	}
+
+	out, err := executors.Unrestricted(exec).Run(ctx, "cat", g.PatchPath)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't read patch %s: %s", g.PatchPath, err)
+		return nil
+	}
+
+	patch, err := diffanchor.Parse(out)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't parse patch %s: %s", g.PatchPath, err)
+		return nil
+	}
+
+	return patch
+}
+
+// parseIssueLine parses an issue of the line-number output, other lines (e.g. logs) aren't issues
+func parseIssueLine(line, workDir string) (result.Issue, bool) {
+	m := issueLineRe.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return result.Issue{}, false
+	}
+
+	lineNumber, err := strconv.Atoi(m[2])
+	if err != nil {
+		return result.Issue{}, false
+	}
+
+	file := m[1]
+	if rel, err := filepath.Rel(workDir, file); err == nil && filepath.IsAbs(file) && !strings.HasPrefix(rel, "..") {
+		file = rel
+	}
+
+	return result.Issue{
+		File:       file,
+		LineNumber: lineNumber,
+		Text:       m[3],
+		FromLinter: m[4],
+		Rule:       issueRule(m[3]),
+	}, true
+}
+
+func streamedResultJSON(issues []result.Issue) json.RawMessage {
+	res := streamedJSONResult{Issues: []streamedJSONIssue{}}
+	for _, i := range issues {
+		ji := streamedJSONIssue{FromLinter: i.FromLinter, Text: i.Text, HunkPos: i.HunkPos}
+		ji.Pos.Filename, ji.Pos.Line = i.File, i.LineNumber
+		res.Issues = append(res.Issues, ji)
+	}
+
+	ret, err := json.Marshal(res)
+	if err != nil {
+		return nil
+	}
+	return ret
+}
@@ -0,0 +1,100 @@
+package golinters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+const streamTestPatch = `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,2 +1,3 @@
+ package a
++var x = 1
+ var y = 2
+`
+
+func TestParseIssueLine(t *testing.T) {
+	i, ok := parseIssueLine("/work/a.go:2:5: SA4006: this value of `x` is never used (staticcheck)", "/work")
+	assert.True(t, ok)
+	assert.Equal(t, result.Issue{
+		File:       "a.go",
+		LineNumber: 2,
+		Text:       "SA4006: this value of `x` is never used",
+		FromLinter: "staticcheck",
+		Rule:       "SA4006",
+	}, i)
+
+	i, ok = parseIssueLine("a.go:3: line is 130 characters (lll)", "/work")
+	assert.True(t, ok)
+	assert.Equal(t, "a.go", i.File)
+	assert.Equal(t, "lll", i.FromLinter)
+
+	_, ok = parseIssueLine(`level=warning msg="[runner] Can't run linter unparam"`, "/work")
+	assert.False(t, ok)
+}
+
+func TestRunStreamingStopsOnError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().WithEnv("GOLANGCI_COM_RUN", "1").Return(exec)
+	exec.EXPECT().WorkDir().Return("/work").AnyTimes()
+	exec.EXPECT().Run(gomock.Any(), "cat", "patch.diff").Return(streamTestPatch, nil)
+	exec.EXPECT().Run(gomock.Any(), "golangci-lint", gomock.Any()).DoAndReturn(
+		func(ctx context.Context, name string, args ...string) (string, error) {
+			assert.Contains(t, args, "--out-format=line-number")
+			return "a.go:2:5: first (govet)\na.go:2:1: second (golint)", nil
+		})
+
+	var emitted []result.Issue
+	res, err := GolangciLint{PatchPath: "patch.diff"}.RunStreaming(context.Background(), exec, func(i result.Issue) error {
+		emitted = append(emitted, i)
+		return linters.ErrStopRun
+	})
+	assert.NoError(t, err)
+	assert.Len(t, emitted, 1)
+	assert.Equal(t, 2, emitted[0].HunkPos) // the position of the added line in the hunk
+	assert.Equal(t, emitted, res.Issues)
+	assert.JSONEq(t, `{"Issues": [{"FromLinter": "govet", "Text": "first", "Pos": {"Filename": "a.go", "Line": 2}, "HunkPos": 2}]}`,
+		string(res.ResultJSON.(json.RawMessage)))
+}
+
+func TestRunStreamingResultIsJSONRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().WithEnv("GOLANGCI_COM_RUN", "1").Return(exec)
+	exec.EXPECT().WorkDir().Return("/work").AnyTimes()
+	exec.EXPECT().Run(gomock.Any(), "cat", "patch.diff").Return(streamTestPatch, nil)
+	gomock.InOrder(
+		exec.EXPECT().Run(gomock.Any(), "golangci-lint", gomock.Any()).DoAndReturn(
+			func(ctx context.Context, name string, args ...string) (string, error) {
+				assert.Contains(t, args, "--out-format=line-number")
+				return "", errors.New("exit status 3")
+			}),
+		exec.EXPECT().Run(gomock.Any(), "golangci-lint", gomock.Any()).DoAndReturn(
+			func(ctx context.Context, name string, args ...string) (string, error) {
+				assert.Contains(t, args, "--out-format=json")
+				return `{"Report": {"Error": "typechecking error: a.go:2: undeclared name: z"}}`, errors.New("exit status 3")
+			}),
+	)
+
+	_, err := GolangciLint{PatchPath: "patch.diff"}.RunStreaming(context.Background(), exec, func(result.Issue) error {
+		return nil
+	})
+	badInputErr, ok := err.(*errorutils.BadInputError)
+	assert.True(t, ok)
+	assert.Contains(t, badInputErr.PublicDesc, "undeclared name: z")
+}
@@ -0,0 +1,37 @@
+package golinters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueRule(t *testing.T) {
+	assert.Equal(t, "G104", issueRule("G104: Errors unhandled."))
+	assert.Equal(t, "SA4006", issueRule("SA4006: this value of `err` is never used"))
+	assert.Equal(t, "", issueRule("Error return value of `f` is not checked"))
+	assert.Equal(t, "", issueRule("ID: unexported"))
+}
+
+func TestRunOOMKilled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().WithEnv("GOLANGCI_COM_RUN", "1").Return(exec)
+	exec.EXPECT().Run(gomock.Any(), "golangci-lint", gomock.Any()).Return("", errors.New("signal: killed"))
+
+	_, err := GolangciLint{}.Run(context.Background(), exec)
+	ie, ok := err.(*errorutils.InternalError)
+	assert.True(t, ok)
+	assert.Equal(t, "can't run golangci-lint: out of memory", ie.PublicDesc)
+
+	reason, ok := errorutils.FlakyReason(err)
+	assert.True(t, ok, "the run must be retried")
+	assert.Equal(t, "oom", reason)
+}
@@ -0,0 +1,234 @@
+package golinters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/pkg/errors"
+)
+
+// Govulncheck reports known vulnerabilities of dependencies and the standard library called by the code:
+// vulnerable modules which aren't called aren't reported
+type Govulncheck struct {
+	// Optional makes failures of govulncheck (e.g. of GOPATH projects or of an old toolchain) not fail the run:
+	// the result has no issues and the linter is in FailedLinters
+	Optional bool
+}
+
+func (g Govulncheck) Name() string {
+	return "govulncheck"
+}
+
+type vulnPosition struct {
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+}
+
+type vulnFrame struct {
+	Module   string        `json:"module"`
+	Package  string        `json:"package"`
+	Function string        `json:"function"`
+	Receiver string        `json:"receiver"`
+	Position *vulnPosition `json:"position"`
+}
+
+// vulnMessage is a message of the json stream of govulncheck
+type vulnMessage struct {
+	OSV *struct {
+		ID      string `json:"id"`
+		Summary string `json:"summary"`
+	} `json:"osv"`
+	Finding *struct {
+		OSV          string      `json:"osv"`
+		FixedVersion string      `json:"fixed_version"`
+		Trace        []vulnFrame `json:"trace"`
+	} `json:"finding"`
+}
+
+// optionalResult returns the result of the failed optional run, other errors are returned as is
+func (g Govulncheck) optionalResult(ctx context.Context, err error) (*result.Result, error) {
+	if err == nil || !g.Optional || ctx.Err() != nil { // timeouts are errors of the analysis
+		return nil, err
+	}
+
+	analytics.Log(ctx).Warnf("Optional govulncheck failed, continue without it: %s", err)
+	return &result.Result{FailedLinters: []string{g.Name()}}, nil
+}
+
+func (g Govulncheck) Run(ctx context.Context, exec executors.Executor) (*result.Result, error) {
+	res, err := g.run(ctx, exec)
+	if err != nil {
+		return g.optionalResult(ctx, err)
+	}
+
+	return res, nil
+}
+
+func (g Govulncheck) run(ctx context.Context, exec executors.Executor) (*result.Result, error) {
+	out, runErr := exec.Run(ctx, "govulncheck", "-json", "./...")
+	if executors.IsOutputTruncated(runErr) { // the json stream has a gap
+		return nil, errors.Wrap(runErr, "can't parse govulncheck output")
+	}
+	issues, err := parseGovulncheck(out, exec.WorkDir())
+	if err != nil {
+		if runErr != nil {
+			return nil, errors.Wrapf(runErr, "can't run govulncheck: %s", out)
+		}
+		return nil, err
+	}
+
+	return &result.Result{Issues: issues}, nil
+}
+
+// RunStreaming emits issues while govulncheck is running: its json output is a stream of messages
+func (g Govulncheck) RunStreaming(ctx context.Context, exec executors.Executor, onIssue linters.IssueFunc) (*result.Result, error) {
+	res, err := g.runStreaming(ctx, exec, onIssue)
+	if err != nil {
+		return g.optionalResult(ctx, err)
+	}
+
+	return res, nil
+}
+
+func (g Govulncheck) runStreaming(ctx context.Context, exec executors.Executor, onIssue linters.IssueFunc) (*result.Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var issues []result.Issue
+	stopped := false
+	emit := func(issue result.Issue) error {
+		issues = append(issues, issue)
+		if err := onIssue(issue); err != nil {
+			stopped = true
+			cancel() // kill govulncheck
+			return err
+		}
+		return nil
+	}
+
+	pr, pw := io.Pipe()
+	decoded := make(chan error, 1)
+	go func() {
+		err := decodeGovulncheck(pr, exec.WorkDir(), emit)
+		pr.CloseWithError(errors.New("govulncheck output isn't read")) // unblock writes after errors
+		decoded <- err
+	}()
+
+	streamed := false
+	lineCtx := executors.ContextWithOutputLines(ctx, func(line []byte) {
+		streamed = true
+		_, _ = pw.Write(append(line, '\n'))
+	})
+	out, runErr := exec.Run(lineCtx, "govulncheck", "-json", "./...")
+	if !streamed { // the executor doesn't stream output
+		_, _ = io.WriteString(pw, out)
+	}
+	pw.Close()
+	err := <-decoded
+
+	if stopped {
+		return &result.Result{Issues: issues}, nil
+	}
+	if err != nil {
+		if runErr != nil {
+			return nil, errors.Wrapf(runErr, "can't run govulncheck: %s", out)
+		}
+		return nil, err
+	}
+
+	return &result.Result{Issues: issues}, nil
+}
+
+// parseGovulncheck returns issues of the json output of govulncheck
+func parseGovulncheck(out, workDir string) ([]result.Issue, error) {
+	var ret []result.Issue
+	err := decodeGovulncheck(strings.NewReader(out), workDir, func(issue result.Issue) error {
+		ret = append(ret, issue)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// decodeGovulncheck emits issues of called vulnerable symbols: the first frame of their traces is
+// the vulnerable symbol, the issue is anchored to the last frame, the call in the code of the module.
+// Decoding stops on the first error of emit.
+func decodeGovulncheck(r io.Reader, workDir string, emit func(issue result.Issue) error) error {
+	summaries := map[string]string{}
+	seen := map[string]bool{}
+
+	dec := json.NewDecoder(r)
+	for {
+		var m vulnMessage
+		if err := dec.Decode(&m); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return errors.Wrap(err, "can't parse govulncheck json")
+		}
+
+		if m.OSV != nil {
+			summaries[m.OSV.ID] = m.OSV.Summary
+		}
+
+		f := m.Finding
+		if f == nil || len(f.Trace) == 0 || f.Trace[0].Function == "" {
+			continue // the vulnerable module or package isn't called
+		}
+
+		call := f.Trace[len(f.Trace)-1]
+		if call.Position == nil {
+			continue
+		}
+
+		file := call.Position.Filename
+		if rel, err := filepath.Rel(workDir, file); err == nil && filepath.IsAbs(file) && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+
+		key := fmt.Sprintf("%s:%s:%d", f.OSV, file, call.Position.Line)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		err := emit(result.Issue{
+			FromLinter: Govulncheck{}.Name(),
+			Rule:       f.OSV,
+			Text:       vulnText(f.OSV, summaries[f.OSV], f.Trace[0], f.FixedVersion),
+			File:       file,
+			LineNumber: call.Position.Line,
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func vulnText(id, summary string, symbol vulnFrame, fixedVersion string) string {
+	name := symbol.Function
+	if symbol.Receiver != "" {
+		name = fmt.Sprintf("%s.%s", strings.TrimPrefix(symbol.Receiver, "*"), name)
+	}
+
+	text := fmt.Sprintf("%s: vulnerable `%s.%s` is called", id, symbol.Package, name)
+	if summary != "" {
+		text += fmt.Sprintf(": %s", summary)
+	}
+	if fixedVersion != "" {
+		text += fmt.Sprintf(", fixed in %s@%s", symbol.Module, fixedVersion)
+	}
+
+	return text
+}
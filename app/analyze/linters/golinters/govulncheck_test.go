@@ -0,0 +1,149 @@
+package golinters
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+const testGovulncheckOut = `{"config": {"scanner_name": "govulncheck"}}
+{"osv": {"id": "GO-2023-0001", "summary": "Panic on crafted input"}}
+{"osv": {"id": "GO-2023-0002", "summary": "Imported but not called"}}
+{
+	"finding": {
+		"osv": "GO-2023-0002",
+		"fixed_version": "v1.2.0",
+		"trace": [{"module": "github.com/unused/dep", "version": "v1.0.0"}]
+	}
+}
+{
+	"finding": {
+		"osv": "GO-2023-0001",
+		"fixed_version": "v0.5.0",
+		"trace": [
+			{"module": "github.com/vuln/dep", "version": "v0.4.0", "package": "github.com/vuln/dep/parse",
+				"function": "Decode", "receiver": "*Decoder"},
+			{"module": "github.com/golangci/example", "package": "github.com/golangci/example/cmd",
+				"function": "main", "position": {"filename": "/repo/cmd/main.go", "line": 12}}
+		]
+	}
+}
+{
+	"finding": {
+		"osv": "GO-2023-0001",
+		"fixed_version": "v0.5.0",
+		"trace": [
+			{"module": "github.com/vuln/dep", "package": "github.com/vuln/dep/parse",
+				"function": "Decode", "receiver": "*Decoder"},
+			{"module": "github.com/golangci/example", "package": "github.com/golangci/example/cmd",
+				"function": "main", "position": {"filename": "/repo/cmd/main.go", "line": 12}}
+		]
+	}
+}
+`
+
+func TestGovulncheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Run(ctx, "govulncheck", "-json", "./...").Return(testGovulncheckOut, nil)
+	exec.EXPECT().WorkDir().Return("/repo")
+
+	res, err := Govulncheck{}.Run(ctx, exec)
+	assert.NoError(t, err)
+	assert.Equal(t, []result.Issue{
+		{
+			FromLinter: "govulncheck",
+			Rule:       "GO-2023-0001",
+			Text: "GO-2023-0001: vulnerable `github.com/vuln/dep/parse.Decoder.Decode` is called: " +
+				"Panic on crafted input, fixed in github.com/vuln/dep@v0.5.0",
+			File:       "cmd/main.go",
+			LineNumber: 12,
+		},
+	}, res.Issues)
+}
+
+func TestGovulncheckBadOutput(t *testing.T) {
+	_, err := parseGovulncheck("no vulnerability database", "/repo")
+	assert.Error(t, err)
+}
+
+func TestGovulncheckStreaming(t *testing.T) {
+	binDir, err := ioutil.TempDir("", "govulncheck")
+	assert.NoError(t, err)
+	defer os.RemoveAll(binDir)
+
+	finding := `{"finding": {"osv": "GO-2023-0001", "trace": [{"package": "p", "function": "F"},
+	{"position": {"filename": "%s", "line": 1}}]}}`
+	script := fmt.Sprintf("#!/bin/sh\necho '%s'\nsleep 10\necho '%s'\n",
+		fmt.Sprintf(finding, "a.go"), fmt.Sprintf(finding, "b.go"))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(binDir, "govulncheck"), []byte(script), 0700))
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	exec, err := executors.NewTempDirShell("test.govulncheck")
+	assert.NoError(t, err)
+	defer exec.Clean()
+
+	ctx := analytics.ContextWithEventPropsCollector(context.Background(), analytics.EventPRChecked)
+	var emitted []string
+	startedAt := time.Now()
+	res, err := Govulncheck{}.RunStreaming(ctx, exec, func(issue result.Issue) error {
+		emitted = append(emitted, issue.File)
+		return linters.ErrStopRun
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.go"}, emitted)
+	assert.Len(t, res.Issues, 1)
+	assert.True(t, time.Since(startedAt) < 5*time.Second, "govulncheck must be killed after the stop")
+}
+
+func TestGovulncheckStreamingWithoutOutputLines(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Run(gomock.Any(), "govulncheck", "-json", "./...").Return(testGovulncheckOut, nil)
+	exec.EXPECT().WorkDir().Return("/repo")
+
+	var emitted []result.Issue
+	res, err := Govulncheck{}.RunStreaming(context.Background(), exec, func(issue result.Issue) error {
+		emitted = append(emitted, issue)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, res.Issues, 1)
+	assert.Equal(t, res.Issues, emitted)
+}
+
+func TestOptionalGovulncheckFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := executors.NewMockExecutor(ctrl)
+	out := "govulncheck: loading packages: go: unsupported version of Go"
+	exec.EXPECT().Run(gomock.Any(), "govulncheck", "-json", "./...").Return(out, fmt.Errorf("exit status 1")).Times(2)
+	exec.EXPECT().WorkDir().Return("/repo").AnyTimes()
+
+	ctx := analytics.ContextWithEventPropsCollector(context.Background(), analytics.EventRepoAnalyzed)
+	_, err := Govulncheck{}.Run(ctx, exec)
+	assert.Error(t, err)
+
+	res, err := Govulncheck{Optional: true}.Run(ctx, exec)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Issues)
+	assert.Equal(t, []string{"govulncheck"}, res.FailedLinters)
+}
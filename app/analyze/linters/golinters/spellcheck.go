@@ -0,0 +1,76 @@
+package golinters
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+)
+
+// SpellCheckDictionary is a file in the repo root with project-specific words: one word per line,
+// lines starting with # are comments
+const SpellCheckDictionary = ".golangci-dictionary.txt"
+
+// spellCheckLinters catch typos and poor comments of exported declarations
+var spellCheckLinters = []string{"misspell", "golint"}
+
+var misspellingRe = regexp.MustCompile("^`([^`]+)` is a misspelling of ")
+
+// withSpellCheckLinters returns enabled linters extended by spell-check linters without duplicates
+func withSpellCheckLinters(enabled []string) []string {
+	ret := append([]string{}, enabled...)
+	for _, l := range spellCheckLinters {
+		found := false
+		for _, e := range enabled {
+			if e == l {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ret = append(ret, l)
+		}
+	}
+
+	return ret
+}
+
+// loadDictionary returns lowercased words of the dictionary of the repo, it's empty if there is no dictionary
+func loadDictionary(ctx context.Context, exec executors.Executor) map[string]bool {
+	out, err := executors.Unrestricted(exec).Run(ctx, "cat", SpellCheckDictionary)
+	if err != nil {
+		return nil
+	}
+
+	ret := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		word := strings.TrimSpace(line)
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		ret[strings.ToLower(word)] = true
+	}
+
+	return ret
+}
+
+// filterDictionaryWords drops misspellings of words from the dictionary
+func filterDictionaryWords(issues []result.Issue, dict map[string]bool) []result.Issue {
+	if len(dict) == 0 {
+		return issues
+	}
+
+	var ret []result.Issue
+	for _, i := range issues {
+		if i.FromLinter == "misspell" {
+			if m := misspellingRe.FindStringSubmatch(i.Text); m != nil && dict[strings.ToLower(m[1])] {
+				continue
+			}
+		}
+		ret = append(ret, i)
+	}
+
+	return ret
+}
@@ -0,0 +1,49 @@
+package golinters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSpellCheckLinters(t *testing.T) {
+	assert.Equal(t, []string{"misspell", "golint"}, withSpellCheckLinters(nil))
+	assert.Equal(t, []string{"gosec", "misspell", "golint"}, withSpellCheckLinters([]string{"gosec", "misspell"}))
+}
+
+func TestSpellCheckDictionary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Run(ctx, "cat", SpellCheckDictionary).Return("# project words\nMarshalling\n\n  cancelled \n", nil)
+
+	dict := loadDictionary(ctx, exec)
+	assert.Equal(t, map[string]bool{"marshalling": true, "cancelled": true}, dict)
+
+	issues := []result.Issue{
+		{FromLinter: "misspell", Text: "`marshalling` is a misspelling of `marshaling`"},
+		{FromLinter: "misspell", Text: "`Cancelled` is a misspelling of `Canceled`"},
+		{FromLinter: "misspell", Text: "`recieve` is a misspelling of `receive`"},
+		{FromLinter: "golint", Text: "comment on exported function Marshalling should be of the form \"Marshalling ...\""},
+	}
+	assert.Equal(t, issues[2:], filterDictionaryWords(issues, dict))
+}
+
+func TestSpellCheckNoDictionary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Run(ctx, "cat", SpellCheckDictionary).Return("", errors.New("no such file"))
+
+	issues := []result.Issue{{FromLinter: "misspell", Text: "`recieve` is a misspelling of `receive`"}}
+	assert.Equal(t, issues, filterDictionaryWords(issues, loadDictionary(ctx, exec)))
+}
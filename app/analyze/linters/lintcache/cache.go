@@ -0,0 +1,190 @@
+package lintcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangci/golangci-shared/pkg/config"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/pkg/errors"
+)
+
+// Cache keeps caches of golangci-lint and go build (analyzer facts, export data) between runs
+// on the same executor host: repeated analyses of the same repo don't start from scratch.
+// Caches are keyed by repo and golangci-lint version: a new version can't use old facts.
+type Cache struct {
+	root          string
+	maxAge        time.Duration
+	maxEntries    int
+	evictInterval time.Duration
+
+	mu            sync.Mutex
+	lastEvictAt   time.Time
+	linterVersion string
+}
+
+var defaultCache *Cache
+var defaultCacheOnce sync.Once
+
+// Default returns the cache configured by LINT_CACHE_DIR env var, it returns nil if it isn't set
+func Default() *Cache {
+	defaultCacheOnce.Do(func() {
+		log := logutil.NewStderrLog("lint cache")
+		cfg := config.NewEnvConfig(log)
+
+		root := cfg.GetString("LINT_CACHE_DIR")
+		if root == "" {
+			return
+		}
+
+		defaultCache = New(root, cfg.GetDuration("LINT_CACHE_MAX_AGE", 7*24*time.Hour),
+			cfg.GetInt("LINT_CACHE_MAX_ENTRIES", 50))
+	})
+
+	return defaultCache
+}
+
+func New(root string, maxAge time.Duration, maxEntries int) *Cache {
+	return &Cache{
+		root:          root,
+		maxAge:        maxAge,
+		maxEntries:    maxEntries,
+		evictInterval: time.Hour,
+	}
+}
+
+var unsafeKeyChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// Key is a name of the cache dir: readable but unique for the repo and the linter version
+func Key(repo, linterVersion string) string {
+	h := sha256.Sum256([]byte(repo + "@" + linterVersion))
+	name := unsafeKeyChars.ReplaceAllString(repo, "_")
+	return fmt.Sprintf("%s-%s", name, hex.EncodeToString(h[:])[:16])
+}
+
+func (c *Cache) getLinterVersion(ctx context.Context, exec executors.Executor) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.linterVersion != "" {
+		return c.linterVersion, nil
+	}
+
+	out, err := exec.Run(ctx, "golangci-lint", "--version")
+	if err != nil {
+		return "", errors.Wrapf(err, "can't get golangci-lint version: %s", out)
+	}
+
+	c.linterVersion = strings.TrimSpace(out)
+	return c.linterVersion, nil
+}
+
+// Prepare returns executor using the cache of the repo
+func (c *Cache) Prepare(ctx context.Context, exec executors.Executor, repo string) (executors.Executor, error) {
+	version, err := c.getLinterVersion(ctx, exec)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := path.Join(c.root, Key(repo, version))
+	if out, err := executors.Unrestricted(exec).Run(ctx, "mkdir", "-p", dir); err != nil {
+		return nil, errors.Wrapf(err, "can't make cache dir %s: %s", dir, out)
+	}
+	// mtime of the dir is the last usage time for eviction
+	if out, err := executors.Unrestricted(exec).Run(ctx, "touch", dir); err != nil {
+		return nil, errors.Wrapf(err, "can't touch cache dir %s: %s", dir, out)
+	}
+
+	c.evictIfNeeded(ctx, exec)
+
+	return exec.
+		WithEnv("GOLANGCI_LINT_CACHE", path.Join(dir, "lint")).
+		WithEnv("GOCACHE", path.Join(dir, "go")), nil
+}
+
+func (c *Cache) evictIfNeeded(ctx context.Context, exec executors.Executor) {
+	c.mu.Lock()
+	if time.Since(c.lastEvictAt) < c.evictInterval {
+		c.mu.Unlock()
+		return
+	}
+	c.lastEvictAt = time.Now()
+	c.mu.Unlock()
+
+	if err := c.Evict(ctx, exec); err != nil {
+		// cache is only an optimization: don't fail analysis
+		analytics.Log(ctx).Warnf("Failed to evict lint caches: %s", err)
+	}
+}
+
+// Evict removes caches not used for maxAge and least recently used ones above maxEntries
+func (c *Cache) Evict(ctx context.Context, exec executors.Executor) error {
+	out, err := executors.Unrestricted(exec).Run(ctx, "ls", "-1t", c.root)
+	if err != nil {
+		return errors.Wrapf(err, "can't list cache dirs: %s", out)
+	}
+	byRecency := splitLines(out)
+
+	minutes := fmt.Sprintf("+%d", int(c.maxAge/time.Minute))
+	out, err = executors.Unrestricted(exec).Run(ctx, "find", c.root, "-mindepth", "1", "-maxdepth", "1", "-mmin", minutes)
+	if err != nil {
+		return errors.Wrapf(err, "can't find expired cache dirs: %s", out)
+	}
+	var expired []string
+	for _, p := range splitLines(out) {
+		expired = append(expired, path.Base(p))
+	}
+
+	evicted := toEvict(byRecency, expired, c.maxEntries)
+	if len(evicted) == 0 {
+		return nil
+	}
+
+	args := []string{"-rf"}
+	for _, name := range evicted {
+		args = append(args, path.Join(c.root, name))
+	}
+	if out, err = executors.Unrestricted(exec).Run(ctx, "rm", args...); err != nil {
+		return errors.Wrapf(err, "can't remove cache dirs: %s", out)
+	}
+
+	analytics.Log(ctx).Infof("Evicted lint caches: %v", evicted)
+	return nil
+}
+
+// toEvict returns expired entries and entries above maxEntries: byRecency is sorted from the newest
+func toEvict(byRecency, expired []string, maxEntries int) []string {
+	isExpired := map[string]bool{}
+	for _, e := range expired {
+		isExpired[e] = true
+	}
+
+	var ret []string
+	for i, e := range byRecency {
+		if isExpired[e] || (maxEntries > 0 && i >= maxEntries) {
+			ret = append(ret, e)
+		}
+	}
+
+	return ret
+}
+
+func splitLines(out string) []string {
+	var ret []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			ret = append(ret, line)
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,60 @@
+package lintcache
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey(t *testing.T) {
+	k := Key("golangci/golangci-worker", "golangci-lint has version 1.12.2")
+	assert.Regexp(t, `^golangci_golangci-worker-[0-9a-f]{16}$`, k)
+	assert.Equal(t, k, Key("golangci/golangci-worker", "golangci-lint has version 1.12.2"))
+	assert.NotEqual(t, k, Key("golangci/golangci-worker", "golangci-lint has version 1.12.3"))
+}
+
+func TestToEvict(t *testing.T) {
+	byRecency := []string{"a", "b", "c", "d"}
+	assert.Equal(t, []string{"b", "d"}, toEvict(byRecency, []string{"b"}, 3))
+	assert.Empty(t, toEvict(byRecency, nil, 0))
+	assert.Equal(t, []string{"c", "d"}, toEvict(byRecency, []string{"d"}, 2))
+}
+
+func TestEvict(t *testing.T) {
+	root, err := ioutil.TempDir("", "lintcache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	old := time.Now().Add(-48 * time.Hour)
+	for i, name := range []string{"old", "recent1", "recent2", "recent3"} {
+		dir := filepath.Join(root, name)
+		assert.NoError(t, os.Mkdir(dir, os.ModePerm))
+
+		mtime := time.Now().Add(-time.Duration(i) * time.Minute)
+		if name == "old" {
+			mtime = old
+		}
+		assert.NoError(t, os.Chtimes(dir, mtime, mtime))
+	}
+
+	exec, err := executors.NewTempDirShell("test.lintcache")
+	assert.NoError(t, err)
+	defer exec.Clean()
+
+	c := New(root, 24*time.Hour, 2)
+	assert.NoError(t, c.Evict(context.Background(), exec))
+
+	files, err := ioutil.ReadDir(root)
+	assert.NoError(t, err)
+	var left []string
+	for _, f := range files {
+		left = append(left, f.Name())
+	}
+	assert.Equal(t, []string{"recent1", "recent2"}, left)
+}
@@ -0,0 +1,136 @@
+package lintdocs
+
+// bundledIndex is a docs index of linters run by golangci-lint, keys are linter names
+var bundledIndex = map[string]linterDocs{
+	"govet": {
+		url:         "https://golang.org/cmd/vet/",
+		explanation: "Vet reports suspicious constructs which are likely bugs, e.g. Printf calls with wrong arguments.",
+	},
+	"errcheck": {
+		url:         "https://github.com/kisielk/errcheck",
+		explanation: "Unchecked errors hide failures: handle the error or explicitly assign it to _.",
+	},
+	"golint": {
+		url:         "https://github.com/golang/lint",
+		explanation: "The code doesn't follow Go style conventions from Effective Go and CodeReviewComments.",
+	},
+	"ineffassign": {
+		url:         "https://github.com/gordonklaus/ineffassign",
+		explanation: "The assigned value is never used: it's often a lost error check or a typo.",
+	},
+	"deadcode": {
+		url:         "https://github.com/remyoudompheng/go-misc/tree/master/deadcode",
+		explanation: "Unused code increases maintenance cost: remove it.",
+	},
+	"varcheck": {
+		url:         "https://github.com/opennota/check",
+		explanation: "Unused global variables and constants increase maintenance cost: remove them.",
+	},
+	"structcheck": {
+		url:         "https://github.com/opennota/check",
+		explanation: "Unused struct fields increase memory usage and maintenance cost: remove them.",
+	},
+	"unused": {
+		url:         "https://staticcheck.io/docs/checks#U1000",
+		explanation: "Unused code increases maintenance cost: remove it.",
+	},
+	"unparam": {
+		url:         "https://github.com/mvdan/unparam",
+		explanation: "The function parameter or result is always the same or unused: simplify the signature.",
+	},
+	"gocyclo": {
+		url:         "https://github.com/fzipp/gocyclo",
+		explanation: "Functions with high cyclomatic complexity are hard to test and understand: split them.",
+	},
+	"misspell": {
+		url:         "https://github.com/client9/misspell",
+		explanation: "Commonly misspelled English word.",
+	},
+	"maligned": {
+		url:         "https://github.com/mdempsky/maligned",
+		explanation: "Reordering struct fields reduces padding and memory usage.",
+	},
+	"goconst": {
+		url:         "https://github.com/jgautheron/goconst",
+		explanation: "Repeated strings could be replaced by a constant.",
+	},
+	"gofmt": {
+		url:         "https://golang.org/cmd/gofmt/",
+		explanation: "The file isn't formatted by gofmt: run gofmt -s -w on it.",
+	},
+	"goimports": {
+		url:         "https://godoc.org/golang.org/x/tools/cmd/goimports",
+		explanation: "Imports aren't formatted by goimports: run goimports -w on the file.",
+	},
+	"dupl": {
+		url:         "https://github.com/mibk/dupl",
+		explanation: "Duplicated code must be changed in all copies: extract it into a function.",
+	},
+	"lll": {
+		url:         "https://github.com/walle/lll",
+		explanation: "Long lines are hard to read and review.",
+	},
+	"nakedret": {
+		url:         "https://github.com/alexkohler/nakedret",
+		explanation: "Naked returns in long functions make it hard to see what is returned.",
+	},
+	"prealloc": {
+		url:         "https://github.com/alexkohler/prealloc",
+		explanation: "Preallocating the slice avoids reallocations while appending.",
+	},
+	"scopelint": {
+		url:         "https://github.com/kyoh86/scopelint",
+		explanation: "The loop variable is captured by reference: all closures see its last value.",
+	},
+	"gocritic": {
+		url: "https://go-critic.github.io/overview",
+	},
+	"staticcheck": {
+		url:         "https://staticcheck.io/docs/checks",
+		explanation: "The code is likely incorrect or has a performance issue.",
+		ruleURL:     staticcheckURL,
+		rules: map[string]string{
+			"SA1019": "The identifier is deprecated: use the recommended alternative.",
+			"SA4006": "The assigned value is never read: it's often a lost error check.",
+			"SA5001": "Close is deferred before checking the error: it panics on the nil value.",
+			"SA9003": "Empty branch: it's either unfinished or dead code.",
+		},
+	},
+	"gosimple": {
+		url:         "https://staticcheck.io/docs/checks",
+		explanation: "The code can be simplified without changing its behavior.",
+		ruleURL:     staticcheckURL,
+	},
+	"stylecheck": {
+		url:         "https://staticcheck.io/docs/checks",
+		explanation: "The code doesn't follow Go style conventions.",
+		ruleURL:     staticcheckURL,
+		rules: map[string]string{
+			"ST1003": "Names must follow Go naming conventions, e.g. ID instead of Id.",
+			"ST1005": "Error strings shouldn't be capitalized or end with punctuation: they are usually wrapped.",
+		},
+	},
+	"gosec": {
+		url:         "https://github.com/securego/gosec",
+		explanation: "The code may have a security problem.",
+		ruleURL:     gosecURL,
+		rules: map[string]string{
+			"G101": "Hardcoded credentials leak with the source code: load them from the environment.",
+			"G102": "Binding to all network interfaces exposes the service to the network.",
+			"G103": "Using package unsafe bypasses memory safety of Go.",
+			"G104": "Unhandled errors hide failures.",
+			"G107": "HTTP request to a variable URL may be abused to send requests on behalf of the server.",
+			"G201": "SQL query built by string formatting is open to SQL injections: use query parameters.",
+			"G202": "SQL query built by string concatenation is open to SQL injections: use query parameters.",
+			"G204": "Running a command with variable arguments is open to command injections.",
+			"G301": "Directory is created with too broad permissions.",
+			"G302": "File permissions are too broad.",
+			"G304": "File path provided as taint input allows reading arbitrary files.",
+			"G401": "MD5 and SHA1 are weak cryptographic primitives.",
+			"G402": "TLS settings are insecure, e.g. certificate verification is disabled.",
+			"G404": "math/rand is predictable: use crypto/rand for security-sensitive values.",
+			"G501": "crypto/md5 is a weak cryptographic primitive.",
+			"G505": "crypto/sha1 is a weak cryptographic primitive.",
+		},
+	},
+}
@@ -0,0 +1,72 @@
+package lintdocs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Doc is a documentation of a linter rule shown with issues
+type Doc struct {
+	URL string
+
+	// Explanation is a one-line explanation of why the issue matters, it can be empty
+	Explanation string
+}
+
+type linterDocs struct {
+	url         string
+	explanation string // for issues without a rule or with an unknown rule
+
+	// ruleURL returns a doc URL for the rule, the linter url is used if it's nil
+	ruleURL func(rule string) string
+
+	rules map[string]string // rule -> explanation
+}
+
+// Registry finds documentation of issues by the linter and the rule (e.g. gosec and G104)
+type Registry struct {
+	linters map[string]linterDocs
+}
+
+var defaultRegistry = &Registry{linters: bundledIndex}
+
+// Default returns the registry of the bundled linters docs index
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Lookup returns a doc for the issue, the rule can be empty
+func (r *Registry) Lookup(linter, rule string) (Doc, bool) {
+	if r == nil {
+		return Doc{}, false
+	}
+
+	ld, ok := r.linters[linter]
+	if !ok {
+		return Doc{}, false
+	}
+
+	ret := Doc{
+		URL:         ld.url,
+		Explanation: ld.explanation,
+	}
+	if rule == "" {
+		return ret, true
+	}
+
+	if ld.ruleURL != nil {
+		ret.URL = ld.ruleURL(rule)
+	}
+	if e, ok := ld.rules[rule]; ok {
+		ret.Explanation = e
+	}
+	return ret, true
+}
+
+func staticcheckURL(rule string) string {
+	return "https://staticcheck.io/docs/checks#" + rule
+}
+
+func gosecURL(rule string) string {
+	return fmt.Sprintf("https://securego.io/docs/rules/%s.html", strings.ToLower(rule))
+}
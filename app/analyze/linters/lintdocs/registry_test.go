@@ -0,0 +1,30 @@
+package lintdocs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	r := Default()
+
+	doc, ok := r.Lookup("gosec", "G104")
+	assert.True(t, ok)
+	assert.Equal(t, Doc{URL: "https://securego.io/docs/rules/g104.html", Explanation: "Unhandled errors hide failures."}, doc)
+
+	doc, ok = r.Lookup("staticcheck", "SA4000") // unknown rule
+	assert.True(t, ok)
+	assert.Equal(t, "https://staticcheck.io/docs/checks#SA4000", doc.URL)
+	assert.Equal(t, bundledIndex["staticcheck"].explanation, doc.Explanation)
+
+	doc, ok = r.Lookup("errcheck", "")
+	assert.True(t, ok)
+	assert.Equal(t, "https://github.com/kisielk/errcheck", doc.URL)
+
+	_, ok = r.Lookup("unknown", "")
+	assert.False(t, ok)
+
+	_, ok = (*Registry)(nil).Lookup("errcheck", "")
+	assert.False(t, ok)
+}
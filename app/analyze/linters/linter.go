@@ -13,3 +13,11 @@ type Linter interface {
 	Run(ctx context.Context, exec executors.Executor) (*result.Result, error)
 	Name() string
 }
+
+// StreamingLinter emits issues to onIssue as it finds them instead of only returning them after the run.
+// It emits every issue of the returned result. If onIssue returns an error the linter stops and returns
+// issues passed to onIssue until then including the last one, the error isn't returned.
+type StreamingLinter interface {
+	Linter
+	RunStreaming(ctx context.Context, exec executors.Executor, onIssue IssueFunc) (*result.Result, error)
+}
@@ -1,6 +1,7 @@
 // Code generated by MockGen. DO NOT EDIT.
 // Source: linter.go
 
+// Package linters is a generated GoMock package.
 package linters
 
 import (
@@ -30,31 +31,92 @@ func NewMockLinter(ctrl *gomock.Controller) *MockLinter {
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use
-func (_m *MockLinter) EXPECT() *MockLinterMockRecorder {
-	return _m.recorder
+func (m *MockLinter) EXPECT() *MockLinterMockRecorder {
+	return m.recorder
 }
 
 // Run mocks base method
-func (_m *MockLinter) Run(ctx context.Context, exec executors.Executor) (*result.Result, error) {
-	ret := _m.ctrl.Call(_m, "Run", ctx, exec)
+func (m *MockLinter) Run(ctx context.Context, exec executors.Executor) (*result.Result, error) {
+	ret := m.ctrl.Call(m, "Run", ctx, exec)
 	ret0, _ := ret[0].(*result.Result)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Run indicates an expected call of Run
-func (_mr *MockLinterMockRecorder) Run(arg0, arg1 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "Run", reflect.TypeOf((*MockLinter)(nil).Run), arg0, arg1)
+func (mr *MockLinterMockRecorder) Run(ctx, exec interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockLinter)(nil).Run), ctx, exec)
 }
 
 // Name mocks base method
-func (_m *MockLinter) Name() string {
-	ret := _m.ctrl.Call(_m, "Name")
+func (m *MockLinter) Name() string {
+	ret := m.ctrl.Call(m, "Name")
 	ret0, _ := ret[0].(string)
 	return ret0
 }
 
 // Name indicates an expected call of Name
-func (_mr *MockLinterMockRecorder) Name() *gomock.Call {
-	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "Name", reflect.TypeOf((*MockLinter)(nil).Name))
+func (mr *MockLinterMockRecorder) Name() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockLinter)(nil).Name))
+}
+
+// MockStreamingLinter is a mock of StreamingLinter interface
+type MockStreamingLinter struct {
+	ctrl     *gomock.Controller
+	recorder *MockStreamingLinterMockRecorder
+}
+
+// MockStreamingLinterMockRecorder is the mock recorder for MockStreamingLinter
+type MockStreamingLinterMockRecorder struct {
+	mock *MockStreamingLinter
+}
+
+// NewMockStreamingLinter creates a new mock instance
+func NewMockStreamingLinter(ctrl *gomock.Controller) *MockStreamingLinter {
+	mock := &MockStreamingLinter{ctrl: ctrl}
+	mock.recorder = &MockStreamingLinterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStreamingLinter) EXPECT() *MockStreamingLinterMockRecorder {
+	return m.recorder
+}
+
+// Run mocks base method
+func (m *MockStreamingLinter) Run(ctx context.Context, exec executors.Executor) (*result.Result, error) {
+	ret := m.ctrl.Call(m, "Run", ctx, exec)
+	ret0, _ := ret[0].(*result.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Run indicates an expected call of Run
+func (mr *MockStreamingLinterMockRecorder) Run(ctx, exec interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockStreamingLinter)(nil).Run), ctx, exec)
+}
+
+// Name mocks base method
+func (m *MockStreamingLinter) Name() string {
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name
+func (mr *MockStreamingLinterMockRecorder) Name() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockStreamingLinter)(nil).Name))
+}
+
+// RunStreaming mocks base method
+func (m *MockStreamingLinter) RunStreaming(ctx context.Context, exec executors.Executor, onIssue IssueFunc) (*result.Result, error) {
+	ret := m.ctrl.Call(m, "RunStreaming", ctx, exec, onIssue)
+	ret0, _ := ret[0].(*result.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunStreaming indicates an expected call of RunStreaming
+func (mr *MockStreamingLinterMockRecorder) RunStreaming(ctx, exec, onIssue interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunStreaming", reflect.TypeOf((*MockStreamingLinter)(nil).RunStreaming), ctx, exec, onIssue)
 }
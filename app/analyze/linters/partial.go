@@ -0,0 +1,30 @@
+package linters
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+)
+
+// PartialResultsFunc receives issues of every finished linter run while the analysis is running,
+// it can be called concurrently by runs of different packages
+type PartialResultsFunc func(issues []result.Issue)
+
+type partialResultsKeyType string
+
+const partialResultsKey partialResultsKeyType = "partial results"
+
+func ContextWithPartialResults(ctx context.Context, f PartialResultsFunc) context.Context {
+	return context.WithValue(ctx, partialResultsKey, f)
+}
+
+func reportPartialResults(ctx context.Context, issues []result.Issue) {
+	if f, ok := ctx.Value(partialResultsKey).(PartialResultsFunc); ok && len(issues) != 0 {
+		f(issues)
+	}
+}
+
+func hasPartialResults(ctx context.Context) bool {
+	_, ok := ctx.Value(partialResultsKey).(PartialResultsFunc)
+	return ok
+}
@@ -0,0 +1,33 @@
+package result
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+)
+
+// Fingerprints returns fingerprints of issues: they identify issues regardless of their lines,
+// so they are stable when code above the issue is changed or a PR is force-pushed.
+// Same issues in the same file are distinguished by their order.
+func Fingerprints(issues []Issue) []string {
+	order := make([]int, len(issues))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return issues[order[a]].LineNumber < issues[order[b]].LineNumber
+	})
+
+	ret := make([]string, len(issues))
+	occurrences := map[string]int{}
+	for _, ind := range order {
+		i := issues[ind]
+		key := fmt.Sprintf("%s\x00%s\x00%s", i.FromLinter, i.File, i.Text)
+		h := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d", key, occurrences[key])))
+		occurrences[key]++
+		ret[ind] = hex.EncodeToString(h[:8])
+	}
+
+	return ret
+}
@@ -0,0 +1,25 @@
+package result
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprints(t *testing.T) {
+	issues := []Issue{
+		{FromLinter: "errcheck", File: "a.go", LineNumber: 20, Text: "unchecked"},
+		{FromLinter: "errcheck", File: "a.go", LineNumber: 10, Text: "unchecked"},
+		{FromLinter: "golint", File: "a.go", LineNumber: 10, Text: "bad name"},
+	}
+	fps := Fingerprints(issues)
+	assert.Len(t, fps, 3)
+	assert.NotEqual(t, fps[0], fps[1]) // same text, distinguished by order
+
+	// lines are shifted by a change above
+	shifted := []Issue{issues[2], issues[1], issues[0]}
+	for i := range shifted {
+		shifted[i].LineNumber += 5
+	}
+	assert.Equal(t, []string{fps[2], fps[1], fps[0]}, Fingerprints(shifted))
+}
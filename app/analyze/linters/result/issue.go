@@ -6,6 +6,43 @@ type Issue struct {
 	File       string
 	LineNumber int
 	HunkPos    int
+
+	// Rule is a check of the linter reported the issue (e.g. G104 of gosec), it's empty if linter doesn't have rules
+	Rule string `json:",omitempty"`
+
+	// Commit is a SHA of the PR commit introduced the issue, it's set only if commits attribution is enabled
+	Commit string `json:",omitempty"`
+
+	// Informational issues are only shown in results: they aren't commented and don't fail statuses
+	Informational bool `json:",omitempty"`
+
+	// DeletedLine is a line of the base file the comment is anchored to if the issue is caused by a deletion
+	DeletedLine int `json:",omitempty"`
+
+	// LineRange is set if the issue spans several lines since LineNumber, e.g. lines of Replacement
+	LineRange *Range `json:",omitempty"`
+
+	// Replacement is a fix of the issue: it replaces the line or lines of LineRange
+	Replacement *Replacement `json:",omitempty"`
+
+	// Related are other locations of the issue, e.g. the other copy of duplicated code
+	Related []Location `json:",omitempty"`
+}
+
+// Range is an inclusive range of lines
+type Range struct {
+	From, To int
+}
+
+// Location is a range of lines of a file
+type Location struct {
+	File  string
+	Lines Range
+}
+
+// Replacement is a fix of an issue, reporters can post it as a suggestion
+type Replacement struct {
+	NewLines []string
 }
 
 func NewIssue(fromLinter, text, file string, lineNumber, hunkPos int) Issue {
@@ -4,4 +4,13 @@ type Result struct {
 	Issues           []Issue
 	MaxIssuesPerFile int // Needed for gofmt and goimports where it is 1
 	ResultJSON       interface{}
+
+	// TimedOutLinters are names of linters canceled by the timeout of the runner: the result is partial
+	TimedOutLinters []string
+
+	// Capped is set if the run was stopped after the max count of issues: the result is partial
+	Capped bool
+
+	// FailedLinters are names of optional linters which failed without failing the run: the result is partial
+	FailedLinters []string `json:",omitempty"`
 }
@@ -2,31 +2,121 @@ package linters
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/golangci/golangci-worker/app/analytics"
 	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
 	"github.com/golangci/golangci-worker/app/lib/executors"
 )
 
+// errLinterTimedOut differs from context.DeadlineExceeded: linters return it on the timeout of the analysis
+var errLinterTimedOut = errors.New("linter timed out")
+
 type Runner interface {
 	Run(ctx context.Context, linters []Linter, exec executors.Executor) (*result.Result, error)
+
+	// RunStreaming is Run emitting issues to onIssue while linters are running: issues of streaming
+	// linters are emitted as they are found, issues of other linters after they finished.
+	// Issues emitted by timed out linters aren't in the result.
+	RunStreaming(ctx context.Context, linters []Linter, exec executors.Executor, onIssue IssueFunc) (*result.Result, error)
 }
 
 type SimpleRunner struct {
+	// LinterTimeout limits every linter: only the timed out linter is canceled, results of others
+	// are returned. Zero means no limit.
+	LinterTimeout time.Duration
+
+	// MaxIssues stops the run after this count of issues: the result is partial and Capped is set.
+	// Zero means no limit.
+	MaxIssues int
 }
 
 func (r SimpleRunner) Run(ctx context.Context, linters []Linter, exec executors.Executor) (*result.Result, error) {
+	return r.RunStreaming(ctx, linters, exec, nil)
+}
+
+func (r SimpleRunner) RunStreaming(ctx context.Context, linters []Linter, exec executors.Executor,
+	onIssue IssueFunc) (*result.Result, error) {
+
+	e := &issueEmitter{onIssue: onIssue, maxIssues: r.MaxIssues}
 	results := []result.Result{}
+	var timedOut []string
 	for _, linter := range linters {
-		res, err := linter.Run(ctx, exec)
+		res, err := r.runLinter(ctx, linter, exec, e)
+		if err == errLinterTimedOut {
+			analytics.Log(ctx).Warnf("Linter %s timed out after %s, continue with other linters", linter.Name(), r.LinterTimeout)
+			timedOut = append(timedOut, linter.Name())
+			continue
+		}
 		if err != nil {
 			return nil, err // don't wrap error here, need to save original error
 		}
+		if err = e.failure(); err != nil {
+			return nil, err
+		}
 
 		results = append(results, *res)
+		if e.stopped() {
+			analytics.Log(ctx).Infof("Linters run is stopped after %d issues by %s", e.emitted, linter.Name())
+			break
+		}
+	}
+
+	if len(results) == 0 && len(timedOut) != 0 {
+		return nil, errorutils.ResourceLimit(fmt.Errorf("all linters timed out after %s", r.LinterTimeout),
+			"all linters timed out")
+	}
+
+	ret := r.mergeResults(results)
+	if ret != nil {
+		ret.TimedOutLinters = timedOut
+		ret.Capped = e.capped
 	}
+	return ret, nil
+}
+
+// runLinter returns errLinterTimedOut if the linter exceeded its own timeout
+func (r SimpleRunner) runLinter(ctx context.Context, linter Linter, exec executors.Executor, e *issueEmitter) (*result.Result, error) {
+	if r.LinterTimeout == 0 {
+		return r.runEmitting(ctx, linter, exec, e)
+	}
+
+	linterCtx, cancel := context.WithTimeout(ctx, r.LinterTimeout)
+	defer cancel()
 
-	return r.mergeResults(results), nil
+	res, err := r.runEmitting(linterCtx, linter, exec, e)
+	if err != nil && linterCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		return nil, errLinterTimedOut
+	}
+
+	return res, err
+}
+
+// runEmitting streams issues of streaming linters if the run needs them early: there is onIssue, the cap of issues
+// or partial results. Streaming linters can be slower or return less detailed results when streaming (e.g. the
+// stopped golangci-lint run has no report), so they are run as usual otherwise.
+func (r SimpleRunner) runEmitting(ctx context.Context, linter Linter, exec executors.Executor, e *issueEmitter) (*result.Result, error) {
+	if sl, ok := linter.(StreamingLinter); ok && (e.onIssue != nil || e.maxIssues != 0 || hasPartialResults(ctx)) {
+		return sl.RunStreaming(ctx, exec, func(issue result.Issue) error {
+			err := e.emit(issue)
+			if err == nil || err == ErrStopRun {
+				reportPartialResults(ctx, []result.Issue{issue})
+			}
+			return err
+		})
+	}
+
+	res, err := linter.Run(ctx, exec)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Issues = e.emitAll(res.Issues)
+	reportPartialResults(ctx, res.Issues)
+	return res, nil
 }
 
 func (r SimpleRunner) mergeResults(results []result.Result) *result.Result {
@@ -34,10 +124,14 @@ func (r SimpleRunner) mergeResults(results []result.Result) *result.Result {
 		return nil
 	}
 
-	if len(results) > 1 {
-		log.Fatalf("len(results) can't be more than 1: %+v", results)
+	ret := results[0] // golangci-lint is the first: its json is the result json
+	for _, res := range results[1:] {
+		ret.Issues = append(ret.Issues, res.Issues...)
+		ret.FailedLinters = append(ret.FailedLinters, res.FailedLinters...)
+		if ret.ResultJSON == nil {
+			ret.ResultJSON = res.ResultJSON
+		}
 	}
 
-	// TODO: support for multiple linters, not only golangci-lint
-	return &results[0]
+	return &ret
 }
@@ -0,0 +1,166 @@
+package linters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSlowLinter(ctrl *gomock.Controller, name string) Linter {
+	l := NewMockLinter(ctrl)
+	l.EXPECT().Name().Return(name).AnyTimes()
+	l.EXPECT().Run(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ executors.Executor) (*result.Result, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	return l
+}
+
+func TestSimpleRunnerLinterTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fast := NewMockLinter(ctrl)
+	fast.EXPECT().Name().Return("fast").AnyTimes()
+	fast.EXPECT().Run(gomock.Any(), gomock.Any()).Return(&result.Result{
+		Issues:     []result.Issue{{FromLinter: "fast", Text: "issue"}},
+		ResultJSON: "json",
+	}, nil)
+
+	r := SimpleRunner{LinterTimeout: 10 * time.Millisecond}
+	res, err := r.Run(context.Background(), []Linter{newSlowLinter(ctrl, "slow"), fast}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"slow"}, res.TimedOutLinters)
+	assert.Equal(t, "json", res.ResultJSON)
+	assert.Len(t, res.Issues, 1)
+
+	_, err = r.Run(context.Background(), []Linter{newSlowLinter(ctrl, "slow")}, nil)
+	assert.Equal(t, errorutils.KindResourceLimit, errorutils.KindOf(err))
+}
+
+func TestSimpleRunnerParentTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// the analysis timed out, not the linter: it's a failure of the whole analysis
+	r := SimpleRunner{LinterTimeout: time.Minute}
+	_, err := r.Run(ctx, []Linter{newSlowLinter(ctrl, "slow")}, nil)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestSimpleRunnerPartialResults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var partial [][]result.Issue
+	ctx := ContextWithPartialResults(context.Background(), func(issues []result.Issue) {
+		partial = append(partial, issues)
+	})
+
+	var lintersList []Linter
+	for _, name := range []string{"a", "clean", "b"} {
+		l := NewMockLinter(ctrl)
+		res := &result.Result{}
+		if name != "clean" {
+			res.Issues = []result.Issue{{FromLinter: name}}
+		}
+		l.EXPECT().Run(ctx, gomock.Any()).Return(res, nil)
+		lintersList = append(lintersList, l)
+	}
+
+	res, err := SimpleRunner{}.Run(ctx, lintersList, nil)
+	assert.NoError(t, err)
+	assert.Len(t, res.Issues, 2)
+	assert.Equal(t, [][]result.Issue{{{FromLinter: "a"}}, {{FromLinter: "b"}}}, partial, "linters without issues aren't reported")
+}
+
+func TestSimpleRunnerStreaming(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	streaming := NewMockStreamingLinter(ctrl)
+	streaming.EXPECT().RunStreaming(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ executors.Executor, onIssue IssueFunc) (*result.Result, error) {
+			issue := result.Issue{FromLinter: "streaming"}
+			assert.NoError(t, onIssue(issue))
+			return &result.Result{Issues: []result.Issue{issue}}, nil
+		})
+
+	plain := NewMockLinter(ctrl)
+	plain.EXPECT().Run(gomock.Any(), gomock.Any()).Return(&result.Result{Issues: []result.Issue{{FromLinter: "plain"}}}, nil)
+
+	var emitted []string
+	res, err := SimpleRunner{}.RunStreaming(context.Background(), []Linter{streaming, plain}, nil, func(issue result.Issue) error {
+		emitted = append(emitted, issue.FromLinter)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"streaming", "plain"}, emitted)
+	assert.Len(t, res.Issues, 2)
+	assert.False(t, res.Capped)
+}
+
+func TestSimpleRunnerDoesntStreamWithoutConsumers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the strict mock fails the test on RunStreaming: nothing needs issues before the run finished
+	streaming := NewMockStreamingLinter(ctrl)
+	streaming.EXPECT().Run(gomock.Any(), gomock.Any()).Return(&result.Result{Issues: []result.Issue{{FromLinter: "streaming"}}}, nil)
+
+	res, err := SimpleRunner{}.Run(context.Background(), []Linter{streaming}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, res.Issues, 1)
+}
+
+func TestSimpleRunnerMaxIssues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	first := NewMockLinter(ctrl)
+	first.EXPECT().Name().Return("first").AnyTimes()
+	first.EXPECT().Run(gomock.Any(), gomock.Any()).Return(&result.Result{
+		Issues: []result.Issue{{Text: "1"}, {Text: "2"}, {Text: "3"}},
+	}, nil)
+	second := NewMockLinter(ctrl) // isn't run: the cap is hit
+
+	res, err := SimpleRunner{MaxIssues: 2}.Run(context.Background(), []Linter{first, second}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []result.Issue{{Text: "1"}, {Text: "2"}}, res.Issues)
+	assert.True(t, res.Capped)
+}
+
+func TestSimpleRunnerStopAndFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newLinter := func() Linter {
+		l := NewMockLinter(ctrl)
+		l.EXPECT().Name().Return("l").AnyTimes()
+		l.EXPECT().Run(gomock.Any(), gomock.Any()).Return(&result.Result{Issues: []result.Issue{{Text: "1"}, {Text: "2"}}}, nil)
+		return l
+	}
+
+	res, err := SimpleRunner{}.RunStreaming(context.Background(), []Linter{newLinter()}, nil, func(result.Issue) error {
+		return ErrStopRun
+	})
+	assert.NoError(t, err)
+	assert.Len(t, res.Issues, 1)
+	assert.False(t, res.Capped, "the run is stopped by the caller")
+
+	failure := errors.New("can't publish issue")
+	_, err = SimpleRunner{}.RunStreaming(context.Background(), []Linter{newLinter()}, nil, func(result.Issue) error {
+		return failure
+	})
+	assert.Equal(t, failure, err)
+}
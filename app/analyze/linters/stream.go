@@ -0,0 +1,81 @@
+package linters
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+)
+
+// ErrStopRun is returned by an IssueFunc to stop the run successfully: the result has issues emitted until then
+var ErrStopRun = errors.New("linters run is stopped")
+
+// IssueFunc receives issues while linters are running, other errors than ErrStopRun fail the run
+type IssueFunc func(issue result.Issue) error
+
+// issueEmitter passes issues of all linters of a run to the IssueFunc and caps them
+type issueEmitter struct {
+	onIssue   IssueFunc
+	maxIssues int // zero means no limit
+
+	mu      sync.Mutex
+	emitted int
+	capped  bool
+	err     error // ErrStopRun if the run is stopped
+}
+
+func (e *issueEmitter) emit(issue result.Issue) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.err != nil {
+		return e.err
+	}
+
+	e.emitted++
+	if e.onIssue != nil {
+		if err := e.onIssue(issue); err != nil {
+			e.err = err
+			return err
+		}
+	}
+
+	if e.maxIssues != 0 && e.emitted >= e.maxIssues {
+		e.capped = true
+		e.err = ErrStopRun
+		return e.err
+	}
+
+	return nil
+}
+
+// emitAll emits issues of a linter which doesn't stream them, it returns emitted issues
+func (e *issueEmitter) emitAll(issues []result.Issue) []result.Issue {
+	for i, issue := range issues {
+		if e.stopped() {
+			return issues[:i]
+		}
+		if err := e.emit(issue); err != nil {
+			return issues[:i+1]
+		}
+	}
+
+	return issues
+}
+
+func (e *issueEmitter) stopped() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.err != nil
+}
+
+// failure returns the error of the IssueFunc failing the run
+func (e *issueEmitter) failure() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.err == ErrStopRun {
+		return nil
+	}
+	return e.err
+}
@@ -0,0 +1,40 @@
+// Package orgfeed sends compact events of finished analyses to the activity feed of organizations on the API:
+// organizations see analyses of all their repos in real time. Events are batched and delivered in the background,
+// analyses never wait for the feed and failures of the feed don't fail analyses.
+package orgfeed
+
+import "time"
+
+const (
+	KindPR   = "pr"
+	KindRepo = "repo"
+)
+
+// Event is a finished analysis
+type Event struct {
+	Repo         string // owner/name
+	PullRequest  int    `json:",omitempty"`
+	Kind         string // pr or repo
+	AnalysisGUID string
+	Status       string // e.g. success, failure or error
+
+	IssuesCount int
+
+	// NewIssues and FixedIssues are the delta of issues since the previous analysis of the pull request,
+	// they are zero for repo analyses
+	NewIssues   int `json:",omitempty"`
+	FixedIssues int `json:",omitempty"`
+
+	DurationSeconds int
+	FinishedAt      time.Time
+}
+
+// Publisher accepts events for the delivery, Publish never blocks
+type Publisher interface {
+	Publish(owner string, e Event)
+}
+
+// NopPublisher drops events, e.g. of local analyses
+type NopPublisher struct{}
+
+func (NopPublisher) Publish(owner string, e Event) {}
@@ -0,0 +1,233 @@
+package orgfeed
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golangci/golangci-shared/pkg/config"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+)
+
+const (
+	defaultBatchSize     = 50
+	defaultFlushInterval = 5 * time.Second
+	defaultMaxAttempts   = 5
+
+	// maxPendingEvents bounds events of an organization waiting for the delivery while the API is unavailable
+	maxPendingEvents = 1000
+
+	// finalFlushTimeout limits the flush of pending events after the feed is stopped
+	finalFlushTimeout = 30 * time.Second
+)
+
+// Feed batches events by organizations and sends them in the background: a batch is sent when it's full
+// or by the flush interval. Failed batches are retried by next flushes with exponential backoff,
+// they are dropped after maxAttempts failures.
+type Feed struct {
+	sender        Sender
+	batchSize     int
+	flushInterval time.Duration
+	maxAttempts   int
+	log           logutil.Log
+
+	mu   sync.Mutex
+	orgs map[string]*orgQueue
+	now  func() time.Time
+
+	// full wakes up Run to send a full batch before the flush interval
+	full chan struct{}
+
+	// flushMu keeps the order of events: only one flush sends them
+	flushMu sync.Mutex
+}
+
+type orgQueue struct {
+	events []Event
+
+	// failures is a count of failed attempts to send the first batch
+	failures int
+	retryAt  time.Time
+}
+
+var _ Publisher = &Feed{}
+
+// NewFeed returns the feed sending events by sender, non-positive settings are replaced by defaults
+func NewFeed(sender Sender, batchSize int, flushInterval time.Duration, maxAttempts int, log logutil.Log) *Feed {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	return &Feed{
+		sender:        sender,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		maxAttempts:   maxAttempts,
+		log:           log,
+		orgs:          map[string]*orgQueue{},
+		now:           time.Now,
+		full:          make(chan struct{}, 1),
+	}
+}
+
+func (f *Feed) Publish(owner string, e Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	q := f.orgs[owner]
+	if q == nil {
+		q = &orgQueue{}
+		f.orgs[owner] = q
+	}
+
+	if len(q.events) >= maxPendingEvents {
+		// pending events can be being sent: drop the new one
+		f.log.Warnf("Drop feed event of analysis %s of %s: %d events are pending", e.AnalysisGUID, e.Repo, len(q.events))
+		return
+	}
+
+	q.events = append(q.events, e)
+
+	if len(q.events) >= f.batchSize {
+		select {
+		case f.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run flushes events until ctx is done, pending events are flushed once more then
+func (f *Feed) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), finalFlushTimeout)
+			f.Flush(flushCtx)
+			cancel()
+			return
+		case <-ticker.C:
+		case <-f.full:
+		}
+
+		f.Flush(ctx)
+	}
+}
+
+// Flush sends pending events by batches, organizations waiting for a retry are skipped
+func (f *Feed) Flush(ctx context.Context) {
+	f.flushMu.Lock()
+	defer f.flushMu.Unlock()
+
+	for _, owner := range f.readyOrgs() {
+		for f.sendBatch(ctx, owner) {
+		}
+	}
+}
+
+func (f *Feed) readyOrgs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	var ret []string
+	for owner, q := range f.orgs {
+		if len(q.events) != 0 && !now.Before(q.retryAt) {
+			ret = append(ret, owner)
+		}
+	}
+
+	sort.Strings(ret)
+	return ret
+}
+
+// sendBatch sends the first batch of the organization, it returns true if more events can be sent
+func (f *Feed) sendBatch(ctx context.Context, owner string) bool {
+	f.mu.Lock()
+	q := f.orgs[owner]
+	n := len(q.events)
+	if n > f.batchSize {
+		n = f.batchSize
+	}
+	batch := append([]Event{}, q.events[:n]...)
+	f.mu.Unlock()
+
+	if len(batch) == 0 {
+		return false
+	}
+
+	err := f.sender.Send(ctx, owner, batch)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err != nil {
+		q.failures++
+		if q.failures < f.maxAttempts {
+			q.retryAt = f.now().Add(f.flushInterval << uint(q.failures))
+			f.log.Infof("Can't send %d feed events of %s, retry at %s: %s", len(batch), owner, q.retryAt, err)
+			return false
+		}
+
+		f.log.Warnf("Drop %d feed events of %s after %d failed attempts: %s", len(batch), owner, q.failures, err)
+	}
+
+	q.events = q.events[len(batch):]
+	q.failures = 0
+	if len(q.events) == 0 {
+		delete(f.orgs, owner) // don't keep queues of all organizations ever analyzed
+		return false
+	}
+
+	return err == nil
+}
+
+var defaultFeed Publisher
+var defaultFeedOnce sync.Once
+
+// Default returns the feed of the API, ORG_FEED_DISABLED=1 disables it. Events are sent only while
+// the feed is run by RunDefault.
+func Default() Publisher {
+	defaultFeedOnce.Do(func() {
+		log := logutil.NewStderrLog("orgfeed")
+		cfg := config.NewEnvConfig(log)
+		if cfg.GetBool("ORG_FEED_DISABLED", false) {
+			defaultFeed = NopPublisher{}
+			return
+		}
+
+		f := NewFeed(NewAPISender(httputils.GrequestsClient{}), cfg.GetInt("ORG_FEED_BATCH_SIZE", defaultBatchSize),
+			cfg.GetDuration("ORG_FEED_FLUSH_INTERVAL", defaultFlushInterval),
+			cfg.GetInt("ORG_FEED_MAX_ATTEMPTS", defaultMaxAttempts), log)
+		defaultFeed = f
+	})
+
+	return defaultFeed
+}
+
+// RunDefault runs the default feed in the background until ctx is done: the returned channel is closed
+// after the final flush of pending events, the worker must wait for it before exit
+func RunDefault(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	f, ok := Default().(*Feed)
+	if !ok {
+		close(done)
+		return done
+	}
+
+	go func() {
+		defer close(done)
+		f.Run(ctx)
+	}()
+	return done
+}
@@ -0,0 +1,130 @@
+package orgfeed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var any = gomock.Any()
+
+func newTestFeed(sender Sender, batchSize, maxAttempts int) (*Feed, *time.Time) {
+	f := NewFeed(sender, batchSize, time.Second, maxAttempts, logutil.NewStderrLog("test"))
+	now := time.Date(2018, 12, 1, 10, 0, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+	return f, &now
+}
+
+func testEvent(i int) Event {
+	return Event{Repo: "org/repo", Kind: KindPR, PullRequest: i, AnalysisGUID: fmt.Sprintf("guid-%d", i)}
+}
+
+func TestFlushSendsBatchesByOrgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sender := NewMockSender(ctrl)
+	f, _ := newTestFeed(sender, 2, 3)
+	for i := 0; i < 3; i++ {
+		f.Publish("org", testEvent(i))
+	}
+	f.Publish("other", testEvent(10))
+
+	gomock.InOrder(
+		sender.EXPECT().Send(any, "org", []Event{testEvent(0), testEvent(1)}).Return(nil),
+		sender.EXPECT().Send(any, "org", []Event{testEvent(2)}).Return(nil),
+	)
+	sender.EXPECT().Send(any, "other", []Event{testEvent(10)}).Return(nil)
+	f.Flush(context.Background())
+
+	f.Flush(context.Background()) // nothing is pending
+	assert.Empty(t, f.orgs)
+}
+
+func TestFlushRetriesWithBackoff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sender := NewMockSender(ctrl)
+	f, now := newTestFeed(sender, 10, 3)
+	f.Publish("org", testEvent(1))
+
+	sender.EXPECT().Send(any, "org", []Event{testEvent(1)}).Return(errors.New("api is down"))
+	f.Flush(context.Background())
+
+	f.Publish("org", testEvent(2))
+	*now = now.Add(time.Second)
+	f.Flush(context.Background()) // waits 2s for the retry
+
+	*now = now.Add(time.Second)
+	sender.EXPECT().Send(any, "org", []Event{testEvent(1), testEvent(2)}).Return(nil)
+	f.Flush(context.Background())
+	assert.Empty(t, f.orgs)
+}
+
+func TestFlushDropsAfterMaxAttempts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sender := NewMockSender(ctrl)
+	f, now := newTestFeed(sender, 1, 2)
+	f.Publish("org", testEvent(1))
+	f.Publish("org", testEvent(2))
+
+	sender.EXPECT().Send(any, "org", []Event{testEvent(1)}).Times(2).Return(errors.New("bad request"))
+	f.Flush(context.Background())
+	*now = now.Add(time.Minute)
+	f.Flush(context.Background())
+
+	sender.EXPECT().Send(any, "org", []Event{testEvent(2)}).Return(nil)
+	f.Flush(context.Background())
+	assert.Empty(t, f.orgs)
+}
+
+func TestPublishBoundsPendingEvents(t *testing.T) {
+	f, _ := newTestFeed(nil, 10, 3)
+	for i := 0; i < maxPendingEvents+5; i++ {
+		f.Publish("org", testEvent(i))
+	}
+
+	assert.Len(t, f.orgs["org"].events, maxPendingEvents)
+	assert.Equal(t, testEvent(0), f.orgs["org"].events[0], "old events are kept")
+}
+
+func TestRunSendsFullBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sent := make(chan []Event, 1)
+	sender := NewMockSender(ctrl)
+	sender.EXPECT().Send(any, "org", any).DoAndReturn(func(_ context.Context, _ string, events []Event) error {
+		sent <- events
+		return nil
+	})
+
+	f := NewFeed(sender, 2, time.Hour, 1, logutil.NewStderrLog("test"))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		f.Run(ctx)
+		close(done)
+	}()
+
+	f.Publish("org", testEvent(1))
+	f.Publish("org", testEvent(2))
+	select {
+	case events := <-sent:
+		assert.Equal(t, []Event{testEvent(1), testEvent(2)}, events)
+	case <-time.After(5 * time.Second):
+		t.Fatal("full batch wasn't sent before the flush interval")
+	}
+
+	cancel()
+	<-done
+}
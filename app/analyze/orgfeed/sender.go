@@ -0,0 +1,33 @@
+package orgfeed
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/lib/apiclient"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+)
+
+//go:generate mockgen -package orgfeed -source sender.go -destination sender_mock.go
+
+// Sender delivers a batch of events of the organization
+type Sender interface {
+	Send(ctx context.Context, owner string, events []Event) error
+}
+
+type APISender struct {
+	api *apiclient.Client
+}
+
+func NewAPISender(client httputils.Client) *APISender {
+	return &APISender{
+		api: apiclient.New(client),
+	}
+}
+
+type eventsRequest struct {
+	Events []Event
+}
+
+func (s APISender) Send(ctx context.Context, owner string, events []Event) error {
+	return s.api.PostOrgFeedEvents(ctx, owner, eventsRequest{Events: events})
+}
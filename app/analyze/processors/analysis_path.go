@@ -0,0 +1,154 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+)
+
+// cleanAnalysisPath returns the analysis path relative to the repo root without trailing slashes,
+// it's empty for the whole repo. Paths outside of the repo and paths starting with "-" (they would be
+// options of commands) are bad input.
+func cleanAnalysisPath(p string) (string, error) {
+	if p == "" {
+		return "", nil
+	}
+
+	cleaned := path.Clean(strings.Trim(p, "/"))
+	if cleaned == "." {
+		return "", nil
+	}
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") || strings.HasPrefix(p, "/") || strings.HasPrefix(cleaned, "-") {
+		return "", &errorutils.BadInputError{
+			PublicDesc: fmt.Sprintf("invalid analysis path %q: it must be a subdirectory of the repo", p),
+		}
+	}
+
+	return cleaned, nil
+}
+
+// checkAnalysisPath checks the path is a directory of the cloned repo
+func checkAnalysisPath(ctx context.Context, exec executors.Executor, dir string) error {
+	// the path is the first arg of find: "./" keeps it a path even if it looks like an expression, e.g. -delete
+	out, err := executors.Unrestricted(exec).Run(ctx, "find", "./"+dir, "-maxdepth", "0", "-type", "d")
+	if err != nil || strings.TrimSpace(out) == "" { // find fails for missing paths and prints nothing for files
+		return &errorutils.BadInputError{
+			PublicDesc: fmt.Sprintf("analysis path %s isn't a directory of the repo", dir),
+		}
+	}
+
+	return nil
+}
+
+func isInPath(file, dir string) bool {
+	return dir == "" || strings.HasPrefix(file, dir+"/")
+}
+
+// scopePatchToPath drops diffs of files outside of dir: golangci-lint reports issues only in the rest.
+// The patch is empty if no files were changed in dir.
+func scopePatchToPath(patch, dir string) string {
+	if dir == "" {
+		return patch
+	}
+
+	var ret []string
+	for _, fileDiff := range splitPatchByFiles(patch) {
+		for _, f := range getPatchFiles(fileDiff) {
+			if isInPath(f, dir) {
+				ret = append(ret, fileDiff)
+				break
+			}
+		}
+	}
+
+	return strings.Join(ret, "")
+}
+
+// splitPatchByFiles splits the diff into diffs of files, a concatenation of them is the patch.
+// Diffs start by "diff --git" headers or by "---" lines followed by "+++" lines for plain unified diffs.
+func splitPatchByFiles(patch string) []string {
+	lines := strings.SplitAfter(patch, "\n")
+
+	var ret []string
+	start, pos := 0, 0
+	inGitHeader := false
+	for i, line := range lines {
+		newFile := false
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			newFile, inGitHeader = true, true
+		case strings.HasPrefix(line, "@@ "):
+			inGitHeader = false
+		case strings.HasPrefix(line, "--- ") && !inGitHeader:
+			newFile = i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ")
+		}
+
+		if newFile && pos != start {
+			ret = append(ret, patch[start:pos])
+			start = pos
+		}
+		pos += len(line)
+	}
+	if start != len(patch) {
+		ret = append(ret, patch[start:])
+	}
+
+	return ret
+}
+
+// sparsePathsWithAnalysisPath adds the analysis path to the sparse checkout of the repo config:
+// the repo is cloned fully if sparse checkout isn't configured
+func sparsePathsWithAnalysisPath(sparsePaths []string, dir string) []string {
+	if dir == "" || len(sparsePaths) == 0 {
+		return sparsePaths
+	}
+
+	for _, p := range sparsePaths {
+		if p == dir || isInPath(dir, strings.Trim(p, "/")) {
+			return sparsePaths
+		}
+	}
+
+	return append(append([]string{}, sparsePaths...), dir)
+}
+
+// withAnalysisPath makes golangci-lint analyze only packages of dir
+func withAnalysisPath(lintersList []linters.Linter, dir string) []linters.Linter {
+	if dir == "" {
+		return lintersList
+	}
+
+	return withPackages(lintersList, projectPackages(dir))
+}
+
+func withoutOutsidePath(dir string, issues []result.Issue) []result.Issue {
+	if dir == "" {
+		return issues
+	}
+
+	var ret []result.Issue
+	for _, i := range issues {
+		if isInPath(i.File, dir) {
+			ret = append(ret, i)
+		}
+	}
+
+	return ret
+}
+
+// filterOutsidePath drops issues of files outside of dir, e.g. found by linters of the whole repo
+func filterOutsidePath(ctx context.Context, dir string, issues []result.Issue, eventName analytics.EventName) []result.Issue {
+	ret := withoutOutsidePath(dir, issues)
+	if dropped := len(issues) - len(ret); dropped != 0 {
+		analytics.Log(ctx).Infof("Dropped %d issues outside of analysis path %s", dropped, dir)
+		analytics.SaveEventProp(ctx, eventName, "issuesOutsidePath", dropped)
+	}
+	return ret
+}
@@ -0,0 +1,101 @@
+package processors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanAnalysisPath(t *testing.T) {
+	for in, want := range map[string]string{"": "", ".": "", "backend": "backend", "backend/": "backend",
+		"./backend/api/": "backend/api", "backend/../api": "api"} {
+		got, err := cleanAnalysisPath(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"..", "../backend", "backend/../..", "/backend", "-delete", "./-delete"} {
+		_, err := cleanAnalysisPath(in)
+		assert.IsType(t, &errorutils.BadInputError{}, err, in)
+	}
+}
+
+const twoFilesPatch = `diff --git a/backend/main.go b/backend/main.go
+index 1..2 100644
+--- a/backend/main.go
++++ b/backend/main.go
+@@ -1 +1 @@
+-package main
++package main // changed
+diff --git a/frontend/main.go b/frontend/main.go
+index 1..2 100644
+--- a/frontend/main.go
++++ b/frontend/main.go
+@@ -1 +1 @@
+-package main
++package main // changed
+`
+
+func TestScopePatchToPath(t *testing.T) {
+	assert.Equal(t, twoFilesPatch, scopePatchToPath(twoFilesPatch, ""))
+
+	scoped := scopePatchToPath(twoFilesPatch, "backend")
+	assert.Equal(t, []string{"backend/main.go"}, getPatchFiles(scoped))
+	assert.Contains(t, scoped, "+package main // changed\n")
+
+	assert.Empty(t, scopePatchToPath(twoFilesPatch, "back"), "prefix of a dir isn't the dir")
+	assert.Empty(t, scopePatchToPath(twoFilesPatch, "docs"))
+
+	plain := "--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-x\n+y\n--- a/backend/x.go\n+++ b/backend/x.go\n@@ -1 +1 @@\n-x\n+y\n"
+	assert.Equal(t, "--- a/backend/x.go\n+++ b/backend/x.go\n@@ -1 +1 @@\n-x\n+y\n", scopePatchToPath(plain, "backend"))
+}
+
+func TestSparsePathsWithAnalysisPath(t *testing.T) {
+	assert.Nil(t, sparsePathsWithAnalysisPath(nil, "backend"), "full clone")
+	assert.Equal(t, []string{"go.mod", "backend"}, sparsePathsWithAnalysisPath([]string{"go.mod"}, "backend"))
+	assert.Equal(t, []string{"backend/"}, sparsePathsWithAnalysisPath([]string{"backend/"}, "backend/api"))
+}
+
+func TestWithAnalysisPath(t *testing.T) {
+	ls := withAnalysisPath([]linters.Linter{golinters.GolangciLint{}}, "backend")
+	assert.Equal(t, []linters.Linter{golinters.GolangciLint{Packages: []string{"./backend/..."}}}, ls)
+}
+
+func TestFilterOutsidePath(t *testing.T) {
+	issues := []result.Issue{{File: "backend/main.go"}, {File: "backend.go"}, {File: "frontend/main.go"}}
+	assert.Equal(t, issues, filterOutsidePath(testCtx, "", issues, analytics.EventPRChecked))
+	assert.Equal(t, issues[:1], filterOutsidePath(testCtx, "backend", issues, analytics.EventPRChecked))
+}
+
+func TestCheckAnalysisPathInRestrictedExecutor(t *testing.T) {
+	shell, err := executors.NewTempDirShell("analysis-path")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer shell.Clean()
+	assert.NoError(t, os.MkdirAll(filepath.Join(shell.WorkDir(), "backend", "api"), 0700))
+	f, err := os.Create(filepath.Join(shell.WorkDir(), "main.go"))
+	assert.NoError(t, err)
+	f.Close()
+
+	defer os.Setenv("EXECUTOR_COMMANDS_MODE", os.Getenv("EXECUTOR_COMMANDS_MODE"))
+	os.Setenv("EXECUTOR_COMMANDS_MODE", "allowlist")
+	exec := executors.RestrictedFromEnv(shell)
+
+	ctx := context.Background()
+	assert.NoError(t, checkAnalysisPath(ctx, exec, "backend/api"))
+	for _, dir := range []string{"frontend", "main.go", "-delete"} {
+		assert.IsType(t, &errorutils.BadInputError{}, checkAnalysisPath(ctx, exec, dir), dir)
+	}
+	_, err = os.Stat(filepath.Join(shell.WorkDir(), "main.go"))
+	assert.NoError(t, err, "find mustn't run expressions of paths")
+}
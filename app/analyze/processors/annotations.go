@@ -0,0 +1,27 @@
+package processors
+
+import (
+	"context"
+	"os"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/reporters"
+)
+
+const annotationsFormatGithubActions = "github-actions"
+
+// buildAnnotations formats issues for the CI the worker runs in, it's set by ANNOTATIONS_FORMAT.
+// Annotations aren't built if the format isn't set.
+func buildAnnotations(ctx context.Context, issues []result.Issue) []string {
+	if os.Getenv("ANNOTATIONS_FORMAT") != annotationsFormatGithubActions {
+		return nil
+	}
+
+	annotations := reporters.FormatActionsAnnotations(issues)
+	for _, a := range annotations {
+		analytics.Log(ctx).Infof("Annotation: %s", a)
+	}
+
+	return annotations
+}
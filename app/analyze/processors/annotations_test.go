@@ -0,0 +1,19 @@
+package processors
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildAnnotations(t *testing.T) {
+	issues := []result.Issue{fakeChangedIssue}
+	assert.Nil(t, buildAnnotations(context.Background(), issues), "format isn't set")
+
+	os.Setenv("ANNOTATIONS_FORMAT", annotationsFormatGithubActions)
+	defer os.Unsetenv("ANNOTATIONS_FORMAT")
+	assert.Equal(t, []string{"::error file=main.go,line=10,title=linter2::F1 issue"}, buildAnnotations(context.Background(), issues))
+}
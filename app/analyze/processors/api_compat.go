@@ -0,0 +1,96 @@
+package processors
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/diffanchor"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+)
+
+// apiPackages returns changed packages which can be imported by other modules:
+// only changes of non-test go files can break their API
+func apiPackages(files []string) []string {
+	var goFiles []string
+	for _, f := range files {
+		if strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+
+		importable := true
+		for _, elem := range strings.Split(path.Dir(f), "/") {
+			if elem == "internal" || elem == "vendor" || elem == "testdata" {
+				importable = false
+				break
+			}
+		}
+		if importable {
+			goFiles = append(goFiles, f)
+		}
+	}
+
+	return changedPackages(goFiles)
+}
+
+// anchorIssues sets hunk positions of issues found by the worker itself:
+// issues out of the diff are kept, they are shown only on the analysis page
+func anchorIssues(patch string, issues []result.Issue) ([]result.Issue, error) {
+	p, err := diffanchor.Parse(patch)
+	if err != nil {
+		return issues, err
+	}
+
+	for ind := range issues {
+		i := &issues[ind]
+		var loc diffanchor.Location
+		var found bool
+		if i.DeletedLine > 0 {
+			loc, found = p.LocateDeleted(i.File, i.DeletedLine)
+		} else if i.LineNumber > 0 {
+			loc, found = p.Locate(i.File, i.LineNumber)
+		}
+		if found {
+			i.HunkPos = loc.Line.Position
+		}
+	}
+
+	return issues, nil
+}
+
+// appendAPICompatIssues appends breaking changes of the exported API since the merge-base to res:
+// the check is skipped on failures, but found issues fail the status as issues of other linters
+func (g *githubGoPR) appendAPICompatIssues(ctx context.Context, res *result.Result) {
+	pkgs := apiPackages(getPatchFiles(g.patch))
+	if len(pkgs) == 0 {
+		return
+	}
+
+	base := g.mergeBase
+	if base == "" {
+		var err error
+		if base, err = g.findMergeBase(ctx); err != nil {
+			g.publicWarn("api compatibility", g.msg.Sprintf(i18n.WarnAPIBaseNotFound))
+			analytics.Log(ctx).Warnf("Can't find merge-base to check API compatibility: %s", err)
+			return
+		}
+	}
+
+	apiRes, err := golinters.APICompat{Base: base, Packages: pkgs}.Run(ctx, g.exec)
+	if err != nil {
+		g.publicWarn("api compatibility", g.msg.Sprintf(i18n.WarnAPICompareFailed))
+		analytics.Log(ctx).Warnf("Failed to check API compatibility: %s", err)
+		return
+	}
+
+	issues, err := anchorIssues(g.patch, apiRes.Issues)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't anchor API compatibility issues to the patch: %s", err)
+	}
+
+	analytics.SaveEventProp(ctx, analytics.EventPRChecked, "apiBreakingChanges", len(issues))
+	res.Issues = append(res.Issues, issues...)
+}
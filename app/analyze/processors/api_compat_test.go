@@ -0,0 +1,37 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIPackages(t *testing.T) {
+	files := []string{"client.go", "pkg/a/a.go", "pkg/a/a_test.go", "pkg/b/b_test.go",
+		"internal/x/x.go", "pkg/internal/y.go", "vendor/dep/dep.go", "pkg/a/testdata/t.go", "go.mod"}
+	assert.Equal(t, []string{".", "./pkg/a"}, apiPackages(files))
+}
+
+const testAPIPatch = `diff --git a/client/client.go b/client/client.go
+--- a/client/client.go
++++ b/client/client.go
+@@ -10,4 +10,3 @@ import "context"
+
+-const Timeout = 10
+-func (c *Client) Do(s string) error {
++func (c *Client) Do(ctx context.Context, s string) error {
+ 	return nil
+`
+
+func TestAnchorIssues(t *testing.T) {
+	issues := []result.Issue{
+		{File: "client/client.go", LineNumber: 11},
+		{File: "client/client.go", DeletedLine: 11},
+		{File: "client/other.go", LineNumber: 3},
+	}
+
+	anchored, err := anchorIssues(testAPIPatch, issues)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{4, 2, 0}, []int{anchored[0].HunkPos, anchored[1].HunkPos, anchored[2].HunkPos})
+}
@@ -0,0 +1,237 @@
+package processors
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	goenvresult "github.com/golangci/golangci-api/pkg/goenv/result"
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/depgraph"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/sbom"
+	"github.com/golangci/golangci-worker/app/lib/artifacts"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/redact"
+)
+
+const (
+	// profiles are written out of the repo as the patch: they mustn't be analyzed or dirty the clone
+	cpuProfilePath = "../cpu.pprof"
+	memProfilePath = "../mem.pprof"
+)
+
+// analysisArtifacts are outputs of the analysis saved as artifacts, nil ones aren't saved
+type analysisArtifacts struct {
+	lintRes  *result.Result
+	buildLog *goenvresult.Log
+	depGraph *depgraph.Graph
+	bom      *sbom.Document
+	outputs  *lintOutputs
+}
+
+// lintOutputs are artifacts of the lint stage which are read from the work dir
+type lintOutputs struct {
+	profiles map[string][]byte // by names of artifacts
+	fixPatch string
+}
+
+// saveArtifacts saves lint json, build log, the dependency graph, the SBOM, profiles and the fix patch
+// of the analysis: texts are redacted as all public texts. It must be called once per analysis,
+// when it's finalized.
+func saveArtifacts(ctx context.Context, m *artifacts.Manager, analysisGUID string, private bool,
+	a analysisArtifacts, secrets map[string]string) []artifacts.Artifact {
+
+	rd := redact.New(secrets)
+	var ret []artifacts.Artifact
+	saveRaw := func(kind artifacts.Kind, name string, data []byte) {
+		if saved := m.Save(ctx, analysisGUID, private, kind, name, data); saved != nil {
+			ret = append(ret, *saved)
+		}
+	}
+	save := func(kind artifacts.Kind, name string, v interface{}) {
+		if !m.IsEnabled(kind) {
+			return
+		}
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			analytics.Log(ctx).Warnf("Failed to marshal artifact %s: %s", name, err)
+			return
+		}
+
+		saveRaw(kind, name, []byte(rd.Redact(string(data))))
+	}
+
+	if a.lintRes != nil && a.lintRes.ResultJSON != nil {
+		save(artifacts.KindLintJSON, "lint.json", a.lintRes.ResultJSON)
+	}
+	if a.buildLog != nil {
+		save(artifacts.KindBuildLog, "build_log.json", a.buildLog)
+	}
+	if a.depGraph != nil {
+		save(artifacts.KindDependencyGraph, "dependency_graph.json", a.depGraph)
+	}
+	if a.bom != nil {
+		save(artifacts.KindSBOM, a.bom.FileName(), a.bom.Data)
+	}
+	if out := a.outputs; out != nil {
+		var names []string
+		for name := range out.profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			saveRaw(artifacts.KindProfile, name, out.profiles[name]) // binary pprof can't be redacted
+		}
+
+		if out.fixPatch != "" {
+			saveRaw(artifacts.KindFixPatch, "fixes.patch", []byte(rd.Redact(out.fixPatch)))
+		}
+	}
+
+	return ret
+}
+
+// withProfiles makes golangci-lint write its CPU and memory profiles if they are saved
+func withProfiles(lintersList []linters.Linter, m *artifacts.Manager) []linters.Linter {
+	if !m.IsEnabled(artifacts.KindProfile) {
+		return lintersList
+	}
+
+	ret := make([]linters.Linter, 0, len(lintersList))
+	for _, l := range lintersList {
+		if gl, ok := l.(golinters.GolangciLint); ok {
+			gl.CPUProfilePath, gl.MemProfilePath = cpuProfilePath, memProfilePath
+			l = gl
+		}
+		ret = append(ret, l)
+	}
+
+	return ret
+}
+
+// collectLintOutputs reads profiles and builds the fix patch of the lint stage if they are saved:
+// they must be collected before the work dir is cleaned
+func collectLintOutputs(ctx context.Context, exec executors.Executor, m *artifacts.Manager,
+	res *result.Result) *lintOutputs {
+
+	ret := &lintOutputs{profiles: map[string][]byte{}}
+	exec = executors.Unrestricted(exec)
+
+	if m.IsEnabled(artifacts.KindProfile) {
+		for name, p := range map[string]string{"cpu.pprof": cpuProfilePath, "mem.pprof": memProfilePath} {
+			out, err := exec.Run(ctx, "base64", p)
+			if err != nil {
+				analytics.Log(ctx).Infof("No golangci-lint profile %s: %s", p, err) // e.g. the lint was cached
+				continue
+			}
+
+			data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(out), ""))
+			if err != nil {
+				analytics.Log(ctx).Warnf("Invalid base64 of golangci-lint profile %s: %s", p, err)
+				continue
+			}
+			ret.profiles[name] = data
+		}
+	}
+
+	if m.IsEnabled(artifacts.KindFixPatch) && res != nil {
+		patch, err := buildFixPatch(res.Issues, func(file string) (string, error) {
+			return exec.Run(ctx, "cat", "--", file)
+		})
+		if err != nil {
+			analytics.Log(ctx).Warnf("Can't build the fix patch: %s", err)
+		} else {
+			ret.fixPatch = patch
+		}
+	}
+
+	return ret
+}
+
+// buildFixPatch renders replacements of issues as a unified diff without context lines: it's applied
+// by `git apply --unidiff-zero`. Fixes overlapping previous ones are skipped.
+func buildFixPatch(issues []result.Issue, readFile func(file string) (string, error)) (string, error) {
+	type fix struct {
+		from, to int
+		newLines []string
+	}
+
+	fixesByFile := map[string][]fix{}
+	var files []string
+	for _, i := range issues {
+		if i.Replacement == nil || i.LineNumber <= 0 {
+			continue
+		}
+
+		file := path.Clean(i.File)
+		if path.IsAbs(file) || file == ".." || strings.HasPrefix(file, "../") {
+			continue
+		}
+
+		to := i.LineNumber
+		if i.LineRange != nil && i.LineRange.To > to {
+			to = i.LineRange.To
+		}
+		if _, ok := fixesByFile[file]; !ok {
+			files = append(files, file)
+		}
+		fixesByFile[file] = append(fixesByFile[file], fix{from: i.LineNumber, to: to, newLines: i.Replacement.NewLines})
+	}
+	sort.Strings(files)
+
+	var b strings.Builder
+	for _, file := range files {
+		src, err := readFile(file)
+		if err != nil {
+			return "", fmt.Errorf("can't read %s: %s", file, err)
+		}
+		lines := strings.SplitAfter(src, "\n")
+		if lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+
+		fixes := fixesByFile[file]
+		sort.SliceStable(fixes, func(i, j int) bool { return fixes[i].from < fixes[j].from })
+
+		var hunks strings.Builder
+		lastTo, delta := 0, 0
+		for _, f := range fixes {
+			if f.from <= lastTo || f.to > len(lines) {
+				continue
+			}
+			if f.to == len(lines) && !strings.HasSuffix(lines[f.to-1], "\n") {
+				continue // "\ No newline at end of file" isn't supported
+			}
+			lastTo = f.to
+
+			oldCount, newCount := f.to-f.from+1, len(f.newLines)
+			newFrom := f.from + delta
+			if newCount == 0 {
+				newFrom-- // it's the line before the deletion
+			}
+			fmt.Fprintf(&hunks, "@@ -%d,%d +%d,%d @@\n", f.from, oldCount, newFrom, newCount)
+			for _, l := range lines[f.from-1 : f.to] {
+				hunks.WriteString("-" + l)
+			}
+			for _, l := range f.newLines {
+				hunks.WriteString("+" + l + "\n")
+			}
+			delta += newCount - oldCount
+		}
+
+		if hunks.Len() != 0 {
+			fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n", file, file, file, file)
+			b.WriteString(hunks.String())
+		}
+	}
+
+	return b.String(), nil
+}
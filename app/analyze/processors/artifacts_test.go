@@ -0,0 +1,63 @@
+package processors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/artifacts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildFixPatch(t *testing.T) {
+	files := map[string]string{
+		"a.go": "package a\nvar x=1\nvar y=2\nfunc f() {\n}\n",
+		"b.go": "package b\n",
+	}
+	readFile := func(file string) (string, error) {
+		src, ok := files[file]
+		if !ok {
+			return "", errors.New("no file")
+		}
+		return src, nil
+	}
+
+	issues := []result.Issue{
+		{File: "a.go", LineNumber: 2, LineRange: &result.Range{From: 2, To: 3},
+			Replacement: &result.Replacement{NewLines: []string{"var (", "\tx = 1", "\ty = 2", ")"}}},
+		{File: "a.go", LineNumber: 3, Replacement: &result.Replacement{NewLines: []string{"var y = 2"}}}, // overlaps
+		{File: "a.go", LineNumber: 5, Replacement: &result.Replacement{}},                                // deletion
+		{File: "b.go", LineNumber: 1, Text: "no fix"},
+		{File: "../c.go", LineNumber: 1, Replacement: &result.Replacement{NewLines: []string{"x"}}},
+	}
+
+	patch, err := buildFixPatch(issues, readFile)
+	assert.NoError(t, err)
+	assert.Equal(t, `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -2,2 +2,4 @@
+-var x=1
+-var y=2
++var (
++	x = 1
++	y = 2
++)
+@@ -5,1 +6,0 @@
+-}
+`, patch)
+
+	_, err = buildFixPatch([]result.Issue{{File: "d.go", LineNumber: 1, Replacement: &result.Replacement{}}}, readFile)
+	assert.Error(t, err)
+}
+
+func TestWithProfiles(t *testing.T) {
+	lintersList := []linters.Linter{golinters.GolangciLint{}}
+	assert.Equal(t, lintersList, withProfiles(lintersList, artifacts.Disabled()))
+
+	gl := withProfiles(lintersList, artifacts.NewManager(nil, 0, nil))[0].(golinters.GolangciLint)
+	assert.Equal(t, cpuProfilePath, gl.CPUProfilePath)
+	assert.Equal(t, memProfilePath, gl.MemProfilePath)
+}
@@ -0,0 +1,71 @@
+package processors
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/resultdiff"
+)
+
+// baselineKey identifies an issue regardless of its line: lines of unchanged code move in pull requests
+type baselineKey struct {
+	file, linter, text string
+}
+
+// baseline is a count of issues of the last analysis of the base branch by keys
+type baseline map[baselineKey]int
+
+func newBaseline(issues []result.Issue) baseline {
+	ret := baseline{}
+	for _, i := range withoutInformational(issues) {
+		ret[baselineKey{file: i.File, linter: i.FromLinter, text: i.Text}]++
+	}
+
+	return ret
+}
+
+// preExisting returns how many of issues the base branch already has: every issue of the base branch
+// matches at most one issue, so a copy of an existing issue is new
+func (b baseline) preExisting(issues []result.Issue) int {
+	left := baseline{}
+	for k, n := range b {
+		left[k] = n
+	}
+
+	ret := 0
+	for _, i := range issues {
+		k := baselineKey{file: i.File, linter: i.FromLinter, text: i.Text}
+		if left[k] > 0 {
+			left[k]--
+			ret++
+		}
+	}
+
+	return ret
+}
+
+// fetchBaseline loads issues of the last analysis of the base branch: the status tells how many issues
+// the pull request adds. The baseline stays nil if the base branch wasn't analyzed, e.g. for the first push
+// to a new repo, or on errors of the storage: the status shows the count of all issues then.
+func (g *githubGoPR) fetchBaseline(ctx context.Context) error {
+	repo := &g.context.Repo
+	branch := g.pr.GetBase().GetRef()
+	state, err := g.baselines.GetLastState(ctx, repo.Owner, repo.Name, branch)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't get the last analysis of the base branch %s: %s", branch, err)
+		return nil
+	}
+	if state == nil || state.Status != statusProcessed {
+		return nil
+	}
+
+	a, err := resultdiff.FromRepoState(branch, state)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't parse the last analysis of the base branch: %s", err)
+		return nil
+	}
+
+	g.baseline = newBaseline(a.Result.Issues())
+	return nil
+}
@@ -0,0 +1,68 @@
+package processors
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/usage"
+)
+
+const (
+	usageKindPR   = "pr"
+	usageKindRepo = "repo"
+)
+
+// changedPackages returns packages of changed go files, e.g. ./pkg/x
+func changedPackages(files []string) []string {
+	var ret []string
+	seen := map[string]bool{}
+	for _, f := range files {
+		if !strings.HasSuffix(f, ".go") {
+			continue
+		}
+
+		pkg := packageArg(path.Dir(f))
+		if !seen[pkg] {
+			seen[pkg] = true
+			ret = append(ret, pkg)
+		}
+	}
+
+	return ret
+}
+
+// packageArg returns the package of the dir for golangci-lint, e.g. ./pkg/x
+func packageArg(dir string) string {
+	if dir == "." {
+		return "."
+	}
+
+	return "./" + dir
+}
+
+// lintChangedPackages is a partial analysis of an organization exceeded the hard cap of its plan
+func (g *githubGoPR) lintChangedPackages(ctx context.Context, onIssue linters.IssueFunc) (*result.Result, error) {
+	pkgs := changedPackages(getPatchFiles(g.patch))
+	if len(pkgs) == 0 {
+		return &result.Result{}, nil // no packages means all packages for golangci-lint
+	}
+
+	return g.runner.RunStreaming(ctx, withPackages(g.linters, pkgs), g.exec, onIssue)
+}
+
+func (g *githubGoPR) reportUsage(ctx context.Context) {
+	rec := &usage.Record{
+		AnalysisGUID:   g.analysisGUID,
+		Kind:           usageKindPR,
+		ComputeSeconds: int(time.Since(g.startedAt) / time.Second),
+		Partial:        g.plan.IsHardCapExceeded(),
+	}
+	if err := g.usage.Report(ctx, g.context.Repo.Owner, g.context.Repo.Name, rec); err != nil {
+		analytics.Log(ctx).Warnf("Can't report usage %+v: %s", rec, err)
+	}
+}
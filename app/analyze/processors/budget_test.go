@@ -0,0 +1,32 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/usage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangedPackages(t *testing.T) {
+	files := []string{"main.go", "pkg/a/a.go", "pkg/a/b.go", "docs/README.md", "pkg/b/b_test.go"}
+	assert.Equal(t, []string{".", "./pkg/a", "./pkg/b"}, changedPackages(files))
+	assert.Empty(t, changedPackages([]string{"go.mod"}))
+}
+
+func TestUsageReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := &github.FakeContext
+	reporter := usage.NewMockReporter(ctrl)
+	reporter.EXPECT().Report(any, c.Repo.Owner, c.Repo.Name, any).
+		Do(func(_, _, _ interface{}, rec *usage.Record) {
+			assert.Equal(t, testAnalysisGUID, rec.AnalysisGUID)
+			assert.Equal(t, usageKindPR, rec.Kind)
+			assert.False(t, rec.Partial)
+		}).Return(nil)
+
+	testProcessor(t, ctrl, githubGoPRConfig{usage: reporter})
+}
@@ -0,0 +1,99 @@
+package processors
+
+import (
+	"context"
+	"os"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+)
+
+// canaryDelta is a difference of issues of the canary golangci-lint from issues of the stable one
+type canaryDelta struct {
+	added   []result.Issue
+	removed []result.Issue
+}
+
+func withBinary(lintersList []linters.Linter, binary string) []linters.Linter {
+	ret := make([]linters.Linter, 0, len(lintersList))
+	for _, l := range lintersList {
+		if gl, ok := l.(golinters.GolangciLint); ok {
+			gl.Binary = binary
+			gl.Cache = nil // caches are keyed by the version of the stable binary
+			l = gl
+		}
+		ret = append(ret, l)
+	}
+
+	return ret
+}
+
+// withoutInformational returns issues found by linters: e.g. dependencies freshness is found by the worker
+func withoutInformational(issues []result.Issue) []result.Issue {
+	var ret []result.Issue
+	for _, i := range issues {
+		if !i.Informational {
+			ret = append(ret, i)
+		}
+	}
+
+	return ret
+}
+
+// diffIssues compares issues by fingerprints: they don't depend on lines
+func diffIssues(stable, canary []result.Issue) canaryDelta {
+	stableCount := map[string]int{}
+	for _, fp := range result.Fingerprints(stable) {
+		stableCount[fp]++
+	}
+
+	var ret canaryDelta
+	canaryFPs := map[string]bool{}
+	for i, fp := range result.Fingerprints(canary) {
+		canaryFPs[fp] = true
+		if stableCount[fp] == 0 {
+			ret.added = append(ret.added, canary[i])
+		}
+	}
+	for i, fp := range result.Fingerprints(stable) {
+		if !canaryFPs[fp] {
+			ret.removed = append(ret.removed, stable[i])
+		}
+	}
+
+	return ret
+}
+
+// runCanary schedules a shadow run of the canary golangci-lint for a percentage of analyses: its issues
+// aren't reported, only deltas with the stable binary are logged. It never fails the analysis.
+func (g *githubGoPR) runCanary(ctx context.Context) error {
+	binary := os.Getenv("CANARY_GOLANGCI_LINT_BINARY")
+	if binary == "" || len(g.repoCfg.Projects) != 0 || g.plan.IsHardCapExceeded() ||
+		!g.ec.IsActiveForAnalysis(ctx, "golangci_lint_canary", &g.context.Repo, true) {
+		return nil // only full analyses by the same linters are comparable
+	}
+
+	lintersList, exec := withBinary(g.linters, binary), g.exec
+	stable := withoutInformational(g.lintRes.Issues)
+	g.scheduleShadowRun("canary", func(ctx context.Context) {
+		canary, err := g.runner.Run(ctx, lintersList, exec)
+		if err != nil {
+			analytics.Log(ctx).Warnf("Canary golangci-lint %s failed: %s", binary, err)
+			analytics.SaveEventProp(ctx, analytics.EventShadowRun, "failed", true)
+			return
+		}
+
+		delta := diffIssues(stable, canary.Issues)
+		analytics.SaveEventProps(ctx, analytics.EventShadowRun, map[string]interface{}{
+			"canaryAddedIssues":   len(delta.added),
+			"canaryRemovedIssues": len(delta.removed),
+		})
+		if len(delta.added) != 0 || len(delta.removed) != 0 {
+			analytics.Log(ctx).Warnf("Canary golangci-lint %s regression: added issues %+v, removed issues %+v",
+				binary, delta.added, delta.removed)
+		}
+	})
+	return nil
+}
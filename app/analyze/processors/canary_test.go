@@ -0,0 +1,34 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/lintcache"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffIssues(t *testing.T) {
+	kept := result.Issue{FromLinter: "govet", File: "a.go", LineNumber: 3, Text: "kept"}
+	removed := result.Issue{FromLinter: "golint", File: "a.go", LineNumber: 5, Text: "removed"}
+	added := result.Issue{FromLinter: "errcheck", File: "b.go", LineNumber: 1, Text: "added"}
+	moved := kept
+	moved.LineNumber = 4 // fingerprints don't depend on lines
+
+	delta := diffIssues([]result.Issue{kept, removed}, []result.Issue{moved, added})
+	assert.Equal(t, []result.Issue{added}, delta.added)
+	assert.Equal(t, []result.Issue{removed}, delta.removed)
+
+	assert.Empty(t, diffIssues([]result.Issue{kept}, []result.Issue{kept}))
+}
+
+func TestWithBinary(t *testing.T) {
+	lintersList := []linters.Linter{golinters.GolangciLint{PatchPath: patchPath, Cache: &lintcache.Cache{}}}
+	gl := withBinary(lintersList, "golangci-lint-canary")[0].(golinters.GolangciLint)
+	assert.Equal(t, "golangci-lint-canary", gl.Binary)
+	assert.Equal(t, patchPath, gl.PatchPath)
+	assert.Nil(t, gl.Cache)
+	assert.Empty(t, lintersList[0].(golinters.GolangciLint).Binary)
+}
@@ -0,0 +1,53 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/cancellation"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+)
+
+// cancellationCheckInterval is an interval of checks whether the running analysis was cancelled
+var cancellationCheckInterval = 10 * time.Second
+
+// errAnalysisCancelled stops the pipeline: the pull request was closed or merged during the analysis
+var errAnalysisCancelled = errors.New("analysis was cancelled")
+
+// cancelledStage doesn't run stages of cancelled analyses: some stages don't observe the context
+func (g *githubGoPR) cancelledStage(stageName string, next stageFunc) stageFunc {
+	return func(ctx context.Context) error {
+		if g.cancelWatcher.Reason() != "" {
+			return errAnalysisCancelled
+		}
+		return next(ctx)
+	}
+}
+
+// cancelledError replaces an error of the cancelled analysis: errors of interrupted stages aren't reported,
+// the commit status is set to the terminal "pull request is closed" (or merged) status as for analyses
+// of already closed pull requests. The analysis cancelled after all stages succeeded is saved as usual.
+func (g *githubGoPR) cancelledError(ctx context.Context, err error) error {
+	reason := g.cancelWatcher.Reason()
+	if reason == "" || err == nil {
+		return err
+	}
+
+	warning, statusDesc := i18n.WarnPRClosed, i18n.StatusPRClosed
+	if reason == cancellation.ReasonMerged {
+		warning, statusDesc = i18n.WarnPRMerged, i18n.StatusPRMerged
+	}
+	g.publicWarn("process", g.msg.Sprintf(warning))
+	analytics.Log(ctx).Infof("Analysis was cancelled: pull request was %s (%v)", reason, err)
+	analytics.SaveEventProp(ctx, analytics.EventPRChecked, "cancelled", string(reason))
+	g.closeIssueAging(context.Background()) // ctx is cancelled
+
+	return &IgnoredError{
+		Status:        github.StatusSuccess,
+		StatusDesc:    g.msg.Sprintf(statusDesc),
+		IsRecoverable: false,
+	}
+}
@@ -0,0 +1,133 @@
+package processors
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/cancellation"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/reporters"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/fetchers"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/github/githubfake"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancelledDuringLint(t *testing.T) {
+	prevInterval := cancellationCheckInterval
+	cancellationCheckInterval = 10 * time.Millisecond
+	defer func() { cancellationCheckInterval = prevInterval }()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := githubfake.NewServer()
+	defer srv.Close()
+	patch := strings.Replace(getFakePatch(t), "+++ a/", "+++ b/", -1)
+	srv.AddPullRequest("golangci", "repo", testPR, patch)
+	c := srv.Context("golangci", "repo", testPR.GetNumber())
+
+	registry := cancellation.NewMemoryRegistry()
+	l := linters.NewMockLinter(ctrl)
+	l.EXPECT().Run(any, any).DoAndReturn(func(ctx context.Context, exec executors.Executor) (*result.Result, error) {
+		_ = registry.Cancel(ctx, testAnalysisGUID, cancellation.ReasonMerged)
+		<-ctx.Done() // the linter is killed
+		return nil, ctx.Err()
+	})
+
+	client := github.NewMyClient()
+	cfg := githubGoPRConfig{
+		client:        client,
+		linters:       []linters.Linter{l},
+		reporter:      reporters.NewGithubReviewer(c, client, reporters.GithubReviewerOptions{}),
+		cancellations: registry,
+	}
+	fillWithNops(t, ctrl, &cfg)
+
+	p, err := newGithubGoPR(testCtx, c, cfg, testAnalysisGUID)
+	assert.NoError(t, err)
+	assert.NoError(t, p.Process(testCtx))
+
+	statuses := srv.Statuses("golangci", "repo")
+	// the status doesn't stay pending: it's the terminal status of merged pull requests
+	if assert.Len(t, statuses, 2) {
+		assert.Equal(t, github.StatusPending, statuses[0].State)
+		assert.Equal(t, github.StatusSuccess, statuses[1].State)
+		assert.Equal(t, "Pull Request is already merged", statuses[1].Description)
+	}
+	assert.Empty(t, srv.Reviews("golangci", "repo", testPR.GetNumber()))
+}
+
+func TestCancelledAfterReport(t *testing.T) {
+	prevInterval := cancellationCheckInterval
+	cancellationCheckInterval = 10 * time.Millisecond
+	defer func() { cancellationCheckInterval = prevInterval }()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := githubfake.NewServer()
+	defer srv.Close()
+	patch := strings.Replace(getFakePatch(t), "+++ a/", "+++ b/", -1)
+	srv.AddPullRequest("golangci", "repo", testPR, patch)
+	c := srv.Context("golangci", "repo", testPR.GetNumber())
+
+	registry := cancellation.NewMemoryRegistry()
+	reporter := reporters.NewMockReporter(ctrl)
+	reporter.EXPECT().Report(any, any, any).DoAndReturn(func(ctx context.Context, ref string, issues []result.Issue) error {
+		_ = registry.Cancel(ctx, testAnalysisGUID, cancellation.ReasonClosed)
+		<-ctx.Done() // the watcher noticed the cancellation after the report was sent
+		return nil
+	})
+
+	cfg := githubGoPRConfig{
+		client:        github.NewMyClient(),
+		reporter:      reporter,
+		cancellations: registry,
+	}
+	fillWithNops(t, ctrl, &cfg)
+
+	p, err := newGithubGoPR(testCtx, c, cfg, testAnalysisGUID)
+	assert.NoError(t, err)
+	assert.NoError(t, p.Process(testCtx))
+
+	// the successful analysis isn't turned into the cancelled one
+	statuses := srv.Statuses("golangci", "repo")
+	if assert.Len(t, statuses, 2) {
+		assert.NotEqual(t, "Pull Request is already closed", statuses[1].Description)
+		assert.NotEqual(t, github.StatusPending, statuses[1].State)
+	}
+}
+
+func TestCancelledBeforeStart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	registry := cancellation.NewMemoryRegistry()
+	assert.NoError(t, registry.Cancel(testCtx, testAnalysisGUID, cancellation.ReasonClosed))
+
+	// the patch is fetched in the background, nothing else is requested or run
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequestPatch(any, any).AnyTimes().Return("", context.Canceled)
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Clean()
+
+	cfg := githubGoPRConfig{
+		client:        gc,
+		exec:          exec,
+		linters:       []linters.Linter{linters.NewMockLinter(ctrl)},
+		reporter:      reporters.NewMockReporter(ctrl),
+		repoFetcher:   fetchers.NewMockFetcher(ctrl),
+		cancellations: registry,
+	}
+	fillWithNops(t, ctrl, &cfg)
+
+	p, err := newGithubGoPR(testCtx, &github.FakeContext, cfg, testAnalysisGUID)
+	assert.NoError(t, err)
+	assert.NoError(t, p.Process(testCtx))
+}
@@ -0,0 +1,129 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	gh "github.com/google/go-github/github"
+)
+
+// ciGithub serves the patch generated by CI instead of fetching it from GitHub.
+// Builds of pushes have no pull request: it's made from the commit and nothing is commented.
+type ciGithub struct {
+	github.Client
+	t *task.CIAnalysis
+}
+
+var _ github.Client = ciGithub{}
+
+func (c ciGithub) hasPullRequest() bool {
+	return c.t.PullRequestNumber != 0
+}
+
+func (c ciGithub) GetPullRequest(ctx context.Context, gc *github.Context) (*gh.PullRequest, error) {
+	if c.hasPullRequest() {
+		pr, err := c.Client.GetPullRequest(ctx, gc)
+		if err != nil {
+			return nil, err
+		}
+
+		// CI could build not the last commit of the pull request: report to the built one
+		prCopy := *pr
+		head := *pr.GetHead()
+		head.SHA = gh.String(c.t.CommitSHA)
+		prCopy.Head = &head
+		return &prCopy, nil
+	}
+
+	// the token clones the repo regardless of its privacy: only private repos require the repo scope of the token
+	cloneURL := fmt.Sprintf("https://github.com/%s.git", c.t.Repo.FullName())
+	if c.t.GithubAccessToken != "" {
+		cloneURL = fmt.Sprintf("https://%s@github.com/%s.git", c.t.GithubAccessToken, c.t.Repo.FullName())
+	}
+	repo := &gh.Repository{
+		Name:     gh.String(c.t.Repo.Name),
+		FullName: gh.String(c.t.Repo.FullName()),
+		CloneURL: gh.String(cloneURL),
+		Private:  gh.Bool(c.t.Private),
+	}
+	return &gh.PullRequest{
+		State: gh.String("open"),
+		Head: &gh.PullRequestBranch{
+			Ref:  gh.String(c.t.Branch),
+			SHA:  gh.String(c.t.CommitSHA),
+			Repo: repo,
+		},
+		Base: &gh.PullRequestBranch{
+			Repo: repo,
+		},
+	}, nil
+}
+
+func (c ciGithub) GetPullRequestPatch(ctx context.Context, _ *github.Context) (string, error) {
+	return c.t.Patch, nil
+}
+
+func (c ciGithub) GetPullRequestComments(ctx context.Context, gc *github.Context) ([]*github.PullRequestComment, error) {
+	if !c.hasPullRequest() {
+		return nil, nil
+	}
+
+	return c.Client.GetPullRequestComments(ctx, gc)
+}
+
+func (c ciGithub) GetPullRequestReviews(ctx context.Context, gc *github.Context) ([]*github.PullRequestReview, error) {
+	if !c.hasPullRequest() {
+		return nil, nil
+	}
+
+	return c.Client.GetPullRequestReviews(ctx, gc)
+}
+
+func (c ciGithub) CreateReview(ctx context.Context, gc *github.Context, review *github.Review) error {
+	if !c.hasPullRequest() {
+		analytics.Log(ctx).Infof("No pull request for CI build of %s: skip review", c.t.CommitSHA)
+		return nil
+	}
+
+	return c.Client.CreateReview(ctx, gc, review)
+}
+
+func validateCIAnalysis(t *task.CIAnalysis) error {
+	if t.CommitSHA == "" || t.Branch == "" {
+		return &errorutils.BadInputError{
+			PublicDesc: "commit SHA and branch of CI build are required",
+		}
+	}
+
+	return nil
+}
+
+type CIFactory interface {
+	BuildProcessor(ctx context.Context, t *task.CIAnalysis) (Processor, error)
+}
+
+type githubCIFactory struct{}
+
+func NewGithubCIFactory() CIFactory {
+	return githubCIFactory{}
+}
+
+func (gf githubCIFactory) BuildProcessor(ctx context.Context, t *task.CIAnalysis) (Processor, error) {
+	if err := validateCIAnalysis(t); err != nil {
+		return nil, err
+	}
+
+	cfg := githubGoPRConfig{
+		client: ciGithub{Client: github.NewCachingClient(github.NewMyClient(), github.DefaultCache()), t: t},
+	}
+	p, err := newGithubGoPR(ctx, &t.Context, cfg, t.AnalysisGUID)
+	if err != nil {
+		return nil, fmt.Errorf("can't make github go processor for CI build: %s", err)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,81 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	gh "github.com/google/go-github/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCIGithubWithPullRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	ci := &task.CIAnalysis{
+		Context:   github.FakeContext,
+		CommitSHA: "built-sha",
+		Branch:    "feature",
+		Patch:     "ci patch",
+	}
+
+	pr := &gh.PullRequest{Head: &gh.PullRequestBranch{SHA: gh.String("last-sha"), Ref: gh.String("feature")}}
+	client := github.NewMockClient(ctrl)
+	client.EXPECT().GetPullRequest(ctx, &ci.Context).Return(pr, nil)
+	client.EXPECT().CreateReview(ctx, &ci.Context, gomock.Any()).Return(nil)
+
+	c := ciGithub{Client: client, t: ci}
+	gotPR, err := c.GetPullRequest(ctx, &ci.Context)
+	assert.NoError(t, err)
+	assert.Equal(t, "built-sha", gotPR.GetHead().GetSHA())
+	assert.Equal(t, "last-sha", pr.GetHead().GetSHA()) // the original isn't changed
+
+	patch, err := c.GetPullRequestPatch(ctx, &ci.Context)
+	assert.NoError(t, err)
+	assert.Equal(t, "ci patch", patch)
+
+	assert.NoError(t, c.CreateReview(ctx, &ci.Context, &github.Review{}))
+}
+
+func TestCIGithubWithoutPullRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	ci := &task.CIAnalysis{
+		Context:   github.FakeContext,
+		CommitSHA: "built-sha",
+		Branch:    "master",
+	}
+	ci.PullRequestNumber = 0 // build of a push
+
+	c := ciGithub{Client: github.NewMockClient(ctrl), t: ci} // no calls to GitHub but statuses
+	pr, err := c.GetPullRequest(ctx, &ci.Context)
+	assert.NoError(t, err)
+	assert.Equal(t, "master", pr.GetHead().GetRef())
+	assert.Equal(t, "built-sha", pr.GetHead().GetSHA())
+	assert.Equal(t, "https://access_token@github.com/owner/name.git", ci.GetCloneURL(pr.GetHead().GetRepo()))
+	assert.False(t, pr.GetBase().GetRepo().GetPrivate(), "the token doesn't make the repo private")
+
+	ci.Private = true
+	pr, err = c.GetPullRequest(ctx, &ci.Context)
+	assert.NoError(t, err)
+	assert.True(t, pr.GetBase().GetRepo().GetPrivate())
+	assert.Equal(t, "https://access_token@github.com/owner/name.git", ci.GetCloneURL(pr.GetHead().GetRepo()))
+
+	assert.NoError(t, c.CreateReview(ctx, &ci.Context, &github.Review{}))
+}
+
+func TestValidateCIAnalysis(t *testing.T) {
+	err := validateCIAnalysis(&task.CIAnalysis{CommitSHA: "sha"})
+	_, ok := err.(*errorutils.BadInputError)
+	assert.True(t, ok)
+
+	assert.NoError(t, validateCIAnalysis(&task.CIAnalysis{CommitSHA: "sha", Branch: "master"}))
+}
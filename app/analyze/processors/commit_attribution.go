@@ -0,0 +1,187 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/pkg/errors"
+)
+
+// maxAttributedFiles limits count of git blame runs: one run per file
+const maxAttributedFiles = 50
+
+type issueCommit struct {
+	File   string
+	Line   int
+	Commit string
+}
+
+func buildIssueCommits(issues []result.Issue) []issueCommit {
+	var ret []issueCommit
+	for _, i := range issues {
+		if i.Commit != "" {
+			ret = append(ret, issueCommit{
+				File:   i.File,
+				Line:   i.LineNumber,
+				Commit: i.Commit,
+			})
+		}
+	}
+
+	return ret
+}
+
+// findAttributionBase finds the merge-base issues are attributed from: it's done in the preparation,
+// history can't be fetched after the deploy key is stopped
+func (g *githubGoPR) findAttributionBase(ctx context.Context) {
+	if !g.repoCfg.GetAttributeCommits() {
+		return
+	}
+
+	if g.mergeBase != "" { // the history is already fetched by the merge-base scoping
+		g.attributionBase = g.mergeBase
+		return
+	}
+
+	mergeBase, err := g.findMergeBase(ctx)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't find merge-base to attribute issues to commits: %s", err)
+		return
+	}
+	g.attributionBase = mergeBase
+}
+
+func (g *githubGoPR) attributeCommits(ctx context.Context) error {
+	if !g.repoCfg.GetAttributeCommits() {
+		return nil
+	}
+
+	// attribution is optional: never fail the analysis because of it
+	_ = g.trackStep("Attribute commits", func() (string, error) {
+		if g.attributionBase == "" {
+			return "no merge-base: issues aren't attributed", nil
+		}
+
+		n, err := attributeCommits(ctx, g.exec, g.lintRes.Issues, g.attributionBase)
+		if err != nil {
+			analytics.Log(ctx).Warnf("Failed to attribute issues to commits: %s", err)
+			return "", nil
+		}
+
+		return fmt.Sprintf("%d issues attributed to commits", n), nil
+	})
+	return nil
+}
+
+// attributeCommits sets the introducing commit for issues in the diff of the PR by git blame.
+// Commits of the PR are commits since the merge-base: history must be fetched up to it. Commits of the base
+// branch merged into the PR and merge commits aren't commits of the PR.
+func attributeCommits(ctx context.Context, exec executors.Executor, issues []result.Issue, mergeBase string) (int, error) {
+	fileLines := map[string][]int{}
+	for _, i := range issues {
+		if isInDiff(&i) {
+			fileLines[i.File] = append(fileLines[i.File], i.LineNumber)
+		}
+	}
+	if len(fileLines) == 0 {
+		return 0, nil
+	}
+
+	out, err := exec.Run(ctx, "git", "rev-list", "--no-merges", mergeBase+"..HEAD")
+	if err != nil {
+		return 0, errors.Wrapf(err, "can't list PR commits: %s", out)
+	}
+	prSHAs := map[string]bool{}
+	for _, sha := range strings.Fields(out) {
+		prSHAs[sha] = true
+	}
+
+	var files []string
+	for f := range fileLines {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	if len(files) > maxAttributedFiles {
+		files = files[:maxAttributedFiles]
+	}
+
+	lineCommits := map[string]map[int]string{}
+	for _, f := range files {
+		args := []string{"blame", "--porcelain"}
+		for _, line := range fileLines[f] {
+			args = append(args, "-L", fmt.Sprintf("%d,%d", line, line))
+		}
+		args = append(args, "HEAD", "--", f)
+
+		out, err := exec.Run(ctx, "git", args...)
+		if err != nil {
+			analytics.Log(ctx).Warnf("Can't git blame %s: %s, %s", f, err, out)
+			continue
+		}
+		lineCommits[f] = parseBlamePorcelain(out)
+	}
+
+	attributed := 0
+	for idx := range issues {
+		i := &issues[idx]
+		if !isInDiff(i) {
+			continue
+		}
+
+		sha := lineCommits[i.File][i.LineNumber]
+		if sha != "" && prSHAs[sha] { // lines from the base branch aren't attributed
+			i.Commit = sha
+			attributed++
+		}
+	}
+
+	return attributed, nil
+}
+
+// isInDiff returns true for issues which can be introduced by the PR
+func isInDiff(i *result.Issue) bool {
+	return i.HunkPos > 0 && i.LineNumber > 0
+}
+
+// parseBlamePorcelain returns commit SHAs by final line numbers from the output of git blame --porcelain
+func parseBlamePorcelain(out string) map[int]string {
+	ret := map[int]string{}
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "\t") { // content of a line
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 3 || !isSHA(fields[0]) {
+			continue
+		}
+
+		finalLine, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		ret[finalLine] = fields[0]
+	}
+
+	return ret
+}
+
+func isSHA(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+
+	return true
+}
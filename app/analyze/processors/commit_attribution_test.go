@@ -0,0 +1,84 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBlamePorcelain(t *testing.T) {
+	sha1 := strings.Repeat("a", 40)
+	sha2 := strings.Repeat("b", 40)
+	out := sha1 + " 1 3 1\n" +
+		"author x\n" +
+		"filename main.go\n" +
+		"\t" + sha2 + " 1 1 1\n" + // content looking like a header
+		sha2 + " 5 7 1\n" +
+		"previous " + sha1 + " main.go\n" +
+		"\tfunc f() {}\n"
+
+	assert.Equal(t, map[int]string{3: sha1, 7: sha2}, parseBlamePorcelain(out))
+}
+
+func TestAttributeCommits(t *testing.T) {
+	ctx := context.Background()
+	origin, err := executors.NewTempDirShell("origin")
+	assert.NoError(t, err)
+	defer origin.Clean()
+
+	git := func(exec executors.Executor, args ...string) string {
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@golangci.com"}, args...)
+		out, runErr := exec.Run(ctx, "git", args...)
+		assert.NoError(t, runErr, out)
+		return strings.TrimSpace(out)
+	}
+	commit := func(file, content, msg string) string {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(origin.WorkDir(), file), []byte(content), 0644))
+		git(origin, "add", file)
+		git(origin, "commit", "-q", "-m", msg)
+		return git(origin, "rev-parse", "HEAD")
+	}
+
+	git(origin, "init", "-q")
+	commit("main.go", "package main\n\nfunc a() {}\n", "base")
+	git(origin, "branch", "base")
+	git(origin, "checkout", "-q", "-b", "pr")
+	first := commit("main.go", "package main\n\nfunc a() {}\nfunc b() {}\n", "first")
+
+	// the base branch is merged into the PR: its commits and the merge commit aren't commits of the PR
+	git(origin, "checkout", "-q", "base")
+	baseTip := commit("other.go", "package main\n\nfunc o() {}\n", "base tip")
+	git(origin, "checkout", "-q", "pr")
+	git(origin, "merge", "-q", "--no-edit", "base")
+	second := commit("main.go", "package main\n\nfunc a() {}\nfunc b() {}\nfunc c() {}\n", "second")
+
+	clone, err := executors.NewTempDirShell("clone")
+	assert.NoError(t, err)
+	defer clone.Clean()
+	git(clone, "clone", "-q", "--branch", "pr", fmt.Sprintf("file://%s", origin.WorkDir()), ".")
+
+	issues := []result.Issue{
+		{File: "main.go", LineNumber: 3, HunkPos: 1}, // from the base branch
+		{File: "main.go", LineNumber: 4, HunkPos: 2},
+		{File: "main.go", LineNumber: 5, HunkPos: 3},
+		{File: "main.go", LineNumber: 5}, // not in the diff
+		{File: "other.go", LineNumber: 3, HunkPos: 1},
+	}
+	n, err := attributeCommits(ctx, clone, issues, baseTip)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"", first, second, "", ""},
+		[]string{issues[0].Commit, issues[1].Commit, issues[2].Commit, issues[3].Commit, issues[4].Commit})
+
+	assert.Equal(t, []issueCommit{
+		{File: "main.go", Line: 4, Commit: first},
+		{File: "main.go", Line: 5, Commit: second},
+	}, buildIssueCommits(issues))
+}
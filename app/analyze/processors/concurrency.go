@@ -0,0 +1,130 @@
+package processors
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/golangci/golangci-shared/pkg/config"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+)
+
+const defaultMaxParallelProjects = 4
+
+// projectSlots bound project analyses running in parallel by all analyses of the worker
+var projectSlots = make(chan struct{}, maxParallelProjects())
+
+func maxParallelProjects() int {
+	n := config.NewEnvConfig(logutil.NewStderrLog("config")).GetInt("MAX_PARALLEL_PROJECTS", defaultMaxParallelProjects)
+	if n <= 0 {
+		return defaultMaxParallelProjects
+	}
+
+	return n
+}
+
+func withConcurrency(lintersList []linters.Linter, concurrency int) []linters.Linter {
+	ret := make([]linters.Linter, 0, len(lintersList))
+	for _, l := range lintersList {
+		if gl, ok := l.(golinters.GolangciLint); ok {
+			gl.Concurrency = concurrency
+			l = gl
+		}
+		ret = append(ret, l)
+	}
+
+	return ret
+}
+
+// concurrencyPerRun divides CPUs of the executor between parallel runs of linters,
+// it's zero if CPUs count is unknown: golangci-lint uses all CPUs of the host then
+func concurrencyPerRun(cpus, parallelRuns int) int {
+	if cpus == 0 {
+		return 0
+	}
+
+	if parallelRuns > cap(projectSlots) {
+		parallelRuns = cap(projectSlots)
+	}
+	if parallelRuns <= 1 {
+		return cpus
+	}
+
+	if n := cpus / parallelRuns; n > 0 {
+		return n
+	}
+	return 1
+}
+
+const (
+	defaultLintMemoryPerCPUMB = 1024
+
+	// smallMemory makes GC of Go tools more aggressive: OOM kills of golangci-lint look like internal errors
+	smallMemory = int64(4) << 30
+)
+
+// resourceTuning is concurrency of golangci-lint and env of Go tools derived from limits of the executor
+type resourceTuning struct {
+	concurrency int // zero if it's unknown: golangci-lint uses all CPUs of the host then
+	env         map[string]string
+}
+
+// tuneForLimits bounds concurrency by memory too: every package analyzed in parallel needs memoryPerCPU.
+// GOMEMLIMIT isn't set: Go tools of the executor are built by Go 1.11 which ignores it, GOGC works there.
+func tuneForLimits(cpus int, memory, memoryPerCPU int64) resourceTuning {
+	ret := resourceTuning{concurrency: cpus, env: map[string]string{}}
+	if memory != 0 {
+		memCPUs := int(memory / memoryPerCPU)
+		if memCPUs < 1 {
+			memCPUs = 1
+		}
+		if ret.concurrency == 0 || memCPUs < ret.concurrency {
+			ret.concurrency = memCPUs
+		}
+
+		ret.env["GOGC"] = "100"
+		if memory < smallMemory {
+			ret.env["GOGC"] = "50"
+		}
+	}
+	if ret.concurrency != 0 {
+		ret.env["GOMAXPROCS"] = strconv.Itoa(ret.concurrency)
+	}
+
+	return ret
+}
+
+// tuneExecutor sets env of Go tools run by the executor by its CPU and memory limits and returns concurrency
+// of golangci-lint, limits are saved into the analytics event
+func tuneExecutor(ctx context.Context, exec executors.Executor, eventName analytics.EventName) int {
+	cpus := executors.CPULimit(ctx, exec)
+	memory := executors.MemoryLimit(ctx, exec)
+	analytics.SaveEventProp(ctx, eventName, "executorCPUs", cpus)
+	analytics.SaveEventProp(ctx, eventName, "executorMemoryMB", memory/(1<<20))
+
+	memoryPerCPU := int64(config.NewEnvConfig(logutil.NewStderrLog("config")).
+		GetInt("LINT_MEMORY_PER_CPU_MB", defaultLintMemoryPerCPUMB)) << 20
+	if memoryPerCPU <= 0 {
+		memoryPerCPU = defaultLintMemoryPerCPUMB << 20
+	}
+
+	t := tuneForLimits(cpus, memory, memoryPerCPU)
+	keys := make([]string, 0, len(t.env))
+	for k := range t.env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		exec.SetEnv(k, t.env[k])
+	}
+	if t.concurrency != cpus {
+		analytics.Log(ctx).Infof("Run golangci-lint with concurrency %d instead of %d CPUs: memory is limited to %dMB",
+			t.concurrency, cpus, memory/(1<<20))
+	}
+
+	return t.concurrency
+}
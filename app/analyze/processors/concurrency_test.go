@@ -0,0 +1,45 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrencyPerRun(t *testing.T) {
+	assert.Equal(t, 0, concurrencyPerRun(0, 2), "unknown CPUs count")
+	assert.Equal(t, 8, concurrencyPerRun(8, 1))
+	assert.Equal(t, 4, concurrencyPerRun(8, 2))
+	assert.Equal(t, 1, concurrencyPerRun(2, 3))
+	assert.Equal(t, 16/cap(projectSlots), concurrencyPerRun(16, 100), "runs are bounded by project slots")
+}
+
+func TestWithConcurrency(t *testing.T) {
+	ls := withConcurrency([]linters.Linter{golinters.GolangciLint{PatchPath: patchPath}}, 4)
+	assert.Equal(t, []linters.Linter{golinters.GolangciLint{PatchPath: patchPath, Concurrency: 4}}, ls)
+}
+
+func TestTuneForLimits(t *testing.T) {
+	const gb = int64(1) << 30
+
+	tn := tuneForLimits(0, 0, gb)
+	assert.Equal(t, 0, tn.concurrency, "unknown limits")
+	assert.Empty(t, tn.env)
+
+	tn = tuneForLimits(8, 0, gb)
+	assert.Equal(t, 8, tn.concurrency)
+	assert.Equal(t, map[string]string{"GOMAXPROCS": "8"}, tn.env)
+
+	tn = tuneForLimits(8, 2*gb, gb)
+	assert.Equal(t, 2, tn.concurrency, "memory bounds concurrency")
+	assert.Equal(t, map[string]string{"GOMAXPROCS": "2", "GOGC": "50"}, tn.env)
+
+	tn = tuneForLimits(4, 16*gb, gb)
+	assert.Equal(t, 4, tn.concurrency)
+	assert.Equal(t, map[string]string{"GOMAXPROCS": "4", "GOGC": "100"}, tn.env)
+
+	tn = tuneForLimits(0, gb/2, gb)
+	assert.Equal(t, 1, tn.concurrency, "at least one package is analyzed")
+}
@@ -0,0 +1,33 @@
+package processors
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/depgraph"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+)
+
+// buildDependencyGraph exports the module graph after dependencies are fetched: it's only consumed
+// by downstream tooling, so failures don't fail the analysis
+func buildDependencyGraph(ctx context.Context, exec executors.Executor, eventName analytics.EventName) *depgraph.Graph {
+	g, err := depgraph.Build(ctx, exec)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't build dependency graph: %s", err)
+		return nil
+	}
+	if g == nil {
+		return nil
+	}
+
+	analytics.SaveEventProp(ctx, eventName, "dependencyModules", len(g.Modules))
+	return g
+}
+
+func graphModules(g *depgraph.Graph) []depgraph.Module {
+	if g == nil {
+		return nil
+	}
+
+	return g.Modules
+}
@@ -0,0 +1,55 @@
+package processors
+
+import (
+	"context"
+	"path"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+)
+
+func isGoModChanged(files []string) bool {
+	for _, f := range files {
+		if path.Base(f) == "go.mod" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// appendDepsIssues appends informational issues of dependencies to res:
+// the check is optional, it never fails analysis
+func appendDepsIssues(ctx context.Context, l linters.Linter, exec executors.Executor, res *result.Result) {
+	depsRes, err := l.Run(ctx, exec)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Failed to check freshness of dependencies: %s", err)
+		return
+	}
+
+	res.Issues = append(res.Issues, depsRes.Issues...)
+}
+
+func countBlockingIssues(issues []result.Issue) int {
+	n := 0
+	for _, i := range issues {
+		if !i.Informational {
+			n++
+		}
+	}
+
+	return n
+}
+
+func getInfoIssues(issues []result.Issue) []result.Issue {
+	var ret []result.Issue
+	for _, i := range issues {
+		if i.Informational {
+			ret = append(ret, i)
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,28 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInformationalIssuesDontFailStatus(t *testing.T) {
+	info := result.Issue{Text: "dependency is outdated", Informational: true}
+	status, desc := getGithubStatusForIssues([]result.Issue{info}, nil, i18n.Printer{}, nil)
+	assert.Equal(t, github.StatusSuccess, status)
+	assert.Equal(t, "No issues found!", desc)
+
+	status, desc = getGithubStatusForIssues([]result.Issue{info, {Text: "issue"}}, nil, i18n.Printer{}, nil)
+	assert.Equal(t, github.StatusFailure, status)
+	assert.Equal(t, "1 issue found", desc)
+
+	assert.Equal(t, []result.Issue{info}, getInfoIssues([]result.Issue{info, {Text: "issue"}}))
+}
+
+func TestIsGoModChanged(t *testing.T) {
+	assert.True(t, isGoModChanged([]string{"main.go", "services/api/go.mod"}))
+	assert.False(t, isGoModChanged([]string{"main.go", "go.sum"}))
+}
@@ -0,0 +1,96 @@
+package processors
+
+import (
+	"context"
+	"sync"
+
+	"github.com/golangci/golangci-worker/app/lib/github"
+)
+
+type DryRunComment struct {
+	Path string
+	Line int
+	Side github.Side
+	Body string
+}
+
+type DryRunStatus struct {
+	Status      github.Status
+	Description string
+	URL         string `json:",omitempty"`
+	Context     string `json:",omitempty"` // empty for the main status
+}
+
+type DryRunRelease struct {
+	ID   int
+	Body string
+}
+
+// dryRunRes is what would have been posted to GitHub
+type dryRunRes struct {
+	Comments []DryRunComment `json:",omitempty"`
+	Statuses []DryRunStatus  `json:",omitempty"`
+	Releases []DryRunRelease `json:",omitempty"`
+}
+
+// dryRunClient reads from GitHub as usual but only records writes instead of posting them:
+// every method of github.Client except Get* methods must be overridden here
+type dryRunClient struct {
+	github.Client
+
+	mu  sync.Mutex
+	res dryRunRes
+}
+
+func newDryRunClient(c github.Client) *dryRunClient {
+	return &dryRunClient{Client: c}
+}
+
+func (c *dryRunClient) CreateReview(ctx context.Context, _ *github.Context, review *github.Review) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, comment := range review.Comments {
+		c.res.Comments = append(c.res.Comments, DryRunComment{
+			Path: comment.Path,
+			Line: comment.Line,
+			Side: comment.Side,
+			Body: comment.Body,
+		})
+	}
+
+	return nil
+}
+
+func (c *dryRunClient) SetCommitStatus(ctx context.Context, gc *github.Context, _ string, status github.Status, desc, url string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.res.Statuses = append(c.res.Statuses, DryRunStatus{
+		Status:      status,
+		Description: desc,
+		URL:         url,
+		Context:     gc.StatusContext,
+	})
+	return nil
+}
+
+func (c *dryRunClient) EditReleaseBody(ctx context.Context, _ *github.Context, id int, body string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.res.Releases = append(c.res.Releases, DryRunRelease{ID: id, Body: body})
+	return nil
+}
+
+func (c *dryRunClient) result() *dryRunRes {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ret := dryRunRes{
+		Comments: append([]DryRunComment{}, c.res.Comments...),
+		Statuses: append([]DryRunStatus{}, c.res.Statuses...),
+		Releases: append([]DryRunRelease{}, c.res.Releases...),
+	}
+	return &ret
+}
@@ -1,17 +1,32 @@
 package processors
 
 import (
+	"context"
 	"errors"
-	"os"
-	"strings"
 
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/errorbudget"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
 	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/redact"
 )
 
 var (
 	errNothingToAnalyze = errors.New("nothing to analyze")
+	errAllPathsSkipped  = errors.New("all changed paths are skipped")
+	errNoChangesInPath  = errors.New("no changes in the analysis path")
 )
 
+// workspaceSetupError is saved into the analysis state, the task is retried only if retry is set
+type workspaceSetupError struct {
+	err   error
+	retry bool
+}
+
+func (e workspaceSetupError) Error() string {
+	return e.err.Error()
+}
+
 type IgnoredError struct {
 	Status        github.Status
 	StatusDesc    string
@@ -23,29 +38,21 @@ func (e IgnoredError) Error() string {
 }
 
 func escapeErrorText(text string, secrets map[string]string) string {
-	ret := text
-	for secret, replacement := range secrets {
-		ret = strings.Replace(ret, secret, replacement, -1)
-	}
-
-	return ret
+	return redact.New(secrets).Redact(text)
 }
 
 func buildSecrets() map[string]string {
-	const hidden = "{hidden}"
-	ret := map[string]string{}
-
-	for _, kv := range os.Environ() {
-		parts := strings.Split(kv, "=")
-		if len(parts) != 2 {
-			continue
-		}
-
-		v := parts[1]
-		if len(v) >= 6 {
-			ret[v] = hidden
-		}
+	return redact.EnvSecrets()
+}
+
+// trackErrorClass classifies the original (not yet transformed) error of analysis
+// and saves the class into analytics and the error budget
+func trackErrorClass(ctx context.Context, eventName analytics.EventName, err error) errorutils.Class {
+	class := errorutils.Classify(err)
+	analytics.SaveEventProp(ctx, eventName, "errorClass", string(class))
+	if rerr := errorbudget.Record(class); rerr != nil {
+		analytics.Log(ctx).Warnf("Can't record error class %q to error budget: %s", class, rerr)
 	}
 
-	return ret
+	return class
 }
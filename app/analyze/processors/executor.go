@@ -7,23 +7,31 @@ import (
 
 	"github.com/golangci/golangci-shared/pkg/config"
 	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/deploykeys"
 	"github.com/golangci/golangci-worker/app/lib/executors"
 	"github.com/golangci/golangci-worker/app/lib/experiments"
 	"github.com/golangci/golangci-worker/app/lib/github"
 	"github.com/pkg/errors"
 )
 
-func makeExecutor(ctx context.Context, repo *github.Repo, forPull bool, log logutil.Log, ec *experiments.Checker) (executors.Executor, error) {
+// defaultEncryptedWorkDirSizeMB limits encrypted work dirs: tmpfs takes memory of the host
+const defaultEncryptedWorkDirSizeMB = 4096
+
+// makeExecutor returns the executor of the analysis, its work dir is encrypted if encryption is set
+func makeExecutor(ctx context.Context, repo *github.Repo, forPull bool, log logutil.Log, ec *experiments.Checker,
+	encryption string) (executors.Executor, error) {
 	if log == nil { // TODO: remove
 		log = logutil.NewStderrLog("executor")
 		log.SetLevel(logutil.LogLevelInfo)
 	}
+	cfg := config.NewEnvConfig(log)
 	if ec == nil { // TODO: remove
-		cfg := config.NewEnvConfig(log)
 		ec = experiments.NewChecker(cfg, log)
 	}
 
-	if ec.IsActiveForAnalysis("use_container_executor", repo, forPull) {
+	var exec executors.Executor
+	if ec.IsActiveForAnalysis(ctx, "use_container_executor", repo, forPull) {
 		ce, err := executors.NewContainer(log)
 		if err != nil {
 			return nil, errors.Wrap(err, "can't build container executor")
@@ -32,17 +40,63 @@ func makeExecutor(ctx context.Context, repo *github.Repo, forPull bool, log logu
 		if err = ce.Setup(ctx); err != nil {
 			return nil, errors.Wrap(err, "failed to setup container executor")
 		}
-		return ce.WithWorkDir("/goapp"), nil
+		exec = ce.WithWorkDir("/goapp")
+	} else {
+		s := executors.NewRemoteShell(
+			os.Getenv("REMOTE_SHELL_USER"),
+			os.Getenv("REMOTE_SHELL_HOST"),
+			os.Getenv("REMOTE_SHELL_KEY_FILE_PATH"),
+		)
+		if err := s.SetupTempWorkDir(ctx); err != nil {
+			return nil, fmt.Errorf("can't setup temp work dir: %s", err)
+		}
+		exec = s
+	}
+
+	if encryption != "" {
+		// mounts are made by the worker itself: commands of the allowlist don't include sudo
+		var err error
+		sizeMB := cfg.GetInt("WORKDIR_ENCRYPTED_SIZE_MB", defaultEncryptedWorkDirSizeMB)
+		if exec, err = withEncryptedWorkDir(ctx, exec, encryption, sizeMB); err != nil {
+			return nil, err
+		}
+	}
+
+	return executors.RestrictedFromEnv(exec), nil
+}
+
+// withEncryptedWorkDir returns the executor with the encrypted work dir, exec is cleaned on errors:
+// analyses of organizations requiring encryption never run in plain work dirs
+func withEncryptedWorkDir(ctx context.Context, exec executors.Executor, encryption string, sizeMB int) (executors.Executor, error) {
+	mode, err := executors.ParseWorkDirEncryption(encryption)
+	if err != nil {
+		exec.Clean()
+		return nil, err
+	}
+
+	ret, err := executors.NewEncryptedWorkDir(ctx, exec, mode, sizeMB)
+	if err != nil {
+		exec.Clean()
+		return nil, err
+	}
+
+	analytics.Log(ctx).Infof("Using %s work dir", mode)
+	return ret, nil
+}
+
+// withDeployKey returns the executor authenticating git by the sealed deploy key, exec is cleaned on errors
+func withDeployKey(ctx context.Context, exec executors.Executor, sealed string) (executors.Executor, error) {
+	key, err := deploykeys.OpenFromEnv(sealed)
+	if err != nil {
+		exec.Clean()
+		return nil, errors.Wrap(err, "can't open deploy key")
 	}
 
-	s := executors.NewRemoteShell(
-		os.Getenv("REMOTE_SHELL_USER"),
-		os.Getenv("REMOTE_SHELL_HOST"),
-		os.Getenv("REMOTE_SHELL_KEY_FILE_PATH"),
-	)
-	if err := s.SetupTempWorkDir(ctx); err != nil {
-		return nil, fmt.Errorf("can't setup temp work dir: %s", err)
+	agent, err := deploykeys.StartAgent(ctx, exec, key)
+	if err != nil {
+		exec.Clean()
+		return nil, errors.Wrap(err, "can't start ssh-agent with deploy key")
 	}
 
-	return s, nil
+	return agent, nil
 }
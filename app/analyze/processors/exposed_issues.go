@@ -0,0 +1,220 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/diffanchor"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+)
+
+// nolintRe matches standalone and trailing //nolint comments: golangci-lint applies trailing ones
+// to the node starting at their line
+var nolintRe = regexp.MustCompile(`(?:^|\s)//\s*nolint(?::([\w,-]+))?`)
+
+// removedNolint is a //nolint comment removed by the PR: issues of the node at the next line
+// (or at the same line for trailing comments) are exposed
+type removedNolint struct {
+	file    string
+	linters []string // all linters if it's empty
+	hunkPos int      // position of the removed line in the diff of the file
+	oldLine int      // line of the removed comment in the base file: comments are anchored to it
+	newLine int      // line of the new file after the removed line: it's the changed line for trailing comments
+}
+
+func (r removedNolint) matchesLinter(linter string) bool {
+	if len(r.linters) == 0 {
+		return true
+	}
+
+	for _, l := range r.linters {
+		if l == linter {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findRemovedNolints finds removed //nolint comments in go files of the unified diff
+func findRemovedNolints(patch string) ([]removedNolint, error) {
+	p, err := diffanchor.Parse(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []removedNolint
+	for _, f := range p.Files {
+		if !strings.HasSuffix(f.Path, ".go") {
+			continue
+		}
+
+		for _, h := range f.Hunks {
+			newLine := h.NewStart // line of the new file after the current line
+			for _, l := range h.Lines {
+				if l.Kind != diffanchor.Deleted {
+					newLine = l.NewLine + 1
+					continue
+				}
+
+				m := nolintRe.FindStringSubmatch(strings.TrimSpace(l.Text))
+				if m == nil {
+					continue
+				}
+
+				r := removedNolint{file: f.Path, hunkPos: l.Position, oldLine: l.OldLine, newLine: newLine}
+				if m[1] != "" {
+					r.linters = strings.Split(m[1], ",")
+				}
+				ret = append(ret, r)
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// nodeLines returns lines of the outermost node starting at the first code line from fromLine:
+// it's the node which was covered by the removed //nolint comment
+func nodeLines(src string, fromLine int) (int, int, bool) {
+	lines := strings.Split(src, "\n")
+	startLine := 0
+	for i := fromLine; i >= 1 && i <= len(lines); i++ {
+		l := strings.TrimSpace(lines[i-1])
+		if l != "" && !strings.HasPrefix(l, "//") {
+			startLine = i
+			break
+		}
+	}
+	if startLine == 0 {
+		return 0, 0, false
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	var found ast.Node
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n == nil || found != nil {
+			return false
+		}
+		switch n.(type) {
+		case *ast.File, *ast.CommentGroup, *ast.Comment:
+			return true
+		}
+
+		if fset.Position(n.Pos()).Line == startLine {
+			found = n
+			return false
+		}
+		return true
+	})
+	if found == nil {
+		return 0, 0, false
+	}
+
+	return startLine, fset.Position(found.End()).Line, true
+}
+
+// addExposedIssues reports issues not in the diff which were suppressed by removed //nolint comments:
+// otherwise they are reported only on next unrelated PRs
+func (g *githubGoPR) addExposedIssues(ctx context.Context) error {
+	removed, err := findRemovedNolints(g.patch)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Failed to parse the patch to find removed nolint comments: %s", err)
+		return nil
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	_ = g.trackStep("Exposed issues", func() (string, error) {
+		issues, err := g.findExposedIssues(ctx, removed)
+		if err != nil {
+			// it's an addition to the analysis: don't fail it
+			analytics.Log(ctx).Warnf("Failed to find issues exposed by removed nolint comments: %s", err)
+			return "", nil
+		}
+
+		g.lintRes.Issues = append(g.lintRes.Issues, issues...)
+		return fmt.Sprintf("%d issues exposed by removed nolint comments", len(issues)), nil
+	})
+	return nil
+}
+
+type lineRange struct {
+	from, to int
+	removed  removedNolint
+}
+
+func (g *githubGoPR) findExposedIssues(ctx context.Context, removed []removedNolint) ([]result.Issue, error) {
+	rangesByFile := map[string][]lineRange{}
+	var packages []string
+	seenPackages := map[string]bool{}
+	for _, r := range removed {
+		src, err := executors.Unrestricted(g.exec).Run(ctx, "cat", r.file)
+		if err != nil {
+			continue // the file was deleted
+		}
+
+		from, to, ok := nodeLines(src, r.newLine)
+		if !ok {
+			continue
+		}
+		rangesByFile[r.file] = append(rangesByFile[r.file], lineRange{from: from, to: to, removed: r})
+
+		pkg := "./" + path.Dir(r.file)
+		if path.Dir(r.file) == "." {
+			pkg = "."
+		}
+		if !seenPackages[pkg] {
+			seenPackages[pkg] = true
+			packages = append(packages, pkg)
+		}
+	}
+	if len(packages) == 0 {
+		return nil, nil
+	}
+
+	res, err := g.runner.Run(ctx, withPackages(withoutPatch(g.linters), packages), g.exec)
+	if err != nil {
+		return nil, err
+	}
+
+	reported := map[string]bool{}
+	for _, i := range g.lintRes.Issues {
+		reported[fmt.Sprintf("%s:%d:%s", i.File, i.LineNumber, i.Text)] = true
+	}
+
+	var ret []result.Issue
+	for _, i := range res.Issues {
+		if reported[fmt.Sprintf("%s:%d:%s", i.File, i.LineNumber, i.Text)] {
+			continue
+		}
+
+		for _, lr := range rangesByFile[i.File] {
+			if i.LineNumber < lr.from || i.LineNumber > lr.to || !lr.removed.matchesLinter(i.FromLinter) {
+				continue
+			}
+
+			i.HunkPos = lr.removed.hunkPos
+			i.DeletedLine = lr.removed.oldLine
+			i.Text = fmt.Sprintf("%s (line %d, exposed by the removed nolint comment)", i.Text, i.LineNumber)
+			ret = append(ret, i)
+			break
+		}
+	}
+
+	return ret, nil
+}
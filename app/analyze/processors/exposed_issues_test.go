@@ -0,0 +1,158 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+const removedNolintPatch = `diff --git a/pkg/a.go b/pkg/a.go
+index 1..2 100644
+--- a/pkg/a.go
++++ b/pkg/a.go
+@@ -1,4 +1,4 @@
+ package pkg
+ 
+-// old comment
++// new comment
+@@ -10,6 +10,5 @@ func x() {
+ }
+ 
+-//nolint:errcheck,gosec
+ func f() {
+ 	g()
+ }
+diff --git a/README.md b/README.md
+--- a/README.md
++++ b/README.md
+@@ -1 +1 @@
+-//nolint
++text
+`
+
+const exposedSrc = `package pkg
+
+// new comment
+
+func x() {
+	return
+}
+
+func y() {
+}
+
+func f() {
+	g()
+}
+`
+
+func TestFindRemovedNolints(t *testing.T) {
+	removed, err := findRemovedNolints(removedNolintPatch)
+	assert.NoError(t, err)
+	assert.Equal(t, []removedNolint{
+		{file: "pkg/a.go", linters: []string{"errcheck", "gosec"}, hunkPos: 8, oldLine: 12, newLine: 12},
+	}, removed)
+}
+
+const removedTrailingNolintPatch = `diff --git a/pkg/a.go b/pkg/a.go
+index 1..2 100644
+--- a/pkg/a.go
++++ b/pkg/a.go
+@@ -10,7 +10,7 @@ func x() {
+ }
+ 
+-func f() { //nolint:errcheck
++func f() {
+ 	g()
+ }
+ 
+-	s := "http://nolint"
++	s := ""
+`
+
+func TestFindRemovedTrailingNolints(t *testing.T) {
+	removed, err := findRemovedNolints(removedTrailingNolintPatch)
+	assert.NoError(t, err)
+	assert.Equal(t, []removedNolint{
+		{file: "pkg/a.go", linters: []string{"errcheck"}, hunkPos: 3, oldLine: 12, newLine: 12},
+	}, removed)
+
+	from, to, ok := nodeLines(exposedSrc, removed[0].newLine) // the node starts at the changed line
+	assert.True(t, ok)
+	assert.Equal(t, []int{12, 14}, []int{from, to})
+}
+
+func TestNodeLines(t *testing.T) {
+	from, to, ok := nodeLines(exposedSrc, 12)
+	assert.True(t, ok)
+	assert.Equal(t, 12, from)
+	assert.Equal(t, 14, to)
+
+	from, to, ok = nodeLines(exposedSrc, 3) // skips comments
+	assert.True(t, ok)
+	assert.Equal(t, []int{5, 7}, []int{from, to})
+
+	_, _, ok = nodeLines(exposedSrc, 100)
+	assert.False(t, ok)
+}
+
+type fixedRunner struct {
+	res      *result.Result
+	packages *[]string
+}
+
+func (r fixedRunner) Run(ctx context.Context, lintersList []linters.Linter, exec executors.Executor) (*result.Result, error) {
+	gl := lintersList[0].(golinters.GolangciLint)
+	*r.packages = gl.Packages
+	return r.res, nil
+}
+
+func (r fixedRunner) RunStreaming(ctx context.Context, lintersList []linters.Linter, exec executors.Executor,
+	_ linters.IssueFunc) (*result.Result, error) {
+	return r.Run(ctx, lintersList, exec)
+}
+
+func TestFindExposedIssues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Run(ctx, "cat", "pkg/a.go").Return(exposedSrc, nil)
+
+	var packages []string
+	g := &githubGoPR{
+		patch:   removedNolintPatch,
+		lintRes: &result.Result{Issues: []result.Issue{{File: "pkg/a.go", LineNumber: 13, Text: "reported", FromLinter: "errcheck"}}},
+		githubGoPRConfig: githubGoPRConfig{
+			exec:    exec,
+			linters: []linters.Linter{golinters.GolangciLint{PatchPath: "patch"}},
+			runner: fixedRunner{packages: &packages, res: &result.Result{Issues: []result.Issue{
+				{File: "pkg/a.go", LineNumber: 13, Text: "unchecked error", FromLinter: "errcheck"},
+				{File: "pkg/a.go", LineNumber: 13, Text: "reported", FromLinter: "errcheck"}, // already reported
+				{File: "pkg/a.go", LineNumber: 13, Text: "bad name", FromLinter: "golint"},   // not suppressed
+				{File: "pkg/a.go", LineNumber: 6, Text: "unchecked error", FromLinter: "errcheck"},
+			}}},
+		},
+	}
+
+	removed, err := findRemovedNolints(g.patch)
+	assert.NoError(t, err)
+	issues, err := g.findExposedIssues(ctx, removed)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"./pkg"}, packages)
+	assert.Equal(t, []result.Issue{{
+		File:        "pkg/a.go",
+		LineNumber:  13,
+		Text:        "unchecked error (line 13, exposed by the removed nolint comment)",
+		FromLinter:  "errcheck",
+		HunkPos:     8,
+		DeletedLine: 12,
+	}}, issues)
+}
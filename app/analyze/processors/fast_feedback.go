@@ -0,0 +1,66 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+)
+
+// fastLinters take seconds on changed packages: they give the first feedback before the full analysis
+var fastLinters = []string{"gofmt", "govet"}
+
+// withFastLinters returns golangci-lint linters running only fast linters for the packages
+func withFastLinters(lintersList []linters.Linter, packages []string) []linters.Linter {
+	var ret []linters.Linter
+	for _, l := range lintersList {
+		if gl, ok := l.(golinters.GolangciLint); ok {
+			gl.DisableAll = true
+			gl.EnabledLinters = fastLinters
+			gl.SpellCheck = false
+			gl.Packages = packages
+			ret = append(ret, gl)
+		}
+	}
+
+	return ret
+}
+
+func preliminaryStatusDesc(p i18n.Printer, issues int) string {
+	return p.Sprintf(i18n.StatusPreliminary, issuesCountText(p, issues))
+}
+
+// fastFeedback runs fast linters on changed packages after the repo is prepared: their issues are
+// published as a preview and the pending status shows their count until the final status is set.
+// Govet needs dependencies: that's why it's run after their installation, not right after the clone.
+// It's optional: failures only delay the feedback till the full analysis.
+func (g *githubGoPR) fastFeedback(ctx context.Context) error {
+	if !g.ec.IsActiveForAnalysis(ctx, "fast_feedback", &g.context.Repo, true) {
+		return nil
+	}
+
+	pkgs := changedPackages(getPatchFiles(g.patch))
+	fast := withFastLinters(g.linters, pkgs)
+	if len(pkgs) == 0 || len(fast) == 0 {
+		return nil
+	}
+
+	_ = g.trackStep("Fast feedback", func() (string, error) {
+		res, err := g.runner.Run(ctx, fast, g.exec)
+		if err != nil {
+			analytics.Log(ctx).Warnf("Fast linters failed, wait for the full analysis: %s", err)
+			return "", nil
+		}
+
+		n := countBlockingIssues(res.Issues)
+		analytics.SaveEventProp(ctx, analytics.EventPRChecked, "fastFeedbackIssues", n)
+		g.publishPreview(ctx, res.Issues)
+		g.setCommitStatus(ctx, github.StatusPending, preliminaryStatusDesc(g.msg, n))
+		return fmt.Sprintf("%s by fast linters", issuesCountText(i18n.Printer{}, n)), nil
+	})
+	return nil
+}
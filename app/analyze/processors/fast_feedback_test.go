@@ -0,0 +1,62 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-shared/pkg/config"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/prstate"
+	"github.com/golangci/golangci-worker/app/lib/experiments"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithFastLinters(t *testing.T) {
+	gl := golinters.GolangciLint{PatchPath: "patch", EnabledLinters: []string{"gosec"}, SpellCheck: true}
+	fast := withFastLinters([]linters.Linter{gl, golinters.DepsFreshness{}}, []string{"./pkg"})
+	assert.Equal(t, []linters.Linter{golinters.GolangciLint{
+		PatchPath:      "patch",
+		DisableAll:     true,
+		EnabledLinters: fastLinters,
+		Packages:       []string{"./pkg"},
+	}}, fast)
+}
+
+func TestFastFeedback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	issues := []result.Issue{{File: "pkg/a.go", LineNumber: 1, Text: "File is not `gofmt`-ed", FromLinter: "gofmt"}}
+	state := prstate.NewMockStorage(ctrl)
+	state.EXPECT().UpdateState(any, any, any, any, &prstate.State{
+		Status:     statusProcessing,
+		ResultJSON: &resultJSON{Version: 1, WorkerRes: workerRes{PreviewIssues: issues}},
+	}).Return(nil)
+	client := github.NewMockClient(ctrl)
+	client.EXPECT().SetCommitStatus(any, any, any, github.StatusPending, preliminaryStatusDesc(i18n.Printer{}, 1), any).Return(nil)
+
+	var packages []string
+	log := logutil.NewStderrLog("test")
+	p := &githubGoPR{
+		context:      &github.FakeContext,
+		analysisGUID: testAnalysisGUID,
+		patch:        "--- a/pkg/a.go\n+++ b/pkg/a.go\n",
+		ec:           experiments.NewChecker(config.NewEnvConfig(log), log),
+		githubGoPRConfig: githubGoPRConfig{
+			linters: []linters.Linter{golinters.GolangciLint{PatchPath: "patch"}},
+			runner:  fixedRunner{packages: &packages, res: &result.Result{Issues: issues}},
+			state:   state,
+			client:  client,
+		},
+	}
+
+	ctx := experiments.ContextWithOverrides(testCtx, map[string]bool{"fast_feedback": true})
+	assert.NoError(t, p.fastFeedback(ctx))
+	assert.Equal(t, []string{"./pkg"}, packages)
+	assert.Equal(t, "Preliminary: 1 issue found by fast linters, full analysis is running...", preliminaryStatusDesc(i18n.Printer{}, 1))
+}
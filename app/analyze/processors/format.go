@@ -0,0 +1,92 @@
+package processors
+
+import (
+	"context"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/diffanchor"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+)
+
+// formattedFiles returns go files existing after the patch: vendored files aren't formatted by policies
+func formattedFiles(p *diffanchor.Patch) []string {
+	var ret []string
+	for _, f := range p.Files {
+		if f.Path == "" || !strings.HasSuffix(f.Path, ".go") || strings.HasPrefix(f.Path, "vendor/") ||
+			strings.Contains(f.Path, "/vendor/") {
+			continue
+		}
+		ret = append(ret, f.Path)
+	}
+
+	return ret
+}
+
+// changedFormatIssues keeps only issues of changed code: authors don't have to reformat untouched code.
+// The last line of multi-line suggestions must be in the diff too, otherwise the issue is reported without the suggestion.
+func changedFormatIssues(p *diffanchor.Patch, issues []result.Issue) []result.Issue {
+	var ret []result.Issue
+	for _, i := range issues {
+		to := i.LineNumber
+		if i.LineRange != nil {
+			to = i.LineRange.To
+		}
+		if !hasAddedLines(p, i.File, i.LineNumber, to) {
+			continue
+		}
+
+		loc, ok := p.Locate(i.File, i.LineNumber)
+		if !ok {
+			continue
+		}
+		i.HunkPos = loc.Line.Position
+
+		if _, ok = p.Locate(i.File, to); !ok {
+			i.LineRange, i.Replacement = nil, nil
+		}
+		ret = append(ret, i)
+	}
+
+	return ret
+}
+
+func hasAddedLines(p *diffanchor.Patch, file string, from, to int) bool {
+	for line := from; line <= to; line++ {
+		if loc, ok := p.Locate(file, line); ok && loc.Line.Kind == diffanchor.Added {
+			return true
+		}
+	}
+
+	return false
+}
+
+// appendFormatIssues enforces the formatting policy of the repo: failures of formatters
+// don't fail analysis, they are shown as warnings
+func (g *githubGoPR) appendFormatIssues(ctx context.Context, res *result.Result) {
+	p, err := diffanchor.Parse(g.patch)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't parse the patch to check the formatting policy: %s", err)
+		return
+	}
+
+	files := formattedFiles(p)
+	if len(files) == 0 {
+		return
+	}
+
+	policy := g.repoCfg.FormatPolicy
+	l := golinters.Format{Formatters: policy.Formatters, LocalPrefix: policy.LocalPrefix, Files: files}
+	fmtRes, err := l.Run(ctx, g.exec)
+	if err != nil {
+		g.publicWarn("format policy", g.msg.Sprintf(i18n.WarnFormatPolicyFailed))
+		analytics.Log(ctx).Warnf("Failed to check the formatting policy: %s", err)
+		return
+	}
+
+	issues := changedFormatIssues(p, fmtRes.Issues)
+	analytics.SaveEventProp(ctx, analytics.EventPRChecked, "formatIssues", len(issues))
+	res.Issues = append(res.Issues, issues...)
+}
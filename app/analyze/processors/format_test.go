@@ -0,0 +1,57 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/diffanchor"
+	"github.com/stretchr/testify/assert"
+)
+
+const testFormatPatch = `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -10,3 +10,5 @@ func f() {
+ 	x := 1
++	y:=2
++	z:=3
+ 	return
+ }
+diff --git a/vendor/dep/dep.go b/vendor/dep/dep.go
+--- a/vendor/dep/dep.go
++++ b/vendor/dep/dep.go
+@@ -1 +1 @@
+-package dep
++package dep // changed
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package a
+`
+
+func TestFormattedFiles(t *testing.T) {
+	p, err := diffanchor.Parse(testFormatPatch)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.go"}, formattedFiles(p))
+}
+
+func TestChangedFormatIssues(t *testing.T) {
+	p, err := diffanchor.Parse(testFormatPatch)
+	assert.NoError(t, err)
+
+	fix := &result.Replacement{NewLines: []string{"\ty := 2", "\tz := 3"}}
+	issues := []result.Issue{
+		{File: "a.go", LineNumber: 11, LineRange: &result.Range{From: 11, To: 12}, Replacement: fix},
+		{File: "a.go", LineNumber: 10, LineRange: &result.Range{From: 10, To: 11}, Replacement: fix},
+		{File: "a.go", LineNumber: 13, LineRange: &result.Range{From: 13, To: 20}, Replacement: fix},
+		{File: "a.go", LineNumber: 12, LineRange: &result.Range{From: 12, To: 20}, Replacement: fix},
+		{File: "a.go", LineNumber: 30, Replacement: fix},
+	}
+	assert.Equal(t, []result.Issue{
+		{File: "a.go", LineNumber: 11, HunkPos: 2, LineRange: &result.Range{From: 11, To: 12}, Replacement: fix},
+		{File: "a.go", LineNumber: 10, HunkPos: 1, LineRange: &result.Range{From: 10, To: 11}, Replacement: fix},
+		{File: "a.go", LineNumber: 12, HunkPos: 3}, // the range is out of the diff
+	}, changedFormatIssues(p, issues))
+}
@@ -3,32 +3,53 @@ package processors
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
 	"os"
-	"runtime/debug"
 	"strings"
 	"time"
 
 	"github.com/golangci/golangci-api/pkg/goenv/ensuredeps"
 	goenvresult "github.com/golangci/golangci-api/pkg/goenv/result"
 	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/cancellation"
+	"github.com/golangci/golangci-worker/app/analyze/commitstatus"
+	"github.com/golangci/golangci-worker/app/analyze/depgraph"
+	"github.com/golangci/golangci-worker/app/analyze/hooks"
+	"github.com/golangci/golangci-worker/app/analyze/issueaging"
+	"github.com/golangci/golangci-worker/app/analyze/issuecache"
+	"github.com/golangci/golangci-worker/app/analyze/killswitch"
+	"github.com/golangci/golangci-worker/app/analyze/lintconfig"
 	"github.com/golangci/golangci-worker/app/analyze/linters"
 	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/lintcache"
+	"github.com/golangci/golangci-worker/app/analyze/linters/lintdocs"
 	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/orgfeed"
 	"github.com/golangci/golangci-worker/app/analyze/prstate"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
+	"github.com/golangci/golangci-worker/app/analyze/repoguard"
 	"github.com/golangci/golangci-worker/app/analyze/repoinfo"
 	"github.com/golangci/golangci-worker/app/analyze/reporters"
+	"github.com/golangci/golangci-worker/app/analyze/repostate"
+	"github.com/golangci/golangci-worker/app/analyze/sbom"
+	"github.com/golangci/golangci-worker/app/analyze/suppressions"
+	"github.com/golangci/golangci-worker/app/lib/artifacts"
+	"github.com/golangci/golangci-worker/app/lib/buildinfo"
+	"github.com/golangci/golangci-worker/app/lib/deploykeys"
 	"github.com/golangci/golangci-worker/app/lib/errorutils"
 	"github.com/golangci/golangci-worker/app/lib/executors"
 	"github.com/golangci/golangci-worker/app/lib/fetchers"
 	"github.com/golangci/golangci-worker/app/lib/github"
 	"github.com/golangci/golangci-worker/app/lib/goutils/workspaces"
 	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/golangci/golangci-worker/app/lib/redact"
+	"github.com/golangci/golangci-worker/app/lib/usage"
 	gh "github.com/google/go-github/github"
 
 	"github.com/golangci/golangci-shared/pkg/config"
 	"github.com/golangci/golangci-shared/pkg/logutil"
 	"github.com/golangci/golangci-worker/app/lib/experiments"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
@@ -44,6 +65,23 @@ type githubGoPRConfig struct {
 	exec        executors.Executor
 	client      github.Client
 	state       prstate.Storage
+	cfgFetcher  repoconfig.Fetcher
+	artifacts   *artifacts.Manager
+	usage       usage.Reporter
+
+	killSwitches killswitch.Fetcher
+	suppressions suppressions.Storage
+	issueAging   issueaging.Storage
+	issueCache   issuecache.Storage
+	baselines    repostate.Storage
+	feed         orgfeed.Publisher
+	statuses     commitstatus.Storage
+
+	// cancellations are checked during the analysis: closing of the pull request aborts it
+	cancellations cancellation.Registry
+
+	// plugins are called at hook points of the analysis
+	plugins []hooks.Plugin
 }
 
 type githubGoPR struct {
@@ -52,42 +90,181 @@ type githubGoPR struct {
 
 	context *github.Context
 	gw      *workspaces.Go
+	repoCfg *repoconfig.Config
+
+	// msg prints texts posted to GitHub in the language of the repo config
+	msg i18n.Printer
 
 	resLog *goenvresult.Log
 
+	labelOpts prLabelOptions
+
+	maxPatchSize int
+
+	// maxRepoSizeMB is a limit of repo size if the plan doesn't limit it, zero means no limit
+	maxRepoSizeMB int
+
+	// previewInterval is a min interval between saves of partial results of linters, zero disables previews
+	previewInterval time.Duration
+
+	// generateTimeout limits go generate of the generated files verification
+	generateTimeout time.Duration
+
+	// deployKeyExec is the executor with the deploy key agent, it's nil if the repo is cloned by the token
+	deployKeyExec executors.Executor
+
+	// set by stages of the pipeline
+	asyncPatch *asyncPatch
+	patch      string
+
+	// rebuiltPatch is set if GitHub refused to render the diff of the too large pull request,
+	// the diff is made from the merge-base in the clone then
+	rebuiltPatch *github.RebuiltPatch
+
+	mergeBase  string
+	lintConfig *lintconfig.Report
+	repoMeta   *repoinfo.Metadata
+	lintRes    *result.Result
+	buildInfo  *buildinfo.Info
+	depGraph   *depgraph.Graph
+	sbom       *sbom.Document
+
+	// attributionBase is the merge-base issues are attributed to commits since, it's empty if it isn't found
+	attributionBase string
+
+	// lintOutputs are read from the work dir after the lint, savedArtifacts are saved once by finalize
+	lintOutputs    *lintOutputs
+	savedArtifacts []artifacts.Artifact
+
+	// shadowRuns are made in the background after the result is saved
+	shadowRuns       []shadowRun
+	shadowRunTimeout time.Duration
+
+	// cpus is a count of CPUs of the executor, it's zero if it's unknown
+	cpus int
+
+	// path is the cleaned analysis path of the task, it's empty if the whole repo is analyzed
+	path string
+
+	// cancelWatcher observes cancellation of the running analysis
+	cancelWatcher *cancellation.Watcher
+
+	// killSwitch is set if analyses of the repo are disabled by operators
+	killSwitch *killswitch.Entry
+
+	// issueDelta is set by issue aging, it's nil if issues of the previous analysis are unknown
+	issueDelta *issueDelta
+
+	// baseline is issues of the base branch, it's nil if they are unknown
+	baseline baseline
+
+	// newcomer is set for PRs of first-time contributors if the repo is friendly to them: issues don't fail status
+	newcomer bool
+
+	// plan is nil if the organization isn't limited
+	plan      *usage.Plan
+	startedAt time.Time
+
 	githubGoPRConfig
 	resultCollector
 
 	newWorkspaceInstaller workspaces.Installer
 	ec                    *experiments.Checker
+
+	// dryRun is set if nothing must be posted to GitHub
+	dryRun *dryRunClient
 }
 
 //nolint:gocyclo
 func newGithubGoPR(ctx context.Context, c *github.Context, cfg githubGoPRConfig, analysisGUID string) (*githubGoPR, error) {
 	if cfg.client == nil {
-		cfg.client = github.NewMyClient()
+		cfg.client = github.NewCachingClient(github.NewMyClient(), github.DefaultCache())
 	}
 
 	if cfg.exec == nil {
 		var err error
-		cfg.exec, err = makeExecutor(ctx, &c.Repo, true, nil, nil)
+		cfg.exec, err = makeExecutor(ctx, &c.Repo, true, nil, nil, c.WorkDirEncryption)
 		if err != nil {
 			return nil, fmt.Errorf("can't make executor: %s", err)
 		}
 	}
 
-	if cfg.repoFetcher == nil {
-		cfg.repoFetcher = fetchers.NewGit()
+	var deployKeyExec executors.Executor
+	if c.DeployKey != "" {
+		var err error
+		if cfg.exec, err = withDeployKey(ctx, cfg.exec, c.DeployKey); err != nil {
+			return nil, err
+		}
+		deployKeyExec = cfg.exec
 	}
 
-	if cfg.infoFetcher == nil {
-		cfg.infoFetcher = repoinfo.NewCloningFetcher(cfg.repoFetcher)
+	if cfg.cfgFetcher == nil {
+		cfg.cfgFetcher = repoconfig.Default()
+	}
+
+	if cfg.artifacts == nil {
+		cfg.artifacts = artifacts.Default()
+	}
+
+	if cfg.killSwitches == nil {
+		cfg.killSwitches = killswitch.Default()
+	}
+
+	if cfg.suppressions == nil {
+		cfg.suppressions = suppressions.NewAPIStorage(httputils.GrequestsClient{})
+	}
+
+	if cfg.issueAging == nil {
+		cfg.issueAging = issueaging.NewAPIStorage(httputils.GrequestsClient{})
+	}
+
+	if cfg.issueCache == nil {
+		cfg.issueCache = issuecache.NewAPIStorage(httputils.GrequestsClient{})
+	}
+
+	if cfg.baselines == nil {
+		cfg.baselines = repostate.NewAPIStorage(httputils.GrequestsClient{})
+	}
+
+	if cfg.plugins == nil {
+		cfg.plugins = hooks.Enabled()
+	}
+
+	if cfg.feed == nil {
+		cfg.feed = orgfeed.Default()
+	}
+
+	if cfg.statuses == nil {
+		cfg.statuses = commitstatus.NewAPIStorage(httputils.GrequestsClient{})
+	}
+
+	if cfg.cancellations == nil {
+		cfg.cancellations = cancellation.Default()
+	}
+
+	repoCfg, err := repoconfig.Load(ctx, cfg.cfgFetcher, &c.Repo)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't load repo config, use the best we have: %s", err)
+	}
+
+	// retried and duplicate tasks of the commit mustn't overwrite its newer statuses
+	cfg.client = newStatusGuardClient(cfg.client, cfg.statuses, analysisGUID)
+
+	var dryRun *dryRunClient
+	if repoCfg.GetDryRun() {
+		dryRun = newDryRunClient(cfg.client)
+		cfg.client = dryRun
+		analytics.SaveEventProp(ctx, analytics.EventPRChecked, "dryRun", true)
 	}
 
 	if cfg.linters == nil {
 		cfg.linters = []linters.Linter{
 			golinters.GolangciLint{
-				PatchPath: patchPath,
+				PatchPath:      patchPath,
+				EnabledLinters: repoCfg.RequiredLinters,
+				Cache:          lintcache.Default(),
+				Repo:           c.Repo.FullName(),
+				SpellCheck:     repoCfg.GetSpellCheck(),
 			},
 		}
 	}
@@ -97,57 +274,90 @@ func newGithubGoPR(ctx context.Context, c *github.Context, cfg githubGoPRConfig,
 	envCfg := config.NewEnvConfig(log)
 	ec := experiments.NewChecker(envCfg, log)
 
+	if cfg.repoFetcher == nil {
+		tarballFromKB := envCfg.GetInt("REPO_TARBALL_FROM_MB", 0) * 1024
+		clone := fetchers.NewPartialGit(fetchers.NewGit(), envCfg.GetInt("REPO_PARTIAL_CLONE_FROM_MB", 0)*1024)
+		cfg.repoFetcher = fetchers.NewBySize(clone, fetchers.NewTarball(), tarballFromKB)
+	}
+
+	if cfg.infoFetcher == nil {
+		cfg.infoFetcher = repoinfo.NewCloningFetcher(cfg.repoFetcher)
+	}
+
 	if cfg.reporter == nil {
-		includeLinterName := ec.IsActiveForAnalysis("include_linter_name_in_comment", &c.Repo, true)
-		cfg.reporter = reporters.NewGithubReviewer(c, cfg.client, includeLinterName)
+		opts := reporters.GithubReviewerOptions{
+			IncludeLinterName: ec.IsActiveForAnalysis(ctx, "include_linter_name_in_comment", &c.Repo, true),
+			CommentTemplate:   repoCfg.CommentTemplate,
+			Lang:              repoCfg.Language,
+		}
+		if repoCfg.GetExplainIssues() {
+			opts.Docs = lintdocs.Default()
+		}
+		if rp := repoCfg.ReviewPolicy; rp != nil {
+			opts.RequestChangesSeverity, opts.Approve = rp.RequestChangesSeverity, rp.Approve
+			opts.Severity = issueSeverityFunc(repoCfg.QualityGate)
+		}
+		cfg.reporter = reporters.NewGithubReviewer(c, cfg.client, opts)
 	}
 
 	if cfg.runner == nil {
-		cfg.runner = linters.SimpleRunner{}
+		cfg.runner = linters.SimpleRunner{
+			LinterTimeout: envCfg.GetDuration("LINTER_TIMEOUT", 0),
+			MaxIssues:     envCfg.GetInt("LINTER_MAX_ISSUES", 0),
+		}
 	}
 
 	if cfg.state == nil {
 		cfg.state = prstate.NewAPIStorage(httputils.GrequestsClient{})
 	}
 
+	if cfg.usage == nil {
+		cfg.usage = usage.NewAPIReporter(httputils.GrequestsClient{})
+	}
+
 	var wi workspaces.Installer
 
-	if ec.IsActiveForAnalysis("new_pr_prepare", &c.Repo, true) {
+	if ec.IsActiveForAnalysis(ctx, "new_pr_prepare", &c.Repo, true) {
 		wi = workspaces.NewGo2(cfg.exec, log, cfg.repoFetcher)
 	}
 
 	return &githubGoPR{
 		context:               c,
+		repoCfg:               repoCfg,
+		msg:                   i18n.NewPrinter(repoCfg.Language),
 		githubGoPRConfig:      cfg,
 		analysisGUID:          analysisGUID,
 		newWorkspaceInstaller: wi,
 		ec:                    ec,
+		dryRun:                dryRun,
+		buildInfo:             buildinfo.New(),
+		maxPatchSize:          envCfg.GetInt("PATCH_MAX_SIZE_MB", defaultMaxPatchSizeMB) * mb,
+		maxRepoSizeMB:         envCfg.GetInt("REPO_MAX_SIZE_MB", 0),
+		previewInterval:       envCfg.GetDuration("PREVIEW_INTERVAL", defaultPreviewInterval),
+		generateTimeout:       envCfg.GetDuration("GO_GENERATE_TIMEOUT", defaultGenerateTimeout),
+		shadowRunTimeout:      envCfg.GetDuration("SHADOW_RUN_TIMEOUT", defaultShadowRunTimeout),
+		deployKeyExec:         deployKeyExec,
 	}, nil
 }
 
 func storePatch(ctx context.Context, patch string, exec executors.Executor) error {
-	f, err := ioutil.TempFile("/tmp", "golangci.diff")
-	defer os.Remove(f.Name())
-
-	if err != nil {
-		return fmt.Errorf("can't create temp file for patch: %s", err)
-	}
-	if err = ioutil.WriteFile(f.Name(), []byte(patch), os.ModePerm); err != nil {
-		return fmt.Errorf("can't write patch to temp file %s: %s", f.Name(), err)
-	}
-
-	if err = exec.CopyFile(ctx, patchPath, f.Name()); err != nil {
-		return fmt.Errorf("can't copy patch file: %s", err)
+	if err := exec.WriteFile(ctx, patchPath, []byte(patch)); err != nil {
+		return fmt.Errorf("can't write patch file: %s", err)
 	}
 
 	return nil
 }
 
-func (g githubGoPR) getRepo() *fetchers.Repo {
+func (g *githubGoPR) getRepo() *fetchers.Repo {
 	return &fetchers.Repo{
-		CloneURL: g.context.GetCloneURL(g.pr.GetHead().GetRepo()),
-		Ref:      g.pr.GetHead().GetRef(),
-		FullPath: fmt.Sprintf("github.com/%s/%s", g.context.Repo.Owner, g.context.Repo.Name),
+		CloneURL:  g.context.GetCloneURL(g.pr.GetHead().GetRepo()),
+		Ref:       g.pr.GetHead().GetRef(),
+		CommitSHA: g.pr.GetHead().GetSHA(),
+		FullPath:  fmt.Sprintf("github.com/%s/%s", g.context.Repo.Owner, g.context.Repo.Name),
+
+		SizeKB:      g.pr.GetHead().GetRepo().GetSize(),
+		TarballURL:  g.context.GetTarballURL(g.pr.GetHead().GetRepo(), g.pr.GetHead().GetSHA()),
+		SparsePaths: sparsePathsWithAnalysisPath(g.repoCfg.SparseCheckout, g.path),
 	}
 }
 
@@ -157,7 +367,7 @@ func (g *githubGoPR) prepareRepo(ctx context.Context) error {
 			for _, sg := range g.resLog.Groups {
 				for _, s := range sg.Steps {
 					if s.Error != "" {
-						text := fmt.Sprintf("%s error: %s", s.Description, s.Error)
+						text := g.msg.Sprintf(i18n.WarnPrepareStep, s.Description, s.Error)
 						text = escapeErrorText(text, g.buildSecrets())
 						g.publicWarn(sg.Name, text)
 					}
@@ -169,20 +379,36 @@ func (g *githubGoPR) prepareRepo(ctx context.Context) error {
 	}
 
 	repo := g.getRepo()
-	var err error
-	g.trackTiming("Clone", func() {
-		err = g.repoFetcher.Fetch(ctx, repo, g.exec)
+	err := g.trackStep("Clone", func() (string, error) {
+		if fetchErr := g.repoFetcher.Fetch(ctx, repo, g.exec); fetchErr != nil {
+			return "", &errorutils.InternalError{
+				PublicDesc:  "can't clone git repo",
+				PrivateDesc: fmt.Sprintf("can't clone git repo: %s", fetchErr),
+				Class:       errorutils.ClassProvider,
+			}
+		}
+		return "", nil
 	})
 	if err != nil {
-		return &errorutils.InternalError{
-			PublicDesc:  "can't clone git repo",
-			PrivateDesc: fmt.Sprintf("can't clone git repo: %s", err),
-		}
+		return err
+	}
+
+	verdict, err := g.checkGuardrails(ctx)
+	if err != nil {
+		return err
+	}
+	if verdict == repoguard.VerdictSandbox {
+		return nil // don't run any build steps
 	}
 
 	var depsRes *ensuredeps.Result
-	g.trackTiming("Deps", func() {
-		depsRes, err = g.gw.FetchDeps(ctx, repo.FullPath)
+	err = g.trackStep("Deps", func() (string, error) {
+		var depsErr error
+		depsRes, depsErr = g.gw.FetchDeps(ctx, repo.FullPath)
+		if depsErr != nil {
+			return "", depsErr
+		}
+		return formatDepsWarnings(depsRes), nil
 	})
 	if err != nil {
 		// don't public warn: it's an internal error
@@ -191,36 +417,97 @@ func (g *githubGoPR) prepareRepo(ctx context.Context) error {
 		analytics.Log(ctx).Infof("Got deps result: %#v", depsRes)
 
 		for _, w := range depsRes.Warnings {
-			warnText := fmt.Sprintf("Fetch deps: %s: %s", w.Kind, w.Text)
+			warnText := g.msg.Sprintf(i18n.WarnFetchDeps, w.Kind, w.Text)
 			warnText = escapeErrorText(warnText, g.buildSecrets())
 			g.publicWarn("prepare repo", warnText)
 
 			analytics.Log(ctx).Infof("Fetch deps warning: [%s]: %s", w.Kind, w.Text)
 		}
+
+		g.depGraph = buildDependencyGraph(ctx, g.exec, analytics.EventPRChecked)
 	}
 
 	return nil
 }
 
-func (g githubGoPR) updateAnalysisState(ctx context.Context, res *result.Result, status github.Status, publicError string) {
+// checkGuardrails scans the cloned repo for red flags before running any build steps
+func (g *githubGoPR) checkGuardrails(ctx context.Context) (repoguard.Verdict, error) {
+	var report *repoguard.Report
+	err := g.trackStep("Guardrails", func() (string, error) {
+		var scanErr error
+		report, scanErr = repoguard.Scan(ctx, g.exec, repoguard.DefaultLimits)
+		if scanErr != nil {
+			return "", scanErr
+		}
+		return strings.Join(report.Reasons, "\n"), nil
+	})
+	if err != nil {
+		// red flags are only heuristics: don't fail analysis because of them
+		analytics.Log(ctx).Warnf("Can't scan repo for red flags: %s", err)
+		return repoguard.VerdictOK, nil
+	}
+
+	analytics.SaveEventProp(ctx, analytics.EventPRChecked, "guardrailsVerdict", report.Verdict.String())
+	reasons := strings.Join(report.Reasons, "; ")
+
+	switch report.Verdict {
+	case repoguard.VerdictRefuse:
+		return report.Verdict, errorutils.ResourceLimit(fmt.Errorf("repo has red flags: %s", reasons),
+			fmt.Sprintf("analysis was refused: %s", reasons))
+	case repoguard.VerdictSandbox:
+		g.publicWarn("guardrails", g.msg.Sprintf(i18n.WarnSandboxed, reasons))
+	}
+
+	return report.Verdict, nil
+}
+
+func formatDepsWarnings(depsRes *ensuredeps.Result) string {
+	var lines []string
+	for _, w := range depsRes.Warnings {
+		lines = append(lines, fmt.Sprintf("%s: %s", w.Kind, w.Text))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (g githubGoPR) updateAnalysisState(ctx context.Context, res *result.Result, status github.Status,
+	publicError string, errClass errorutils.Class) {
 	resJSON := &resultJSON{
 		Version: 1,
 		WorkerRes: workerRes{
 			Timings:  g.timings,
 			Warnings: g.warnings,
+			Timeline: g.timeline,
 			Error:    publicError,
 		},
 	}
+	if g.dryRun != nil {
+		resJSON.WorkerRes.DryRun = g.dryRun.result()
+	}
+	resJSON.WorkerRes.redact(g.buildSecrets())
+	resJSON.WorkerRes.Artifacts = g.savedArtifacts
+	resJSON.WorkerRes.Dependencies = graphModules(g.depGraph)
+	resJSON.WorkerRes.Build = g.buildInfo
+	resJSON.WorkerRes.MergeBase = g.mergeBase
+	resJSON.WorkerRes.LintConfig = g.lintConfig
+	resJSON.WorkerRes.RepoMetadata = g.repoMeta
 
 	issuesCount := 0
 	if res != nil {
 		resJSON.GolangciLintRes = res.ResultJSON
-		issuesCount = len(res.Issues)
+		resJSON.WorkerRes.IssueCommits = buildIssueCommits(res.Issues)
+		resJSON.WorkerRes.InfoIssues = getInfoIssues(res.Issues)
+		resJSON.WorkerRes.TimedOutLinters = res.TimedOutLinters
+		resJSON.WorkerRes.IssuesCapped = res.Capped
+		resJSON.WorkerRes.IssueAnchors = buildIssueAnchors(res.Issues, g.detailsURL())
+		resJSON.WorkerRes.Annotations = buildAnnotations(ctx, res.Issues)
+		issuesCount = countBlockingIssues(res.Issues)
 	}
 	s := &prstate.State{
 		Status:              "processed/" + string(status),
 		ReportedIssuesCount: issuesCount,
 		ResultJSON:          resJSON,
+		ErrorClass:          string(errClass),
 	}
 
 	if err := g.state.UpdateState(ctx, g.context.Repo.Owner, g.context.Repo.Name, g.analysisGUID, s); err != nil {
@@ -228,47 +515,34 @@ func (g githubGoPR) updateAnalysisState(ctx context.Context, res *result.Result,
 	}
 }
 
-func getGithubStatusForIssues(issues []result.Issue) (github.Status, string) {
-	switch len(issues) {
-	case 0:
-		return github.StatusSuccess, "No issues found!"
-	case 1:
-		return github.StatusFailure, "1 issue found"
-	default:
-		return github.StatusFailure, fmt.Sprintf("%d issues found", len(issues))
-	}
-}
-
 func (g githubGoPR) buildSecrets() map[string]string {
-	const hidden = "{hidden}"
-	ret := map[string]string{
-		g.context.GithubAccessToken: hidden,
-		g.analysisGUID:              hidden,
-	}
-	if g.newWorkspaceInstaller == nil {
+	ret := redact.EnvSecrets()
+	ret[g.context.GithubAccessToken] = redact.Hidden
+	ret[g.analysisGUID] = redact.Hidden
+	if g.newWorkspaceInstaller == nil && g.gw != nil {
 		ret[g.gw.Gopath()] = "$GOPATH"
 	}
 
-	for _, kv := range os.Environ() {
-		parts := strings.Split(kv, "=")
-		if len(parts) != 2 {
-			continue
-		}
-
-		v := parts[1]
-		if len(v) >= 6 {
-			ret[v] = hidden
-		}
-	}
-
 	return ret
 }
 
-func (g *githubGoPR) processWithGuaranteedGithubStatus(ctx context.Context) error {
-	res, err := g.work(ctx)
+// finalize saves the result of analysis stages: state and commit status are set regardless of err
+func (g *githubGoPR) finalize(ctx context.Context, err error) error {
+	if _, ok := err.(*IgnoredError); !ok {
+		err = errorutils.WithAnalysisTimeout(ctx, err)
+	}
+	res := g.lintRes
+	if err != nil {
+		res = nil
+	}
+
 	analytics.Log(ctx).Infof("timings: %s", g.timings)
+	errClass := trackErrorClass(ctx, analytics.EventPRChecked, err)
+	g.buildInfo = stampBuildInfo(ctx, analytics.EventPRChecked, g.buildInfo)
+	g.warnTruncatedOutputs(ctx, g.msg)
 
 	ctx = context.Background() // no timeout for state and status saving: it must be durable
+	g.reportUsage(ctx)
 
 	var status github.Status
 	var statusDesc, publicError string
@@ -279,69 +553,177 @@ func (g *githubGoPR) processWithGuaranteedGithubStatus(ctx context.Context) erro
 				err = nil
 			}
 			// already must have warning, don't set publicError
-		} else if ierr, ok := err.(*errorutils.InternalError); ok {
-			if strings.Contains(ierr.PrivateDesc, noGoFilesToAnalyzeErr) {
-				status, statusDesc = github.StatusSuccess, noGoFilesToAnalyzeMessage
-				err = nil
-			} else {
-				status, statusDesc = github.StatusError, ierr.PublicDesc
-			}
+		} else if strings.Contains(err.Error(), noGoFilesToAnalyzeErr) {
+			status, statusDesc = github.StatusSuccess, g.msg.Sprintf(i18n.StatusNoGoFiles)
 			publicError = statusDesc
-		} else if berr, ok := err.(*errorutils.BadInputError); ok {
-			berr.PublicDesc = escapeErrorText(berr.PublicDesc, g.buildSecrets())
-			status, statusDesc = github.StatusError, "can't analyze"
-			publicError = berr.PublicDesc
 			err = nil
-			analytics.Log(ctx).Warnf("PR analysis bad input error: %s", berr)
 		} else {
-			status, statusDesc = github.StatusError, internalError
-			publicError = statusDesc
+			outcome := errorutils.OutcomeOf(err)
+			publicError = escapeErrorText(errorutils.PublicDesc(err), g.buildSecrets())
+			if publicError == "" {
+				publicError = internalError
+			}
+
+			status, statusDesc = outcome.Status, outcome.StatusDesc
+			if statusDesc == "" {
+				statusDesc = publicError
+			}
+
+			if !outcome.Retry {
+				analytics.Log(ctx).Warnf("PR analysis failed without retry (%s): %s", errorutils.KindOf(err), err)
+				err = nil
+			}
 		}
 	} else {
-		status, statusDesc = getGithubStatusForIssues(res.Issues)
+		status, statusDesc = g.statusForIssues(res.Issues)
+		if g.labelOpts.strict && len(g.warnings) != 0 {
+			status, statusDesc = github.StatusError, g.msg.Plural(i18n.StatusStrictWarnings, len(g.warnings))
+		}
 	}
 
-	// update of state must be before commit status update: user can open details link before: race condition
-	g.updateAnalysisState(ctx, res, status, publicError)
-	g.setCommitStatus(ctx, status, statusDesc)
-
+	// artifacts of unknown repos are considered private
+	private := g.pr.GetBase().GetRepo() == nil || g.pr.GetBase().GetRepo().GetPrivate()
+	g.savedArtifacts = saveArtifacts(ctx, g.artifacts, g.analysisGUID, private, analysisArtifacts{
+		lintRes:  res,
+		buildLog: g.resLog,
+		depGraph: g.depGraph,
+		bom:      g.sbom,
+		outputs:  g.lintOutputs,
+	}, g.buildSecrets())
+
+	g.saveResult(ctx, res, status, statusDesc, publicError, errClass)
+	g.publishFeedEvent(res, status)
 	return err
 }
 
-func (g *githubGoPR) work(ctx context.Context) (res *result.Result, err error) {
-	defer func() {
-		if rerr := recover(); rerr != nil {
-			err = &errorutils.InternalError{
-				PublicDesc:  "golangci-worker panic-ed",
-				PrivateDesc: fmt.Sprintf("panic occured: %s, %s", rerr, debug.Stack()),
-			}
-		}
-	}()
+// saveResult saves the analysis state and sets the final commit status
+func (g githubGoPR) saveResult(ctx context.Context, res *result.Result, status github.Status,
+	statusDesc, publicError string, errClass errorutils.Class) {
+	if g.dryRun != nil {
+		// status isn't posted: record it first to save it in the state
+		g.setCommitStatus(ctx, status, statusDesc)
+		g.updateAnalysisState(ctx, res, status, publicError, errClass)
+		return
+	}
 
+	// update of state must be before commit status update: user can open details link before: race condition
+	g.updateAnalysisState(ctx, res, status, publicError, errClass)
+	g.setCommitStatus(ctx, status, statusDesc)
+}
+
+func (g *githubGoPR) prepareRepoStage(ctx context.Context) error {
 	prState := strings.ToUpper(g.pr.GetState())
 	if prState == "MERGED" || prState == "CLOSED" {
 		// branch can be deleted: will be an error; no need to analyze
-		g.publicWarn("process", fmt.Sprintf("Pull Request is already %s, skip analysis", prState))
+		warning, statusDesc := i18n.WarnPRClosed, i18n.StatusPRClosed
+		if prState == "MERGED" {
+			warning, statusDesc = i18n.WarnPRMerged, i18n.StatusPRMerged
+		}
+		g.publicWarn("process", g.msg.Sprintf(warning))
 		analytics.Log(ctx).Warnf("Pull Request is already %s, skip analysis", prState)
-		return nil, &IgnoredError{
+		g.closeIssueAging(ctx)
+		return &IgnoredError{
 			Status:        github.StatusSuccess,
-			StatusDesc:    fmt.Sprintf("Pull Request is already %s", strings.ToLower(prState)),
+			StatusDesc:    g.msg.Sprintf(statusDesc),
 			IsRecoverable: false,
 		}
 	}
 
-	if err = g.prepareRepo(ctx); err != nil {
-		return nil, err // don't wrap error, need to save it's type
+	// the merge-base scoping is the last fetch: linters must not use the deploy key
+	defer deploykeys.StopAgent(ctx, g.deployKeyExec)
+
+	if err := g.prepareRepo(ctx); err != nil {
+		return err
+	}
+	if g.path != "" {
+		if err := checkAnalysisPath(ctx, g.exec, g.path); err != nil {
+			return err
+		}
 	}
 
-	g.trackTiming("Analysis", func() {
-		res, err = g.runner.Run(ctx, g.linters, g.exec)
-	})
-	if err != nil {
-		return nil, err // don't wrap error, need to save it's type
+	g.repoMeta = fetchRepoMetadata(ctx, g.infoFetcher, g.exec, analytics.EventPRChecked)
+	if skipNotGoRepo(ctx, g.repoMeta, analytics.EventPRChecked, g.publicWarn) {
+		return &IgnoredError{
+			Status:        github.StatusSuccess,
+			StatusDesc:    g.msg.Sprintf(i18n.StatusNotGoRepo),
+			IsRecoverable: false,
+		}
+	}
+
+	if g.rebuiltPatch == nil { // the rebuilt patch has no modes and is replaced by the merge-base diff
+		if err := g.checkPatchApplies(ctx); err != nil {
+			return err
+		}
+	}
+	g.scopeByMergeBase(ctx)
+	g.findAttributionBase(ctx)
+	if err := g.checkRebuiltPatch(ctx); err != nil {
+		return err
+	}
+	g.checkLintConfig(ctx)
+
+	g.buildInfo.Collect(ctx, g.exec)
+	return nil
+}
+
+// lint can be retried: it doesn't change the workspace
+func (g *githubGoPR) lint(ctx context.Context) error {
+	g.cpus = tuneExecutor(ctx, g.exec, analytics.EventPRChecked)
+	g.linters = withConcurrency(g.linters, g.cpus)
+	projects := len(g.repoCfg.Projects) != 0 && g.path == "" // the analysis path overrides projects
+	if !projects {
+		// parallel runs of projects would overwrite profiles of each other
+		g.linters = withProfiles(g.linters, g.artifacts)
+	}
+	var onIssue linters.IssueFunc // issues are streamed only for previews
+	if g.previewInterval > 0 {
+		p := &preview{interval: g.previewInterval, publish: func(issues []result.Issue) {
+			g.publishPreview(ctx, issues)
+		}}
+		onIssue = p.addIssue
 	}
 
-	issues := res.Issues
+	return g.trackStep("Analysis", func() (string, error) {
+		var res *result.Result
+		var runErr error
+		if projects {
+			res, runErr = g.lintProjects(ctx, onIssue)
+		} else if g.plan.IsHardCapExceeded() {
+			res, runErr = g.lintChangedPackages(ctx, onIssue)
+		} else {
+			res, runErr = g.lintIncrementally(ctx, onIssue)
+		}
+		if runErr != nil {
+			return "", runErr
+		}
+		if len(res.TimedOutLinters) != 0 {
+			g.publicWarn("analysis", timedOutLintersWarning(g.msg, res.TimedOutLinters))
+		}
+		if len(res.FailedLinters) != 0 {
+			g.publicWarn("analysis", failedLintersWarning(g.msg, res.FailedLinters))
+		}
+		if res.Capped {
+			g.publicWarn("analysis", issuesCappedWarning(g.msg))
+		}
+
+		if g.repoCfg.GetDependencyFreshness() && isGoModChanged(getPatchFiles(g.patch)) {
+			appendDepsIssues(ctx, golinters.DepsFreshness{}, g.exec, res)
+		}
+		if g.repoCfg.GetAPICompatibility() {
+			g.appendAPICompatIssues(ctx, res)
+		}
+		if g.repoCfg.FormatPolicy != nil {
+			g.appendFormatIssues(ctx, res)
+		}
+
+		g.lintRes = res
+		g.lintOutputs = collectLintOutputs(ctx, g.exec, g.artifacts, res)
+		return fmt.Sprintf("%d issues found", len(res.Issues)), nil
+	})
+}
+
+func (g *githubGoPR) report(ctx context.Context) error {
+	issues := g.lintRes.Issues
 	analytics.SaveEventProp(ctx, analytics.EventPRChecked, "reportedIssues", len(issues))
 
 	if len(issues) == 0 {
@@ -350,82 +732,231 @@ func (g *githubGoPR) work(ctx context.Context) (res *result.Result, err error) {
 		analytics.Log(ctx).Infof("Linters found %d issues: %+v", len(issues), issues)
 	}
 
-	if err = g.reporter.Report(ctx, g.pr.GetHead().GetSHA(), issues); err != nil {
-		return nil, &errorutils.InternalError{
-			PublicDesc:  "can't send pull request comments to github",
-			PrivateDesc: fmt.Sprintf("can't send pull request comments to github: %s", err),
+	return g.trackStep("Report", func() (string, error) {
+		if reportErr := g.reporter.Report(ctx, g.pr.GetHead().GetSHA(), issues); reportErr != nil {
+			return "", &errorutils.InternalError{
+				PublicDesc:  "can't send pull request comments to github",
+				PrivateDesc: fmt.Sprintf("can't send pull request comments to github: %s", reportErr),
+				Class:       errorutils.ClassProvider,
+			}
 		}
+		return fmt.Sprintf("%d issues reported", len(issues)), nil
+	})
+}
+
+func (g *githubGoPR) detailsURL() string {
+	c := g.context
+	return fmt.Sprintf("%s/r/github.com/%s/%s/pulls/%d",
+		os.Getenv("WEB_ROOT"), c.Repo.Owner, c.Repo.Name, g.pr.GetNumber())
+}
+
+func (g *githubGoPR) statusURL(status github.Status) string {
+	if status != github.StatusFailure && status != github.StatusSuccess && status != github.StatusError {
+		return ""
 	}
 
-	return res, nil
+	return g.detailsURL()
 }
 
-func (g githubGoPR) setCommitStatus(ctx context.Context, status github.Status, desc string) {
-	var url string
-	if status == github.StatusFailure || status == github.StatusSuccess || status == github.StatusError {
-		c := g.context
-		url = fmt.Sprintf("%s/r/github.com/%s/%s/pulls/%d",
-			os.Getenv("WEB_ROOT"), c.Repo.Owner, c.Repo.Name, g.pr.GetNumber())
+func (g *githubGoPR) setCommitStatus(ctx context.Context, status github.Status, desc string) {
+	url := g.statusURL(status)
+	if status == github.StatusFailure && g.lintRes != nil {
+		// deep link to the first issue: it's usually the only one to look at
+		if anchors := buildIssueAnchors(g.lintRes.Issues, url); len(anchors) != 0 {
+			url = anchors[0].URL
+		}
 	}
-	err := g.client.SetCommitStatus(ctx, g.context, g.pr.GetHead().GetSHA(), status, desc, url)
+
+	err := g.client.SetCommitStatus(ctx, g.context, g.pr.GetHead().GetSHA(), status, truncateStatusDesc(desc), url)
 	if err != nil {
-		g.publicWarn("github", "Can't set github commit status")
+		g.publicWarn("github", g.msg.Sprintf(i18n.WarnCommitStatus))
 		analytics.Log(ctx).Warnf("Can't set github commit status: %s", err)
 	}
 }
 
-//nolint:gocyclo
-func (g githubGoPR) Process(ctx context.Context) error {
-	defer g.exec.Clean()
+func (g *githubGoPR) skipAnalysis(ctx context.Context, warnText, statusDesc string) {
+	analytics.Log(ctx).Infof("Skip analysis: %s", warnText)
+	g.publicWarn("process", warnText)
+	g.saveResult(ctx, nil, github.StatusSuccess, statusDesc, "", errorutils.ClassNone)
+}
 
-	var err error
-	g.pr, err = g.client.GetPullRequest(ctx, g.context)
-	if err != nil {
-		if !github.IsRecoverableError(err) {
-			return err // preserve error
+// checkPatch runs concurrently with setupWorkspace: it mustn't touch the workspace and the executor
+func (g *githubGoPR) checkPatch(ctx context.Context, fp *fetchedPatch) (string, error) {
+	g.addStep("Fetch patch", fp.startedAt, fp.finishedAt, "", fp.err)
+	if fp.err != nil {
+		if !github.IsRecoverableError(fp.err) {
+			return "", fp.err // preserve error
 		}
-		return fmt.Errorf("can't get pull request: %s", err)
+		return "", fmt.Errorf("can't get patch: %s", fp.err)
+	}
+
+	fetched := fp.patch
+	if fp.rebuilt != nil {
+		analytics.Log(ctx).Warnf("Got rebuilt patch: %s", fp.rebuilt)
+		g.rebuiltPatch = fp.rebuilt
+		fetched = fp.rebuilt.Patch
+	}
+
+	if err := validatePatch(fetched, g.maxPatchSize); err != nil {
+		return "", &patchValidationError{err: err}
+	}
+
+	patch := scopePatchToPath(fetched, g.path)
+	if patch == "" {
+		return "", errNoChangesInPath
+	}
+
+	if g.repoCfg.AllPathsSkipped(getPatchFiles(patch)) {
+		return "", errAllPathsSkipped
 	}
 
-	g.setCommitStatus(ctx, github.StatusPending, "GolangCI is reviewing your Pull Request...")
+	g.setCommitStatus(ctx, github.StatusPending, g.msg.Sprintf(i18n.StatusReviewing))
+	return patch, nil
+}
 
+// setupWorkspace runs concurrently with checkPatch: it mustn't touch results
+func (g *githubGoPR) setupWorkspace(ctx context.Context) error {
 	if g.newWorkspaceInstaller == nil {
 		g.gw = workspaces.NewGo(g.exec, g.infoFetcher)
-		if err = g.gw.Setup(ctx, g.getRepo(), "github.com", g.context.Repo.Owner, g.context.Repo.Name); err != nil {
-			publicError := fmt.Sprintf("failed to setup workspace: %s", err)
-			publicError = escapeErrorText(publicError, g.buildSecrets())
-			g.updateAnalysisState(ctx, nil, github.StatusError, publicError)
-			g.setCommitStatus(ctx, github.StatusError, "failed to setup")
-
-			return fmt.Errorf("can't setup go workspace: %s", err)
+		if err := g.gw.Setup(ctx, g.getRepo(), "github.com", g.context.Repo.Owner, g.context.Repo.Name); err != nil {
+			return &workspaceSetupError{err: err, retry: true}
 		}
-		defer g.gw.Clean(ctx)
+
 		g.exec = g.gw.Executor()
-	} else {
-		startedAt := time.Now()
-		exec, resLog, err := g.newWorkspaceInstaller.Setup(ctx, g.getRepo(), "github.com", g.context.Repo.Owner, g.context.Repo.Name) //nolint:govet
-		if err != nil {
-			publicError := fmt.Sprintf("failed to setup workspace: %s", err)
-			publicError = escapeErrorText(publicError, g.buildSecrets())
-			g.updateAnalysisState(ctx, nil, github.StatusError, publicError)
-			g.setCommitStatus(ctx, github.StatusError, "failed to setup")
+		return nil
+	}
 
-			return nil
-		}
-		g.exec = exec
-		g.resLog = resLog
-		g.addTimingFrom("Prepare", startedAt)
+	exec, resLog, err := g.newWorkspaceInstaller.Setup(ctx, g.getRepo(), "github.com", g.context.Repo.Owner, g.context.Repo.Name)
+	if err != nil {
+		return &workspaceSetupError{err: err}
+	}
+
+	g.exec = exec
+	g.resLog = resLog
+	return nil
+}
+
+// failBeforeAnalysis saves the result of the error found before the workspace is ready, e.g. an exceeded limit
+func (g *githubGoPR) failBeforeAnalysis(ctx context.Context, err error) error {
+	publicError := escapeErrorText(errorutils.PublicDesc(err), g.buildSecrets())
+	errClass := trackErrorClass(ctx, analytics.EventPRChecked, err)
+	g.saveResult(ctx, nil, errorutils.OutcomeOf(err).Status, publicError, publicError, errClass)
+	return errStopPipeline
+}
+
+func (g *githubGoPR) handlePrepareError(ctx context.Context, err error) error {
+	if err == errAllPathsSkipped {
+		g.skipAnalysis(ctx, g.msg.Sprintf(i18n.WarnAllPathsSkipped), g.msg.Sprintf(i18n.StatusNoGoFiles))
+		return errStopPipeline
 	}
+	if err == errNoChangesInPath {
+		g.skipAnalysis(ctx, g.msg.Sprintf(i18n.WarnNoChangesInPath, g.path),
+			g.msg.Sprintf(i18n.StatusNoProjectChanges))
+		return errStopPipeline
+	}
+
+	if perr, ok := err.(*patchValidationError); ok {
+		return g.failBeforeAnalysis(ctx, perr.err)
+	}
+
+	serr, ok := err.(*workspaceSetupError)
+	if !ok {
+		return err
+	}
+
+	publicError := fmt.Sprintf("failed to setup workspace: %s", serr.err)
+	publicError = escapeErrorText(publicError, g.buildSecrets())
+	errClass := trackErrorClass(ctx, analytics.EventPRChecked, serr.err)
+	g.saveResult(ctx, nil, github.StatusError, "failed to setup", publicError, errClass)
+
+	if serr.retry {
+		return fmt.Errorf("can't setup go workspace: %s", serr.err)
+	}
+	return errStopPipeline
+}
 
-	patch, err := g.client.GetPullRequestPatch(ctx, g.context)
+func (g *githubGoPR) fetchPullRequest(ctx context.Context) error {
+	tokenScopes := g.fetchTokenScopes(ctx)
+	if err := validateTokenScopes(tokenScopes, false); err != nil {
+		return g.failOnTokenScopes(ctx, err)
+	}
+
+	var err error
+	g.pr, err = g.client.GetPullRequest(ctx, g.context)
 	if err != nil {
 		if !github.IsRecoverableError(err) {
 			return err // preserve error
 		}
-		return fmt.Errorf("can't get patch: %s", err)
+		return fmt.Errorf("can't get pull request: %s", err)
+	}
+
+	if g.killSwitch != nil { // the status needs the head commit of the pull request
+		g.skipAnalysis(ctx, killSwitchWarning(g.msg, g.killSwitch), g.msg.Sprintf(i18n.StatusDisabled))
+		return errStopPipeline
+	}
+
+	if err = validateTokenScopes(tokenScopes, g.pr.GetBase().GetRepo().GetPrivate()); err != nil {
+		return g.failOnTokenScopes(ctx, err)
 	}
 
-	if err = storePatch(ctx, patch, g.exec); err != nil {
+	g.labelOpts = g.labelOptions(ctx)
+	if g.labelOpts.skip {
+		g.skipAnalysis(ctx, g.msg.Sprintf(i18n.WarnSkippedByLabel, labelSkip),
+			fmt.Sprintf("skipped by label %s", labelSkip))
+		return errStopPipeline
+	}
+	if g.labelOpts.fullRepo {
+		g.linters = withoutPatch(g.linters)
+	}
+	if g.path, err = cleanAnalysisPath(g.context.Path); err != nil {
+		return g.failBeforeAnalysis(ctx, err)
+	}
+	g.linters = withAnalysisPath(g.linters, g.path)
+	g.detectNewcomer(ctx)
+
+	return g.checkRepoSize(ctx)
+}
+
+// prepareWorkspace overlaps the workspace setup with the patch check: they are independent
+func (g *githubGoPR) prepareWorkspace(ctx context.Context) error {
+	var setupStartedAt, setupFinishedAt time.Time
+	eg, egCtx := errgroup.WithContext(ctx)
+	patchChecked := make(chan struct{})
+	eg.Go(func() error {
+		var checkErr error
+		if g.patch, checkErr = g.checkPatch(ctx, g.asyncPatch.wait()); checkErr != nil {
+			return checkErr
+		}
+		close(patchChecked)
+		return nil
+	})
+	eg.Go(func() error {
+		if len(g.repoCfg.SkipPaths) != 0 || g.path != "" {
+			// analysis can be skipped by changed paths: don't waste executor time before the check
+			select {
+			case <-patchChecked:
+			case <-egCtx.Done():
+				return egCtx.Err()
+			}
+		}
+
+		setupStartedAt = time.Now()
+		setupErr := g.setupWorkspace(egCtx)
+		setupFinishedAt = time.Now()
+		return setupErr
+	})
+	if err := eg.Wait(); err != nil {
+		return g.handlePrepareError(ctx, err)
+	}
+
+	if g.newWorkspaceInstaller != nil {
+		g.addTiming("Prepare", setupStartedAt, setupFinishedAt)
+	}
+	return nil
+}
+
+func (g *githubGoPR) storePatch(ctx context.Context) error {
+	if err := storePatch(ctx, g.patch, g.exec); err != nil {
 		return fmt.Errorf("can't store patch: %s", err)
 	}
 
@@ -433,14 +964,94 @@ func (g githubGoPR) Process(ctx context.Context) error {
 	if err != nil {
 		analytics.Log(ctx).Warnf("Can't get current state: %s", err)
 	} else if curState.Status == statusSentToQueue {
-		g.addTimingFrom("In Queue", fromDBTime(curState.CreatedAt))
-		inQueue := time.Since(fromDBTime(curState.CreatedAt))
-		analytics.SaveEventProp(ctx, analytics.EventPRChecked, "inQueueSeconds", int(inQueue/time.Second))
+		if inQueue, ok := g.trackQueueTime(ctx, "analyzeV2", curState.CreatedAt); ok {
+			analytics.SaveEventProp(ctx, analytics.EventPRChecked, "inQueueSeconds", int(inQueue/time.Second))
+		}
 		curState.Status = statusProcessing
 		if err = g.state.UpdateState(ctx, g.context.Repo.Owner, g.context.Repo.Name, g.analysisGUID, curState); err != nil {
 			analytics.Log(ctx).Warnf("Can't update analysis %s state with setting status to 'processing': %s", g.analysisGUID, err)
 		}
 	}
 
-	return g.processWithGuaranteedGithubStatus(ctx)
+	return nil
+}
+
+func (g githubGoPR) Process(ctx context.Context) error {
+	// shadow runs use the workspace after return: they clean it themselves
+	exec, shadowing := g.exec, false
+	defer func() {
+		if !shadowing {
+			exec.Clean()
+		}
+	}()
+	if g.isHardKilled(ctx) {
+		return nil
+	}
+
+	g.startedAt = time.Now()
+	g.plan = usage.PlanFromContext(ctx)
+	ctx = executors.ContextWithTruncationRecorder(ctx, executors.NewTruncationRecorder())
+
+	// closing of the pull request cancels ctx: running commands are killed
+	ctx, g.cancelWatcher = cancellation.Watch(ctx, g.cancellations, g.analysisGUID, cancellationCheckInterval)
+	defer g.cancelWatcher.Stop()
+
+	// the patch doesn't depend on other requests: fetch it in the background
+	patchCtx, cancelPatch := context.WithCancel(ctx)
+	g.asyncPatch = g.fetchPatchAsync(patchCtx)
+	defer func() {
+		cancelPatch()
+		g.asyncPatch.wait() // don't leave the request running after return
+	}()
+	defer func() {
+		if g.gw != nil && !shadowing {
+			g.gw.Clean(ctx)
+		}
+	}()
+
+	// errors of preparation are returned before the analysis state is set to processing
+	err := newPipeline(
+		stage{name: "fetch pull request", run: g.fetchPullRequest},
+		stage{name: "prepare workspace", run: g.prepareWorkspace},
+	).use(logStage, g.checkpointStage, g.cancelledStage).run(ctx)
+	if err != nil {
+		if err == errStopPipeline {
+			return nil
+		}
+		if g.cancelWatcher.Reason() != "" {
+			if g.pr == nil { // nothing was posted to the pull request yet
+				return nil
+			}
+			return g.finalize(ctx, g.cancelledError(ctx, err))
+		}
+		return err
+	}
+
+	if warning := g.plan.Warning(g.msg); warning != "" {
+		g.publicWarn("budget", warning)
+	}
+
+	err = newPipeline(
+		stage{name: "store patch", run: g.storePatch},
+		stage{name: "prepare repo", run: g.prepareRepoStage},
+		stage{name: "fast feedback", run: g.fastFeedback},
+		stage{name: "lint", run: g.lint},
+		stage{name: "verify generate", run: g.verifyGenerate},
+		stage{name: "sbom", run: g.generateSBOM},
+		stage{name: "canary", run: g.runCanary},
+		stage{name: "shadow prepare", run: g.shadowPrepare},
+		stage{name: "exposed issues", run: g.addExposedIssues},
+		stage{name: "scope issues", run: g.scopeIssues},
+		stage{name: "attribute commits", run: g.attributeCommits},
+		stage{name: "issue aging", run: g.trackIssueAging},
+		stage{name: "baseline", run: g.fetchBaseline},
+		stage{name: "suppressions", run: g.dropSuppressedIssues},
+		stage{name: "report", run: g.report},
+	).use(logStage, g.checkpointStage, g.cancelledStage, recoverStage, g.callHooks, g.retryFlaky("lint")).run(ctx)
+	if err = g.finalize(ctx, g.cancelledError(ctx, err)); err != nil {
+		return err
+	}
+
+	shadowing = g.startShadowRuns(ctx, exec)
+	return nil
 }
@@ -2,9 +2,12 @@ package processors
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -12,14 +15,26 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/cancellation"
+	"github.com/golangci/golangci-worker/app/analyze/commitstatus"
+	"github.com/golangci/golangci-worker/app/analyze/issueaging"
+	"github.com/golangci/golangci-worker/app/analyze/issuecache"
+	"github.com/golangci/golangci-worker/app/analyze/killswitch"
 	"github.com/golangci/golangci-worker/app/analyze/linters"
 	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/orgfeed"
 	"github.com/golangci/golangci-worker/app/analyze/prstate"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
 	"github.com/golangci/golangci-worker/app/analyze/repoinfo"
 	"github.com/golangci/golangci-worker/app/analyze/reporters"
+	"github.com/golangci/golangci-worker/app/analyze/repostate"
+	"github.com/golangci/golangci-worker/app/analyze/suppressions"
 	"github.com/golangci/golangci-worker/app/lib/executors"
 	"github.com/golangci/golangci-worker/app/lib/fetchers"
 	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/github/githubfake"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+	"github.com/golangci/golangci-worker/app/lib/usage"
 	"github.com/golangci/golangci-worker/app/test"
 	gh "github.com/google/go-github/github"
 	"github.com/stretchr/testify/assert"
@@ -51,6 +66,15 @@ var testPR = &gh.PullRequest{
 }
 var testAnalysisGUID = "test-guid"
 
+func withLabels(pr *gh.PullRequest, labels ...string) *gh.PullRequest {
+	ret := *pr
+	ret.Labels = nil
+	for _, l := range labels {
+		ret.Labels = append(ret.Labels, &gh.Label{Name: gh.String(l)})
+	}
+	return &ret
+}
+
 func getFakeLinters(ctrl *gomock.Controller, issues ...result.Issue) []linters.Linter {
 	a := linters.NewMockLinter(ctrl)
 	a.EXPECT().
@@ -76,6 +100,7 @@ func getNopReporter(ctrl *gomock.Controller) reporters.Reporter {
 func getNopInfoFetcher(ctrl *gomock.Controller) repoinfo.Fetcher {
 	r := repoinfo.NewMockFetcher(ctrl)
 	r.EXPECT().Fetch(testCtxMatcher, any, any).AnyTimes().Return(&repoinfo.Info{}, nil)
+	r.EXPECT().FetchMetadata(testCtxMatcher, any).AnyTimes().Return(&repoinfo.Metadata{}, nil)
 	return r
 }
 
@@ -94,6 +119,13 @@ func getNopState(ctrl *gomock.Controller) prstate.Storage {
 	return r
 }
 
+func getNopConfigFetcher(ctrl *gomock.Controller) repoconfig.Fetcher {
+	f := repoconfig.NewMockFetcher(ctrl)
+	f.EXPECT().FetchOrgDefaults(any, any).AnyTimes().Return(&repoconfig.Config{}, nil)
+	f.EXPECT().FetchRepoConfig(any, any).AnyTimes().Return(&repoconfig.Config{}, nil)
+	return f
+}
+
 func getNopExecutor(ctrl *gomock.Controller) executors.Executor {
 	e := executors.NewMockExecutor(ctrl)
 	e.EXPECT().WorkDir().Return("").AnyTimes()
@@ -102,7 +134,7 @@ func getNopExecutor(ctrl *gomock.Controller) executors.Executor {
 	e.EXPECT().Run(testCtxMatcher, any, any, any).Return("", nil).AnyTimes()
 	e.EXPECT().Clean().AnyTimes()
 	e.EXPECT().SetEnv(any, any).AnyTimes()
-	e.EXPECT().CopyFile(any, any, any).Return(nil)
+	e.EXPECT().WriteFile(any, patchPath, any).Return(nil)
 	return e
 }
 
@@ -116,6 +148,7 @@ func getFakeStatusGithubClient(t *testing.T, ctrl *gomock.Controller, status git
 	c := &github.FakeContext
 	gc := github.NewMockClient(ctrl)
 	gc.EXPECT().GetPullRequest(testCtxMatcher, c).Return(testPR, nil)
+	gc.EXPECT().GetTokenScopes(testCtxMatcher, c).AnyTimes().Return(&github.TokenScopes{}, nil)
 
 	scsPending := gc.EXPECT().SetCommitStatus(testCtxMatcher, c, testSHA,
 		github.StatusPending, "GolangCI is reviewing your Pull Request...", "").
@@ -125,6 +158,9 @@ func getFakeStatusGithubClient(t *testing.T, ctrl *gomock.Controller, status git
 
 	test.Init()
 	url := fmt.Sprintf("%s/r/github.com/%s/%s/pulls/%d", os.Getenv("WEB_ROOT"), c.Repo.Owner, c.Repo.Name, testPR.GetNumber())
+	if status == github.StatusFailure { // deep link to the first issue: it's the same as fakeChangedIssue in tests
+		url += "#" + anchorName(result.Fingerprints([]result.Issue{fakeChangedIssue})[0])
+	}
 	gc.EXPECT().SetCommitStatus(testCtxMatcher, c, testSHA, status, statusDesc, url).After(scsPending)
 
 	return gc
@@ -136,7 +172,8 @@ func getNopGithubClient(t *testing.T, ctrl *gomock.Controller) github.Client {
 	gc := github.NewMockClient(ctrl)
 	gc.EXPECT().CreateReview(any, any, any).AnyTimes()
 	gc.EXPECT().GetPullRequest(testCtxMatcher, c).AnyTimes().Return(testPR, nil)
-	gc.EXPECT().GetPullRequestPatch(any, any).AnyTimes().Return(getFakePatch(t))
+	gc.EXPECT().GetTokenScopes(testCtxMatcher, c).AnyTimes().Return(&github.TokenScopes{}, nil)
+	gc.EXPECT().GetPullRequestPatch(any, any).AnyTimes().Return(getFakePatch(t), nil)
 	gc.EXPECT().SetCommitStatus(any, any, testSHA, any, any, any).AnyTimes()
 	return gc
 }
@@ -163,6 +200,50 @@ func fillWithNops(t *testing.T, ctrl *gomock.Controller, cfg *githubGoPRConfig)
 	if cfg.state == nil {
 		cfg.state = getNopState(ctrl)
 	}
+	if cfg.cfgFetcher == nil {
+		cfg.cfgFetcher = getNopConfigFetcher(ctrl)
+	}
+	if cfg.usage == nil {
+		cfg.usage = getNopUsageReporter(ctrl)
+	}
+	if cfg.killSwitches == nil {
+		cfg.killSwitches = getKillSwitches(ctrl)
+	}
+	if cfg.suppressions == nil {
+		s := suppressions.NewMockStorage(ctrl)
+		s.EXPECT().List(any, any, any).AnyTimes().Return(nil, nil)
+		cfg.suppressions = s
+	}
+	if cfg.issueAging == nil {
+		cfg.issueAging = issueaging.NopStorage{}
+	}
+	if cfg.issueCache == nil {
+		cfg.issueCache = issuecache.NopStorage{}
+	}
+	if cfg.baselines == nil {
+		cfg.baselines = repostate.NopStorage{}
+	}
+	if cfg.feed == nil {
+		cfg.feed = orgfeed.NopPublisher{}
+	}
+	if cfg.statuses == nil {
+		cfg.statuses = commitstatus.NopStorage{}
+	}
+	if cfg.cancellations == nil {
+		cfg.cancellations = cancellation.NopRegistry{}
+	}
+}
+
+func getKillSwitches(ctrl *gomock.Controller, entries ...killswitch.Entry) killswitch.Fetcher {
+	f := killswitch.NewMockFetcher(ctrl)
+	f.EXPECT().Fetch(any).AnyTimes().Return(&killswitch.List{Entries: entries}, nil)
+	return f
+}
+
+func getNopUsageReporter(ctrl *gomock.Controller) usage.Reporter {
+	r := usage.NewMockReporter(ctrl)
+	r.EXPECT().Report(any, any, any, any).AnyTimes().Return(nil)
+	return r
 }
 
 func getNopedProcessor(t *testing.T, ctrl *gomock.Controller, cfg githubGoPRConfig) *githubGoPR {
@@ -211,6 +292,60 @@ func TestSetCommitStatusFailureTwoIssues(t *testing.T) {
 	})
 }
 
+func TestIssueAgingIsSaved(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := &github.FakeContext
+	storage := issueaging.NewMockStorage(ctrl)
+	storage.EXPECT().GetLast(any, c.Repo.Owner, c.Repo.Name, testPR.GetNumber()).Return(nil, errors.New("no API"))
+	testProcessor(t, ctrl, githubGoPRConfig{
+		linters:    getFakeLinters(ctrl, fakeChangedIssue),
+		client:     getFakeStatusGithubClient(t, ctrl, github.StatusFailure, "1 issue found"),
+		issueAging: storage,
+	})
+
+	storage.EXPECT().GetLast(any, c.Repo.Owner, c.Repo.Name, testPR.GetNumber()).Return(nil, nil)
+	storage.EXPECT().Put(any, c.Repo.Owner, c.Repo.Name, testPR.GetNumber(), gomock.Any()).
+		Do(func(_ context.Context, _, _ string, _ int, s *issueaging.Snapshot) {
+			assert.Equal(t, testSHA, s.CommitSHA)
+			assert.Len(t, s.Issues, 1)
+		})
+	testProcessor(t, ctrl, githubGoPRConfig{
+		linters:    getFakeLinters(ctrl, fakeChangedIssue),
+		client:     getFakeStatusGithubClient(t, ctrl, github.StatusFailure, "1 issue found"),
+		issueAging: storage,
+	})
+}
+
+func TestStatusComparesIssuesWithBaseBranch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := &github.FakeContext
+	baselines := repostate.NewMockStorage(ctrl)
+	// the issue of the base branch is on the other line: lines above it were changed by the PR
+	baselines.EXPECT().GetLastState(any, c.Repo.Owner, c.Repo.Name, testPR.GetBase().GetRef()).Return(&repostate.State{
+		Status:     statusProcessed,
+		ResultJSON: json.RawMessage(`{"GolangciLintRes":{"Issues":[{"FromLinter":"linter2","Text":"F1 issue","Pos":{"Filename":"main.go","Line":3}}]}}`),
+	}, nil)
+	testProcessor(t, ctrl, githubGoPRConfig{
+		linters:   getFakeLinters(ctrl, fakeChangedIssue),
+		client:    getFakeStatusGithubClient(t, ctrl, github.StatusFailure, "0 new issues, 1 pre-existing"),
+		baselines: baselines,
+	})
+
+	// the last analysis of the base branch isn't finished
+	baselines.EXPECT().GetLastState(any, c.Repo.Owner, c.Repo.Name, testPR.GetBase().GetRef()).Return(&repostate.State{
+		Status: statusProcessing,
+	}, nil)
+	testProcessor(t, ctrl, githubGoPRConfig{
+		linters:   getFakeLinters(ctrl, fakeChangedIssue),
+		client:    getFakeStatusGithubClient(t, ctrl, github.StatusFailure, "1 issue found"),
+		baselines: baselines,
+	})
+}
+
 func TestSetCommitStatusOnReportingError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -224,6 +359,269 @@ func TestSetCommitStatusOnReportingError(t *testing.T) {
 	assert.Error(t, p.Process(testCtx))
 }
 
+func TestSkipByLabel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequest(testCtxMatcher, c).Return(withLabels(testPR, "bug", labelSkip), nil)
+	gc.EXPECT().GetTokenScopes(testCtxMatcher, c).Return(&github.TokenScopes{}, nil)
+	gc.EXPECT().GetPullRequestPatch(testCtxMatcher, c).Return(getFakePatch(t), nil) // fetched in the background
+
+	test.Init()
+	url := fmt.Sprintf("%s/r/github.com/%s/%s/pulls/%d", os.Getenv("WEB_ROOT"), c.Repo.Owner, c.Repo.Name, testPR.GetNumber())
+	gc.EXPECT().SetCommitStatus(testCtxMatcher, c, testSHA, github.StatusSuccess, "skipped by label golangci:skip", url)
+
+	// nothing must be cloned and run
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Clean()
+	testProcessor(t, ctrl, githubGoPRConfig{
+		linters:     []linters.Linter{linters.NewMockLinter(ctrl)},
+		repoFetcher: fetchers.NewMockFetcher(ctrl),
+		exec:        exec,
+		client:      gc,
+	})
+}
+
+func TestDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl) // no expectations of SetCommitStatus: nothing must be posted
+	gc.EXPECT().GetPullRequest(testCtxMatcher, c).Return(withLabels(testPR, labelSkip), nil)
+	gc.EXPECT().GetTokenScopes(testCtxMatcher, c).Return(&github.TokenScopes{}, nil)
+	gc.EXPECT().GetPullRequestPatch(testCtxMatcher, c).Return(getFakePatch(t), nil)
+
+	cfgFetcher := repoconfig.NewMockFetcher(ctrl)
+	cfgFetcher.EXPECT().FetchOrgDefaults(any, any).Return(&repoconfig.Config{DryRun: repoconfig.Bool(true)}, nil)
+	cfgFetcher.EXPECT().FetchRepoConfig(any, any).Return(&repoconfig.Config{}, nil)
+
+	state := prstate.NewMockStorage(ctrl)
+	state.EXPECT().UpdateState(any, c.Repo.Owner, c.Repo.Name, testAnalysisGUID, any).
+		Do(func(_ context.Context, _, _, _ string, s *prstate.State) {
+			dryRun := s.ResultJSON.(*resultJSON).WorkerRes.DryRun
+			if assert.NotNil(t, dryRun) && assert.Len(t, dryRun.Statuses, 1) {
+				assert.Equal(t, github.StatusSuccess, dryRun.Statuses[0].Status)
+				assert.Equal(t, "skipped by label golangci:skip", dryRun.Statuses[0].Description)
+			}
+		})
+
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Clean()
+	testProcessor(t, ctrl, githubGoPRConfig{
+		linters:     []linters.Linter{linters.NewMockLinter(ctrl)},
+		repoFetcher: fetchers.NewMockFetcher(ctrl),
+		exec:        exec,
+		client:      gc,
+		state:       state,
+		cfgFetcher:  cfgFetcher,
+	})
+}
+
+func TestDryRunClientRecordsReview(t *testing.T) {
+	c := newDryRunClient(nil)
+	review := &github.Review{
+		Comments: []github.ReviewComment{
+			{Path: "main.go", Line: 3, Side: github.SideRight, Body: "issue"},
+		},
+	}
+	assert.NoError(t, c.CreateReview(testCtx, &github.FakeContext, review))
+	assert.Equal(t, []DryRunComment{{Path: "main.go", Line: 3, Side: github.SideRight, Body: "issue"}}, c.result().Comments)
+
+	assert.NoError(t, c.EditReleaseBody(testCtx, &github.FakeContext, 42, "report"))
+	assert.Equal(t, []DryRunRelease{{ID: 42, Body: "report"}}, c.result().Releases)
+}
+
+func TestDryRunClientWrapsWrites(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the mock fails on any call: writes must be recorded, not passed to GitHub
+	c := newDryRunClient(github.NewMockClient(ctrl))
+	clientType := reflect.TypeOf((*github.Client)(nil)).Elem()
+	ctxType := reflect.TypeOf((*context.Context)(nil)).Elem()
+	for i := 0; i < clientType.NumMethod(); i++ {
+		m := clientType.Method(i)
+		if strings.HasPrefix(m.Name, "Get") || m.Name == "RebuildPullRequestPatch" { // reads
+			continue
+		}
+
+		var args []reflect.Value
+		for j := 0; j < m.Type.NumIn(); j++ {
+			switch in := m.Type.In(j); {
+			case in == ctxType:
+				args = append(args, reflect.ValueOf(testCtx))
+			case in.Kind() == reflect.Ptr:
+				args = append(args, reflect.New(in.Elem()))
+			default:
+				args = append(args, reflect.Zero(in))
+			}
+		}
+		reflect.ValueOf(c).MethodByName(m.Name).Call(args)
+	}
+}
+
+func TestTokenMissingScope(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetTokenScopes(testCtxMatcher, c).Return(&github.TokenScopes{Known: true, Scopes: []string{"read:user"}}, nil)
+	gc.EXPECT().GetPullRequestPatch(testCtxMatcher, c).Return(getFakePatch(t), nil)
+
+	state := prstate.NewMockStorage(ctrl)
+	state.EXPECT().UpdateState(any, c.Repo.Owner, c.Repo.Name, testAnalysisGUID, any).
+		Do(func(_ context.Context, _, _, _ string, s *prstate.State) {
+			assert.Contains(t, s.ResultJSON.(*resultJSON).WorkerRes.Error, "token missing repo scope")
+		})
+
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Clean()
+	p := getNopedProcessor(t, ctrl, githubGoPRConfig{
+		linters:     []linters.Linter{linters.NewMockLinter(ctrl)},
+		repoFetcher: fetchers.NewMockFetcher(ctrl),
+		exec:        exec,
+		client:      gc,
+		state:       state,
+	})
+	assert.Error(t, p.Process(testCtx))
+}
+
+func TestValidateTokenScopes(t *testing.T) {
+	scopes := func(s ...string) *github.TokenScopes {
+		return &github.TokenScopes{Known: true, Scopes: s}
+	}
+
+	assert.NoError(t, validateTokenScopes(nil, true))
+	assert.NoError(t, validateTokenScopes(&github.TokenScopes{}, true)) // app token
+	assert.NoError(t, validateTokenScopes(scopes("repo"), true))
+	assert.NoError(t, validateTokenScopes(scopes("public_repo"), false))
+	assert.Error(t, validateTokenScopes(scopes("public_repo"), true))
+	assert.Error(t, validateTokenScopes(scopes(), false))
+}
+
+func TestParsePRLabels(t *testing.T) {
+	assert.Equal(t, prLabelOptions{}, parsePRLabels(nil))
+	assert.Equal(t, prLabelOptions{strict: true, fullRepo: true},
+		parsePRLabels([]string{labelFullRepo, "golangci", labelStrict}))
+}
+
+func TestSkipByPaths(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequest(testCtxMatcher, c).Return(testPR, nil)
+	gc.EXPECT().GetTokenScopes(testCtxMatcher, c).Return(&github.TokenScopes{}, nil)
+	gc.EXPECT().GetPullRequestPatch(testCtxMatcher, c).Return(getFakePatch(t), nil)
+
+	test.Init()
+	url := fmt.Sprintf("%s/r/github.com/%s/%s/pulls/%d", os.Getenv("WEB_ROOT"), c.Repo.Owner, c.Repo.Name, testPR.GetNumber())
+	gc.EXPECT().SetCommitStatus(testCtxMatcher, c, testSHA, github.StatusSuccess, noGoFilesToAnalyzeMessage, url)
+
+	cf := repoconfig.NewMockFetcher(ctrl)
+	cf.EXPECT().FetchOrgDefaults(any, any).Return(&repoconfig.Config{SkipPaths: []string{"*.go"}}, nil)
+	cf.EXPECT().FetchRepoConfig(any, any).Return(&repoconfig.Config{}, nil)
+
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Clean()
+	testProcessor(t, ctrl, githubGoPRConfig{
+		linters:     []linters.Linter{linters.NewMockLinter(ctrl)},
+		repoFetcher: fetchers.NewMockFetcher(ctrl),
+		exec:        exec,
+		client:      gc,
+		cfgFetcher:  cf,
+	})
+}
+
+func TestSkipByAnalysisPath(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := github.FakeContext
+	c.Path = "backend/"
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequest(testCtxMatcher, &c).Return(testPR, nil)
+	gc.EXPECT().GetTokenScopes(testCtxMatcher, &c).Return(&github.TokenScopes{}, nil)
+	gc.EXPECT().GetPullRequestPatch(testCtxMatcher, &c).Return(getFakePatch(t), nil)
+
+	test.Init()
+	url := fmt.Sprintf("%s/r/github.com/%s/%s/pulls/%d", os.Getenv("WEB_ROOT"), c.Repo.Owner, c.Repo.Name, testPR.GetNumber())
+	gc.EXPECT().SetCommitStatus(testCtxMatcher, &c, testSHA, github.StatusSuccess, "No changes in the project", url)
+
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Clean()
+	cfg := githubGoPRConfig{
+		linters:     []linters.Linter{linters.NewMockLinter(ctrl)},
+		repoFetcher: fetchers.NewMockFetcher(ctrl),
+		exec:        exec,
+		client:      gc,
+	}
+	fillWithNops(t, ctrl, &cfg)
+
+	p, err := newGithubGoPR(testCtx, &c, cfg, testAnalysisGUID)
+	assert.NoError(t, err)
+	assert.NoError(t, p.Process(testCtx))
+}
+
+func TestPatchFetchOverlapsWorkspaceSetup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := &github.FakeContext
+	setupStarted := make(chan struct{})
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequest(testCtxMatcher, c).Return(testPR, nil)
+	gc.EXPECT().GetTokenScopes(testCtxMatcher, c).Return(&github.TokenScopes{}, nil)
+	gc.EXPECT().SetCommitStatus(any, any, testSHA, any, any, any).AnyTimes()
+	gc.EXPECT().GetPullRequestPatch(testCtxMatcher, c).DoAndReturn(func(context.Context, *github.Context) (string, error) {
+		select {
+		case <-setupStarted:
+			return getFakePatch(t), nil
+		case <-time.After(5 * time.Second):
+			return "", fmt.Errorf("workspace setup wasn't started during patch fetching")
+		}
+	})
+
+	infoFetcher := repoinfo.NewMockFetcher(ctrl)
+	infoFetcher.EXPECT().Fetch(testCtxMatcher, any, any).DoAndReturn(func(context.Context, *fetchers.Repo, executors.Executor) (*repoinfo.Info, error) {
+		close(setupStarted)
+		return &repoinfo.Info{}, nil
+	})
+	infoFetcher.EXPECT().FetchMetadata(testCtxMatcher, any).Return(&repoinfo.Metadata{}, nil)
+
+	testProcessor(t, ctrl, githubGoPRConfig{
+		client:      gc,
+		infoFetcher: infoFetcher,
+	})
+}
+
+func TestGetPatchFiles(t *testing.T) {
+	patch := `diff --git a/docs/a.md b/docs/a.md
+--- a/docs/a.md
++++ b/docs/a.md
+@@ -1 +1 @@
+-a
++b
+diff --git a/old.go b/old.go
+--- a/old.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package p
+`
+	assert.Equal(t, []string{"docs/a.md", "old.go"}, getPatchFiles(patch))
+	assert.Equal(t, []string{"main.go"}, getPatchFiles(getFakePatch(t)))
+
+	renamed := "diff --git a/old/name.go b/new/name.go\nsimilarity index 100%\nrename from old/name.go\nrename to new/name.go\n" +
+		"diff --git \"a/caf\\303\\251.go\" \"b/caf\\303\\251.go\"\nnew file mode 100644\n" +
+		"--- /dev/null\n+++ \"b/caf\\303\\251.go\"\n@@ -0,0 +1 @@\n+package p\n"
+	assert.Equal(t, []string{"old/name.go", "new/name.go", "café.go"}, getPatchFiles(renamed))
+}
+
 func getRealisticTestProcessor(ctx context.Context, t *testing.T, ctrl *gomock.Controller) *githubGoPR {
 	c := getTestingRepo(t)
 	cloneURL := fmt.Sprintf("git@github.com:%s/%s.git", c.Repo.Owner, c.Repo.Name)
@@ -237,6 +635,7 @@ func getRealisticTestProcessor(ctx context.Context, t *testing.T, ctrl *gomock.C
 	}
 	gc := github.NewMockClient(ctrl)
 	gc.EXPECT().GetPullRequest(testCtxMatcher, c).Return(pr, nil).AnyTimes()
+	gc.EXPECT().GetTokenScopes(testCtxMatcher, c).Return(&github.TokenScopes{}, nil).AnyTimes()
 	gc.EXPECT().GetPullRequestPatch(any, any).AnyTimes().Return(getFakePatch(t), nil)
 	gc.EXPECT().SetCommitStatus(any, any, any, any, any, any).AnyTimes()
 
@@ -311,6 +710,7 @@ func getTestProcessorWithFakeGithub(ctx context.Context, t *testing.T, ctrl *gom
 	gc := github.NewMockClient(ctrl)
 	gc.EXPECT().GetPullRequestPatch(any, any).AnyTimes().Return(patch, nil)
 	gc.EXPECT().GetPullRequest(testCtxMatcher, c).Return(pr, nil)
+	gc.EXPECT().GetTokenScopes(testCtxMatcher, c).AnyTimes().Return(&github.TokenScopes{}, nil)
 	gc.EXPECT().SetCommitStatus(any, any, any, any, any, any).AnyTimes()
 
 	cfg := githubGoPRConfig{
@@ -335,3 +735,67 @@ func TestProcessRepoWithFakeGithub(t *testing.T) {
 	err := p.Process(testCtx)
 	assert.NoError(t, err)
 }
+
+func TestProcessWithFakeGithubServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := githubfake.NewServer()
+	defer srv.Close()
+	// diffs of GitHub have the b/ prefix of new paths, the fixture doesn't have it
+	patch := strings.Replace(getFakePatch(t), "+++ a/", "+++ b/", -1)
+	srv.AddPullRequest("golangci", "repo", testPR, patch)
+	c := srv.Context("golangci", "repo", testPR.GetNumber())
+
+	client := github.NewMyClient()
+	cfg := githubGoPRConfig{
+		client:   client,
+		linters:  getFakeLinters(ctrl, fakeChangedIssue),
+		reporter: reporters.NewGithubReviewer(c, client, reporters.GithubReviewerOptions{}),
+	}
+	fillWithNops(t, ctrl, &cfg)
+
+	p, err := newGithubGoPR(testCtx, c, cfg, testAnalysisGUID)
+	assert.NoError(t, err)
+	assert.NoError(t, p.Process(testCtx))
+
+	statuses := srv.Statuses("golangci", "repo")
+	if assert.Len(t, statuses, 2) {
+		assert.Equal(t, github.StatusPending, statuses[0].State)
+		assert.Equal(t, github.StatusFailure, statuses[1].State)
+		assert.Equal(t, "1 issue found", statuses[1].Description)
+	}
+
+	reviews := srv.Reviews("golangci", "repo", testPR.GetNumber())
+	if assert.Len(t, reviews, 1) && assert.Len(t, reviews[0].Comments, 1) {
+		assert.Equal(t, testSHA, reviews[0].CommitID)
+		assert.Equal(t, "main.go", reviews[0].Comments[0].Path)
+		assert.Equal(t, 10, reviews[0].Comments[0].Line)
+	}
+}
+
+func TestIssuesCapped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	lintersList := getFakeLinters(ctrl, fakeChangedIssues...)
+	lintersList[0].(*linters.MockLinter).EXPECT().Name().Return("golangci-lint").AnyTimes()
+	var last *prstate.State
+	state := prstate.NewMockStorage(ctrl)
+	state.EXPECT().GetState(any, any, any, any).AnyTimes().Return(&prstate.State{Status: statusSentToQueue}, nil)
+	state.EXPECT().UpdateState(any, any, any, any, any).AnyTimes().
+		Do(func(_ context.Context, _, _, _ string, s *prstate.State) {
+			last = s
+		})
+
+	testProcessor(t, ctrl, githubGoPRConfig{
+		linters: lintersList,
+		runner:  linters.SimpleRunner{MaxIssues: 1},
+		state:   state,
+	})
+
+	assert.Equal(t, 1, last.ReportedIssuesCount)
+	res := last.ResultJSON.(*resultJSON)
+	assert.True(t, res.WorkerRes.IssuesCapped)
+	assert.Contains(t, res.WorkerRes.Warnings, Warning{Tag: "analysis", Text: issuesCappedWarning(i18n.Printer{})})
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"runtime/debug"
 	"strings"
 
@@ -12,6 +11,7 @@ import (
 	"github.com/golangci/golangci-worker/app/analytics"
 	"github.com/golangci/golangci-worker/app/analyze/linters"
 	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/lintcache"
 	"github.com/golangci/golangci-worker/app/analyze/linters/result"
 	"github.com/golangci/golangci-worker/app/analyze/repoinfo"
 	"github.com/golangci/golangci-worker/app/analyze/repostate"
@@ -21,6 +21,8 @@ import (
 	"github.com/golangci/golangci-worker/app/lib/github"
 	"github.com/golangci/golangci-worker/app/lib/goutils/workspaces"
 	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+	"github.com/golangci/golangci-worker/app/lib/redact"
 	"github.com/pkg/errors"
 )
 
@@ -55,7 +57,7 @@ func NewGithubGoRepo(ctx context.Context, cfg GithubGoRepoConfig, analysisGUID,
 
 	if cfg.exec == nil {
 		var err error
-		cfg.exec, err = makeExecutor(ctx, repo, true, nil, nil)
+		cfg.exec, err = makeExecutor(ctx, repo, true, nil, nil, "")
 		if err != nil {
 			return nil, fmt.Errorf("can't make executor: %s", err)
 		}
@@ -71,7 +73,10 @@ func NewGithubGoRepo(ctx context.Context, cfg GithubGoRepoConfig, analysisGUID,
 
 	if cfg.linters == nil {
 		cfg.linters = []linters.Linter{
-			golinters.GolangciLint{},
+			golinters.GolangciLint{
+				Cache: lintcache.Default(),
+				Repo:  repo.FullName(),
+			},
 		}
 	}
 
@@ -109,6 +114,7 @@ func (g *GithubGoRepo) prepareRepo(ctx context.Context) error {
 		return &errorutils.InternalError{
 			PublicDesc:  "can't clone git repo",
 			PrivateDesc: fmt.Sprintf("can't clone git repo: %s", err),
+			Class:       errorutils.ClassProvider,
 		}
 	}
 
@@ -123,7 +129,8 @@ func (g *GithubGoRepo) prepareRepo(ctx context.Context) error {
 		analytics.Log(ctx).Infof("Got deps result: %#v", depsRes)
 
 		for _, w := range depsRes.Warnings {
-			warnText := fmt.Sprintf("Fetch deps: %s: %s", w.Kind, w.Text)
+			// repo analyses have no repo config: warnings are in English
+			warnText := i18n.Printer{}.Sprintf(i18n.WarnFetchDeps, w.Kind, w.Text)
 			warnText = escapeErrorText(warnText, g.buildSecrets())
 			g.publicWarn("prepare repo", warnText)
 
@@ -134,7 +141,8 @@ func (g *GithubGoRepo) prepareRepo(ctx context.Context) error {
 	return nil
 }
 
-func (g GithubGoRepo) updateAnalysisState(ctx context.Context, res *result.Result, status, publicError string) {
+func (g GithubGoRepo) updateAnalysisState(ctx context.Context, res *result.Result, status, publicError string,
+	errClass errorutils.Class) {
 	resJSON := &resultJSON{
 		Version: 1,
 		WorkerRes: workerRes{
@@ -143,6 +151,7 @@ func (g GithubGoRepo) updateAnalysisState(ctx context.Context, res *result.Resul
 			Error:    publicError,
 		},
 	}
+	resJSON.WorkerRes.redact(g.buildSecrets())
 
 	if res != nil {
 		resJSON.GolangciLintRes = res.ResultJSON
@@ -150,6 +159,7 @@ func (g GithubGoRepo) updateAnalysisState(ctx context.Context, res *result.Resul
 	s := &repostate.State{
 		Status:     status,
 		ResultJSON: resJSON,
+		ErrorClass: string(errClass),
 	}
 
 	jsonBytes, err := json.Marshal(*resJSON)
@@ -165,6 +175,7 @@ func (g GithubGoRepo) updateAnalysisState(ctx context.Context, res *result.Resul
 func (g *GithubGoRepo) processWithGuaranteedGithubStatus(ctx context.Context) error {
 	res, err := g.work(ctx)
 	analytics.Log(ctx).Infof("timings: %s", g.timings)
+	errClass := trackErrorClass(ctx, analytics.EventRepoAnalyzed, err)
 
 	ctx = context.Background() // no timeout for state and status saving: it must be durable
 
@@ -194,28 +205,13 @@ func (g *GithubGoRepo) processWithGuaranteedGithubStatus(ctx context.Context) er
 		status = statusProcessed
 	}
 
-	g.updateAnalysisState(ctx, res, status, publicError)
+	g.updateAnalysisState(ctx, res, status, publicError, errClass)
 	return err
 }
 
 func (g GithubGoRepo) buildSecrets() map[string]string {
-	const hidden = "{hidden}"
-	ret := map[string]string{
-		g.gw.Gopath(): "$GOPATH",
-	}
-
-	for _, kv := range os.Environ() {
-		parts := strings.Split(kv, "=")
-		if len(parts) != 2 {
-			continue
-		}
-
-		v := parts[1]
-		if len(v) >= 6 {
-			ret[v] = hidden
-		}
-	}
-
+	ret := redact.EnvSecrets()
+	ret[g.gw.Gopath()] = "$GOPATH"
 	return ret
 }
 
@@ -268,7 +264,7 @@ func (g GithubGoRepo) Process(ctx context.Context) error {
 	g.exec = g.gw.Executor()
 
 	if curState.Status == statusSentToQueue {
-		g.addTimingFrom("In Queue", fromDBTime(curState.CreatedAt))
+		g.trackQueueTime(ctx, "analyzeRepo", curState.CreatedAt)
 		curState.Status = statusProcessing
 		if err = g.state.UpdateState(ctx, g.repo.Owner, g.repo.Name, g.analysisGUID, curState); err != nil {
 			analytics.Log(ctx).Warnf("Can't update repo analysis %s state with setting status to 'processing': %s", g.analysisGUID, err)
@@ -0,0 +1,327 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/diffanchor"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+	"github.com/pkg/errors"
+)
+
+const (
+	goGenerateLinter = "go-generate"
+
+	defaultGenerateTimeout = 5 * time.Minute
+
+	gitConfigPath = ".git/config"
+)
+
+// safeGitArgs disable git config running commands: generators can write it into .git/config,
+// but git runs after them with network access and credentials
+var safeGitArgs = []string{"-c", "core.fsmonitor=", "-c", "core.hooksPath=/dev/null"}
+
+func runSafeGit(ctx context.Context, exec executors.Executor, args ...string) (string, error) {
+	return exec.Run(ctx, "git", append(append([]string{}, safeGitArgs...), args...)...)
+}
+
+func readGitConfig(ctx context.Context, exec executors.Executor) (string, error) {
+	out, err := executors.Unrestricted(exec).Run(ctx, "cat", gitConfigPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "can't read %s: %s", gitConfigPath, out)
+	}
+
+	return out, nil
+}
+
+// restoreGitConfig writes the snapshot of .git/config back if it was changed, it's true then
+func restoreGitConfig(ctx context.Context, exec executors.Executor, snapshot string) (bool, error) {
+	if cur, err := readGitConfig(ctx, exec); err == nil && cur == snapshot {
+		return false, nil
+	}
+
+	// executors strip the trailing newline
+	if err := exec.WriteFile(ctx, gitConfigPath, []byte(snapshot+"\n")); err != nil {
+		return true, errors.Wrapf(err, "can't restore %s", gitConfigPath)
+	}
+
+	return true, nil
+}
+
+// staleFile is a file changed or created by go generate
+type staleFile struct {
+	path    string
+	line    int // first line of the committed file changed by go generate, zero for created files
+	created bool
+}
+
+// gitStatusFiles returns changed and untracked files of the workspace, untracked ones are true
+func gitStatusFiles(ctx context.Context, exec executors.Executor) (map[string]bool, error) {
+	out, err := runSafeGit(ctx, exec, "status", "--porcelain", "--untracked-files=all")
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get git status: %s", out)
+	}
+
+	ret := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		if len(line) < 4 {
+			continue
+		}
+
+		p := line[3:]
+		if i := strings.Index(p, " -> "); i != -1 { // renames
+			p = p[i+len(" -> "):]
+		}
+		ret[p] = line[:2] == "??"
+	}
+
+	return ret, nil
+}
+
+// findStaleGenerated runs go generate by genExec and returns files it changed with the diff of them:
+// the workspace is restored after that. Generators can write .git/config: it's restored before any git command.
+func findStaleGenerated(ctx context.Context, exec, genExec executors.Executor, timeout time.Duration) ([]staleFile, string, error) {
+	before, err := gitStatusFiles(ctx, exec)
+	if err != nil {
+		return nil, "", err
+	}
+
+	gitConfig, err := readGitConfig(ctx, exec)
+	if err != nil {
+		return nil, "", err
+	}
+
+	genCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	out, genErr := genExec.Run(genCtx, "go", "generate", "./...")
+
+	changedConfig, err := restoreGitConfig(ctx, exec, gitConfig)
+	if err != nil {
+		return nil, "", err
+	}
+	if changedConfig {
+		analytics.Log(ctx).Warnf("go generate changed %s: it's restored", gitConfigPath)
+	}
+
+	// the output is only shown in errors: its truncation isn't an error
+	if genErr != nil && !executors.IsOutputTruncated(genErr) {
+		if genCtx.Err() == context.DeadlineExceeded {
+			return nil, "", fmt.Errorf("go generate timed out after %s", timeout)
+		}
+		return nil, "", errors.Wrapf(genErr, "can't run go generate: %s", out)
+	}
+
+	after, err := gitStatusFiles(ctx, exec)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var changed, created []string
+	for p, untracked := range after {
+		if _, ok := before[p]; ok {
+			continue // changed before go generate, e.g. by dependencies installation
+		}
+		if untracked {
+			created = append(created, p)
+		} else {
+			changed = append(changed, p)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(created)
+
+	var ret []staleFile
+	var diff string
+	if len(changed) != 0 {
+		args := append([]string{"diff", "--no-color", "--no-ext-diff", "HEAD", "--"}, changed...)
+		out, diffErr := runSafeGit(ctx, exec, args...)
+		if diffErr != nil {
+			return nil, "", errors.Wrapf(diffErr, "can't diff generated files: %s", out)
+		}
+		diff = out + "\n" // executors strip the trailing newline
+		lines := firstChangedLines(diff)
+		for _, p := range changed {
+			ret = append(ret, staleFile{path: p, line: lines[p]})
+		}
+
+		args = append([]string{"checkout", "-q", "--"}, changed...)
+		if out, checkoutErr := runSafeGit(ctx, exec, args...); checkoutErr != nil {
+			return nil, "", errors.Wrapf(checkoutErr, "can't restore generated files: %s", out)
+		}
+	}
+	for _, p := range created {
+		ret = append(ret, staleFile{path: p, created: true})
+		diff += fmt.Sprintf("created %s\n", p)
+	}
+	if len(created) != 0 {
+		args := append([]string{"-f", "--"}, created...)
+		if out, rmErr := executors.Unrestricted(exec).Run(ctx, "rm", args...); rmErr != nil {
+			return nil, "", errors.Wrapf(rmErr, "can't remove generated files: %s", out)
+		}
+	}
+
+	return ret, diff, nil
+}
+
+// withoutRemoteCredentials removes credentials from URLs of git remotes while f runs:
+// private repos are cloned by URLs with the access token. Credentials are restored only
+// into .git/config which f didn't change: otherwise it's restored first.
+func withoutRemoteCredentials(ctx context.Context, exec executors.Executor, f func() error) error {
+	out, err := exec.Run(ctx, "git", "remote")
+	if err != nil {
+		return errors.Wrapf(err, "can't list git remotes: %s", out)
+	}
+
+	restore := map[string]string{}
+	var gitConfig *string // the snapshot without credentials, it's set before f runs
+	defer func() {
+		if gitConfig != nil {
+			changed, err := restoreGitConfig(ctx, exec, *gitConfig)
+			if err != nil {
+				analytics.Log(ctx).Warnf("Don't restore urls of git remotes: %s", err)
+				return
+			}
+			if changed {
+				analytics.Log(ctx).Warnf("%s was changed while git remotes had no credentials: it's restored", gitConfigPath)
+			}
+		}
+
+		for name, u := range restore {
+			if out, err := runSafeGit(ctx, exec, "remote", "set-url", name, u); err != nil {
+				analytics.Log(ctx).Warnf("Can't restore url of git remote %s: %s, %s", name, err, out)
+			}
+		}
+	}()
+
+	for _, name := range strings.Fields(out) {
+		u, err := exec.Run(ctx, "git", "remote", "get-url", name)
+		if err != nil {
+			return errors.Wrapf(err, "can't get url of git remote %s: %s", name, u)
+		}
+
+		pu, err := url.Parse(strings.TrimSpace(u))
+		if err != nil || pu.User == nil {
+			continue // e.g. ssh urls of deploy keys: they have no secrets
+		}
+		pu.User = nil
+		if out, err := exec.Run(ctx, "git", "remote", "set-url", name, pu.String()); err != nil {
+			return errors.Wrapf(err, "can't remove credentials from git remote %s: %s", name, out)
+		}
+		restore[name] = strings.TrimSpace(u)
+	}
+
+	if len(restore) != 0 {
+		cfg, err := readGitConfig(ctx, exec)
+		if err != nil {
+			return err
+		}
+		gitConfig = &cfg
+	}
+
+	return f()
+}
+
+// firstChangedLines returns the first line of every file of the diff changed in the old version
+func firstChangedLines(diff string) map[string]int {
+	ret := map[string]int{}
+	p, err := diffanchor.Parse(diff)
+	if err != nil {
+		return ret
+	}
+
+	for _, f := range p.Files {
+		for _, h := range f.Hunks {
+			for _, l := range h.Lines {
+				if l.Kind == diffanchor.Deleted {
+					ret[f.OldPath] = l.OldLine
+					break
+				}
+			}
+			if ret[f.OldPath] != 0 {
+				break
+			}
+		}
+	}
+
+	return ret
+}
+
+// staleGeneratedIssues fail only files changed by the PR: other files were stale before it
+func staleGeneratedIssues(stale []staleFile, prFiles []string) []result.Issue {
+	inPR := map[string]bool{}
+	for _, f := range prFiles {
+		inPR[f] = true
+	}
+
+	var ret []result.Issue
+	for _, f := range stale {
+		text := "generated file is stale: run `go generate ./...` and commit the changes"
+		if f.created {
+			text = "`go generate ./...` creates the file, but it isn't committed"
+		}
+		ret = append(ret, result.Issue{
+			FromLinter:    goGenerateLinter,
+			Text:          text,
+			File:          f.path,
+			LineNumber:    f.line,
+			Informational: !inPR[f.path],
+		})
+	}
+
+	return ret
+}
+
+// verifyGenerate is an optional stage: it never fails the analysis, only reported issues can fail the status.
+// Generators are code of the PR: they run only in containers without network and credentials.
+func (g *githubGoPR) verifyGenerate(ctx context.Context) error {
+	if !g.repoCfg.GetVerifyGenerate() {
+		return nil
+	}
+
+	genExec, isolated := executors.WithoutNetwork(g.exec)
+	if !isolated {
+		g.publicWarn("go generate", g.msg.Sprintf(i18n.WarnGenerateNotVerified))
+		analytics.Log(ctx).Infof("Skipped go generate: the executor isn't a container")
+		return nil
+	}
+	if err := executors.CheckNetworkIsolation(ctx, genExec); err != nil {
+		g.publicWarn("go generate", g.msg.Sprintf(i18n.WarnGenerateNotVerified))
+		analytics.Log(ctx).Warnf("Skipped go generate: %s", err)
+		return nil
+	}
+
+	_ = g.trackStep("Verify generated files", func() (string, error) {
+		var stale []staleFile
+		var diff string
+		err := withoutRemoteCredentials(ctx, g.exec, func() error {
+			var genErr error
+			stale, diff, genErr = findStaleGenerated(ctx, g.exec, genExec, g.generateTimeout)
+			return genErr
+		})
+		if err != nil {
+			g.publicWarn("go generate", g.msg.Sprintf(i18n.WarnGenerateFailed))
+			analytics.Log(ctx).Warnf("Failed to verify generated files: %s", err)
+			return err.Error(), nil // the output of go generate is shown to users
+		}
+		analytics.SaveEventProp(ctx, analytics.EventPRChecked, "staleGeneratedFiles", len(stale))
+		if len(stale) == 0 {
+			return "generated files are up to date", nil
+		}
+
+		issues, err := anchorIssues(g.patch, staleGeneratedIssues(stale, getPatchFiles(g.patch)))
+		if err != nil {
+			analytics.Log(ctx).Warnf("Can't anchor stale generated files to the patch: %s", err)
+		}
+		g.lintRes.Issues = append(g.lintRes.Issues, issues...)
+
+		return fmt.Sprintf("%d generated files are stale:\n%s", len(stale), diff), nil
+	})
+	return nil
+}
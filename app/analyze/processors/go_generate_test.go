@@ -0,0 +1,169 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+const testGenerateDiff = `diff --git a/mocks/client_mock.go b/mocks/client_mock.go
+index 1111111..2222222 100644
+--- a/mocks/client_mock.go
++++ b/mocks/client_mock.go
+@@ -40,3 +40,3 @@ func (m *MockClient) Do() {
+ 	m.ctrl.T.Helper()
+-	ret := m.ctrl.Call(m, "Do")
++	ret := m.ctrl.Call(m, "Do", ctx)
+ 	return ret
+`
+
+const testGitConfig = "[core]\n\tbare = false"
+
+func TestFindStaleGenerated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	gomock.InOrder(
+		exec.EXPECT().Run(ctx, "git", "-c", "core.fsmonitor=", "-c", "core.hooksPath=/dev/null",
+			"status", "--porcelain", "--untracked-files=all").Return(" M go.sum", nil),
+		exec.EXPECT().Run(ctx, "cat", ".git/config").Return(testGitConfig, nil),
+		exec.EXPECT().Run(gomock.Any(), "go", "generate", "./...").Return("", nil),
+		exec.EXPECT().Run(ctx, "cat", ".git/config").Return(testGitConfig, nil),
+		exec.EXPECT().Run(ctx, "git", "-c", "core.fsmonitor=", "-c", "core.hooksPath=/dev/null",
+			"status", "--porcelain", "--untracked-files=all").
+			Return(" M go.sum\n M mocks/client_mock.go\n?? mocks/new_mock.go", nil),
+		exec.EXPECT().Run(ctx, "git", "-c", "core.fsmonitor=", "-c", "core.hooksPath=/dev/null",
+			"diff", "--no-color", "--no-ext-diff", "HEAD", "--", "mocks/client_mock.go").
+			Return(testGenerateDiff, nil),
+		exec.EXPECT().Run(ctx, "git", "-c", "core.fsmonitor=", "-c", "core.hooksPath=/dev/null",
+			"checkout", "-q", "--", "mocks/client_mock.go").Return("", nil),
+		exec.EXPECT().Run(ctx, "rm", "-f", "--", "mocks/new_mock.go").Return("", nil),
+	)
+
+	stale, diff, err := findStaleGenerated(ctx, exec, exec, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []staleFile{
+		{path: "mocks/client_mock.go", line: 41},
+		{path: "mocks/new_mock.go", created: true},
+	}, stale)
+	assert.Contains(t, diff, `+	ret := m.ctrl.Call(m, "Do", ctx)`)
+	assert.Contains(t, diff, "created mocks/new_mock.go")
+}
+
+func TestFindStaleGeneratedFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	gomock.InOrder(
+		exec.EXPECT().Run(ctx, "git", "-c", "core.fsmonitor=", "-c", "core.hooksPath=/dev/null",
+			"status", "--porcelain", "--untracked-files=all").Return("", nil),
+		exec.EXPECT().Run(ctx, "cat", ".git/config").Return(testGitConfig, nil),
+		exec.EXPECT().Run(gomock.Any(), "go", "generate", "./...").
+			Return("protoc: not found", errors.New("exit status 1")),
+		exec.EXPECT().Run(ctx, "cat", ".git/config").Return(testGitConfig, nil),
+	)
+
+	_, _, err := findStaleGenerated(ctx, exec, exec, time.Minute)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "protoc: not found")
+}
+
+func TestFindStaleGeneratedRestoresGitConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	gomock.InOrder(
+		exec.EXPECT().Run(ctx, "git", "-c", "core.fsmonitor=", "-c", "core.hooksPath=/dev/null",
+			"status", "--porcelain", "--untracked-files=all").Return("", nil),
+		exec.EXPECT().Run(ctx, "cat", ".git/config").Return(testGitConfig, nil),
+		exec.EXPECT().Run(gomock.Any(), "go", "generate", "./...").Return("", nil),
+		exec.EXPECT().Run(ctx, "cat", ".git/config").Return(testGitConfig+"\n\tfsmonitor = ./evil.sh", nil),
+		// the config is restored before the next git command
+		exec.EXPECT().WriteFile(ctx, ".git/config", []byte(testGitConfig+"\n")).Return(nil),
+		exec.EXPECT().Run(ctx, "git", "-c", "core.fsmonitor=", "-c", "core.hooksPath=/dev/null",
+			"status", "--porcelain", "--untracked-files=all").Return("", nil),
+	)
+
+	stale, _, err := findStaleGenerated(ctx, exec, exec, time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, stale)
+}
+
+func TestWithoutRemoteCredentials(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	var ran bool
+	gomock.InOrder(
+		exec.EXPECT().Run(ctx, "git", "remote").Return("origin\nbase", nil),
+		exec.EXPECT().Run(ctx, "git", "remote", "get-url", "origin").
+			Return("https://token@github.com/golangci/repo.git", nil),
+		exec.EXPECT().Run(ctx, "git", "remote", "set-url", "origin", "https://github.com/golangci/repo.git").
+			Return("", nil),
+		exec.EXPECT().Run(ctx, "git", "remote", "get-url", "base").Return("git@github.com:golangci/repo.git", nil),
+		exec.EXPECT().Run(ctx, "cat", ".git/config").Return(testGitConfig, nil),
+		exec.EXPECT().Run(ctx, "cat", ".git/config").Return(testGitConfig, nil),
+		exec.EXPECT().Run(ctx, "git", "-c", "core.fsmonitor=", "-c", "core.hooksPath=/dev/null",
+			"remote", "set-url", "origin", "https://token@github.com/golangci/repo.git").
+			Do(func(context.Context, string, ...string) { assert.True(t, ran) }).Return("", nil),
+	)
+
+	err := withoutRemoteCredentials(ctx, exec, func() error {
+		ran = true
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestWithoutRemoteCredentialsKeepsChangedGitConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	gomock.InOrder(
+		exec.EXPECT().Run(ctx, "git", "remote").Return("origin", nil),
+		exec.EXPECT().Run(ctx, "git", "remote", "get-url", "origin").
+			Return("https://token@github.com/golangci/repo.git", nil),
+		exec.EXPECT().Run(ctx, "git", "remote", "set-url", "origin", "https://github.com/golangci/repo.git").
+			Return("", nil),
+		exec.EXPECT().Run(ctx, "cat", ".git/config").Return(testGitConfig, nil),
+		exec.EXPECT().Run(ctx, "cat", ".git/config").Return(testGitConfig+"\n\thooksPath = hooks", nil),
+		// credentials aren't restored into the config which can't be restored
+		exec.EXPECT().WriteFile(ctx, ".git/config", []byte(testGitConfig+"\n")).Return(errors.New("read-only")),
+	)
+
+	err := withoutRemoteCredentials(ctx, exec, func() error { return nil })
+	assert.NoError(t, err)
+}
+
+func TestStaleGeneratedIssues(t *testing.T) {
+	stale := []staleFile{
+		{path: "mocks/client_mock.go", line: 41},
+		{path: "pb/api.pb.go", line: 3},
+		{path: "mocks/new_mock.go", created: true},
+	}
+	issues := staleGeneratedIssues(stale, []string{"mocks/client_mock.go", "client.go"})
+	assert.Equal(t, []result.Issue{
+		{FromLinter: goGenerateLinter, File: "mocks/client_mock.go", LineNumber: 41,
+			Text: "generated file is stale: run `go generate ./...` and commit the changes"},
+		{FromLinter: goGenerateLinter, File: "pb/api.pb.go", LineNumber: 3, Informational: true,
+			Text: "generated file is stale: run `go generate ./...` and commit the changes"},
+		{FromLinter: goGenerateLinter, File: "mocks/new_mock.go", Informational: true,
+			Text: "`go generate ./...` creates the file, but it isn't committed"},
+	}, issues)
+}
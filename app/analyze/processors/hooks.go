@@ -0,0 +1,74 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golangci/golangci-worker/app/analyze/hooks"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+)
+
+// stageHooks are hook points called before and after stages of the pipeline
+var stageHooks = map[string]struct{ before, after hooks.Point }{
+	"prepare repo": {before: hooks.PreClone, after: hooks.PostDeps},
+	"lint":         {after: hooks.PostLint},
+	"report":       {before: hooks.PreReport},
+}
+
+// runHooks calls plugins at the point: changes of issues by plugins are saved in the result
+func (g *githubGoPR) runHooks(ctx context.Context, point hooks.Point) error {
+	a := &hooks.Analysis{
+		Repo:              g.context.Repo,
+		PullRequestNumber: g.context.PullRequestNumber,
+		CommitSHA:         g.pr.GetHead().GetSHA(),
+		GUID:              g.analysisGUID,
+		Exec:              g.exec,
+		Config:            g.repoCfg,
+		Patch:             g.patch,
+	}
+	if g.lintRes != nil {
+		a.Issues = g.lintRes.Issues
+	}
+
+	for _, p := range g.plugins {
+		err := g.trackStep(fmt.Sprintf("Plugin %s (%s)", p.Name(), point), func() (string, error) {
+			return "", p.Hook(ctx, point, a)
+		})
+		if err != nil {
+			return &errorutils.InternalError{
+				PublicDesc:  fmt.Sprintf("plugin %s failed", p.Name()),
+				PrivateDesc: fmt.Sprintf("plugin %s failed at %s: %s", p.Name(), point, err),
+			}
+		}
+	}
+
+	if g.lintRes != nil {
+		g.lintRes.Issues = a.Issues
+	}
+	return nil
+}
+
+// callHooks is a middleware calling plugins around stages, after hooks aren't called for failed stages
+func (g *githubGoPR) callHooks(stageName string, next stageFunc) stageFunc {
+	points, ok := stageHooks[stageName]
+	if !ok || len(g.plugins) == 0 {
+		return next
+	}
+
+	return func(ctx context.Context) error {
+		if points.before != "" {
+			if err := g.runHooks(ctx, points.before); err != nil {
+				return err
+			}
+		}
+
+		if err := next(ctx); err != nil {
+			return err
+		}
+
+		if points.after != "" {
+			return g.runHooks(ctx, points.after)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,79 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analyze/hooks"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingPlugin struct {
+	points []hooks.Point
+	failAt hooks.Point
+}
+
+func (p *recordingPlugin) Name() string {
+	return "recording"
+}
+
+func (p *recordingPlugin) Hook(ctx context.Context, point hooks.Point, a *hooks.Analysis) error {
+	p.points = append(p.points, point)
+	if point == p.failAt {
+		return errors.New("compliance check failed")
+	}
+	if point == hooks.PostLint {
+		a.Issues = a.Issues[1:]
+	}
+	return nil
+}
+
+func runHookedPipeline(g *githubGoPR) (reported []result.Issue, err error) {
+	err = newPipeline(
+		stage{name: "prepare repo", run: func(context.Context) error { return nil }},
+		stage{name: "lint", run: func(context.Context) error {
+			g.lintRes = &result.Result{Issues: []result.Issue{{Text: "dropped"}, {Text: "kept"}}}
+			return nil
+		}},
+		stage{name: "report", run: func(context.Context) error {
+			reported = g.lintRes.Issues
+			return nil
+		}},
+	).use(g.callHooks).run(context.Background())
+	return reported, err
+}
+
+func TestCallHooks(t *testing.T) {
+	p := &recordingPlugin{}
+	g := &githubGoPR{
+		pr:               testPR,
+		context:          &github.Context{Repo: github.Repo{Owner: "owner", Name: "name"}},
+		githubGoPRConfig: githubGoPRConfig{plugins: []hooks.Plugin{p}},
+	}
+
+	reported, err := runHookedPipeline(g)
+	assert.NoError(t, err)
+	assert.Equal(t, []hooks.Point{hooks.PreClone, hooks.PostDeps, hooks.PostLint, hooks.PreReport}, p.points)
+	assert.Equal(t, []result.Issue{{Text: "kept"}}, reported)
+	assert.Len(t, g.timeline, 4)
+}
+
+func TestCallHooksFailure(t *testing.T) {
+	p := &recordingPlugin{failAt: hooks.PostDeps}
+	g := &githubGoPR{
+		pr:               testPR,
+		context:          &github.Context{},
+		githubGoPRConfig: githubGoPRConfig{plugins: []hooks.Plugin{p}},
+	}
+
+	_, err := runHookedPipeline(g)
+	ierr, ok := err.(*errorutils.InternalError)
+	if assert.True(t, ok) {
+		assert.Equal(t, "plugin recording failed", ierr.PublicDesc)
+	}
+	assert.Equal(t, []hooks.Point{hooks.PreClone, hooks.PostDeps}, p.points)
+}
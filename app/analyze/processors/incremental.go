@@ -0,0 +1,254 @@
+package processors
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/issuecache"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
+	"github.com/golangci/golangci-worker/app/lib/diffanchor"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/pkg/errors"
+)
+
+// listedPackage is a package of the repo listed by go list
+type listedPackage struct {
+	dir        string // relative to the repo root, "." is the root package
+	importPath string
+	deps       []string
+}
+
+// listPackages lists packages matching pattern in the work dir of exec with their transitive deps
+func listPackages(ctx context.Context, exec executors.Executor, pattern string) ([]listedPackage, error) {
+	out, err := exec.Run(ctx, "go", "list", "-e", "-f", `{{.Dir}}|{{.ImportPath}}|{{join .Deps " "}}`, pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't list packages: %s", out)
+	}
+
+	var ret []listedPackage
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		parts := strings.SplitN(line, "|", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		dir, err := filepath.Rel(exec.WorkDir(), parts[0])
+		if err != nil || strings.HasPrefix(dir, "..") {
+			continue // not a package of the repo
+		}
+		ret = append(ret, listedPackage{
+			dir:        filepath.ToSlash(dir),
+			importPath: parts[1],
+			deps:       strings.Fields(parts[2]),
+		})
+	}
+
+	return ret, nil
+}
+
+// affectedPackages returns packages of pkgs in changed dirs and packages importing them:
+// issues of importers can be changed too, e.g. by a changed signature of a function
+func affectedPackages(pkgs []listedPackage, changed map[string]bool) []listedPackage {
+	changedImports := map[string]bool{}
+	for _, p := range pkgs {
+		if changed[p.dir] {
+			changedImports[p.importPath] = true
+		}
+	}
+
+	var ret []listedPackage
+	for _, p := range pkgs {
+		affected := changed[p.dir]
+		for _, dep := range p.deps {
+			affected = affected || changedImports[dep]
+		}
+		if affected {
+			ret = append(ret, p)
+		}
+	}
+
+	return ret
+}
+
+// isIncremental returns true if issues of the last analysis of the PR can be cached and reused:
+// it needs the merge-base diff to compare diffs of files between analyses, builds of pushes have no PR
+func (g *githubGoPR) isIncremental(ctx context.Context) bool {
+	return g.mergeBase != "" && g.pr.GetNumber() != 0 && !g.labelOpts.fullRepo &&
+		g.ec.IsActiveForAnalysis(ctx, "incremental_analysis", &g.context.Repo, true)
+}
+
+// issueCacheSettings are settings of the analysis changing found issues
+type issueCacheSettings struct {
+	WorkerVersion string
+	LinterVersion string
+	GoVersion     string
+	RepoConfig    *repoconfig.Config
+	Linters       []interface{}
+	Path          string
+}
+
+// issueCacheKey identifies settings of the analysis: versions of tools, the effective repo config, settings
+// of linters and the analysis path. The config of golangci-lint in the repo is compared as a changed file.
+func (g *githubGoPR) issueCacheKey() string {
+	b := g.buildInfo
+	key, err := json.Marshal(issueCacheSettings{
+		WorkerVersion: b.WorkerVersion,
+		LinterVersion: b.LinterVersion,
+		GoVersion:     b.GoVersion,
+		RepoConfig:    g.repoCfg,
+		Linters:       lintersSettings(g.linters),
+		Path:          g.path,
+	})
+	if err != nil {
+		return "" // never happens: settings have only plain fields; an empty key is never reused
+	}
+
+	h := sha256.Sum256(key)
+	return hex.EncodeToString(h[:])
+}
+
+// lintersSettings returns settings of linters changing found issues: caches, packages, concurrency and profiles don't
+func lintersSettings(lintersList []linters.Linter) []interface{} {
+	var ret []interface{}
+	for _, l := range lintersList {
+		if gl, ok := l.(golinters.GolangciLint); ok {
+			gl.Cache, gl.Packages, gl.Concurrency = nil, nil, 0
+			gl.CPUProfilePath, gl.MemProfilePath = "", ""
+			ret = append(ret, gl)
+			continue
+		}
+		ret = append(ret, l.Name())
+	}
+
+	return ret
+}
+
+// emitIssues streams cached issues to onIssue like issues found by linters, e.g. for previews
+func emitIssues(issues []result.Issue, onIssue linters.IssueFunc) error {
+	if onIssue == nil {
+		return nil
+	}
+
+	for _, i := range issues {
+		if err := onIssue(i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lintIncrementally re-lints only packages changed since the last analysis of the force-pushed PR:
+// issues of other packages are taken from the issue cache. All packages are linted if cached issues
+// can't be reused, issues of complete runs are cached for the next analysis.
+func (g *githubGoPR) lintIncrementally(ctx context.Context, onIssue linters.IssueFunc) (*result.Result, error) {
+	if !g.isIncremental(ctx) {
+		return g.runner.RunStreaming(ctx, g.linters, g.exec, onIssue)
+	}
+
+	patch, err := diffanchor.Parse(g.patch)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't parse patch, lint all packages: %s", err)
+		return g.runner.RunStreaming(ctx, g.linters, g.exec, onIssue)
+	}
+	files := issuecache.HashFiles(patch)
+
+	var res *result.Result
+	if cached, pkgs, ok := g.reusableIssues(ctx, files); ok {
+		analytics.SaveEventProp(ctx, analytics.EventPRChecked, "incrementalPackages", len(pkgs))
+		if err = emitIssues(cached, onIssue); err != nil && err != linters.ErrStopRun {
+			return nil, err
+		}
+		res = &result.Result{}
+		if len(pkgs) != 0 { // no packages means all packages for golangci-lint
+			if res, err = g.runner.RunStreaming(ctx, withPackages(g.linters, pkgs), g.exec, onIssue); err != nil {
+				return nil, err
+			}
+		}
+		res.Issues = append(cached, res.Issues...)
+	} else if res, err = g.runner.RunStreaming(ctx, g.linters, g.exec, onIssue); err != nil {
+		return nil, err
+	}
+
+	g.cacheIssues(ctx, res, files)
+	return res, nil
+}
+
+// reusableIssues returns cached issues of packages not affected by changes since the last analysis
+// of the PR and packages to re-lint, it's false if all packages must be linted
+func (g *githubGoPR) reusableIssues(ctx context.Context, files map[string]string) ([]result.Issue, []string, bool) {
+	repo := &g.context.Repo
+	prev, err := g.issueCache.GetLast(ctx, repo.Owner, repo.Name, g.pr.GetNumber())
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't get cached issues of the PR, lint all packages: %s", err)
+		return nil, nil, false
+	}
+
+	changed, ok := prev.Changed(g.pr.GetHead().GetSHA(), g.mergeBase, g.issueCacheKey(), files)
+	if !ok {
+		return nil, nil, false
+	}
+
+	changedDirs := map[string]bool{}
+	for _, f := range changed {
+		if !strings.HasSuffix(f, ".go") { // e.g. go.mod or the config of golangci-lint
+			analytics.Log(ctx).Infof("File %s was changed since commit %s, lint all packages", f, prev.CommitSHA)
+			return nil, nil, false
+		}
+		changedDirs[path.Dir(f)] = true
+	}
+
+	pattern := "./..."
+	if g.path != "" {
+		pattern = "./" + g.path + "/..."
+	}
+	listed, err := listPackages(ctx, g.exec, pattern)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't find affected packages, lint all packages: %s", err)
+		return nil, nil, false
+	}
+
+	affected := affectedPackages(listed, changedDirs)
+	if len(affected) == len(listed) {
+		return nil, nil, false
+	}
+
+	// issues of changed dirs are dropped even if they aren't packages anymore
+	var pkgs []string
+	for _, p := range affected {
+		changedDirs[p.dir] = true
+		pkgs = append(pkgs, packageArg(p.dir))
+	}
+
+	analytics.Log(ctx).Infof("%d files were changed since commit %s, re-lint %d of %d packages",
+		len(changed), prev.CommitSHA, len(pkgs), len(listed))
+	return prev.IssuesOutside(changedDirs), pkgs, true
+}
+
+// cacheIssues saves issues of the complete run for the next analysis of the PR: errors don't fail the analysis
+func (g *githubGoPR) cacheIssues(ctx context.Context, res *result.Result, files map[string]string) {
+	if res.Capped || len(res.TimedOutLinters) != 0 || len(res.FailedLinters) != 0 {
+		return
+	}
+
+	e := &issuecache.Entry{
+		CommitSHA: g.pr.GetHead().GetSHA(),
+		MergeBase: g.mergeBase,
+		Key:       g.issueCacheKey(),
+		Files:     files,
+		Issues:    append([]result.Issue(nil), res.Issues...), // issues of the result are changed by next stages
+	}
+	repo := &g.context.Repo
+	if err := g.issueCache.Put(ctx, repo.Owner, repo.Name, g.pr.GetNumber(), e); err != nil {
+		analytics.Log(ctx).Warnf("Can't cache issues of the PR: %s", err)
+	}
+}
@@ -0,0 +1,71 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
+	"github.com/golangci/golangci-worker/app/lib/buildinfo"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAffectedPackages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().WorkDir().Return("/goapp/src/github.com/o/r").AnyTimes()
+	exec.EXPECT().Run(any, "go", "list", "-e", "-f", `{{.Dir}}|{{.ImportPath}}|{{join .Deps " "}}`, "./...").
+		Return("/goapp/src/github.com/o/r|github.com/o/r|fmt github.com/o/r/api github.com/o/r/store\n"+
+			"/goapp/src/github.com/o/r/api|github.com/o/r/api|fmt github.com/o/r/store\n"+
+			"/goapp/src/github.com/o/r/store|github.com/o/r/store|fmt\n"+
+			"/goapp/src/github.com/o/r/tools|github.com/o/r/tools|fmt", nil)
+
+	pkgs, err := listPackages(context.Background(), exec, "./...")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, pkgs, 4)
+	assert.Equal(t, ".", pkgs[0].dir)
+	assert.Equal(t, "store", pkgs[2].dir)
+
+	var dirs []string
+	for _, p := range affectedPackages(pkgs, map[string]bool{"store": true}) {
+		dirs = append(dirs, p.dir)
+	}
+	assert.Equal(t, []string{".", "api", "store"}, dirs, "importers of changed packages are affected")
+	assert.Empty(t, affectedPackages(pkgs, map[string]bool{"docs": true}))
+}
+
+func TestIssueCacheKey(t *testing.T) {
+	newPR := func(repoCfg *repoconfig.Config, gl golinters.GolangciLint) *githubGoPR {
+		return &githubGoPR{
+			repoCfg:          repoCfg,
+			buildInfo:        &buildinfo.Info{WorkerVersion: "v1"},
+			githubGoPRConfig: githubGoPRConfig{linters: []linters.Linter{gl}},
+		}
+	}
+
+	key := newPR(&repoconfig.Config{}, golinters.GolangciLint{}).issueCacheKey()
+	assert.NotEmpty(t, key)
+	partial := golinters.GolangciLint{Packages: []string{"./a"}, Concurrency: 2}
+	assert.Equal(t, key, newPR(&repoconfig.Config{}, partial).issueCacheKey(), "packages and concurrency don't change issues")
+	assert.NotEqual(t, key, newPR(&repoconfig.Config{SkipDirs: []string{"gen"}}, golinters.GolangciLint{}).issueCacheKey())
+	assert.NotEqual(t, key, newPR(&repoconfig.Config{}, golinters.GolangciLint{DisableAll: true}).issueCacheKey())
+}
+
+func TestEmitIssues(t *testing.T) {
+	issues := []result.Issue{{File: "a.go"}, {File: "b.go"}}
+	var emitted []result.Issue
+	assert.NoError(t, emitIssues(issues, func(i result.Issue) error {
+		emitted = append(emitted, i)
+		return nil
+	}))
+	assert.Equal(t, issues, emitted)
+	assert.NoError(t, emitIssues(issues, nil))
+}
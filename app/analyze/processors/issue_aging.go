@@ -0,0 +1,75 @@
+package processors
+
+import (
+	"context"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/issueaging"
+)
+
+// maxResolvedIssueEvents limits events of an analysis: e.g. a fix of generated code can resolve thousands of issues
+const maxResolvedIssueEvents = 100
+
+// trackIssueAging compares issues with issues of the previous analysis of the PR and sends ages of
+// resolved issues to analytics. It runs before suppressions: ignored issues aren't counted as fixed.
+// Errors of the storage don't fail the analysis.
+func (g *githubGoPR) trackIssueAging(ctx context.Context) error {
+	repo := &g.context.Repo
+	prev, err := g.issueAging.GetLast(ctx, repo.Owner, repo.Name, g.pr.GetNumber())
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't get issues of the previous analysis, don't track their aging: %s", err)
+		return nil
+	}
+
+	now := time.Now()
+	next, resolved := issueaging.Track(prev, withoutInformational(g.lintRes.Issues), g.pr.GetHead().GetSHA(), now)
+	g.issueDelta = newIssueDelta(next, resolved, now)
+	if err = g.issueAging.Put(ctx, repo.Owner, repo.Name, g.pr.GetNumber(), next); err != nil {
+		analytics.Log(ctx).Warnf("Can't save issues of the analysis for aging: %s", err)
+		return nil
+	}
+
+	trackResolvedIssues(ctx, resolved)
+	return nil
+}
+
+// closeIssueAging resolves issues of the last analysis of the merged or closed PR
+func (g *githubGoPR) closeIssueAging(ctx context.Context) {
+	repo := &g.context.Repo
+	prev, err := g.issueAging.GetLast(ctx, repo.Owner, repo.Name, g.pr.GetNumber())
+	if err != nil || prev == nil || len(prev.Issues) == 0 {
+		if err != nil {
+			analytics.Log(ctx).Warnf("Can't get issues of the last analysis of the closed PR: %s", err)
+		}
+		return
+	}
+
+	resolution := issueaging.ResolutionClosed
+	if g.pr.GetMerged() {
+		resolution = issueaging.ResolutionMerged
+	}
+
+	// the empty snapshot prevents resolving the issues again by reanalyses
+	now := time.Now()
+	closed := &issueaging.Snapshot{CommitSHA: prev.CommitSHA, AnalyzedAt: now}
+	if err = g.issueAging.Put(ctx, repo.Owner, repo.Name, g.pr.GetNumber(), closed); err != nil {
+		analytics.Log(ctx).Warnf("Can't save issues of the closed PR for aging: %s", err)
+		return
+	}
+
+	trackResolvedIssues(ctx, issueaging.Close(prev, resolution, now))
+}
+
+func trackResolvedIssues(ctx context.Context, resolved []issueaging.Resolved) {
+	if len(resolved) > maxResolvedIssueEvents {
+		analytics.Log(ctx).Infof("%d issues were resolved, track only %d of them", len(resolved), maxResolvedIssueEvents)
+		resolved = resolved[:maxResolvedIssueEvents]
+	}
+
+	for _, r := range resolved {
+		evCtx := analytics.ContextWithEventPropsCollector(ctx, analytics.EventIssueResolved)
+		analytics.SaveEventProps(evCtx, analytics.EventIssueResolved, r.EventProps())
+		analytics.GetTracker(evCtx).Track(evCtx, analytics.EventIssueResolved)
+	}
+}
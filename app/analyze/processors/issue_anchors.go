@@ -0,0 +1,45 @@
+package processors
+
+import (
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+)
+
+// issueAnchor links an issue on the details page: statuses and comments deep-link to it.
+// The web renders issues with id={Anchor}, the anchor is stable between analyses of the PR.
+type issueAnchor struct {
+	File        string
+	Line        int
+	Fingerprint string
+	Anchor      string
+	URL         string `json:",omitempty"` // empty if there is no details page
+}
+
+func anchorName(fingerprint string) string {
+	return "issue-" + fingerprint
+}
+
+// buildIssueAnchors returns anchors of blocking issues in the order of issues,
+// fingerprints are the same as in review comments
+func buildIssueAnchors(issues []result.Issue, pageURL string) []issueAnchor {
+	fingerprints := result.Fingerprints(issues)
+
+	var ret []issueAnchor
+	for ind, i := range issues {
+		if i.Informational {
+			continue // it's shown in a separate list without anchors
+		}
+
+		a := issueAnchor{
+			File:        i.File,
+			Line:        i.LineNumber,
+			Fingerprint: fingerprints[ind],
+			Anchor:      anchorName(fingerprints[ind]),
+		}
+		if pageURL != "" {
+			a.URL = pageURL + "#" + a.Anchor
+		}
+		ret = append(ret, a)
+	}
+
+	return ret
+}
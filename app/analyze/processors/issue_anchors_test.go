@@ -0,0 +1,26 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildIssueAnchors(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "errcheck", File: "a.go", LineNumber: 3, Text: "unchecked"},
+		{FromLinter: "deps-freshness", File: "go.mod", LineNumber: 5, Text: "outdated", Informational: true},
+	}
+	fp := result.Fingerprints(issues)[0]
+
+	assert.Equal(t, []issueAnchor{{
+		File:        "a.go",
+		Line:        3,
+		Fingerprint: fp,
+		Anchor:      "issue-" + fp,
+		URL:         "https://golangci.com/r/github.com/o/r/pulls/1#issue-" + fp,
+	}}, buildIssueAnchors(issues, "https://golangci.com/r/github.com/o/r/pulls/1"))
+
+	assert.Empty(t, buildIssueAnchors(issues, "")[0].URL)
+}
@@ -0,0 +1,35 @@
+package processors
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/killswitch"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+)
+
+func killSwitchWarning(msg i18n.Printer, e *killswitch.Entry) string {
+	return msg.Sprintf(i18n.WarnKillSwitch, e.Reason)
+}
+
+// isHardKilled checks kill switches before any work: analyses of repos under hard kill switches are dropped
+// without requests to GitHub, soft kill switches are handled after the pull request is fetched
+func (g *githubGoPR) isHardKilled(ctx context.Context) bool {
+	g.killSwitch = killswitch.Check(ctx, g.killSwitches, &g.context.Repo)
+	if g.killSwitch == nil || g.killSwitch.Level != killswitch.LevelHard {
+		return false
+	}
+
+	analytics.Log(ctx).Warnf("Drop analysis disabled by the hard kill switch %+v", *g.killSwitch)
+	return true
+}
+
+// repoKillSwitch returns the kill switch disabling analyses of the repo or nil
+func (r Repo) repoKillSwitch(ctx *RepoContext) *killswitch.Entry {
+	e := killswitch.Check(ctx.Ctx, r.KillSwitches, ctx.Repo)
+	if e != nil {
+		r.Log.Warnf("Analysis is disabled by the kill switch %+v", *e)
+	}
+
+	return e
+}
@@ -0,0 +1,60 @@
+package processors
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/killswitch"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/fetchers"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/test"
+)
+
+func TestHardKillSwitch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// nothing must be requested from GitHub, cloned and run
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Clean()
+	testProcessor(t, ctrl, githubGoPRConfig{
+		linters:     []linters.Linter{linters.NewMockLinter(ctrl)},
+		repoFetcher: fetchers.NewMockFetcher(ctrl),
+		exec:        exec,
+		client:      github.NewMockClient(ctrl),
+		killSwitches: getKillSwitches(ctrl, killswitch.Entry{
+			Owner: github.FakeContext.Repo.Owner, Level: killswitch.LevelHard, Reason: "abuse",
+		}),
+	})
+}
+
+func TestSoftKillSwitch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetTokenScopes(testCtxMatcher, c).Return(&github.TokenScopes{}, nil)
+	gc.EXPECT().GetPullRequest(testCtxMatcher, c).Return(testPR, nil)
+	gc.EXPECT().GetPullRequestPatch(testCtxMatcher, c).Return(getFakePatch(t), nil).MaxTimes(1) // fetched in the background
+
+	test.Init()
+	url := fmt.Sprintf("%s/r/github.com/%s/%s/pulls/%d", os.Getenv("WEB_ROOT"), c.Repo.Owner, c.Repo.Name, testPR.GetNumber())
+	gc.EXPECT().SetCommitStatus(testCtxMatcher, c, testSHA, github.StatusSuccess, "Analysis is disabled", url)
+
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Clean()
+	testProcessor(t, ctrl, githubGoPRConfig{
+		linters:     []linters.Linter{linters.NewMockLinter(ctrl)},
+		repoFetcher: fetchers.NewMockFetcher(ctrl),
+		exec:        exec,
+		client:      gc,
+		killSwitches: getKillSwitches(ctrl, killswitch.Entry{
+			Owner: c.Repo.Owner, Repo: c.Repo.Name, Level: killswitch.LevelSoft, Reason: "broken builds",
+		}),
+	})
+}
@@ -0,0 +1,62 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golangci/golangci-shared/pkg/config"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/repoinfo"
+)
+
+const defaultMinGoSharePercent = 10
+
+type repoLanguage string
+
+const (
+	languageGo    repoLanguage = "go"
+	languageMixed repoLanguage = "mixed" // go isn't the primary language, only go code is analyzed
+	languageNotGo repoLanguage = "notGo"
+)
+
+// classifyLanguage classifies the repo by the share of go code: repos with a small share of go code
+// (e.g. examples or scripts in go) aren't analyzed, unknown repos are treated as go ones
+func classifyLanguage(meta *repoinfo.Metadata, minGoSharePercent int) repoLanguage {
+	if meta == nil {
+		return languageGo
+	}
+
+	share := meta.GoShare() * 100
+	switch {
+	case share < float64(minGoSharePercent):
+		return languageNotGo
+	case meta.PrimaryLanguage() != "go":
+		return languageMixed
+	default:
+		return languageGo
+	}
+}
+
+func minGoSharePercent() int {
+	return config.NewEnvConfig(logutil.NewStderrLog("config")).GetInt("REPO_MIN_GO_SHARE_PERCENT", defaultMinGoSharePercent)
+}
+
+func notGoRepoWarning(meta *repoinfo.Metadata) string {
+	return fmt.Sprintf("The repo is mostly written in %s: Go is only %.0f%% of its code. "+
+		"GolangCI analyzes only Go code, the analysis is skipped", meta.PrimaryLanguage(), meta.GoShare()*100)
+}
+
+// skipNotGoRepo checks whether the analysis of the repo must be skipped because it isn't written in go
+// and warns about it: linters would fail with confusing "no go files" errors
+func skipNotGoRepo(ctx context.Context, meta *repoinfo.Metadata, eventName analytics.EventName, warn func(tag, text string)) bool {
+	lang := classifyLanguage(meta, minGoSharePercent())
+	analytics.SaveEventProp(ctx, eventName, "repoLanguage", string(lang))
+	if lang != languageNotGo {
+		return false
+	}
+
+	analytics.Log(ctx).Infof("Skip analysis of the repo not written in go: %+v", meta)
+	warn("process", notGoRepoWarning(meta))
+	return true
+}
@@ -0,0 +1,34 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/repoinfo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyLanguage(t *testing.T) {
+	assert.Equal(t, languageGo, classifyLanguage(nil, 10), "unknown metadata")
+	assert.Equal(t, languageGo, classifyLanguage(&repoinfo.Metadata{}, 10), "no code")
+	assert.Equal(t, languageGo, classifyLanguage(&repoinfo.Metadata{LOC: 60, OtherLOC: map[string]int{"python": 40}}, 10))
+	assert.Equal(t, languageMixed, classifyLanguage(&repoinfo.Metadata{LOC: 40, OtherLOC: map[string]int{"python": 60}}, 10))
+	assert.Equal(t, languageNotGo, classifyLanguage(&repoinfo.Metadata{LOC: 5, OtherLOC: map[string]int{"python": 95}}, 10))
+	assert.Equal(t, languageNotGo, classifyLanguage(&repoinfo.Metadata{OtherLOC: map[string]int{"java": 1}}, 10))
+}
+
+func TestSkipNotGoRepo(t *testing.T) {
+	var warnings []string
+	warn := func(tag, text string) {
+		warnings = append(warnings, text)
+	}
+
+	meta := &repoinfo.Metadata{LOC: 40, OtherLOC: map[string]int{"javascript": 60}}
+	assert.False(t, skipNotGoRepo(testCtx, meta, analytics.EventPRChecked, warn), "mixed repo is analyzed")
+	assert.Empty(t, warnings)
+
+	meta = &repoinfo.Metadata{LOC: 5, OtherLOC: map[string]int{"javascript": 95}}
+	assert.True(t, skipNotGoRepo(testCtx, meta, analytics.EventPRChecked, warn))
+	assert.Equal(t, []string{"The repo is mostly written in javascript: Go is only 5% of its code. " +
+		"GolangCI analyzes only Go code, the analysis is skipped"}, warnings)
+}
@@ -0,0 +1,27 @@
+package processors
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/lintconfig"
+)
+
+// summaryReporter is a reporter posting a summary comment, e.g. reporters.GithubReviewer
+type summaryReporter interface {
+	AddSummary(text string)
+}
+
+// checkLintConfig helps onboarding: it suggests a config if the repo has no one and flags deprecated settings
+func (g *githubGoPR) checkLintConfig(ctx context.Context) {
+	report, err := lintconfig.Check(ctx, g.exec)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't check golangci-lint config: %s", err)
+		return
+	}
+
+	g.lintConfig = report
+	if sr, ok := g.reporter.(summaryReporter); ok && !report.IsEmpty() {
+		sr.AddSummary(report.SummaryText(g.msg))
+	}
+}
@@ -0,0 +1,210 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/cancellation"
+	"github.com/golangci/golangci-worker/app/analyze/commitstatus"
+	"github.com/golangci/golangci-worker/app/analyze/hooks"
+	"github.com/golangci/golangci-worker/app/analyze/issueaging"
+	"github.com/golangci/golangci-worker/app/analyze/issuecache"
+	"github.com/golangci/golangci-worker/app/analyze/killswitch"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/orgfeed"
+	"github.com/golangci/golangci-worker/app/analyze/prstate"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
+	"github.com/golangci/golangci-worker/app/analyze/repostate"
+	"github.com/golangci/golangci-worker/app/analyze/suppressions"
+	"github.com/golangci/golangci-worker/app/lib/artifacts"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/fetchers"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/usage"
+	gh "github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+const (
+	localRef          = "local"
+	localAnalysisGUID = "analyze-local"
+)
+
+// LocalConfig configures analysis of a local repo by the production pull request pipeline
+type LocalConfig struct {
+	RepoDir   string
+	PatchPath string
+
+	// Repo is used for the workspace path, it's local/<dir name> if it's empty
+	Repo *github.Repo
+}
+
+// localGithub serves the pull request from the local patch and sends nothing to GitHub
+type localGithub struct {
+	patch string
+	repo  *github.Repo
+}
+
+var _ github.Client = localGithub{}
+
+func (c localGithub) GetPullRequest(ctx context.Context, _ *github.Context) (*gh.PullRequest, error) {
+	repo := &gh.Repository{
+		Name:     gh.String(c.repo.Name),
+		FullName: gh.String(c.repo.FullName()),
+	}
+	return &gh.PullRequest{
+		State: gh.String("open"),
+		Head: &gh.PullRequestBranch{
+			Ref:  gh.String(localRef),
+			SHA:  gh.String(localRef),
+			Repo: repo,
+		},
+		Base: &gh.PullRequestBranch{
+			Repo: repo,
+		},
+	}, nil
+}
+
+func (c localGithub) GetPullRequestComments(ctx context.Context, _ *github.Context) ([]*github.PullRequestComment, error) {
+	return nil, nil
+}
+
+func (c localGithub) GetPullRequestComment(ctx context.Context, _ *github.Context, id int64) (*github.PullRequestComment, error) {
+	return nil, fmt.Errorf("no comment %d in local analysis", id)
+}
+
+func (c localGithub) GetPullRequestReviews(ctx context.Context, _ *github.Context) ([]*github.PullRequestReview, error) {
+	return nil, nil
+}
+
+func (c localGithub) GetPullRequestPatch(ctx context.Context, _ *github.Context) (string, error) {
+	return c.patch, nil
+}
+
+func (c localGithub) RebuildPullRequestPatch(ctx context.Context, _ *github.Context) (*github.RebuiltPatch, error) {
+	return nil, errors.New("local patch is never rebuilt")
+}
+
+func (c localGithub) GetTokenScopes(ctx context.Context, _ *github.Context) (*github.TokenScopes, error) {
+	return &github.TokenScopes{}, nil
+}
+
+func (c localGithub) CreateReview(ctx context.Context, _ *github.Context, _ *github.Review) error {
+	return nil
+}
+
+func (c localGithub) SetCommitStatus(ctx context.Context, _ *github.Context, _ string, _ github.Status, _, _ string) error {
+	return nil
+}
+
+func (c localGithub) GetReleaseByTag(ctx context.Context, _ *github.Context, tag string) (*gh.RepositoryRelease, error) {
+	return nil, fmt.Errorf("no release of tag %s in local analysis", tag)
+}
+
+func (c localGithub) EditReleaseBody(ctx context.Context, _ *github.Context, _ int, _ string) error {
+	return nil
+}
+
+type nopReporter struct{}
+
+func (nopReporter) Report(ctx context.Context, ref string, issues []result.Issue) error {
+	return nil
+}
+
+// localState keeps the last saved state instead of sending it to the API
+type localState struct {
+	last *prstate.State
+}
+
+func (s *localState) UpdateState(ctx context.Context, owner, name, analysisID string, state *prstate.State) error {
+	s.last = state
+	return nil
+}
+
+func (s *localState) GetState(ctx context.Context, owner, name, analysisID string) (*prstate.State, error) {
+	return &prstate.State{Status: statusProcessing}, nil
+}
+
+type emptyConfigFetcher struct{}
+
+func (emptyConfigFetcher) FetchOrgDefaults(ctx context.Context, owner string) (*repoconfig.Config, error) {
+	return &repoconfig.Config{}, nil
+}
+
+func (emptyConfigFetcher) FetchRepoConfig(ctx context.Context, repo *github.Repo) (*repoconfig.Config, error) {
+	return &repoconfig.Config{}, nil
+}
+
+// AnalyzeLocal runs the pull request pipeline on the local repo and returns the saved state:
+// nothing is sent to GitHub or the API
+func AnalyzeLocal(ctx context.Context, cfg LocalConfig) (*prstate.State, error) {
+	repoDir, err := filepath.Abs(cfg.RepoDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get absolute path of %s", cfg.RepoDir)
+	}
+
+	patch, err := ioutil.ReadFile(cfg.PatchPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read patch %s", cfg.PatchPath)
+	}
+
+	repo := cfg.Repo
+	if repo == nil {
+		repo = &github.Repo{Owner: "local", Name: filepath.Base(repoDir)}
+	}
+
+	exec, err := executors.NewTempDirShell("analyze-local")
+	if err != nil {
+		return nil, errors.Wrap(err, "can't make executor")
+	}
+
+	// the pipeline saves analytics props like in the consumer
+	ctx = analytics.ContextWithEventPropsCollector(ctx, analytics.EventPRChecked)
+	ctx = analytics.ContextWithTrackingProps(ctx, map[string]interface{}{
+		"repoName": repo.FullName(),
+		"provider": "local",
+	})
+
+	state := &localState{}
+	prCfg := githubGoPRConfig{
+		repoFetcher: fetchers.NewLocal(repoDir),
+		reporter:    nopReporter{},
+		exec:        exec,
+		client:      localGithub{patch: string(patch), repo: repo},
+		state:       state,
+		cfgFetcher:  emptyConfigFetcher{},
+		issueAging:  issueaging.NopStorage{},
+		issueCache:  issuecache.NopStorage{},
+		baselines:   repostate.NopStorage{},
+		feed:        orgfeed.NopPublisher{},
+		statuses:    commitstatus.NopStorage{},
+
+		// nothing is reported or stored remotely
+		suppressions: suppressions.NopStorage{},
+		usage:        usage.NopReporter{},
+		killSwitches: killswitch.NopFetcher{},
+		artifacts:    artifacts.Disabled(),
+		plugins:      []hooks.Plugin{},
+
+		cancellations: cancellation.NopRegistry{},
+	}
+	c := &github.Context{Repo: *repo}
+
+	p, err := newGithubGoPR(ctx, c, prCfg, localAnalysisGUID)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't make processor")
+	}
+
+	processErr := p.Process(ctx)
+	if state.last == nil {
+		if processErr == nil {
+			processErr = fmt.Errorf("no result was saved")
+		}
+		return nil, processErr
+	}
+
+	return state.last, processErr
+}
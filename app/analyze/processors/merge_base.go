@@ -0,0 +1,113 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+)
+
+const (
+	mergeBaseRef = "refs/golangci/base"
+
+	// the head is cloned with depth 1: history is fetched by steps until the merge-base is found
+	mergeBaseFetchStep  = 100
+	mergeBaseMaxDeepens = 3
+)
+
+// findMergeBase returns the merge-base of the head and the current target branch
+func (g *githubGoPR) findMergeBase(ctx context.Context) (string, error) {
+	base := g.pr.GetBase()
+	baseURL := g.context.GetCloneURL(base.GetRepo())
+	refspec := fmt.Sprintf("+refs/heads/%s:%s", base.GetRef(), mergeBaseRef)
+	if err := executors.GitFetch(ctx, g.exec, baseURL, refspec, mergeBaseFetchStep); err != nil {
+		return "", err
+	}
+
+	for i := 0; ; i++ {
+		mergeBase, err := executors.GitMergeBase(ctx, g.exec, "HEAD", mergeBaseRef)
+		if err == nil {
+			return mergeBase, nil
+		}
+		if i == mergeBaseMaxDeepens {
+			return "", fmt.Errorf("no merge-base in %d commits: %s", (i+1)*mergeBaseFetchStep, err)
+		}
+
+		if err = executors.GitDeepen(ctx, g.exec, "origin", "", mergeBaseFetchStep); err != nil {
+			return "", err
+		}
+		if err = executors.GitDeepen(ctx, g.exec, baseURL, refspec, mergeBaseFetchStep); err != nil {
+			return "", err
+		}
+	}
+}
+
+// scopeByMergeBase replaces the provider patch with the diff from the merge-base: it's what GitHub
+// shows for pull requests rebased onto the target branch. The provider patch is kept on failures.
+// Patches rebuilt from listed files are always replaced: they can miss files.
+func (g *githubGoPR) scopeByMergeBase(ctx context.Context) {
+	if g.rebuiltPatch == nil &&
+		(g.labelOpts.fullRepo || !g.ec.IsActiveForAnalysis(ctx, "merge_base_scoping", &g.context.Repo, true)) {
+		return
+	}
+
+	mergeBase, err := g.findMergeBase(ctx)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't find merge-base, use the provider patch: %s", err)
+		return
+	}
+
+	patch, err := executors.GitDiff(ctx, g.exec, mergeBase, "HEAD")
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't diff with merge-base %s, use the provider patch: %s", mergeBase, err)
+		return
+	}
+	if err = storePatch(ctx, patch, g.exec); err != nil {
+		analytics.Log(ctx).Warnf("Can't store merge-base patch, use the provider patch: %s", err)
+		return
+	}
+
+	g.patch = patch
+	g.mergeBase = mergeBase
+	analytics.SaveEventProp(ctx, analytics.EventPRChecked, "mergeBaseScoped", true)
+}
+
+// maxListedSkippedFiles limits files listed in the warning about files missing in the rebuilt patch
+const maxListedSkippedFiles = 10
+
+// checkRebuiltPatch runs if the merge-base diff of the too large pull request couldn't be made:
+// the analysis fails if GitHub didn't list all files, files without patches are shown in a warning
+func (g *githubGoPR) checkRebuiltPatch(ctx context.Context) error {
+	if g.rebuiltPatch == nil || g.mergeBase != "" {
+		return nil
+	}
+
+	if g.rebuiltPatch.Capped {
+		return errorutils.ResourceLimit(errors.New("rebuilt patch is capped and there is no merge-base diff"),
+			fmt.Sprintf("pull request is too large: GitHub lists only %d changed files and the full diff can't be made",
+				github.MaxListedPullRequestFiles))
+	}
+
+	skipped := g.rebuiltPatch.SkippedFiles
+	if len(skipped) == 0 {
+		return nil
+	}
+
+	analytics.Log(ctx).Warnf("Analyzing rebuilt patch without %d files", len(skipped))
+	listed := skipped
+	if len(listed) > maxListedSkippedFiles {
+		listed = listed[:maxListedSkippedFiles]
+	}
+	text := g.msg.Sprintf(i18n.WarnRebuiltPatch, len(skipped), strings.Join(listed, ", "))
+	if len(listed) < len(skipped) {
+		text += g.msg.Sprintf(i18n.WarnMoreFiles, len(skipped)-len(listed))
+	}
+	g.publicWarn("patch", text)
+	return nil
+}
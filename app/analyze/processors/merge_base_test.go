@@ -0,0 +1,36 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRebuiltPatch(t *testing.T) {
+	ctx := context.Background()
+
+	g := &githubGoPR{rebuiltPatch: &github.RebuiltPatch{Capped: true}, mergeBase: "abc"}
+	assert.NoError(t, g.checkRebuiltPatch(ctx), "the merge-base diff replaced the rebuilt patch")
+	assert.Empty(t, g.warnings)
+
+	g = &githubGoPR{rebuiltPatch: &github.RebuiltPatch{Capped: true}}
+	err := g.checkRebuiltPatch(ctx)
+	assert.Equal(t, errorutils.KindResourceLimit, errorutils.KindOf(err))
+	assert.Equal(t, "pull request is too large: GitHub lists only 3000 changed files and the full diff can't be made",
+		errorutils.PublicDesc(err))
+
+	var skipped []string
+	for i := 0; i < 12; i++ {
+		skipped = append(skipped, fmt.Sprintf("f%d.bin", i))
+	}
+	g = &githubGoPR{rebuiltPatch: &github.RebuiltPatch{SkippedFiles: skipped}}
+	assert.NoError(t, g.checkRebuiltPatch(ctx))
+	if assert.Len(t, g.warnings, 1) {
+		assert.Equal(t, "The diff of the pull request is too large: 12 changed files have no diff on GitHub and aren't analyzed: "+
+			"f0.bin, f1.bin, f2.bin, f3.bin, f4.bin, f5.bin, f6.bin, f7.bin, f8.bin, f9.bin and 2 more", g.warnings[0].Text)
+	}
+}
@@ -0,0 +1,81 @@
+package processors
+
+import (
+	"bytes"
+	"context"
+	"text/template"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+	gh "github.com/google/go-github/github"
+)
+
+// summaryOnlyReporter is a reporter able to list issues in the summary comment, e.g. reporters.GithubReviewer
+type summaryOnlyReporter interface {
+	summaryReporter
+	UseSummaryOnly()
+}
+
+func isFirstTimeContributor(pr *gh.PullRequest) bool {
+	switch pr.GetAuthorAssociation() {
+	case "FIRST_TIME_CONTRIBUTOR", "FIRST_TIMER":
+		return true
+	default:
+		return false
+	}
+}
+
+func newcomerGreeting(tpl, author string, p i18n.Printer) (string, error) {
+	if tpl == "" {
+		return p.Sprintf(i18n.SummaryNewcomer, author), nil
+	}
+
+	t, err := template.New("newcomer").Parse(tpl)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, repoconfig.NewcomerData{Author: author}); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// detectNewcomer softens reporting for PRs of first-time contributors if the repo config asks for it
+func (g *githubGoPR) detectNewcomer(ctx context.Context) {
+	if !g.repoCfg.GetFriendlyToNewcomers() || !isFirstTimeContributor(g.pr) {
+		return
+	}
+
+	sr, ok := g.reporter.(summaryOnlyReporter)
+	if !ok {
+		return
+	}
+
+	author := g.pr.GetUser().GetLogin()
+	greeting, err := newcomerGreeting(g.repoCfg.NewcomerTemplate, author, g.msg)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Failed to execute newcomer template %q: %s", g.repoCfg.NewcomerTemplate, err)
+		greeting, _ = newcomerGreeting("", author, g.msg)
+	}
+
+	sr.AddSummary(greeting)
+	sr.UseSummaryOnly()
+	g.newcomer = true
+	analytics.SaveEventProp(ctx, analytics.EventPRChecked, "firstTimeContributor", true)
+}
+
+// statusForIssues doesn't fail the status for first-time contributors: issues are only suggestions for them
+func (g *githubGoPR) statusForIssues(issues []result.Issue) (github.Status, string) {
+	status, desc := getGithubStatusForIssues(issues, g.repoCfg.QualityGate, g.msg, g.baseline)
+	if g.newcomer {
+		status = github.StatusSuccess
+	}
+
+	return status, desc
+}
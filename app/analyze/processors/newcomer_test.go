@@ -0,0 +1,59 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
+	"github.com/golangci/golangci-worker/app/analyze/reporters"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+	gh "github.com/google/go-github/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewcomerGreeting(t *testing.T) {
+	text, err := newcomerGreeting("", "octocat", i18n.Printer{})
+	assert.NoError(t, err)
+	assert.Contains(t, text, "@octocat")
+
+	text, err = newcomerGreeting("Welcome, {{.Author}}!", "octocat", i18n.Printer{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome, octocat!", text)
+
+	_, err = newcomerGreeting("{{.Unknown}}", "octocat", i18n.Printer{})
+	assert.Error(t, err)
+}
+
+func TestNewcomerStatusDoesntFail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pr := &gh.PullRequest{
+		AuthorAssociation: gh.String("FIRST_TIME_CONTRIBUTOR"),
+		User:              &gh.User{Login: gh.String("octocat")},
+	}
+	g := &githubGoPR{
+		pr:      pr,
+		repoCfg: &repoconfig.Config{FriendlyToNewcomers: repoconfig.Bool(true)},
+		githubGoPRConfig: githubGoPRConfig{
+			reporter: reporters.NewGithubReviewer(&github.FakeContext, github.NewMockClient(ctrl), reporters.GithubReviewerOptions{}),
+		},
+	}
+	issues := []result.Issue{fakeChangedIssue}
+
+	status, desc := g.statusForIssues(issues)
+	assert.Equal(t, github.StatusFailure, status)
+
+	g.detectNewcomer(testCtx)
+	assert.True(t, g.newcomer)
+	status, newcomerDesc := g.statusForIssues(issues)
+	assert.Equal(t, github.StatusSuccess, status)
+	assert.Equal(t, desc, newcomerDesc)
+
+	pr.AuthorAssociation = gh.String("CONTRIBUTOR")
+	g.newcomer = false
+	g.detectNewcomer(testCtx)
+	assert.False(t, g.newcomer)
+}
@@ -0,0 +1,67 @@
+package processors
+
+import (
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analyze/issueaging"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/orgfeed"
+	"github.com/golangci/golangci-worker/app/lib/github"
+)
+
+// issueDelta is a change of issues since the previous analysis of the pull request
+type issueDelta struct {
+	new, fixed int
+}
+
+func newIssueDelta(next *issueaging.Snapshot, resolved []issueaging.Resolved, analyzedAt time.Time) *issueDelta {
+	d := &issueDelta{fixed: len(resolved)}
+	for _, r := range next.Issues {
+		if r.FirstSeen.Equal(analyzedAt) {
+			d.new++
+		}
+	}
+
+	return d
+}
+
+// publishFeedEvent publishes the finished analysis to the feed of the organization, dry runs aren't published
+func (g *githubGoPR) publishFeedEvent(res *result.Result, status github.Status) {
+	if g.dryRun != nil || g.pr == nil {
+		return
+	}
+
+	e := orgfeed.Event{
+		Repo:            g.context.Repo.FullName(),
+		PullRequest:     g.pr.GetNumber(),
+		Kind:            orgfeed.KindPR,
+		AnalysisGUID:    g.analysisGUID,
+		Status:          string(status),
+		DurationSeconds: int(time.Since(g.startedAt) / time.Second),
+		FinishedAt:      time.Now(),
+	}
+	if res != nil {
+		e.IssuesCount = countBlockingIssues(res.Issues)
+	}
+	if g.issueDelta != nil {
+		e.NewIssues, e.FixedIssues = g.issueDelta.new, g.issueDelta.fixed
+	}
+
+	g.feed.Publish(g.context.Repo.Owner, e)
+}
+
+func (r Repo) publishFeedEvent(ctx *RepoContext, res *repoResult, status string, duration time.Duration) {
+	e := orgfeed.Event{
+		Repo:            ctx.Repo.FullName(),
+		Kind:            orgfeed.KindRepo,
+		AnalysisGUID:    ctx.AnalysisGUID,
+		Status:          status,
+		DurationSeconds: int(duration / time.Second),
+		FinishedAt:      time.Now(),
+	}
+	if res.lintRes != nil {
+		e.IssuesCount = countBlockingIssues(res.lintRes.Issues)
+	}
+
+	r.Feed.Publish(ctx.Repo.Owner, e)
+}
@@ -0,0 +1,63 @@
+package processors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/issueaging"
+	"github.com/golangci/golangci-worker/app/analyze/orgfeed"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingPublisher struct {
+	owners []string
+	events []orgfeed.Event
+}
+
+func (p *recordingPublisher) Publish(owner string, e orgfeed.Event) {
+	p.owners = append(p.owners, owner)
+	p.events = append(p.events, e)
+}
+
+func TestFeedEventIsPublished(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := &github.FakeContext
+	prev := &issueaging.Snapshot{CommitSHA: "prevSHA", Issues: []issueaging.Record{
+		{Fingerprint: "fixed", Linter: "golint", FirstSeen: time.Now().Add(-time.Hour), Analyses: 1},
+	}}
+	storage := issueaging.NewMockStorage(ctrl)
+	storage.EXPECT().GetLast(any, c.Repo.Owner, c.Repo.Name, testPR.GetNumber()).Return(prev, nil)
+	storage.EXPECT().Put(any, c.Repo.Owner, c.Repo.Name, testPR.GetNumber(), any).Return(nil)
+
+	feed := &recordingPublisher{}
+	testProcessor(t, ctrl, githubGoPRConfig{
+		linters:    getFakeLinters(ctrl, fakeChangedIssue),
+		client:     getFakeStatusGithubClient(t, ctrl, github.StatusFailure, "1 issue found"),
+		issueAging: storage,
+		feed:       feed,
+	})
+
+	assert.Equal(t, []string{c.Repo.Owner}, feed.owners)
+	e := feed.events[0]
+	assert.Equal(t, c.Repo.FullName(), e.Repo)
+	assert.Equal(t, testPR.GetNumber(), e.PullRequest)
+	assert.Equal(t, orgfeed.KindPR, e.Kind)
+	assert.Equal(t, testAnalysisGUID, e.AnalysisGUID)
+	assert.Equal(t, string(github.StatusFailure), e.Status)
+	assert.Equal(t, 1, e.IssuesCount)
+	assert.Equal(t, 1, e.NewIssues)
+	assert.Equal(t, 1, e.FixedIssues)
+	assert.False(t, e.FinishedAt.IsZero())
+}
+
+func TestNewIssueDeltaOfReanalysis(t *testing.T) {
+	t0 := time.Now()
+	prev := &issueaging.Snapshot{CommitSHA: "sha", Issues: []issueaging.Record{{Fingerprint: "a", FirstSeen: t0}}}
+
+	next, resolved := issueaging.Track(prev, nil, "sha", t0.Add(time.Minute))
+	assert.Equal(t, &issueDelta{}, newIssueDelta(next, resolved, t0.Add(time.Minute)), "the same commit changes nothing")
+}
@@ -0,0 +1,69 @@
+package processors
+
+import (
+	"context"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/diffanchor"
+	"github.com/golangci/golangci-worker/app/lib/github"
+)
+
+// getPatchFiles returns paths of files changed (including deleted) in the unified diff: both paths
+// of renamed files are returned. Patches are validated after fetching, a broken patch has no files.
+func getPatchFiles(patch string) []string {
+	p, err := diffanchor.Parse(patch)
+	if err != nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var ret []string
+	for _, f := range p.Files {
+		for _, path := range []string{f.OldPath, f.Path} {
+			if path != "" && !seen[path] {
+				seen[path] = true
+				ret = append(ret, path)
+			}
+		}
+	}
+
+	return ret
+}
+
+type fetchedPatch struct {
+	patch   string
+	rebuilt *github.RebuiltPatch // set instead of patch if the diff was too large
+	err     error
+
+	startedAt  time.Time
+	finishedAt time.Time
+}
+
+type asyncPatch struct {
+	done chan struct{}
+	res  fetchedPatch
+}
+
+// wait can be called many times
+func (p *asyncPatch) wait() *fetchedPatch {
+	<-p.done
+	return &p.res
+}
+
+func (g githubGoPR) fetchPatchAsync(ctx context.Context) *asyncPatch {
+	p := &asyncPatch{done: make(chan struct{})}
+	go func() {
+		defer close(p.done)
+
+		p.res.startedAt = time.Now()
+		p.res.patch, p.res.err = g.client.GetPullRequestPatch(ctx, g.context)
+		if p.res.err == github.ErrDiffTooLarge {
+			analytics.Log(ctx).Infof("Diff of pull request is too large, rebuilding it from changed files")
+			p.res.rebuilt, p.res.err = g.client.RebuildPullRequestPatch(ctx, g.context)
+		}
+		p.res.finishedAt = time.Now()
+	}()
+
+	return p
+}
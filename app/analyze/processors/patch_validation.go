@@ -0,0 +1,84 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golangci/golangci-worker/app/lib/diffanchor"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+)
+
+const (
+	mb = 1024 * 1024
+
+	defaultMaxPatchSizeMB = 4
+)
+
+// patchValidationError is a failed validation of the fetched patch: the analysis isn't started
+type patchValidationError struct {
+	err error
+}
+
+func (e patchValidationError) Error() string {
+	return e.err.Error()
+}
+
+func formatSize(size int) string {
+	if size >= mb {
+		return fmt.Sprintf("%dMB", (size+mb/2)/mb)
+	}
+
+	return fmt.Sprintf("%dKB", (size+1023)/1024)
+}
+
+// validatePatch runs before the analysis: golangci-lint fails obscurely on bad patches
+func validatePatch(patch string, maxSize int) error {
+	if maxSize > 0 && len(patch) > maxSize {
+		return errorutils.ResourceLimit(fmt.Errorf("patch size is %d bytes", len(patch)),
+			fmt.Sprintf("patch too large: %s, limit %s", formatSize(len(patch)), formatSize(maxSize)))
+	}
+
+	if _, err := diffanchor.Parse(patch); err != nil {
+		return errorutils.Permanent(err, fmt.Sprintf("can't parse patch of the pull request: %s", err))
+	}
+
+	return nil
+}
+
+// checkPatchApplies checks the patch was made for the checked-out commit: the head could have been
+// force-pushed after fetching of the patch
+func (g *githubGoPR) checkPatchApplies(ctx context.Context) error {
+	args := append([]string{"apply", "--check", "--reverse"}, binaryFilesExcludes(g.patch)...)
+	out, err := g.exec.Run(ctx, "git", append(args, patchPath)...)
+	if err != nil {
+		return errorutils.Transient(fmt.Errorf("git apply failed: %s, %s", err, out),
+			fmt.Sprintf("patch doesn't apply to the commit %s: the pull request was probably updated during the analysis",
+				g.pr.GetHead().GetSHA()))
+	}
+
+	return nil
+}
+
+// binaryFilesExcludes excludes binary files from git apply: GitHub diffs don't have full index
+// lines of them and git can't apply them
+func binaryFilesExcludes(patch string) []string {
+	p, err := diffanchor.Parse(patch)
+	if err != nil {
+		return nil // the patch was validated
+	}
+
+	var ret []string
+	for _, f := range p.Files {
+		if !f.Binary {
+			continue
+		}
+		if f.OldPath != "" && f.OldPath != f.Path {
+			ret = append(ret, "--exclude="+f.OldPath)
+		}
+		if f.Path != "" {
+			ret = append(ret, "--exclude="+f.Path)
+		}
+	}
+
+	return ret
+}
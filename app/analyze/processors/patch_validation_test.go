@@ -0,0 +1,97 @@
+package processors
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/fetchers"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePatch(t *testing.T) {
+	assert.NoError(t, validatePatch(getFakePatch(t), 0))
+	assert.NoError(t, validatePatch("", 0))
+	assert.NoError(t, validatePatch("--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-a\n+b\n\\ No newline at end of file\n", 0))
+
+	testCases := []struct {
+		patch string
+		err   string
+	}{
+		{"--- a/a.go\n+++ b/a.go\n@@ -1,2 +1,2 @@\n-a\n+b\n", "patch is truncated"},
+		{"--- a/a.go\n+++ b/a.go\n@@ -1,1 +1,1 @@\n a\n b\n", `line 5 of the patch: line " b" is out of the hunk`},
+		{"--- a/a.go\n+++ b/a.go\n@@ -1,2 +1,2 @@\n a\n?b\n", `line 5 of the patch: invalid line "?b" of the hunk`},
+		{"--- a/a.go\n+++ b/a.go\n@@ -x +1 @@\n", `line 3 of the patch: invalid hunk header "@@ -x +1 @@"`},
+		{"<html>rate limit</html>\n@@ -1 +1 @@\n-a\n+b\n", `line 2 of the patch: hunk "@@ -1 +1 @@" without a file header`},
+	}
+	for _, tc := range testCases {
+		err := validatePatch(tc.patch, 0)
+		assert.Equal(t, "can't parse patch of the pull request: "+tc.err, errorutils.PublicDesc(err), tc.patch)
+	}
+}
+
+func TestBinaryFilesExcludes(t *testing.T) {
+	patch := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-a\n+b\n" +
+		"diff --git a/logo.png b/logo.png\nindex 1111111..2222222 100644\nBinary files a/logo.png and b/logo.png differ\n" +
+		"diff --git a/new.bin b/new.bin\nnew file mode 100644\nindex 0000000..2222222\nBinary files /dev/null and b/new.bin differ\n"
+	assert.Equal(t, []string{"--exclude=logo.png", "--exclude=new.bin"}, binaryFilesExcludes(patch))
+	assert.Empty(t, binaryFilesExcludes(getFakePatch(t)))
+}
+
+func TestValidatePatchSize(t *testing.T) {
+	err := validatePatch(strings.Repeat("a", 12*mb), 4*mb)
+	assert.Equal(t, "patch too large: 12MB, limit 4MB", errorutils.PublicDesc(err))
+	assert.Equal(t, errorutils.KindResourceLimit, errorutils.KindOf(err))
+
+	assert.NoError(t, validatePatch(getFakePatch(t), 4*mb))
+}
+
+func TestTooLargePatchIsRebuilt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	c := &github.FakeContext
+	rebuilt := &github.RebuiltPatch{Patch: getFakePatch(t), Capped: true}
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequestPatch(ctx, c).Return("", github.ErrDiffTooLarge)
+	gc.EXPECT().RebuildPullRequestPatch(ctx, c).Return(rebuilt, nil)
+
+	g := githubGoPR{context: c, githubGoPRConfig: githubGoPRConfig{client: gc}}
+	fp := g.fetchPatchAsync(ctx).wait()
+	assert.NoError(t, fp.err)
+	assert.Empty(t, fp.patch)
+	assert.Equal(t, rebuilt, fp.rebuilt)
+}
+
+func TestInvalidPatchSetsStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequest(testCtxMatcher, c).Return(testPR, nil)
+	gc.EXPECT().GetTokenScopes(testCtxMatcher, c).AnyTimes().Return(&github.TokenScopes{}, nil)
+	gc.EXPECT().GetPullRequestPatch(any, c).Return(getFakePatch(t)+"@@ -1,2 +1,2 @@\n", nil)
+	gc.EXPECT().SetCommitStatus(testCtxMatcher, c, testSHA, github.StatusError,
+		"can't parse patch of the pull request: patch is truncated", any)
+
+	exec := executors.NewMockExecutor(ctrl) // the patch isn't stored
+	exec.EXPECT().WorkDir().Return("").AnyTimes()
+	exec.EXPECT().WithWorkDir(any).Return(exec).AnyTimes()
+	exec.EXPECT().Run(testCtxMatcher, any, any).Return("", nil).AnyTimes()
+	exec.EXPECT().SetEnv(any, any).AnyTimes()
+	exec.EXPECT().Clean().AnyTimes()
+
+	testProcessor(t, ctrl, githubGoPRConfig{
+		linters:     []linters.Linter{linters.NewMockLinter(ctrl)},
+		repoFetcher: fetchers.NewMockFetcher(ctrl),
+		exec:        exec,
+		client:      gc,
+	})
+}
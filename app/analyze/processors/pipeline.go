@@ -0,0 +1,127 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/checkpoint"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+)
+
+// errStopPipeline is returned by a stage to stop the pipeline when the result
+// was already saved, e.g. analysis was skipped
+var errStopPipeline = fmt.Errorf("stop pipeline")
+
+type stageFunc func(ctx context.Context) error
+
+// stageMiddleware wraps every stage of a pipeline, e.g. to add retries, caching or progress reporting
+type stageMiddleware func(stageName string, next stageFunc) stageFunc
+
+type stage struct {
+	name string
+	run  stageFunc
+}
+
+// pipeline runs stages one by one until the first error or errStopPipeline
+type pipeline struct {
+	stages      []stage
+	middlewares []stageMiddleware
+}
+
+func newPipeline(stages ...stage) *pipeline {
+	return &pipeline{stages: stages}
+}
+
+// use adds middlewares: the first one is the outermost
+func (p *pipeline) use(middlewares ...stageMiddleware) *pipeline {
+	p.middlewares = append(p.middlewares, middlewares...)
+	return p
+}
+
+func (p pipeline) run(ctx context.Context) error {
+	for _, s := range p.stages {
+		f := s.run
+		for i := len(p.middlewares) - 1; i >= 0; i-- {
+			f = p.middlewares[i](s.name, f)
+		}
+
+		if err := f(ctx); err != nil {
+			return err // don't wrap error, need to save it's type
+		}
+	}
+
+	return nil
+}
+
+// recoverStage converts panics of stages into internal errors
+func recoverStage(stageName string, next stageFunc) stageFunc {
+	return func(ctx context.Context) (err error) {
+		defer func() {
+			if rerr := recover(); rerr != nil {
+				err = &errorutils.InternalError{
+					PublicDesc:  "golangci-worker panic-ed",
+					PrivateDesc: fmt.Sprintf("panic occured in stage %q: %s, %s", stageName, rerr, debug.Stack()),
+				}
+			}
+		}()
+
+		return next(ctx)
+	}
+}
+
+// retryFlaky reruns the stages once if they failed with a flaky error:
+// the already prepared workspace is reused, the retry is marked in timings
+func (g *githubGoPR) retryFlaky(stageNames ...string) stageMiddleware {
+	retried := map[string]bool{}
+	for _, name := range stageNames {
+		retried[name] = true
+	}
+
+	return func(stageName string, next stageFunc) stageFunc {
+		if !retried[stageName] {
+			return next
+		}
+
+		return func(ctx context.Context) error {
+			startedAt := time.Now()
+			err := next(ctx)
+			reason, ok := errorutils.FlakyReason(err)
+			if !ok || ctx.Err() != nil {
+				return err
+			}
+
+			analytics.Log(ctx).Warnf("Stage %q failed with flaky error (%s), retry it: %s", stageName, reason, err)
+			analytics.SaveEventProp(ctx, analytics.EventPRChecked, "flakyRetry", reason)
+			g.addStep(fmt.Sprintf("Retry %s", stageName), startedAt, time.Now(),
+				fmt.Sprintf("retried after %s failure", reason), nil)
+			return next(ctx)
+		}
+	}
+}
+
+func logStage(stageName string, next stageFunc) stageFunc {
+	return func(ctx context.Context) error {
+		startedAt := time.Now()
+		err := next(ctx)
+		if err != nil && err != errStopPipeline {
+			analytics.Log(ctx).Infof("Stage %q failed in %s: %s", stageName, time.Since(startedAt), err)
+		} else {
+			analytics.Log(ctx).Infof("Stage %q finished in %s", stageName, time.Since(startedAt))
+		}
+		return err
+	}
+}
+
+// checkpointStage records the started stage in the checkpoint of the analysis: the recovery after a crash
+// of the worker logs where the analysis was interrupted
+func (g *githubGoPR) checkpointStage(stageName string, next stageFunc) stageFunc {
+	return func(ctx context.Context) error {
+		if t := checkpoint.FromContext(ctx); t != nil {
+			t.Reached(ctx, stageName, g.exec.WorkDir())
+		}
+		return next(ctx)
+	}
+}
@@ -0,0 +1,100 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineMiddlewaresOrder(t *testing.T) {
+	var calls []string
+	trace := func(prefix string) stageMiddleware {
+		return func(stageName string, next stageFunc) stageFunc {
+			return func(ctx context.Context) error {
+				calls = append(calls, prefix+" "+stageName)
+				return next(ctx)
+			}
+		}
+	}
+	runStage := func(name string) stage {
+		return stage{name: name, run: func(context.Context) error {
+			calls = append(calls, "run "+name)
+			return nil
+		}}
+	}
+
+	err := newPipeline(runStage("a"), runStage("b")).use(trace("outer"), trace("inner")).run(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"outer a", "inner a", "run a", "outer b", "inner b", "run b"}, calls)
+}
+
+func TestPipelineStopsOnError(t *testing.T) {
+	for _, stopErr := range []error{errStopPipeline, fmt.Errorf("failed")} {
+		secondRan := false
+		err := newPipeline(
+			stage{name: "first", run: func(context.Context) error { return stopErr }},
+			stage{name: "second", run: func(context.Context) error { secondRan = true; return nil }},
+		).run(context.Background())
+		assert.Equal(t, stopErr, err)
+		assert.False(t, secondRan)
+	}
+}
+
+func TestRecoverStage(t *testing.T) {
+	err := newPipeline(stage{name: "lint", run: func(context.Context) error {
+		panic("boom")
+	}}).use(recoverStage).run(context.Background())
+
+	ierr, ok := err.(*errorutils.InternalError)
+	assert.True(t, ok)
+	assert.Equal(t, "golangci-worker panic-ed", ierr.PublicDesc)
+	assert.Contains(t, ierr.PrivateDesc, `stage "lint": boom`)
+}
+
+func TestRetryFlaky(t *testing.T) {
+	ctx := analytics.ContextWithEventPropsCollector(context.Background(), analytics.EventPRChecked)
+	g := &githubGoPR{}
+
+	lintRuns, reportRuns := 0, 0
+	flakyErr := &errorutils.InternalError{PrivateDesc: "can't run golangci-lint: signal: killed"}
+	err := newPipeline(
+		stage{name: "lint", run: func(context.Context) error {
+			lintRuns++
+			if lintRuns == 1 {
+				return flakyErr
+			}
+			return nil
+		}},
+		stage{name: "report", run: func(context.Context) error {
+			reportRuns++
+			return flakyErr // not retried stage
+		}},
+	).use(g.retryFlaky("lint")).run(ctx)
+
+	assert.Equal(t, flakyErr, err)
+	assert.Equal(t, 2, lintRuns)
+	assert.Equal(t, 1, reportRuns)
+	if assert.Len(t, g.timeline, 1) {
+		assert.Equal(t, "Retry lint", g.timeline[0].Name)
+		assert.Equal(t, "retried after oom failure", g.timeline[0].Output)
+	}
+}
+
+func TestRetryFlakyOnlyOnce(t *testing.T) {
+	ctx := analytics.ContextWithEventPropsCollector(context.Background(), analytics.EventPRChecked)
+	g := &githubGoPR{}
+
+	runs := 0
+	flakyErr := fmt.Errorf("lookup proxy.golang.org: no such host")
+	err := newPipeline(stage{name: "lint", run: func(context.Context) error {
+		runs++
+		return flakyErr
+	}}).use(g.retryFlaky("lint")).run(ctx)
+
+	assert.Equal(t, flakyErr, err)
+	assert.Equal(t, 2, runs)
+}
@@ -0,0 +1,72 @@
+package processors
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+)
+
+const (
+	// labelSkip skips analysis of PR, e.g. for vendored-only or generated-only PRs
+	labelSkip = "golangci:skip"
+
+	// labelStrict fails analysis if it was incomplete: e.g. dependencies weren't fetched
+	labelStrict = "golangci:strict"
+
+	// labelFullRepo reports issues in the whole repo, not only in the changed code
+	labelFullRepo = "golangci:full-repo"
+)
+
+type prLabelOptions struct {
+	skip     bool
+	strict   bool
+	fullRepo bool
+}
+
+func parsePRLabels(labels []string) prLabelOptions {
+	var ret prLabelOptions
+	for _, l := range labels {
+		switch l {
+		case labelSkip:
+			ret.skip = true
+		case labelStrict:
+			ret.strict = true
+		case labelFullRepo:
+			ret.fullRepo = true
+		}
+	}
+
+	return ret
+}
+
+// labelOptions parses labels of the fetched pull request: they are a part of it, no API call is needed
+func (g *githubGoPR) labelOptions(ctx context.Context) prLabelOptions {
+	var labels []string
+	for _, l := range g.pr.Labels {
+		labels = append(labels, l.GetName())
+	}
+
+	opts := parsePRLabels(labels)
+	analytics.SaveEventProps(ctx, analytics.EventPRChecked, map[string]interface{}{
+		"skipLabel":     opts.skip,
+		"strictLabel":   opts.strict,
+		"fullRepoLabel": opts.fullRepo,
+	})
+	return opts
+}
+
+// withoutPatch makes linters report issues in the whole repo
+func withoutPatch(lintersList []linters.Linter) []linters.Linter {
+	ret := make([]linters.Linter, 0, len(lintersList))
+	for _, l := range lintersList {
+		if gl, ok := l.(golinters.GolangciLint); ok {
+			gl.PatchPath = ""
+			l = gl
+		}
+		ret = append(ret, l)
+	}
+
+	return ret
+}
@@ -0,0 +1,59 @@
+package processors
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/prstate"
+)
+
+const defaultPreviewInterval = 15 * time.Second
+
+// preview collects partial results of linters and publishes issues found so far at most once per interval:
+// the web UI renders them before long analyses are finished
+type preview struct {
+	interval time.Duration
+	publish  func(issues []result.Issue)
+
+	mu          sync.Mutex
+	issues      []result.Issue
+	publishedAt time.Time
+}
+
+func (p *preview) add(issues []result.Issue) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.issues = append(p.issues, issues...)
+	if time.Since(p.publishedAt) < p.interval {
+		return // the final result is saved anyway
+	}
+
+	p.publishedAt = time.Now()
+	p.publish(append([]result.Issue{}, p.issues...))
+}
+
+// addIssue adds an issue streamed by linters, it's a linters.IssueFunc
+func (p *preview) addIssue(issue result.Issue) error {
+	p.add([]result.Issue{issue})
+	return nil
+}
+
+// publishPreview saves issues found so far into the state of the processing analysis
+func (g *githubGoPR) publishPreview(ctx context.Context, issues []result.Issue) {
+	// the final result is scoped by scopeIssues
+	issues = withoutOutsidePath(g.path, withoutSkippedFiles(g.repoCfg, issues))
+	s := &prstate.State{
+		Status: statusProcessing,
+		ResultJSON: &resultJSON{
+			Version:   1,
+			WorkerRes: workerRes{PreviewIssues: issues},
+		},
+	}
+	if err := g.state.UpdateState(ctx, g.context.Repo.Owner, g.context.Repo.Name, g.analysisGUID, s); err != nil {
+		analytics.Log(ctx).Warnf("Can't save preview of analysis %s with %d issues: %s", g.analysisGUID, len(issues), err)
+	}
+}
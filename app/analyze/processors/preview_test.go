@@ -0,0 +1,48 @@
+package processors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/prstate"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviewThrottling(t *testing.T) {
+	var published [][]result.Issue
+	p := &preview{interval: time.Hour, publish: func(issues []result.Issue) {
+		published = append(published, issues)
+	}}
+
+	p.add([]result.Issue{{Text: "a"}})
+	p.add([]result.Issue{{Text: "b"}})
+	assert.Equal(t, [][]result.Issue{{{Text: "a"}}}, published, "second partial result is throttled")
+
+	p.publishedAt = time.Now().Add(-2 * time.Hour)
+	p.add([]result.Issue{{Text: "c"}})
+	assert.Equal(t, [][]result.Issue{{{Text: "a"}}, {{Text: "a"}, {Text: "b"}, {Text: "c"}}}, published,
+		"all issues found so far are published")
+}
+
+func TestPublishPreview(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	issues := []result.Issue{{File: "a.go", LineNumber: 1, Text: "issue", FromLinter: "govet"}}
+	state := prstate.NewMockStorage(ctrl)
+	state.EXPECT().UpdateState(ctx, github.FakeContext.Repo.Owner, github.FakeContext.Repo.Name, "guid", &prstate.State{
+		Status: statusProcessing,
+		ResultJSON: &resultJSON{
+			Version:   1,
+			WorkerRes: workerRes{PreviewIssues: issues},
+		},
+	}).Return(nil)
+
+	g := &githubGoPR{context: &github.FakeContext, analysisGUID: "guid", githubGoPRConfig: githubGoPRConfig{state: state}}
+	g.publishPreview(ctx, issues)
+}
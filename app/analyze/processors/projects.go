@@ -0,0 +1,172 @@
+package processors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/golangci/golangci-lint/pkg/printers"
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+)
+
+type projectResult struct {
+	project repoconfig.Project
+	res     *result.Result
+	err     error
+}
+
+// projectDir returns the dir of the project relative to the repo root, it's empty for the repo root
+func projectDir(p repoconfig.Project) (string, error) {
+	dir, err := cleanAnalysisPath(p.Dir)
+	if err != nil {
+		return "", &errorutils.BadInputError{
+			PublicDesc: fmt.Sprintf("invalid dir %q of project %s: it must be a subdirectory of the repo", p.Dir, p.Name),
+		}
+	}
+
+	return dir, nil
+}
+
+// projectPackages returns packages of the project by its dir returned by projectDir
+func projectPackages(dir string) []string {
+	if dir == "" {
+		return []string{"./..."}
+	}
+
+	return []string{fmt.Sprintf("./%s/...", dir)}
+}
+
+func withPackages(lintersList []linters.Linter, packages []string) []linters.Linter {
+	ret := make([]linters.Linter, 0, len(lintersList))
+	for _, l := range lintersList {
+		if gl, ok := l.(golinters.GolangciLint); ok {
+			gl.Packages = packages
+			l = gl
+		}
+		ret = append(ret, l)
+	}
+
+	return ret
+}
+
+func (g *githubGoPR) setProjectStatus(ctx context.Context, p repoconfig.Project, status github.Status, desc string) {
+	c := *g.context
+	c.StatusContext = fmt.Sprintf("golangci/%s", p.Name)
+	err := g.client.SetCommitStatus(ctx, &c, g.pr.GetHead().GetSHA(), status, truncateStatusDesc(desc), g.statusURL(status))
+	if err != nil {
+		g.publicWarn("github", g.msg.Sprintf(i18n.WarnProjectStatus, p.Name))
+		analytics.Log(ctx).Warnf("Can't set github commit status of project %s: %s", p.Name, err)
+	}
+}
+
+// lintProjects analyzes projects touched by the PR in parallel in the same workspace:
+// every project gets its own commit status, issues are merged into one result
+func (g *githubGoPR) lintProjects(ctx context.Context, onIssue linters.IssueFunc) (*result.Result, error) {
+	// dirs are validated before any status is set: packages of the repo config mustn't point out of the repo
+	dirs := map[string]string{}
+	for _, p := range g.repoCfg.Projects {
+		dir, err := projectDir(p)
+		if err != nil {
+			return nil, err
+		}
+		dirs[p.Name] = dir
+	}
+
+	files := getPatchFiles(g.patch)
+	var touched []repoconfig.Project
+	for _, p := range g.repoCfg.Projects {
+		if p.IsTouched(files) {
+			touched = append(touched, p)
+			g.setProjectStatus(ctx, p, github.StatusPending, g.msg.Sprintf(i18n.StatusReviewing))
+		} else {
+			// required checks of untouched projects mustn't block merging
+			g.setProjectStatus(ctx, p, github.StatusSuccess, g.msg.Sprintf(i18n.StatusNoProjectChanges))
+		}
+	}
+	analytics.Log(ctx).Infof("Touched projects: %v", touched)
+
+	lintersList := withConcurrency(g.linters, concurrencyPerRun(g.cpus, len(touched)))
+	results := make([]projectResult, len(touched))
+	var wg sync.WaitGroup
+	for i, p := range touched {
+		wg.Add(1)
+		go func(i int, p repoconfig.Project) {
+			defer wg.Done()
+
+			select {
+			case projectSlots <- struct{}{}:
+				defer func() { <-projectSlots }()
+			case <-ctx.Done():
+				results[i] = projectResult{project: p, err: ctx.Err()}
+				return
+			}
+
+			res, err := g.runner.RunStreaming(ctx, withPackages(lintersList, projectPackages(dirs[p.Name])), g.exec, onIssue)
+			results[i] = projectResult{project: p, res: res, err: err}
+		}(i, p)
+	}
+	wg.Wait()
+
+	var firstErr error
+	for _, pr := range results {
+		if pr.err != nil {
+			g.setProjectStatus(ctx, pr.project, github.StatusError, escapeErrorText(publicErrorText(pr.err), g.buildSecrets()))
+			if firstErr == nil {
+				firstErr = pr.err
+			}
+			continue
+		}
+
+		// issues of skipped files are dropped from the merged result
+		status, desc := g.statusForIssues(withoutSkippedFiles(g.repoCfg, pr.res.Issues))
+		g.setProjectStatus(ctx, pr.project, status, desc)
+	}
+	if firstErr != nil {
+		return nil, firstErr // don't wrap error, need to save it's type
+	}
+
+	return mergeProjectResults(ctx, results), nil
+}
+
+// mergeProjectResults merges results keeping the format of golangci-lint json
+func mergeProjectResults(ctx context.Context, results []projectResult) *result.Result {
+	ret := &result.Result{}
+	var mergedJSON *printers.JSONResult
+	for _, pr := range results {
+		ret.Issues = append(ret.Issues, pr.res.Issues...)
+		ret.Capped = ret.Capped || pr.res.Capped
+		for _, l := range pr.res.TimedOutLinters {
+			ret.TimedOutLinters = append(ret.TimedOutLinters, fmt.Sprintf("%s of project %s", l, pr.project.Name))
+		}
+
+		rawJSON, ok := pr.res.ResultJSON.(json.RawMessage)
+		if !ok {
+			continue
+		}
+
+		var res printers.JSONResult
+		if err := json.Unmarshal(rawJSON, &res); err != nil {
+			analytics.Log(ctx).Warnf("Can't parse golangci-lint json of project %s: %s", pr.project.Name, err)
+			continue
+		}
+
+		if mergedJSON == nil {
+			mergedJSON = &res
+		} else {
+			mergedJSON.Issues = append(mergedJSON.Issues, res.Issues...)
+		}
+	}
+
+	if mergedJSON != nil {
+		ret.ResultJSON = mergedJSON
+	}
+	return ret
+}
@@ -0,0 +1,134 @@
+package processors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-lint/pkg/printers"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	gh "github.com/google/go-github/github"
+	"github.com/stretchr/testify/assert"
+)
+
+// packagesRunner returns issues by packages of golangci-lint: it's called concurrently
+type packagesRunner struct {
+	issues map[string][]result.Issue
+	errs   map[string]error
+}
+
+func (r packagesRunner) Run(ctx context.Context, lintersList []linters.Linter, exec executors.Executor) (*result.Result, error) {
+	pkg := lintersList[0].(golinters.GolangciLint).Packages[0]
+	if err := r.errs[pkg]; err != nil {
+		return nil, err
+	}
+
+	rawJSON, err := json.Marshal(printers.JSONResult{})
+	if err != nil {
+		return nil, err
+	}
+	return &result.Result{Issues: r.issues[pkg], ResultJSON: json.RawMessage(rawJSON)}, nil
+}
+
+func (r packagesRunner) RunStreaming(ctx context.Context, lintersList []linters.Linter, exec executors.Executor,
+	_ linters.IssueFunc) (*result.Result, error) {
+	return r.Run(ctx, lintersList, exec)
+}
+
+func newTestProjectsPR(ctrl *gomock.Controller, runner linters.Runner) (*githubGoPR, *github.MockClient) {
+	client := github.NewMockClient(ctrl)
+	g := &githubGoPR{
+		context: &github.FakeContext,
+		pr:      &gh.PullRequest{Head: &gh.PullRequestBranch{SHA: gh.String("sha")}},
+		repoCfg: &repoconfig.Config{
+			Projects: []repoconfig.Project{
+				{Name: "api", Dir: "api"},
+				{Name: "web", Dir: "web"},
+				{Name: "docs", Dir: "docs"},
+			},
+		},
+		patch: "--- a/api/main.go\n+++ b/api/main.go\n--- a/web/main.go\n+++ b/web/main.go\n",
+		githubGoPRConfig: githubGoPRConfig{
+			client:  client,
+			runner:  runner,
+			linters: []linters.Linter{golinters.GolangciLint{}},
+		},
+	}
+	return g, client
+}
+
+func expectProjectStatus(client *github.MockClient, project string, status github.Status) {
+	c := github.FakeContext
+	c.StatusContext = "golangci/" + project
+	client.EXPECT().SetCommitStatus(any, &c, "sha", status, any, any).Return(nil)
+}
+
+func TestLintProjects(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	g, client := newTestProjectsPR(ctrl, packagesRunner{
+		issues: map[string][]result.Issue{
+			"./api/...": {{File: "api/main.go", Text: "issue"}},
+		},
+	})
+
+	expectProjectStatus(client, "docs", github.StatusSuccess) // not touched
+	expectProjectStatus(client, "api", github.StatusPending)
+	expectProjectStatus(client, "web", github.StatusPending)
+	expectProjectStatus(client, "api", github.StatusFailure)
+	expectProjectStatus(client, "web", github.StatusSuccess)
+
+	res, err := g.lintProjects(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []result.Issue{{File: "api/main.go", Text: "issue"}}, res.Issues)
+	assert.IsType(t, &printers.JSONResult{}, res.ResultJSON)
+}
+
+func TestLintProjectsInvalidDir(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	g, _ := newTestProjectsPR(ctrl, packagesRunner{}) // no statuses must be set
+	g.repoCfg.Projects[1].Dir = "../other"
+
+	_, err := g.lintProjects(context.Background(), nil)
+	assert.IsType(t, &errorutils.BadInputError{}, err)
+}
+
+func TestLintProjectsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	runErr := fmt.Errorf("can't run")
+	g, client := newTestProjectsPR(ctrl, packagesRunner{
+		errs: map[string]error{"./web/...": runErr},
+	})
+
+	expectProjectStatus(client, "docs", github.StatusSuccess)
+	expectProjectStatus(client, "api", github.StatusPending)
+	expectProjectStatus(client, "web", github.StatusPending)
+	expectProjectStatus(client, "api", github.StatusSuccess) // independent of other projects
+	expectProjectStatus(client, "web", github.StatusError)
+
+	_, err := g.lintProjects(context.Background(), nil)
+	assert.Equal(t, runErr, err)
+}
+
+func TestTruncateStatusDesc(t *testing.T) {
+	assert.Equal(t, "short", truncateStatusDesc("short"))
+
+	desc := truncateStatusDesc(strings.Repeat("ё", maxStatusDescLen+1))
+	assert.Equal(t, maxStatusDescLen, utf8.RuneCountInString(desc))
+	assert.True(t, strings.HasSuffix(desc, "..."))
+}
@@ -0,0 +1,105 @@
+package processors
+
+import (
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+)
+
+// defaultSeverity is a severity of style issues and issues of linters not listed in defaultSeverities
+const defaultSeverity = 1
+
+// defaultSeverities of linters: bugs and security issues weigh more than style issues
+var defaultSeverities = map[string]int{
+	"typecheck":   5,
+	"gosec":       3,
+	"govet":       3,
+	"staticcheck": 3,
+	"apicompat":   3,
+	"govulncheck": 5,
+	"secretscan":  5,
+	"errcheck":    2,
+	"ineffassign": 2,
+	"unused":      2,
+}
+
+// issueSeverity looks up the rule (e.g. gosec/G104), then the linter in severities of the gate
+// and only then in default severities
+func issueSeverity(i *result.Issue, gate *repoconfig.QualityGate) int {
+	if i.Rule != "" {
+		if s, ok := gate.Severities[i.FromLinter+"/"+i.Rule]; ok {
+			return s
+		}
+	}
+	if s, ok := gate.Severities[i.FromLinter]; ok {
+		return s
+	}
+	if s, ok := defaultSeverities[i.FromLinter]; ok {
+		return s
+	}
+
+	return defaultSeverity
+}
+
+// issueSeverityFunc returns severities of issues by the gate, default severities are used if it's nil
+func issueSeverityFunc(gate *repoconfig.QualityGate) func(i *result.Issue) int {
+	if gate == nil {
+		gate = &repoconfig.QualityGate{}
+	}
+
+	return func(i *result.Issue) int {
+		return issueSeverity(i, gate)
+	}
+}
+
+// qualityScore is a sum of severities of blocking issues
+func qualityScore(issues []result.Issue, gate *repoconfig.QualityGate) int {
+	score := 0
+	for ind := range issues {
+		if !issues[ind].Informational {
+			score += issueSeverity(&issues[ind], gate)
+		}
+	}
+
+	return score
+}
+
+func issuesCountText(p i18n.Printer, n int) string {
+	return p.Plural(i18n.StatusIssuesFound, n)
+}
+
+// blockingIssuesText shows how many blocking issues are new and how many the base branch already has:
+// maintainers see whether the pull request made things worse. It's the raw count if the base branch is unknown.
+func blockingIssuesText(p i18n.Printer, issues []result.Issue, base baseline) string {
+	blocking := withoutInformational(issues)
+	if base == nil {
+		return issuesCountText(p, len(blocking))
+	}
+
+	preExisting := base.preExisting(blocking)
+	return p.Plural(i18n.StatusIssuesDelta, len(blocking)-preExisting, preExisting)
+}
+
+// getGithubStatusForIssues fails the status for any blocking issue if there is no quality gate,
+// otherwise only for scores exceeding the threshold: the status passes with the count of issues.
+// The count is split into new and pre-existing issues by the baseline if it's known.
+func getGithubStatusForIssues(issues []result.Issue, gate *repoconfig.QualityGate, p i18n.Printer,
+	base baseline) (github.Status, string) {
+
+	n := countBlockingIssues(issues)
+	if n == 0 {
+		return github.StatusSuccess, p.Sprintf(i18n.StatusNoIssues)
+	}
+	countText := blockingIssuesText(p, issues, base)
+	if gate == nil {
+		return github.StatusFailure, countText
+	}
+
+	score := qualityScore(issues, gate)
+	if score > gate.Threshold {
+		return github.StatusFailure, p.Sprintf(i18n.StatusScoreExceeds, countText, score, gate.Threshold)
+	}
+
+	return github.StatusSuccess, p.Sprintf(i18n.StatusScoreWithin, countText, score, gate.Threshold)
+}
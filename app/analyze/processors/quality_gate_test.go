@@ -0,0 +1,91 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueSeverity(t *testing.T) {
+	gate := &repoconfig.QualityGate{Severities: map[string]int{"gosec/G104": 0, "golint": 2}}
+	assert.Equal(t, 0, issueSeverity(&result.Issue{FromLinter: "gosec", Rule: "G104"}, gate))
+	assert.Equal(t, 3, issueSeverity(&result.Issue{FromLinter: "gosec", Rule: "G101"}, gate))
+	assert.Equal(t, 2, issueSeverity(&result.Issue{FromLinter: "golint"}, gate))
+	assert.Equal(t, defaultSeverity, issueSeverity(&result.Issue{FromLinter: "misspell"}, gate))
+}
+
+func TestQualityGateStatus(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "govet"},
+		{FromLinter: "golint"},
+		{FromLinter: "golint"},
+		{FromLinter: "deps-freshness", Informational: true},
+	}
+
+	status, desc := getGithubStatusForIssues(issues, nil, i18n.Printer{}, nil)
+	assert.Equal(t, github.StatusFailure, status)
+	assert.Equal(t, "3 issues found", desc)
+
+	status, desc = getGithubStatusForIssues(issues, &repoconfig.QualityGate{Threshold: 5}, i18n.Printer{}, nil)
+	assert.Equal(t, github.StatusSuccess, status)
+	assert.Equal(t, "3 issues found, score 5 is within 5", desc)
+
+	status, desc = getGithubStatusForIssues(issues, &repoconfig.QualityGate{Threshold: 4}, i18n.Printer{}, nil)
+	assert.Equal(t, github.StatusFailure, status)
+	assert.Equal(t, "3 issues found, score 5 exceeds 4", desc)
+
+	status, desc = getGithubStatusForIssues(issues[3:], &repoconfig.QualityGate{}, i18n.Printer{}, nil)
+	assert.Equal(t, github.StatusSuccess, status)
+	assert.Equal(t, "No issues found!", desc)
+}
+
+func TestLocalizedStatus(t *testing.T) {
+	issues := []result.Issue{{FromLinter: "govet"}, {FromLinter: "golint"}}
+
+	_, desc := getGithubStatusForIssues(issues, &repoconfig.QualityGate{Threshold: 1}, i18n.NewPrinter("ru"), nil)
+	assert.Equal(t, "2 проблемы найдены, оценка 4 превышает 1", desc)
+
+	_, desc = getGithubStatusForIssues(issues, nil, i18n.NewPrinter("ja"), nil)
+	assert.Equal(t, "2 件の問題が見つかりました", desc)
+}
+
+func TestStatusShowsIssuesDelta(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "govet", File: "a.go", Text: "old", LineNumber: 10},
+		{FromLinter: "golint", File: "a.go", Text: "new"},
+		{FromLinter: "golint", File: "b.go", Text: "new"},
+		{FromLinter: "deps-freshness", Informational: true},
+	}
+	// the issue of the base branch moved from the line 5 to the line 10
+	base := newBaseline([]result.Issue{{FromLinter: "govet", File: "a.go", Text: "old", LineNumber: 5}})
+
+	_, desc := getGithubStatusForIssues(issues, nil, i18n.Printer{}, base)
+	assert.Equal(t, "2 new issues, 1 pre-existing", desc)
+
+	_, desc = getGithubStatusForIssues(issues[:1], &repoconfig.QualityGate{Threshold: 1}, i18n.Printer{}, base)
+	assert.Equal(t, "0 new issues, 1 pre-existing, score 3 exceeds 1", desc)
+
+	_, desc = getGithubStatusForIssues(issues[1:2], nil, i18n.NewPrinter("ru"), base)
+	assert.Equal(t, "1 новая проблема, 0 уже были", desc)
+
+	// a copy of the existing issue is new
+	_, desc = getGithubStatusForIssues(append(issues[:1:1], issues[0]), nil, i18n.Printer{}, base)
+	assert.Equal(t, "1 new issue, 1 pre-existing", desc)
+
+	// the base branch wasn't analyzed
+	_, desc = getGithubStatusForIssues(issues, nil, i18n.Printer{}, nil)
+	assert.Equal(t, "3 issues found", desc)
+}
+
+func TestIssueSeverityFunc(t *testing.T) {
+	severity := issueSeverityFunc(nil)
+	assert.Equal(t, 3, severity(&result.Issue{FromLinter: "gosec"}))
+	assert.Equal(t, 1, severity(&result.Issue{FromLinter: "golint"}))
+
+	severity = issueSeverityFunc(&repoconfig.QualityGate{Severities: map[string]int{"golint": 4}})
+	assert.Equal(t, 4, severity(&result.Issue{FromLinter: "golint"}))
+}
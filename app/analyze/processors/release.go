@@ -0,0 +1,196 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+	"github.com/pkg/errors"
+)
+
+// releaseLinters are enabled for release analyses in addition to linters of the repo config
+var releaseLinters = []string{"govet", "errcheck", "staticcheck", "unused", "gosec", "ineffassign", "typecheck"}
+
+const (
+	releaseReportBegin = "<!-- golangci release readiness -->"
+	releaseReportEnd   = "<!-- /golangci release readiness -->"
+)
+
+// ReleaseContext is a tag analyzed for the release readiness
+type ReleaseContext struct {
+	Tag string
+
+	// Github and Client are used to post the report into the release, it isn't posted if Github is nil
+	Github *github.Context
+	Client github.Client
+}
+
+// ReleaseReport is a release readiness of the tag: it's ready if there are no blocking issues,
+// vulnerabilities are blocking issues too
+type ReleaseReport struct {
+	Tag             string
+	Ready           bool
+	BlockingIssues  int
+	IssuesByLinter  map[string]int `json:",omitempty"`
+	Vulnerabilities []result.Issue `json:",omitempty"`
+
+	// Posted is set if the report was posted into the GitHub Release of the tag
+	Posted bool `json:",omitempty"`
+}
+
+// withReleaseProfile enables the stricter profile of release analyses: more golangci-lint linters and govulncheck.
+// Failures of govulncheck are warnings: it needs a newer toolchain and modules, analyses of other projects go on.
+func withReleaseProfile(lintersList []linters.Linter) []linters.Linter {
+	ret := make([]linters.Linter, 0, len(lintersList)+1)
+	for _, l := range lintersList {
+		if gl, ok := l.(golinters.GolangciLint); ok {
+			gl.EnabledLinters = mergeLinters(gl.EnabledLinters, releaseLinters)
+			l = gl
+		}
+		ret = append(ret, l)
+	}
+
+	return append(ret, golinters.Govulncheck{Optional: true})
+}
+
+func mergeLinters(enabled, extra []string) []string {
+	ret := append([]string{}, enabled...)
+	for _, name := range extra {
+		found := false
+		for _, e := range enabled {
+			if e == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ret = append(ret, name)
+		}
+	}
+
+	return ret
+}
+
+func buildReleaseReport(tag string, issues []result.Issue) *ReleaseReport {
+	ret := &ReleaseReport{
+		Tag:            tag,
+		IssuesByLinter: map[string]int{},
+	}
+	for _, i := range withoutInformational(issues) {
+		ret.BlockingIssues++
+		ret.IssuesByLinter[i.FromLinter]++
+		if i.FromLinter == (golinters.Govulncheck{}).Name() {
+			ret.Vulnerabilities = append(ret.Vulnerabilities, i)
+		}
+	}
+	ret.Ready = ret.BlockingIssues == 0
+
+	return ret
+}
+
+func (r ReleaseReport) eventProps() map[string]interface{} {
+	return map[string]interface{}{
+		"ready":           r.Ready,
+		"blockingIssues":  r.BlockingIssues,
+		"vulnerabilities": len(r.Vulnerabilities),
+	}
+}
+
+func (r ReleaseReport) markdown() string {
+	var b strings.Builder
+	if r.Ready {
+		fmt.Fprintf(&b, "### Release readiness of %s: ready\n\nNo issues found by golangci.\n", r.Tag)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "### Release readiness of %s: not ready\n\n", r.Tag)
+
+	var names []string
+	for name := range r.IssuesByLinter {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var counts []string
+	for _, name := range names {
+		counts = append(counts, fmt.Sprintf("%s: %d", name, r.IssuesByLinter[name]))
+	}
+	fmt.Fprintf(&b, "%d blocking issues (%s).\n", r.BlockingIssues, strings.Join(counts, ", "))
+
+	if len(r.Vulnerabilities) != 0 {
+		b.WriteString("\nVulnerabilities:\n")
+		for _, v := range r.Vulnerabilities {
+			fmt.Fprintf(&b, "- %s (`%s:%d`)\n", v.Text, v.File, v.LineNumber)
+		}
+	}
+
+	return b.String()
+}
+
+// withReleaseReport puts the report into the release body: the report of the previous analysis is replaced,
+// the rest of the body is kept
+func withReleaseReport(body string, report *ReleaseReport) string {
+	section := fmt.Sprintf("%s\n%s%s", releaseReportBegin, report.markdown(), releaseReportEnd)
+
+	begin := strings.Index(body, releaseReportBegin)
+	end := strings.Index(body, releaseReportEnd)
+	if begin != -1 && end > begin {
+		return body[:begin] + section + body[end+len(releaseReportEnd):]
+	}
+
+	if strings.TrimSpace(body) == "" {
+		return section
+	}
+	return strings.TrimRight(body, "\n") + "\n\n" + section
+}
+
+func postReleaseReport(ctx context.Context, rel *ReleaseContext, report *ReleaseReport) error {
+	release, err := rel.Client.GetReleaseByTag(ctx, rel.Github, rel.Tag)
+	if err != nil {
+		return errors.Wrap(err, "can't get release")
+	}
+
+	body := withReleaseReport(release.GetBody(), report)
+	if err = rel.Client.EditReleaseBody(ctx, rel.Github, release.GetID(), body); err != nil {
+		return errors.Wrap(err, "can't update release body")
+	}
+
+	return nil
+}
+
+// AnalyzeRelease analyzes the tag by the release profile and saves the release readiness report
+// into the analysis result, the report is posted into the GitHub Release if the repo config enables it
+func (r Repo) AnalyzeRelease(ctx *RepoContext, rel *ReleaseContext) {
+	r.Linters = withReleaseProfile(r.Linters)
+	r.process(ctx, func(res *repoResult) {
+		res.release = buildReleaseReport(rel.Tag, res.lintRes.Issues)
+		analytics.SaveEventProps(ctx.Ctx, analytics.EventReleaseAnalyzed, res.release.eventProps())
+
+		if !r.RepoCfg.GetPostReleaseReport() || rel.Github == nil {
+			return
+		}
+
+		post := *rel
+		var dryRun *dryRunClient
+		if r.RepoCfg.GetDryRun() { // the edited body is only recorded into the result
+			dryRun = newDryRunClient(rel.Client)
+			post.Client = dryRun
+		}
+		if err := postReleaseReport(ctx.Ctx, &post, res.release); err != nil {
+			r.Log.Warnf("Can't post release readiness report of %s: %s", rel.Tag, err)
+			res.publicWarn("release", r.msg().Sprintf(i18n.WarnReleaseReport))
+			return
+		}
+		if dryRun != nil {
+			res.dryRun = dryRun.result()
+			return
+		}
+		res.release.Posted = true
+	})
+}
@@ -0,0 +1,70 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	gh "github.com/google/go-github/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithReleaseProfile(t *testing.T) {
+	lintersList := withReleaseProfile([]linters.Linter{golinters.GolangciLint{EnabledLinters: []string{"gosec", "misspell"}}})
+	assert.Len(t, lintersList, 2)
+	assert.Equal(t, []string{"gosec", "misspell", "govet", "errcheck", "staticcheck", "unused", "ineffassign", "typecheck"},
+		lintersList[0].(golinters.GolangciLint).EnabledLinters)
+	assert.Equal(t, golinters.Govulncheck{Optional: true}, lintersList[1])
+}
+
+func TestBuildReleaseReport(t *testing.T) {
+	vuln := result.Issue{FromLinter: "govulncheck", Rule: "GO-2023-0001", Text: "GO-2023-0001: vulnerable", File: "main.go", LineNumber: 3}
+	report := buildReleaseReport("v1.0.0", []result.Issue{
+		{FromLinter: "errcheck", Text: "unchecked"},
+		{FromLinter: "errcheck", Text: "unchecked too"},
+		{FromLinter: "depsfreshness", Text: "outdated", Informational: true},
+		vuln,
+	})
+	assert.Equal(t, &ReleaseReport{
+		Tag:             "v1.0.0",
+		BlockingIssues:  3,
+		IssuesByLinter:  map[string]int{"errcheck": 2, "govulncheck": 1},
+		Vulnerabilities: []result.Issue{vuln},
+	}, report)
+
+	assert.True(t, buildReleaseReport("v1.0.1", nil).Ready)
+}
+
+func TestWithReleaseReport(t *testing.T) {
+	report := buildReleaseReport("v1.0.0", []result.Issue{{FromLinter: "govet", Text: "shadow"}})
+	body := withReleaseReport("Changelog", report)
+	assert.Equal(t, "Changelog\n\n"+releaseReportBegin+"\n### Release readiness of v1.0.0: not ready\n\n"+
+		"1 blocking issues (govet: 1).\n"+releaseReportEnd, body)
+
+	ready := buildReleaseReport("v1.0.0", nil)
+	updated := withReleaseReport(body+"\n\nFooter", ready)
+	assert.Equal(t, "Changelog\n\n"+releaseReportBegin+"\n### Release readiness of v1.0.0: ready\n\n"+
+		"No issues found by golangci.\n"+releaseReportEnd+"\n\nFooter", updated, "the previous report is replaced")
+
+	assert.Equal(t, releaseReportBegin+"\n"+ready.markdown()+releaseReportEnd, withReleaseReport("", ready))
+}
+
+func TestPostReleaseReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	client := github.NewMockClient(ctrl)
+	rel := &ReleaseContext{Tag: "v1.0.0", Github: &github.Context{}, Client: client}
+	report := buildReleaseReport("v1.0.0", nil)
+
+	client.EXPECT().GetReleaseByTag(ctx, rel.Github, "v1.0.0").
+		Return(&gh.RepositoryRelease{ID: gh.Int(7), Body: gh.String("Changelog")}, nil)
+	client.EXPECT().EditReleaseBody(ctx, rel.Github, 7, withReleaseReport("Changelog", report)).Return(nil)
+
+	assert.NoError(t, postReleaseReport(ctx, rel, report))
+}
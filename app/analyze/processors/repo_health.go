@@ -0,0 +1,50 @@
+package processors
+
+import (
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/health"
+	"github.com/pkg/errors"
+)
+
+// SnapshotHealth analyzes the whole repo and saves its health snapshot, the analysis state isn't saved.
+// Analytics events of both repo analysis and health snapshot must be collected by ctx.
+func (r *Repo) SnapshotHealth(ctx *RepoContext, storage health.Storage) error {
+	if r.repoKillSwitch(ctx) != nil {
+		return nil // snapshots of disabled repos aren't needed
+	}
+
+	now := time.Now()
+	ctx.checkDependencies = health.IsDependenciesWeek(now)
+
+	var res repoResult
+	if err := r.prepare(ctx, &res); err != nil {
+		return errors.Wrap(err, "failed to prepare repo")
+	}
+
+	if err := r.analyze(ctx, &res); err != nil {
+		return errors.Wrap(err, "failed to analyze repo")
+	}
+
+	prev, err := storage.GetLast(ctx.Ctx, ctx.Repo.Owner, ctx.Repo.Name)
+	if err != nil {
+		r.Log.Warnf("Can't get the last health snapshot, trends aren't computed: %s", err)
+		prev = nil
+	}
+
+	loc := 0
+	if res.repoMeta != nil {
+		loc = res.repoMeta.LOC
+	}
+	snapshot := health.Compute(withoutInformational(res.lintRes.Issues), loc, now, prev)
+	snapshot.Branch = ctx.Branch
+	snapshot.Dependencies = getInfoIssues(res.lintRes.Issues)
+	analytics.SaveEventProps(ctx.Ctx, analytics.EventRepoHealthSnapshotted, snapshot.EventProps())
+
+	if err = storage.Put(ctx.Ctx, ctx.Repo.Owner, ctx.Repo.Name, snapshot); err != nil {
+		return errors.Wrap(err, "failed to save health snapshot")
+	}
+
+	return nil
+}
@@ -12,15 +12,31 @@ import (
 	"github.com/golangci/golangci-shared/pkg/apperrors"
 	"github.com/golangci/golangci-shared/pkg/config"
 	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/checkpoint"
+	"github.com/golangci/golangci-worker/app/analyze/depgraph"
+	"github.com/golangci/golangci-worker/app/analyze/killswitch"
+	"github.com/golangci/golangci-worker/app/analyze/lintconfig"
 	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
 	lintersResult "github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/orgfeed"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
+	"github.com/golangci/golangci-worker/app/analyze/repoinfo"
 	"github.com/golangci/golangci-worker/app/analyze/repostate"
+	"github.com/golangci/golangci-worker/app/analyze/sbom"
+	"github.com/golangci/golangci-worker/app/analyze/suppressions"
+	"github.com/golangci/golangci-worker/app/lib/artifacts"
+	"github.com/golangci/golangci-worker/app/lib/buildinfo"
+	"github.com/golangci/golangci-worker/app/lib/deploykeys"
 	"github.com/golangci/golangci-worker/app/lib/errorutils"
 	"github.com/golangci/golangci-worker/app/lib/executors"
 	"github.com/golangci/golangci-worker/app/lib/experiments"
 	"github.com/golangci/golangci-worker/app/lib/fetchers"
 	"github.com/golangci/golangci-worker/app/lib/github"
 	"github.com/golangci/golangci-worker/app/lib/goutils/workspaces"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+	"github.com/golangci/golangci-worker/app/lib/usage"
 
 	"github.com/pkg/errors"
 )
@@ -32,6 +48,14 @@ type StaticRepoConfig struct {
 	State       repostate.Storage
 	Cfg         config.Config
 	Et          apperrors.Tracker
+	Artifacts   *artifacts.Manager
+	CfgFetcher  repoconfig.Fetcher
+	Usage       usage.Reporter
+	InfoFetcher repoinfo.Fetcher
+
+	KillSwitches killswitch.Fetcher
+	Suppressions suppressions.Storage
+	Feed         orgfeed.Publisher
 }
 
 type RepoConfig struct {
@@ -41,6 +65,8 @@ type RepoConfig struct {
 	Exec executors.Executor
 	Wi   workspaces.Installer
 	Ec   *experiments.Checker
+
+	RepoCfg *repoconfig.Config
 }
 
 type Repo struct {
@@ -53,12 +79,34 @@ type RepoContext struct {
 	AnalysisGUID string
 	Branch       string
 	Repo         *github.Repo // TODO: abstract from repo provider
+
+	// DeployKey is a sealed SSH key to clone the private repo by, it's empty for public repos
+	DeployKey string
+
+	// Path is a subdirectory of the repo the analysis is restricted to, see github.Context
+	Path string
+
+	// WorkDirEncryption is a mode of the encrypted work dir, see github.Context
+	WorkDirEncryption string
+
+	// checkDependencies is set only for scheduled analyses of health snapshots: freshness of
+	// dependencies is checked biweekly, not on every push
+	checkDependencies bool
 }
 
 type repoResult struct {
 	resultCollector
 	prepareLog *result.Log
 	lintRes    *lintersResult.Result
+	buildInfo  *buildinfo.Info
+	lintConfig *lintconfig.Report
+	repoMeta   *repoinfo.Metadata
+	release    *ReleaseReport
+	dryRun     *dryRunRes
+	depGraph   *depgraph.Graph
+	sbom       *sbom.Document
+
+	lintOutputs *lintOutputs
 }
 
 func NewRepo(cfg *RepoConfig) *Repo {
@@ -68,12 +116,44 @@ func NewRepo(cfg *RepoConfig) *Repo {
 }
 
 func (r Repo) Process(ctx *RepoContext) {
+	r.process(ctx, nil)
+}
+
+// process analyzes the repo and saves the result: afterAnalysis is called only for successful analyses
+// before the result is saved
+func (r Repo) process(ctx *RepoContext, afterAnalysis func(res *repoResult)) {
+	startedAt := time.Now()
+	ctx.Ctx = executors.ContextWithTruncationRecorder(ctx.Ctx, executors.NewTruncationRecorder())
+	if e := r.repoKillSwitch(ctx); e != nil {
+		if e.Level == killswitch.LevelSoft { // analyses under hard kill switches are dropped
+			r.submitResult(ctx, &repoResult{}, &errorutils.BadInputError{PublicDesc: killSwitchWarning(r.msg(), e)})
+		}
+		return
+	}
+
 	res, err := r.processPanicSafe(ctx)
 	if res == nil {
 		res = &repoResult{}
 	}
+	if err == nil && afterAnalysis != nil {
+		afterAnalysis(res)
+	}
+
+	status := r.submitResult(ctx, res, err)
+	r.publishFeedEvent(ctx, res, status, time.Since(startedAt))
+	r.reportUsage(ctx, time.Since(startedAt))
+}
 
-	r.submitResult(ctx, res, err)
+func (r Repo) reportUsage(ctx *RepoContext, spent time.Duration) {
+	rec := &usage.Record{
+		AnalysisGUID:   ctx.AnalysisGUID,
+		Kind:           usageKindRepo,
+		ComputeSeconds: int(spent / time.Second),
+	}
+	updateCtx := context.Background() // the analysis context can be already expired
+	if err := r.Usage.Report(updateCtx, ctx.Repo.Owner, ctx.Repo.Name, rec); err != nil {
+		r.Log.Warnf("Can't report usage %+v: %s", rec, err)
+	}
 }
 
 func (r Repo) processPanicSafe(ctx *RepoContext) (retRes *repoResult, err error) {
@@ -90,10 +170,24 @@ func (r Repo) processPanicSafe(ctx *RepoContext) (retRes *repoResult, err error)
 	var res repoResult
 	r.updateStatusToInQueue(ctx, &res)
 
+	var pathErr error
+	if ctx.Path, pathErr = cleanAnalysisPath(ctx.Path); pathErr != nil {
+		return nil, pathErr
+	}
+
+	cp := checkpoint.FromContext(ctx.Ctx)
+	cp.Reached(ctx.Ctx, "prepare", "")
 	if err := r.prepare(ctx, &res); err != nil {
 		return nil, errors.Wrap(err, "failed to prepare repo")
 	}
 
+	if skipNotGoRepo(ctx.Ctx, res.repoMeta, analytics.EventRepoAnalyzed, res.publicWarn) {
+		return &res, errNothingToAnalyze
+	}
+
+	if cp != nil {
+		cp.Reached(ctx.Ctx, "analyze", r.Exec.WorkDir())
+	}
 	if err := r.analyze(ctx, &res); err != nil {
 		return nil, errors.Wrap(err, "failed to analyze repo")
 	}
@@ -106,7 +200,7 @@ func (r Repo) updateStatusToInQueue(ctx *RepoContext, res *repoResult) {
 	if err != nil {
 		r.Log.Warnf("Can't get current state: %s", err)
 	} else if curState.Status == statusSentToQueue {
-		res.addTimingFrom("In Queue", fromDBTime(curState.CreatedAt))
+		res.trackQueueTime(ctx.Ctx, "analyzeRepo", curState.CreatedAt)
 		curState.Status = statusProcessing
 		if err = r.State.UpdateState(ctx.Ctx, ctx.Repo.Owner, ctx.Repo.Name, ctx.AnalysisGUID, curState); err != nil {
 			r.Log.Warnf("Can't update repo analysis %s state with setting status to 'processing': %s", ctx.AnalysisGUID, err)
@@ -118,32 +212,87 @@ func (r *Repo) prepare(ctx *RepoContext, res *repoResult) error {
 	defer res.addTimingFrom("Prepare", time.Now())
 
 	fr := buildFetchersRepo(ctx)
+	if r.RepoCfg != nil {
+		fr.SparsePaths = sparsePathsWithAnalysisPath(r.RepoCfg.SparseCheckout, ctx.Path)
+	}
 	exec, resLog, err := r.Wi.Setup(ctx.Ctx, fr, "github.com", ctx.Repo.Owner, ctx.Repo.Name)
+	deploykeys.StopAgent(ctx.Ctx, r.Exec) // the repo is fetched: linters must not use the deploy key
 	if err != nil {
 		return errors.Wrap(err, "failed to setup workspace")
 	}
 
 	r.Exec = exec
 	res.prepareLog = resLog
+	res.buildInfo = buildinfo.New()
+	res.buildInfo.Collect(ctx.Ctx, exec)
+
+	if ctx.Path != "" {
+		if err = checkAnalysisPath(ctx.Ctx, exec, ctx.Path); err != nil {
+			return err
+		}
+	}
+
+	res.repoMeta = fetchRepoMetadata(ctx.Ctx, r.InfoFetcher, exec, analytics.EventRepoAnalyzed)
+	res.depGraph = buildDependencyGraph(ctx.Ctx, exec, analytics.EventRepoAnalyzed)
+	if r.RepoCfg != nil {
+		res.sbom, err = generateSBOM(ctx.Ctx, exec, r.Artifacts, r.RepoCfg.SBOM, ctx.Repo.FullName(), "", res.depGraph)
+		if err != nil {
+			r.Log.Warnf("Can't generate SBOM: %s", err)
+		}
+	}
+	if res.lintConfig, err = lintconfig.Check(ctx.Ctx, exec); err != nil {
+		r.Log.Warnf("Can't check golangci-lint config: %s", err)
+	}
 	return nil
 }
 
 func (r Repo) analyze(ctx *RepoContext, res *repoResult) error {
 	defer res.addTimingFrom("Analysis", time.Now())
 
-	lintRes, err := r.Runner.Run(ctx.Ctx, r.Linters, r.Exec)
+	lintersList := withConcurrency(r.Linters, tuneExecutor(ctx.Ctx, r.Exec, analytics.EventRepoAnalyzed))
+	lintersList = withAnalysisPath(lintersList, ctx.Path)
+	lintersList = withProfiles(lintersList, r.Artifacts)
+	startedAt := time.Now()
+	lintRes, err := r.Runner.Run(ctx.Ctx, lintersList, r.Exec)
+	if reason, ok := errorutils.FlakyReason(err); ok && ctx.Ctx.Err() == nil {
+		// retry only linting: preparation of the workspace is the most expensive part
+		r.Log.Warnf("Linting failed with flaky error (%s), retry it: %s", reason, err)
+		res.addStep("Retry analysis", startedAt, time.Now(), fmt.Sprintf("retried after %s failure", reason), nil)
+		lintRes, err = r.Runner.Run(ctx.Ctx, lintersList, r.Exec)
+	}
 	if err != nil {
 		return errors.Wrap(err, "failed running linters")
 	}
 
+	lintRes.Issues = filterSuppressed(ctx.Ctx, r.Suppressions, ctx.Repo, lintRes.Issues, analytics.EventRepoAnalyzed)
+	if ctx.checkDependencies && r.RepoCfg.GetDependencyFreshness() {
+		appendDepsIssues(ctx.Ctx, golinters.DepsFreshness{}, r.Exec, lintRes)
+	}
+	lintRes.Issues = filterSkippedFiles(ctx.Ctx, r.RepoCfg, lintRes.Issues, analytics.EventRepoAnalyzed)
+	lintRes.Issues = filterOutsidePath(ctx.Ctx, ctx.Path, lintRes.Issues, analytics.EventRepoAnalyzed)
+	if len(lintRes.TimedOutLinters) != 0 {
+		res.publicWarn("analysis", timedOutLintersWarning(r.msg(), lintRes.TimedOutLinters))
+	}
+	if len(lintRes.FailedLinters) != 0 {
+		res.publicWarn("analysis", failedLintersWarning(r.msg(), lintRes.FailedLinters))
+	}
+	if lintRes.Capped {
+		res.publicWarn("analysis", issuesCappedWarning(r.msg()))
+	}
+
 	res.lintRes = lintRes
+	res.lintOutputs = collectLintOutputs(ctx.Ctx, r.Exec, r.Artifacts, lintRes)
 	return nil
 }
 
 func buildFetchersRepo(ctx *RepoContext) *fetchers.Repo {
 	repo := ctx.Repo
+	cloneURL := fmt.Sprintf("https://github.com/%s/%s.git", repo.Owner, repo.Name)
+	if ctx.DeployKey != "" {
+		cloneURL = github.SSHCloneURL(*repo)
+	}
 	return &fetchers.Repo{
-		CloneURL: fmt.Sprintf("https://github.com/%s/%s.git", repo.Owner, repo.Name),
+		CloneURL: cloneURL,
 		Ref:      ctx.Branch,
 		FullPath: fmt.Sprintf("github.com/%s/%s", repo.Owner, repo.Name),
 	}
@@ -216,7 +365,9 @@ func (r Repo) buildPublicError(err error) string {
 	return internalError
 }
 
-func (r Repo) submitResult(ctx *RepoContext, res *repoResult, err error) {
+// submitResult saves the result and returns the status of the analysis
+func (r Repo) submitResult(ctx *RepoContext, res *repoResult, err error) string {
+	errClass := trackErrorClass(ctx.Ctx, analytics.EventRepoAnalyzed, errorutils.WithAnalysisTimeout(ctx.Ctx, err))
 	err = r.transformError(err)
 	status := r.errorToStatus(err)
 	publicErrorText := r.buildPublicError(err)
@@ -227,11 +378,12 @@ func (r Repo) submitResult(ctx *RepoContext, res *repoResult, err error) {
 		r.Log.Errorf("Failed repo analysis: %s, timings: %v", err, res.timings)
 	}
 
+	res.warnTruncatedOutputs(ctx.Ctx, r.msg())
 	if res.prepareLog != nil {
 		for _, sg := range res.prepareLog.Groups {
 			for _, s := range sg.Steps {
 				if s.Error != "" {
-					text := fmt.Sprintf("%s error: %s", s.Description, s.Error)
+					text := r.msg().Sprintf(i18n.WarnPrepareStep, s.Description, s.Error)
 					text = escapeErrorText(text, buildSecrets())
 					res.publicWarn(sg.Name, text)
 				}
@@ -245,15 +397,36 @@ func (r Repo) submitResult(ctx *RepoContext, res *repoResult, err error) {
 			Timings:  res.timings,
 			Warnings: res.warnings,
 			Error:    publicErrorText,
+			DryRun:   res.dryRun,
 		},
 	}
+	resJSON.WorkerRes.redact(buildSecrets())
+	// repos are cloned by deploy keys only if they are private
+	resJSON.WorkerRes.Artifacts = saveArtifacts(ctx.Ctx, r.Artifacts, ctx.AnalysisGUID, ctx.DeployKey != "", analysisArtifacts{
+		lintRes:  res.lintRes,
+		buildLog: res.prepareLog,
+		depGraph: res.depGraph,
+		bom:      res.sbom,
+		outputs:  res.lintOutputs,
+	}, buildSecrets())
+	resJSON.WorkerRes.Dependencies = graphModules(res.depGraph)
+	resJSON.WorkerRes.Build = stampBuildInfo(ctx.Ctx, analytics.EventRepoAnalyzed, res.buildInfo)
+	resJSON.WorkerRes.LintConfig = res.lintConfig
+	resJSON.WorkerRes.RepoMetadata = res.repoMeta
+	resJSON.WorkerRes.Release = res.release
 
 	if res.lintRes != nil {
 		resJSON.GolangciLintRes = res.lintRes.ResultJSON
+		resJSON.WorkerRes.InfoIssues = getInfoIssues(res.lintRes.Issues)
+		resJSON.WorkerRes.TimedOutLinters = res.lintRes.TimedOutLinters
+		resJSON.WorkerRes.IssuesCapped = res.lintRes.Capped
+		resJSON.WorkerRes.IssueAnchors = buildIssueAnchors(res.lintRes.Issues, "")
+		resJSON.WorkerRes.Annotations = buildAnnotations(ctx.Ctx, res.lintRes.Issues)
 	}
 	s := &repostate.State{
 		Status:     status,
 		ResultJSON: resJSON,
+		ErrorClass: string(errClass),
 	}
 
 	jsonBytes, err := json.Marshal(*resJSON)
@@ -267,4 +440,15 @@ func (r Repo) submitResult(ctx *RepoContext, res *repoResult, err error) {
 	if err = r.State.UpdateState(updateCtx, ctx.Repo.Owner, ctx.Repo.Name, ctx.AnalysisGUID, s); err != nil {
 		r.Log.Warnf("Can't set analysis %s status to '%v': %s", ctx.AnalysisGUID, s, err)
 	}
+
+	return status
+}
+
+// msg prints texts posted to GitHub in the language of the repo config
+func (r Repo) msg() i18n.Printer {
+	if r.RepoCfg == nil {
+		return i18n.Printer{}
+	}
+
+	return i18n.NewPrinter(r.RepoCfg.Language)
 }
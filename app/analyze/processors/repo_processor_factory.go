@@ -4,13 +4,21 @@ import (
 	"github.com/golangci/golangci-shared/pkg/apperrors"
 	"github.com/golangci/golangci-shared/pkg/config"
 	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/analyze/killswitch"
 	"github.com/golangci/golangci-worker/app/analyze/linters"
 	"github.com/golangci/golangci-worker/app/analyze/linters/golinters"
+	"github.com/golangci/golangci-worker/app/analyze/linters/lintcache"
+	"github.com/golangci/golangci-worker/app/analyze/orgfeed"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
+	"github.com/golangci/golangci-worker/app/analyze/repoinfo"
 	"github.com/golangci/golangci-worker/app/analyze/repostate"
+	"github.com/golangci/golangci-worker/app/analyze/suppressions"
+	"github.com/golangci/golangci-worker/app/lib/artifacts"
 	"github.com/golangci/golangci-worker/app/lib/experiments"
 	"github.com/golangci/golangci-worker/app/lib/fetchers"
 	"github.com/golangci/golangci-worker/app/lib/goutils/workspaces"
 	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/golangci/golangci-worker/app/lib/usage"
 	"github.com/pkg/errors"
 )
 
@@ -29,27 +37,52 @@ func NewRepoProcessorFactory(cfg *StaticRepoConfig, noCtxLog logutil.Log) *RepoP
 func (f RepoProcessorFactory) BuildProcessor(ctx *RepoContext) (*Repo, func(), error) {
 	cfg := *f.cfg
 
+	if cfg.State == nil {
+		cfg.State = repostate.NewAPIStorage(httputils.GrequestsClient{})
+	}
+
+	if cfg.Cfg == nil {
+		envCfg := config.NewEnvConfig(f.noCtxLog)
+		cfg.Cfg = envCfg
+	}
+
 	if cfg.RepoFetcher == nil {
-		cfg.RepoFetcher = fetchers.NewGit()
+		cfg.RepoFetcher = fetchers.NewPartialGit(fetchers.NewGit(), cfg.Cfg.GetInt("REPO_PARTIAL_CLONE_FROM_MB", 0)*1024)
 	}
 
-	if cfg.Linters == nil {
-		cfg.Linters = []linters.Linter{
-			golinters.GolangciLint{},
-		}
+	if cfg.InfoFetcher == nil {
+		cfg.InfoFetcher = repoinfo.NewCloningFetcher(cfg.RepoFetcher)
+	}
+
+	if cfg.Usage == nil {
+		cfg.Usage = usage.NewAPIReporter(httputils.GrequestsClient{})
 	}
 
 	if cfg.Runner == nil {
-		cfg.Runner = linters.SimpleRunner{}
+		cfg.Runner = linters.SimpleRunner{
+			LinterTimeout: cfg.Cfg.GetDuration("LINTER_TIMEOUT", 0),
+			MaxIssues:     cfg.Cfg.GetInt("LINTER_MAX_ISSUES", 0),
+		}
 	}
 
-	if cfg.State == nil {
-		cfg.State = repostate.NewAPIStorage(httputils.GrequestsClient{})
+	if cfg.CfgFetcher == nil {
+		cfg.CfgFetcher = repoconfig.Default()
 	}
 
-	if cfg.Cfg == nil {
-		envCfg := config.NewEnvConfig(f.noCtxLog)
-		cfg.Cfg = envCfg
+	if cfg.Artifacts == nil {
+		cfg.Artifacts = artifacts.Default()
+	}
+
+	if cfg.KillSwitches == nil {
+		cfg.KillSwitches = killswitch.Default()
+	}
+
+	if cfg.Suppressions == nil {
+		cfg.Suppressions = suppressions.NewAPIStorage(httputils.GrequestsClient{})
+	}
+
+	if cfg.Feed == nil {
+		cfg.Feed = orgfeed.Default()
 	}
 
 	if cfg.Et == nil {
@@ -68,11 +101,32 @@ func (f RepoProcessorFactory) BuildProcessor(ctx *RepoContext) (*Repo, func(), e
 
 	ec := experiments.NewChecker(cfg.Cfg, log)
 
-	exec, err := makeExecutor(ctx.Ctx, ctx.Repo, false, log, ec)
+	repoCfg, err := repoconfig.Load(ctx.Ctx, cfg.CfgFetcher, ctx.Repo)
+	if err != nil {
+		log.Warnf("Can't load repo config, use the best we have: %s", err)
+	}
+
+	if cfg.Linters == nil {
+		cfg.Linters = []linters.Linter{
+			golinters.GolangciLint{
+				Cache:      lintcache.Default(),
+				Repo:       ctx.Repo.FullName(),
+				SpellCheck: repoCfg.GetSpellCheck(),
+			},
+		}
+	}
+
+	exec, err := makeExecutor(ctx.Ctx, ctx.Repo, false, log, ec, ctx.WorkDirEncryption)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "can't make executor")
 	}
 
+	if ctx.DeployKey != "" {
+		if exec, err = withDeployKey(ctx.Ctx, exec, ctx.DeployKey); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	cleanup := func() {
 		exec.Clean()
 	}
@@ -82,6 +136,7 @@ func (f RepoProcessorFactory) BuildProcessor(ctx *RepoContext) (*Repo, func(), e
 		Exec:             exec,
 		Wi:               workspaces.NewGo2(exec, log, cfg.RepoFetcher),
 		Ec:               ec,
+		RepoCfg:          repoCfg,
 	})
 
 	return p, cleanup, nil
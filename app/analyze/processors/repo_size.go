@@ -0,0 +1,36 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+)
+
+// validateRepoSize checks the size reported by the provider: zero size is unknown, zero limit is no limit
+func validateRepoSize(sizeKB, limitMB int) error {
+	if limitMB == 0 || sizeKB <= limitMB*1024 {
+		return nil
+	}
+
+	desc := fmt.Sprintf("repo is too large: %s, limit %s", formatSize(sizeKB*1024), formatSize(limitMB*mb))
+	return errorutils.ResourceLimit(fmt.Errorf("repo size %dKB exceeds %dMB", sizeKB, limitMB), desc)
+}
+
+// checkRepoSize fails fast before the expensive clone if the repo exceeds the limit of the plan
+func (g *githubGoPR) checkRepoSize(ctx context.Context) error {
+	sizeKB := g.pr.GetHead().GetRepo().GetSize()
+	analytics.SaveEventProp(ctx, analytics.EventPRChecked, "repoSizeKB", sizeKB)
+
+	limitMB := g.maxRepoSizeMB
+	if g.plan != nil && g.plan.MaxRepoSizeMB != 0 {
+		limitMB = g.plan.MaxRepoSizeMB
+	}
+
+	if err := validateRepoSize(sizeKB, limitMB); err != nil {
+		return g.failBeforeAnalysis(ctx, err)
+	}
+
+	return nil
+}
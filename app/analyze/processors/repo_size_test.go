@@ -0,0 +1,54 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/linters"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/fetchers"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/usage"
+	gh "github.com/google/go-github/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRepoSize(t *testing.T) {
+	err := validateRepoSize(3*1024*1024, 1024)
+	assert.Equal(t, "repo is too large: 3072MB, limit 1024MB", errorutils.PublicDesc(err))
+	assert.Equal(t, errorutils.KindResourceLimit, errorutils.KindOf(err))
+
+	assert.NoError(t, validateRepoSize(1024*1024, 1024))
+	assert.NoError(t, validateRepoSize(3*1024*1024, 0), "no limit")
+	assert.NoError(t, validateRepoSize(0, 1024), "unknown size")
+}
+
+func TestRepoTooLargeIsNotCloned(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pr := *testPR
+	head := *pr.Head
+	head.Repo = &gh.Repository{Size: gh.Int(2 * 1024 * 1024)}
+	pr.Head = &head
+
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequest(testCtxMatcher, c).Return(&pr, nil)
+	gc.EXPECT().GetTokenScopes(testCtxMatcher, c).AnyTimes().Return(&github.TokenScopes{}, nil)
+	gc.EXPECT().GetPullRequestPatch(any, c).AnyTimes().Return(getFakePatch(t), nil)
+	gc.EXPECT().SetCommitStatus(testCtxMatcher, c, testSHA, github.StatusError,
+		"repo is too large: 2048MB, limit 1024MB", any)
+
+	exec := executors.NewMockExecutor(ctrl) // nothing is run in the workspace
+	exec.EXPECT().Clean().AnyTimes()
+
+	p := getNopedProcessor(t, ctrl, githubGoPRConfig{
+		linters:     []linters.Linter{linters.NewMockLinter(ctrl)},
+		repoFetcher: fetchers.NewMockFetcher(ctrl),
+		exec:        exec,
+		client:      gc,
+	})
+	assert.NoError(t, p.Process(usage.ContextWithPlan(testCtx, &usage.Plan{MaxRepoSizeMB: 1024})))
+}
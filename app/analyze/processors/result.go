@@ -1,10 +1,31 @@
 package processors
 
 import (
+	"context"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/depgraph"
+	"github.com/golangci/golangci-worker/app/analyze/lintconfig"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/repoinfo"
+	"github.com/golangci/golangci-worker/app/lib/artifacts"
+	"github.com/golangci/golangci-worker/app/lib/buildinfo"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/experiments"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+	"github.com/golangci/golangci-worker/app/lib/queue"
+	"github.com/golangci/golangci-worker/app/lib/redact"
+	"github.com/golangci/golangci-worker/app/lib/shadow"
+	"github.com/pkg/errors"
 )
 
+const maxTimelineOutputLen = 4096
+
 type JSONDuration time.Duration
 
 func (d JSONDuration) MarshalJSON() ([]byte, error) {
@@ -25,27 +46,118 @@ type Warning struct {
 	Text string
 }
 
+// TimelineStep is a public step of analysis, it's rendered as a CI-like timeline on the details page
+type TimelineStep struct {
+	Name       string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Output     string `json:",omitempty"`
+	Error      string `json:",omitempty"`
+}
+
 type resultCollector struct {
 	timings  []Timing
 	warnings []Warning
+	timeline []TimelineStep
 }
 
 func (r *resultCollector) trackTiming(name string, f func()) {
+	_ = r.trackStep(name, func() (string, error) {
+		f()
+		return "", nil
+	})
+}
+
+// trackStep runs f and saves its timing and timeline step with the public output and error
+func (r *resultCollector) trackStep(name string, f func() (string, error)) error {
 	startedAt := time.Now()
-	f()
+	out, err := f()
+	r.addStep(name, startedAt, time.Now(), out, err)
+	return err
+}
+
+// addStep saves timing and timeline step of the already finished step
+func (r *resultCollector) addStep(name string, startedAt, finishedAt time.Time, out string, err error) {
 	r.timings = append(r.timings, Timing{
 		Name:     name,
-		Duration: JSONDuration(time.Since(startedAt)),
+		Duration: JSONDuration(finishedAt.Sub(startedAt)),
 	})
+
+	step := TimelineStep{
+		Name:       name,
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+		Output:     out, // it's truncated after redaction: a cut secret can't be redacted
+	}
+	if err != nil {
+		step.Error = publicErrorText(err)
+	}
+	r.timeline = append(r.timeline, step)
+}
+
+func (r *resultCollector) addTiming(name string, from, to time.Time) {
+	r.addStep(name, from, to, "", nil)
 }
 
 func (r *resultCollector) addTimingFrom(name string, from time.Time) {
-	r.timings = append(r.timings, Timing{
-		Name:     name,
-		Duration: JSONDuration(time.Since(from)),
-	})
+	r.addTiming(name, from, time.Now())
+}
+
+// trackQueueTime adds "In Queue" timing if the time in queue is known
+func (r *resultCollector) trackQueueTime(ctx context.Context, taskName string, dbCreatedAt time.Time) (time.Duration, bool) {
+	now := time.Now()
+	d, source, ok := queue.GetLatency(queue.EnqueuedAtFromContext(ctx), fromDBTime(dbCreatedAt), now)
+	if !ok {
+		analytics.Log(ctx).Warnf("Can't compute time in queue: clocks drift is too large")
+		return 0, false
+	}
+
+	queue.ExportLatency(taskName, source, d)
+	r.addTimingFrom("In Queue", now.Add(-d))
+	return d, true
+}
+
+// maxStatusDescLen is the limit of GitHub for descriptions of commit statuses: longer ones are refused
+const maxStatusDescLen = 140
+
+// truncateStatusDesc cuts desc to maxStatusDescLen runes
+func truncateStatusDesc(desc string) string {
+	runes := []rune(desc)
+	if len(runes) <= maxStatusDescLen {
+		return desc
+	}
+
+	return string(runes[:maxStatusDescLen-3]) + "..."
+}
+
+// truncateOutput cuts out to maxTimelineOutputLen bytes on a boundary of runes
+func truncateOutput(out string) string {
+	if len(out) <= maxTimelineOutputLen {
+		return out
+	}
+
+	end := maxTimelineOutputLen
+	for end > 0 && !utf8.RuneStart(out[end]) {
+		end--
+	}
+	return out[:end] + "... (truncated)"
+}
+
+func publicErrorText(err error) string {
+	switch cerr := errors.Cause(err).(type) {
+	case *errorutils.InternalError:
+		return cerr.PublicDesc
+	case *errorutils.BadInputError:
+		return cerr.PublicDesc
+	case *IgnoredError:
+		return cerr.StatusDesc
+	default:
+		return internalError
+	}
 }
 
+// publicWarn adds a warning shown on the analysis page: text is a message of the i18n catalog
+// in the language of the repo
 func (r *resultCollector) publicWarn(tag string, text string) {
 	r.warnings = append(r.warnings, Warning{
 		Tag:  tag,
@@ -54,9 +166,90 @@ func (r *resultCollector) publicWarn(tag string, text string) {
 }
 
 type workerRes struct {
-	Timings  []Timing  `json:",omitempty"`
-	Warnings []Warning `json:",omitempty"`
-	Error    string    `json:",omitempty"`
+	Timings  []Timing       `json:",omitempty"`
+	Warnings []Warning      `json:",omitempty"`
+	Timeline []TimelineStep `json:",omitempty"`
+	Error    string         `json:",omitempty"`
+
+	// DryRun is set only in dry-run mode
+	DryRun *dryRunRes `json:",omitempty"`
+
+	// IssueCommits is set only if commits attribution is enabled
+	IssueCommits []issueCommit `json:",omitempty"`
+
+	Artifacts []artifacts.Artifact `json:",omitempty"`
+
+	// Dependencies are modules of the build list, requirements between them are in the dependency_graph artifact
+	Dependencies []depgraph.Module `json:",omitempty"`
+
+	// InfoIssues don't affect status, e.g. issues of dependencies freshness
+	InfoIssues []result.Issue `json:",omitempty"`
+
+	// TimedOutLinters are set if the result is partial: issues of these linters aren't reported
+	TimedOutLinters []string `json:",omitempty"`
+
+	// IssuesCapped is set if the result is partial: linters were stopped at the max count of issues
+	IssuesCapped bool `json:",omitempty"`
+
+	// IssueAnchors are deep links to issues on the details page
+	IssueAnchors []issueAnchor `json:",omitempty"`
+
+	// Build is versions of the worker and tools the analysis was made with
+	Build *buildinfo.Info `json:",omitempty"`
+
+	// MergeBase is set only if issues were scoped by the diff from the merge-base instead of the provider patch
+	MergeBase string `json:",omitempty"`
+
+	// LintConfig is a suggested golangci-lint config or deprecated settings of the existing one
+	LintConfig *lintconfig.Report `json:",omitempty"`
+
+	RepoMetadata *repoinfo.Metadata `json:",omitempty"`
+
+	// Shadow are reports of shadow runs of candidate implementations of stages
+	Shadow []*shadow.Report `json:",omitempty"`
+
+	// PreviewIssues are issues found so far, they are set only while the analysis is processing
+	PreviewIssues []result.Issue `json:",omitempty"`
+
+	// Annotations are issues formatted for the CI the worker runs in, e.g. workflow commands of GitHub Actions
+	Annotations []string `json:",omitempty"`
+
+	// Release is a release readiness report, it's set only for analyses of tags
+	Release *ReleaseReport `json:",omitempty"`
+}
+
+// redact hides secrets in all public texts of result: they are shown on the details page
+func (r *workerRes) redact(secrets map[string]string) {
+	rd := redact.New(secrets)
+
+	warnings := make([]Warning, 0, len(r.Warnings))
+	for _, w := range r.Warnings {
+		w.Text = rd.Redact(w.Text)
+		warnings = append(warnings, w)
+	}
+	r.Warnings = warnings
+
+	timeline := make([]TimelineStep, 0, len(r.Timeline))
+	for _, s := range r.Timeline {
+		s.Output = truncateOutput(rd.Redact(s.Output))
+		s.Error = truncateOutput(rd.Redact(s.Error))
+		timeline = append(timeline, s)
+	}
+	r.Timeline = timeline
+
+	r.Error = rd.Redact(r.Error)
+
+	if r.DryRun != nil {
+		for i := range r.DryRun.Comments {
+			r.DryRun.Comments[i].Body = rd.Redact(r.DryRun.Comments[i].Body)
+		}
+		for i := range r.DryRun.Statuses {
+			r.DryRun.Statuses[i].Description = rd.Redact(r.DryRun.Statuses[i].Description)
+		}
+		for i := range r.DryRun.Releases {
+			r.DryRun.Releases[i].Body = rd.Redact(r.DryRun.Releases[i].Body)
+		}
+	}
 }
 
 type resultJSON struct {
@@ -68,3 +261,51 @@ type resultJSON struct {
 func fromDBTime(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.Local)
 }
+
+// stampBuildInfo adds experiments of the analysis to info and saves it into the analytics event
+func stampBuildInfo(ctx context.Context, eventName analytics.EventName, info *buildinfo.Info) *buildinfo.Info {
+	if info == nil { // versions of tools weren't collected
+		info = buildinfo.New()
+	}
+	info.Experiments = experiments.Evaluated(ctx)
+
+	analytics.SaveEventProps(ctx, eventName, info.EventProps())
+	return info
+}
+
+func issuesCappedWarning(p i18n.Printer) string {
+	return p.Sprintf(i18n.WarnIssuesCapped)
+}
+
+func timedOutLintersWarning(p i18n.Printer, linters []string) string {
+	return p.Sprintf(i18n.WarnTimedOutLinters, strings.Join(linters, ", "))
+}
+
+func failedLintersWarning(p i18n.Printer, linters []string) string {
+	return p.Sprintf(i18n.WarnFailedLinters, strings.Join(linters, ", "))
+}
+
+// fetchRepoMetadata saves metadata of the checked-out repo to the analytics event, it's nil on errors
+func fetchRepoMetadata(ctx context.Context, f repoinfo.Fetcher, exec executors.Executor, eventName analytics.EventName) *repoinfo.Metadata {
+	meta, err := f.FetchMetadata(ctx, exec)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't fetch repo metadata: %s", err)
+		return nil
+	}
+
+	analytics.SaveEventProps(ctx, eventName, meta.EventProps())
+	return meta
+}
+
+// warnTruncatedOutputs adds public warnings about outputs of commands truncated by executors:
+// errors of them can be in the truncated part
+func (r *resultCollector) warnTruncatedOutputs(ctx context.Context, msg i18n.Printer) {
+	rec := executors.TruncationRecorderFromContext(ctx)
+	if rec == nil {
+		return
+	}
+
+	for _, t := range rec.Truncations() {
+		r.publicWarn("executor", msg.Sprintf(i18n.WarnOutputTruncated, t.Command, formatSize(int(t.TruncatedBytes))))
+	}
+}
@@ -0,0 +1,47 @@
+package processors
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/redact"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateOutput(t *testing.T) {
+	assert.Equal(t, "short", truncateOutput("short"))
+
+	out := truncateOutput(strings.Repeat("a", maxTimelineOutputLen+10))
+	assert.Equal(t, strings.Repeat("a", maxTimelineOutputLen)+"... (truncated)", out)
+
+	// a 3-byte rune crosses the limit: it's dropped as a whole
+	out = truncateOutput(strings.Repeat("a", maxTimelineOutputLen-1) + "€tail")
+	assert.True(t, utf8.ValidString(out))
+	assert.Equal(t, strings.Repeat("a", maxTimelineOutputLen-1)+"... (truncated)", out)
+}
+
+func TestTimelineIsRedactedBeforeTruncation(t *testing.T) {
+	const secret = "s3cr3t-token-value"
+	// the secret crosses the limit: a truncated secret wouldn't be found by redaction
+	out := strings.Repeat("a", maxTimelineOutputLen-5) + secret
+	publicErr := &errorutils.BadInputError{PublicDesc: strings.Repeat("b", maxTimelineOutputLen-3) + secret}
+
+	var r resultCollector
+	r.addStep("Deps", time.Now(), time.Now(), out, publicErr)
+	r.addStep("Lint", time.Now(), time.Now(), "", errors.New("internal"))
+
+	res := workerRes{Timeline: r.timeline}
+	res.redact(map[string]string{secret: redact.Hidden})
+
+	step := res.Timeline[0]
+	for _, s := range []string{step.Output, step.Error} {
+		assert.NotContains(t, s, secret[:5])
+		assert.True(t, strings.HasSuffix(s, "... (truncated)"), s[len(s)-30:])
+		assert.True(t, len(s) <= maxTimelineOutputLen+len("... (truncated)"))
+	}
+	assert.Equal(t, internalError, res.Timeline[1].Error)
+}
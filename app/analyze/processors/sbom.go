@@ -0,0 +1,61 @@
+package processors
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/depgraph"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
+	"github.com/golangci/golangci-worker/app/analyze/sbom"
+	"github.com/golangci/golangci-worker/app/lib/artifacts"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+	"github.com/pkg/errors"
+)
+
+// generateSBOM renders the SBOM of the analyzed commit if the repo opted in: it's nil if the policy is nil
+// or SBOM artifacts aren't saved. Repos without go modules get SBOMs of only the repo itself.
+func generateSBOM(ctx context.Context, exec executors.Executor, m *artifacts.Manager, policy *repoconfig.SBOMPolicy,
+	repo, commit string, g *depgraph.Graph) (*sbom.Document, error) {
+
+	if policy == nil {
+		return nil, nil
+	}
+	if !m.IsEnabled(artifacts.KindSBOM) {
+		analytics.Log(ctx).Warnf("SBOM is configured by the repo but %s artifacts aren't saved", artifacts.KindSBOM)
+		return nil, nil
+	}
+
+	format, err := sbom.ParseFormat(policy.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	if commit == "" {
+		out, err := exec.Run(ctx, "git", "rev-parse", "HEAD")
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't get the analyzed commit: %s", out)
+		}
+		commit = strings.TrimSpace(out)
+	}
+
+	if g == nil {
+		g = &depgraph.Graph{}
+	}
+	return sbom.Generate(format, sbom.Subject{Repo: repo, Commit: commit}, g, time.Now())
+}
+
+func (g *githubGoPR) generateSBOM(ctx context.Context) error {
+	doc, err := generateSBOM(ctx, g.exec, g.artifacts, g.repoCfg.SBOM, g.context.Repo.FullName(),
+		g.pr.GetHead().GetSHA(), g.depGraph)
+	if err != nil {
+		g.publicWarn("sbom", g.msg.Sprintf(i18n.WarnSBOMFailed))
+		analytics.Log(ctx).Warnf("Can't generate SBOM: %s", err)
+		return nil
+	}
+
+	g.sbom = doc
+	return nil
+}
@@ -0,0 +1,135 @@
+package processors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/goutils/workspaces"
+	"github.com/golangci/golangci-worker/app/lib/shadow"
+)
+
+// prepareOutput is compared between workspace installers: the same issues must be found
+type prepareOutput struct {
+	Issues []string // fingerprints
+}
+
+func newPrepareOutput(issues []result.Issue) prepareOutput {
+	fps := result.Fingerprints(withoutInformational(issues))
+	sort.Strings(fps)
+	return prepareOutput{Issues: fps}
+}
+
+// shadowPrepare schedules preparing of the workspace by the new installer (Go2) in the shadow of the old one
+// and linting of it: issues are compared, only issues of the old installer are reported
+func (g *githubGoPR) shadowPrepare(ctx context.Context) error {
+	if g.gw == nil || !g.ec.IsActiveForAnalysis(ctx, "new_pr_prepare_shadow", &g.context.Repo, true) {
+		return nil // the new installer is already stable for the analysis
+	}
+
+	output := newPrepareOutput(g.lintRes.Issues)
+	stable := func(context.Context) (interface{}, error) {
+		return output, nil
+	}
+	g.scheduleShadowRun("prepare", func(ctx context.Context) {
+		_, report, err := shadow.Run(ctx, "prepare", stable, g.prepareByNewInstaller)
+		if err != nil { // never happens: the stable output is ready
+			analytics.Log(ctx).Warnf("Shadow prepare failed: %s", err)
+			return
+		}
+
+		g.recordShadowReport(ctx, report)
+	})
+	return nil
+}
+
+func (g *githubGoPR) prepareByNewInstaller(ctx context.Context) (interface{}, error) {
+	dir := path.Join(g.gw.Gopath(), "shadow")
+	if out, err := executors.Unrestricted(g.exec).Run(ctx, "mkdir", "-p", dir); err != nil {
+		return nil, fmt.Errorf("can't create shadow dir: %s, %s", err, out)
+	}
+
+	wi := workspaces.NewGo2(g.exec.WithWorkDir(dir), logutil.NewStderrLog("shadow"), g.repoFetcher)
+	exec, _, err := wi.Setup(ctx, g.getRepo(), "github.com", g.context.Repo.Owner, g.context.Repo.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = storePatch(ctx, g.patch, exec); err != nil {
+		return nil, err
+	}
+
+	res, err := g.runner.Run(ctx, g.linters, exec)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPrepareOutput(res.Issues), nil
+}
+
+// recordShadowReport adds the report to the result json of the analysis: the result is saved before shadow runs
+func (g *githubGoPR) recordShadowReport(ctx context.Context, report *shadow.Report) {
+	analytics.SaveEventProp(ctx, analytics.EventShadowRun, "diffs", len(report.Diffs))
+	if report.HasDiffs() {
+		analytics.Log(ctx).Warnf("Shadow run of %s differs: %+v", report.Name, report)
+	}
+
+	repo := &g.context.Repo
+	s, err := g.state.GetState(ctx, repo.Owner, repo.Name, g.analysisGUID)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't get state of analysis %s to save shadow report: %s", g.analysisGUID, err)
+		return
+	}
+
+	raw, ok := s.ResultJSON.(json.RawMessage)
+	if !ok {
+		analytics.Log(ctx).Warnf("Analysis %s has no result json to save shadow report", g.analysisGUID)
+		return
+	}
+	if s.ResultJSON, err = withShadowReport(raw, report); err != nil {
+		analytics.Log(ctx).Warnf("Can't add shadow report to result json of analysis %s: %s", g.analysisGUID, err)
+		return
+	}
+
+	if err = g.state.UpdateState(ctx, repo.Owner, repo.Name, g.analysisGUID, s); err != nil {
+		analytics.Log(ctx).Warnf("Can't save shadow report of analysis %s: %s", g.analysisGUID, err)
+	}
+}
+
+// withShadowReport appends the report to WorkerRes.Shadow of the result json, other fields are kept as is
+func withShadowReport(resJSON json.RawMessage, report *shadow.Report) (json.RawMessage, error) {
+	var res, workerRes map[string]json.RawMessage
+	if err := json.Unmarshal(resJSON, &res); err != nil {
+		return nil, err
+	}
+	if raw, ok := res["WorkerRes"]; ok {
+		if err := json.Unmarshal(raw, &workerRes); err != nil {
+			return nil, err
+		}
+	}
+	if workerRes == nil {
+		workerRes = map[string]json.RawMessage{}
+	}
+
+	var reports []*shadow.Report
+	if raw, ok := workerRes["Shadow"]; ok {
+		if err := json.Unmarshal(raw, &reports); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if workerRes["Shadow"], err = json.Marshal(append(reports, report)); err != nil {
+		return nil, err
+	}
+	if res["WorkerRes"], err = json.Marshal(workerRes); err != nil {
+		return nil, err
+	}
+	return json.Marshal(res)
+}
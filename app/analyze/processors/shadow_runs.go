@@ -0,0 +1,70 @@
+package processors
+
+import (
+	"context"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+)
+
+// defaultShadowRunTimeout limits every shadow run: they don't have the deadline of the analysis
+const defaultShadowRunTimeout = 10 * time.Minute
+
+// shadowRun compares a candidate (e.g. a new golangci-lint) with the stable implementation of a stage,
+// it saves props of the EventShadowRun event and never fails
+type shadowRun struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+// scheduleShadowRun makes the run after the result of the analysis is saved: shadow runs don't delay
+// statuses and reviews. The run must capture its inputs: results are changed by next stages.
+func (g *githubGoPR) scheduleShadowRun(name string, run func(ctx context.Context)) {
+	g.shadowRuns = append(g.shadowRuns, shadowRun{name: name, run: run})
+}
+
+// startShadowRuns makes scheduled shadow runs in the background, it's false if there are no runs.
+// The runs are detached from ctx of the task: exec and the workspace are cleaned after them.
+func (g *githubGoPR) startShadowRuns(ctx context.Context, exec executors.Executor) bool {
+	if len(g.shadowRuns) == 0 {
+		return false
+	}
+
+	ctx = analytics.Detach(ctx)
+	go func() {
+		defer exec.Clean()
+		for _, r := range g.shadowRuns {
+			g.makeShadowRun(ctx, r)
+		}
+		if g.gw != nil {
+			g.gw.Clean(ctx)
+		}
+	}()
+	return true
+}
+
+func (g *githubGoPR) makeShadowRun(ctx context.Context, r shadowRun) {
+	ctx = analytics.ContextWithEventPropsCollector(ctx, analytics.EventShadowRun)
+	runCtx, cancel := context.WithTimeout(ctx, g.shadowRunTimeout)
+	defer cancel()
+
+	startedAt := time.Now()
+	func() {
+		defer func() {
+			if rerr := recover(); rerr != nil {
+				analytics.Log(ctx).Errorf("Shadow run %s panicked: %v", r.name, rerr)
+				analytics.SaveEventProp(ctx, analytics.EventShadowRun, "failed", true)
+			}
+		}()
+		r.run(runCtx)
+	}()
+
+	analytics.SaveEventProps(ctx, analytics.EventShadowRun, map[string]interface{}{
+		"name":            r.name,
+		"analysisGUID":    g.analysisGUID,
+		"durationSeconds": int(time.Since(startedAt) / time.Second),
+	})
+	analytics.GetTracker(ctx).Track(ctx, analytics.EventShadowRun)
+	analytics.Log(ctx).Infof("Shadow run %s finished for %s", r.name, time.Since(startedAt))
+}
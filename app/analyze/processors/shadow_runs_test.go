@@ -0,0 +1,63 @@
+package processors
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/shadow"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShadowRunsAreDetached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	g := &githubGoPR{shadowRunTimeout: time.Minute}
+	exec := executors.NewMockExecutor(ctrl)
+	assert.False(t, g.startShadowRuns(testCtx, exec), "nothing is scheduled")
+
+	ctx, cancel := context.WithCancel(testCtx)
+	var runErr error
+	g.scheduleShadowRun("canary", func(ctx context.Context) {
+		cancel() // the task is finished while the run is running
+		runErr = ctx.Err()
+	})
+	g.scheduleShadowRun("panic", func(context.Context) {
+		panic("candidate")
+	})
+
+	cleaned := make(chan struct{})
+	exec.EXPECT().Clean().Do(func() { close(cleaned) })
+	assert.True(t, g.startShadowRuns(ctx, exec))
+
+	select {
+	case <-cleaned:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the executor wasn't cleaned after shadow runs")
+	}
+	assert.NoError(t, runErr, "shadow runs aren't cancelled with the task")
+}
+
+func TestWithShadowReport(t *testing.T) {
+	saved := json.RawMessage(`{"Version":1,"GolangciLintRes":{"Issues":[]},"WorkerRes":{"Error":"e","Shadow":[{"Name":"canary"}]}}`)
+	patched, err := withShadowReport(saved, &shadow.Report{Name: "prepare", CandidateError: "failed"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var res resultJSON
+	assert.NoError(t, json.Unmarshal(patched, &res))
+	assert.Equal(t, 1, res.Version)
+	assert.Equal(t, "e", res.WorkerRes.Error)
+	if assert.Len(t, res.WorkerRes.Shadow, 2) {
+		assert.Equal(t, "prepare", res.WorkerRes.Shadow[1].Name)
+	}
+
+	patched, err = withShadowReport(json.RawMessage(`{"Version":1}`), &shadow.Report{Name: "prepare"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"Version":1,"WorkerRes":{"Shadow":[{"Name":"prepare","StableDuration":0,"CandidateDuration":0}]}}`, string(patched))
+}
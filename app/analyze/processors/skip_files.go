@@ -0,0 +1,45 @@
+package processors
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
+)
+
+func withoutSkippedFiles(cfg *repoconfig.Config, issues []result.Issue) []result.Issue {
+	if cfg == nil || len(cfg.SkipDirs)+len(cfg.SkipFiles) == 0 {
+		return issues
+	}
+
+	var ret []result.Issue
+	for _, i := range issues {
+		if !cfg.IsFileSkipped(i.File) {
+			ret = append(ret, i)
+		}
+	}
+
+	return ret
+}
+
+// filterSkippedFiles drops issues of skip dirs and files of the repo config: unlike skip settings
+// of .golangci.yml they work for issues of every linter, including linters run by the worker itself
+func filterSkippedFiles(ctx context.Context, cfg *repoconfig.Config, issues []result.Issue,
+	eventName analytics.EventName) []result.Issue {
+	ret := withoutSkippedFiles(cfg, issues)
+	if n := len(issues) - len(ret); n != 0 {
+		analytics.Log(ctx).Infof("Dropped %d issues of skipped dirs and files", n)
+		analytics.SaveEventProp(ctx, eventName, "skippedFilesIssues", n)
+	}
+
+	return ret
+}
+
+// scopeIssues drops issues of skipped files and files outside of the analysis path once all stages
+// adding issues are done: later stages, the report and the saved result see only issues in scope
+func (g *githubGoPR) scopeIssues(ctx context.Context) error {
+	g.lintRes.Issues = filterSkippedFiles(ctx, g.repoCfg, g.lintRes.Issues, analytics.EventPRChecked)
+	g.lintRes.Issues = filterOutsidePath(ctx, g.path, g.lintRes.Issues, analytics.EventPRChecked)
+	return nil
+}
@@ -0,0 +1,41 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/repoconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterSkippedFiles(t *testing.T) {
+	ctx := analytics.ContextWithEventPropsCollector(context.Background(), analytics.EventPRChecked)
+	issues := []result.Issue{
+		{FromLinter: "govet", File: "gen/api.go"},
+		{FromLinter: "apicompat", File: "api/client_mock.go"},
+		{FromLinter: "errcheck", File: "api/client.go"},
+	}
+
+	cfg := &repoconfig.Config{SkipDirs: []string{"gen"}, SkipFiles: []string{"*_mock.go"}}
+	assert.Equal(t, issues[2:], filterSkippedFiles(ctx, cfg, issues, analytics.EventPRChecked))
+	assert.Equal(t, issues, filterSkippedFiles(ctx, &repoconfig.Config{}, issues, analytics.EventPRChecked))
+	assert.Equal(t, issues, filterSkippedFiles(ctx, nil, issues, analytics.EventPRChecked))
+}
+
+func TestScopeIssuesDropsIssuesOfLaterStages(t *testing.T) {
+	ctx := analytics.ContextWithEventPropsCollector(context.Background(), analytics.EventPRChecked)
+	g := &githubGoPR{
+		repoCfg: &repoconfig.Config{SkipDirs: []string{"gen"}},
+		path:    "api",
+		lintRes: &result.Result{Issues: []result.Issue{
+			{FromLinter: "errcheck", File: "api/client.go"},
+			{FromLinter: "gogenerate", File: "gen/api.go"}, // added after linting by go generate verification
+			{FromLinter: "gogenerate", File: "web/gen.go"},
+		}},
+	}
+
+	assert.NoError(t, g.scopeIssues(ctx))
+	assert.Equal(t, []result.Issue{{FromLinter: "errcheck", File: "api/client.go"}}, g.lintRes.Issues)
+}
@@ -0,0 +1,101 @@
+package processors
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/commitstatus"
+	"github.com/golangci/golangci-worker/app/lib/github"
+)
+
+// statusSwapAttempts is a max count of reads and swaps of the status record changed concurrently
+const statusSwapAttempts = 3
+
+// statusGuardClient skips commit statuses which would overwrite newer statuses of the commit: a status is
+// claimed in the storage by compare-and-swap before it's set on GitHub. Errors of the storage don't block
+// statuses: the guard is best-effort.
+type statusGuardClient struct {
+	github.Client
+
+	storage      commitstatus.Storage
+	analysisGUID string
+
+	// seq orders the analysis among analyses of the commit, it's zero until the first status is claimed
+	seq int64
+}
+
+func newStatusGuardClient(c github.Client, storage commitstatus.Storage, analysisGUID string) *statusGuardClient {
+	return &statusGuardClient{
+		Client:       c,
+		storage:      storage,
+		analysisGUID: analysisGUID,
+	}
+}
+
+func (c *statusGuardClient) SetCommitStatus(ctx context.Context, gc *github.Context, ref string, status github.Status,
+	desc, url string) error {
+	set, err := c.claim(ctx, gc, ref, status)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't claim status %s %q of commit %s, set status unguarded: %s",
+			gc.GetStatusContext(), status, ref, err)
+	} else if !set {
+		return nil
+	}
+
+	return c.Client.SetCommitStatus(ctx, gc, ref, status, desc, url)
+}
+
+// claim returns false if the status is stale, it's retried if other analyses change the record concurrently
+func (c *statusGuardClient) claim(ctx context.Context, gc *github.Context, ref string, status github.Status) (bool, error) {
+	statusContext := gc.GetStatusContext()
+	for i := 0; i < statusSwapAttempts; i++ {
+		last, err := c.storage.GetLast(ctx, &gc.Repo, ref, statusContext)
+		if err != nil {
+			return false, err
+		}
+
+		if c.seq == 0 && last != nil && last.AnalysisGUID != c.analysisGUID &&
+			!commitstatus.IsTerminal(status) && commitstatus.IsTerminal(last.Status) {
+			reserved, err := c.reserve(ctx, gc, ref, last)
+			if err != nil || reserved {
+				return false, err
+			}
+			continue // the record was changed concurrently
+		}
+
+		if !commitstatus.ShouldSet(last, status, c.analysisGUID, c.seq) {
+			analytics.Log(ctx).Warnf("Don't set stale status %s %q of commit %s: status %s was set by analysis %s",
+				statusContext, status, ref, last.Status, last.AnalysisGUID)
+			return false, nil
+		}
+
+		r := commitstatus.Next(last, status, c.analysisGUID, c.seq)
+		swapped, err := c.storage.Swap(ctx, &gc.Repo, ref, statusContext, r)
+		if err != nil {
+			return false, err
+		}
+		if swapped {
+			c.seq = r.Seq
+			return true, nil
+		}
+	}
+
+	analytics.Log(ctx).Warnf("Status %s of commit %s is changed concurrently, don't set stale status %q",
+		statusContext, ref, status)
+	return false, nil
+}
+
+// reserve gives the new analysis of the commit with the terminal status its sequence number: pending isn't set,
+// but the final status of the analysis replaces the last one. It returns false if the record was changed concurrently.
+func (c *statusGuardClient) reserve(ctx context.Context, gc *github.Context, ref string, last *commitstatus.Record) (bool, error) {
+	r := commitstatus.Reserve(last)
+	swapped, err := c.storage.Swap(ctx, &gc.Repo, ref, gc.GetStatusContext(), r)
+	if err != nil || !swapped {
+		return false, err
+	}
+
+	c.seq = r.Version
+	analytics.Log(ctx).Infof("Don't set pending status %s of commit %s over status %s of analysis %s, "+
+		"the analysis is ordered after it by seq %d", gc.GetStatusContext(), ref, last.Status, last.AnalysisGUID, c.seq)
+	return true, nil
+}
@@ -0,0 +1,96 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/commitstatus"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusGuardSkipsStalePending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gc := &github.FakeContext
+	ref := "sha"
+	storage := commitstatus.NewMockStorage(ctrl)
+	storage.EXPECT().GetLast(any, &gc.Repo, ref, gc.GetStatusContext()).Return(&commitstatus.Record{
+		Status:       github.StatusSuccess,
+		AnalysisGUID: "retried",
+		Seq:          2,
+		Version:      3,
+	}, nil)
+
+	// the inner client is strict: setting the status fails the test
+	c := newStatusGuardClient(github.NewMockClient(ctrl), storage, "retried")
+	assert.NoError(t, c.SetCommitStatus(context.Background(), gc, ref, github.StatusPending, "in progress", ""))
+}
+
+func TestStatusGuardReanalysisOfTerminalCommit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gc := &github.FakeContext
+	ref := "sha"
+	inner := github.NewMockClient(ctrl)
+	storage := commitstatus.NewMockStorage(ctrl)
+	c := newStatusGuardClient(inner, storage, "rerun")
+
+	// pending doesn't overwrite the old failure, but the re-run gets its sequence number
+	failure := &commitstatus.Record{Status: github.StatusFailure, AnalysisGUID: "old", Seq: 1, Version: 2}
+	reserved := &commitstatus.Record{Status: github.StatusFailure, AnalysisGUID: "old", Seq: 1, Version: 3}
+	gomock.InOrder(
+		storage.EXPECT().GetLast(any, &gc.Repo, ref, gc.GetStatusContext()).Return(failure, nil),
+		storage.EXPECT().Swap(any, &gc.Repo, ref, gc.GetStatusContext(), reserved).Return(true, nil),
+	)
+	assert.NoError(t, c.SetCommitStatus(context.Background(), gc, ref, github.StatusPending, "in progress", ""))
+
+	// the final status of the re-run replaces the old one
+	gomock.InOrder(
+		storage.EXPECT().GetLast(any, &gc.Repo, ref, gc.GetStatusContext()).Return(reserved, nil),
+		storage.EXPECT().Swap(any, &gc.Repo, ref, gc.GetStatusContext(), &commitstatus.Record{
+			Status:       github.StatusSuccess,
+			AnalysisGUID: "rerun",
+			Seq:          3,
+			Version:      4,
+		}).Return(true, nil),
+		inner.EXPECT().SetCommitStatus(any, gc, ref, github.StatusSuccess, "no issues", "").Return(nil),
+	)
+	assert.NoError(t, c.SetCommitStatus(context.Background(), gc, ref, github.StatusSuccess, "no issues", ""))
+}
+
+func TestStatusGuardClaimsStatusBeforeSettingIt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gc := &github.FakeContext
+	ref := "sha"
+	inner := github.NewMockClient(ctrl)
+	storage := commitstatus.NewMockStorage(ctrl)
+	c := newStatusGuardClient(inner, storage, "guid")
+
+	pending := &commitstatus.Record{Status: github.StatusPending, AnalysisGUID: "guid", Seq: 1, Version: 1}
+	gomock.InOrder(
+		storage.EXPECT().GetLast(any, &gc.Repo, ref, gc.GetStatusContext()).Return(nil, nil),
+		storage.EXPECT().Swap(any, &gc.Repo, ref, gc.GetStatusContext(), pending).Return(true, nil),
+		inner.EXPECT().SetCommitStatus(any, gc, ref, github.StatusPending, "in progress", "").Return(nil),
+	)
+	assert.NoError(t, c.SetCommitStatus(context.Background(), gc, ref, github.StatusPending, "in progress", ""))
+
+	// a newer analysis claimed the commit concurrently: the record is read again and the status is stale
+	newer := &commitstatus.Record{Status: github.StatusPending, AnalysisGUID: "newer", Seq: 2, Version: 2}
+	gomock.InOrder(
+		storage.EXPECT().GetLast(any, &gc.Repo, ref, gc.GetStatusContext()).Return(pending, nil),
+		storage.EXPECT().Swap(any, &gc.Repo, ref, gc.GetStatusContext(), &commitstatus.Record{
+			Status:       github.StatusFailure,
+			AnalysisGUID: "guid",
+			Seq:          1,
+			Version:      2,
+		}).Return(false, nil),
+		storage.EXPECT().GetLast(any, &gc.Repo, ref, gc.GetStatusContext()).Return(newer, nil),
+	)
+	assert.NoError(t, c.SetCommitStatus(context.Background(), gc, ref, github.StatusFailure, "1 issue found", ""))
+}
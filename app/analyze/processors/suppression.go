@@ -0,0 +1,79 @@
+package processors
+
+import (
+	"context"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/reporters"
+	"github.com/golangci/golangci-worker/app/analyze/suppressions"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/pkg/errors"
+)
+
+// RecordSuppression records the suppression of the issue of the replied review comment
+// if the reply is "/golangci ignore" of a maintainer, other replies are ignored
+func RecordSuppression(ctx context.Context, client github.Client, storage suppressions.Storage, t *task.IssueIgnore) error {
+	reason, ok := suppressions.ParseCommand(t.Body)
+	if !ok {
+		analytics.Log(ctx).Infof("No ignore command in the reply %d", t.CommentID)
+		return nil
+	}
+	if !suppressions.IsMaintainer(t.AuthorAssociation) {
+		analytics.Log(ctx).Infof("Ignore command of %s (%s) isn't allowed: only maintainers can ignore issues",
+			t.Author, t.AuthorAssociation)
+		return nil
+	}
+
+	comment, err := client.GetPullRequestComment(ctx, &t.Context, t.InReplyToID)
+	if err != nil {
+		return errors.Wrapf(err, "can't get the replied comment %d", t.InReplyToID)
+	}
+
+	fingerprint := reporters.CommentFingerprint(comment.Body)
+	if fingerprint == "" {
+		analytics.Log(ctx).Infof("The replied comment %d isn't a comment of an issue", t.InReplyToID)
+		return nil
+	}
+
+	s := &suppressions.Suppression{
+		Fingerprint: fingerprint,
+		File:        comment.Path,
+		By:          t.Author,
+		Reason:      reason,
+		CreatedAt:   time.Now(),
+	}
+	if err = storage.Add(ctx, t.Repo.Owner, t.Repo.Name, s); err != nil {
+		return errors.Wrap(err, "can't save suppression")
+	}
+
+	analytics.Log(ctx).Infof("Issue %s of %s was ignored by %s", fingerprint, comment.Path, t.Author)
+	return nil
+}
+
+// filterSuppressed drops issues ignored by maintainers, errors of fetching suppressions don't fail analyses
+func filterSuppressed(ctx context.Context, storage suppressions.Storage, repo *github.Repo,
+	issues []result.Issue, eventName analytics.EventName) []result.Issue {
+	if storage == nil || len(issues) == 0 {
+		return issues
+	}
+
+	list, err := storage.List(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't fetch suppressions of issues, report all issues: %s", err)
+		return issues
+	}
+
+	ret, n := suppressions.Filter(issues, list)
+	analytics.SaveEventProp(ctx, eventName, "suppressedIssues", n)
+	return ret
+}
+
+// dropSuppressedIssues drops issues ignored by maintainers before reporting,
+// statuses of monorepo projects are already set: they count suppressed issues
+func (g *githubGoPR) dropSuppressedIssues(ctx context.Context) error {
+	g.lintRes.Issues = filterSuppressed(ctx, g.suppressions, &g.context.Repo, g.lintRes.Issues, analytics.EventPRChecked)
+	return nil
+}
@@ -0,0 +1,65 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/suppressions"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordSuppression(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	tk := &task.IssueIgnore{
+		Context:           github.FakeContext,
+		CommentID:         2,
+		InReplyToID:       1,
+		Author:            "maintainer",
+		AuthorAssociation: "MEMBER",
+		Body:              "/golangci ignore it's generated",
+	}
+	client := github.NewMockClient(ctrl)
+	client.EXPECT().GetPullRequestComment(ctx, &tk.Context, int64(1)).Return(&github.PullRequestComment{
+		Path: "a.go",
+		Body: "Error return value is not checked\n\n<!-- golangci fingerprint: 0123abcd -->",
+	}, nil)
+	storage := suppressions.NewMockStorage(ctrl)
+	storage.EXPECT().Add(ctx, tk.Repo.Owner, tk.Repo.Name, gomock.Any()).
+		Do(func(_ context.Context, _, _ string, s *suppressions.Suppression) {
+			assert.Equal(t, "0123abcd", s.Fingerprint)
+			assert.Equal(t, "a.go", s.File)
+			assert.Equal(t, "maintainer", s.By)
+			assert.Equal(t, "it's generated", s.Reason)
+		})
+	assert.NoError(t, RecordSuppression(ctx, client, storage, tk))
+
+	// nothing is requested for other replies and for replies of not maintainers
+	tk.Body = "I'll fix it"
+	assert.NoError(t, RecordSuppression(ctx, client, storage, tk))
+	tk.Body, tk.AuthorAssociation = "/golangci ignore", "CONTRIBUTOR"
+	assert.NoError(t, RecordSuppression(ctx, client, storage, tk))
+}
+
+func TestFilterSuppressed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := &github.FakeContext.Repo
+	issues := []result.Issue{{FromLinter: "errcheck", File: "a.go", Text: "unchecked"}, {FromLinter: "govet", File: "b.go", Text: "shadow"}}
+	storage := suppressions.NewMockStorage(ctrl)
+	storage.EXPECT().List(testCtx, repo.Owner, repo.Name).
+		Return([]suppressions.Suppression{{Fingerprint: result.Fingerprints(issues)[0]}}, nil)
+	assert.Equal(t, issues[1:], filterSuppressed(testCtx, storage, repo, issues, analytics.EventPRChecked))
+
+	storage.EXPECT().List(testCtx, repo.Owner, repo.Name).Return(nil, fmt.Errorf("api is down"))
+	assert.Equal(t, issues, filterSuppressed(testCtx, storage, repo, issues, analytics.EventPRChecked), "errors don't drop issues")
+}
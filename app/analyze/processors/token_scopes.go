@@ -0,0 +1,56 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/golangci/golangci-worker/app/lib/github"
+)
+
+const (
+	scopeRepo       = "repo"
+	scopePublicRepo = "public_repo"
+)
+
+// validateTokenScopes checks that the token can read the repo, set commit statuses
+// and create reviews: "repo" scope grants it for all repos, "public_repo" only for public ones
+func validateTokenScopes(ts *github.TokenScopes, private bool) error {
+	if ts == nil || !ts.Known || ts.Has(scopeRepo) {
+		return nil
+	}
+
+	if private {
+		return errorutils.ProviderAuth(fmt.Errorf("token scopes are %v", ts.Scopes),
+			"token missing repo scope: it's required to analyze private repos")
+	}
+
+	if !ts.Has(scopePublicRepo) {
+		return errorutils.ProviderAuth(fmt.Errorf("token scopes are %v", ts.Scopes),
+			"token missing repo scope: repo or public_repo scope is required to read the repo, set commit statuses and comment pull requests")
+	}
+
+	return nil
+}
+
+// fetchTokenScopes returns nil if scopes can't be fetched: they are checked only to make
+// actionable errors, analysis fails later anyway if the token can't be used
+func (g githubGoPR) fetchTokenScopes(ctx context.Context) *github.TokenScopes {
+	ts, err := g.client.GetTokenScopes(ctx, g.context)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't get token scopes: %s", err)
+		return nil
+	}
+
+	return ts
+}
+
+func (g githubGoPR) failOnTokenScopes(ctx context.Context, err error) error {
+	analytics.Log(ctx).Warnf("Invalid token scopes: %s", err)
+	errClass := trackErrorClass(ctx, analytics.EventPRChecked, err)
+
+	// commit status can't be set without scopes: show the error on the details page
+	g.updateAnalysisState(ctx, nil, github.StatusError, errorutils.PublicDesc(err), errClass)
+	return err
+}
@@ -2,17 +2,13 @@ package prstate
 
 import (
 	"context"
-	"time"
+
+	"github.com/golangci/golangci-worker/app/lib/apiclient"
 )
 
 //go:generate mockgen -package prstate -source storage.go -destination storage_mock.go
 
-type State struct {
-	CreatedAt           time.Time
-	Status              string
-	ReportedIssuesCount int
-	ResultJSON          interface{}
-}
+type State = apiclient.PRAnalysisState
 
 type Storage interface {
 	UpdateState(ctx context.Context, owner, name, analysisID string, state *State) error
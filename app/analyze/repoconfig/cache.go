@@ -0,0 +1,145 @@
+package repoconfig
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golangci/golangci-shared/pkg/config"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/apiclient"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+)
+
+// maxCachedConfigs limits memory of the cache: expired configs are dropped when it's reached
+const maxCachedConfigs = 10000
+
+type cachedConfig struct {
+	cfg       *Config
+	etag      string
+	checkedAt time.Time
+}
+
+// CachingAPIFetcher keeps configs of organizations and repos for ttl: bursts of pushes into a repo
+// don't fetch its config for every analysis. Expired configs are revalidated by their etags,
+// unchanged configs aren't transferred again. The stale config is used if the API fails.
+type CachingAPIFetcher struct {
+	api *apiclient.Client
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	configs map[string]*cachedConfig
+}
+
+func NewCachingAPIFetcher(client httputils.Client, ttl time.Duration) *CachingAPIFetcher {
+	return &CachingAPIFetcher{
+		api:     apiclient.New(client),
+		ttl:     ttl,
+		now:     time.Now,
+		configs: map[string]*cachedConfig{},
+	}
+}
+
+var _ Fetcher = &CachingAPIFetcher{}
+
+func (f *CachingAPIFetcher) FetchOrgDefaults(ctx context.Context, owner string) (*Config, error) {
+	return f.fetch(ctx, "orgs/"+owner, func(etag string, cfg *Config) (string, bool, error) {
+		return f.api.GetOrgConfigIfChanged(ctx, owner, etag, cfg)
+	})
+}
+
+func (f *CachingAPIFetcher) FetchRepoConfig(ctx context.Context, repo *github.Repo) (*Config, error) {
+	return f.fetch(ctx, "repos/"+repo.FullName(), func(etag string, cfg *Config) (string, bool, error) {
+		return f.api.GetRepoConfigIfChanged(ctx, repo.Owner, repo.Name, etag, cfg)
+	})
+}
+
+func (f *CachingAPIFetcher) get(key string) *cachedConfig {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c := f.configs[key]
+	if c == nil {
+		return nil
+	}
+
+	ret := *c
+	return &ret
+}
+
+func (f *CachingAPIFetcher) put(key string, c *cachedConfig) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.configs[key]; !ok && len(f.configs) >= maxCachedConfigs {
+		for k, v := range f.configs {
+			if f.now().Sub(v.checkedAt) >= f.ttl {
+				delete(f.configs, k)
+			}
+		}
+		if len(f.configs) >= maxCachedConfigs {
+			f.configs = map[string]*cachedConfig{}
+		}
+	}
+	f.configs[key] = c
+}
+
+// fetch isn't locked for the time of the request: concurrent analyses of a repo with
+// the expired config can revalidate it at the same time
+func (f *CachingAPIFetcher) fetch(ctx context.Context, key string,
+	getIfChanged func(etag string, cfg *Config) (string, bool, error)) (*Config, error) {
+
+	cached := f.get(key)
+	if cached != nil && f.now().Sub(cached.checkedAt) < f.ttl {
+		return copyConfig(cached.cfg), nil
+	}
+
+	etag := ""
+	if cached != nil {
+		etag = cached.etag
+	}
+
+	var cfg Config
+	newETag, changed, err := getIfChanged(etag, &cfg)
+	if err != nil {
+		if cached == nil {
+			return nil, err
+		}
+
+		analytics.Log(ctx).Warnf("Can't fetch config %s, use the config checked at %s: %s", key, cached.checkedAt, err)
+		return copyConfig(cached.cfg), nil
+	}
+
+	if changed || cached == nil {
+		cached = &cachedConfig{cfg: &cfg}
+	}
+	cached.etag = newETag
+	cached.checkedAt = f.now()
+	f.put(key, cached)
+
+	return copyConfig(cached.cfg), nil
+}
+
+// copyConfig returns a shallow copy: cached configs aren't changed by analyses
+func copyConfig(cfg *Config) *Config {
+	ret := *cfg
+	return &ret
+}
+
+const defaultCacheTTL = time.Minute
+
+var defaultFetcher *CachingAPIFetcher
+var defaultFetcherOnce sync.Once
+
+// Default returns the fetcher shared by analyses of the worker, configs are cached for REPO_CONFIG_CACHE_TTL
+func Default() *CachingAPIFetcher {
+	defaultFetcherOnce.Do(func() {
+		cfg := config.NewEnvConfig(logutil.NewStderrLog("repoconfig"))
+		defaultFetcher = NewCachingAPIFetcher(httputils.GrequestsClient{}, cfg.GetDuration("REPO_CONFIG_CACHE_TTL", defaultCacheTTL))
+	})
+
+	return defaultFetcher
+}
@@ -0,0 +1,67 @@
+package repoconfig
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachingAPIFetcher(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	now := time.Now()
+	client := httputils.NewMockClient(ctrl)
+	f := NewCachingAPIFetcher(client, time.Minute)
+	f.now = func() time.Time { return now }
+
+	repo := &github.Repo{Owner: "org", Name: "repo"}
+	url := gomock.Any()
+	client.EXPECT().GetIfNoneMatch(ctx, url, "").Return(nil, "", errors.New("api is down"))
+	_, err := f.FetchRepoConfig(ctx, repo)
+	assert.Error(t, err, "nothing was fetched before")
+
+	client.EXPECT().GetIfNoneMatch(ctx, url, "").
+		Return(ioutil.NopCloser(strings.NewReader(`{"DryRun": true}`)), `"v1"`, nil)
+	cfg, err := f.FetchRepoConfig(ctx, repo)
+	assert.NoError(t, err)
+	assert.Equal(t, &Config{DryRun: Bool(true)}, cfg)
+
+	cfg.DryRun = Bool(false)
+	cfg, err = f.FetchRepoConfig(ctx, repo)
+	assert.NoError(t, err)
+	assert.Equal(t, &Config{DryRun: Bool(true)}, cfg, "cached and not changed by the caller")
+
+	now = now.Add(2 * time.Minute)
+	client.EXPECT().GetIfNoneMatch(ctx, url, `"v1"`).Return(nil, `"v1"`, nil)
+	cfg, err = f.FetchRepoConfig(ctx, repo)
+	assert.NoError(t, err)
+	assert.Equal(t, &Config{DryRun: Bool(true)}, cfg, "revalidated")
+
+	now = now.Add(2 * time.Minute)
+	client.EXPECT().GetIfNoneMatch(ctx, url, `"v1"`).Return(nil, "", errors.New("api is down"))
+	cfg, err = f.FetchRepoConfig(ctx, repo)
+	assert.NoError(t, err)
+	assert.Equal(t, &Config{DryRun: Bool(true)}, cfg, "stale")
+
+	client.EXPECT().GetIfNoneMatch(ctx, url, `"v1"`).
+		Return(ioutil.NopCloser(strings.NewReader(`{"SpellCheck": true}`)), `"v2"`, nil)
+	cfg, err = f.FetchRepoConfig(ctx, repo)
+	assert.NoError(t, err)
+	assert.Equal(t, &Config{SpellCheck: Bool(true)}, cfg, "changed")
+
+	client.EXPECT().GetIfNoneMatch(ctx, url, "").
+		Return(ioutil.NopCloser(strings.NewReader(`{"AttributeCommits": true}`)), `"o1"`, nil)
+	cfg, err = f.FetchOrgDefaults(ctx, "org")
+	assert.NoError(t, err)
+	assert.Equal(t, &Config{AttributeCommits: Bool(true)}, cfg, "org configs are cached separately")
+}
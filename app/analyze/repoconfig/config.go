@@ -0,0 +1,282 @@
+package repoconfig
+
+// Config is a worker-level configuration of analysis: it's set by users on golangci.com
+// for a repo or for a whole organization. Flags are pointers: unset flags of repos don't override
+// flags of organizations, a repo can turn off a flag of its organization by false.
+type Config struct {
+	// RequiredLinters are enabled in addition to linters from .golangci.yml
+	RequiredLinters []string `json:",omitempty"`
+
+	// CommentTemplate is a text/template for review comments, see reporters.CommentData
+	CommentTemplate string `json:",omitempty"`
+
+	// SkipPaths are globs (e.g. docs/**, *.md, vendor/**): analysis is skipped if PR changes only matching paths
+	SkipPaths []string `json:",omitempty"`
+
+	// SkipDirs are globs of directories (e.g. gen, internal/legacy/**): issues in their files are dropped
+	// whatever linter reported them
+	SkipDirs []string `json:",omitempty"`
+
+	// SkipFiles are globs of files (e.g. *_mock.go, **/*.pb.go): their issues are dropped like ones of SkipDirs
+	SkipFiles []string `json:",omitempty"`
+
+	// DryRun runs analysis without posting comments and statuses: they are recorded into result json.
+	// It's used to preview the noise level before enabling the bot for an organization.
+	DryRun *bool `json:",omitempty"`
+
+	// AttributeCommits attributes every issue to the commit of the PR introduced it (by git blame):
+	// the commit is shown in comments, it helps authors of stacked-commit PRs.
+	AttributeCommits *bool `json:",omitempty"`
+
+	// DependencyFreshness reports outdated, deprecated and archived direct dependencies as informational
+	// issues: in repo analyses and in PRs changing go.mod
+	DependencyFreshness *bool `json:",omitempty"`
+
+	// APICompatibility reports breaking changes of the exported API of packages changed by PRs:
+	// it's a gate for libraries, issues fail the commit status
+	APICompatibility *bool `json:",omitempty"`
+
+	// PostReleaseReport posts the release readiness report of tag analyses into the body of the GitHub Release
+	// of the tag: the report is saved into the analysis result anyway
+	PostReleaseReport *bool `json:",omitempty"`
+
+	// SpellCheck enables misspell and golint: typos and comments of exported declarations are reported,
+	// project-specific words can be listed in .golangci-dictionary.txt of the repo
+	SpellCheck *bool `json:",omitempty"`
+
+	// VerifyGenerate runs go generate after linters: files changed by it are reported as stale,
+	// only files changed by the PR fail the commit status
+	VerifyGenerate *bool `json:",omitempty"`
+
+	// SecretScan reports potential leaked credentials in lines added by PRs (the secretscan plugin must be enabled),
+	// it's opt-in: generic rules have false positives
+	SecretScan *bool `json:",omitempty"`
+
+	// FormatPolicy is enforced in PRs: changed code not formatted by its formatters is reported
+	// with suggested fixes
+	FormatPolicy *FormatPolicy `json:",omitempty"`
+
+	// ReviewPolicy selects events of reviews posted by the bot, reviews are neutral comments if it's nil
+	ReviewPolicy *ReviewPolicy `json:",omitempty"`
+
+	// QualityGate fails the commit status only if the weighted score of issues exceeds the threshold,
+	// any blocking issue fails the status if it's nil
+	QualityGate *QualityGate `json:",omitempty"`
+
+	// SBOM of analyzed commits of PRs and the default branch is uploaded to the artifact store
+	// (ARTIFACTS_DIR) if it's set, dependencies are listed only for repos using go modules
+	SBOM *SBOMPolicy `json:",omitempty"`
+
+	// ExplainIssues appends a one-line explanation and a link to the rule documentation to review comments
+	ExplainIssues *bool `json:",omitempty"`
+
+	// Projects of a monorepo are analyzed only if PR touches them and get independent commit statuses
+	Projects []Project `json:",omitempty"`
+
+	// SparseCheckout are dirs of the repo checked out in partial clones of large repos (REPO_PARTIAL_CLONE_FROM_MB):
+	// files of the repo root are checked out too, all files are checked out if it's empty
+	SparseCheckout []string `json:",omitempty"`
+
+	// FriendlyToNewcomers softens reporting for PRs of first-time contributors: issues are listed
+	// in one summary comment without line comments and the commit status doesn't fail
+	FriendlyToNewcomers *bool `json:",omitempty"`
+
+	// NewcomerTemplate is a text/template of the greeting in the summary comment executed with NewcomerData,
+	// the default greeting is used if it's empty
+	NewcomerTemplate string `json:",omitempty"`
+
+	// Language of texts posted to GitHub (statuses, comments and summaries): en, ru, zh or ja.
+	// English is used if it's empty or unsupported, custom templates aren't translated.
+	Language string `json:",omitempty"`
+}
+
+// NewcomerData is passed to a newcomer template
+type NewcomerData struct {
+	Author string // login of the PR author
+}
+
+// FormatPolicy is a list of formatters the code must be formatted by
+type FormatPolicy struct {
+	// Formatters are gofmt, gofumpt or gci
+	Formatters []string
+
+	// LocalPrefix is an import path prefix of packages of the repo: gci groups their imports separately
+	LocalPrefix string `json:",omitempty"`
+}
+
+// SBOMPolicy selects the format of SBOMs
+type SBOMPolicy struct {
+	// Format is cyclonedx (the default) or spdx
+	Format string `json:",omitempty"`
+}
+
+// QualityGate scores issues by their severities: the score is a sum of severities of blocking issues
+type QualityGate struct {
+	// Threshold is the max score of passing analyses
+	Threshold int
+
+	// Severities override default severities of linters (e.g. gosec) or rules (e.g. gosec/G104)
+	Severities map[string]int `json:",omitempty"`
+}
+
+// ReviewPolicy makes reviews of the bot formally block or allow merging. Changes requested by a review
+// block merging until the review is dismissed or the bot approves the PR: RequestChangesSeverity needs Approve
+// to unblock fixed PRs automatically.
+type ReviewPolicy struct {
+	// RequestChangesSeverity requests changes if an issue has at least this severity (see QualityGate),
+	// changes are never requested if it's zero
+	RequestChangesSeverity int `json:",omitempty"`
+
+	// Approve approves PRs without blocking issues
+	Approve bool `json:",omitempty"`
+}
+
+// Project is a part of a monorepo, e.g. a service
+type Project struct {
+	// Name is used in the status context: golangci/{Name}
+	Name string
+
+	// Dir is a subdirectory of the project: only its packages are analyzed
+	Dir string
+
+	// Paths are globs of files outside of Dir affecting the project, e.g. go.mod
+	Paths []string `json:",omitempty"`
+}
+
+// MergeUnder returns config where settings of c override settings of defaults.
+// Required linters and skip paths, dirs and files are merged: repo can't disable linters required by organization.
+// Dry-run can't be disabled by repo too.
+func (c *Config) MergeUnder(defaults *Config) *Config {
+	ret := Config{}
+	if defaults != nil {
+		ret = *defaults
+	}
+	if c == nil {
+		return &ret
+	}
+
+	ret.RequiredLinters = mergeUnique(ret.RequiredLinters, c.RequiredLinters)
+	ret.SkipPaths = mergeUnique(ret.SkipPaths, c.SkipPaths)
+	ret.SkipDirs = mergeUnique(ret.SkipDirs, c.SkipDirs)
+	ret.SkipFiles = mergeUnique(ret.SkipFiles, c.SkipFiles)
+	if c.CommentTemplate != "" {
+		ret.CommentTemplate = c.CommentTemplate
+	}
+	ret.DryRun = mergeFlag(ret.DryRun, c.DryRun)
+	ret.AttributeCommits = mergeFlag(ret.AttributeCommits, c.AttributeCommits)
+	ret.DependencyFreshness = mergeFlag(ret.DependencyFreshness, c.DependencyFreshness)
+	ret.APICompatibility = mergeFlag(ret.APICompatibility, c.APICompatibility)
+	ret.PostReleaseReport = mergeFlag(ret.PostReleaseReport, c.PostReleaseReport)
+	ret.SpellCheck = mergeFlag(ret.SpellCheck, c.SpellCheck)
+	ret.VerifyGenerate = mergeFlag(ret.VerifyGenerate, c.VerifyGenerate)
+	ret.SecretScan = mergeFlag(ret.SecretScan, c.SecretScan)
+	ret.ExplainIssues = mergeFlag(ret.ExplainIssues, c.ExplainIssues)
+	ret.FriendlyToNewcomers = mergeFlag(ret.FriendlyToNewcomers, c.FriendlyToNewcomers)
+	if c.NewcomerTemplate != "" {
+		ret.NewcomerTemplate = c.NewcomerTemplate
+	}
+	if c.Language != "" {
+		ret.Language = c.Language
+	}
+	if c.QualityGate != nil {
+		ret.QualityGate = c.QualityGate
+	}
+	if c.ReviewPolicy != nil {
+		ret.ReviewPolicy = c.ReviewPolicy
+	}
+	if c.FormatPolicy != nil { // the repo policy replaces the organization one: formatters can conflict
+		ret.FormatPolicy = c.FormatPolicy
+	}
+	if c.SBOM != nil {
+		ret.SBOM = c.SBOM
+	}
+	if len(c.Projects) != 0 { // projects are specific to the repo: they aren't merged
+		ret.Projects = c.Projects
+	}
+	if len(c.SparseCheckout) != 0 { // dirs are specific to the repo too
+		ret.SparseCheckout = c.SparseCheckout
+	}
+
+	return &ret
+}
+
+func mergeFlag(defaultValue, value *bool) *bool {
+	if value != nil {
+		return value
+	}
+
+	return defaultValue
+}
+
+// Bool returns a pointer to v, it's used to set flags
+func Bool(v bool) *bool {
+	return &v
+}
+
+func isOn(flag *bool) bool {
+	return flag != nil && *flag
+}
+
+// GetDryRun returns DryRun, unset flags are off
+func (c *Config) GetDryRun() bool {
+	return c != nil && isOn(c.DryRun)
+}
+
+// GetAttributeCommits returns AttributeCommits, unset flags are off
+func (c *Config) GetAttributeCommits() bool {
+	return c != nil && isOn(c.AttributeCommits)
+}
+
+// GetDependencyFreshness returns DependencyFreshness, unset flags are off
+func (c *Config) GetDependencyFreshness() bool {
+	return c != nil && isOn(c.DependencyFreshness)
+}
+
+// GetAPICompatibility returns APICompatibility, unset flags are off
+func (c *Config) GetAPICompatibility() bool {
+	return c != nil && isOn(c.APICompatibility)
+}
+
+// GetPostReleaseReport returns PostReleaseReport, unset flags are off
+func (c *Config) GetPostReleaseReport() bool {
+	return c != nil && isOn(c.PostReleaseReport)
+}
+
+// GetSpellCheck returns SpellCheck, unset flags are off
+func (c *Config) GetSpellCheck() bool {
+	return c != nil && isOn(c.SpellCheck)
+}
+
+// GetVerifyGenerate returns VerifyGenerate, unset flags are off
+func (c *Config) GetVerifyGenerate() bool {
+	return c != nil && isOn(c.VerifyGenerate)
+}
+
+// GetSecretScan returns SecretScan, unset flags are off
+func (c *Config) GetSecretScan() bool {
+	return c != nil && isOn(c.SecretScan)
+}
+
+// GetExplainIssues returns ExplainIssues, unset flags are off
+func (c *Config) GetExplainIssues() bool {
+	return c != nil && isOn(c.ExplainIssues)
+}
+
+// GetFriendlyToNewcomers returns FriendlyToNewcomers, unset flags are off
+func (c *Config) GetFriendlyToNewcomers() bool {
+	return c != nil && isOn(c.FriendlyToNewcomers)
+}
+
+func mergeUnique(a, b []string) []string {
+	seen := map[string]bool{}
+	var ret []string
+	for _, v := range append(append([]string{}, a...), b...) {
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		ret = append(ret, v)
+	}
+
+	return ret
+}
@@ -0,0 +1,109 @@
+package repoconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeUnder(t *testing.T) {
+	org := &Config{
+		RequiredLinters: []string{"govet", "errcheck"},
+		CommentTemplate: "org: {{.Text}}",
+		SkipPaths:       []string{"docs/**"},
+	}
+
+	assert.Equal(t, org, (*Config)(nil).MergeUnder(org))
+	assert.Equal(t, &Config{}, (*Config)(nil).MergeUnder(nil))
+
+	repo := &Config{
+		RequiredLinters: []string{"errcheck", "gocyclo"},
+		SkipPaths:       []string{"*.md"},
+	}
+	assert.Equal(t, &Config{
+		RequiredLinters: []string{"govet", "errcheck", "gocyclo"},
+		CommentTemplate: "org: {{.Text}}",
+		SkipPaths:       []string{"docs/**", "*.md"},
+	}, repo.MergeUnder(org))
+
+	org.SkipDirs, org.SkipFiles = []string{"gen"}, []string{"*.pb.go"}
+	repo.SkipDirs, repo.SkipFiles = []string{"gen", "legacy"}, []string{"*_mock.go"}
+	assert.Equal(t, []string{"gen", "legacy"}, repo.MergeUnder(org).SkipDirs)
+	assert.Equal(t, []string{"*.pb.go", "*_mock.go"}, repo.MergeUnder(org).SkipFiles)
+
+	repo.CommentTemplate = "repo: {{.Text}}"
+	assert.Equal(t, "repo: {{.Text}}", repo.MergeUnder(org).CommentTemplate)
+
+	org.DryRun = Bool(true)
+	assert.True(t, repo.MergeUnder(org).GetDryRun())
+	assert.True(t, (&Config{DryRun: Bool(true)}).MergeUnder(nil).GetDryRun())
+
+	assert.False(t, repo.MergeUnder(org).GetAttributeCommits())
+	repo.AttributeCommits = Bool(true)
+	assert.True(t, repo.MergeUnder(org).GetAttributeCommits())
+
+	org.DependencyFreshness = Bool(true)
+	assert.True(t, repo.MergeUnder(org).GetDependencyFreshness())
+
+	org.APICompatibility = Bool(true)
+	assert.True(t, repo.MergeUnder(org).GetAPICompatibility())
+
+	repo.SpellCheck = Bool(true)
+	assert.True(t, repo.MergeUnder(org).GetSpellCheck())
+
+	org.PostReleaseReport = Bool(true)
+	assert.True(t, repo.MergeUnder(org).GetPostReleaseReport())
+
+	org.VerifyGenerate = Bool(true)
+	assert.True(t, repo.MergeUnder(org).GetVerifyGenerate())
+
+	// false of the repo turns off the flag of the organization, unset flags don't
+	repo.VerifyGenerate = Bool(false)
+	assert.False(t, repo.MergeUnder(org).GetVerifyGenerate())
+	repo.DryRun = Bool(false)
+	assert.False(t, repo.MergeUnder(org).GetDryRun())
+	assert.True(t, (&Config{}).MergeUnder(org).GetDryRun())
+
+	org.FormatPolicy = &FormatPolicy{Formatters: []string{"gofmt"}}
+	assert.Equal(t, org.FormatPolicy, repo.MergeUnder(org).FormatPolicy)
+	repo.FormatPolicy = &FormatPolicy{Formatters: []string{"gofumpt", "gci"}, LocalPrefix: "github.com/golangci"}
+	assert.Equal(t, repo.FormatPolicy, repo.MergeUnder(org).FormatPolicy)
+
+	org.QualityGate = &QualityGate{Threshold: 10}
+	assert.Equal(t, org.QualityGate, repo.MergeUnder(org).QualityGate)
+	repo.QualityGate = &QualityGate{Threshold: 5, Severities: map[string]int{"golint": 0}}
+	assert.Equal(t, repo.QualityGate, repo.MergeUnder(org).QualityGate)
+
+	org.ReviewPolicy = &ReviewPolicy{RequestChangesSeverity: 3, Approve: true}
+	assert.Equal(t, org.ReviewPolicy, repo.MergeUnder(org).ReviewPolicy)
+	repo.ReviewPolicy = &ReviewPolicy{}
+	assert.Equal(t, repo.ReviewPolicy, repo.MergeUnder(org).ReviewPolicy)
+
+	org.SBOM = &SBOMPolicy{}
+	assert.Equal(t, org.SBOM, repo.MergeUnder(org).SBOM)
+	repo.SBOM = &SBOMPolicy{Format: "spdx"}
+	assert.Equal(t, repo.SBOM, repo.MergeUnder(org).SBOM)
+
+	repo.ExplainIssues = Bool(true)
+	assert.True(t, repo.MergeUnder(org).GetExplainIssues())
+
+	org.FriendlyToNewcomers = Bool(true)
+	org.NewcomerTemplate = "Welcome!"
+	assert.True(t, repo.MergeUnder(org).GetFriendlyToNewcomers())
+	assert.Equal(t, "Welcome!", repo.MergeUnder(org).NewcomerTemplate)
+
+	org.Language = "ru"
+	assert.Equal(t, "ru", repo.MergeUnder(org).Language)
+	repo.Language = "ja"
+	assert.Equal(t, "ja", repo.MergeUnder(org).Language)
+
+	org.Projects = []Project{{Name: "org"}}
+	assert.Equal(t, org.Projects, repo.MergeUnder(org).Projects)
+	repo.Projects = []Project{{Name: "api", Dir: "api"}}
+	assert.Equal(t, repo.Projects, repo.MergeUnder(org).Projects)
+
+	org.SparseCheckout = []string{"vendor"}
+	assert.Equal(t, org.SparseCheckout, repo.MergeUnder(org).SparseCheckout)
+	repo.SparseCheckout = []string{"services/api", "pkg"}
+	assert.Equal(t, repo.SparseCheckout, repo.MergeUnder(org).SparseCheckout)
+}
@@ -0,0 +1,63 @@
+package repoconfig
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/lib/apiclient"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/pkg/errors"
+)
+
+//go:generate mockgen -package repoconfig -source fetcher.go -destination fetcher_mock.go
+
+type Fetcher interface {
+	FetchOrgDefaults(ctx context.Context, owner string) (*Config, error)
+	FetchRepoConfig(ctx context.Context, repo *github.Repo) (*Config, error)
+}
+
+type APIFetcher struct {
+	api *apiclient.Client
+}
+
+func NewAPIFetcher(client httputils.Client) *APIFetcher {
+	return &APIFetcher{
+		api: apiclient.New(client),
+	}
+}
+
+func (f APIFetcher) FetchOrgDefaults(ctx context.Context, owner string) (*Config, error) {
+	var cfg Config
+	if err := f.api.GetOrgConfig(ctx, owner, &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func (f APIFetcher) FetchRepoConfig(ctx context.Context, repo *github.Repo) (*Config, error) {
+	var cfg Config
+	if err := f.api.GetRepoConfig(ctx, repo.Owner, repo.Name, &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// Load fetches repo config and merges it over organization defaults.
+// Analysis mustn't fail because of config fetching errors: they are returned along with the best config we have.
+func Load(ctx context.Context, f Fetcher, repo *github.Repo) (*Config, error) {
+	var retErr error
+
+	orgCfg, err := f.FetchOrgDefaults(ctx, repo.Owner)
+	if err != nil {
+		retErr = errors.Wrapf(err, "failed to fetch defaults of organization %s", repo.Owner)
+	}
+
+	repoCfg, err := f.FetchRepoConfig(ctx, repo)
+	if err != nil && retErr == nil {
+		retErr = errors.Wrapf(err, "failed to fetch config of repo %s", repo.FullName())
+	}
+
+	return repoCfg.MergeUnder(orgCfg), retErr
+}
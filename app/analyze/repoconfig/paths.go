@@ -0,0 +1,123 @@
+package repoconfig
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// MatchPath reports whether the slash-separated file path matches the glob pattern.
+// "**" matches any number of directories, "*" and "?" don't match "/".
+// Pattern without "/" matches a file name in any directory, like in .gitignore.
+func MatchPath(pattern, filePath string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		filePath = path.Base(filePath)
+	}
+
+	re, err := globToRegexp(pattern)
+	if err != nil {
+		return false
+	}
+
+	return re.MatchString(filePath)
+}
+
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' { // "**/" matches zero or more directories
+					i++
+					sb.WriteString("(.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
+
+// IsTouched reports whether any of files is in the dir of the project or matches its paths
+func (p Project) IsTouched(files []string) bool {
+	dir := strings.Trim(path.Clean(p.Dir), "/") + "/"
+	for _, f := range files {
+		if dir == "./" || strings.HasPrefix(f, dir) {
+			return true
+		}
+
+		for _, pattern := range p.Paths {
+			if MatchPath(pattern, f) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// AllPathsSkipped reports whether every file matches at least one of skip patterns:
+// there is nothing to analyze then.
+func (c *Config) AllPathsSkipped(files []string) bool {
+	if c == nil || len(c.SkipPaths) == 0 || len(files) == 0 {
+		return false
+	}
+
+	for _, f := range files {
+		skipped := false
+		for _, p := range c.SkipPaths {
+			if MatchPath(p, f) {
+				skipped = true
+				break
+			}
+		}
+
+		if !skipped {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsFileSkipped reports whether issues of the file must be dropped: the file matches skip files
+// or any of its parent directories matches skip dirs
+func (c *Config) IsFileSkipped(filePath string) bool {
+	if c == nil {
+		return false
+	}
+
+	for _, p := range c.SkipFiles {
+		if MatchPath(p, filePath) {
+			return true
+		}
+	}
+
+	if len(c.SkipDirs) == 0 {
+		return false
+	}
+	for dir := path.Dir(filePath); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		for _, p := range c.SkipDirs {
+			p = strings.TrimSuffix(strings.TrimSuffix(p, "/**"), "/") // dirs match with all their contents
+			if MatchPath(p, dir) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
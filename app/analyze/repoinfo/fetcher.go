@@ -8,6 +8,7 @@ import (
 	"github.com/golangci/golangci-worker/app/analytics"
 	"github.com/golangci/golangci-worker/app/lib/executors"
 	"github.com/golangci/golangci-worker/app/lib/fetchers"
+	"github.com/golangci/golangci-worker/app/lib/redact"
 	"github.com/pkg/errors"
 )
 
@@ -20,6 +21,9 @@ type Info struct {
 
 type Fetcher interface {
 	Fetch(ctx context.Context, repo *fetchers.Repo, exec executors.Executor) (*Info, error)
+
+	// FetchMetadata detects metadata of the repo already checked out into the work dir
+	FetchMetadata(ctx context.Context, exec executors.Executor) (*Metadata, error)
 }
 
 type CloningFetcher struct {
@@ -35,10 +39,10 @@ func NewCloningFetcher(repoFetcher fetchers.Fetcher) *CloningFetcher {
 func (f CloningFetcher) Fetch(ctx context.Context, repo *fetchers.Repo, exec executors.Executor) (*Info, error) {
 	// fetch into the current dir
 	if err := f.repoFetcher.Fetch(ctx, repo, exec); err != nil {
-		return nil, errors.Wrapf(err, "failed to fetch repo ref %q by url %q", repo.Ref, repo.CloneURL)
+		return nil, errors.Wrapf(err, "failed to fetch repo ref %q by url %q", repo.Ref, redact.URLCredentials(repo.CloneURL))
 	}
 
-	out, err := exec.Run(ctx, "getrepoinfo", "--repo", repo.FullPath)
+	out, err := executors.Unrestricted(exec).Run(ctx, "getrepoinfo", "--repo", repo.FullPath)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to run 'getrepoinfo --repo %s'", repo.FullPath)
 	}
@@ -54,3 +58,7 @@ func (f CloningFetcher) Fetch(ctx context.Context, repo *fetchers.Repo, exec exe
 
 	return &ret, nil
 }
+
+func (f CloningFetcher) FetchMetadata(ctx context.Context, exec executors.Executor) (*Metadata, error) {
+	return DetectMetadata(ctx, exec)
+}
@@ -1,6 +1,7 @@
 // Code generated by MockGen. DO NOT EDIT.
 // Source: fetcher.go
 
+// Package repoinfo is a generated GoMock package.
 package repoinfo
 
 import (
@@ -30,19 +31,32 @@ func NewMockFetcher(ctrl *gomock.Controller) *MockFetcher {
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use
-func (_m *MockFetcher) EXPECT() *MockFetcherMockRecorder {
-	return _m.recorder
+func (m *MockFetcher) EXPECT() *MockFetcherMockRecorder {
+	return m.recorder
 }
 
 // Fetch mocks base method
-func (_m *MockFetcher) Fetch(ctx context.Context, repo *fetchers.Repo, exec executors.Executor) (*Info, error) {
-	ret := _m.ctrl.Call(_m, "Fetch", ctx, repo, exec)
+func (m *MockFetcher) Fetch(ctx context.Context, repo *fetchers.Repo, exec executors.Executor) (*Info, error) {
+	ret := m.ctrl.Call(m, "Fetch", ctx, repo, exec)
 	ret0, _ := ret[0].(*Info)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Fetch indicates an expected call of Fetch
-func (_mr *MockFetcherMockRecorder) Fetch(arg0, arg1, arg2 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "Fetch", reflect.TypeOf((*MockFetcher)(nil).Fetch), arg0, arg1, arg2)
+func (mr *MockFetcherMockRecorder) Fetch(ctx, repo, exec interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fetch", reflect.TypeOf((*MockFetcher)(nil).Fetch), ctx, repo, exec)
+}
+
+// FetchMetadata mocks base method
+func (m *MockFetcher) FetchMetadata(ctx context.Context, exec executors.Executor) (*Metadata, error) {
+	ret := m.ctrl.Call(m, "FetchMetadata", ctx, exec)
+	ret0, _ := ret[0].(*Metadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchMetadata indicates an expected call of FetchMetadata
+func (mr *MockFetcherMockRecorder) FetchMetadata(ctx, exec interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchMetadata", reflect.TypeOf((*MockFetcher)(nil).FetchMetadata), ctx, exec)
 }
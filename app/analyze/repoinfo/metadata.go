@@ -0,0 +1,224 @@
+package repoinfo
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/lib/executors"
+)
+
+// Metadata is detected in the checked-out repo: timeout policy, executor selection and
+// linters set depend on these signals
+type Metadata struct {
+	ModulePath string `json:",omitempty"` // empty if the repo doesn't use go modules
+	GoVersion  string `json:",omitempty"` // go directive of go.mod
+
+	UsesCgo   bool
+	HasVendor bool
+
+	// LOC is the number of lines of go files excluding vendor
+	LOC int
+
+	// Frameworks are hints detected by requirements of go.mod, e.g. grpc
+	Frameworks []string `json:",omitempty"`
+
+	// OtherLOC are numbers of lines of code of other languages by language
+	OtherLOC map[string]int `json:",omitempty"`
+}
+
+// languageExtensions are extensions of code files of languages other than go
+var languageExtensions = map[string]string{
+	".py":    "python",
+	".js":    "javascript",
+	".ts":    "typescript",
+	".java":  "java",
+	".kt":    "kotlin",
+	".rb":    "ruby",
+	".php":   "php",
+	".c":     "c",
+	".h":     "c",
+	".cc":    "c++",
+	".cpp":   "c++",
+	".hpp":   "c++",
+	".cs":    "c#",
+	".rs":    "rust",
+	".swift": "swift",
+	".scala": "scala",
+}
+
+// GoShare is a share of go code in lines of code of the repo, it's 1 if no code was detected
+func (m *Metadata) GoShare() float64 {
+	total := m.LOC
+	for _, loc := range m.OtherLOC {
+		total += loc
+	}
+	if total == 0 {
+		return 1
+	}
+
+	return float64(m.LOC) / float64(total)
+}
+
+// PrimaryLanguage is the language with the most lines of code, go wins ties
+func (m *Metadata) PrimaryLanguage() string {
+	var langs []string
+	for lang := range m.OtherLOC {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	ret, maxLOC := "go", m.LOC
+	for _, lang := range langs {
+		if m.OtherLOC[lang] > maxLOC {
+			ret, maxLOC = lang, m.OtherLOC[lang]
+		}
+	}
+
+	return ret
+}
+
+// frameworkModules are module path prefixes of frameworks
+var frameworkModules = map[string]string{
+	"github.com/gin-gonic/gin":    "gin",
+	"github.com/labstack/echo":    "echo",
+	"github.com/gorilla/mux":      "gorilla",
+	"github.com/go-kit/kit":       "go-kit",
+	"github.com/spf13/cobra":      "cobra",
+	"google.golang.org/grpc":      "grpc",
+	"k8s.io/client-go":            "kubernetes",
+	"github.com/gofiber/fiber":    "fiber",
+	"github.com/beego/beego":      "beego",
+	"github.com/astaxie/beego":    "beego",
+	"github.com/go-chi/chi":       "chi",
+	"github.com/valyala/fasthttp": "fasthttp",
+}
+
+func (m *Metadata) EventProps() map[string]interface{} {
+	return map[string]interface{}{
+		"goModules":  m.ModulePath != "",
+		"goVersion":  m.GoVersion,
+		"usesCgo":    m.UsesCgo,
+		"hasVendor":  m.HasVendor,
+		"loc":        m.LOC,
+		"frameworks": strings.Join(m.Frameworks, ","),
+		"goShare":    m.GoShare(),
+		"language":   m.PrimaryLanguage(),
+	}
+}
+
+// DetectMetadata detects metadata of the repo in the work dir of the executor
+func DetectMetadata(ctx context.Context, exec executors.Executor) (*Metadata, error) {
+	var m Metadata
+	if goMod, err := executors.Unrestricted(exec).Run(ctx, "cat", "go.mod"); err == nil { // no go.mod isn't an error
+		parseGoMod(goMod, &m)
+	}
+
+	out, err := executors.Unrestricted(exec).Run(ctx, "find", ".", "-maxdepth", "1", "-name", "vendor", "-type", "d")
+	if err != nil {
+		return nil, fmt.Errorf("can't find vendor: %s, %s", err, out)
+	}
+	m.HasVendor = strings.TrimSpace(out) != ""
+
+	// grep exits with 1 if nothing matches: it isn't an error
+	out, _ = executors.Unrestricted(exec).Run(ctx, "grep", "-r", "-c", `"--include=*.go"`, "--exclude-dir=vendor", `""`, ".")
+	m.LOC = sumCounts(out)
+
+	out, _ = executors.Unrestricted(exec).Run(ctx, "grep", "-r", "-l", `"--include=*.go"`, "--exclude-dir=vendor", `"^import \"C\""`, ".")
+	m.UsesCgo = strings.TrimSpace(out) != ""
+
+	args := []string{"-r", "-c"}
+	for _, ext := range sortedExtensions() {
+		args = append(args, fmt.Sprintf(`"--include=*%s"`, ext))
+	}
+	args = append(args, "--exclude-dir=vendor", "--exclude-dir=node_modules", `""`, ".")
+	out, _ = executors.Unrestricted(exec).Run(ctx, "grep", args...)
+	m.OtherLOC = sumCountsByLanguage(out)
+
+	return &m, nil
+}
+
+func parseGoMod(goMod string, m *Metadata) {
+	frameworks := map[string]bool{}
+	for _, line := range strings.Split(goMod, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case fields[0] == "module" && len(fields) == 2:
+			m.ModulePath = strings.Trim(fields[1], `"`)
+		case fields[0] == "go" && len(fields) == 2:
+			m.GoVersion = fields[1]
+		default:
+			if fields[0] == "require" && len(fields) > 1 {
+				fields = fields[1:] // one-line require
+			}
+			for prefix, name := range frameworkModules {
+				if strings.HasPrefix(fields[0], prefix) {
+					frameworks[name] = true
+				}
+			}
+		}
+	}
+
+	for name := range frameworks {
+		m.Frameworks = append(m.Frameworks, name)
+	}
+	sort.Strings(m.Frameworks)
+}
+
+// sumCounts sums counts of grep -c output: path:count lines
+func sumCounts(out string) int {
+	sum := 0
+	for _, line := range strings.Split(out, "\n") {
+		i := strings.LastIndexByte(line, ':')
+		if i == -1 {
+			continue
+		}
+		if n, err := strconv.Atoi(line[i+1:]); err == nil {
+			sum += n
+		}
+	}
+
+	return sum
+}
+
+func sortedExtensions() []string {
+	var ret []string
+	for ext := range languageExtensions {
+		ret = append(ret, ext)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// sumCountsByLanguage sums counts of grep -c output by languages of extensions of files
+func sumCountsByLanguage(out string) map[string]int {
+	var ret map[string]int
+	for _, line := range strings.Split(out, "\n") {
+		i := strings.LastIndexByte(line, ':')
+		if i == -1 {
+			continue
+		}
+		n, err := strconv.Atoi(line[i+1:])
+		if err != nil || n == 0 {
+			continue
+		}
+
+		lang, ok := languageExtensions[path.Ext(line[:i])]
+		if !ok {
+			continue
+		}
+		if ret == nil {
+			ret = map[string]int{}
+		}
+		ret[lang] += n
+	}
+
+	return ret
+}
@@ -0,0 +1,61 @@
+package repoinfo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGoMod(t *testing.T) {
+	goMod := `module github.com/owner/name
+
+go 1.12
+
+require (
+	github.com/gin-gonic/gin v1.3.0
+	google.golang.org/grpc v1.16.0
+	github.com/pkg/errors v0.8.0
+)
+
+require github.com/spf13/cobra v0.0.3
+`
+	var m Metadata
+	parseGoMod(goMod, &m)
+	assert.Equal(t, Metadata{
+		ModulePath: "github.com/owner/name",
+		GoVersion:  "1.12",
+		Frameworks: []string{"cobra", "gin", "grpc"},
+	}, m)
+}
+
+func TestDetectMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Run(ctx, "cat", "go.mod").Return("", errFake)
+	exec.EXPECT().Run(ctx, "find", ".", "-maxdepth", "1", "-name", "vendor", "-type", "d").Return("./vendor", nil)
+	exec.EXPECT().Run(ctx, "grep", "-r", "-c", gomock.Any(), gomock.Any(), `""`, ".").Return("./a.go:10\n./b/b.go:32", nil)
+	exec.EXPECT().Run(ctx, "grep", "-r", "-l", gomock.Any(), gomock.Any(), gomock.Any(), ".").Return("", errFake)
+	exec.EXPECT().Run(ctx, "grep", gomock.Any()).Return("./web/app.js:100\n./web/lib.ts:0\n./x.py:8\n./y.py:2", nil)
+
+	m, err := DetectMetadata(ctx, exec)
+	assert.NoError(t, err)
+	assert.Equal(t, &Metadata{HasVendor: true, LOC: 42, OtherLOC: map[string]int{"javascript": 100, "python": 10}}, m)
+	assert.Equal(t, "javascript", m.PrimaryLanguage())
+	assert.InDelta(t, 42.0/152, m.GoShare(), 0.001)
+}
+
+func TestGoShare(t *testing.T) {
+	assert.Equal(t, 1.0, (&Metadata{}).GoShare(), "no code")
+	assert.Equal(t, 1.0, (&Metadata{LOC: 10}).GoShare())
+	assert.Equal(t, 0.25, (&Metadata{LOC: 10, OtherLOC: map[string]int{"python": 10, "ruby": 20}}).GoShare())
+	assert.Equal(t, "go", (&Metadata{LOC: 10, OtherLOC: map[string]int{"python": 10}}).PrimaryLanguage())
+}
+
+var errFake = errors.New("exit status 1")
@@ -0,0 +1,44 @@
+package reporters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+)
+
+// FormatActionsAnnotations formats issues as workflow commands of GitHub Actions:
+// commands printed to stdout of a step are shown as native annotations of files.
+// Informational issues are warnings, other issues are errors.
+func FormatActionsAnnotations(issues []result.Issue) []string {
+	var ret []string
+	for _, i := range issues {
+		level := "error"
+		if i.Informational {
+			level = "warning"
+		}
+
+		props := []string{"file=" + escapeActionsProperty(i.File)}
+		if i.LineNumber > 0 {
+			props = append(props, fmt.Sprintf("line=%d", i.LineNumber))
+		}
+		if i.FromLinter != "" {
+			props = append(props, "title="+escapeActionsProperty(i.FromLinter))
+		}
+
+		ret = append(ret, fmt.Sprintf("::%s %s::%s", level, strings.Join(props, ","), escapeActionsData(i.Text)))
+	}
+
+	return ret
+}
+
+var actionsDataEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+var actionsPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+func escapeActionsData(s string) string {
+	return actionsDataEscaper.Replace(s)
+}
+
+func escapeActionsProperty(s string) string {
+	return actionsPropertyEscaper.Replace(s)
+}
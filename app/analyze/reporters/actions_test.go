@@ -0,0 +1,20 @@
+package reporters
+
+import (
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatActionsAnnotations(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "govet", File: "a.go", LineNumber: 7, Text: "printf: wrong verb %d\nin call"},
+		{FromLinter: "depsfreshness", File: "dir,1/go.mod", Text: "outdated: 100%", Informational: true},
+	}
+
+	assert.Equal(t, []string{
+		"::error file=a.go,line=7,title=govet::printf: wrong verb %25d%0Ain call",
+		"::warning file=dir%2C1/go.mod,title=depsfreshness::outdated: 100%25",
+	}, FormatActionsAnnotations(issues))
+}
@@ -1,23 +1,82 @@
 package reporters
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
+	"text/template"
 
 	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/analyze/linters/lintdocs"
 	"github.com/golangci/golangci-worker/app/analyze/linters/result"
 	"github.com/golangci/golangci-worker/app/lib/github"
-	gh "github.com/google/go-github/github"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
 )
 
+type GithubReviewerOptions struct {
+	IncludeLinterName bool
+
+	// CommentTemplate is a text/template executed with CommentData, the default comment format is used if it's empty
+	CommentTemplate string
+
+	// Docs are used to explain issues in comments, issues aren't explained if it's nil
+	Docs *lintdocs.Registry
+
+	// Lang is the language of texts the reviewer adds to comments, see i18n.ParseLang
+	Lang string
+
+	// RequestChangesSeverity makes reviews REQUEST_CHANGES if an issue has at least this severity,
+	// reviews are neutral comments if it's zero
+	RequestChangesSeverity int
+
+	// Severity of an issue, every issue has severity 1 if it's nil
+	Severity func(i *result.Issue) int
+
+	// Approve posts an approving review if there are no blocking issues
+	Approve bool
+}
+
+// CommentData is passed to a comment template
+type CommentData struct {
+	Text       string
+	FromLinter string
+	File       string
+	Line       int
+	Commit     string // empty if the issue isn't attributed to a commit
+
+	// DocURL and Explanation of the rule are empty if issues explaining is disabled or there are no docs
+	DocURL      string
+	Explanation string
+}
+
 type GithubReviewer struct {
 	*github.Context
-	client            github.Client
-	includeLinterName bool
+	client github.Client
+	opts   GithubReviewerOptions
+	msg    i18n.Printer
+
+	// summary is the body of the review, it's posted only with comments: not on every push
+	summary []string
+
+	// summaryOnly lists issues in the summary instead of line comments
+	summaryOnly bool
+}
+
+// AddSummary adds markdown text to the summary comment of the review
+func (gr *GithubReviewer) AddSummary(text string) {
+	gr.summary = append(gr.summary, text)
+}
+
+// UseSummaryOnly makes the reviewer list issues in the summary comment without line comments:
+// it's less intimidating, e.g. for first-time contributors
+func (gr *GithubReviewer) UseSummaryOnly() {
+	gr.summaryOnly = true
 }
 
-func NewGithubReviewer(c *github.Context, client github.Client, includeLinterName bool) *GithubReviewer {
+func NewGithubReviewer(c *github.Context, client github.Client, opts GithubReviewerOptions) *GithubReviewer {
 	accessToken := os.Getenv("GITHUB_REVIEWER_ACCESS_TOKEN")
 	if accessToken != "" { // review as special user
 		cCopy := *c
@@ -25,23 +84,44 @@ func NewGithubReviewer(c *github.Context, client github.Client, includeLinterNam
 		c = &cCopy
 	}
 	ret := &GithubReviewer{
-		Context:           c,
-		client:            client,
-		includeLinterName: includeLinterName,
+		Context: c,
+		client:  client,
+		opts:    opts,
+		msg:     i18n.NewPrinter(opts.Lang),
 	}
 	return ret
 }
 
+// fingerprintMarker is a hidden part of comments: issues are recognized after force-pushes by it
+const fingerprintMarker = "<!-- golangci fingerprint: %s -->"
+
+var fingerprintRe = regexp.MustCompile(`<!-- golangci fingerprint: ([0-9a-f]+) -->`)
+
+// CommentFingerprint returns the fingerprint of the issue of the review comment, it's empty for other comments
+func CommentFingerprint(body string) string {
+	if m := fingerprintRe.FindStringSubmatch(body); m != nil {
+		return m[1]
+	}
+
+	return ""
+}
+
 type existingComment struct {
-	file string
-	line int
+	file        string
+	line        int // zero for comments on outdated code
+	side        github.Side
+	fingerprint string // empty for comments without the marker
 }
 
 type existingComments []existingComment
 
-func (ecs existingComments) contains(i *result.Issue) bool {
+func (ecs existingComments) contains(i *result.Issue, fingerprint string) bool {
+	line, side := issueAnchor(i)
 	for _, c := range ecs {
-		if c.file == i.File && c.line == i.HunkPos {
+		if c.fingerprint != "" && c.fingerprint == fingerprint {
+			return true
+		}
+		if c.line != 0 && c.file == i.File && c.line == line && c.side == side {
 			return true
 		}
 	}
@@ -49,6 +129,16 @@ func (ecs existingComments) contains(i *result.Issue) bool {
 	return false
 }
 
+// issueAnchor returns a line and a side of the diff to anchor a comment to:
+// unlike diff positions they don't change after next pushes
+func issueAnchor(i *result.Issue) (int, github.Side) {
+	if i.DeletedLine > 0 {
+		return i.DeletedLine, github.SideLeft
+	}
+
+	return i.LineNumber, github.SideRight
+}
+
 func (gr GithubReviewer) fetchExistingComments(ctx context.Context) (existingComments, error) {
 	comments, err := gr.client.GetPullRequestComments(ctx, gr.Context)
 	if err != nil {
@@ -57,56 +147,280 @@ func (gr GithubReviewer) fetchExistingComments(ctx context.Context) (existingCom
 
 	var ret existingComments
 	for _, c := range comments {
-		if c.Position == nil { // comment on outdated code, skip it
-			continue
+		ec := existingComment{
+			file: c.Path,
+			line: c.Line,
+			side: c.Side,
+		}
+		if ec.side == "" {
+			ec.side = github.SideRight
 		}
-		ret = append(ret, existingComment{
-			file: c.GetPath(),
-			line: c.GetPosition(),
-		})
+		ec.fingerprint = CommentFingerprint(c.Body)
+
+		if ec.line == 0 && ec.fingerprint == "" {
+			continue // comment on outdated code can't be matched without a fingerprint
+		}
+		ret = append(ret, ec)
 	}
 
 	return ret, nil
 }
 
+func (gr GithubReviewer) buildCommentText(ctx context.Context, i *result.Issue) string {
+	doc, hasDoc := gr.opts.Docs.Lookup(i.FromLinter, i.Rule)
+	if gr.opts.CommentTemplate != "" {
+		text, err := executeCommentTemplate(gr.opts.CommentTemplate, i, doc)
+		if err == nil {
+			return text
+		}
+		analytics.Log(ctx).Warnf("Failed to execute comment template %q: %s", gr.opts.CommentTemplate, err)
+	}
+
+	text := i.Text
+	if gr.opts.IncludeLinterName && i.FromLinter != "" {
+		text += gr.msg.Sprintf(i18n.CommentFromLinter, i.FromLinter)
+	}
+	if i.Commit != "" {
+		text += gr.msg.Sprintf(i18n.CommentIntroducedIn, shortSHA(i.Commit))
+	}
+	if hasDoc {
+		text += "\n\n" + gr.explanationText(doc)
+	}
+
+	return text
+}
+
+// suggestionText formats the replacement as a suggestion: GitHub shows a button to commit it
+func suggestionText(i *result.Issue) string {
+	if i.Replacement == nil {
+		return ""
+	}
+
+	var body string
+	for _, l := range i.Replacement.NewLines {
+		body += l + "\n"
+	}
+
+	return "\n\n```suggestion\n" + body + "```" // an empty suggestion deletes lines
+}
+
+// relatedText links other locations of the issue at the commit, e.g. the other copy of duplicated code
+func (gr GithubReviewer) relatedText(ref string, i *result.Issue) string {
+	if len(i.Related) == 0 {
+		return ""
+	}
+
+	var links []string
+	for _, l := range i.Related {
+		url := fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s#L%d-L%d",
+			gr.Repo.Owner, gr.Repo.Name, ref, l.File, l.Lines.From, l.Lines.To)
+		links = append(links, fmt.Sprintf("[`%s:%d-%d`](%s)", l.File, l.Lines.From, l.Lines.To, url))
+	}
+
+	return "\n\n" + gr.msg.Sprintf(i18n.CommentSeeAlso, strings.Join(links, ", "))
+}
+
+func (gr GithubReviewer) explanationText(doc lintdocs.Doc) string {
+	link := fmt.Sprintf("[%s](%s)", gr.msg.Sprintf(i18n.CommentExplain), doc.URL)
+	if doc.Explanation == "" {
+		return link
+	}
+
+	return doc.Explanation + " " + link
+}
+
+func executeCommentTemplate(tpl string, i *result.Issue, doc lintdocs.Doc) (string, error) {
+	t, err := template.New("comment").Parse(tpl)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	data := CommentData{
+		Text:        i.Text,
+		FromLinter:  i.FromLinter,
+		File:        i.File,
+		Line:        i.LineNumber,
+		Commit:      i.Commit,
+		DocURL:      doc.URL,
+		Explanation: doc.Explanation,
+	}
+	if err = t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func shortSHA(sha string) string {
+	const shortLen = 7 // github links short SHAs in comments
+	if len(sha) <= shortLen {
+		return sha
+	}
+
+	return sha[:shortLen]
+}
+
+func (gr GithubReviewer) severity(i *result.Issue) int {
+	if gr.opts.Severity == nil {
+		return 1
+	}
+
+	return gr.opts.Severity(i)
+}
+
+// reviewEvent requests changes for severe issues, reviews of first-time contributors are always comments
+func (gr GithubReviewer) reviewEvent(issues []result.Issue) string {
+	if gr.opts.RequestChangesSeverity <= 0 || gr.summaryOnly {
+		return github.ReviewEventComment
+	}
+
+	for ind := range issues {
+		if !issues[ind].Informational && gr.severity(&issues[ind]) >= gr.opts.RequestChangesSeverity {
+			return github.ReviewEventRequestChanges
+		}
+	}
+
+	return github.ReviewEventComment
+}
+
+// reviewBody is the summary of the review: GitHub requires a body for requested changes
+func (gr GithubReviewer) reviewBody(event string, extra ...string) string {
+	summary := append(append([]string{}, gr.summary...), extra...)
+	if event == github.ReviewEventRequestChanges && len(gr.summary) == 0 {
+		summary = append([]string{gr.msg.Sprintf(i18n.SummaryChangesRequested)}, summary...)
+	}
+
+	return strings.Join(summary, "\n\n")
+}
+
+// hasBlockingIssues reports whether issues prevent approving: issues block if they meet RequestChangesSeverity,
+// any not informational issue blocks if changes are never requested
+func (gr GithubReviewer) hasBlockingIssues(issues []result.Issue) bool {
+	for ind := range issues {
+		if issues[ind].Informational {
+			continue
+		}
+
+		if gr.opts.RequestChangesSeverity <= 0 || gr.severity(&issues[ind]) >= gr.opts.RequestChangesSeverity {
+			return true
+		}
+	}
+
+	return false
+}
+
+// approvalMarker is a hidden part of approving reviews: it tells them from approvals of people
+const approvalMarker = "<!-- golangci approval -->"
+
+// isApproved reports whether the bot has already approved ref: approvals aren't repeated on reanalysis
+func (gr GithubReviewer) isApproved(ctx context.Context, ref string) (bool, error) {
+	reviews, err := gr.client.GetPullRequestReviews(ctx, gr.Context)
+	if err != nil {
+		return false, err
+	}
+
+	for _, r := range reviews {
+		if r.CommitID == ref && r.State == github.ReviewStateApproved && strings.Contains(r.Body, approvalMarker) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// approve posts an approving review: it unblocks merging after changes requested by previous reviews
+func (gr GithubReviewer) approve(ctx context.Context, ref string) error {
+	approved, err := gr.isApproved(ctx, ref)
+	if err != nil {
+		return err
+	}
+	if approved {
+		analytics.Log(ctx).Infof("Commit %s is already approved", ref)
+		return nil
+	}
+
+	body := append(append([]string{}, gr.summary...), gr.msg.Sprintf(i18n.SummaryApproved))
+	review := &github.Review{
+		CommitID: ref,
+		Body:     strings.Join(body, "\n\n") + "\n\n" + approvalMarker,
+		Event:    github.ReviewEventApprove,
+	}
+	if err := gr.client.CreateReview(ctx, gr.Context, review); err != nil {
+		return fmt.Errorf("can't create approving review %+v: %s", review, err)
+	}
+
+	analytics.Log(ctx).Infof("Submitted approving review %+v", review)
+	return nil
+}
+
+// Report posts comments and then approves the commit if issues don't block merging
 func (gr GithubReviewer) Report(ctx context.Context, ref string, issues []result.Issue) error {
+	if err := gr.comment(ctx, ref, issues); err != nil {
+		return err
+	}
+
+	if gr.opts.Approve && !gr.hasBlockingIssues(issues) {
+		return gr.approve(ctx, ref)
+	}
+
+	return nil
+}
+
+func (gr GithubReviewer) comment(ctx context.Context, ref string, issues []result.Issue) error {
 	if len(issues) == 0 {
 		analytics.Log(ctx).Infof("Nothing to report")
 		return nil
 	}
 
+	if gr.summaryOnly {
+		return gr.reportSummary(ctx, ref, issues)
+	}
+
 	existingComments, err := gr.fetchExistingComments(ctx)
 	if err != nil {
 		return err
 	}
 
-	comments := []*gh.DraftReviewComment{}
-	for _, i := range issues {
-		if existingComments.contains(&i) {
-			continue // don't be annoying: don't comment on the same line twice
+	fingerprints := result.Fingerprints(issues)
+	comments := []github.ReviewComment{}
+	for ind, i := range issues {
+		if i.Informational {
+			continue // it's shown only on the analysis page
+		}
+
+		if i.HunkPos <= 0 && i.DeletedLine <= 0 {
+			continue // issue isn't in the diff (e.g. full repo analysis): github can't anchor a comment
 		}
 
-		text := i.Text
-		if gr.includeLinterName && i.FromLinter != "" {
-			text += fmt.Sprintf(" (from `%s`)", i.FromLinter)
+		if existingComments.contains(&i, fingerprints[ind]) {
+			continue // don't be annoying: don't comment the same issue twice, even after force-pushes
 		}
 
-		comment := &gh.DraftReviewComment{
-			Path:     gh.String(i.File),
-			Position: gh.Int(i.HunkPos),
-			Body:     gh.String(text),
+		text := gr.buildCommentText(ctx, &i) + gr.relatedText(ref, &i) + suggestionText(&i) +
+			"\n\n" + fmt.Sprintf(fingerprintMarker, fingerprints[ind])
+		line, side := issueAnchor(&i)
+		c := github.ReviewComment{
+			Path: i.File,
+			Line: line,
+			Side: side,
+			Body: text,
 		}
-		comments = append(comments, comment)
+		if r := i.LineRange; r != nil && r.From < r.To && side == github.SideRight {
+			c.StartLine, c.StartSide, c.Line = r.From, side, r.To
+		}
+		comments = append(comments, c)
 	}
 
 	if len(comments) == 0 {
 		return nil // all comments are already exist
 	}
 
-	review := &gh.PullRequestReviewRequest{
-		CommitID: gh.String(ref),
-		Event:    gh.String("COMMENT"),
-		Body:     gh.String(""),
+	event := gr.reviewEvent(issues)
+	review := &github.Review{
+		CommitID: ref,
+		Body:     gr.reviewBody(event),
+		Event:    event,
 		Comments: comments,
 	}
 	if err := gr.client.CreateReview(ctx, gr.Context, review); err != nil {
@@ -117,3 +431,80 @@ func (gr GithubReviewer) Report(ctx context.Context, ref string, issues []result
 		review, existingComments, issues)
 	return nil
 }
+
+// summaryMarker is a hidden part of summary reviews with fingerprints of listed issues:
+// the summary isn't repeated on every push
+const summaryMarker = "<!-- golangci summary: %s -->"
+
+var summaryRe = regexp.MustCompile(`<!-- golangci summary: ([0-9a-f,]*) -->`)
+
+// fetchSummarizedIssues returns fingerprints of issues listed in previous summary reviews and
+// whether any summary review was posted
+func (gr GithubReviewer) fetchSummarizedIssues(ctx context.Context) (map[string]bool, bool, error) {
+	reviews, err := gr.client.GetPullRequestReviews(ctx, gr.Context)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ret := map[string]bool{}
+	posted := false
+	for _, r := range reviews {
+		m := summaryRe.FindStringSubmatch(r.Body)
+		if m == nil {
+			continue
+		}
+
+		posted = true
+		for _, fp := range strings.Split(m[1], ",") {
+			if fp != "" {
+				ret[fp] = true
+			}
+		}
+	}
+
+	return ret, posted, nil
+}
+
+func (gr GithubReviewer) reportSummary(ctx context.Context, ref string, issues []result.Issue) error {
+	summarized, posted, err := gr.fetchSummarizedIssues(ctx)
+	if err != nil {
+		return err
+	}
+
+	fingerprints := result.Fingerprints(issues)
+	var lines, listed []string
+	for ind, i := range issues {
+		if i.Informational || summarized[fingerprints[ind]] {
+			continue
+		}
+
+		line := fmt.Sprintf("- `%s:%d`: %s", i.File, i.LineNumber, i.Text)
+		if gr.opts.IncludeLinterName && i.FromLinter != "" {
+			line += gr.msg.Sprintf(i18n.CommentFromLinter, i.FromLinter)
+		}
+		lines = append(lines, line)
+		listed = append(listed, fingerprints[ind])
+	}
+
+	if len(lines) == 0 {
+		return nil // all issues are already listed
+	}
+
+	body := strings.Join(lines, "\n")
+	if !posted { // the summary (e.g. the greeting of newcomers) is posted once per pull request
+		body = gr.reviewBody(github.ReviewEventComment, body)
+	}
+	body += "\n\n" + fmt.Sprintf(summaryMarker, strings.Join(listed, ","))
+
+	review := &github.Review{
+		CommitID: ref,
+		Body:     body,
+		Event:    github.ReviewEventComment,
+	}
+	if err := gr.client.CreateReview(ctx, gr.Context, review); err != nil {
+		return fmt.Errorf("can't create summary review %+v: %s", review, err)
+	}
+
+	analytics.Log(ctx).Infof("Submitted summary review %+v, issues: %+v", review, issues)
+	return nil
+}
@@ -0,0 +1,317 @@
+package reporters
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportSkipsAlreadyCommentedIssues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	issues := []result.Issue{
+		{FromLinter: "errcheck", File: "a.go", LineNumber: 3, HunkPos: 1, Text: "force-pushed"},
+		{FromLinter: "golint", File: "a.go", LineNumber: 5, HunkPos: 2, Text: "same line"},
+		{FromLinter: "govet", File: "a.go", LineNumber: 7, HunkPos: 3, Text: "new"},
+	}
+	fps := result.Fingerprints(issues)
+
+	ctx := context.Background()
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequestComments(ctx, c).Return([]*github.PullRequestComment{
+		{Path: "a.go", Body: "force-pushed\n\n" + fmt.Sprintf(fingerprintMarker, fps[0])}, // outdated
+		{Path: "a.go", Line: 5, Side: github.SideRight, Body: "by an old worker"},
+		{Path: "a.go", Body: "outdated without fingerprint"},
+	}, nil)
+	gc.EXPECT().CreateReview(ctx, c, &github.Review{
+		CommitID: "sha",
+		Event:    "COMMENT",
+		Comments: []github.ReviewComment{{
+			Path: "a.go",
+			Line: 7,
+			Side: github.SideRight,
+			Body: "new\n\n" + fmt.Sprintf(fingerprintMarker, fps[2]),
+		}},
+	}).Return(nil)
+
+	assert.NoError(t, NewGithubReviewer(c, gc, GithubReviewerOptions{}).Report(ctx, "sha", issues))
+}
+
+func TestReportSuggestions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	issues := []result.Issue{
+		{FromLinter: "gofmt", File: "a.go", LineNumber: 3, HunkPos: 1, Text: "File is not `gofmt`-ed",
+			LineRange: &result.Range{From: 3, To: 4}, Replacement: &result.Replacement{NewLines: []string{"x := 1"}}},
+		{FromLinter: "gci", File: "a.go", LineNumber: 9, HunkPos: 5, Text: "File is not `gci`-ed",
+			Replacement: &result.Replacement{}},
+	}
+	fps := result.Fingerprints(issues)
+
+	ctx := context.Background()
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequestComments(ctx, c).Return(nil, nil)
+	gc.EXPECT().CreateReview(ctx, c, &github.Review{
+		CommitID: "sha",
+		Event:    "COMMENT",
+		Comments: []github.ReviewComment{
+			{
+				Path:      "a.go",
+				StartLine: 3,
+				StartSide: github.SideRight,
+				Line:      4,
+				Side:      github.SideRight,
+				Body:      "File is not `gofmt`-ed\n\n```suggestion\nx := 1\n```\n\n" + fmt.Sprintf(fingerprintMarker, fps[0]),
+			},
+			{
+				Path: "a.go",
+				Line: 9,
+				Side: github.SideRight,
+				Body: "File is not `gci`-ed\n\n```suggestion\n```\n\n" + fmt.Sprintf(fingerprintMarker, fps[1]),
+			},
+		},
+	}).Return(nil)
+
+	assert.NoError(t, NewGithubReviewer(c, gc, GithubReviewerOptions{}).Report(ctx, "sha", issues))
+}
+
+func TestReportLinksRelatedLocations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	issues := []result.Issue{
+		{FromLinter: "dupl", File: "b.go", LineNumber: 30, HunkPos: 1, Text: "30-40 lines are duplicate of `a.go:10-20`",
+			Related: []result.Location{{File: "a.go", Lines: result.Range{From: 10, To: 20}}}},
+	}
+	fps := result.Fingerprints(issues)
+
+	ctx := context.Background()
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequestComments(ctx, c).Return(nil, nil)
+	url := fmt.Sprintf("https://github.com/%s/%s/blob/sha/a.go#L10-L20", c.Repo.Owner, c.Repo.Name)
+	gc.EXPECT().CreateReview(ctx, c, &github.Review{
+		CommitID: "sha",
+		Event:    "COMMENT",
+		Comments: []github.ReviewComment{{
+			Path: "b.go",
+			Line: 30,
+			Side: github.SideRight,
+			Body: "30-40 lines are duplicate of `a.go:10-20`\n\nSee also [`a.go:10-20`](" + url + ")\n\n" +
+				fmt.Sprintf(fingerprintMarker, fps[0]),
+		}},
+	}).Return(nil)
+
+	assert.NoError(t, NewGithubReviewer(c, gc, GithubReviewerOptions{}).Report(ctx, "sha", issues))
+}
+
+func TestReportPostsSummaryWithComments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	issues := []result.Issue{{FromLinter: "govet", File: "a.go", LineNumber: 7, HunkPos: 3, Text: "issue"}}
+	ctx := context.Background()
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequestComments(ctx, c).Return(nil, nil)
+	gc.EXPECT().CreateReview(ctx, c, gomock.Any()).Do(func(_ context.Context, _ *github.Context, r *github.Review) {
+		assert.Equal(t, "suggested config\n\ndeprecated settings", r.Body)
+	}).Return(nil)
+
+	gr := NewGithubReviewer(c, gc, GithubReviewerOptions{})
+	gr.AddSummary("suggested config")
+	gr.AddSummary("deprecated settings")
+	assert.NoError(t, gr.Report(ctx, "sha", issues))
+}
+
+func TestReportSummaryOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	issues := []result.Issue{
+		{FromLinter: "govet", File: "a.go", LineNumber: 7, HunkPos: 3, Text: "issue"},
+		{FromLinter: "depsfreshness", File: "go.mod", LineNumber: 3, Text: "outdated", Informational: true},
+	}
+	ctx := context.Background()
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl) // existing comments aren't fetched: no line comments are posted
+	gc.EXPECT().GetPullRequestReviews(ctx, c).Return(nil, nil)
+	gc.EXPECT().CreateReview(ctx, c, &github.Review{
+		CommitID: "sha",
+		Body: "welcome\n\n- `a.go:7`: issue (from `govet`)\n\n" +
+			fmt.Sprintf(summaryMarker, result.Fingerprints(issues)[0]),
+		Event: "COMMENT",
+	}).Return(nil)
+
+	gr := NewGithubReviewer(c, gc, GithubReviewerOptions{IncludeLinterName: true})
+	gr.AddSummary("welcome")
+	gr.UseSummaryOnly()
+	assert.NoError(t, gr.Report(ctx, "sha", issues))
+}
+
+func TestReportSummaryOnlyOncePerPR(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	issues := []result.Issue{
+		{FromLinter: "govet", File: "a.go", LineNumber: 7, Text: "listed"},
+		{FromLinter: "govet", File: "b.go", LineNumber: 2, Text: "new"},
+	}
+	fingerprints := result.Fingerprints(issues)
+	ctx := context.Background()
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequestReviews(ctx, c).Return([]*github.PullRequestReview{
+		{CommitID: "old", State: "COMMENTED", Body: "welcome\n\n" + fmt.Sprintf(summaryMarker, fingerprints[0])},
+	}, nil).Times(2)
+	gc.EXPECT().CreateReview(ctx, c, &github.Review{
+		CommitID: "sha",
+		Body:     "- `b.go:2`: new\n\n" + fmt.Sprintf(summaryMarker, fingerprints[1]),
+		Event:    "COMMENT",
+	}).Return(nil)
+
+	gr := NewGithubReviewer(c, gc, GithubReviewerOptions{})
+	gr.AddSummary("welcome")
+	gr.UseSummaryOnly()
+	assert.NoError(t, gr.Report(ctx, "sha", issues))
+	assert.NoError(t, gr.Report(ctx, "sha", issues[:1])) // nothing new: no review is posted
+}
+
+func TestReportInRepoLanguage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	issues := []result.Issue{{FromLinter: "govet", File: "a.go", LineNumber: 7, Text: "issue", Commit: "0123456789"}}
+	ctx := context.Background()
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequestReviews(ctx, c).Return(nil, nil)
+	gc.EXPECT().CreateReview(ctx, c, &github.Review{
+		CommitID: "sha",
+		Body:     "- `a.go:7`: issue (линтер `govet`)\n\n" + fmt.Sprintf(summaryMarker, result.Fingerprints(issues)[0]),
+		Event:    "COMMENT",
+	}).Return(nil)
+
+	gr := NewGithubReviewer(c, gc, GithubReviewerOptions{IncludeLinterName: true, Lang: "ru"})
+	assert.Equal(t, "issue (линтер `govet`) (добавлено в 0123456)", gr.buildCommentText(ctx, &issues[0]))
+
+	gr.UseSummaryOnly()
+	assert.NoError(t, gr.Report(ctx, "sha", issues))
+}
+
+func TestReportRequestsChangesForSevereIssues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	severities := map[string]int{"gosec": 3, "golint": 1}
+	opts := GithubReviewerOptions{
+		RequestChangesSeverity: 3,
+		Severity: func(i *result.Issue) int {
+			return severities[i.FromLinter]
+		},
+	}
+	ctx := context.Background()
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequestComments(ctx, c).Return(nil, nil).Times(2)
+
+	var events, bodies []string
+	gc.EXPECT().CreateReview(ctx, c, gomock.Any()).Do(func(_ context.Context, _ *github.Context, r *github.Review) {
+		events = append(events, r.Event)
+		bodies = append(bodies, r.Body)
+	}).Return(nil).Times(2)
+
+	gr := NewGithubReviewer(c, gc, opts)
+	style := result.Issue{FromLinter: "golint", File: "a.go", LineNumber: 7, HunkPos: 3, Text: "style"}
+	assert.NoError(t, gr.Report(ctx, "sha", []result.Issue{style}))
+
+	security := result.Issue{FromLinter: "gosec", File: "a.go", LineNumber: 8, HunkPos: 4, Text: "security"}
+	assert.NoError(t, gr.Report(ctx, "sha", []result.Issue{style, security}))
+
+	assert.Equal(t, []string{github.ReviewEventComment, github.ReviewEventRequestChanges}, events)
+	assert.Equal(t, []string{"", "GolangCI found issues that must be fixed before merging."}, bodies)
+}
+
+func TestReportApprovesCleanPRs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequestComments(ctx, c).Return(nil, nil)
+	gc.EXPECT().GetPullRequestReviews(ctx, c).Return(nil, nil)
+	gc.EXPECT().CreateReview(ctx, c, &github.Review{
+		CommitID: "sha",
+		Body:     "GolangCI found no issues blocking merging.\n\n" + approvalMarker,
+		Event:    github.ReviewEventApprove,
+	}).Return(nil)
+
+	gr := NewGithubReviewer(c, gc, GithubReviewerOptions{Approve: true})
+	info := result.Issue{FromLinter: "depsfreshness", File: "go.mod", LineNumber: 3, Text: "outdated", Informational: true}
+	assert.NoError(t, gr.Report(ctx, "sha", []result.Issue{info}))
+}
+
+func TestReportCommentsAndApprovesPRsWithoutSevereIssues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := GithubReviewerOptions{
+		Approve:                true,
+		RequestChangesSeverity: 3,
+		Severity: func(i *result.Issue) int {
+			return 1
+		},
+	}
+	ctx := context.Background()
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequestComments(ctx, c).Return(nil, nil)
+	gc.EXPECT().GetPullRequestReviews(ctx, c).Return([]*github.PullRequestReview{
+		{CommitID: "sha", State: github.ReviewStateApproved, Body: "LGTM"}, // approval of a person
+	}, nil)
+
+	var events []string
+	gc.EXPECT().CreateReview(ctx, c, gomock.Any()).Do(func(_ context.Context, _ *github.Context, r *github.Review) {
+		events = append(events, r.Event)
+	}).Return(nil).Times(2)
+
+	gr := NewGithubReviewer(c, gc, opts)
+	style := result.Issue{FromLinter: "golint", File: "a.go", LineNumber: 7, HunkPos: 3, Text: "style"}
+	assert.NoError(t, gr.Report(ctx, "sha", []result.Issue{style}))
+	assert.Equal(t, []string{github.ReviewEventComment, github.ReviewEventApprove}, events)
+}
+
+func TestReportDoesntRepeatApprovals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	c := &github.FakeContext
+	gc := github.NewMockClient(ctrl)
+	gc.EXPECT().GetPullRequestReviews(ctx, c).Return([]*github.PullRequestReview{
+		{CommitID: "old", State: github.ReviewStateChangesRequested},
+		{CommitID: "sha", State: github.ReviewStateApproved, Body: "approved\n\n" + approvalMarker},
+	}, nil)
+
+	gr := NewGithubReviewer(c, gc, GithubReviewerOptions{Approve: true})
+	assert.NoError(t, gr.Report(ctx, "sha", nil))
+}
+
+func TestSummaryOnlyReviewsDontRequestChanges(t *testing.T) {
+	gr := NewGithubReviewer(&github.FakeContext, nil, GithubReviewerOptions{RequestChangesSeverity: 1})
+	issues := []result.Issue{{FromLinter: "govet", Text: "issue"}}
+	assert.Equal(t, github.ReviewEventRequestChanges, gr.reviewEvent(issues))
+
+	gr.UseSummaryOnly()
+	assert.Equal(t, github.ReviewEventComment, gr.reviewEvent(issues))
+}
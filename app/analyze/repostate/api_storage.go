@@ -3,45 +3,52 @@ package repostate
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"os"
 
+	"github.com/golangci/golangci-worker/app/lib/apiclient"
 	"github.com/golangci/golangci-worker/app/lib/httputils"
 )
 
 type APIStorage struct {
-	host   string
-	client httputils.Client
+	api *apiclient.Client
 }
 
 func NewAPIStorage(client httputils.Client) *APIStorage {
 	return &APIStorage{
-		client: client,
-		host:   os.Getenv("API_URL"),
+		api: apiclient.New(client),
 	}
 }
 
-func (s APIStorage) getAnalysisURL(owner, name, analysisID string) string {
-	return fmt.Sprintf("%s/v1/repos/github.com/%s/%s/repoanalyzes/%s", s.host, owner, name, analysisID)
-}
-
 func (s APIStorage) UpdateState(ctx context.Context, owner, name, analysisID string, state *State) error {
-	return s.client.Put(ctx, s.getAnalysisURL(owner, name, analysisID), state)
+	return s.api.UpdateRepoAnalysisState(ctx, owner, name, analysisID, state)
 }
 
 func (s APIStorage) GetState(ctx context.Context, owner, name, analysisID string) (*State, error) {
-	bodyReader, err := s.client.Get(ctx, s.getAnalysisURL(owner, name, analysisID))
+	return s.api.GetRepoAnalysisState(ctx, owner, name, analysisID)
+}
+
+func (s APIStorage) GetLastState(ctx context.Context, owner, name, branch string) (*State, error) {
+	state, err := s.api.GetLastRepoAnalysisState(ctx, owner, name, branch)
 	if err != nil {
+		if apiclient.IsNotFound(err) {
+			return nil, nil
+		}
 		return nil, err
 	}
 
-	defer bodyReader.Close()
+	return state, nil
+}
+
+// NopStorage keeps no states: branches are never analyzed for it, e.g. for local analyses
+type NopStorage struct{}
 
-	var state State
-	if err = json.NewDecoder(bodyReader).Decode(&state); err != nil {
-		return nil, fmt.Errorf("can't read json body: %s", err)
-	}
+func (NopStorage) UpdateState(ctx context.Context, owner, name, analysisID string, state *State) error {
+	return nil
+}
+
+func (NopStorage) GetState(ctx context.Context, owner, name, analysisID string) (*State, error) {
+	return nil, apiclient.ErrNotFound
+}
 
-	return &state, nil
+func (NopStorage) GetLastState(ctx context.Context, owner, name, branch string) (*State, error) {
+	return nil, nil
 }
@@ -2,18 +2,18 @@ package repostate
 
 import (
 	"context"
-	"time"
+
+	"github.com/golangci/golangci-worker/app/lib/apiclient"
 )
 
 //go:generate mockgen -package repostate -source storage.go -destination storage_mock.go
 
-type State struct {
-	CreatedAt  time.Time
-	Status     string
-	ResultJSON interface{}
-}
+type State = apiclient.RepoAnalysisState
 
 type Storage interface {
 	UpdateState(ctx context.Context, owner, name, analysisID string, state *State) error
 	GetState(ctx context.Context, owner, name, analysisID string) (*State, error)
+
+	// GetLastState returns the state of the last analysis of the branch, it's nil if the branch wasn't analyzed
+	GetLastState(ctx context.Context, owner, name, branch string) (*State, error)
 }
@@ -1,6 +1,7 @@
 // Code generated by MockGen. DO NOT EDIT.
 // Source: storage.go
 
+// Package repostate is a generated GoMock package.
 package repostate
 
 import (
@@ -28,31 +29,44 @@ func NewMockStorage(ctrl *gomock.Controller) *MockStorage {
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use
-func (_m *MockStorage) EXPECT() *MockStorageMockRecorder {
-	return _m.recorder
+func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
+	return m.recorder
 }
 
 // UpdateState mocks base method
-func (_m *MockStorage) UpdateState(ctx context.Context, owner string, name string, analysisID string, state *State) error {
-	ret := _m.ctrl.Call(_m, "UpdateState", ctx, owner, name, analysisID, state)
+func (m *MockStorage) UpdateState(ctx context.Context, owner, name, analysisID string, state *State) error {
+	ret := m.ctrl.Call(m, "UpdateState", ctx, owner, name, analysisID, state)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateState indicates an expected call of UpdateState
-func (_mr *MockStorageMockRecorder) UpdateState(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "UpdateState", reflect.TypeOf((*MockStorage)(nil).UpdateState), arg0, arg1, arg2, arg3, arg4)
+func (mr *MockStorageMockRecorder) UpdateState(ctx, owner, name, analysisID, state interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateState", reflect.TypeOf((*MockStorage)(nil).UpdateState), ctx, owner, name, analysisID, state)
 }
 
 // GetState mocks base method
-func (_m *MockStorage) GetState(ctx context.Context, owner string, name string, analysisID string) (*State, error) {
-	ret := _m.ctrl.Call(_m, "GetState", ctx, owner, name, analysisID)
+func (m *MockStorage) GetState(ctx context.Context, owner, name, analysisID string) (*State, error) {
+	ret := m.ctrl.Call(m, "GetState", ctx, owner, name, analysisID)
 	ret0, _ := ret[0].(*State)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetState indicates an expected call of GetState
-func (_mr *MockStorageMockRecorder) GetState(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "GetState", reflect.TypeOf((*MockStorage)(nil).GetState), arg0, arg1, arg2, arg3)
+func (mr *MockStorageMockRecorder) GetState(ctx, owner, name, analysisID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetState", reflect.TypeOf((*MockStorage)(nil).GetState), ctx, owner, name, analysisID)
+}
+
+// GetLastState mocks base method
+func (m *MockStorage) GetLastState(ctx context.Context, owner, name, branch string) (*State, error) {
+	ret := m.ctrl.Call(m, "GetLastState", ctx, owner, name, branch)
+	ret0, _ := ret[0].(*State)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastState indicates an expected call of GetLastState
+func (mr *MockStorageMockRecorder) GetLastState(ctx, owner, name, branch interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastState", reflect.TypeOf((*MockStorage)(nil).GetLastState), ctx, owner, name, branch)
 }
@@ -0,0 +1,272 @@
+// Package resultdiff compares stored results of two analyses of a repo:
+// it's used by support to find why e.g. a pull request suddenly failed.
+package resultdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/golangci/golangci-lint/pkg/printers"
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/prstate"
+	"github.com/golangci/golangci-worker/app/analyze/repostate"
+	"github.com/golangci/golangci-worker/app/lib/buildinfo"
+)
+
+// Analysis is a stored state of an analysis with the parsed result json
+type Analysis struct {
+	ID     string
+	Status string
+	Result Result
+}
+
+// Result is a part of the result json of processors to compare
+type Result struct {
+	GolangciLintRes *printers.JSONResult
+	WorkerRes       struct {
+		Timings []struct {
+			Name       string
+			DurationMs int
+		}
+		Error      string
+		InfoIssues []result.Issue
+		Build      *buildinfo.Info
+	}
+}
+
+// Issues returns issues of the result including informational ones
+func (r Result) Issues() []result.Issue {
+	var ret []result.Issue
+	if r.GolangciLintRes != nil {
+		for _, i := range r.GolangciLintRes.Issues {
+			ret = append(ret, result.NewIssue(i.FromLinter, i.Text, i.FilePath(), i.Line(), i.HunkPos))
+		}
+	}
+
+	return append(ret, r.WorkerRes.InfoIssues...)
+}
+
+func newAnalysis(id, status string, resultJSON interface{}) (*Analysis, error) {
+	a := &Analysis{
+		ID:     id,
+		Status: status,
+	}
+	if resultJSON == nil { // e.g. analysis is still processing
+		return a, nil
+	}
+
+	// storages return raw result json, other states have result structures of processors
+	data, ok := resultJSON.(json.RawMessage)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(resultJSON); err != nil {
+			return nil, fmt.Errorf("can't marshal result json of %s: %s", id, err)
+		}
+	}
+	if err := json.Unmarshal(data, &a.Result); err != nil {
+		return nil, fmt.Errorf("can't parse result json of %s: %s", id, err)
+	}
+
+	return a, nil
+}
+
+// FromPRState returns analysis of a pull request
+func FromPRState(id string, state *prstate.State) (*Analysis, error) {
+	return newAnalysis(id, state.Status, state.ResultJSON)
+}
+
+// FromRepoState returns analysis of a default branch of a repo
+func FromRepoState(id string, state *repostate.State) (*Analysis, error) {
+	return newAnalysis(id, state.Status, state.ResultJSON)
+}
+
+// Change is a change of a value between analyses, values are empty if they are absent
+type Change struct {
+	Name   string
+	Before string
+	After  string
+}
+
+// TimingChange is a change of a duration of a step, durations are zero if the step wasn't made
+type TimingChange struct {
+	Name   string
+	Before time.Duration
+	After  time.Duration
+}
+
+// Diff is changes from the first analysis to the second one
+type Diff struct {
+	From, To string
+
+	// Status and Error are changes of the outcome
+	Status *Change `json:",omitempty"`
+	Error  *Change `json:",omitempty"`
+
+	// AddedIssues and RemovedIssues are compared by fingerprints: moved issues aren't changes
+	AddedIssues   []result.Issue `json:",omitempty"`
+	RemovedIssues []result.Issue `json:",omitempty"`
+
+	Timings []TimingChange `json:",omitempty"`
+
+	// Env is changes of versions of the worker and tools and of evaluated experiments
+	Env []Change `json:",omitempty"`
+}
+
+// Compare returns changes from the analysis from to the analysis to
+func Compare(from, to *Analysis) *Diff {
+	d := &Diff{
+		From:    from.ID,
+		To:      to.ID,
+		Status:  compareValue("status", from.Status, to.Status),
+		Error:   compareValue("error", from.Result.WorkerRes.Error, to.Result.WorkerRes.Error),
+		Timings: compareTimings(from.Result, to.Result),
+		Env:     compareEnv(from.Result.WorkerRes.Build, to.Result.WorkerRes.Build),
+	}
+	d.AddedIssues, d.RemovedIssues = compareIssues(from.Result.Issues(), to.Result.Issues())
+	return d
+}
+
+func compareValue(name, before, after string) *Change {
+	if before == after {
+		return nil
+	}
+
+	return &Change{Name: name, Before: before, After: after}
+}
+
+func compareIssues(before, after []result.Issue) (added, removed []result.Issue) {
+	beforeFPs := map[string]bool{}
+	for _, fp := range result.Fingerprints(before) {
+		beforeFPs[fp] = true
+	}
+
+	afterFPs := map[string]bool{}
+	for i, fp := range result.Fingerprints(after) {
+		afterFPs[fp] = true
+		if !beforeFPs[fp] {
+			added = append(added, after[i])
+		}
+	}
+
+	for i, fp := range result.Fingerprints(before) {
+		if !afterFPs[fp] {
+			removed = append(removed, before[i])
+		}
+	}
+
+	return added, removed
+}
+
+func compareTimings(before, after Result) []TimingChange {
+	durations := func(r Result) map[string]time.Duration {
+		ret := map[string]time.Duration{}
+		for _, t := range r.WorkerRes.Timings {
+			ret[t.Name] += time.Duration(t.DurationMs) * time.Millisecond
+		}
+		return ret
+	}
+
+	beforeDurations, afterDurations := durations(before), durations(after)
+	var ret []TimingChange
+	for _, name := range unionKeys(beforeDurations, afterDurations) {
+		ret = append(ret, TimingChange{
+			Name:   name,
+			Before: beforeDurations[name],
+			After:  afterDurations[name],
+		})
+	}
+
+	return ret
+}
+
+func unionKeys(maps ...map[string]time.Duration) []string {
+	seen := map[string]bool{}
+	var ret []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				ret = append(ret, k)
+			}
+		}
+	}
+
+	sort.Strings(ret)
+	return ret
+}
+
+func envValues(info *buildinfo.Info) map[string]string {
+	ret := map[string]string{}
+	if info == nil { // results of old workers aren't stamped
+		return ret
+	}
+
+	ret["worker version"] = info.WorkerVersion
+	ret["golangci-lint version"] = info.LinterVersion
+	ret["go version"] = info.GoVersion
+	for name, isActive := range info.Experiments {
+		ret["experiment "+name] = fmt.Sprint(isActive)
+	}
+
+	return ret
+}
+
+func compareEnv(before, after *buildinfo.Info) []Change {
+	beforeValues, afterValues := envValues(before), envValues(after)
+
+	var names []string
+	for name := range beforeValues {
+		names = append(names, name)
+	}
+	for name := range afterValues {
+		if _, ok := beforeValues[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var ret []Change
+	for _, name := range names {
+		if c := compareValue(name, beforeValues[name], afterValues[name]); c != nil {
+			ret = append(ret, *c)
+		}
+	}
+
+	return ret
+}
+
+// Print prints the diff in a human-readable form
+func (d Diff) Print(w io.Writer) {
+	fmt.Fprintf(w, "Changes from %s to %s\n", d.From, d.To)
+	for _, c := range []*Change{d.Status, d.Error} {
+		if c != nil {
+			fmt.Fprintf(w, "%s: %q -> %q\n", c.Name, c.Before, c.After)
+		}
+	}
+
+	fmt.Fprintf(w, "\nIssues: %d added, %d removed\n", len(d.AddedIssues), len(d.RemovedIssues))
+	printIssues := func(prefix string, issues []result.Issue) {
+		for _, i := range issues {
+			fmt.Fprintf(w, "%s %s:%d: %s (%s)\n", prefix, i.File, i.LineNumber, i.Text, i.FromLinter)
+		}
+	}
+	printIssues("+", d.AddedIssues)
+	printIssues("-", d.RemovedIssues)
+
+	if len(d.Timings) != 0 {
+		fmt.Fprintln(w, "\nTimings:")
+		for _, t := range d.Timings {
+			fmt.Fprintf(w, "%-32s %10s -> %10s\n", t.Name, t.Before, t.After)
+		}
+	}
+
+	if len(d.Env) != 0 {
+		fmt.Fprintln(w, "\nEnv:")
+		for _, c := range d.Env {
+			fmt.Fprintf(w, "%s: %q -> %q\n", c.Name, c.Before, c.After)
+		}
+	}
+}
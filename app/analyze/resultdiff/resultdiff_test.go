@@ -0,0 +1,76 @@
+package resultdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/golangci/golangci-worker/app/analyze/prstate"
+	"github.com/stretchr/testify/assert"
+)
+
+// decodedState returns a state as it's returned by the API storage: result json is decoded into maps
+func decodedState(t *testing.T, status, resultJSON string) *prstate.State {
+	var res interface{}
+	assert.NoError(t, json.Unmarshal([]byte(resultJSON), &res))
+	return &prstate.State{Status: status, ResultJSON: res}
+}
+
+func TestCompare(t *testing.T) {
+	from, err := FromPRState("a", decodedState(t, "processed/success", `{
+		"GolangciLintRes": {"Issues": [
+			{"FromLinter": "errcheck", "Text": "unchecked error", "Pos": {"Filename": "main.go", "Line": 10}},
+			{"FromLinter": "govet", "Text": "unreachable code", "Pos": {"Filename": "main.go", "Line": 20}}
+		]},
+		"WorkerRes": {
+			"Timings": [{"Name": "lint", "DurationMs": 1000}, {"Name": "clone", "DurationMs": 500}],
+			"Build": {"WorkerVersion": "v1", "GoVersion": "go1.11", "Experiments": {"merge_base_scoping": false}}
+		}
+	}`))
+	assert.NoError(t, err)
+
+	to, err := FromPRState("b", decodedState(t, "processed/failure", `{
+		"GolangciLintRes": {"Issues": [
+			{"FromLinter": "errcheck", "Text": "unchecked error", "Pos": {"Filename": "main.go", "Line": 12}},
+			{"FromLinter": "staticcheck", "Text": "SA4006: unused value", "Pos": {"Filename": "util.go", "Line": 3}}
+		]},
+		"WorkerRes": {
+			"Timings": [{"Name": "lint", "DurationMs": 3000}],
+			"Build": {"WorkerVersion": "v2", "GoVersion": "go1.11", "Experiments": {"merge_base_scoping": true}}
+		}
+	}`))
+	assert.NoError(t, err)
+
+	d := Compare(from, to)
+	assert.Equal(t, &Change{Name: "status", Before: "processed/success", After: "processed/failure"}, d.Status)
+	assert.Nil(t, d.Error)
+	assert.Equal(t, []result.Issue{result.NewIssue("staticcheck", "SA4006: unused value", "util.go", 3, 0)}, d.AddedIssues)
+	assert.Equal(t, []result.Issue{result.NewIssue("govet", "unreachable code", "main.go", 20, 0)}, d.RemovedIssues)
+	assert.Equal(t, []TimingChange{
+		{Name: "clone", Before: 500 * time.Millisecond},
+		{Name: "lint", Before: time.Second, After: 3 * time.Second},
+	}, d.Timings)
+	assert.Equal(t, []Change{
+		{Name: "experiment merge_base_scoping", Before: "false", After: "true"},
+		{Name: "worker version", Before: "v1", After: "v2"},
+	}, d.Env)
+
+	var buf bytes.Buffer
+	d.Print(&buf)
+	assert.Contains(t, buf.String(), "+ util.go:3: SA4006: unused value (staticcheck)")
+	assert.Contains(t, buf.String(), "- main.go:20: unreachable code (govet)")
+}
+
+func TestCompareWithoutResult(t *testing.T) {
+	from, err := FromPRState("a", &prstate.State{Status: "sent_to_queue"})
+	assert.NoError(t, err)
+	to, err := FromPRState("b", decodedState(t, "processed/success", `{"WorkerRes": {"Build": {"WorkerVersion": "v1"}}}`))
+	assert.NoError(t, err)
+
+	d := Compare(from, to)
+	assert.Empty(t, d.AddedIssues)
+	assert.Empty(t, d.RemovedIssues)
+	assert.Equal(t, []Change{{Name: "worker version", After: "v1"}}, d.Env)
+}
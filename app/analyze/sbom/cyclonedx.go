@@ -0,0 +1,103 @@
+package sbom
+
+import (
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analyze/depgraph"
+)
+
+// CycloneDX 1.5 JSON: https://cyclonedx.org/docs/1.5/json/
+
+type cdxBOM struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies,omitempty"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     []cdxTool    `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTool struct {
+	Name string `json:"name"`
+}
+
+type cdxComponent struct {
+	Type    string `json:"type"`
+	BOMRef  string `json:"bom-ref"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+	Scope   string `json:"scope,omitempty"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+func buildCycloneDX(s Subject, g *depgraph.Graph, now time.Time) *cdxBOM {
+	main, deps := components(g)
+
+	bom := &cdxBOM{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: cdxMetadata{
+			Timestamp: now.UTC().Format(time.RFC3339),
+			Tools:     []cdxTool{{Name: toolName}},
+			Component: cdxComponent{
+				Type:    "application",
+				BOMRef:  "repo:" + s.Repo,
+				Name:    s.Repo,
+				Version: s.Commit,
+			},
+		},
+	}
+
+	refs := map[string]string{} // ids of modules -> bom refs
+	if main != nil {
+		bom.Metadata.Component.Name = main.path
+		bom.Metadata.Component.PURL = purl(main.path, s.Commit)
+		refs[main.id] = bom.Metadata.Component.BOMRef
+	}
+
+	for _, d := range deps {
+		c := cdxComponent{
+			Type:    "library",
+			BOMRef:  purl(d.path, d.version),
+			Name:    d.path,
+			Version: d.version,
+			PURL:    purl(d.path, d.version),
+			Scope:   "required",
+		}
+		if !d.direct {
+			c.Scope = "optional" // transitive modules aren't necessarily linked into binaries
+		}
+		bom.Components = append(bom.Components, c)
+		refs[d.id] = c.BOMRef
+	}
+
+	for from, tos := range dependsOn(g) {
+		ref, ok := refs[from]
+		if !ok {
+			continue // requirements of versions pruned by MVS
+		}
+
+		dep := cdxDependency{Ref: ref}
+		for _, to := range tos {
+			if toRef, ok := refs[to]; ok {
+				dep.DependsOn = append(dep.DependsOn, toRef)
+			}
+		}
+		bom.Dependencies = append(bom.Dependencies, dep)
+	}
+	sortCDXDependencies(bom.Dependencies)
+
+	return bom
+}
@@ -0,0 +1,126 @@
+// Package sbom renders software bills of materials of analyzed commits from their module dependency graphs
+// in CycloneDX or SPDX JSON formats.
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analyze/depgraph"
+	"github.com/pkg/errors"
+)
+
+type Format string
+
+const (
+	FormatCycloneDX Format = "cyclonedx"
+	FormatSPDX      Format = "spdx"
+)
+
+// toolName is the creator of documents
+const toolName = "golangci-worker"
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case "", FormatCycloneDX:
+		return FormatCycloneDX, nil
+	case FormatSPDX:
+		return FormatSPDX, nil
+	default:
+		return "", fmt.Errorf("unknown SBOM format %q: cyclonedx or spdx are supported", s)
+	}
+}
+
+// Subject is the analyzed commit the SBOM describes
+type Subject struct {
+	Repo   string // owner/name
+	Commit string
+}
+
+// Document is a rendered SBOM
+type Document struct {
+	Format Format
+	Data   json.RawMessage
+}
+
+// FileName is a name of the artifact of the document
+func (d Document) FileName() string {
+	if d.Format == FormatSPDX {
+		return "sbom.spdx.json"
+	}
+
+	return "sbom.cdx.json"
+}
+
+// Generate renders the SBOM of the graph, replaced modules are listed by their replacements: they are built
+func Generate(format Format, s Subject, g *depgraph.Graph, now time.Time) (*Document, error) {
+	var doc interface{}
+	switch format {
+	case FormatCycloneDX:
+		doc = buildCycloneDX(s, g, now)
+	case FormatSPDX:
+		doc = buildSPDX(s, g, now)
+	default:
+		return nil, fmt.Errorf("unknown SBOM format %q", format)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't marshal %s SBOM", format)
+	}
+
+	return &Document{Format: format, Data: data}, nil
+}
+
+// component is a module as it's built
+type component struct {
+	id      string // id of the module in the graph
+	path    string
+	version string
+	direct  bool
+}
+
+func components(g *depgraph.Graph) (main *component, deps []component) {
+	for _, m := range g.Modules {
+		c := component{id: m.ID(), path: m.Path, version: m.Version, direct: m.Direct}
+		if r := m.Replace; r != nil && r.Version != "" { // local replacements keep the original module
+			c.path, c.version = r.Path, r.Version
+		}
+
+		if m.Main {
+			main = &c
+			continue
+		}
+		deps = append(deps, c)
+	}
+
+	return main, deps
+}
+
+// purl is a package URL of the module
+func purl(path, version string) string {
+	if version == "" {
+		return "pkg:golang/" + path
+	}
+
+	return fmt.Sprintf("pkg:golang/%s@%s", path, version)
+}
+
+// dependsOn returns ids of modules required by modules by ids
+func dependsOn(g *depgraph.Graph) map[string][]string {
+	ret := map[string][]string{}
+	for _, e := range g.Edges {
+		ret[e.From] = append(ret[e.From], e.To)
+	}
+
+	return ret
+}
+
+func sortCDXDependencies(deps []cdxDependency) {
+	sort.Slice(deps, func(i, j int) bool {
+		return deps[i].Ref < deps[j].Ref
+	})
+}
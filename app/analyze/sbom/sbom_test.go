@@ -0,0 +1,111 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analyze/depgraph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testGraph = &depgraph.Graph{
+	Modules: []depgraph.Module{
+		{Path: "github.com/golangci/worker", Main: true},
+		{Path: "github.com/pkg/errors", Version: "v0.8.0", Direct: true},
+		{
+			Path:     "golang.org/x/sync",
+			Version:  "v0.0.0-20181108010431-42b317875d0f",
+			Indirect: true,
+			Replace:  &depgraph.Module{Path: "github.com/golang/sync", Version: "v0.1.0"},
+		},
+	},
+	Edges: []depgraph.Edge{
+		{From: "github.com/golangci/worker", To: "golang.org/x/sync@v0.0.0-20181108010431-42b317875d0f"},
+		{From: "github.com/golangci/worker", To: "github.com/pkg/errors@v0.8.0"},
+		{From: "github.com/pkg/errors@v0.8.0", To: "github.com/pkg/errors@v0.7.0"}, // pruned by MVS
+	},
+}
+
+var testSubject = Subject{Repo: "golangci/worker", Commit: "0123abc"}
+
+var testNow = time.Date(2018, 11, 20, 10, 0, 0, 0, time.UTC)
+
+func TestParseFormat(t *testing.T) {
+	for s, f := range map[string]Format{"": FormatCycloneDX, "CycloneDX": FormatCycloneDX, "spdx": FormatSPDX} {
+		got, err := ParseFormat(s)
+		assert.NoError(t, err)
+		assert.Equal(t, f, got, s)
+	}
+
+	_, err := ParseFormat("syft")
+	assert.Error(t, err)
+}
+
+func TestGenerateCycloneDX(t *testing.T) {
+	doc, err := Generate(FormatCycloneDX, testSubject, testGraph, testNow)
+	require.NoError(t, err)
+	assert.Equal(t, "sbom.cdx.json", doc.FileName())
+
+	var bom cdxBOM
+	require.NoError(t, json.Unmarshal(doc.Data, &bom))
+	assert.Equal(t, "CycloneDX", bom.BOMFormat)
+	assert.Equal(t, "2018-11-20T10:00:00Z", bom.Metadata.Timestamp)
+	assert.Equal(t, "pkg:golang/github.com/golangci/worker@0123abc", bom.Metadata.Component.PURL)
+
+	assert.Equal(t, []cdxComponent{
+		{
+			Type: "library", BOMRef: "pkg:golang/github.com/pkg/errors@v0.8.0", Name: "github.com/pkg/errors",
+			Version: "v0.8.0", PURL: "pkg:golang/github.com/pkg/errors@v0.8.0", Scope: "required",
+		},
+		{
+			Type: "library", BOMRef: "pkg:golang/github.com/golang/sync@v0.1.0", Name: "github.com/golang/sync",
+			Version: "v0.1.0", PURL: "pkg:golang/github.com/golang/sync@v0.1.0", Scope: "optional",
+		},
+	}, bom.Components)
+
+	assert.Equal(t, []cdxDependency{
+		{Ref: "pkg:golang/github.com/pkg/errors@v0.8.0"},
+		{Ref: "repo:golangci/worker", DependsOn: []string{
+			"pkg:golang/github.com/golang/sync@v0.1.0",
+			"pkg:golang/github.com/pkg/errors@v0.8.0",
+		}},
+	}, bom.Dependencies)
+}
+
+func TestComponentsOfTransitiveModules(t *testing.T) {
+	// go list doesn't mark modules missing in go.mod as indirect: only Direct tells direct dependencies
+	g := &depgraph.Graph{Modules: []depgraph.Module{
+		{Path: "github.com/golangci/worker", Main: true},
+		{Path: "github.com/pkg/errors", Version: "v0.8.0", Direct: true},
+		{Path: "golang.org/x/text", Version: "v0.3.0"},
+	}}
+
+	_, deps := components(g)
+	require.Len(t, deps, 2)
+	assert.True(t, deps[0].direct)
+	assert.False(t, deps[1].direct)
+}
+
+func TestGenerateSPDX(t *testing.T) {
+	doc, err := Generate(FormatSPDX, testSubject, testGraph, testNow)
+	require.NoError(t, err)
+	assert.Equal(t, "sbom.spdx.json", doc.FileName())
+
+	var sd spdxDocument
+	require.NoError(t, json.Unmarshal(doc.Data, &sd))
+	assert.Equal(t, "SPDX-2.3", sd.SPDXVersion)
+	assert.Equal(t, "https://golangci.com/spdx/golangci/worker/0123abc", sd.DocumentNamespace)
+
+	require.Len(t, sd.Packages, 3)
+	assert.Equal(t, "github.com/golangci/worker", sd.Packages[0].Name)
+	assert.Equal(t, "0123abc", sd.Packages[0].VersionInfo)
+	assert.Equal(t, "pkg:golang/github.com/golang/sync@v0.1.0", sd.Packages[2].ExternalRefs[0].ReferenceLocator)
+
+	assert.Equal(t, []spdxRelationship{
+		{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Package-0"},
+		{SPDXElementID: "SPDXRef-Package-0", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-1"},
+		{SPDXElementID: "SPDXRef-Package-2", RelationshipType: "OPTIONAL_DEPENDENCY_OF", RelatedSPDXElement: "SPDXRef-Package-0"},
+	}, sd.Relationships)
+}
@@ -0,0 +1,142 @@
+package sbom
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analyze/depgraph"
+)
+
+// SPDX 2.3 JSON: https://spdx.github.io/spdx-spec/v2.3/
+
+const spdxNamespaceBase = "https://golangci.com/spdx/"
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+func newSPDXPackage(id, name, version string) spdxPackage {
+	return spdxPackage{
+		Name:             name,
+		SPDXID:           id,
+		VersionInfo:      version,
+		DownloadLocation: "NOASSERTION",
+		ExternalRefs: []spdxExternalRef{{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  purl(name, version),
+		}},
+	}
+}
+
+func buildSPDX(s Subject, g *depgraph.Graph, now time.Time) *spdxDocument {
+	main, deps := components(g)
+
+	doc := &spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              fmt.Sprintf("%s@%s", s.Repo, s.Commit),
+		DocumentNamespace: fmt.Sprintf("%s%s/%s", spdxNamespaceBase, s.Repo, s.Commit),
+		CreationInfo: spdxCreationInfo{
+			Created:  now.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + toolName},
+		},
+	}
+
+	// SPDX ids allow only letters, digits, dots and dashes: number packages
+	const mainID = "SPDXRef-Package-0"
+	mainName := s.Repo
+	if main != nil {
+		mainName = main.path
+	}
+	doc.Packages = append(doc.Packages, newSPDXPackage(mainID, mainName, s.Commit))
+	doc.Relationships = append(doc.Relationships, spdxRelationship{
+		SPDXElementID:      doc.SPDXID,
+		RelationshipType:   "DESCRIBES",
+		RelatedSPDXElement: mainID,
+	})
+
+	ids := map[string]string{} // ids of modules -> SPDX ids
+	direct := map[string]bool{}
+	if main != nil {
+		ids[main.id] = mainID
+	}
+	for i, d := range deps {
+		p := newSPDXPackage(fmt.Sprintf("SPDXRef-Package-%d", i+1), d.path, d.version)
+		doc.Packages = append(doc.Packages, p)
+		ids[d.id] = p.SPDXID
+		direct[p.SPDXID] = d.direct
+	}
+
+	var rels []spdxRelationship
+	for from, tos := range dependsOn(g) {
+		fromID, ok := ids[from]
+		if !ok {
+			continue
+		}
+		for _, to := range tos {
+			toID, ok := ids[to]
+			if !ok {
+				continue
+			}
+
+			if fromID == mainID && !direct[toID] { // a requirement marked indirect in go.mod
+				rels = append(rels, spdxRelationship{
+					SPDXElementID:      toID,
+					RelationshipType:   "OPTIONAL_DEPENDENCY_OF",
+					RelatedSPDXElement: fromID,
+				})
+				continue
+			}
+			rels = append(rels, spdxRelationship{
+				SPDXElementID:      fromID,
+				RelationshipType:   "DEPENDS_ON",
+				RelatedSPDXElement: toID,
+			})
+		}
+	}
+	sort.Slice(rels, func(i, j int) bool {
+		if rels[i].SPDXElementID != rels[j].SPDXElementID {
+			return rels[i].SPDXElementID < rels[j].SPDXElementID
+		}
+		return rels[i].RelatedSPDXElement < rels[j].RelatedSPDXElement
+	})
+	doc.Relationships = append(doc.Relationships, rels...)
+
+	return doc
+}
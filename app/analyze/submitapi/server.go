@@ -0,0 +1,82 @@
+package submitapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// NewServer makes gRPC server of the submission API: requests must have "authorization: Bearer <token>" metadata.
+// It's served only over TLS: the token and tokens of tasks mustn't be sent in plaintext.
+func NewServer(h Handler, token string, creds credentials.TransportCredentials) *grpc.Server {
+	s := grpc.NewServer(grpc.Creds(creds), grpc.UnaryInterceptor(authInterceptor(token)))
+	s.RegisterService(&serviceDesc, h)
+	return s
+}
+
+func authInterceptor(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		var got string
+		if values := md.Get("authorization"); len(values) != 0 {
+			got = strings.TrimPrefix(values[0], "Bearer ")
+		}
+
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// RunServerIfConfigured serves the submission API on SUBMIT_API_ADDR in the background,
+// it's a no-op if it isn't set. SUBMIT_API_TOKEN, SUBMIT_API_TLS_CERT and SUBMIT_API_TLS_KEY are required then.
+func RunServerIfConfigured(h Handler) error {
+	addr := os.Getenv("SUBMIT_API_ADDR")
+	if addr == "" {
+		return nil
+	}
+
+	token := os.Getenv("SUBMIT_API_TOKEN")
+	if token == "" {
+		return errors.New("no SUBMIT_API_TOKEN for submit api")
+	}
+
+	certFile, keyFile := os.Getenv("SUBMIT_API_TLS_CERT"), os.Getenv("SUBMIT_API_TLS_KEY")
+	if certFile == "" || keyFile == "" {
+		return errors.New("no SUBMIT_API_TLS_CERT or SUBMIT_API_TLS_KEY for submit api")
+	}
+
+	creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+	if err != nil {
+		return errors.Wrap(err, "can't load tls certificate")
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return errors.Wrapf(err, "can't listen on %s", addr)
+	}
+
+	s := NewServer(h, token, creds)
+	go func() {
+		logrus.Infof("Serving submit api on %s", addr)
+		if err := s.Serve(l); err != nil {
+			logrus.Warnf("Submit api server failed: %s", err)
+		}
+	}()
+
+	return nil
+}
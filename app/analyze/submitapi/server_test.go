@@ -0,0 +1,134 @@
+package submitapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type fakeHandler struct {
+	pr   *task.PRAnalysis
+	repo *task.RepoAnalysis
+}
+
+func (h *fakeHandler) AnalyzePR(ctx context.Context, t *task.PRAnalysis) error {
+	h.pr = t
+	return nil
+}
+
+func (h *fakeHandler) AnalyzeRepo(ctx context.Context, t *task.RepoAnalysis) error {
+	h.repo = t
+	return errors.New("no branch")
+}
+
+func runTestServer(t *testing.T, h Handler) (*Client, func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	cert, pool := selfSignedCert(t)
+	s := NewServer(h, "token", credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{cert}}))
+	go s.Serve(l) //nolint:errcheck
+
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: pool})))
+	assert.NoError(t, err)
+
+	return NewClient(conn), func() {
+		conn.Close()
+		s.Stop()
+	}
+}
+
+func selfSignedCert(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestSubmit(t *testing.T) {
+	h := &fakeHandler{}
+	c, stop := runTestServer(t, h)
+	defer stop()
+
+	pr := &task.PRAnalysis{AnalysisGUID: "guid", UserID: 1}
+	pr.Repo.Owner = "golangci"
+	pr.PullRequestNumber = 3
+	reply, err := c.AnalyzePR(withToken("token"), pr)
+	assert.NoError(t, err)
+	assert.Equal(t, &SubmitReply{AnalysisGUID: "guid"}, reply)
+	assert.Equal(t, pr, h.pr)
+
+	reply, err = c.AnalyzeRepo(withToken("token"), &task.RepoAnalysis{Name: "golangci/worker", AnalysisGUID: "guid2"})
+	assert.NoError(t, err)
+	assert.Equal(t, &SubmitReply{AnalysisGUID: "guid2", Error: "no branch"}, reply)
+}
+
+func TestSubmitUnauthenticated(t *testing.T) {
+	h := &fakeHandler{}
+	c, stop := runTestServer(t, h)
+	defer stop()
+
+	_, err := c.AnalyzePR(withToken("other"), &task.PRAnalysis{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Nil(t, h.pr)
+}
+
+func TestSubmitPlaintextRejected(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	h := &fakeHandler{}
+	cert, _ := selfSignedCert(t)
+	s := NewServer(h, "token", credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{cert}}))
+	go s.Serve(l) //nolint:errcheck
+	defer s.Stop()
+
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithInsecure())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(withToken("token"), time.Second)
+	defer cancel()
+	_, err = NewClient(conn).AnalyzePR(ctx, &task.PRAnalysis{})
+	assert.Error(t, err)
+	assert.Nil(t, h.pr)
+}
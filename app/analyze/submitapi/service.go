@@ -0,0 +1,110 @@
+package submitapi
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"google.golang.org/grpc"
+)
+
+const serviceName = "golangci.worker.Submit"
+
+type SubmitReply struct {
+	AnalysisGUID string
+
+	// Error is the failure of the analysis including ones which aren't retried, it's empty if the analysis succeeded
+	Error string `json:",omitempty"`
+}
+
+// Handler processes submitted analyses bypassing the broker
+type Handler interface {
+	AnalyzePR(ctx context.Context, t *task.PRAnalysis) error
+	AnalyzeRepo(ctx context.Context, t *task.RepoAnalysis) error
+}
+
+func submitPRHandler(srv interface{}, ctx context.Context, dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+
+	var t task.PRAnalysis
+	if err := dec(&t); err != nil {
+		return nil, err
+	}
+
+	handle := func(ctx context.Context, req interface{}) (interface{}, error) {
+		t := req.(*task.PRAnalysis)
+		return buildReply(t.AnalysisGUID, srv.(Handler).AnalyzePR(ctx, t)), nil
+	}
+	if interceptor == nil {
+		return handle(ctx, &t)
+	}
+
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/AnalyzePR"}
+	return interceptor(ctx, &t, info, handle)
+}
+
+func submitRepoHandler(srv interface{}, ctx context.Context, dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+
+	var t task.RepoAnalysis
+	if err := dec(&t); err != nil {
+		return nil, err
+	}
+
+	handle := func(ctx context.Context, req interface{}) (interface{}, error) {
+		t := req.(*task.RepoAnalysis)
+		return buildReply(t.AnalysisGUID, srv.(Handler).AnalyzeRepo(ctx, t)), nil
+	}
+	if interceptor == nil {
+		return handle(ctx, &t)
+	}
+
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/AnalyzeRepo"}
+	return interceptor(ctx, &t, info, handle)
+}
+
+func buildReply(analysisGUID string, err error) *SubmitReply {
+	ret := &SubmitReply{AnalysisGUID: analysisGUID}
+	if err != nil {
+		ret.Error = err.Error()
+	}
+
+	return ret
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*Handler)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "AnalyzePR", Handler: submitPRHandler},
+		{MethodName: "AnalyzeRepo", Handler: submitRepoHandler},
+	},
+}
+
+// Client submits analyses to the worker, calls block until the analysis is processed
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+func NewClient(conn *grpc.ClientConn) *Client {
+	return &Client{conn: conn}
+}
+
+func (c Client) AnalyzePR(ctx context.Context, t *task.PRAnalysis, opts ...grpc.CallOption) (*SubmitReply, error) {
+	var reply SubmitReply
+	opts = append(opts, grpc.CallContentSubtype(codecName))
+	if err := c.conn.Invoke(ctx, "/"+serviceName+"/AnalyzePR", t, &reply, opts...); err != nil {
+		return nil, err
+	}
+
+	return &reply, nil
+}
+
+func (c Client) AnalyzeRepo(ctx context.Context, t *task.RepoAnalysis, opts ...grpc.CallOption) (*SubmitReply, error) {
+	var reply SubmitReply
+	opts = append(opts, grpc.CallContentSubtype(codecName))
+	if err := c.conn.Invoke(ctx, "/"+serviceName+"/AnalyzeRepo", t, &reply, opts...); err != nil {
+		return nil, err
+	}
+
+	return &reply, nil
+}
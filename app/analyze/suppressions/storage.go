@@ -0,0 +1,51 @@
+package suppressions
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/lib/apiclient"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+)
+
+//go:generate mockgen -package suppressions -source storage.go -destination storage_mock.go
+
+type Storage interface {
+	Add(ctx context.Context, owner, name string, s *Suppression) error
+	List(ctx context.Context, owner, name string) ([]Suppression, error)
+}
+
+type APIStorage struct {
+	api *apiclient.Client
+}
+
+func NewAPIStorage(client httputils.Client) *APIStorage {
+	return &APIStorage{
+		api: apiclient.New(client),
+	}
+}
+
+func (s APIStorage) Add(ctx context.Context, owner, name string, suppression *Suppression) error {
+	return s.api.AddSuppression(ctx, owner, name, suppression)
+}
+
+func (s APIStorage) List(ctx context.Context, owner, name string) ([]Suppression, error) {
+	var resp struct {
+		Suppressions []Suppression
+	}
+	if err := s.api.ListSuppressions(ctx, owner, name, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Suppressions, nil
+}
+
+// NopStorage has no suppressions and doesn't save them, e.g. for local analyses
+type NopStorage struct{}
+
+func (NopStorage) Add(ctx context.Context, owner, name string, suppression *Suppression) error {
+	return nil
+}
+
+func (NopStorage) List(ctx context.Context, owner, name string) ([]Suppression, error) {
+	return nil, nil
+}
@@ -0,0 +1,68 @@
+// Package suppressions keeps issues silenced by maintainers without code changes:
+// a reply "/golangci ignore" to a review comment suppresses the issue of the comment by its fingerprint.
+package suppressions
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+)
+
+// Suppression of an issue of a repo
+type Suppression struct {
+	Fingerprint string
+	File        string
+	Linter      string `json:",omitempty"` // empty if the linter isn't known by the comment
+
+	By        string // login of the maintainer
+	Reason    string `json:",omitempty"`
+	CreatedAt time.Time
+}
+
+var commandRe = regexp.MustCompile(`^/golangci\s+ignore\b(.*)$`)
+
+// ParseCommand finds the "/golangci ignore [reason]" command on a separate line of the comment
+func ParseCommand(body string) (reason string, ok bool) {
+	for _, line := range strings.Split(body, "\n") {
+		if m := commandRe.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			return strings.TrimSpace(m[1]), true
+		}
+	}
+
+	return "", false
+}
+
+// IsMaintainer checks an author association of the comment: only maintainers can suppress issues
+func IsMaintainer(authorAssociation string) bool {
+	switch authorAssociation {
+	case "OWNER", "MEMBER", "COLLABORATOR":
+		return true
+	default:
+		return false
+	}
+}
+
+// Filter returns issues without suppressed ones and the count of suppressed issues
+func Filter(issues []result.Issue, suppressions []Suppression) ([]result.Issue, int) {
+	if len(suppressions) == 0 {
+		return issues, 0
+	}
+
+	suppressed := map[string]bool{}
+	for _, s := range suppressions {
+		suppressed[s.Fingerprint] = true
+	}
+
+	fingerprints := result.Fingerprints(issues)
+	ret := make([]result.Issue, 0, len(issues))
+	for ind, i := range issues {
+		if suppressed[fingerprints[ind]] {
+			continue
+		}
+		ret = append(ret, i)
+	}
+
+	return ret, len(issues) - len(ret)
+}
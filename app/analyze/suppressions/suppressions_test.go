@@ -0,0 +1,47 @@
+package suppressions
+
+import (
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analyze/linters/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommand(t *testing.T) {
+	reason, ok := ParseCommand("/golangci ignore")
+	assert.True(t, ok)
+	assert.Empty(t, reason)
+
+	reason, ok = ParseCommand("Thanks!\n  /golangci ignore false positive: it's checked above \nbye")
+	assert.True(t, ok)
+	assert.Equal(t, "false positive: it's checked above", reason)
+
+	for _, body := range []string{"", "please /golangci ignore", "/golangci ignored", "/golangci check"} {
+		_, ok = ParseCommand(body)
+		assert.False(t, ok, body)
+	}
+}
+
+func TestIsMaintainer(t *testing.T) {
+	assert.True(t, IsMaintainer("OWNER"))
+	assert.True(t, IsMaintainer("COLLABORATOR"))
+	assert.False(t, IsMaintainer("CONTRIBUTOR"))
+	assert.False(t, IsMaintainer("NONE"))
+}
+
+func TestFilter(t *testing.T) {
+	issues := []result.Issue{
+		{FromLinter: "errcheck", File: "a.go", LineNumber: 1, Text: "unchecked"},
+		{FromLinter: "errcheck", File: "a.go", LineNumber: 5, Text: "unchecked"},
+		{FromLinter: "govet", File: "b.go", LineNumber: 2, Text: "shadow"},
+	}
+	fingerprints := result.Fingerprints(issues)
+
+	ret, n := Filter(issues, nil)
+	assert.Equal(t, issues, ret)
+	assert.Zero(t, n)
+
+	ret, n = Filter(issues, []Suppression{{Fingerprint: fingerprints[1]}, {Fingerprint: "unknown"}})
+	assert.Equal(t, []result.Issue{issues[0], issues[2]}, ret, "only the second occurrence is suppressed")
+	assert.Equal(t, 1, n)
+}
@@ -0,0 +1,116 @@
+package webhooks
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/pkg/errors"
+)
+
+// deliveryTTL is longer than GitHub retries deliveries and users redeliver them from the webhook page
+const deliveryTTL = 72 * time.Hour
+
+// Deliveries remember handled webhook deliveries by their X-GitHub-Delivery IDs: redelivered
+// webhooks must not enqueue analyses twice
+type Deliveries interface {
+	// Claim returns false if the delivery was already claimed
+	Claim(id string) (bool, error)
+
+	// Release forgets the delivery: it's handled again if GitHub redelivers it after a failure
+	Release(id string) error
+}
+
+// RedisDeliveries are shared by replicas receiving webhooks
+type RedisDeliveries struct {
+	pool *redis.Pool
+}
+
+func NewRedisDeliveries(redisURL string) *RedisDeliveries {
+	return &RedisDeliveries{
+		pool: &redis.Pool{
+			MaxIdle:     2,
+			IdleTimeout: 4 * time.Minute,
+			Dial: func() (redis.Conn, error) {
+				return redis.DialURL(redisURL)
+			},
+		},
+	}
+}
+
+func deliveryKey(id string) string {
+	return fmt.Sprintf("webhook_delivery:%s", id)
+}
+
+func (d RedisDeliveries) Claim(id string) (bool, error) {
+	conn := d.pool.Get()
+	defer conn.Close()
+
+	_, err := redis.String(conn.Do("SET", deliveryKey(id), "1", "NX", "EX", int(deliveryTTL/time.Second)))
+	if err == redis.ErrNil {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "can't claim delivery %s", id)
+	}
+
+	return true, nil
+}
+
+func (d RedisDeliveries) Release(id string) error {
+	conn := d.pool.Get()
+	defer conn.Close()
+
+	if _, err := conn.Do("DEL", deliveryKey(id)); err != nil {
+		return errors.Wrapf(err, "can't release delivery %s", id)
+	}
+	return nil
+}
+
+// MemoryDeliveries are used by single-process setups without Redis
+type MemoryDeliveries struct {
+	mu        sync.Mutex
+	claimedAt map[string]time.Time
+	now       func() time.Time
+}
+
+func NewMemoryDeliveries() *MemoryDeliveries {
+	return &MemoryDeliveries{claimedAt: map[string]time.Time{}, now: time.Now}
+}
+
+func (d *MemoryDeliveries) Claim(id string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	for claimedID, at := range d.claimedAt {
+		if now.Sub(at) > deliveryTTL {
+			delete(d.claimedAt, claimedID)
+		}
+	}
+
+	if _, ok := d.claimedAt[id]; ok {
+		return false, nil
+	}
+	d.claimedAt[id] = now
+	return true, nil
+}
+
+func (d *MemoryDeliveries) Release(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.claimedAt, id)
+	return nil
+}
+
+// newDeliveries returns Redis deliveries if REDIS_URL is set and in-memory ones otherwise
+func newDeliveries() Deliveries {
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		return NewRedisDeliveries(redisURL)
+	}
+
+	return NewMemoryDeliveries()
+}
@@ -0,0 +1,278 @@
+// Package webhooks receives GitHub webhooks in the direct-ingest mode: without the API the worker
+// verifies webhooks itself and enqueues analyses of pull requests. It's used by small self-hosted setups.
+package webhooks
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"github.com/golangci/golangci-worker/app/analyze/cancellation"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// maxBodySize is the max size of webhook payloads sent by GitHub
+const maxBodySize = 25 << 20
+
+// analyzedActions of pull request events change code of pull requests
+var analyzedActions = map[string]bool{
+	"opened":      true,
+	"reopened":    true,
+	"synchronize": true,
+}
+
+// ScheduleFunc enqueues the analysis, e.g. analyzequeue.SchedulePRAnalysis
+type ScheduleFunc func(t *task.PRAnalysis) error
+
+// CancelFunc enqueues the cancellation of the analysis, e.g. analyzequeue.ScheduleAnalysisCancellation
+type CancelFunc func(t *task.AnalysisCancellation) error
+
+type Handler struct {
+	secrets     []string
+	accessToken string
+	schedule    ScheduleFunc
+	cancel      CancelFunc
+	newGUID     func() (string, error)
+	deliveries  Deliveries
+
+	// lastAnalyses are GUIDs of the last analyses enqueued for pull requests: they are cancelled
+	// when pull requests are closed. They are kept in memory: analyses enqueued before a restart
+	// or by another replica aren't cancelled, they finish as usual
+	lastAnalyses *analysesByPR
+}
+
+type analysesByPR struct {
+	mu    sync.Mutex
+	guids map[string]string
+}
+
+func prKey(repo github.Repo, number int) string {
+	return fmt.Sprintf("%s#%d", repo.FullName(), number)
+}
+
+func (a *analysesByPR) set(key, guid string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.guids[key] = guid
+}
+
+func (a *analysesByPR) pop(key string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	guid := a.guids[key]
+	delete(a.guids, key)
+	return guid
+}
+
+var _ http.Handler = &Handler{}
+
+// NewHandler returns the handler of webhooks signed by any of secrets, analyses access GitHub by accessToken
+func NewHandler(secrets []string, accessToken string, schedule ScheduleFunc, cancel CancelFunc) *Handler {
+	return &Handler{
+		secrets:      secrets,
+		accessToken:  accessToken,
+		schedule:     schedule,
+		cancel:       cancel,
+		newGUID:      newAnalysisGUID,
+		deliveries:   newDeliveries(),
+		lastAnalyses: &analysesByPR{guids: map[string]string{}},
+	}
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		http.Error(w, "can't read body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxBodySize {
+		http.Error(w, "body is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if err = github.VerifyWebhookSignature(body, r.Header.Get(github.WebhookSignatureHeader), h.secrets...); err != nil {
+		logrus.Warnf("Rejected webhook delivery %s: %s", r.Header.Get(github.WebhookDeliveryHeader), err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	event := r.Header.Get(github.WebhookEventHeader)
+	if event != "pull_request" { // e.g. ping on creation of the webhook
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	e, err := github.ParsePullRequestEvent(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if e.Action != "closed" && !analyzedActions[e.Action] {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	deliveryID := r.Header.Get(github.WebhookDeliveryHeader)
+	if !h.claimDelivery(deliveryID) {
+		logrus.Infof("Skipped duplicate webhook delivery %s of %s#%d", deliveryID, e.Repo.FullName(), e.Number)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if e.Action == "closed" {
+		h.cancelLastAnalysis(w, e, deliveryID)
+		return
+	}
+
+	t, err := h.buildTask(e, deliveryID)
+	if err == nil {
+		err = h.schedule(t)
+	}
+	if err != nil {
+		// GitHub doesn't redeliver failed webhooks automatically: the error is shown in deliveries of the webhook
+		logrus.Errorf("Can't enqueue analysis of %s#%d: %s", e.Repo.FullName(), e.Number, err)
+		h.releaseDelivery(deliveryID)
+		http.Error(w, "can't enqueue analysis", http.StatusInternalServerError)
+		return
+	}
+
+	h.lastAnalyses.set(prKey(e.Repo, e.Number), t.AnalysisGUID)
+	logrus.Infof("Enqueued analysis %s of %s#%d (%s) by webhook", t.AnalysisGUID, e.Repo.FullName(), e.Number, e.Action)
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprintln(w, t.AnalysisGUID)
+}
+
+// claimDelivery returns false for deliveries which were already handled, e.g. redelivered by GitHub
+// after a timeout. Deliveries without IDs and deliveries which can't be checked are handled:
+// a duplicate analysis is better than a lost one.
+func (h Handler) claimDelivery(id string) bool {
+	if id == "" {
+		return true
+	}
+
+	claimed, err := h.deliveries.Claim(id)
+	if err != nil {
+		logrus.Warnf("Can't check webhook delivery %s for duplicates: %s", id, err)
+		return true
+	}
+
+	return claimed
+}
+
+// releaseDelivery lets a manual redelivery of the failed delivery be handled
+func (h Handler) releaseDelivery(id string) {
+	if id == "" {
+		return
+	}
+
+	if err := h.deliveries.Release(id); err != nil {
+		logrus.Warnf("Can't release webhook delivery %s: %s", id, err)
+	}
+}
+
+// cancelLastAnalysis cancels the last analysis of the closed pull request, finished analyses
+// ignore the cancellation
+func (h Handler) cancelLastAnalysis(w http.ResponseWriter, e *github.PullRequestEvent, deliveryID string) {
+	guid := h.lastAnalyses.pop(prKey(e.Repo, e.Number))
+	if guid == "" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	reason := cancellation.ReasonClosed
+	if e.Merged {
+		reason = cancellation.ReasonMerged
+	}
+	err := h.cancel(&task.AnalysisCancellation{
+		Repo:              e.Repo,
+		PullRequestNumber: e.Number,
+		AnalysisGUID:      guid,
+		Reason:            string(reason),
+	})
+	if err != nil {
+		logrus.Errorf("Can't enqueue cancellation of analysis %s of %s#%d: %s", guid, e.Repo.FullName(), e.Number, err)
+		h.releaseDelivery(deliveryID)
+		http.Error(w, "can't enqueue cancellation", http.StatusInternalServerError)
+		return
+	}
+
+	logrus.Infof("Enqueued cancellation of analysis %s of %s#%d (%s) by webhook", guid, e.Repo.FullName(), e.Number, reason)
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprintln(w, guid)
+}
+
+func (h Handler) buildTask(e *github.PullRequestEvent, deliveryID string) (*task.PRAnalysis, error) {
+	guid, err := h.newGUID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &task.PRAnalysis{
+		Context: github.Context{
+			Repo:              e.Repo,
+			GithubAccessToken: h.accessToken,
+			PullRequestNumber: e.Number,
+		},
+		APIRequestID: deliveryID,
+		AnalysisGUID: guid,
+	}, nil
+}
+
+// newAnalysisGUID returns a random UUID like GUIDs of analyses made by the API
+func newAnalysisGUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "can't make analysis guid")
+	}
+
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// RunServerIfConfigured serves webhooks on WEBHOOK_ADDR in the background, it's a no-op if it isn't set.
+// WEBHOOK_SECRET (comma-separated secrets during rotation) and WEBHOOK_GITHUB_TOKEN are required then.
+func RunServerIfConfigured(schedule ScheduleFunc, cancel CancelFunc) error {
+	addr := os.Getenv("WEBHOOK_ADDR")
+	if addr == "" {
+		return nil
+	}
+
+	var secrets []string
+	for _, s := range strings.Split(os.Getenv("WEBHOOK_SECRET"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			secrets = append(secrets, s)
+		}
+	}
+	if len(secrets) == 0 {
+		return errors.New("no WEBHOOK_SECRET for webhooks")
+	}
+
+	token := os.Getenv("WEBHOOK_GITHUB_TOKEN")
+	if token == "" {
+		return errors.New("no WEBHOOK_GITHUB_TOKEN for webhooks")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/webhooks/github", NewHandler(secrets, token, schedule, cancel))
+	go func() {
+		logrus.Infof("Serving webhooks on %s", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			logrus.Warnf("Webhooks server failed: %s", err)
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/analyze/analyzequeue/task"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "secret"
+
+func sign(body string) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(body)) //nolint:errcheck
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+var lastDeliveryID int32
+
+// deliver sends the webhook as a new delivery
+func deliver(h http.Handler, event, body, signature string) *httptest.ResponseRecorder {
+	id := fmt.Sprintf("delivery-%d", atomic.AddInt32(&lastDeliveryID, 1))
+	return deliverAs(h, id, event, body, signature)
+}
+
+func deliverAs(h http.Handler, id, event, body, signature string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/webhooks/github", strings.NewReader(body))
+	r.Header.Set(github.WebhookEventHeader, event)
+	r.Header.Set(github.WebhookDeliveryHeader, id)
+	r.Header.Set(github.WebhookSignatureHeader, signature)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func prEvent(action string) string {
+	return `{"action": "` + action + `", "number": 3, "pull_request": {"head": {"sha": "abc"}},
+		"repository": {"name": "worker", "owner": {"login": "golangci"}}}`
+}
+
+func TestEnqueuePullRequest(t *testing.T) {
+	var scheduled []*task.PRAnalysis
+	h := NewHandler([]string{testSecret}, "token", func(t *task.PRAnalysis) error {
+		scheduled = append(scheduled, t)
+		return nil
+	}, nil)
+
+	body := prEvent("synchronize")
+	w := deliverAs(h, "delivery", "pull_request", body, sign(body))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	require.Len(t, scheduled, 1)
+
+	pr := scheduled[0]
+	assert.Equal(t, github.Repo{Owner: "golangci", Name: "worker"}, pr.Repo)
+	assert.Equal(t, 3, pr.PullRequestNumber)
+	assert.Equal(t, "token", pr.GithubAccessToken)
+	assert.Equal(t, "delivery", pr.APIRequestID)
+	assert.Len(t, pr.AnalysisGUID, 36)
+	assert.Equal(t, pr.AnalysisGUID+"\n", w.Body.String())
+}
+
+func TestSkipDuplicateDeliveries(t *testing.T) {
+	fail := true
+	var scheduled []*task.PRAnalysis
+	h := NewHandler([]string{testSecret}, "token", func(t *task.PRAnalysis) error {
+		if fail {
+			return errors.New("queue is down")
+		}
+		scheduled = append(scheduled, t)
+		return nil
+	}, nil)
+
+	body := prEvent("opened")
+	assert.Equal(t, http.StatusInternalServerError, deliverAs(h, "d1", "pull_request", body, sign(body)).Code)
+
+	fail = false // the failed delivery is handled again
+	assert.Equal(t, http.StatusAccepted, deliverAs(h, "d1", "pull_request", body, sign(body)).Code)
+	assert.Equal(t, http.StatusOK, deliverAs(h, "d1", "pull_request", body, sign(body)).Code)
+	assert.Equal(t, http.StatusAccepted, deliverAs(h, "d2", "pull_request", body, sign(body)).Code)
+	assert.Len(t, scheduled, 2)
+}
+
+func TestMemoryDeliveriesExpire(t *testing.T) {
+	now := time.Now()
+	d := NewMemoryDeliveries()
+	d.now = func() time.Time { return now }
+
+	claimed, _ := d.Claim("d1")
+	assert.True(t, claimed)
+	claimed, _ = d.Claim("d1")
+	assert.False(t, claimed)
+
+	now = now.Add(deliveryTTL + time.Second)
+	claimed, _ = d.Claim("d1")
+	assert.True(t, claimed)
+}
+
+func TestCancelOnClose(t *testing.T) {
+	var cancelled []*task.AnalysisCancellation
+	h := NewHandler([]string{testSecret}, "token", func(t *task.PRAnalysis) error {
+		return nil
+	}, func(t *task.AnalysisCancellation) error {
+		cancelled = append(cancelled, t)
+		return nil
+	})
+
+	body := prEvent("opened")
+	w := deliver(h, "pull_request", body, sign(body))
+	require.Equal(t, http.StatusAccepted, w.Code)
+	guid := strings.TrimSpace(w.Body.String())
+
+	body = strings.Replace(prEvent("closed"), `"head"`, `"merged": true, "head"`, 1)
+	assert.Equal(t, http.StatusAccepted, deliver(h, "pull_request", body, sign(body)).Code)
+	require.Len(t, cancelled, 1)
+	assert.Equal(t, &task.AnalysisCancellation{
+		Repo:              github.Repo{Owner: "golangci", Name: "worker"},
+		PullRequestNumber: 3,
+		AnalysisGUID:      guid,
+		Reason:            "merged",
+	}, cancelled[0])
+
+	// the analysis is cancelled once
+	assert.Equal(t, http.StatusNoContent, deliver(h, "pull_request", body, sign(body)).Code)
+}
+
+func TestIgnoredEvents(t *testing.T) {
+	h := NewHandler([]string{testSecret}, "token", func(t *task.PRAnalysis) error {
+		panic("must not be scheduled")
+	}, func(t *task.AnalysisCancellation) error {
+		panic("must not be cancelled")
+	})
+
+	body := prEvent("labeled")
+	assert.Equal(t, http.StatusNoContent, deliver(h, "pull_request", body, sign(body)).Code)
+
+	body = prEvent("closed") // no analysis was enqueued by the worker
+	assert.Equal(t, http.StatusNoContent, deliver(h, "pull_request", body, sign(body)).Code)
+
+	ping := `{"zen": "Keep it logically awesome."}`
+	assert.Equal(t, http.StatusNoContent, deliver(h, "ping", ping, sign(ping)).Code)
+
+	body = prEvent("opened")
+	assert.Equal(t, http.StatusUnauthorized, deliver(h, "pull_request", body, sign(body+" ")).Code)
+	assert.Equal(t, http.StatusUnauthorized, deliver(h, "pull_request", body, "").Code)
+
+	r := httptest.NewRequest(http.MethodGet, "/webhooks/github", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
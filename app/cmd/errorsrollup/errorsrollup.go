@@ -0,0 +1,18 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/lib/errorbudget"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	rollup, err := errorbudget.GetWeeklyRollup(time.Now())
+	if err != nil {
+		logrus.Fatalf("Can't get weekly rollup of errors: %s", err)
+	}
+
+	rollup.Print(os.Stdout)
+}
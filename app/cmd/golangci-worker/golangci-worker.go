@@ -1,15 +1,235 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/golangci/golangci-shared/pkg/config"
+	"github.com/golangci/golangci-shared/pkg/logutil"
 	"github.com/golangci/golangci-worker/app/analyze/analyzequeue"
+	"github.com/golangci/golangci-worker/app/analyze/hooks"
+	"github.com/golangci/golangci-worker/app/analyze/orgfeed"
+	"github.com/golangci/golangci-worker/app/analyze/processors"
+	"github.com/golangci/golangci-worker/app/analyze/prstate"
+	"github.com/golangci/golangci-worker/app/analyze/repostate"
+	"github.com/golangci/golangci-worker/app/analyze/resultdiff"
+	"github.com/golangci/golangci-worker/app/analyze/submitapi"
+	"github.com/golangci/golangci-worker/app/analyze/webhooks"
+	"github.com/golangci/golangci-worker/app/lib/artifacts"
+	"github.com/golangci/golangci-worker/app/lib/buildinfo"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/experiments"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/golangci/golangci-worker/app/lib/janitor"
+	"github.com/golangci/golangci-worker/app/lib/metrics"
 	"github.com/golangci/golangci-worker/app/lib/queue"
+	"github.com/golangci/golangci-worker/app/lib/selfcheck"
+	"github.com/golangci/golangci-worker/app/lib/selfhosted"
+	"github.com/golangci/golangci-worker/app/lib/selfupdate"
 	"github.com/sirupsen/logrus"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "check":
+			os.Exit(runSelfCheck())
+		case "analyze-local":
+			os.Exit(runAnalyzeLocal(os.Args[2:]))
+		case "result-diff":
+			os.Exit(runResultDiff(os.Args[2:]))
+		}
+	}
+
+	tuneGOMAXPROCS()
+	if selfhosted.IsEnabled() {
+		registerSelfHosted()
+	}
+
+	if err := hooks.EnableFromEnv(); err != nil {
+		logrus.Fatalf("Can't enable plugins (registered are %v): %s", hooks.Registered(), err)
+	}
+
 	queue.Init()
 	analyzequeue.RegisterTasks()
-	if err := analyzequeue.RunWorker(); err != nil {
+
+	metrics.RunServerIfConfigured()
+	if err := submitapi.RunServerIfConfigured(analyzequeue.NewDirectDispatcher()); err != nil {
+		logrus.Fatalf("Can't run submit api: %s", err)
+	}
+	if err := webhooks.RunServerIfConfigured(analyzequeue.SchedulePRAnalysis, analyzequeue.ScheduleAnalysisCancellation); err != nil {
+		logrus.Fatalf("Can't run webhooks server: %s", err)
+	}
+	analyzequeue.RunLagExporter(context.Background())
+	janitor.RunFromEnv(context.Background())
+	artifacts.RunCleanupIfConfigured(context.Background())
+	runExperimentsSync()
+	analyzequeue.RecoverInterrupted(context.Background())
+
+	feedCtx, stopFeed := context.WithCancel(context.Background())
+	feedDone := orgfeed.RunDefault(feedCtx)
+
+	err := analyzequeue.RunWorker()
+	stopFeed()
+	<-feedDone // events of finished analyses mustn't be lost on deploys
+	if err != nil {
+		if err == analyzequeue.ErrWorkerOutdated {
+			logrus.Warnf("Worker %s is outdated: exit to be restarted on the new image", buildinfo.Version)
+			os.Exit(selfupdate.ExitCodeOutdated)
+		}
 		logrus.Fatalf("Can't run analyze worker: %s", err)
 	}
 }
+
+// tuneGOMAXPROCS limits threads of the worker by the CPU quota of its container: Go uses all CPUs of the host otherwise
+func tuneGOMAXPROCS() {
+	if os.Getenv("GOMAXPROCS") != "" {
+		return
+	}
+
+	if n := executors.LocalCPUQuota(); n > 0 && n < runtime.GOMAXPROCS(0) {
+		runtime.GOMAXPROCS(n)
+		logrus.Infof("Set GOMAXPROCS to %d by the CPU quota", n)
+	}
+}
+
+func runExperimentsSync() {
+	log := logutil.NewStderrLog("experiments")
+	log.SetLevel(logutil.LogLevelInfo)
+	cfg := config.NewEnvConfig(log)
+
+	// experiments not fetched from the API are configured by env vars
+	if !cfg.GetBool("EXPERIMENTS_FROM_API", false) {
+		return
+	}
+
+	interval := cfg.GetDuration("EXPERIMENTS_REFRESH_INTERVAL", time.Minute)
+	experiments.RunAPISync(context.Background(), httputils.GrequestsClient{}, log, interval)
+}
+
+func registerSelfHosted() {
+	reg := &selfhosted.Registration{
+		Version:      buildinfo.Version,
+		Capabilities: analyzequeue.TaskNames(),
+	}
+	creds, err := selfhosted.RegisterFromEnv(context.Background(), reg)
+	if err != nil {
+		logrus.Fatalf("Can't register self-hosted worker: %s", err)
+	}
+
+	if creds.Queue == queue.DefaultQueueName {
+		logrus.Fatalf("Can't run self-hosted worker on the shared queue %s", creds.Queue)
+	}
+
+	selfhosted.Activate(creds)
+	queue.SetQueueName(creds.Queue)
+	logrus.Infof("Registered self-hosted worker %s (%s) for %s", reg.Name, creds.WorkerID, creds.Org)
+}
+
+func runAnalyzeLocal(args []string) int {
+	fs := flag.NewFlagSet("analyze-local", flag.ExitOnError)
+	repoDir := fs.String("repo", ".", "path to the repo")
+	patchPath := fs.String("patch", "", "path to the patch of changes to analyze, e.g. output of git diff")
+	_ = fs.Parse(args)
+
+	if *patchPath == "" {
+		fmt.Fprintln(os.Stderr, "--patch is required")
+		fs.Usage()
+		return 2
+	}
+
+	state, err := processors.AnalyzeLocal(context.Background(), processors.LocalConfig{
+		RepoDir:   *repoDir,
+		PatchPath: *patchPath,
+	})
+	if state != nil {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if encErr := enc.Encode(state.ResultJSON); encErr != nil {
+			logrus.Errorf("Can't print result json: %s", encErr)
+			return 1
+		}
+	}
+	if err != nil {
+		logrus.Errorf("Local analysis failed: %s", err)
+		return 1
+	}
+
+	return 0
+}
+
+func runResultDiff(args []string) int {
+	fs := flag.NewFlagSet("result-diff", flag.ExitOnError)
+	repo := fs.String("repo", "", "owner/name of the GitHub repo")
+	kind := fs.String("kind", "pr", "kind of analyses: pr or repo")
+	printJSON := fs.Bool("json", false, "print the diff as json")
+	_ = fs.Parse(args)
+
+	repoParts := strings.Split(*repo, "/")
+	if len(repoParts) != 2 || fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: result-diff --repo owner/name [--kind pr|repo] [--json] <from analysis guid> <to analysis guid>")
+		fs.Usage()
+		return 2
+	}
+
+	ctx := context.Background()
+	fetch := func(id string) (*resultdiff.Analysis, error) {
+		client := httputils.GrequestsClient{}
+		switch *kind {
+		case "pr":
+			state, err := prstate.NewAPIStorage(client).GetState(ctx, repoParts[0], repoParts[1], id)
+			if err != nil {
+				return nil, err
+			}
+			return resultdiff.FromPRState(id, state)
+		case "repo":
+			state, err := repostate.NewAPIStorage(client).GetState(ctx, repoParts[0], repoParts[1], id)
+			if err != nil {
+				return nil, err
+			}
+			return resultdiff.FromRepoState(id, state)
+		default:
+			return nil, fmt.Errorf("unknown kind of analyses %q", *kind)
+		}
+	}
+
+	var analyses []*resultdiff.Analysis
+	for _, id := range fs.Args() {
+		a, err := fetch(id)
+		if err != nil {
+			logrus.Errorf("Can't fetch analysis %s: %s", id, err)
+			return 1
+		}
+		analyses = append(analyses, a)
+	}
+
+	diff := resultdiff.Compare(analyses[0], analyses[1])
+	if !*printJSON {
+		diff.Print(os.Stdout)
+		return 0
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(diff); err != nil {
+		logrus.Errorf("Can't print diff json: %s", err)
+		return 1
+	}
+
+	return 0
+}
+
+func runSelfCheck() int {
+	results := selfcheck.Run(context.Background(), selfcheck.DefaultChecks())
+	if !selfcheck.PrintReport(os.Stdout, results) {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,7 @@
+package main
+
+// Plugins linked into the worker, WORKER_PLUGINS enables them. Forks link their plugins here.
+import (
+	_ "github.com/golangci/golangci-worker/app/analyze/hooks/plugins/issuemetrics"
+	_ "github.com/golangci/golangci-worker/app/analyze/hooks/plugins/secretscan"
+)
@@ -0,0 +1,6 @@
+#!/bin/sh
+# govulncheck needs Go 1.18+ to load packages: it's run by the toolchain it was built with,
+# analyses keep Go of the image
+export GOROOT=/usr/local/go-govulncheck
+export PATH=$GOROOT/bin:$PATH
+exec $GOROOT/bin/govulncheck "$@"
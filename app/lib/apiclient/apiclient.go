@@ -0,0 +1,189 @@
+// Package apiclient is a typed client of the golangci API: every endpoint the worker calls is a method,
+// URLs aren't built by callers. Auth, signing and retries are made by httputils.Client,
+// error codes are mapped to errors of this package.
+//
+// Models of endpoints are defined by packages owning them (e.g. repoconfig.Config): they are passed
+// as interface{} to avoid import cycles. Only models of analysis states are defined here.
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNotFound     = errors.New("not found in API")
+	ErrUnauthorized = errors.New("unauthorized by API")
+	ErrConflict     = errors.New("conflicting change in API")
+)
+
+// IsNotFound returns true if the API returned 404 for the request
+func IsNotFound(err error) bool {
+	return errors.Cause(err) == ErrNotFound
+}
+
+// IsConflict returns true if the API rejected a conditional change: the resource was changed concurrently
+func IsConflict(err error) bool {
+	return errors.Cause(err) == ErrConflict
+}
+
+type Client struct {
+	host   string
+	client httputils.Client
+
+	// deltas is nil if analysis states are always sent fully
+	deltas *deltaEncoder
+}
+
+// New returns the client of the API at API_URL, API_STATE_DELTA_UPDATES=1 enables delta updates of analysis states
+func New(client httputils.Client) *Client {
+	c := NewWithHost(os.Getenv("API_URL"), client)
+	if os.Getenv("API_STATE_DELTA_UPDATES") == "1" {
+		c.EnableDeltaUpdates()
+	}
+
+	return c
+}
+
+func NewWithHost(host string, client httputils.Client) *Client {
+	return &Client{
+		host:   host,
+		client: client,
+	}
+}
+
+// EnableDeltaUpdates makes the client send updates of analysis states as merge patches
+// of the last states it sent: unchanged parts of results aren't sent again
+func (c *Client) EnableDeltaUpdates() {
+	c.deltas = newDeltaEncoder()
+}
+
+// buildURL joins escaped path segments
+func (c Client) buildURL(segments ...string) string {
+	escaped := make([]string, 0, len(segments))
+	for _, s := range segments {
+		escaped = append(escaped, url.PathEscape(s))
+	}
+
+	return fmt.Sprintf("%s/v1/%s", c.host, strings.Join(escaped, "/"))
+}
+
+func (c Client) repoURL(owner, name string, segments ...string) string {
+	return c.buildURL(append([]string{"repos", "github.com", owner, name}, segments...)...)
+}
+
+func mapError(err error) error {
+	se, ok := errors.Cause(err).(httputils.StatusError)
+	if !ok {
+		return err
+	}
+
+	switch se.StatusCode {
+	case http.StatusNotFound:
+		return errors.Wrap(ErrNotFound, se.URL)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errors.Wrap(ErrUnauthorized, se.URL)
+	case http.StatusConflict:
+		return errors.Wrap(ErrConflict, se.URL)
+	}
+
+	return err
+}
+
+func (c Client) get(ctx context.Context, reqURL string, resp interface{}) error {
+	bodyReader, err := c.client.Get(ctx, reqURL)
+	if err != nil {
+		return mapError(err)
+	}
+	defer bodyReader.Close()
+
+	if err = json.NewDecoder(bodyReader).Decode(resp); err != nil {
+		return errors.Wrapf(err, "can't read json body of %s", reqURL)
+	}
+
+	return nil
+}
+
+// getIfChanged decodes the resource into resp only if it doesn't match etag: changed is false otherwise
+func (c Client) getIfChanged(ctx context.Context, reqURL, etag string, resp interface{}) (newETag string, changed bool, err error) {
+	bodyReader, newETag, err := c.client.GetIfNoneMatch(ctx, reqURL, etag)
+	if err != nil {
+		return "", false, mapError(err)
+	}
+	if bodyReader == nil {
+		return newETag, false, nil
+	}
+	defer bodyReader.Close()
+
+	if err = json.NewDecoder(bodyReader).Decode(resp); err != nil {
+		return "", false, errors.Wrapf(err, "can't read json body of %s", reqURL)
+	}
+
+	return newETag, true, nil
+}
+
+func (c Client) put(ctx context.Context, reqURL string, req interface{}) error {
+	return mapError(c.client.Put(ctx, reqURL, req))
+}
+
+// putState sends the state fully or as a delta, failed deltas are retried as full updates:
+// the API can lack the state the delta was made against, e.g. after its restart
+func (c Client) putState(ctx context.Context, reqURL string, state interface{}) error {
+	if c.deltas == nil {
+		return c.put(ctx, reqURL, state)
+	}
+
+	full, err := json.Marshal(state)
+	if err != nil {
+		return errors.Wrap(err, "can't marshal state")
+	}
+
+	patch, ok, err := c.deltas.encode(reqURL, full)
+	if err == nil && ok {
+		if isEmptyPatch(patch) {
+			return nil
+		}
+		if err = mapError(c.client.Patch(ctx, reqURL, patch)); err == nil {
+			c.deltas.setSent(reqURL, full)
+			return nil
+		}
+	}
+	if err != nil {
+		analytics.Log(ctx).Warnf("Can't send delta of state %s, send it fully: %s", reqURL, err)
+	}
+
+	if err = c.put(ctx, reqURL, json.RawMessage(full)); err != nil {
+		c.deltas.forget(reqURL) // the API state is unknown
+		return err
+	}
+
+	c.deltas.setSent(reqURL, full)
+	return nil
+}
+
+func (c Client) post(ctx context.Context, reqURL string, req interface{}) error {
+	return mapError(c.client.Post(ctx, reqURL, req))
+}
+
+func (c Client) postWithResponse(ctx context.Context, reqURL string, req, resp interface{}) error {
+	bodyReader, err := c.client.PostWithResponse(ctx, reqURL, req)
+	if err != nil {
+		return mapError(err)
+	}
+	defer bodyReader.Close()
+
+	if err = json.NewDecoder(bodyReader).Decode(resp); err != nil {
+		return errors.Wrapf(err, "can't read json body of %s", reqURL)
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type request struct {
+	method, path, body string
+}
+
+var testMaxRetries = 1
+
+func newTestAPI(t *testing.T, code int, resp string) (*Client, *[]request, func()) {
+	var reqs []request
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		reqs = append(reqs, request{method: r.Method, path: r.URL.EscapedPath(), body: string(body)})
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(resp))
+	}))
+
+	client := httputils.GrequestsClient{Timeout: time.Second, MaxRetries: &testMaxRetries}
+	return NewWithHost(s.URL, client), &reqs, s.Close
+}
+
+func TestGetPRAnalysisState(t *testing.T) {
+	api, reqs, done := newTestAPI(t, http.StatusOK, `{"Status": "processed", "ResultJSON": {"Version": 1}}`)
+	defer done()
+
+	state, err := api.GetPRAnalysisState(context.Background(), "golangci", "golangci-worker", "guid")
+	assert.NoError(t, err)
+	assert.Equal(t, "processed", state.Status)
+	assert.Equal(t, json.RawMessage(`{"Version": 1}`), state.ResultJSON)
+	assert.Equal(t, []request{{method: http.MethodGet, path: "/v1/repos/github.com/golangci/golangci-worker/analyzes/guid/state"}}, *reqs)
+}
+
+func TestGetRepoAnalysisStateWithoutResult(t *testing.T) {
+	api, _, done := newTestAPI(t, http.StatusOK, `{"Status": "sent_to_queue", "ResultJSON": null}`)
+	defer done()
+
+	state, err := api.GetRepoAnalysisState(context.Background(), "golangci", "golangci-worker", "guid")
+	assert.NoError(t, err)
+	assert.Nil(t, state.ResultJSON)
+}
+
+func TestUpdateRepoAnalysisState(t *testing.T) {
+	api, reqs, done := newTestAPI(t, http.StatusOK, "")
+	defer done()
+
+	err := api.UpdateRepoAnalysisState(context.Background(), "golangci", "golangci-worker", "guid",
+		&RepoAnalysisState{Status: "processed"})
+	assert.NoError(t, err)
+	assert.Len(t, *reqs, 1)
+	assert.Equal(t, http.MethodPut, (*reqs)[0].method)
+	assert.Equal(t, "/v1/repos/github.com/golangci/golangci-worker/repoanalyzes/guid", (*reqs)[0].path)
+	assert.Contains(t, (*reqs)[0].body, `"Status":"processed"`)
+}
+
+func TestPathSegmentsAreEscaped(t *testing.T) {
+	api, reqs, done := newTestAPI(t, http.StatusOK, "{}")
+	defer done()
+
+	var cfg map[string]interface{}
+	assert.NoError(t, api.GetRepoConfig(context.Background(), "golangci", "../killswitches", &cfg))
+	assert.Equal(t, "/v1/repos/github.com/golangci/..%2Fkillswitches/config", (*reqs)[0].path)
+}
+
+func TestErrorMapping(t *testing.T) {
+	api, _, done := newTestAPI(t, http.StatusNotFound, "")
+	defer done()
+
+	var resp struct{}
+	err := api.GetLastHealthSnapshot(context.Background(), "golangci", "golangci-worker", &resp)
+	assert.True(t, IsNotFound(err))
+
+	api, _, done = newTestAPI(t, http.StatusForbidden, "")
+	defer done()
+	err = api.ReportUsage(context.Background(), "golangci", "golangci-worker", "guid", struct{}{})
+	assert.Equal(t, ErrUnauthorized, errors.Cause(err))
+
+	api, _, done = newTestAPI(t, http.StatusConflict, "")
+	defer done()
+	err = api.SwapCommitStatus(context.Background(), "golangci", "golangci-worker", "sha", "GolangCI", struct{}{})
+	assert.True(t, IsConflict(err))
+}
+
+func TestDeltaUpdatesOfState(t *testing.T) {
+	api, reqs, done := newTestAPI(t, http.StatusOK, "")
+	defer done()
+	api.EnableDeltaUpdates()
+
+	ctx := context.Background()
+	state := &PRAnalysisState{Status: "processing", ResultJSON: map[string]interface{}{"Issues": []string{"a"}, "Version": 1}}
+	assert.NoError(t, api.UpdatePRAnalysisState(ctx, "golangci", "golangci-worker", "guid", state))
+	assert.NoError(t, api.UpdatePRAnalysisState(ctx, "golangci", "golangci-worker", "guid", state))
+
+	state.Status = "processed/success"
+	state.ResultJSON = map[string]interface{}{"Issues": []string{"a"}, "Version": 2}
+	assert.NoError(t, api.UpdatePRAnalysisState(ctx, "golangci", "golangci-worker", "guid", state))
+
+	assert.Len(t, *reqs, 2, "unchanged state must not be sent")
+	assert.Equal(t, http.MethodPut, (*reqs)[0].method)
+	assert.Equal(t, http.MethodPatch, (*reqs)[1].method)
+	assert.JSONEq(t, `{"Status":"processed/success","ResultJSON":{"Version":2}}`, (*reqs)[1].body)
+}
+
+func TestFailedDeltaIsSentFully(t *testing.T) {
+	var methods []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if r.Method == http.MethodPatch {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	api := NewWithHost(s.URL, httputils.GrequestsClient{Timeout: time.Second, MaxRetries: &testMaxRetries})
+	api.EnableDeltaUpdates()
+
+	ctx := context.Background()
+	assert.NoError(t, api.UpdateRepoAnalysisState(ctx, "golangci", "golangci-worker", "guid", &RepoAnalysisState{Status: "processing"}))
+	assert.NoError(t, api.UpdateRepoAnalysisState(ctx, "golangci", "golangci-worker", "guid", &RepoAnalysisState{Status: "processed"}))
+	assert.Equal(t, []string{http.MethodPut, http.MethodPatch, http.MethodPut}, methods)
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	cases := []struct {
+		prev, next, patch string
+	}{
+		{`{"a":1,"b":{"c":1,"d":2}}`, `{"a":1,"b":{"c":1,"d":3}}`, `{"b":{"d":3}}`},
+		{`{"a":1,"b":2}`, `{"a":1}`, `{"b":null}`},
+		{`{"a":[1,2]}`, `{"a":[1,2,3]}`, `{"a":[1,2,3]}`},
+		{`{"a":{"b":1}}`, `{"a":5}`, `{"a":5}`},
+		{`{"a":null}`, `{"a":null,"b":null}`, `{}`},
+		{`{"id":9007199254740993}`, `{"id":9007199254740995}`, `{"id":9007199254740995}`},
+	}
+	for _, c := range cases {
+		patch, err := createMergePatch([]byte(c.prev), []byte(c.next))
+		assert.NoError(t, err)
+		assert.Equal(t, c.patch, string(patch), "%s -> %s", c.prev, c.next)
+	}
+}
@@ -0,0 +1,123 @@
+package apiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+// maxDeltaStates bounds memory of long-lived clients: states are forgotten and sent fully again
+const maxDeltaStates = 100
+
+// deltaEncoder remembers JSON of states sent by the client: next updates of the states are sent
+// as merge patches of them. It reduces payloads of frequent progress updates of big results.
+type deltaEncoder struct {
+	mu   sync.Mutex
+	sent map[string][]byte // by state URL
+}
+
+func newDeltaEncoder() *deltaEncoder {
+	return &deltaEncoder{sent: map[string][]byte{}}
+}
+
+// encode returns the merge patch of the state against the last state sent to reqURL, ok is false
+// if there is no such state
+func (e *deltaEncoder) encode(reqURL string, full []byte) (patch json.RawMessage, ok bool, err error) {
+	e.mu.Lock()
+	prev, ok := e.sent[reqURL]
+	e.mu.Unlock()
+	if !ok {
+		return nil, false, nil
+	}
+
+	patch, err = createMergePatch(prev, full)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return patch, true, nil
+}
+
+func (e *deltaEncoder) setSent(reqURL string, full []byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.sent[reqURL]; !ok && len(e.sent) >= maxDeltaStates {
+		e.sent = map[string][]byte{}
+	}
+	e.sent[reqURL] = full
+}
+
+func (e *deltaEncoder) forget(reqURL string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.sent, reqURL)
+}
+
+// isEmptyPatch returns true if the patch doesn't change anything
+func isEmptyPatch(patch json.RawMessage) bool {
+	return bytes.Equal(patch, []byte("{}"))
+}
+
+// createMergePatch returns the JSON merge patch (RFC 7386) transforming the prev document into next:
+// changed fields of objects are patched recursively, arrays are replaced. Null values are deleted
+// by merge patches: they are equal to absent fields for the API.
+func createMergePatch(prev, next []byte) (json.RawMessage, error) {
+	var prevDoc, nextDoc interface{}
+	if err := unmarshalNumbers(prev, &prevDoc); err != nil {
+		return nil, err
+	}
+	if err := unmarshalNumbers(next, &nextDoc); err != nil {
+		return nil, err
+	}
+
+	prevObj, prevIsObj := prevDoc.(map[string]interface{})
+	nextObj, nextIsObj := nextDoc.(map[string]interface{})
+	if !prevIsObj || !nextIsObj {
+		return next, nil // only objects can be patched
+	}
+
+	return json.Marshal(diffObjects(prevObj, nextObj))
+}
+
+func unmarshalNumbers(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // don't lose precision of int64
+	return dec.Decode(v)
+}
+
+func diffObjects(prev, next map[string]interface{}) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for k, nv := range next {
+		pv, ok := prev[k]
+		if !ok {
+			if nv != nil {
+				patch[k] = nv
+			}
+			continue
+		}
+
+		pObj, pIsObj := pv.(map[string]interface{})
+		nObj, nIsObj := nv.(map[string]interface{})
+		if pIsObj && nIsObj {
+			if sub := diffObjects(pObj, nObj); len(sub) != 0 {
+				patch[k] = sub
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(pv, nv) {
+			patch[k] = nv
+		}
+	}
+
+	for k, pv := range prev {
+		if _, ok := next[k]; !ok && pv != nil {
+			patch[k] = nil
+		}
+	}
+
+	return patch
+}
@@ -0,0 +1,100 @@
+package apiclient
+
+import (
+	"context"
+	"strconv"
+)
+
+// ReportUsage saves the usage record of the analysis
+func (c Client) ReportUsage(ctx context.Context, owner, name, analysisID string, rec interface{}) error {
+	return c.post(ctx, c.repoURL(owner, name, "analyzes", analysisID, "usage"), rec)
+}
+
+func (c Client) AddSuppression(ctx context.Context, owner, name string, s interface{}) error {
+	return c.post(ctx, c.repoURL(owner, name, "suppressions"), s)
+}
+
+// ListSuppressions decodes {"Suppressions": [...]} into resp
+func (c Client) ListSuppressions(ctx context.Context, owner, name string, resp interface{}) error {
+	return c.get(ctx, c.repoURL(owner, name, "suppressions"), resp)
+}
+
+func (c Client) GetOrgConfig(ctx context.Context, owner string, cfg interface{}) error {
+	return c.get(ctx, c.buildURL("orgs", "github.com", owner, "config"), cfg)
+}
+
+func (c Client) GetRepoConfig(ctx context.Context, owner, name string, cfg interface{}) error {
+	return c.get(ctx, c.repoURL(owner, name, "config"), cfg)
+}
+
+// GetOrgConfigIfChanged decodes the config into cfg only if its etag isn't etag
+func (c Client) GetOrgConfigIfChanged(ctx context.Context, owner, etag string, cfg interface{}) (string, bool, error) {
+	return c.getIfChanged(ctx, c.buildURL("orgs", "github.com", owner, "config"), etag, cfg)
+}
+
+// GetRepoConfigIfChanged decodes the config into cfg only if its etag isn't etag
+func (c Client) GetRepoConfigIfChanged(ctx context.Context, owner, name, etag string, cfg interface{}) (string, bool, error) {
+	return c.getIfChanged(ctx, c.repoURL(owner, name, "config"), etag, cfg)
+}
+
+// GetLastHealthSnapshot decodes {"Snapshot": ...} into resp
+func (c Client) GetLastHealthSnapshot(ctx context.Context, owner, name string, resp interface{}) error {
+	return c.get(ctx, c.repoURL(owner, name, "health", "snapshots", "last"), resp)
+}
+
+// PutHealthSnapshot saves the snapshot of the week, e.g. 2018-W47
+func (c Client) PutHealthSnapshot(ctx context.Context, owner, name, week string, snapshot interface{}) error {
+	return c.put(ctx, c.repoURL(owner, name, "health", "snapshots", week), snapshot)
+}
+
+func (c Client) GetKillSwitches(ctx context.Context, list interface{}) error {
+	return c.get(ctx, c.buildURL("killswitches"), list)
+}
+
+// GetWorkerVersion decodes the version requirement of workers into req
+func (c Client) GetWorkerVersion(ctx context.Context, req interface{}) error {
+	return c.get(ctx, c.buildURL("worker", "version"), req)
+}
+
+func (c Client) GetWorkerExperiments(ctx context.Context, experiments interface{}) error {
+	return c.get(ctx, c.buildURL("worker", "experiments"), experiments)
+}
+
+// RegisterWorker exchanges the registration of a self-hosted worker for its credentials
+func (c Client) RegisterWorker(ctx context.Context, reg, creds interface{}) error {
+	return c.postWithResponse(ctx, c.buildURL("worker", "register"), reg, creds)
+}
+
+// GetPRIssueAging decodes the issues snapshot of the last analysis of the pull request into snapshot
+func (c Client) GetPRIssueAging(ctx context.Context, owner, name string, pull int, snapshot interface{}) error {
+	return c.get(ctx, c.repoURL(owner, name, "pulls", strconv.Itoa(pull), "issueaging"), snapshot)
+}
+
+func (c Client) PutPRIssueAging(ctx context.Context, owner, name string, pull int, snapshot interface{}) error {
+	return c.put(ctx, c.repoURL(owner, name, "pulls", strconv.Itoa(pull), "issueaging"), snapshot)
+}
+
+// GetPRIssueCache decodes the cached issues of the last analysis of the pull request into entry
+func (c Client) GetPRIssueCache(ctx context.Context, owner, name string, pull int, entry interface{}) error {
+	return c.get(ctx, c.repoURL(owner, name, "pulls", strconv.Itoa(pull), "issuecache"), entry)
+}
+
+func (c Client) PutPRIssueCache(ctx context.Context, owner, name string, pull int, entry interface{}) error {
+	return c.put(ctx, c.repoURL(owner, name, "pulls", strconv.Itoa(pull), "issuecache"), entry)
+}
+
+// PostOrgFeedEvents appends {"Events": [...]} to the activity feed of the organization
+func (c Client) PostOrgFeedEvents(ctx context.Context, owner string, events interface{}) error {
+	return c.post(ctx, c.buildURL("orgs", "github.com", owner, "feed"), events)
+}
+
+// GetCommitStatus decodes the last commit status of the context set for the commit into resp
+func (c Client) GetCommitStatus(ctx context.Context, owner, name, sha, statusContext string, resp interface{}) error {
+	return c.get(ctx, c.repoURL(owner, name, "commits", sha, "statuses", statusContext), resp)
+}
+
+// SwapCommitStatus replaces the commit status of the context if it wasn't changed since it was read,
+// the API responds with 409 otherwise (see IsConflict)
+func (c Client) SwapCommitStatus(ctx context.Context, owner, name, sha, statusContext string, req interface{}) error {
+	return c.post(ctx, c.repoURL(owner, name, "commits", sha, "statuses", statusContext, "swap"), req)
+}
@@ -0,0 +1,79 @@
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// PRAnalysisState is a state of a pull request analysis
+type PRAnalysisState struct {
+	CreatedAt           time.Time
+	Status              string
+	ReportedIssuesCount int
+
+	// ResultJSON is set by processors, it's json.RawMessage in states got from the API (or nil)
+	ResultJSON interface{}
+	ErrorClass string `json:",omitempty"`
+}
+
+// RepoAnalysisState is a state of an analysis of a branch or a tag of a repo
+type RepoAnalysisState struct {
+	CreatedAt time.Time
+	Status    string
+
+	// ResultJSON is set by processors, it's json.RawMessage in states got from the API (or nil)
+	ResultJSON interface{}
+	ErrorClass string `json:",omitempty"`
+}
+
+// rawResult returns the raw result json decoded into res, it's nil if the result is absent
+func rawResult(res *json.RawMessage) interface{} {
+	if len(*res) == 0 || string(*res) == "null" {
+		return nil
+	}
+
+	return *res
+}
+
+func (c Client) GetPRAnalysisState(ctx context.Context, owner, name, analysisID string) (*PRAnalysisState, error) {
+	var res json.RawMessage
+	state := PRAnalysisState{ResultJSON: &res}
+	if err := c.get(ctx, c.repoURL(owner, name, "analyzes", analysisID, "state"), &state); err != nil {
+		return nil, err
+	}
+
+	state.ResultJSON = rawResult(&res)
+	return &state, nil
+}
+
+func (c Client) UpdatePRAnalysisState(ctx context.Context, owner, name, analysisID string, state *PRAnalysisState) error {
+	return c.putState(ctx, c.repoURL(owner, name, "analyzes", analysisID, "state"), state)
+}
+
+func (c Client) GetRepoAnalysisState(ctx context.Context, owner, name, analysisID string) (*RepoAnalysisState, error) {
+	var res json.RawMessage
+	state := RepoAnalysisState{ResultJSON: &res}
+	if err := c.get(ctx, c.repoURL(owner, name, "repoanalyzes", analysisID), &state); err != nil {
+		return nil, err
+	}
+
+	state.ResultJSON = rawResult(&res)
+	return &state, nil
+}
+
+// GetLastRepoAnalysisState returns the state of the last analysis of the branch
+func (c Client) GetLastRepoAnalysisState(ctx context.Context, owner, name, branch string) (*RepoAnalysisState, error) {
+	var res json.RawMessage
+	state := RepoAnalysisState{ResultJSON: &res}
+	if err := c.get(ctx, c.repoURL(owner, name, "branches", branch, "repoanalyzes", "last"), &state); err != nil {
+		return nil, err
+	}
+
+	state.ResultJSON = rawResult(&res)
+	return &state, nil
+}
+
+func (c Client) UpdateRepoAnalysisState(ctx context.Context, owner, name, analysisID string, state *RepoAnalysisState) error {
+	return c.putState(ctx, c.repoURL(owner, name, "repoanalyzes", analysisID), state)
+}
@@ -0,0 +1,195 @@
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golangci/golangci-shared/pkg/config"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/analytics"
+)
+
+// Kind of artifact: artifacts are saved only for selected kinds
+type Kind string
+
+const (
+	KindLintJSON Kind = "lint_json"
+	KindBuildLog Kind = "build_log"
+
+	// KindDependencyGraph is the module graph of the repo: modules, versions and requirements
+	KindDependencyGraph Kind = "dependency_graph"
+
+	// KindSBOM is the CycloneDX or SPDX SBOM of the analyzed commit, it's built only if repos opt in
+	KindSBOM Kind = "sbom"
+
+	// KindProfile is a pprof CPU or memory profile of golangci-lint
+	KindProfile Kind = "profile"
+
+	// KindFixPatch is a patch applying fixes suggested by linters
+	KindFixPatch Kind = "fix_patch"
+)
+
+// Artifact is recorded into result json of analysis
+type Artifact struct {
+	Kind      Kind
+	Name      string
+	URL       string
+	ExpiresAt time.Time
+}
+
+// Manager saves selected artifacts of analyses and deletes expired ones.
+// Artifacts are optional: errors are logged and never fail analysis.
+type Manager struct {
+	storage Storage
+
+	// privateStorage keeps artifacts of private repos: URLs of storage are served without authentication.
+	// Artifacts of private repos aren't saved if it's nil.
+	privateStorage Storage
+
+	ttl             time.Duration
+	kinds           map[Kind]bool // all kinds are saved if it's empty
+	cleanupInterval time.Duration
+
+	disabled bool
+}
+
+var defaultManager *Manager
+var defaultManagerOnce sync.Once
+
+// Default returns the manager configured by ARTIFACTS_DIR env var, it returns nil if it isn't set.
+// Artifacts of private repos are saved only if ARTIFACTS_PRIVATE_DIR is set: its URL prefix must require
+// access to the repo.
+func Default() *Manager {
+	defaultManagerOnce.Do(func() {
+		log := logutil.NewStderrLog("artifacts")
+		cfg := config.NewEnvConfig(log)
+
+		root := cfg.GetString("ARTIFACTS_DIR")
+		if root == "" {
+			return
+		}
+
+		storage := NewFSStorage(root, cfg.GetString("ARTIFACTS_URL_PREFIX"))
+		defaultManager = NewManager(storage, cfg.GetDuration("ARTIFACTS_TTL", 14*24*time.Hour),
+			parseKinds(cfg.GetString("ARTIFACTS_KINDS")))
+		if privateRoot := cfg.GetString("ARTIFACTS_PRIVATE_DIR"); privateRoot != "" {
+			defaultManager.privateStorage = NewFSStorage(privateRoot, cfg.GetString("ARTIFACTS_PRIVATE_URL_PREFIX"))
+		}
+	})
+
+	return defaultManager
+}
+
+// Disabled returns the manager saving no artifacts, e.g. for local analyses: nil is the default manager for processors
+func Disabled() *Manager {
+	return &Manager{disabled: true}
+}
+
+func NewManager(storage Storage, ttl time.Duration, kinds []Kind) *Manager {
+	m := &Manager{
+		storage:         storage,
+		ttl:             ttl,
+		kinds:           map[Kind]bool{},
+		cleanupInterval: time.Hour,
+	}
+	for _, k := range kinds {
+		m.kinds[k] = true
+	}
+
+	return m
+}
+
+func parseKinds(s string) []Kind {
+	var ret []Kind
+	for _, k := range strings.Split(s, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			ret = append(ret, Kind(k))
+		}
+	}
+
+	return ret
+}
+
+// IsEnabled returns true if artifacts of the kind are saved: it's used to skip building of unneeded artifacts
+func (m *Manager) IsEnabled(kind Kind) bool {
+	if m == nil || m.disabled {
+		return false
+	}
+
+	return len(m.kinds) == 0 || m.kinds[kind]
+}
+
+// Save saves the artifact of the analysis, it returns nil if the kind isn't enabled or saving failed.
+// Artifacts of private repos are saved only to the private storage.
+func (m *Manager) Save(ctx context.Context, analysisGUID string, private bool, kind Kind, name string, data []byte) *Artifact {
+	if !m.IsEnabled(kind) {
+		return nil
+	}
+
+	storage := m.storage
+	if private {
+		if m.privateStorage == nil {
+			return nil
+		}
+		storage = m.privateStorage
+	}
+
+	key := fmt.Sprintf("%s/%s", analysisGUID, name)
+	url, err := storage.Put(ctx, key, data)
+	if err != nil {
+		analytics.Log(ctx).Warnf("Failed to save artifact %s: %s", key, err)
+		return nil
+	}
+
+	return &Artifact{
+		Kind:      kind,
+		Name:      name,
+		URL:       url,
+		ExpiresAt: time.Now().Add(m.ttl),
+	}
+}
+
+// RunCleanupIfConfigured deletes expired artifacts of the default manager in the background until ctx is done,
+// it's a no-op if artifacts aren't saved
+func RunCleanupIfConfigured(ctx context.Context) {
+	if m := Default(); m != nil {
+		go m.RunCleanup(ctx)
+	}
+}
+
+// RunCleanup deletes expired artifacts every cleanup interval until ctx is done: walking of storage is slow,
+// so analyses never wait for it
+func (m *Manager) RunCleanup(ctx context.Context) {
+	t := time.NewTicker(m.cleanupInterval)
+	defer t.Stop()
+
+	for {
+		m.cleanup(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (m *Manager) cleanup(ctx context.Context) {
+	for _, s := range []Storage{m.storage, m.privateStorage} {
+		if s == nil {
+			continue
+		}
+
+		n, err := s.DeleteOlderThan(ctx, time.Now().Add(-m.ttl))
+		if err != nil {
+			analytics.Log(ctx).Warnf("Failed to delete expired artifacts: %s", err)
+			continue
+		}
+		if n != 0 {
+			analytics.Log(ctx).Infof("Deleted %d expired artifacts", n)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package artifacts
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestManager(t *testing.T, kinds ...Kind) (*Manager, string, func()) {
+	root, err := ioutil.TempDir("", "artifacts")
+	assert.NoError(t, err)
+
+	m := NewManager(NewFSStorage(root, "https://artifacts.golangci.com/"), time.Hour, kinds)
+	return m, root, func() { os.RemoveAll(root) }
+}
+
+func TestSave(t *testing.T) {
+	m, root, cleanup := newTestManager(t)
+	defer cleanup()
+
+	a := m.Save(context.Background(), "guid", false, KindLintJSON, "lint.json", []byte("{}"))
+	assert.NotNil(t, a)
+	assert.Equal(t, "https://artifacts.golangci.com/guid/lint.json", a.URL)
+	assert.Equal(t, KindLintJSON, a.Kind)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), a.ExpiresAt, time.Minute)
+
+	data, err := ioutil.ReadFile(filepath.Join(root, "guid", "lint.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", string(data))
+
+	assert.Nil(t, m.Save(context.Background(), "../guid", false, KindLintJSON, "lint.json", []byte("{}")))
+}
+
+func TestSaveOnlySelectedKinds(t *testing.T) {
+	m, _, cleanup := newTestManager(t, KindBuildLog)
+	defer cleanup()
+
+	assert.Nil(t, m.Save(context.Background(), "guid", false, KindLintJSON, "lint.json", []byte("{}")))
+	assert.NotNil(t, m.Save(context.Background(), "guid", false, KindBuildLog, "build_log.json", []byte("{}")))
+
+	var nilManager *Manager
+	assert.False(t, nilManager.IsEnabled(KindBuildLog))
+	assert.Nil(t, nilManager.Save(context.Background(), "guid", false, KindBuildLog, "build_log.json", nil))
+}
+
+func TestDeleteOlderThan(t *testing.T) {
+	m, root, cleanup := newTestManager(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	assert.NotNil(t, m.Save(ctx, "old", false, KindLintJSON, "lint.json", []byte("{}")))
+	assert.NotNil(t, m.Save(ctx, "new", false, KindLintJSON, "lint.json", []byte("{}")))
+
+	oldPath := filepath.Join(root, "old", "lint.json")
+	oldTime := time.Now().Add(-2 * time.Hour)
+	assert.NoError(t, os.Chtimes(oldPath, oldTime, oldTime))
+
+	n, err := m.storage.DeleteOlderThan(ctx, time.Now().Add(-m.ttl))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = os.Stat(oldPath)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(root, "new", "lint.json"))
+	assert.NoError(t, err)
+
+	// the dir is emptied: it's removed by the next cleanup after its modification
+	assert.NoError(t, os.Chtimes(filepath.Join(root, "old"), oldTime, oldTime))
+	n, err = m.storage.DeleteOlderThan(ctx, time.Now().Add(-m.ttl))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	_, err = os.Stat(filepath.Join(root, "old"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(root)
+	assert.NoError(t, err)
+}
+
+func TestRunCleanup(t *testing.T) {
+	m, root, cleanup := newTestManager(t)
+	defer cleanup()
+
+	assert.NotNil(t, m.Save(context.Background(), "old", false, KindLintJSON, "lint.json", []byte("{}")))
+	oldPath := filepath.Join(root, "old", "lint.json")
+	oldTime := time.Now().Add(-2 * time.Hour)
+	assert.NoError(t, os.Chtimes(oldPath, oldTime, oldTime))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m.RunCleanup(ctx) // the first cleanup is immediate
+
+	_, err := os.Stat(oldPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSavePrivate(t *testing.T) {
+	m, root, cleanup := newTestManager(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	assert.Nil(t, m.Save(ctx, "guid", true, KindLintJSON, "lint.json", []byte("{}")), "there is no private storage")
+	_, err := os.Stat(filepath.Join(root, "guid"))
+	assert.True(t, os.IsNotExist(err))
+
+	privateRoot, err := ioutil.TempDir("", "private-artifacts")
+	assert.NoError(t, err)
+	defer os.RemoveAll(privateRoot)
+	m.privateStorage = NewFSStorage(privateRoot, "https://api.golangci.com/v1/artifacts/")
+
+	a := m.Save(ctx, "guid", true, KindLintJSON, "lint.json", []byte("{}"))
+	assert.NotNil(t, a)
+	assert.Equal(t, "https://api.golangci.com/v1/artifacts/guid/lint.json", a.URL)
+	_, err = os.Stat(filepath.Join(privateRoot, "guid", "lint.json"))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(root, "guid"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestParseKinds(t *testing.T) {
+	assert.Equal(t, []Kind{KindLintJSON, KindBuildLog}, parseKinds(" lint_json, build_log,"))
+	assert.Nil(t, parseKinds(""))
+}
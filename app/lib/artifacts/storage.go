@@ -0,0 +1,98 @@
+package artifacts
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Storage is a sink for artifacts of analyses
+type Storage interface {
+	// Put saves data by key and returns its public URL
+	Put(ctx context.Context, key string, data []byte) (string, error)
+
+	// DeleteOlderThan deletes artifacts saved before t and returns count of deleted artifacts,
+	// it's slow and must be run in the background
+	DeleteOlderThan(ctx context.Context, t time.Time) (int, error)
+}
+
+// FSStorage keeps artifacts in a dir: it's a mounted bucket of object storage (e.g. by gcsfuse or s3fs)
+// or a dir served by a static server. URL of an artifact is urlPrefix/key.
+type FSStorage struct {
+	root      string
+	urlPrefix string
+}
+
+var _ Storage = FSStorage{}
+
+func NewFSStorage(root, urlPrefix string) *FSStorage {
+	return &FSStorage{
+		root:      root,
+		urlPrefix: strings.TrimSuffix(urlPrefix, "/"),
+	}
+}
+
+func (s FSStorage) Put(ctx context.Context, key string, data []byte) (string, error) {
+	p := filepath.Join(s.root, filepath.FromSlash(key))
+	if !strings.HasPrefix(p, filepath.Clean(s.root)+string(filepath.Separator)) {
+		return "", errors.Errorf("invalid artifact key %q", key)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return "", errors.Wrapf(err, "can't make dir for artifact %s", key)
+	}
+	if err := ioutil.WriteFile(p, data, 0644); err != nil {
+		return "", errors.Wrapf(err, "can't write artifact %s", key)
+	}
+
+	return s.urlPrefix + "/" + key, nil
+}
+
+// DeleteOlderThan deletes expired artifacts and then dirs of analyses left empty: dirs modified after t
+// are kept, Put can be writing into them
+func (s FSStorage) DeleteOlderThan(ctx context.Context, t time.Time) (int, error) {
+	deleted := 0
+	var expiredDirs []string
+	err := filepath.Walk(s.root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil // nothing was saved yet or it was deleted concurrently
+			}
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if info.IsDir() {
+			// walking is lexical: children follow their dir, so dirs are removed in reverse order
+			if p != s.root && info.ModTime().Before(t) {
+				expiredDirs = append(expiredDirs, p)
+			}
+			return nil
+		}
+		if !info.ModTime().Before(t) {
+			return nil
+		}
+
+		if err = os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "can't remove artifact %s", p)
+		}
+		deleted++
+		return nil
+	})
+	if err != nil {
+		return deleted, err
+	}
+
+	for i := len(expiredDirs) - 1; i >= 0; i-- {
+		_ = os.Remove(expiredDirs[i]) // it fails for non-empty dirs
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,64 @@
+package buildinfo
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/lib/executors"
+)
+
+// Version of the worker is set at build time by
+// -ldflags "-X github.com/golangci/golangci-worker/app/lib/buildinfo.Version=$(git describe --always)"
+var Version = "dev"
+
+// Info is stamped into results and analytics events of analyses:
+// differences of results can be traced to deployments
+type Info struct {
+	WorkerVersion string
+	LinterVersion string `json:",omitempty"`
+	GoVersion     string `json:",omitempty"`
+
+	// Experiments are evaluated for the analysis: name -> activity
+	Experiments map[string]bool `json:",omitempty"`
+}
+
+// New returns info without versions of tools: they are filled by Collect
+func New() *Info {
+	return &Info{WorkerVersion: Version}
+}
+
+// Collect fills versions of golangci-lint and Go toolchain of the analysis environment:
+// they are left empty if they can't be fetched
+func (i *Info) Collect(ctx context.Context, exec executors.Executor) {
+	i.LinterVersion = toolVersion(ctx, exec, "golangci-lint", "--version")
+	i.GoVersion = toolVersion(ctx, exec, "go", "version")
+}
+
+func toolVersion(ctx context.Context, exec executors.Executor, name string, args ...string) string {
+	out, err := exec.Run(ctx, name, args...)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(out)
+}
+
+// EventProps returns props of an analytics event
+func (i Info) EventProps() map[string]interface{} {
+	ret := map[string]interface{}{
+		"workerVersion": i.WorkerVersion,
+		"linterVersion": i.LinterVersion,
+		"goVersion":     i.GoVersion,
+	}
+
+	var active []string
+	for name, isActive := range i.Experiments {
+		if isActive {
+			active = append(active, name)
+		}
+	}
+	sort.Strings(active)
+	ret["activeExperiments"] = strings.Join(active, ",")
+	return ret
+}
@@ -0,0 +1,31 @@
+package buildinfo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().Run(ctx, "golangci-lint", "--version").Return("golangci-lint has version 1.12.2\n", nil)
+	exec.EXPECT().Run(ctx, "go", "version").Return("", errors.New("no go"))
+
+	i := New()
+	i.Collect(ctx, exec)
+	i.Experiments = map[string]bool{"b": true, "a": true, "c": false}
+	assert.Equal(t, &Info{
+		WorkerVersion: "dev",
+		LinterVersion: "golangci-lint has version 1.12.2",
+		Experiments:   map[string]bool{"b": true, "a": true, "c": false},
+	}, i)
+	assert.Equal(t, "a,b", i.EventProps()["activeExperiments"])
+}
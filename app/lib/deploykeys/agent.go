@@ -0,0 +1,206 @@
+package deploykeys
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/pkg/errors"
+)
+
+var agentPIDRe = regexp.MustCompile(`SSH_AGENT_PID=(\d+)`)
+
+// githubKnownHosts are SSH host keys of github.com published at https://api.github.com/meta:
+// they are written next to the agent socket, so executors don't need them. Update them on rotations.
+const githubKnownHosts = `github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl
+github.com ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBEmKSENjQEezOmxkZMy7opKgwFB9nkt5YRrYMjNuG5N87uRgg6CLrbo5wAdT/y6v0mKV0U2w0WZ2YB/++Tpockg=
+github.com ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABgQCj7ndNxQowgcQnjshcLrqPEiiphnt+VTTvDP6mHBL9j1aNUkY4Ue1gvwnGLVlOhGeYrnZaMgRK6+PKCUXaDbC7qtbW8gIkhL7aGCsOr/C56SJMy/BCZfxd1nWzAOxSDPgVsmerOBYfNqltV9/hWCqBywINIR+5dIg6JTJ72pcEpEjcYgXkE2YEFXV1JHnsKgbLWNlhScqb2UmyRkQyytRLtL+38TGxkxCflmO+5Z8CSSNY7GidjMIZ7Q4zMjA2n1nGrlTDkzwDCsw+wqFPGQA179cnfGWOWRVruj16z6XyvxvjJwbz0wQZ75XK5tKSb7FNyeIEs4TT4jk+S4dhPeAUC5y+bDYirYgM4GC7uEnztnZyaVWQ7B381AK4Qdrwt51ZqExKbQpTUNn+EjqoTwvqNj4kqx5QUCI0ThS/YkOxJCXmPUWZbhjpCg56i+2aB6CmK2JGhn57K5mj0MNdBXA4/WnwH6XoPWJzK5Nyu2zB3nAZp+S5hpQs+p1vN1/wsjk=
+`
+
+// Agent is the executor with an ephemeral ssh-agent holding the deploy key: only git commands of
+// the executor get the agent socket, linters and build tools run code of repos and mustn't use the key.
+// The key file is removed right after it's added to the agent, Stop kills the agent when cloning
+// and fetching are finished. Clean stops the agent before cleaning the executor.
+type Agent struct {
+	executors.Executor // without the agent env
+
+	*agentState // shared by copies made by WithEnv and WithWorkDir
+
+	// Fingerprint is the SHA256 fingerprint of the key, it's logged for audit
+	Fingerprint string
+}
+
+type agentState struct {
+	mu         sync.Mutex
+	dir        string
+	sock       string
+	knownHosts string
+	pid        string
+	stopped    bool
+}
+
+var _ executors.Executor = &Agent{}
+
+// StartAgent starts ssh-agent with the key on the host of exec: the dir of the agent is created
+// next to the work dir, not in it, so the repo can be cloned into the work dir and linters can't read it
+func StartAgent(ctx context.Context, exec executors.Executor, key *Key) (*Agent, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, errors.Wrap(err, "can't make agent dir name")
+	}
+	relDir := filepath.Join("..", ".golangci-deploykey-"+hex.EncodeToString(suffix))
+	a := &Agent{
+		Executor:   exec,
+		agentState: &agentState{dir: filepath.Join(exec.WorkDir(), relDir)},
+	}
+
+	if out, err := executors.Unrestricted(exec).Run(ctx, "mkdir", "-m", "700", a.dir); err != nil {
+		return nil, errors.Wrapf(err, "can't make agent dir: %s", out)
+	}
+
+	if err := a.start(ctx, exec, relDir, key); err != nil {
+		a.Stop(ctx) // exec is still owned by the caller
+		return nil, err
+	}
+
+	analytics.Log(ctx).Infof("Using deploy key %s", a.Fingerprint)
+	return a, nil
+}
+
+func (a *Agent) start(ctx context.Context, exec executors.Executor, relDir string, key *Key) error {
+	keyPath := filepath.Join(a.dir, "key")
+	if err := copyKey(ctx, exec, filepath.Join(relDir, "key"), key); err != nil {
+		return err
+	}
+	defer func() {
+		if out, err := executors.Unrestricted(exec).Run(ctx, "rm", "-f", keyPath); err != nil {
+			analytics.Log(ctx).Warnf("Can't remove deploy key file: %s, %s", err, out)
+		}
+	}()
+
+	// ssh-add refuses keys readable by others
+	if out, err := executors.Unrestricted(exec).Run(ctx, "chmod", "600", keyPath); err != nil {
+		return errors.Wrapf(err, "can't chmod deploy key: %s", out)
+	}
+
+	out, err := executors.Unrestricted(exec).Run(ctx, "ssh-keygen", "-l", "-E", "sha256", "-f", keyPath)
+	if err != nil {
+		return errors.Wrapf(err, "invalid deploy key: %s", out)
+	}
+	a.Fingerprint = parseFingerprint(out)
+
+	sock := filepath.Join(a.dir, "agent.sock")
+	out, err = executors.Unrestricted(exec).Run(ctx, "ssh-agent", "-a", sock)
+	if err != nil {
+		return errors.Wrapf(err, "can't start ssh-agent: %s", out)
+	}
+	m := agentPIDRe.FindStringSubmatch(out)
+	if m == nil {
+		return errors.Errorf("no pid in ssh-agent output %q", out)
+	}
+	a.pid = m[1]
+
+	a.sock = sock
+	a.knownHosts = filepath.Join(a.dir, "known_hosts")
+	if err = exec.WriteFile(ctx, filepath.Join(relDir, "known_hosts"), []byte(githubKnownHosts)); err != nil {
+		return errors.Wrap(err, "can't write known hosts")
+	}
+	if out, err = executors.Unrestricted(exec).WithEnv("SSH_AUTH_SOCK", sock).Run(ctx, "ssh-add", "-q", keyPath); err != nil {
+		return errors.Wrapf(err, "can't add deploy key to ssh-agent: %s", out)
+	}
+
+	return nil
+}
+
+// copyKey writes the key to the executor directly: it's never stored on the worker
+func copyKey(ctx context.Context, exec executors.Executor, dst string, key *Key) error {
+	if err := exec.WriteFile(ctx, dst, []byte(key.PrivateKey)); err != nil {
+		return errors.Wrap(err, "can't copy deploy key to executor")
+	}
+
+	return nil
+}
+
+// parseFingerprint returns SHA256:... of ssh-keygen -l output: "256 SHA256:... comment (ED25519)"
+func parseFingerprint(out string) string {
+	for _, f := range strings.Fields(out) {
+		if strings.HasPrefix(f, "SHA256:") {
+			return f
+		}
+	}
+
+	return strings.TrimSpace(out)
+}
+
+// Run runs git commands with the agent socket until the agent is stopped
+func (a Agent) Run(ctx context.Context, name string, args ...string) (string, error) {
+	a.mu.Lock()
+	sock := a.sock
+	if a.stopped {
+		sock = ""
+	}
+	a.mu.Unlock()
+
+	if name == "git" && sock != "" {
+		// host keys are pinned: unknown or changed keys of github.com fail cloning
+		sshCmd := "ssh -o StrictHostKeyChecking=yes -o UserKnownHostsFile=" + a.knownHosts
+		return a.Executor.WithEnv("SSH_AUTH_SOCK", sock).WithEnv("GIT_SSH_COMMAND", sshCmd).Run(ctx, name, args...)
+	}
+
+	return a.Executor.Run(ctx, name, args...)
+}
+
+// Stop kills the agent and removes its dir, it's a no-op for stopped agents
+func (a Agent) Stop(ctx context.Context) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stopped {
+		return
+	}
+	a.stopped = true
+
+	if a.pid != "" {
+		if out, err := executors.Unrestricted(a.Executor).Run(ctx, "kill", a.pid); err != nil {
+			analytics.Log(ctx).Warnf("Can't kill ssh-agent %s: %s, %s", a.pid, err, out)
+		}
+	}
+	if out, err := executors.Unrestricted(a.Executor).Run(ctx, "rm", "-rf", a.dir); err != nil {
+		analytics.Log(ctx).Warnf("Can't remove ssh-agent dir %s: %s, %s", a.dir, err, out)
+	}
+}
+
+// Clean stops the agent and cleans the executor
+func (a Agent) Clean() {
+	a.Stop(context.TODO())
+	a.Executor.Clean()
+}
+
+func (a Agent) WithEnv(k, v string) executors.Executor {
+	ret := a
+	ret.Executor = a.Executor.WithEnv(k, v)
+	return &ret
+}
+
+func (a Agent) WithWorkDir(wd string) executors.Executor {
+	ret := a
+	ret.Executor = a.Executor.WithWorkDir(wd)
+	return &ret
+}
+
+// Unwrap returns the executor without the agent: commands without network don't need the key
+func (a Agent) Unwrap() executors.Executor {
+	return a.Executor
+}
+
+// StopAgent stops the agent of exec if it has one: it's called when the repo is fetched
+func StopAgent(ctx context.Context, exec executors.Executor) {
+	if a, ok := exec.(*Agent); ok {
+		a.Stop(ctx)
+	}
+}
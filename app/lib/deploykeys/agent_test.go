@@ -0,0 +1,92 @@
+package deploykeys
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	any := gomock.Any()
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().WorkDir().Return("/tmp/golangci.pr")
+
+	var dir string
+	exec.EXPECT().Run(any, "mkdir", "-m", "700", any).Do(func(_ context.Context, _ string, args ...string) {
+		dir = args[2]
+	}).Return("", nil)
+	exec.EXPECT().WriteFile(any, any, []byte("private")).Return(nil)
+	exec.EXPECT().Run(any, "chmod", "600", any).Return("", nil)
+	exec.EXPECT().Run(any, "ssh-keygen", "-l", "-E", "sha256", "-f", any).
+		Return("256 SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8 deploy (ED25519)\n", nil)
+	exec.EXPECT().Run(any, "ssh-agent", "-a", any).
+		Return("SSH_AUTH_SOCK=/tmp/x/agent.sock; export SSH_AUTH_SOCK;\nSSH_AGENT_PID=4242; export SSH_AGENT_PID;\n", nil)
+	exec.EXPECT().WriteFile(any, any, []byte(githubKnownHosts)).Return(nil)
+	exec.EXPECT().WithEnv("SSH_AUTH_SOCK", any).Return(exec)
+	exec.EXPECT().Run(any, "ssh-add", "-q", any).Return("", nil)
+	exec.EXPECT().Run(any, "rm", "-f", any).Return("", nil) // the key file is removed after adding
+
+	agent, err := StartAgent(ctx, exec, &Key{PrivateKey: "private"})
+	assert.NoError(t, err)
+	assert.Equal(t, "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8", agent.Fingerprint)
+	assert.Equal(t, "/tmp", dir[:4], "the agent dir must be outside of the work dir")
+	assert.NotContains(t, dir, "golangci.pr/")
+
+	// only git gets the agent socket: linters run code of repos
+	gitExec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().WithEnv("SSH_AUTH_SOCK", dir+"/agent.sock").Return(gitExec)
+	gitExec.EXPECT().WithEnv("GIT_SSH_COMMAND",
+		"ssh -o StrictHostKeyChecking=yes -o UserKnownHostsFile="+dir+"/known_hosts").Return(gitExec)
+	gitExec.EXPECT().Run(ctx, "git", "fetch").Return("", nil)
+	_, err = agent.Run(ctx, "git", "fetch")
+	assert.NoError(t, err)
+	exec.EXPECT().Run(ctx, "golangci-lint", "run").Return("", nil)
+	_, err = agent.Run(ctx, "golangci-lint", "run")
+	assert.NoError(t, err)
+
+	gomock.InOrder(
+		exec.EXPECT().Run(any, "kill", "4242").Return("", nil),
+		exec.EXPECT().Run(any, "rm", "-rf", dir).Return("", nil),
+	)
+	StopAgent(ctx, agent)
+
+	exec.EXPECT().Run(ctx, "git", "fetch").Return("", nil) // without the socket after the stop
+	_, err = agent.Run(ctx, "git", "fetch")
+	assert.NoError(t, err)
+
+	exec.EXPECT().Clean()
+	agent.Clean()
+}
+
+func TestAgentInvalidKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	any := gomock.Any()
+	exec := executors.NewMockExecutor(ctrl)
+	exec.EXPECT().WorkDir().Return("/tmp/golangci.pr")
+	exec.EXPECT().Run(any, "mkdir", "-m", "700", any).Return("", nil)
+	exec.EXPECT().WriteFile(any, any, any).Return(nil)
+	exec.EXPECT().Run(any, "chmod", "600", any).Return("", nil)
+	exec.EXPECT().Run(any, "ssh-keygen", "-l", "-E", "sha256", "-f", any).Return("not a key file", assert.AnError)
+	exec.EXPECT().Run(any, "rm", "-f", any).Return("", nil)
+	exec.EXPECT().Run(any, "rm", "-rf", any).Return("", nil) // exec itself isn't cleaned
+
+	_, err := StartAgent(context.Background(), exec, &Key{PrivateKey: "invalid"})
+	assert.Error(t, err)
+}
+
+func TestAgentIsStrippedWithoutNetwork(t *testing.T) {
+	a := &Agent{Executor: executors.NewRestricted(&executors.Container{}, executors.AllowedCommands), agentState: &agentState{}}
+	e, ok := executors.WithoutNetwork(a)
+	assert.True(t, ok, "the agent wraps the container")
+	_, isAgent := e.(*Agent)
+	assert.False(t, isAgent)
+}
@@ -0,0 +1,91 @@
+// Package deploykeys delivers per-repo SSH deploy keys to analyses: private repos are cloned
+// without OAuth tokens of users. Keys are sealed by the API with the secret shared with workers,
+// the broker only sees sealed keys.
+package deploykeys
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// sealedPrefix marks the version of the sealing scheme: AES-256-GCM, the nonce precedes the ciphertext
+const sealedPrefix = "deploykey:v1:"
+
+// Key is an SSH private key in the PEM or OpenSSH format
+type Key struct {
+	PrivateKey string
+}
+
+func newAEAD(secret []byte) (cipher.AEAD, error) {
+	if len(secret) != 32 {
+		return nil, fmt.Errorf("invalid secret length %d: must be 32 bytes", len(secret))
+	}
+
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Seal encrypts the key by the secret, it's used by the API and tests
+func Seal(key *Key, secret []byte) (string, error) {
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", errors.Wrap(err, "can't make nonce")
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(key.PrivateKey), nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts the sealed key by the secret
+func Open(sealed string, secret []byte) (*Key, error) {
+	if !strings.HasPrefix(sealed, sealedPrefix) {
+		return nil, errors.New("unknown format of sealed deploy key")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, sealedPrefix))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid base64 of sealed deploy key")
+	}
+
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("sealed deploy key is too short")
+	}
+
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't decrypt deploy key") // the secret was rotated or the key was changed
+	}
+
+	return &Key{PrivateKey: string(plain)}, nil
+}
+
+// OpenFromEnv decrypts the sealed key by the base64 secret of DEPLOY_KEYS_SECRET
+func OpenFromEnv(sealed string) (*Key, error) {
+	secret, err := base64.StdEncoding.DecodeString(os.Getenv("DEPLOY_KEYS_SECRET"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid base64 of DEPLOY_KEYS_SECRET")
+	}
+
+	return Open(sealed, secret)
+}
@@ -0,0 +1,114 @@
+package diffanchor
+
+import "github.com/golangci/golangci-worker/app/lib/github"
+
+// Location is a line of the diff an issue is anchored to
+type Location struct {
+	File *File
+	Line Line
+}
+
+// Locate finds the added or context line of the new file in the diff
+func (p *Patch) Locate(path string, newLine int) (Location, bool) {
+	return p.locate(path, func(l Line) bool {
+		return l.Kind != Deleted && l.NewLine == newLine
+	})
+}
+
+// LocateDeleted finds the deleted line of the old file in the diff, path is a new path of the file
+func (p *Patch) LocateDeleted(path string, oldLine int) (Location, bool) {
+	return p.locate(path, func(l Line) bool {
+		return l.Kind == Deleted && l.OldLine == oldLine
+	})
+}
+
+func (p *Patch) locate(path string, match func(l Line) bool) (Location, bool) {
+	f := p.FindFile(path)
+	if f == nil {
+		return Location{}, false
+	}
+
+	for _, h := range f.Hunks {
+		for _, l := range h.Lines {
+			if match(l) {
+				return Location{File: f, Line: l}, true
+			}
+		}
+	}
+
+	return Location{}, false
+}
+
+func (loc Location) path() string {
+	if loc.File.Path == "" {
+		return loc.File.OldPath
+	}
+
+	return loc.File.Path
+}
+
+// GitHubAnchor anchors a review comment by a line and a side,
+// Position is needed only by the legacy API of review comments
+type GitHubAnchor struct {
+	Path     string
+	Line     int
+	Side     github.Side
+	Position int
+}
+
+func (loc Location) GitHub() GitHubAnchor {
+	ret := GitHubAnchor{
+		Path:     loc.path(),
+		Line:     loc.Line.NewLine,
+		Side:     github.SideRight,
+		Position: loc.Line.Position,
+	}
+	if loc.Line.Kind == Deleted {
+		ret.Line, ret.Side = loc.Line.OldLine, github.SideLeft
+	}
+
+	return ret
+}
+
+// GitLabAnchor is a position of a merge request discussion: old lines are set for deleted
+// and context lines, new lines are set for added and context lines
+type GitLabAnchor struct {
+	OldPath string `json:"old_path"`
+	NewPath string `json:"new_path"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+}
+
+func (loc Location) GitLab() GitLabAnchor {
+	// GitLab expects both paths to be set, they are equal for not renamed files
+	ret := GitLabAnchor{
+		OldPath: loc.File.OldPath,
+		NewPath: loc.File.Path,
+		OldLine: loc.Line.OldLine,
+		NewLine: loc.Line.NewLine,
+	}
+	if ret.OldPath == "" {
+		ret.OldPath = ret.NewPath
+	}
+	if ret.NewPath == "" {
+		ret.NewPath = ret.OldPath
+	}
+
+	return ret
+}
+
+// BitbucketAnchor is an inline anchor of a pull request comment: "to" is a line of the new file,
+// "from" is a line of the old file and it's set only for deleted lines
+type BitbucketAnchor struct {
+	Path string `json:"path"`
+	From int    `json:"from,omitempty"`
+	To   int    `json:"to,omitempty"`
+}
+
+func (loc Location) Bitbucket() BitbucketAnchor {
+	if loc.Line.Kind == Deleted {
+		return BitbucketAnchor{Path: loc.path(), From: loc.Line.OldLine}
+	}
+
+	return BitbucketAnchor{Path: loc.path(), To: loc.Line.NewLine}
+}
@@ -0,0 +1,80 @@
+package diffanchor
+
+import (
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnchors(t *testing.T) {
+	p, err := Parse(trickyPatch)
+	assert.NoError(t, err)
+
+	type anchors struct {
+		github    GitHubAnchor
+		gitlab    GitLabAnchor
+		bitbucket BitbucketAnchor
+	}
+	anchorsOf := func(loc Location) anchors {
+		return anchors{loc.GitHub(), loc.GitLab(), loc.Bitbucket()}
+	}
+
+	loc, ok := p.Locate("new/name.go", 2)
+	assert.True(t, ok)
+	assert.Equal(t, anchors{
+		github:    GitHubAnchor{Path: "new/name.go", Line: 2, Side: github.SideRight, Position: 3},
+		gitlab:    GitLabAnchor{OldPath: "old/name.go", NewPath: "new/name.go", NewLine: 2},
+		bitbucket: BitbucketAnchor{Path: "new/name.go", To: 2},
+	}, anchorsOf(loc), "added line of the renamed file")
+
+	loc, ok = p.Locate("new/name.go", 3)
+	assert.True(t, ok)
+	assert.Equal(t, anchors{
+		github:    GitHubAnchor{Path: "new/name.go", Line: 3, Side: github.SideRight, Position: 4},
+		gitlab:    GitLabAnchor{OldPath: "old/name.go", NewPath: "new/name.go", OldLine: 3, NewLine: 3},
+		bitbucket: BitbucketAnchor{Path: "new/name.go", To: 3},
+	}, anchorsOf(loc), "context line")
+
+	loc, ok = p.LocateDeleted("sql.go", 6)
+	assert.True(t, ok)
+	assert.Equal(t, anchors{
+		github:    GitHubAnchor{Path: "sql.go", Line: 6, Side: github.SideLeft, Position: 2},
+		gitlab:    GitLabAnchor{OldPath: "sql.go", NewPath: "sql.go", OldLine: 6},
+		bitbucket: BitbucketAnchor{Path: "sql.go", From: 6},
+	}, anchorsOf(loc), "deleted line")
+
+	loc, ok = p.Locate("new.go", 1)
+	assert.True(t, ok)
+	assert.Equal(t, GitLabAnchor{OldPath: "new.go", NewPath: "new.go", NewLine: 1}, loc.GitLab(), "new file")
+
+	loc, ok = p.LocateDeleted("gone.go", 1)
+	assert.True(t, ok)
+	assert.Equal(t, GitHubAnchor{Path: "gone.go", Line: 1, Side: github.SideLeft, Position: 1}, loc.GitHub())
+	assert.Equal(t, GitLabAnchor{OldPath: "gone.go", NewPath: "gone.go", OldLine: 1}, loc.GitLab(), "deleted file")
+}
+
+func TestLocateOutOfDiff(t *testing.T) {
+	p, err := Parse(trickyPatch)
+	assert.NoError(t, err)
+
+	for _, c := range []struct {
+		name string
+		path string
+		line int
+	}{
+		{"line out of hunks", "sql.go", 15},
+		{"line after the end of the file", "new/name.go", 4},
+		{"old path of the renamed file", "old/name.go", 1},
+		{"pure rename", "dir/moved.go", 1},
+		{"mode change", "script.sh", 1},
+		{"binary file", "logo.png", 1},
+		{"file out of the diff", "other.go", 1},
+	} {
+		_, ok := p.Locate(c.path, c.line)
+		assert.False(t, ok, c.name)
+	}
+
+	_, ok := p.LocateDeleted("sql.go", 5)
+	assert.False(t, ok, "context line isn't deleted")
+}
@@ -0,0 +1,303 @@
+// Package diffanchor maps issues (file, line) to anchors of comments in diffs of providers:
+// GitHub, GitLab and Bitbucket anchor comments differently, but all of them need to know
+// whether and where a line is in the diff. Reporters of all providers share the parsing.
+package diffanchor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LineKind is a kind of a line of a hunk
+type LineKind int
+
+const (
+	Context LineKind = iota
+	Added
+	Deleted
+)
+
+// Line is a line of a hunk
+type Line struct {
+	Kind LineKind
+	Text string
+
+	OldLine int // zero for added lines
+	NewLine int // zero for deleted lines
+
+	// Position is a position of the line in the diff of the file as GitHub counts it:
+	// the line after the first hunk header has position 1, next hunk headers are counted as lines
+	Position int
+}
+
+// Hunk is a hunk of the unified diff
+type Hunk struct {
+	OldStart int
+	NewStart int
+	Lines    []Line
+}
+
+// File is a diff of a file
+type File struct {
+	OldPath string // empty for new files
+	Path    string // empty for deleted files
+
+	Renamed     bool
+	ModeChanged bool
+	Binary      bool // binary files don't have hunks
+
+	Hunks []Hunk
+}
+
+// Patch is a parsed unified diff, e.g. the output of git diff
+type Patch struct {
+	Files []*File
+}
+
+var hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+type parser struct {
+	files []*File
+	file  *File
+
+	pos              int
+	oldLine, newLine int
+	oldLeft, newLeft int // lines left in the current hunk
+}
+
+// Parse parses the unified diff: headers of git diffs (renames, modes, binary files) are supported.
+// Truncated hunks and hunk lines out of hunks are errors: the patch is broken.
+func Parse(patch string) (*Patch, error) {
+	p := &parser{}
+	for n, line := range strings.Split(strings.TrimSuffix(patch, "\n"), "\n") {
+		if err := p.parseLine(line); err != nil {
+			return nil, fmt.Errorf("line %d of the patch: %s", n+1, err)
+		}
+	}
+	if p.inHunk() {
+		return nil, fmt.Errorf("patch is truncated")
+	}
+
+	return &Patch{Files: p.files}, nil
+}
+
+func (p *parser) inHunk() bool {
+	return p.oldLeft > 0 || p.newLeft > 0
+}
+
+func (p *parser) parseLine(line string) error {
+	if p.inHunk() {
+		if !strings.HasPrefix(line, "@@ ") && !strings.HasPrefix(line, "diff ") {
+			return p.parseHunkLine(line)
+		}
+		p.oldLeft, p.newLeft = 0, 0 // line counts of the hunk header are wrong, e.g. in handwritten patches
+	}
+
+	switch {
+	case strings.HasPrefix(line, "diff --git "):
+		p.startFile()
+		p.file.OldPath, p.file.Path = parseGitHeaderPaths(strings.TrimPrefix(line, "diff --git "))
+	case strings.HasPrefix(line, "diff "):
+		p.startFile()
+	case p.file != nil && strings.HasPrefix(line, "\\"):
+		p.pos++ // "\ No newline at end of file" after the last hunk
+	case strings.HasPrefix(line, "--- "):
+		if p.file == nil || len(p.file.Hunks) != 0 {
+			p.startFile() // plain unified diff without "diff" headers
+		}
+		p.file.OldPath = parseFilePath(line[len("--- "):], "a/")
+	case strings.HasPrefix(line, "+++ "):
+		if p.file == nil {
+			return fmt.Errorf("%q without a file header", line)
+		}
+		p.file.Path = parseFilePath(line[len("+++ "):], "b/")
+	case strings.HasPrefix(line, "@@ "):
+		return p.startHunk(line)
+	case p.file == nil:
+		return nil
+	case len(p.file.Hunks) != 0 && line != "" && strings.ContainsAny(line[:1], " +-"):
+		return fmt.Errorf("line %q is out of the hunk", line)
+	case strings.HasPrefix(line, "rename from "):
+		p.file.Renamed = true
+		p.file.OldPath = unquotePath(strings.TrimPrefix(line, "rename from "))
+	case strings.HasPrefix(line, "rename to "):
+		p.file.Renamed = true
+		p.file.Path = unquotePath(strings.TrimPrefix(line, "rename to "))
+	case strings.HasPrefix(line, "new mode "):
+		p.file.ModeChanged = true
+	case strings.HasPrefix(line, "new file mode "):
+		p.file.OldPath = ""
+	case strings.HasPrefix(line, "deleted file mode "):
+		p.file.Path = ""
+	case strings.HasPrefix(line, "Binary files "), line == "GIT binary patch":
+		p.file.Binary = true
+	}
+
+	return nil
+}
+
+func (p *parser) startFile() {
+	p.file = &File{}
+	p.files = append(p.files, p.file)
+	p.pos = -1
+}
+
+func (p *parser) startHunk(line string) error {
+	if p.file == nil {
+		return fmt.Errorf("hunk %q without a file header", line)
+	}
+
+	m := hunkHeaderRe.FindStringSubmatch(line)
+	if m == nil {
+		return fmt.Errorf("invalid hunk header %q", line)
+	}
+
+	p.oldLine, _ = strconv.Atoi(m[1])
+	p.newLine, _ = strconv.Atoi(m[3])
+	p.oldLeft, p.newLeft = hunkLen(m[2]), hunkLen(m[4])
+	p.pos++ // the first header has position 0
+	p.file.Hunks = append(p.file.Hunks, Hunk{OldStart: p.oldLine, NewStart: p.newLine})
+	return nil
+}
+
+func hunkLen(s string) int {
+	if s == "" {
+		return 1
+	}
+
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func (p *parser) parseHunkLine(line string) error {
+	p.pos++
+	if strings.HasPrefix(line, "\\") {
+		return nil // "\ No newline at end of file"
+	}
+
+	l := Line{Position: p.pos}
+	switch {
+	case strings.HasPrefix(line, "+"):
+		l.Kind, l.NewLine = Added, p.newLine
+		p.newLine++
+		p.newLeft--
+	case strings.HasPrefix(line, "-"):
+		l.Kind, l.OldLine = Deleted, p.oldLine
+		p.oldLine++
+		p.oldLeft--
+	case strings.HasPrefix(line, " "), line == "": // some tools trim trailing spaces of empty context lines
+		l.Kind, l.OldLine, l.NewLine = Context, p.oldLine, p.newLine
+		p.oldLine++
+		p.newLine++
+		p.oldLeft--
+		p.newLeft--
+	default:
+		return fmt.Errorf("invalid line %q of the hunk", line)
+	}
+	if p.oldLeft < 0 || p.newLeft < 0 {
+		return fmt.Errorf("line %q is out of the hunk", line)
+	}
+
+	l.Text = line[min(1, len(line)):]
+	h := &p.file.Hunks[len(p.file.Hunks)-1]
+	h.Lines = append(h.Lines, l)
+	return nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// parseGitHeaderPaths parses "a/old b/new" of the "diff --git" header: paths are overridden
+// by next headers, the header is ambiguous for paths with spaces
+func parseGitHeaderPaths(s string) (string, string) {
+	if strings.HasPrefix(s, `"`) || strings.HasSuffix(s, `"`) {
+		return parseQuotedGitHeaderPaths(s)
+	}
+
+	parts := strings.SplitN(s, " b/", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+
+	return strings.TrimPrefix(parts[0], "a/"), parts[1]
+}
+
+// parseQuotedGitHeaderPaths parses the header if any of paths is quoted: git quotes paths
+// with special chars, e.g. "a/tab\there.go" b/plain.go
+func parseQuotedGitHeaderPaths(s string) (string, string) {
+	var old string
+	if strings.HasPrefix(s, `"`) {
+		end := closingQuote(s)
+		if end == -1 {
+			return "", ""
+		}
+		old, s = unquotePath(s[:end+1]), strings.TrimPrefix(s[end+1:], " ")
+	} else {
+		i := strings.Index(s, ` "b/`)
+		if i == -1 {
+			return "", ""
+		}
+		old, s = s[:i], s[i+1:]
+	}
+
+	return strings.TrimPrefix(old, "a/"), strings.TrimPrefix(unquotePath(s), "b/")
+}
+
+func parseFilePath(s, prefix string) string {
+	if i := strings.Index(s, "\t"); i != -1 {
+		s = s[:i] // timestamps of plain unified diffs, git adds a tab after paths with spaces
+	}
+	s = strings.TrimSpace(s)
+	if s == "/dev/null" {
+		return ""
+	}
+
+	return strings.TrimPrefix(unquotePath(s), prefix)
+}
+
+// closingQuote returns the index of the quote closing the quoted string at the start of s
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++ // escaped char
+		case '"':
+			return i
+		}
+	}
+
+	return -1
+}
+
+// unquotePath unquotes the path quoted by git in C style: escapes of git are valid escapes of Go,
+// e.g. octal bytes of non-ASCII chars
+func unquotePath(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+
+	ret, err := strconv.Unquote(s)
+	if err != nil {
+		return s
+	}
+
+	return ret
+}
+
+// FindFile finds the diff of the file by its new path, deleted files are found by old paths
+func (p *Patch) FindFile(path string) *File {
+	for _, f := range p.Files {
+		if f.Path == path || (f.Path == "" && f.OldPath == path) {
+			return f
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package diffanchor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const trickyPatch = `diff --git a/old/name.go b/new/name.go
+similarity index 90%
+rename from old/name.go
+rename to new/name.go
+index 1..2 100644
+--- a/old/name.go
++++ b/new/name.go
+@@ -1,3 +1,3 @@
+ package name
+-var a = 1
++var a = 2
+ 
+diff --git a/moved.go b/dir/moved.go
+similarity index 100%
+rename from moved.go
+rename to dir/moved.go
+diff --git a/script.sh b/script.sh
+old mode 100644
+new mode 100755
+diff --git a/logo.png b/logo.png
+index 1..2 100644
+Binary files a/logo.png and b/logo.png differ
+diff --git a/sql.go b/sql.go
+index 1..2 100644
+--- a/sql.go
++++ b/sql.go
+@@ -5,4 +5,4 @@ func q() {
+ 	x()
+--- removed line starting with dashes
++++ added line starting with pluses
+ 	y()
+ }
+@@ -20,2 +20,3 @@ func r() {
+ 	z()
++	w()
+ }
+\ No newline at end of file
+diff --git a/new.go b/new.go
+new file mode 100644
+index 0..1
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,2 @@
++package x
++
+diff --git a/gone.go b/gone.go
+deleted file mode 100644
+index 1..0
+--- a/gone.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package gone
+`
+
+func TestParse(t *testing.T) {
+	p, err := Parse(trickyPatch)
+	assert.NoError(t, err)
+	if !assert.Len(t, p.Files, 7) {
+		return
+	}
+
+	renamed := p.Files[0]
+	assert.Equal(t, "old/name.go", renamed.OldPath)
+	assert.Equal(t, "new/name.go", renamed.Path)
+	assert.True(t, renamed.Renamed)
+	assert.Equal(t, []Hunk{{OldStart: 1, NewStart: 1, Lines: []Line{
+		{Kind: Context, Text: "package name", OldLine: 1, NewLine: 1, Position: 1},
+		{Kind: Deleted, Text: "var a = 1", OldLine: 2, Position: 2},
+		{Kind: Added, Text: "var a = 2", NewLine: 2, Position: 3},
+		{Kind: Context, Text: "", OldLine: 3, NewLine: 3, Position: 4},
+	}}}, renamed.Hunks)
+
+	moved := p.Files[1]
+	assert.Equal(t, &File{OldPath: "moved.go", Path: "dir/moved.go", Renamed: true}, moved)
+
+	assert.Equal(t, &File{OldPath: "script.sh", Path: "script.sh", ModeChanged: true}, p.Files[2])
+	assert.Equal(t, &File{OldPath: "logo.png", Path: "logo.png", Binary: true}, p.Files[3])
+
+	sql := p.Files[4]
+	assert.Len(t, sql.Hunks, 2)
+	assert.Equal(t, Line{Kind: Deleted, Text: "-- removed line starting with dashes", OldLine: 6, Position: 2},
+		sql.Hunks[0].Lines[1])
+	assert.Equal(t, Line{Kind: Added, Text: "++ added line starting with pluses", NewLine: 6, Position: 3},
+		sql.Hunks[0].Lines[2])
+	assert.Equal(t, Line{Kind: Added, Text: "\tw()", NewLine: 21, Position: 8}, sql.Hunks[1].Lines[1])
+
+	newFile := p.Files[5]
+	assert.Equal(t, "", newFile.OldPath)
+	assert.Equal(t, "new.go", newFile.Path)
+	assert.Equal(t, []Line{
+		{Kind: Added, Text: "package x", NewLine: 1, Position: 1},
+		{Kind: Added, Text: "", NewLine: 2, Position: 2},
+	}, newFile.Hunks[0].Lines)
+
+	gone := p.Files[6]
+	assert.Equal(t, "gone.go", gone.OldPath)
+	assert.Equal(t, "", gone.Path)
+	assert.Equal(t, []Line{{Kind: Deleted, Text: "package gone", OldLine: 1, Position: 1}}, gone.Hunks[0].Lines)
+}
+
+func TestParsePlainUnifiedDiff(t *testing.T) {
+	p, err := Parse("--- a.go\t2018-01-01 00:00:00\n+++ a.go\t2018-01-02 00:00:00\n@@ -1 +1 @@\n-a\n+b\n" +
+		"--- b.go\n+++ b.go\n@@ -1 +1,2 @@\n b\n+c\n")
+	assert.NoError(t, err)
+	if assert.Len(t, p.Files, 2) {
+		assert.Equal(t, "a.go", p.Files[0].Path)
+		assert.Equal(t, "b.go", p.Files[1].Path)
+		assert.Equal(t, Line{Kind: Added, Text: "c", NewLine: 2, Position: 2}, p.Files[1].Hunks[0].Lines[1])
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse("@@ -1 +1 @@\n-a\n+b\n")
+	assert.Error(t, err, "hunk without a file")
+
+	_, err = Parse("diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,2 +1,2 @@\n-a\n?b\n")
+	assert.Error(t, err, "invalid hunk line")
+
+	_, err = Parse("diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-a\n-b\n")
+	assert.Error(t, err, "line out of the hunk")
+
+	_, err = Parse("--- a/a.go\n+++ b/a.go\n@@ -1,1 +1,1 @@\n a\n b\n")
+	assert.EqualError(t, err, `line 5 of the patch: line " b" is out of the hunk`)
+
+	_, err = Parse("--- a/a.go\n+++ b/a.go\n@@ -1,2 +1,2 @@\n-a\n+b\n")
+	assert.EqualError(t, err, "patch is truncated")
+}
+
+func TestParseQuotedPaths(t *testing.T) {
+	p, err := Parse(`diff --git "a/caf\303\251.go" "b/caf\303\251.go"
+--- "a/caf\303\251.go"
++++ "b/caf\303\251.go"
+@@ -1 +1 @@
+-a
++b
+diff --git "a/tab\there.go" b/plain.go
+similarity index 100%
+rename from "tab\there.go"
+rename to plain.go
+diff --git a/with space.go b/with space.go
+--- a/with space.go	
++++ b/with space.go	
+@@ -1 +1 @@
+-a
++b
+`)
+	assert.NoError(t, err)
+	if assert.Len(t, p.Files, 3) {
+		assert.Equal(t, "café.go", p.Files[0].OldPath)
+		assert.Equal(t, "café.go", p.Files[0].Path)
+		assert.Equal(t, &File{OldPath: "tab\there.go", Path: "plain.go", Renamed: true}, p.Files[1])
+		assert.Equal(t, "with space.go", p.Files[2].Path)
+	}
+}
+
+func TestParseWrongHunkCounts(t *testing.T) {
+	p, err := Parse("diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,5 +1,5 @@\n-a\n+b\n@@ -10 +10 @@\n-c\n+d\n")
+	assert.NoError(t, err)
+	if assert.Len(t, p.Files, 1) && assert.Len(t, p.Files[0].Hunks, 2) {
+		assert.Equal(t, Line{Kind: Added, Text: "d", NewLine: 10, Position: 5}, p.Files[0].Hunks[1].Lines[1])
+	}
+}
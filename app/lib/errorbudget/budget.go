@@ -0,0 +1,107 @@
+package errorbudget
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/golangci/golangci-worker/app/lib/errorutils"
+	"github.com/pkg/errors"
+)
+
+const (
+	keyPrefix = "error_budget"
+	okField   = "ok"
+
+	// keep a bit more than a week to be able to build a rollup for any last 7 days
+	retention = 8 * 24 * time.Hour
+)
+
+func dayKey(t time.Time) string {
+	return fmt.Sprintf("%s:%s", keyPrefix, t.UTC().Format("2006-01-02"))
+}
+
+func openConn() (redis.Conn, error) {
+	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		return nil, errors.New("no REDIS_URL env var")
+	}
+
+	return redis.DialURL(redisURL)
+}
+
+// Record increments counter of analyses with the given class for the current day
+func Record(class errorutils.Class) error {
+	conn, err := openConn()
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to redis")
+	}
+	defer conn.Close()
+
+	field := string(class)
+	if class == errorutils.ClassNone {
+		field = okField
+	}
+
+	key := dayKey(time.Now())
+	if _, err = conn.Do("HINCRBY", key, field, 1); err != nil {
+		return errors.Wrapf(err, "failed to increment %s of %s", field, key)
+	}
+	if _, err = conn.Do("EXPIRE", key, int(retention/time.Second)); err != nil {
+		return errors.Wrapf(err, "failed to set expiration of %s", key)
+	}
+
+	return nil
+}
+
+type Rollup struct {
+	From, To time.Time
+	Total    int
+	Counts   map[string]int
+}
+
+func GetWeeklyRollup(now time.Time) (*Rollup, error) {
+	conn, err := openConn()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to redis")
+	}
+	defer conn.Close()
+
+	ret := &Rollup{
+		From:   now.AddDate(0, 0, -6),
+		To:     now,
+		Counts: map[string]int{},
+	}
+	for day := ret.From; !day.After(now); day = day.AddDate(0, 0, 1) {
+		counts, err := redis.IntMap(conn.Do("HGETALL", dayKey(day)))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get counters of %s", dayKey(day))
+		}
+
+		for class, n := range counts {
+			ret.Counts[class] += n
+			ret.Total += n
+		}
+	}
+
+	return ret, nil
+}
+
+func (r Rollup) Print(w io.Writer) {
+	fmt.Fprintf(w, "Analyses from %s to %s: %d\n", r.From.Format("2006-01-02"), r.To.Format("2006-01-02"), r.Total)
+	if r.Total == 0 {
+		return
+	}
+
+	fields := []string{okField}
+	for _, c := range errorutils.AllClasses {
+		fields = append(fields, string(c))
+	}
+
+	for _, f := range fields {
+		n := r.Counts[f]
+		fmt.Fprintf(w, "%-12s %6d %6.2f%%\n", f, n, 100*float64(n)/float64(r.Total))
+	}
+}
@@ -0,0 +1,54 @@
+package errorutils
+
+import (
+	"context"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/pkg/errors"
+)
+
+type Class string
+
+const (
+	ClassNone       Class = ""
+	ClassUserConfig Class = "user_config"
+	ClassRepoBuild  Class = "repo_build"
+	ClassProvider   Class = "provider"
+	ClassWorkerBug  Class = "worker_bug"
+	ClassTimeout    Class = "timeout"
+)
+
+var AllClasses = []Class{ClassUserConfig, ClassRepoBuild, ClassProvider, ClassWorkerBug, ClassTimeout}
+
+// Classify is the only place where analysis failures are mapped to classes
+func Classify(err error) Class {
+	if err == nil {
+		return ClassNone
+	}
+
+	if errors.Cause(err) == context.DeadlineExceeded || strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		return ClassTimeout
+	}
+
+	switch e := findError(err).(type) {
+	case *InternalError:
+		if e.Class != ClassNone {
+			return e.Class
+		}
+		return ClassWorkerBug
+	case *BadInputError:
+		if e.Class != ClassNone {
+			return e.Class
+		}
+		return ClassUserConfig
+	case *Error:
+		return outcomes[e.Kind].Class
+	}
+
+	if !github.IsRecoverableError(errors.Cause(err)) {
+		return ClassProvider
+	}
+
+	return ClassWorkerBug
+}
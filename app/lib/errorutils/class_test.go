@@ -0,0 +1,33 @@
+package errorutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/lib/github"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	testCases := []struct {
+		err error
+		exp Class
+	}{
+		{nil, ClassNone},
+		{context.DeadlineExceeded, ClassTimeout},
+		{fmt.Errorf("can't run: %s", context.DeadlineExceeded), ClassTimeout},
+		{&InternalError{PrivateDesc: "panic"}, ClassWorkerBug},
+		{&InternalError{PrivateDesc: "clone", Class: ClassProvider}, ClassProvider},
+		{pkgerrors.Wrap(&BadInputError{PublicDesc: "bad config"}, "failed"), ClassUserConfig},
+		{&BadInputError{PublicDesc: "can't load", Class: ClassRepoBuild}, ClassRepoBuild},
+		{github.ErrUnauthorized, ClassProvider},
+		{errors.New("something"), ClassWorkerBug},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.exp, Classify(tc.err), "%v", tc.err)
+	}
+}
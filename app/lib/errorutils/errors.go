@@ -3,6 +3,7 @@ package errorutils
 type InternalError struct {
 	PublicDesc  string
 	PrivateDesc string
+	Class       Class
 }
 
 func (e InternalError) Error() string {
@@ -11,6 +12,7 @@ func (e InternalError) Error() string {
 
 type BadInputError struct {
 	PublicDesc string
+	Class      Class
 }
 
 func (e BadInputError) Error() string {
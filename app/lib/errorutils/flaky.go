@@ -0,0 +1,43 @@
+package errorutils
+
+import (
+	"context"
+	"strings"
+)
+
+type flakySignature struct {
+	reason    string
+	substring string
+}
+
+// flakySignatures are failures which are likely to pass on the next attempt in the same workspace
+var flakySignatures = []flakySignature{
+	{"oom", "signal: killed"},
+	{"oom", "exit status 137"},
+	{"oom", "out of memory"},
+	{"oom", "cannot allocate memory"},
+	{"git", "the remote end hung up unexpectedly"},
+	{"git", "early EOF"},
+	{"git", "RPC failed"},
+	{"dns", "no such host"},
+	{"dns", "Temporary failure in name resolution"},
+	{"dns", "server misbehaving"},
+	{"network", "connection reset by peer"},
+	{"network", "TLS handshake timeout"},
+}
+
+// FlakyReason returns the kind of flaky failure (oom, git, dns, network) if err has its signature
+func FlakyReason(err error) (string, bool) {
+	if err == nil || Classify(err) == ClassTimeout || err == context.Canceled {
+		return "", false // the time of the task is over: a retry can't help
+	}
+
+	text := err.Error()
+	for _, s := range flakySignatures {
+		if strings.Contains(text, s.substring) {
+			return s.reason, true
+		}
+	}
+
+	return "", false
+}
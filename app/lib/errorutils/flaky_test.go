@@ -0,0 +1,30 @@
+package errorutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlakyReason(t *testing.T) {
+	testCases := []struct {
+		err    error
+		reason string
+	}{
+		{nil, ""},
+		{&InternalError{PrivateDesc: "can't run golangci-lint: signal: killed, "}, "oom"},
+		{fmt.Errorf("can't fetch: fatal: the remote end hung up unexpectedly"), "git"},
+		{errors.New("dial tcp: lookup proxy.golang.org: no such host"), "dns"},
+		{fmt.Errorf("can't run golangci-lint: %s", context.DeadlineExceeded), ""},
+		{&BadInputError{PublicDesc: "can't load packages"}, ""},
+	}
+
+	for _, tc := range testCases {
+		reason, ok := FlakyReason(tc.err)
+		assert.Equal(t, tc.reason, reason, "%v", tc.err)
+		assert.Equal(t, tc.reason != "", ok, "%v", tc.err)
+	}
+}
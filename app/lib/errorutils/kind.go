@@ -0,0 +1,183 @@
+package errorutils
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/pkg/errors"
+)
+
+// Kind defines how a failure of analysis is handled: which commit status is set and
+// whether the task is retried
+type Kind string
+
+const (
+	// KindTransient is a temporary failure (network, provider or worker blip): retry can succeed
+	KindTransient Kind = "transient"
+
+	// KindPermanent is a failure which will happen again on retry, e.g. a bug in the worker
+	KindPermanent Kind = "permanent"
+
+	// KindUserConfig is a failure caused by the repo: invalid config, not buildable code
+	KindUserConfig Kind = "user_config"
+
+	// KindProviderAuth is a lack of access to the repo or the pull request in the provider
+	KindProviderAuth Kind = "provider_auth"
+
+	// KindResourceLimit is an exceeded limit of time, memory or repo size
+	KindResourceLimit Kind = "resource_limit"
+)
+
+// Error is a typed error of analysis, use constructors like Transient to make it
+type Error struct {
+	Kind Kind
+
+	// PublicDesc is shown to users, it must not contain private info
+	PublicDesc string
+
+	Err error
+}
+
+func (e Error) Error() string {
+	if e.Err == nil {
+		return e.PublicDesc
+	}
+	if e.PublicDesc == "" {
+		return e.Err.Error()
+	}
+
+	return e.PublicDesc + ": " + e.Err.Error()
+}
+
+// Cause makes errors.Cause return the wrapped error
+func (e Error) Cause() error {
+	return e.Err
+}
+
+func newError(kind Kind, err error, publicDesc string) *Error {
+	return &Error{
+		Kind:       kind,
+		PublicDesc: publicDesc,
+		Err:        err,
+	}
+}
+
+func Transient(err error, publicDesc string) error {
+	return newError(KindTransient, err, publicDesc)
+}
+
+func Permanent(err error, publicDesc string) error {
+	return newError(KindPermanent, err, publicDesc)
+}
+
+func UserConfig(err error, publicDesc string) error {
+	return newError(KindUserConfig, err, publicDesc)
+}
+
+func ProviderAuth(err error, publicDesc string) error {
+	return newError(KindProviderAuth, err, publicDesc)
+}
+
+func ResourceLimit(err error, publicDesc string) error {
+	return newError(KindResourceLimit, err, publicDesc)
+}
+
+// findError returns the outermost typed error in the chain of wrapped errors
+func findError(err error) error {
+	type causer interface {
+		Cause() error
+	}
+
+	for err != nil {
+		switch err.(type) {
+		case *Error, *InternalError, *BadInputError:
+			return err
+		}
+
+		c, ok := err.(causer)
+		if !ok {
+			return err
+		}
+		err = c.Cause()
+	}
+
+	return nil
+}
+
+// WithAnalysisTimeout makes err the resource limit failure if ctx of the analysis exceeded its deadline.
+// Other deadlines, e.g. of requests to providers, aren't limits of the analysis: such errors are transient.
+func WithAnalysisTimeout(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() != context.DeadlineExceeded || KindOf(err) == KindResourceLimit {
+		return err
+	}
+
+	return ResourceLimit(err, "analysis timed out")
+}
+
+// KindOf returns kind of the error, untyped errors are considered transient.
+// Timeouts of analyses are typed by WithAnalysisTimeout.
+func KindOf(err error) Kind {
+	if err == nil {
+		return ""
+	}
+
+	switch e := findError(err).(type) {
+	case *Error:
+		return e.Kind
+	case *InternalError:
+		return KindTransient
+	case *BadInputError:
+		return KindUserConfig
+	}
+
+	if !github.IsRecoverableError(errors.Cause(err)) {
+		return KindProviderAuth
+	}
+
+	return KindTransient
+}
+
+// PublicDesc returns the public description of the typed error or "" if there is no one
+func PublicDesc(err error) string {
+	switch e := findError(err).(type) {
+	case *Error:
+		return e.PublicDesc
+	case *InternalError:
+		return e.PublicDesc
+	case *BadInputError:
+		return e.PublicDesc
+	}
+
+	return ""
+}
+
+// Outcome is how a failure of the kind is handled
+type Outcome struct {
+	Status github.Status
+
+	// StatusDesc is a description of commit status, PublicDesc of the error is used if it's empty
+	StatusDesc string
+
+	Retry bool
+
+	// Class is used for errors without an explicit class
+	Class Class
+}
+
+// outcomes is the only place where kinds of failures are mapped to handling of them
+var outcomes = map[Kind]Outcome{
+	KindTransient:     {Status: github.StatusError, Retry: true, Class: ClassWorkerBug},
+	KindPermanent:     {Status: github.StatusError, Retry: false, Class: ClassWorkerBug},
+	KindUserConfig:    {Status: github.StatusError, StatusDesc: "can't analyze", Retry: false, Class: ClassUserConfig},
+	KindProviderAuth:  {Status: github.StatusError, StatusDesc: "no access to the repo", Retry: false, Class: ClassProvider},
+	KindResourceLimit: {Status: github.StatusError, StatusDesc: "analysis exceeded limits", Retry: false, Class: ClassTimeout},
+}
+
+func OutcomeOf(err error) Outcome {
+	return outcomes[KindOf(err)]
+}
+
+// ShouldRetry reports whether the failed task should be retried
+func ShouldRetry(err error) bool {
+	return err != nil && OutcomeOf(err).Retry
+}
@@ -0,0 +1,70 @@
+package errorutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/lib/github"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutcomeOf(t *testing.T) {
+	testCases := []struct {
+		err        error
+		kind       Kind
+		retry      bool
+		publicDesc string
+	}{
+		{errors.New("network blip"), KindTransient, true, ""},
+		{&InternalError{PublicDesc: "can't clone", PrivateDesc: "token"}, KindTransient, true, "can't clone"},
+		{pkgerrors.Wrap(&BadInputError{PublicDesc: "bad config"}, "failed"), KindUserConfig, false, "bad config"},
+		{github.ErrPRNotFound, KindProviderAuth, false, ""},
+		{fmt.Errorf("can't get pull request: %s", context.DeadlineExceeded), KindTransient, true, ""},
+		{Permanent(errors.New("invalid task"), ""), KindPermanent, false, ""},
+		{pkgerrors.Wrap(UserConfig(errors.New("no go.mod"), "invalid go.mod"), "prepare"), KindUserConfig, false, "invalid go.mod"},
+		{ProviderAuth(errors.New("401"), "token was revoked"), KindProviderAuth, false, "token was revoked"},
+		{ResourceLimit(errors.New("oom"), "out of memory"), KindResourceLimit, false, "out of memory"},
+		{Transient(errors.New("502"), "github is unavailable"), KindTransient, true, "github is unavailable"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.kind, KindOf(tc.err), "%v", tc.err)
+		assert.Equal(t, tc.retry, ShouldRetry(tc.err), "%v", tc.err)
+		assert.Equal(t, tc.publicDesc, PublicDesc(tc.err), "%v", tc.err)
+		assert.Equal(t, github.StatusError, OutcomeOf(tc.err).Status, "%v", tc.err)
+	}
+
+	assert.False(t, ShouldRetry(nil))
+}
+
+func TestWithAnalysisTimeout(t *testing.T) {
+	err := fmt.Errorf("can't run golangci-lint: %s", context.DeadlineExceeded)
+	assert.Equal(t, err, WithAnalysisTimeout(context.Background(), err), "the deadline of a request isn't the analysis timeout")
+	assert.Nil(t, WithAnalysisTimeout(context.Background(), nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	timedOut := WithAnalysisTimeout(ctx, err)
+	assert.Equal(t, KindResourceLimit, KindOf(timedOut))
+	assert.False(t, ShouldRetry(timedOut))
+	assert.Equal(t, "analysis timed out", PublicDesc(timedOut))
+
+	limit := ResourceLimit(errors.New("oom"), "out of memory")
+	assert.Equal(t, limit, WithAnalysisTimeout(ctx, limit))
+
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	assert.Equal(t, err, WithAnalysisTimeout(cancelled, err), "cancellation isn't a timeout")
+}
+
+func TestClassifyTypedError(t *testing.T) {
+	assert.Equal(t, ClassUserConfig, Classify(UserConfig(errors.New("x"), "")))
+	assert.Equal(t, ClassProvider, Classify(ProviderAuth(github.ErrUnauthorized, "")))
+	assert.Equal(t, ClassWorkerBug, Classify(Permanent(errors.New("x"), "")))
+}
@@ -3,7 +3,6 @@ package executors
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"strings"
 	"time"
@@ -16,6 +15,8 @@ import (
 	"github.com/pkg/errors"
 )
 
+const networkCheckTimeout = 30 * time.Second
+
 type Container struct {
 	envStore
 	wd string
@@ -25,6 +26,8 @@ type Container struct {
 
 	containerID containers.ContainerID
 	log         logutil.Log
+
+	noNetwork bool
 }
 
 var _ Executor = &Container{}
@@ -89,6 +92,12 @@ func (c Container) Run(ctx context.Context, name string, args ...string) (string
 		return "", errors.New("deadline exceeded: it's before now")
 	}
 
+	cmd := append([]string{name}, args...)
+	if c.noNetwork {
+		// a new network namespace has only the loopback interface
+		cmd = append([]string{"unshare", "--net", "--map-root-user", "--"}, cmd...)
+	}
+
 	req := containers.BuildCommandRequest{
 		ContainerID: c.containerID,
 		Request: build.Request{
@@ -96,11 +105,12 @@ func (c Container) Run(ctx context.Context, name string, args ...string) (string
 			WorkDir:   c.wd,
 			Env:       c.env,
 			Kind:      build.RequestKindRun,
-			Args:      append([]string{name}, args...),
+			Args:      cmd,
 		},
 	}
 
-	return c.runBuildCommand(ctx, &req)
+	out, err := c.runBuildCommand(ctx, &req)
+	return limitOutputString(ctx, name, out, err)
 }
 
 func (c Container) runBuildCommand(ctx context.Context, req *containers.BuildCommandRequest) (string, error) {
@@ -138,23 +148,18 @@ func (c Container) runBuildCommand(ctx context.Context, req *containers.BuildCom
 	return buildResp.StdOut, nil
 }
 
-func (c Container) CopyFile(ctx context.Context, dst, src string) error {
-	srcContent, err := ioutil.ReadFile(src)
-	if err != nil {
-		return errors.Wrapf(err, "failed to read file %s", src)
-	}
-
+func (c Container) WriteFile(ctx context.Context, dst string, content []byte) error {
 	req := containers.BuildCommandRequest{
 		ContainerID: c.containerID,
 		Request: build.Request{
 			WorkDir: c.wd,
 			Env:     c.env,
 			Kind:    build.RequestKindCopy,
-			Args:    []string{dst, string(srcContent)},
+			Args:    []string{dst, string(content)},
 		},
 	}
 
-	_, err = c.runBuildCommand(ctx, &req)
+	_, err := c.runBuildCommand(ctx, &req)
 	return err
 }
 
@@ -200,6 +205,29 @@ func (c Container) WithEnv(k, v string) Executor {
 	return &eCopy
 }
 
+// WithoutNetwork returns the executor running commands in the container without network access
+func (c Container) WithoutNetwork() Executor {
+	eCopy := c
+	eCopy.noNetwork = true
+	return &eCopy
+}
+
+// checkNetworkIsolation runs a no-op command without network: unshare needs CAP_SYS_ADMIN
+// and the default seccomp profile of Docker blocks it, then commands without network always fail
+func (c Container) checkNetworkIsolation(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, networkCheckTimeout)
+	defer cancel()
+
+	nc := c
+	nc.noNetwork = true
+	if out, err := nc.Run(ctx, "true"); err != nil {
+		return errors.Wrapf(err, "can't create a network namespace in the container "+
+			"(it needs CAP_SYS_ADMIN or a seccomp profile allowing unshare): %s", out)
+	}
+
+	return nil
+}
+
 func (c Container) WorkDir() string {
 	return c.wd
 }
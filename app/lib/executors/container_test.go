@@ -0,0 +1,63 @@
+package executors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/golangci/golangci-api/pkg/app/buildagent/containers"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeOrchestrator runs commands by run: it returns the stdout and the command error
+func newFakeOrchestrator(run func(args []string) (string, string)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req containers.BuildCommandRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var resp containers.BuildCommandResponse
+		resp.BuildResponse.StdOut, resp.BuildResponse.CommandError = run(req.Request.Args)
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestCheckNetworkIsolation(t *testing.T) {
+	var mu sync.Mutex
+	var ran [][]string
+	blocked := false
+	s := newFakeOrchestrator(func(args []string) (string, string) {
+		mu.Lock()
+		defer mu.Unlock()
+		ran = append(ran, args)
+		if blocked {
+			return "unshare: unshare failed: Operation not permitted", "exit status 1"
+		}
+		return "", ""
+	})
+	defer s.Close()
+
+	c := &Container{orchestratorAddr: s.URL, log: logutil.NewStderrLog("test")}
+	e, ok := WithoutNetwork(NewRestricted(c, AllowedCommands))
+	assert.True(t, ok)
+
+	ctx := context.Background()
+	assert.NoError(t, CheckNetworkIsolation(ctx, e), "the probe isn't restricted by the allowlist")
+	mu.Lock()
+	assert.Equal(t, [][]string{{"unshare", "--net", "--map-root-user", "--", "true"}}, ran)
+	blocked = true
+	mu.Unlock()
+
+	err := CheckNetworkIsolation(ctx, e)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "seccomp")
+	}
+
+	assert.Error(t, CheckNetworkIsolation(ctx, NewRemoteShell("user", "host", "key")))
+}
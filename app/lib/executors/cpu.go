@@ -0,0 +1,93 @@
+package executors
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CPULimit returns count of CPUs available to commands of the executor: EXECUTOR_CPU_LIMIT
+// if it's set, otherwise nproc output limited by the cgroup CPU quota (a quota of containers
+// isn't visible to nproc). It's zero if the count is unknown.
+func CPULimit(ctx context.Context, exec Executor) int {
+	if n, err := strconv.Atoi(os.Getenv("EXECUTOR_CPU_LIMIT")); err == nil && n > 0 {
+		return n
+	}
+
+	quota := cgroupCPUQuota(ctx, exec)
+
+	out, err := Unrestricted(exec).Run(ctx, "nproc")
+	if err != nil {
+		return quota
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil || n < 0 {
+		return quota
+	}
+
+	if quota != 0 && quota < n {
+		return quota
+	}
+	return n
+}
+
+// cgroupCPUQuota returns CPUs of the cgroup v2 or v1 quota rounded up, it's zero if there is no quota
+func cgroupCPUQuota(ctx context.Context, exec Executor) int {
+	if out, err := Unrestricted(exec).Run(ctx, "cat", "/sys/fs/cgroup/cpu.max"); err == nil {
+		return parseCgroupCPU(out)
+	}
+
+	out, err := Unrestricted(exec).Run(ctx, "cat", "/sys/fs/cgroup/cpu/cpu.cfs_quota_us", "/sys/fs/cgroup/cpu/cpu.cfs_period_us")
+	if err != nil {
+		return 0
+	}
+
+	return parseCgroupCPU(out)
+}
+
+// LocalCPUQuota returns CPUs of the cgroup quota of the worker process, it's zero if there is no quota
+func LocalCPUQuota() int {
+	if data, err := ioutil.ReadFile("/sys/fs/cgroup/cpu.max"); err == nil {
+		return parseCgroupCPU(string(data))
+	}
+
+	quota, err := ioutil.ReadFile("/sys/fs/cgroup/cpu/cpu.cfs_quota_us")
+	if err != nil {
+		return 0
+	}
+	period, err := ioutil.ReadFile("/sys/fs/cgroup/cpu/cpu.cfs_period_us")
+	if err != nil {
+		return 0
+	}
+
+	return parseCgroupCPU(string(quota) + "\n" + string(period))
+}
+
+// parseCgroupCPU parses cpu.max of cgroup v2 ("$QUOTA $PERIOD", the quota is "max" if there is no quota)
+// or cpu.cfs_quota_us and cpu.cfs_period_us of cgroup v1 (the quota is -1 if there is no quota)
+func parseCgroupCPU(s string) int {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return 0
+	}
+
+	return quotaCPUs(fields[0], fields[1])
+}
+
+func quotaCPUs(quotaStr, periodStr string) int {
+	quota, err := strconv.ParseFloat(quotaStr, 64)
+	if err != nil || quota <= 0 {
+		return 0
+	}
+
+	period, err := strconv.ParseFloat(periodStr, 64)
+	if err != nil || period <= 0 {
+		return 0
+	}
+
+	return int(math.Ceil(quota / period))
+}
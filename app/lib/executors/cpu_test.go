@@ -0,0 +1,71 @@
+package executors
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCPULimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := NewMockExecutor(ctrl)
+	e.EXPECT().Run(gomock.Any(), "cat", gomock.Any()).Return("", errors.New("no cgroup")).AnyTimes()
+	e.EXPECT().Run(gomock.Any(), "cat", gomock.Any(), gomock.Any()).Return("", errors.New("no cgroup")).AnyTimes()
+	e.EXPECT().Run(gomock.Any(), "nproc").Return("8\n", nil)
+	assert.Equal(t, 8, CPULimit(context.Background(), e))
+
+	e.EXPECT().Run(gomock.Any(), "nproc").Return("", errors.New("not found"))
+	assert.Equal(t, 0, CPULimit(context.Background(), e))
+
+	os.Setenv("EXECUTOR_CPU_LIMIT", "2")
+	defer os.Unsetenv("EXECUTOR_CPU_LIMIT")
+	assert.Equal(t, 2, CPULimit(context.Background(), e), "nproc isn't run")
+}
+
+func TestCPULimitByCgroupQuota(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := NewMockExecutor(ctrl)
+	e.EXPECT().Run(gomock.Any(), "cat", "/sys/fs/cgroup/cpu.max").Return("150000 100000\n", nil)
+	e.EXPECT().Run(gomock.Any(), "nproc").Return("64\n", nil)
+	assert.Equal(t, 2, CPULimit(context.Background(), e), "nproc shows CPUs of the host")
+}
+
+func TestParseCgroupCPU(t *testing.T) {
+	assert.Equal(t, 0, parseCgroupCPU("max 100000"))
+	assert.Equal(t, 4, parseCgroupCPU("400000 100000"))
+	assert.Equal(t, 1, parseCgroupCPU("50000 100000"))
+	assert.Equal(t, 0, parseCgroupCPU("-1\n100000\n"))
+	assert.Equal(t, 3, parseCgroupCPU("300000\n100000\n"))
+	assert.Equal(t, 0, parseCgroupCPU(""))
+}
+
+func TestMemoryLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := NewMockExecutor(ctrl)
+	e.EXPECT().Run(gomock.Any(), "cat", "/sys/fs/cgroup/memory.max").Return("", errors.New("no cgroup v2"))
+	e.EXPECT().Run(gomock.Any(), "cat", "/sys/fs/cgroup/memory/memory.limit_in_bytes").Return("2147483648\n", nil)
+	assert.Equal(t, int64(2<<30), MemoryLimit(context.Background(), e))
+
+	e.EXPECT().Run(gomock.Any(), "cat", "/sys/fs/cgroup/memory.max").Return("max\n", nil)
+	assert.Zero(t, MemoryLimit(context.Background(), e))
+
+	os.Setenv("EXECUTOR_MEMORY_LIMIT_MB", "512")
+	defer os.Unsetenv("EXECUTOR_MEMORY_LIMIT_MB")
+	assert.Equal(t, int64(512<<20), MemoryLimit(context.Background(), e))
+}
+
+func TestParseCgroupMemory(t *testing.T) {
+	assert.Equal(t, int64(1024), parseCgroupMemory("1024\n"))
+	assert.Zero(t, parseCgroupMemory("9223372036854771712"), "no limit in cgroup v1")
+	assert.Zero(t, parseCgroupMemory("max"))
+}
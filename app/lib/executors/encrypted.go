@@ -0,0 +1,246 @@
+package executors
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/pkg/errors"
+)
+
+// WorkDirEncryption is a mode of the encrypted work dir of an analysis
+type WorkDirEncryption string
+
+const (
+	// WorkDirTmpfs places the work dir on tmpfs: files are never written to disks
+	WorkDirTmpfs WorkDirEncryption = "tmpfs"
+
+	// WorkDirLUKS places the work dir on a LUKS volume of a loop device with an ephemeral key:
+	// the key exists only in the kernel while the volume is open
+	WorkDirLUKS WorkDirEncryption = "luks"
+)
+
+// EncryptedVolumeFile is the file in the work dir of the base executor with the name of the LUKS volume:
+// the janitor closes volumes of crashed analyses by it
+const EncryptedVolumeFile = "encrypted.volume"
+
+// EncryptedVolumeRe matches names of LUKS volumes of encrypted work dirs
+var EncryptedVolumeRe = regexp.MustCompile(`^golangci-[0-9a-f]{16}$`)
+
+func ParseWorkDirEncryption(s string) (WorkDirEncryption, error) {
+	switch m := WorkDirEncryption(s); m {
+	case WorkDirTmpfs, WorkDirLUKS:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown work dir encryption %q", s)
+	}
+}
+
+// EncryptedWorkDir is the executor with the work dir on an ephemeral encrypted filesystem mounted
+// into the work dir of the base executor. Clean destroys the filesystem before cleaning the base executor.
+// Mounts need passwordless sudo for mount, umount, dd, cryptsetup, mkfs.ext4 and chown on the host of the executor.
+type EncryptedWorkDir struct {
+	Executor
+
+	base   Executor
+	mode   WorkDirEncryption
+	mnt    string
+	keyDir string // tmpfs for the LUKS key while the volume is being opened
+	image  string
+	volume string // name of the device mapper of the LUKS volume
+
+	mounted, keyMounted, opened bool
+}
+
+var _ Executor = &EncryptedWorkDir{}
+
+// NewEncryptedWorkDir mounts the filesystem of sizeMB, it cleans up partially made mounts on errors:
+// exec is still owned by the caller then
+func NewEncryptedWorkDir(ctx context.Context, exec Executor, mode WorkDirEncryption, sizeMB int) (*EncryptedWorkDir, error) {
+	if _, ok := exec.(*Container); ok {
+		// containers run without CAP_SYS_ADMIN: fail before mount fails by an obscure permission error
+		return nil, fmt.Errorf("can't make %s work dir: mounts need CAP_SYS_ADMIN which the container executor lacks", mode)
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, errors.Wrap(err, "can't make volume name")
+	}
+
+	base := exec.WorkDir()
+	e := &EncryptedWorkDir{
+		base:   exec,
+		mode:   mode,
+		mnt:    filepath.Join(base, "encrypted"),
+		keyDir: filepath.Join(base, "encrypted-key"),
+		image:  filepath.Join(base, "encrypted.img"),
+		volume: "golangci-" + hex.EncodeToString(suffix),
+	}
+
+	if err := e.setup(ctx, sizeMB); err != nil {
+		e.destroy(ctx)
+		return nil, errors.Wrapf(err, "can't make %s work dir", mode)
+	}
+
+	// the work dir is a subdir of the mount: files kept next to it, e.g. the deploy key, are encrypted too
+	wd := filepath.Join(e.mnt, "work")
+	if err := e.run(ctx, "mkdir", "-m", "700", wd); err != nil {
+		e.destroy(ctx)
+		return nil, errors.Wrapf(err, "can't make %s work dir", mode)
+	}
+
+	e.Executor = exec.WithWorkDir(wd)
+	return e, nil
+}
+
+func (e *EncryptedWorkDir) run(ctx context.Context, name string, args ...string) error {
+	if out, err := e.base.Run(ctx, name, args...); err != nil {
+		return fmt.Errorf("%s %s failed: %s, %s", name, strings.Join(args, " "), err, out)
+	}
+
+	return nil
+}
+
+func (e *EncryptedWorkDir) sudo(ctx context.Context, args ...string) error {
+	err := e.run(ctx, "sudo", append([]string{"-n"}, args...)...)
+	if err != nil && isPermissionError(err.Error()) {
+		return fmt.Errorf("no privileges for %s on the host of the executor, mounts need passwordless sudo "+
+			"and CAP_SYS_ADMIN: %s", args[0], err)
+	}
+
+	return err
+}
+
+func isPermissionError(out string) bool {
+	for _, s := range []string{"a password is required", "must be superuser", "Operation not permitted", "Permission denied"} {
+		if strings.Contains(out, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *EncryptedWorkDir) setup(ctx context.Context, sizeMB int) error {
+	owner, err := e.base.Run(ctx, "id", "-u")
+	if err != nil {
+		return errors.Wrap(err, "can't get user of executor")
+	}
+	owner = strings.TrimSpace(owner)
+
+	if err = e.run(ctx, "mkdir", "-m", "700", e.mnt); err != nil {
+		return err
+	}
+
+	switch e.mode {
+	case WorkDirTmpfs:
+		opts := fmt.Sprintf("size=%dm,mode=0700,uid=%s", sizeMB, owner)
+		if err = e.sudo(ctx, "mount", "-t", "tmpfs", "-o", opts, "tmpfs", e.mnt); err != nil {
+			return err
+		}
+		e.mounted = true
+		return nil
+	case WorkDirLUKS:
+		if err = e.openLUKS(ctx, sizeMB); err != nil {
+			return err
+		}
+		if err = e.sudo(ctx, "mkfs.ext4", "-q", e.device()); err != nil {
+			return err
+		}
+		if err = e.sudo(ctx, "mount", e.device(), e.mnt); err != nil {
+			return err
+		}
+		e.mounted = true
+		return e.sudo(ctx, "chown", owner, e.mnt)
+	default:
+		return fmt.Errorf("unknown work dir encryption %q", e.mode)
+	}
+}
+
+func (e *EncryptedWorkDir) device() string {
+	return "/dev/mapper/" + e.volume
+}
+
+// openLUKS formats and opens the volume by a random key: the key is kept on tmpfs and destroyed
+// right after the volume is opened, so the volume can't be opened again
+func (e *EncryptedWorkDir) openLUKS(ctx context.Context, sizeMB int) error {
+	if err := e.run(ctx, "mkdir", "-m", "700", e.keyDir); err != nil {
+		return err
+	}
+	if err := e.sudo(ctx, "mount", "-t", "tmpfs", "-o", "size=1m,mode=0700", "tmpfs", e.keyDir); err != nil {
+		return err
+	}
+	e.keyMounted = true
+	defer e.unmountKey(ctx)
+
+	key := filepath.Join(e.keyDir, "key")
+	if err := e.sudo(ctx, "dd", "if=/dev/urandom", "of="+key, "bs=64", "count=1", "status=none"); err != nil {
+		return err
+	}
+	if err := e.run(ctx, "truncate", "-s", fmt.Sprintf("%dM", sizeMB), e.image); err != nil {
+		return err
+	}
+	if err := e.sudo(ctx, "cryptsetup", "luksFormat", "--batch-mode", "--key-file", key, e.image); err != nil {
+		return err
+	}
+	if err := e.base.WriteFile(ctx, EncryptedVolumeFile, []byte(e.volume)); err != nil {
+		return errors.Wrap(err, "can't save volume name")
+	}
+	if err := e.sudo(ctx, "cryptsetup", "open", "--key-file", key, e.image, e.volume); err != nil {
+		return err
+	}
+	e.opened = true
+
+	return nil
+}
+
+func (e *EncryptedWorkDir) unmountKey(ctx context.Context) {
+	if !e.keyMounted {
+		return
+	}
+
+	if err := e.sudo(ctx, "umount", e.keyDir); err != nil {
+		analytics.Log(ctx).Warnf("Can't unmount key of encrypted work dir: %s", err)
+		return
+	}
+	e.keyMounted = false
+}
+
+// destroy unmounts the filesystem and closes the volume: files of the analysis are unreadable after it
+func (e *EncryptedWorkDir) destroy(ctx context.Context) {
+	if e.mounted {
+		// processes of the analysis can still hold files: unmount lazily
+		if err := e.sudo(ctx, "umount", "--lazy", e.mnt); err != nil {
+			analytics.Log(ctx).Warnf("Can't unmount encrypted work dir: %s", err)
+		}
+	}
+	e.unmountKey(ctx)
+	if e.opened {
+		if err := e.sudo(ctx, "cryptsetup", "close", "--deferred", e.volume); err != nil {
+			analytics.Log(ctx).Warnf("Can't close encrypted volume %s: %s", e.volume, err)
+		}
+	}
+}
+
+// Clean destroys the encrypted filesystem and cleans the base executor, it removes the image of the volume
+func (e EncryptedWorkDir) Clean() {
+	e.destroy(context.TODO())
+	e.base.Clean()
+}
+
+func (e EncryptedWorkDir) WithEnv(k, v string) Executor {
+	ret := e
+	ret.Executor = e.Executor.WithEnv(k, v)
+	return &ret
+}
+
+func (e EncryptedWorkDir) WithWorkDir(wd string) Executor {
+	ret := e
+	ret.Executor = e.Executor.WithWorkDir(wd)
+	return &ret
+}
@@ -0,0 +1,101 @@
+package executors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptedWorkDirTmpfs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	any := gomock.Any()
+	base := NewMockExecutor(ctrl)
+	inner := NewMockExecutor(ctrl)
+	base.EXPECT().WorkDir().Return("/tmp/wd")
+
+	gomock.InOrder(
+		base.EXPECT().Run(any, "id", "-u").Return("1000\n", nil),
+		base.EXPECT().Run(any, "mkdir", "-m", "700", "/tmp/wd/encrypted").Return("", nil),
+		base.EXPECT().Run(any, "sudo", "-n", "mount", "-t", "tmpfs", "-o", "size=64m,mode=0700,uid=1000",
+			"tmpfs", "/tmp/wd/encrypted").Return("", nil),
+		base.EXPECT().Run(any, "mkdir", "-m", "700", "/tmp/wd/encrypted/work").Return("", nil),
+		base.EXPECT().WithWorkDir("/tmp/wd/encrypted/work").Return(inner),
+		base.EXPECT().Run(any, "sudo", "-n", "umount", "--lazy", "/tmp/wd/encrypted").Return("", nil),
+		base.EXPECT().Clean(),
+	)
+
+	e, err := NewEncryptedWorkDir(ctx, base, WorkDirTmpfs, 64)
+	assert.NoError(t, err)
+	e.Clean()
+}
+
+func TestEncryptedWorkDirLUKSFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	any := gomock.Any()
+	base := NewMockExecutor(ctrl)
+	base.EXPECT().WorkDir().Return("/tmp/wd")
+
+	gomock.InOrder(
+		base.EXPECT().Run(any, "id", "-u").Return("1000\n", nil),
+		base.EXPECT().Run(any, "mkdir", "-m", "700", "/tmp/wd/encrypted").Return("", nil),
+		base.EXPECT().Run(any, "mkdir", "-m", "700", "/tmp/wd/encrypted-key").Return("", nil),
+		base.EXPECT().Run(any, "sudo", "-n", "mount", "-t", "tmpfs", "-o", "size=1m,mode=0700",
+			"tmpfs", "/tmp/wd/encrypted-key").Return("", nil),
+		base.EXPECT().Run(any, "sudo", "-n", "dd", "if=/dev/urandom", "of=/tmp/wd/encrypted-key/key",
+			"bs=64", "count=1", "status=none").Return("", nil),
+		base.EXPECT().Run(any, "truncate", "-s", "64M", "/tmp/wd/encrypted.img").Return("", nil),
+		base.EXPECT().Run(any, "sudo", "-n", "cryptsetup", "luksFormat", "--batch-mode", "--key-file",
+			"/tmp/wd/encrypted-key/key", "/tmp/wd/encrypted.img").Return("", errors.New("no cryptsetup")),
+		// the key is destroyed, the volume wasn't opened and the base executor isn't cleaned
+		base.EXPECT().Run(any, "sudo", "-n", "umount", "/tmp/wd/encrypted-key").Return("", nil),
+	)
+
+	_, err := NewEncryptedWorkDir(ctx, base, WorkDirLUKS, 64)
+	assert.Error(t, err)
+}
+
+func TestParseWorkDirEncryption(t *testing.T) {
+	m, err := ParseWorkDirEncryption("luks")
+	assert.NoError(t, err)
+	assert.Equal(t, WorkDirLUKS, m)
+
+	_, err = ParseWorkDirEncryption("ecryptfs")
+	assert.Error(t, err)
+}
+
+func TestEncryptedWorkDirInContainer(t *testing.T) {
+	_, err := NewEncryptedWorkDir(context.Background(), &Container{wd: "/goapp"}, WorkDirTmpfs, 64)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "CAP_SYS_ADMIN")
+}
+
+func TestEncryptedWorkDirWithoutPrivileges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	any := gomock.Any()
+	base := NewMockExecutor(ctrl)
+	base.EXPECT().WorkDir().Return("/tmp/wd")
+
+	gomock.InOrder(
+		base.EXPECT().Run(any, "id", "-u").Return("1000\n", nil),
+		base.EXPECT().Run(any, "mkdir", "-m", "700", "/tmp/wd/encrypted").Return("", nil),
+		base.EXPECT().Run(any, "sudo", "-n", "mount", "-t", "tmpfs", "-o", "size=64m,mode=0700,uid=1000",
+			"tmpfs", "/tmp/wd/encrypted").Return("mount: only root can use \"--options\" option (effective UID is 1000)\n"+
+			"mount: must be superuser to use mount", errors.New("exit status 1")),
+	)
+
+	_, err := NewEncryptedWorkDir(ctx, base, WorkDirTmpfs, 64)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no privileges for mount")
+}
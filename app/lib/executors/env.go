@@ -3,15 +3,66 @@ package executors
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
+// allowedEnvVars are passed from the worker environment to spawned commands. Other vars
+// (queue, API and analytics credentials) must not be readable by build scripts of repos.
+var allowedEnvVars = []string{
+	"PATH",
+	"HOME",
+	"USER",
+	"LANG",
+	"LC_ALL",
+	"TMPDIR",
+	"GOPATH",
+	"GOROOT",
+	"GOFLAGS",
+	"GOCACHE",
+	"GOPROXY",
+	"GO111MODULE",
+	"CGO_ENABLED",
+
+	// proxies are needed for cloning and fetching deps in enterprise deployments
+	"HTTP_PROXY",
+	"HTTPS_PROXY",
+	"NO_PROXY",
+	"http_proxy",
+	"https_proxy",
+	"no_proxy",
+}
+
+// allowedEnviron returns the allowed subset of the worker environment,
+// extra var names can be allowed by comma-separated EXECUTOR_ENV_ALLOWLIST
+func allowedEnviron() []string {
+	allowed := map[string]bool{}
+	for _, k := range allowedEnvVars {
+		allowed[k] = true
+	}
+	for _, k := range strings.Split(os.Getenv("EXECUTOR_ENV_ALLOWLIST"), ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			allowed[k] = true
+		}
+	}
+
+	var ret []string
+	for _, kv := range os.Environ() {
+		parts := strings.SplitN(kv, "=", 2)
+		if allowed[parts[0]] {
+			ret = append(ret, kv)
+		}
+	}
+
+	return ret
+}
+
 type envStore struct {
 	env []string
 }
 
 func newEnvStore() *envStore {
 	return &envStore{
-		env: os.Environ(),
+		env: allowedEnviron(),
 	}
 }
 
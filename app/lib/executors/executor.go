@@ -1,6 +1,9 @@
 package executors
 
-import "context"
+import (
+	"context"
+	"errors"
+)
 
 //go:generate mockgen -package executors -source executor.go -destination executor_mock.go
 
@@ -13,7 +16,50 @@ type Executor interface {
 	WorkDir() string
 	WithWorkDir(wd string) Executor
 
-	CopyFile(ctx context.Context, dst, src string) error
+	// WriteFile writes content readable only by the owner to dst relative to the work dir:
+	// it's never staged on the worker, so files stay in the encrypted work dir if any
+	WriteFile(ctx context.Context, dst string, content []byte) error
 
 	Clean()
 }
+
+// Wrapper is an executor adding credentials to commands of the wrapped executor, e.g. the deploy key agent
+type Wrapper interface {
+	Unwrap() Executor
+}
+
+// WithoutNetwork returns e running commands without network access, it's false if e can't do it:
+// only containers are isolated enough to run code of repos. Wrappers are stripped: credentials are useless without network.
+func WithoutNetwork(e Executor) (Executor, bool) {
+	switch ee := e.(type) {
+	case Wrapper:
+		return WithoutNetwork(ee.Unwrap())
+	case *Container:
+		return ee.WithoutNetwork(), true
+	case Restricted:
+		inner, ok := WithoutNetwork(ee.Executor)
+		if !ok {
+			return nil, false
+		}
+		return Restricted{Executor: inner, allowed: ee.allowed}, true
+	case *Restricted:
+		return WithoutNetwork(*ee)
+	}
+
+	return nil, false
+}
+
+// CheckNetworkIsolation checks that e returned by WithoutNetwork is able to run commands:
+// containers can be forbidden to create network namespaces, e.g. by the seccomp profile of Docker
+func CheckNetworkIsolation(ctx context.Context, e Executor) error {
+	switch ee := e.(type) {
+	case *Container:
+		return ee.checkNetworkIsolation(ctx)
+	case Restricted:
+		return CheckNetworkIsolation(ctx, ee.Executor) // the probe isn't a command of analyses
+	case *Restricted:
+		return CheckNetworkIsolation(ctx, ee.Executor)
+	}
+
+	return errors.New("the executor can't run commands without network")
+}
@@ -96,16 +96,16 @@ func (_mr *MockExecutorMockRecorder) WithWorkDir(arg0 interface{}) *gomock.Call
 	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "WithWorkDir", reflect.TypeOf((*MockExecutor)(nil).WithWorkDir), arg0)
 }
 
-// CopyFile mocks base method
-func (_m *MockExecutor) CopyFile(ctx context.Context, dst string, src string) error {
-	ret := _m.ctrl.Call(_m, "CopyFile", ctx, dst, src)
+// WriteFile mocks base method
+func (_m *MockExecutor) WriteFile(ctx context.Context, dst string, content []byte) error {
+	ret := _m.ctrl.Call(_m, "WriteFile", ctx, dst, content)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// CopyFile indicates an expected call of CopyFile
-func (_mr *MockExecutorMockRecorder) CopyFile(arg0, arg1, arg2 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "CopyFile", reflect.TypeOf((*MockExecutor)(nil).CopyFile), arg0, arg1, arg2)
+// WriteFile indicates an expected call of WriteFile
+func (_mr *MockExecutorMockRecorder) WriteFile(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "WriteFile", reflect.TypeOf((*MockExecutor)(nil).WriteFile), arg0, arg1, arg2)
 }
 
 // Clean mocks base method
@@ -0,0 +1,60 @@
+package executors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/lib/redact"
+)
+
+// Git helpers run git in the work dir of the executor: it must be a checkout of a repo
+
+func runGit(ctx context.Context, exec Executor, args ...string) (string, error) {
+	out, err := exec.Run(ctx, "git", args...)
+	if err != nil {
+		return "", fmt.Errorf("can't run git %s: %s, %s", redact.URLCredentials(fmt.Sprint(args)), err, out)
+	}
+
+	return out, nil
+}
+
+// GitFetch fetches the refspec from the remote (a name or an url), depth 0 means full history
+func GitFetch(ctx context.Context, exec Executor, remote, refspec string, depth int) error {
+	args := []string{"fetch", "-q"}
+	if depth > 0 {
+		args = append(args, fmt.Sprintf("--depth=%d", depth))
+	}
+	_, err := runGit(ctx, exec, append(args, remote, refspec)...)
+	return err
+}
+
+// GitDeepen fetches more commits of shallow history of the refspec
+func GitDeepen(ctx context.Context, exec Executor, remote, refspec string, commits int) error {
+	args := []string{"fetch", "-q", fmt.Sprintf("--deepen=%d", commits), remote}
+	if refspec != "" {
+		args = append(args, refspec)
+	}
+	_, err := runGit(ctx, exec, args...)
+	return err
+}
+
+// GitMergeBase returns the best common ancestor of the revisions
+func GitMergeBase(ctx context.Context, exec Executor, a, b string) (string, error) {
+	out, err := runGit(ctx, exec, "merge-base", a, b)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+// GitDiff returns the unified diff between the revisions
+func GitDiff(ctx context.Context, exec Executor, from, to string) (string, error) {
+	out, err := runGit(ctx, exec, "diff", "--no-color", "--no-ext-diff", from, to)
+	if err != nil || out == "" {
+		return out, err
+	}
+
+	return out + "\n", nil // executors strip the trailing newline
+}
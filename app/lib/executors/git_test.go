@@ -0,0 +1,60 @@
+package executors
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func commitFile(t *testing.T, ctx context.Context, exec Executor, name, content string) {
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(exec.WorkDir(), name), []byte(content), 0644))
+	_, err := runGit(ctx, exec, "add", name)
+	assert.NoError(t, err)
+	_, err = runGit(ctx, exec, "-c", "user.name=test", "-c", "user.email=test@test", "commit", "-q", "-m", name)
+	assert.NoError(t, err)
+}
+
+func TestGitMergeBase(t *testing.T) {
+	ctx := context.Background()
+	upstream, err := NewTempDirShell(t.Name())
+	assert.NoError(t, err)
+	defer upstream.Clean()
+
+	_, err = runGit(ctx, upstream, "init", "-q")
+	assert.NoError(t, err)
+	commitFile(t, ctx, upstream, "a.go", "package p\n")
+	_, err = runGit(ctx, upstream, "branch", "-M", "master")
+	assert.NoError(t, err)
+	_, err = runGit(ctx, upstream, "checkout", "-q", "-b", "feature")
+	assert.NoError(t, err)
+	commitFile(t, ctx, upstream, "b.go", "package p\n")
+	_, err = runGit(ctx, upstream, "checkout", "-q", "master")
+	assert.NoError(t, err)
+	commitFile(t, ctx, upstream, "c.go", "package p\n") // the target branch moved after the branching
+
+	clone, err := NewTempDirShell(t.Name())
+	assert.NoError(t, err)
+	defer clone.Clean()
+
+	_, err = runGit(ctx, clone, "init", "-q")
+	assert.NoError(t, err)
+	assert.NoError(t, GitFetch(ctx, clone, upstream.WorkDir(), "+refs/heads/feature:refs/heads/feature", 0))
+	_, err = runGit(ctx, clone, "checkout", "-q", "feature")
+	assert.NoError(t, err)
+	assert.NoError(t, GitFetch(ctx, clone, upstream.WorkDir(), "+refs/heads/master:refs/golangci/base", 1))
+	assert.NoError(t, GitDeepen(ctx, clone, upstream.WorkDir(), "+refs/heads/master:refs/golangci/base", 1))
+
+	mergeBase, err := GitMergeBase(ctx, clone, "HEAD", "refs/golangci/base")
+	assert.NoError(t, err)
+	expMergeBase, err := runGit(ctx, upstream, "rev-parse", "master~1")
+	assert.NoError(t, err)
+	assert.Equal(t, expMergeBase, mergeBase)
+
+	diff, err := GitDiff(ctx, clone, mergeBase, "HEAD")
+	assert.NoError(t, err)
+	assert.Contains(t, diff, "+++ b/b.go")
+	assert.NotContains(t, diff, "c.go") // changes of the target branch aren't in the diff
+}
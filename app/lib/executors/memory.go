@@ -0,0 +1,39 @@
+package executors
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// unlimitedMemory is the min value of memory.limit_in_bytes meaning no limit in cgroup v1: it's the max int64
+// rounded down to the page size
+const unlimitedMemory = int64(1) << 62
+
+// MemoryLimit returns bytes of memory available to commands of the executor: EXECUTOR_MEMORY_LIMIT_MB
+// if it's set, otherwise the cgroup v2 or v1 memory limit. It's zero if memory isn't limited or the limit is unknown.
+func MemoryLimit(ctx context.Context, exec Executor) int64 {
+	if n, err := strconv.ParseInt(os.Getenv("EXECUTOR_MEMORY_LIMIT_MB"), 10, 64); err == nil && n > 0 {
+		return n * 1024 * 1024
+	}
+
+	out, err := Unrestricted(exec).Run(ctx, "cat", "/sys/fs/cgroup/memory.max")
+	if err != nil {
+		if out, err = Unrestricted(exec).Run(ctx, "cat", "/sys/fs/cgroup/memory/memory.limit_in_bytes"); err != nil {
+			return 0
+		}
+	}
+
+	return parseCgroupMemory(out)
+}
+
+// parseCgroupMemory parses memory.max ("max" if there is no limit) or memory.limit_in_bytes
+func parseCgroupMemory(s string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n <= 0 || n >= unlimitedMemory {
+		return 0
+	}
+
+	return n
+}
@@ -0,0 +1,207 @@
+package executors
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+)
+
+const defaultMaxOutputSizeMB = 16
+
+// maxOutputSize is a limit of captured output of a command, it's set by EXECUTOR_MAX_OUTPUT_SIZE_MB
+func maxOutputSize() int {
+	if n, err := strconv.Atoi(os.Getenv("EXECUTOR_MAX_OUTPUT_SIZE_MB")); err == nil && n > 0 {
+		return n * 1024 * 1024
+	}
+
+	return defaultMaxOutputSizeMB * 1024 * 1024
+}
+
+// limitedOutput keeps the head and the tail of the output exceeding the limit:
+// the tail usually has errors and the head has the context of them
+type limitedOutput struct {
+	limit      int
+	head, tail []byte
+	total      int64
+}
+
+func newLimitedOutput(limit int) *limitedOutput {
+	return &limitedOutput{limit: limit}
+}
+
+func (o *limitedOutput) Write(p []byte) (int, error) {
+	n := len(p)
+	o.total += int64(n)
+
+	headLimit := o.limit / 2
+	if free := headLimit - len(o.head); free > 0 {
+		if free > len(p) {
+			free = len(p)
+		}
+		o.head = append(o.head, p[:free]...)
+		p = p[free:]
+	}
+
+	tailLimit := o.limit - headLimit
+	o.tail = append(o.tail, p...)
+	if len(o.tail) > tailLimit {
+		o.tail = o.tail[len(o.tail)-tailLimit:]
+	}
+
+	return n, nil
+}
+
+func (o *limitedOutput) truncatedBytes() int64 {
+	return o.total - int64(len(o.head)+len(o.tail))
+}
+
+func (o *limitedOutput) String() string {
+	if n := o.truncatedBytes(); n != 0 {
+		return fmt.Sprintf("%s\n... %d bytes of output truncated ...\n%s", o.head, n, o.tail)
+	}
+
+	return string(o.head) + string(o.tail)
+}
+
+// limitOutputString applies the limit of output to already captured output, e.g. of remote commands
+func limitOutputString(ctx context.Context, name, out string, runErr error) (string, error) {
+	o := newLimitedOutput(maxOutputSize())
+	_, _ = o.Write([]byte(out))
+	o.record(ctx, name)
+	return o.String(), o.err(name, runErr)
+}
+
+// OutputTruncatedError is returned by executors if the command succeeded but its output exceeded the limit:
+// the output has only its head and tail, so it can't be parsed (e.g. json). Callers using the output
+// only for logs can ignore it by IsOutputTruncated.
+type OutputTruncatedError struct {
+	Command        string
+	TruncatedBytes int64
+}
+
+func (e *OutputTruncatedError) Error() string {
+	return fmt.Sprintf("output of %s was truncated by %d bytes", e.Command, e.TruncatedBytes)
+}
+
+func IsOutputTruncated(err error) bool {
+	_, ok := err.(*OutputTruncatedError)
+	return ok
+}
+
+// err returns the error of the command: errors of failed commands are kept as is, they hold exit codes
+func (o *limitedOutput) err(name string, runErr error) error {
+	if runErr != nil {
+		return runErr
+	}
+
+	if n := o.truncatedBytes(); n != 0 {
+		return &OutputTruncatedError{Command: name, TruncatedBytes: n}
+	}
+
+	return nil
+}
+
+// record records the truncation of the command output if it was truncated
+func (o *limitedOutput) record(ctx context.Context, name string) {
+	n := o.truncatedBytes()
+	if n == 0 {
+		return
+	}
+
+	analytics.Log(ctx).Warnf("Output of %s was truncated by %d bytes", name, n)
+	if r := TruncationRecorderFromContext(ctx); r != nil {
+		r.add(OutputTruncation{Command: name, TruncatedBytes: n})
+	}
+}
+
+// OutputTruncation is a truncated output of a command
+type OutputTruncation struct {
+	Command        string
+	TruncatedBytes int64
+}
+
+// TruncationRecorder collects truncations of outputs of commands of an analysis: they are shown in the result
+type TruncationRecorder struct {
+	mu          sync.Mutex
+	truncations []OutputTruncation
+}
+
+func NewTruncationRecorder() *TruncationRecorder {
+	return &TruncationRecorder{}
+}
+
+func (r *TruncationRecorder) add(t OutputTruncation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.truncations = append(r.truncations, t)
+}
+
+func (r *TruncationRecorder) Truncations() []OutputTruncation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]OutputTruncation{}, r.truncations...)
+}
+
+type truncationRecorderKeyType string
+
+const truncationRecorderKey truncationRecorderKeyType = "truncation recorder"
+
+func ContextWithTruncationRecorder(ctx context.Context, r *TruncationRecorder) context.Context {
+	return context.WithValue(ctx, truncationRecorderKey, r)
+}
+
+// TruncationRecorderFromContext returns nil if truncations aren't recorded
+func TruncationRecorderFromContext(ctx context.Context) *TruncationRecorder {
+	r, _ := ctx.Value(truncationRecorderKey).(*TruncationRecorder)
+	return r
+}
+
+// OutputLineFunc receives lines of the output of commands while they are running, the line is reused after the call
+type OutputLineFunc func(line []byte)
+
+type outputLinesKeyType string
+
+const outputLinesKey outputLinesKeyType = "output lines"
+
+// ContextWithOutputLines streams output lines of commands run by the context to the func: it's called by
+// executors running local processes and by RemoteShell, Container returns the output only after the command finished
+func ContextWithOutputLines(ctx context.Context, f OutputLineFunc) context.Context {
+	return context.WithValue(ctx, outputLinesKey, f)
+}
+
+func outputLinesFromContext(ctx context.Context) OutputLineFunc {
+	f, _ := ctx.Value(outputLinesKey).(OutputLineFunc)
+	return f
+}
+
+// lineWriter passes complete lines written to it to the func, the rest of the output is passed by flush
+type lineWriter struct {
+	onLine OutputLineFunc
+	buf    []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.onLine(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+
+	return len(p), nil
+}
+
+func (w *lineWriter) flush() {
+	if len(w.buf) != 0 {
+		w.onLine(w.buf)
+		w.buf = nil
+	}
+}
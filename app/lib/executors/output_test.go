@@ -0,0 +1,91 @@
+package executors
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitedOutput(t *testing.T) {
+	o := newLimitedOutput(10)
+	_, _ = o.Write([]byte("abc"))
+	assert.Equal(t, "abc", o.String())
+	assert.Zero(t, o.truncatedBytes())
+
+	for _, s := range []string{"defgh", "ijklmnop", "qrstuvwxyz"} {
+		_, _ = o.Write([]byte(s))
+	}
+	assert.Equal(t, int64(16), o.truncatedBytes())
+	assert.Equal(t, "abcde\n... 16 bytes of output truncated ...\nvwxyz", o.String())
+	assert.Equal(t, &OutputTruncatedError{Command: "cmd", TruncatedBytes: 16}, o.err("cmd", nil))
+
+	// errors of failed commands are kept: they hold exit codes
+	runErr := errors.New("exit status 1")
+	assert.Equal(t, runErr, o.err("cmd", runErr))
+}
+
+func TestShellOutputTruncation(t *testing.T) {
+	os.Setenv("EXECUTOR_MAX_OUTPUT_SIZE_MB", "1")
+	defer os.Unsetenv("EXECUTOR_MAX_OUTPUT_SIZE_MB")
+
+	exec, err := NewTempDirShell("test.output")
+	assert.NoError(t, err)
+	defer exec.Clean()
+
+	r := NewTruncationRecorder()
+	ctx := ContextWithTruncationRecorder(context.Background(), r)
+	ctx = analytics.ContextWithEventPropsCollector(ctx, analytics.EventPRChecked)
+
+	out, err := exec.Run(ctx, "seq", "1000000") // 6.9MB
+	assert.True(t, IsOutputTruncated(err))
+	assert.True(t, strings.HasPrefix(out, "1\n2\n3\n"))
+	assert.True(t, strings.HasSuffix(out, "999999\n1000000"))
+	assert.Contains(t, out, "bytes of output truncated")
+	assert.True(t, len(out) < 1024*1024+100)
+
+	truncations := r.Truncations()
+	if assert.Len(t, truncations, 1) {
+		assert.Equal(t, "seq", truncations[0].Command)
+	}
+
+	out, err = exec.Run(ctx, "echo", "short")
+	assert.NoError(t, err)
+	assert.Equal(t, "short", out)
+	assert.Len(t, r.Truncations(), 1)
+}
+
+func TestShellOutputLines(t *testing.T) {
+	exec, err := NewTempDirShell("test.output")
+	assert.NoError(t, err)
+	defer exec.Clean()
+
+	var lines []string
+	ctx := ContextWithOutputLines(context.Background(), func(line []byte) {
+		lines = append(lines, string(line))
+	})
+	ctx = analytics.ContextWithEventPropsCollector(ctx, analytics.EventPRChecked)
+
+	out, err := exec.Run(ctx, "seq", "3")
+	assert.NoError(t, err)
+	assert.Equal(t, "1\n2\n3", out)
+	assert.Equal(t, []string{"1", "2", "3"}, lines)
+}
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+	w := &lineWriter{onLine: func(line []byte) {
+		lines = append(lines, string(line))
+	}}
+
+	_, _ = w.Write([]byte("a.go:1: first"))
+	_, _ = w.Write([]byte(" issue\nb.go:2: second\nrest"))
+	assert.Equal(t, []string{"a.go:1: first issue", "b.go:2: second"}, lines)
+
+	w.flush()
+	assert.Equal(t, []string{"a.go:1: first issue", "b.go:2: second", "rest"}, lines)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
 	"path/filepath"
 	"strconv"
@@ -58,42 +59,84 @@ func sprintArgs(args []string) string {
 	return strings.Join(quoteArgs(args), " ")
 }
 
+// shellQuote quotes the arg for the remote shell: ssh joins the command into a line interpreted by the shell,
+// but args must be passed as is, e.g. regexes of grep
+func shellQuote(arg string) string {
+	if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./=:,@%+") == "" {
+		return arg
+	}
+
+	return "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+}
+
+// shellLine makes the command line run by the remote shell: args are unquoted like by the local shell
+// and then quoted for the remote shell, so the same args give the same command in both executors
+func (s RemoteShell) shellLine(name string, srcArgs []string) string {
+	var env []string
+	for _, kv := range s.env {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 { // quoting of the name would make it a command
+			kv = parts[0] + "=" + shellQuote(parts[1])
+		}
+		env = append(env, kv)
+	}
+
+	args := []string{shellQuote(name)}
+	for _, arg := range unquoteArgs(srcArgs) {
+		args = append(args, shellQuote(arg))
+	}
+
+	return fmt.Sprintf("cd %s; %s %s",
+		shellQuote(s.wd),
+		strings.Join(env, " "),
+		strings.Join(args, " "))
+}
+
 func (s RemoteShell) Run(ctx context.Context, name string, srcArgs ...string) (string, error) {
-	shellArg := fmt.Sprintf("cd %s; %s %s %s",
-		s.wd,
-		strings.Join(s.env, " "),
-		name, strings.Join(srcArgs, " "))
-	args := []string{
+	sshArgs := []string{
 		"-i",
 		s.keyFilePath,
 		fmt.Sprintf("%s@%s", s.user, s.host),
-		shellArg,
+		s.shellLine(name, srcArgs),
 	}
 
-	cmd := exec.CommandContext(ctx, "ssh", args...)
-	var stderrBuf bytes.Buffer
-	cmd.Stderr = &stderrBuf
+	cmd := exec.CommandContext(ctx, "ssh", sshArgs...)
+	stdout, stderr := newLimitedOutput(maxOutputSize()), newLimitedOutput(maxOutputSize())
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	var lines *lineWriter
+	if onLine := outputLinesFromContext(ctx); onLine != nil {
+		lines = &lineWriter{onLine: onLine}
+		cmd.Stdout = io.MultiWriter(stdout, lines)
+	}
 
-	out, err := cmd.Output()
+	err := cmd.Run()
+	if lines != nil {
+		lines.flush()
+	}
+	stdout.record(ctx, name)
 	if err != nil {
 		return "", fmt.Errorf("can't execute command ssh %s: %s, %s, %s",
-			sprintArgs(args), err, string(out), stderrBuf.String())
+			sprintArgs(sshArgs), err, stdout, stderr)
 	}
 
-	return string(out), nil
+	return stdout.String(), stdout.err(name, nil)
 }
 
-func (s RemoteShell) CopyFile(ctx context.Context, dst, src string) error {
+// WriteFile streams content to cat on the remote host: no local copy of the file is made
+func (s RemoteShell) WriteFile(ctx context.Context, dst string, content []byte) error {
 	if !filepath.IsAbs(dst) {
 		dst = filepath.Join(s.WorkDir(), dst)
 	}
-	out, err := exec.CommandContext(ctx, "scp",
+	cmd := exec.CommandContext(ctx, "ssh",
 		"-i", s.keyFilePath,
-		src,
-		fmt.Sprintf("%s@%s:%s", s.user, s.host, dst),
-	).CombinedOutput()
+		fmt.Sprintf("%s@%s", s.user, s.host),
+		"umask 077 && cat > "+shellQuote(dst),
+	)
+	cmd.Stdin = bytes.NewReader(content)
+	out, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("can't copy file %s to %s: %s, %s", src, dst, err, out)
+		return fmt.Errorf("can't write file %s: %s, %s", dst, err, out)
 	}
 
 	return nil
@@ -2,7 +2,9 @@ package executors
 
 import (
 	"context"
+	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -60,3 +62,30 @@ func TestRemoteShellInWorkDir(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, wd, strings.TrimSpace(out))
 }
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, "./pkg/client", shellQuote("./pkg/client"))
+	assert.Equal(t, "''", shellQuote(""))
+	assert.Equal(t, `'^func \([^)]*\bT\) F\('`, shellQuote(`^func \([^)]*\bT\) F\(`))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+	assert.Equal(t, "'*.go'", shellQuote("*.go"))
+}
+
+func TestRemoteShellRunsCommandOfShell(t *testing.T) {
+	ts, err := NewTempDirShell(t.Name())
+	assert.NoError(t, err)
+	defer ts.Clean()
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(ts.WorkDir(), "a.go"), []byte("package a\n\nimport \"C\"\n"), 0644))
+	args := []string{"-r", "-l", `"--include=*.go"`, "--exclude-dir=vendor", `"^import \"C\""`, "."}
+
+	out, err := ts.Run(context.Background(), "grep", args...)
+	assert.NoError(t, err)
+	assert.Equal(t, "./a.go", strings.TrimSpace(out))
+
+	// the remote shell runs its command line by sh of the host
+	rs := NewRemoteShell("", "", "").WithWorkDir(ts.WorkDir()).(*RemoteShell)
+	remoteOut, err := exec.Command("sh", "-c", rs.shellLine("grep", args)).CombinedOutput()
+	assert.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(out), strings.TrimSpace(string(remoteOut)))
+}
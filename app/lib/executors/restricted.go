@@ -0,0 +1,97 @@
+package executors
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// AllowedCommands are run in the workspace by analyses
+var AllowedCommands = []string{"git", "go", "golangci-lint", "goenvbuild"}
+
+// CommandNotAllowedError is returned by Restricted for commands not in the allowlist
+type CommandNotAllowedError struct {
+	Name string
+}
+
+func (e CommandNotAllowedError) Error() string {
+	return fmt.Sprintf("command %q isn't allowed by the executor allowlist", e.Name)
+}
+
+// Restricted runs only allowlisted commands: it's a defense-in-depth against commands
+// injected into analyses, e.g. by config-driven features
+type Restricted struct {
+	Executor
+	allowed map[string]bool
+}
+
+var _ Executor = Restricted{}
+
+func NewRestricted(e Executor, allowed []string) *Restricted {
+	r := &Restricted{
+		Executor: e,
+		allowed:  map[string]bool{},
+	}
+	for _, name := range allowed {
+		r.allowed[name] = true
+	}
+
+	return r
+}
+
+// RestrictedFromEnv wraps e if EXECUTOR_COMMANDS_MODE is "allowlist": AllowedCommands and
+// commands from comma-separated EXECUTOR_COMMANDS_ALLOWLIST are allowed
+func RestrictedFromEnv(e Executor) Executor {
+	if os.Getenv("EXECUTOR_COMMANDS_MODE") != "allowlist" {
+		return e
+	}
+
+	allowed := append([]string{}, AllowedCommands...)
+	for _, name := range strings.Split(os.Getenv("EXECUTOR_COMMANDS_ALLOWLIST"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			allowed = append(allowed, name)
+		}
+	}
+
+	return NewRestricted(e, allowed)
+}
+
+// Unrestricted returns e without the allowlist: only utilities the worker runs itself with fixed args
+// (cat, find, mkdir, etc.) are run by it, never commands of analyses or configs. Wrappers are stripped:
+// utilities don't need their credentials.
+func Unrestricted(e Executor) Executor {
+	switch ee := e.(type) {
+	case Wrapper:
+		return Unrestricted(ee.Unwrap())
+	case Restricted:
+		return ee.Executor
+	case *Restricted:
+		return ee.Executor
+	}
+
+	return e
+}
+
+func (r Restricted) Run(ctx context.Context, name string, args ...string) (string, error) {
+	// paths aren't allowed: ./git can be a binary from the repo
+	if strings.ContainsRune(name, '/') || !r.allowed[name] {
+		return "", &CommandNotAllowedError{Name: name}
+	}
+
+	return r.Executor.Run(ctx, name, args...)
+}
+
+func (r Restricted) WithEnv(k, v string) Executor {
+	return Restricted{
+		Executor: r.Executor.WithEnv(k, v),
+		allowed:  r.allowed,
+	}
+}
+
+func (r Restricted) WithWorkDir(wd string) Executor {
+	return Restricted{
+		Executor: r.Executor.WithWorkDir(wd),
+		allowed:  r.allowed,
+	}
+}
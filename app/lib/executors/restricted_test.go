@@ -0,0 +1,76 @@
+package executors
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestricted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	e := NewMockExecutor(ctrl)
+	e.EXPECT().WithWorkDir("sub").Return(e)
+	e.EXPECT().Run(ctx, "git", "status").Return("clean", nil)
+
+	r := NewRestricted(e, AllowedCommands).WithWorkDir("sub")
+	out, err := r.Run(ctx, "git", "status")
+	assert.NoError(t, err)
+	assert.Equal(t, "clean", out)
+
+	for _, name := range []string{"bash", "./git", "/usr/bin/git"} {
+		_, err = r.Run(ctx, name, "-c", "id")
+		assert.Equal(t, &CommandNotAllowedError{Name: name}, err)
+	}
+}
+
+func TestRestrictedFromEnv(t *testing.T) {
+	e := NewMockExecutor(gomock.NewController(t))
+	assert.Equal(t, e, RestrictedFromEnv(e))
+
+	os.Setenv("EXECUTOR_COMMANDS_MODE", "allowlist")
+	defer os.Unsetenv("EXECUTOR_COMMANDS_MODE")
+	os.Setenv("EXECUTOR_COMMANDS_ALLOWLIST", "bash, make")
+	defer os.Unsetenv("EXECUTOR_COMMANDS_ALLOWLIST")
+
+	r := RestrictedFromEnv(e).(*Restricted)
+	assert.True(t, r.allowed["golangci-lint"])
+	assert.False(t, r.allowed["cat"], "utilities are run by the unrestricted executor")
+	assert.True(t, r.allowed["bash"])
+	assert.True(t, r.allowed["make"])
+	assert.False(t, r.allowed["wget"])
+}
+
+func TestUnrestricted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	e := NewMockExecutor(ctrl)
+	e.EXPECT().Run(ctx, "mkdir", "-p", "dir").Return("", nil)
+
+	r := NewRestricted(e, AllowedCommands)
+	_, err := r.Run(ctx, "mkdir", "-p", "dir")
+	assert.Equal(t, &CommandNotAllowedError{Name: "mkdir"}, err)
+
+	_, err = Unrestricted(r).Run(ctx, "mkdir", "-p", "dir")
+	assert.NoError(t, err)
+	assert.Equal(t, e, Unrestricted(e))
+}
+
+func TestWithoutNetwork(t *testing.T) {
+	_, ok := WithoutNetwork(NewRemoteShell("user", "host", "key"))
+	assert.False(t, ok)
+
+	e, ok := WithoutNetwork(NewRestricted(&Container{}, AllowedCommands))
+	assert.True(t, ok)
+	r, isRestricted := e.(Restricted)
+	if assert.True(t, isRestricted) {
+		assert.True(t, r.Executor.(*Container).noNetwork)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/redact"
 	"github.com/shirou/gopsutil/process"
 )
 
@@ -68,32 +69,46 @@ func trackMemoryEveryNSeconds(ctx context.Context, name string, pid int) {
 	}
 }
 
-func (s shell) wait(ctx context.Context, name string, childPid int, outReader io.Reader) []string {
+func (s shell) wait(ctx context.Context, name string, childPid int, outReader io.Reader) *limitedOutput {
 	trackCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	go trackMemoryEveryNSeconds(trackCtx, name, childPid)
 
+	onLine := outputLinesFromContext(ctx)
+	out := newLimitedOutput(maxOutputSize())
 	scanner := bufio.NewScanner(outReader)
-	lines := []string{}
 	for scanner.Scan() {
-		line := scanner.Text()
+		line := scanner.Bytes()
 		analytics.Log(ctx).Debugf("%s", line)
-		lines = append(lines, line)
+		if onLine != nil {
+			onLine(line)
+		}
+		_, _ = out.Write(line)
+		_, _ = out.Write([]byte{'\n'})
 	}
 	if err := scanner.Err(); err != nil {
 		analytics.Log(ctx).Warnf("Out lines scanning error: %s", err)
 	}
 
-	return lines
+	out.record(ctx, name)
+	return out
 }
 
-func (s shell) Run(ctx context.Context, name string, args ...string) (string, error) {
-	for i := range args {
-		unquotedArg, err := strconv.Unquote(args[i])
-		if err == nil {
-			args[i] = unquotedArg
+// unquoteArgs unquotes Go-quoted args, e.g. `"--include=*.go"`: callers quote args with special chars,
+// other args are kept as is
+func unquoteArgs(args []string) []string {
+	ret := make([]string, 0, len(args))
+	for _, arg := range args {
+		if unquotedArg, err := strconv.Unquote(arg); err == nil {
+			arg = unquotedArg
 		}
+		ret = append(ret, arg)
 	}
+	return ret
+}
+
+func (s shell) Run(ctx context.Context, name string, args ...string) (string, error) {
+	args = unquoteArgs(args)
 	startedAt := time.Now()
 	pid, outReader, finish, err := s.runAsync(ctx, name, args...)
 	if err != nil {
@@ -114,7 +129,7 @@ func (s shell) Run(ctx context.Context, name string, args ...string) (string, er
 		}
 	}()
 
-	lines := s.wait(ctx, name, pid, outReader)
+	out := s.wait(ctx, name, pid, outReader)
 
 	err = finish()
 
@@ -122,10 +137,12 @@ func (s shell) Run(ctx context.Context, name string, args ...string) (string, er
 	if err != nil {
 		logger = analytics.Log(ctx).Infof
 	}
-	logger("shell[%s]: %s %v executed for %s: %v", s.wd, name, args, time.Since(startedAt), err)
+	// args can contain clone URLs with access tokens
+	logger("shell[%s]: %s %s executed for %s: %v", s.wd, name,
+		redact.URLCredentials(fmt.Sprint(args)), time.Since(startedAt), err)
 
 	// XXX: it's important to not change error here, because it holds exit code
-	return strings.Join(lines, "\n"), err
+	return strings.TrimSuffix(out.String(), "\n"), out.err(name, err)
 }
 
 type finishFunc func() error
@@ -3,7 +3,6 @@ package executors
 import (
 	"context"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"log"
 	"os"
@@ -28,6 +27,11 @@ func init() {
 	}
 }
 
+// TmpRoot is the resolved dir of local temp workspaces, their names start with "golangci."
+func TmpRoot() string {
+	return tmpRoot
+}
+
 func NewTempDirShell(tag string) (*TempDirShell, error) {
 	wd, err := ioutil.TempDir(tmpRoot, fmt.Sprintf("golangci.%s", tag))
 	if err != nil {
@@ -65,24 +69,10 @@ func (s TempDirShell) WithWorkDir(wd string) Executor {
 	return &eCopy
 }
 
-func (s TempDirShell) CopyFile(ctx context.Context, dst, src string) error {
+func (s TempDirShell) WriteFile(ctx context.Context, dst string, content []byte) error {
 	dst = filepath.Join(s.WorkDir(), dst)
-
-	from, err := os.Open(src)
-	if err != nil {
-		return fmt.Errorf("can't open %s: %s", src, err)
-	}
-	defer from.Close()
-
-	to, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE, 0666)
-	if err != nil {
-		return fmt.Errorf("can't open %s: %s", dst, err)
-	}
-	defer to.Close()
-
-	_, err = io.Copy(to, from)
-	if err != nil {
-		return fmt.Errorf("can't copy from %s to %s: %s", src, dst, err)
+	if err := ioutil.WriteFile(dst, content, 0600); err != nil {
+		return fmt.Errorf("can't write %s: %s", dst, err)
 	}
 
 	return nil
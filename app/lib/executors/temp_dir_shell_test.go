@@ -1,6 +1,7 @@
 package executors
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -11,7 +12,7 @@ func TestTempDirShellWithEnv(t *testing.T) {
 	ts, err := NewTempDirShell(t.Name())
 	assert.NoError(t, err)
 	assert.NotEmpty(t, ts.wd)
-	assert.Equal(t, os.Environ(), ts.env)
+	assert.Equal(t, allowedEnviron(), ts.env)
 
 	defer ts.Clean()
 
@@ -19,8 +20,26 @@ func TestTempDirShellWithEnv(t *testing.T) {
 	assert.NotEmpty(t, ts.wd)
 	assert.Equal(t, ts.wd, tse.wd) // check was saved
 
-	assert.Equal(t, os.Environ(), ts.env) // check didn't change
-	assert.Equal(t, append(os.Environ(), "k=v"), tse.env)
+	assert.Equal(t, allowedEnviron(), ts.env) // check didn't change
+	assert.Equal(t, append(allowedEnviron(), "k=v"), tse.env)
+}
+
+func TestTempDirShellEnvAllowlist(t *testing.T) {
+	os.Setenv("TEST_WORKER_SECRET", "secret")
+	defer os.Unsetenv("TEST_WORKER_SECRET")
+
+	ts, err := NewTempDirShell(t.Name())
+	assert.NoError(t, err)
+	defer ts.Clean()
+
+	out, err := ts.Run(context.Background(), "env")
+	assert.NoError(t, err)
+	assert.NotContains(t, out, "TEST_WORKER_SECRET")
+	assert.Contains(t, out, "PATH=")
+
+	os.Setenv("EXECUTOR_ENV_ALLOWLIST", "TEST_WORKER_SECRET")
+	defer os.Unsetenv("EXECUTOR_ENV_ALLOWLIST")
+	assert.Contains(t, allowedEnviron(), "TEST_WORKER_SECRET=secret")
 }
 
 func exists(t *testing.T, path string) bool {
@@ -1,76 +1,161 @@
 package experiments
 
 import (
+	"context"
+	"fmt"
 	"hash/fnv"
 	"strings"
 
 	"github.com/golangci/golangci-shared/pkg/config"
 	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/analytics"
 	"github.com/golangci/golangci-worker/app/lib/github"
 )
 
 type Checker struct {
-	cfg config.Config
-	log logutil.Log
+	cfg    config.Config
+	log    logutil.Log
+	remote *RemoteSource
 }
 
 func NewChecker(cfg config.Config, log logutil.Log) *Checker {
-	return &Checker{cfg: cfg, log: log}
+	return &Checker{cfg: cfg, log: log, remote: defaultRemoteSource}
 }
 
 func (c Checker) getConfigKey(name, suffix string) string {
 	return strings.ToUpper(name + "_" + suffix)
 }
 
-func (c Checker) parseConfigVarToBoolMap(k string) map[string]bool {
+func (c Checker) parseConfigVarToList(k string) []string {
 	elems := c.cfg.GetString(k)
 	if elems == "" {
-		return map[string]bool{}
+		return nil
 	}
 
-	elemList := strings.Split(elems, ",")
-	ret := map[string]bool{}
-	for _, e := range elemList {
-		ret[e] = true
+	return strings.Split(elems, ",")
+}
+
+func (c Checker) getFromEnv(name string) *Experiment {
+	return &Experiment{
+		Disabled:   c.cfg.GetBool(c.getConfigKey(name, "disabled"), false),
+		ForPulls:   c.cfg.GetBool(c.getConfigKey(name, "for_pulls"), false),
+		Percent:    c.cfg.GetInt(c.getConfigKey(name, "percent"), 0),
+		Repos:      c.parseConfigVarToList(c.getConfigKey(name, "repos")),
+		Owners:     c.parseConfigVarToList(c.getConfigKey(name, "owners")),
+		DenyRepos:  c.parseConfigVarToList(c.getConfigKey(name, "deny_repos")),
+		DenyOwners: c.parseConfigVarToList(c.getConfigKey(name, "deny_owners")),
+
+		BucketVersion: c.cfg.GetInt(c.getConfigKey(name, "bucket_version"), 0),
+	}
+}
+
+func (c Checker) getExperiment(name string) *Experiment {
+	if c.remote != nil {
+		if e, ok := c.remote.Get(name); ok {
+			return e
+		}
+	}
+
+	return c.getFromEnv(name)
+}
+
+func contains(list []string, v string) bool {
+	for _, e := range list {
+		if e == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Bucket returns a sticky bucket [0, 100) of the repo in the experiment: the same repo
+// stays in the experiment while its percent is only increased. Buckets of version 0 depend
+// only on the repo: running rollouts keep their repos. Since version 1 the experiment name
+// salts the hash to not expose the same repos to every experiment.
+func Bucket(name string, repo *github.Repo, version int) uint32 {
+	if version == 0 {
+		return hash(repo.FullName()) % 100
+	}
+
+	return hash(fmt.Sprintf("v%d:%s:%s", version, name, repo.FullName())) % 100
+}
+
+func (c Checker) IsActiveForAnalysis(ctx context.Context, name string, repo *github.Repo, forPull bool) bool {
+	e := c.getExperiment(name)
+	bucket := Bucket(name, repo, e.BucketVersion)
+
+	var active bool
+	var reason string
+	if overridden, ok := getOverride(ctx, name); ok {
+		active, reason = overridden, "task override"
+	} else {
+		active, reason = evaluate(e, bucket, repo, forPull)
+	}
+	c.log.Infof("Experiment %s is %s for repo %s: %s", name, activityString(active), repo.FullName(), reason)
+	if recordEvaluation(ctx, name, active) {
+		trackExposure(ctx, name, repo, forPull, bucket, active, reason)
+	}
+
+	return active
+}
+
+func activityString(active bool) string {
+	if active {
+		return "enabled"
 	}
 
-	return ret
+	return "disabled"
 }
 
-func (c Checker) IsActiveForAnalysis(name string, repo *github.Repo, forPull bool) bool {
-	if forPull && !c.cfg.GetBool(c.getConfigKey(name, "for_pulls"), false) {
-		c.log.Infof("Experiment %s is disabled for pull analyzes", name)
-		return false
+//nolint:gocyclo
+func evaluate(e *Experiment, bucket uint32, repo *github.Repo, forPull bool) (bool, string) {
+	if e.Disabled {
+		return false, "kill switch"
 	}
 
-	enabledRepos := c.parseConfigVarToBoolMap(c.getConfigKey(name, "repos"))
-	if enabledRepos[repo.FullName()] {
-		c.log.Infof("Experiment %s is enabled for repo %s", name, repo.FullName())
-		return true
+	if forPull && !e.ForPulls {
+		return false, "pull analyzes aren't enabled"
 	}
 
-	enabledOwners := c.parseConfigVarToBoolMap(c.getConfigKey(name, "owners"))
-	if enabledOwners[repo.Owner] {
-		c.log.Infof("Experiment %s is enabled for owner of repo %s", name, repo.FullName())
-		return true
+	if contains(e.DenyRepos, repo.FullName()) {
+		return false, "denied repo"
 	}
 
-	percent := c.cfg.GetInt(c.getConfigKey(name, "percent"), 0)
-	if percent < 0 || percent > 100 {
-		c.log.Infof("Experiment %s is disabled: invalid percent %d", name, percent)
-		return false
+	if contains(e.DenyOwners, repo.Owner) {
+		return false, "denied owner"
 	}
 
-	hash := hash(repo.FullName())
-	if uint32(percent) <= (hash % 100) {
-		c.log.Infof("Experiment %s is disabled by percent for repo %s: %d (percent) <= %d (hash mod 100)",
-			name, repo.FullName(), percent, hash%100)
-		return false
+	if contains(e.Repos, repo.FullName()) {
+		return true, "allowed repo"
 	}
 
-	c.log.Infof("Experiment %s is enabled by percent for repo %s: %d (percent) > %d (hash mod 100)",
-		name, repo.FullName(), percent, hash%100)
-	return true
+	if contains(e.Owners, repo.Owner) {
+		return true, "allowed owner"
+	}
+
+	if e.Percent < 0 || e.Percent > 100 {
+		return false, fmt.Sprintf("invalid percent %d", e.Percent)
+	}
+
+	if uint32(e.Percent) <= bucket {
+		return false, fmt.Sprintf("%d (percent) <= %d (bucket)", e.Percent, bucket)
+	}
+
+	return true, fmt.Sprintf("%d (percent) > %d (bucket)", e.Percent, bucket)
+}
+
+func trackExposure(ctx context.Context, name string, repo *github.Repo, forPull bool, bucket uint32, active bool, reason string) {
+	ctx = analytics.ContextWithEventPropsCollector(ctx, analytics.EventExperimentExposure)
+	analytics.SaveEventProps(ctx, analytics.EventExperimentExposure, map[string]interface{}{
+		"experiment": name,
+		"repoName":   repo.FullName(),
+		"forPull":    forPull,
+		"bucket":     bucket,
+		"active":     active,
+		"reason":     reason,
+	})
+	analytics.GetTracker(ctx).Track(ctx, analytics.EventExperimentExposure)
 }
 
 func hash(s string) uint32 {
@@ -0,0 +1,133 @@
+package experiments
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/golangci/golangci-shared/pkg/config"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestChecker(experiments map[string]Experiment) *Checker {
+	log := logutil.NewStderrLog("test")
+	return &Checker{
+		cfg:    config.NewEnvConfig(log),
+		log:    log,
+		remote: &RemoteSource{experiments: experiments},
+	}
+}
+
+func TestRemoteExperiment(t *testing.T) {
+	repo := &github.Repo{Owner: "owner", Name: "repo"}
+
+	cases := []struct {
+		name    string
+		e       Experiment
+		forPull bool
+		active  bool
+	}{
+		{"full rollout", Experiment{Percent: 100}, false, true},
+		{"zero rollout", Experiment{Percent: 0}, false, false},
+		{"kill switch", Experiment{Disabled: true, Percent: 100, Repos: []string{"owner/repo"}}, false, false},
+		{"not for pulls", Experiment{Percent: 100}, true, false},
+		{"for pulls", Experiment{Percent: 100, ForPulls: true}, true, true},
+		{"allowed repo", Experiment{Repos: []string{"owner/repo"}}, false, true},
+		{"allowed owner", Experiment{Owners: []string{"owner"}}, false, true},
+		{"denied repo", Experiment{Percent: 100, DenyRepos: []string{"owner/repo"}}, false, false},
+		{"denied owner over allowed repo", Experiment{Repos: []string{"owner/repo"}, DenyOwners: []string{"owner"}}, false, false},
+		{"invalid percent", Experiment{Percent: 101}, false, false},
+	}
+
+	for _, tc := range cases {
+		c := newTestChecker(map[string]Experiment{"exp": tc.e})
+		assert.Equal(t, tc.active, c.IsActiveForAnalysis(context.Background(), "exp", repo, tc.forPull), tc.name)
+	}
+}
+
+func TestBucketIsSticky(t *testing.T) {
+	repo := &github.Repo{Owner: "owner", Name: "repo"}
+	for _, version := range []int{0, 1} {
+		bucket := Bucket("exp", repo, version)
+		assert.Equal(t, bucket, Bucket("exp", repo, version))
+
+		// once the repo is in the experiment it stays there while percent grows
+		for percent := 0; percent <= 100; percent++ {
+			c := newTestChecker(map[string]Experiment{"exp": {Percent: percent, BucketVersion: version}})
+			assert.Equal(t, uint32(percent) > bucket, c.IsActiveForAnalysis(context.Background(), "exp", repo, false))
+		}
+	}
+}
+
+func TestBucketVersions(t *testing.T) {
+	repo := &github.Repo{Owner: "owner", Name: "repo"}
+
+	// running rollouts keep their repos: version 0 is bucketing by the repo only
+	assert.Equal(t, hash("owner/repo")%100, Bucket("exp", repo, 0))
+	assert.Equal(t, Bucket("exp", repo, 0), Bucket("other", repo, 0))
+
+	differs := false
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		if Bucket(name, repo, 1) != Bucket("exp", repo, 1) {
+			differs = true
+		}
+	}
+	assert.True(t, differs, "the name salts buckets since version 1")
+}
+
+func TestExposureIsRecordedOncePerAnalysis(t *testing.T) {
+	ctx := ContextWithEvaluations(context.Background())
+	assert.True(t, recordEvaluation(ctx, "exp", true))
+	assert.False(t, recordEvaluation(ctx, "exp", true), "e.g. the evaluation by makeExecutor")
+	assert.True(t, recordEvaluation(ctx, "exp", false), "the activity changed")
+	assert.True(t, recordEvaluation(ctx, "other", true))
+
+	assert.True(t, recordEvaluation(context.Background(), "exp", true))
+	assert.True(t, recordEvaluation(context.Background(), "exp", true), "nothing is recorded without evaluations")
+}
+
+func TestEnvFallback(t *testing.T) {
+	repo := &github.Repo{Owner: "owner", Name: "repo"}
+	c := newTestChecker(map[string]Experiment{"other": {Percent: 100}})
+
+	assert.False(t, c.IsActiveForAnalysis(context.Background(), "test_exp", repo, false))
+
+	os.Setenv("TEST_EXP_REPOS", "owner/repo")
+	defer os.Unsetenv("TEST_EXP_REPOS")
+	assert.True(t, c.IsActiveForAnalysis(context.Background(), "test_exp", repo, false))
+
+	os.Setenv("TEST_EXP_DISABLED", "true")
+	defer os.Unsetenv("TEST_EXP_DISABLED")
+	assert.False(t, c.IsActiveForAnalysis(context.Background(), "test_exp", repo, false))
+}
+
+func TestEvaluatedExperiments(t *testing.T) {
+	repo := &github.Repo{Owner: "owner", Name: "repo"}
+	c := newTestChecker(map[string]Experiment{
+		"on":  {Percent: 100},
+		"off": {Disabled: true},
+	})
+
+	ctx := context.Background()
+	assert.True(t, c.IsActiveForAnalysis(ctx, "on", repo, false))
+	assert.Nil(t, Evaluated(ctx))
+
+	ctx = ContextWithEvaluations(ctx)
+	assert.True(t, c.IsActiveForAnalysis(ctx, "on", repo, false))
+	assert.False(t, c.IsActiveForAnalysis(ctx, "off", repo, false))
+	assert.Equal(t, map[string]bool{"on": true, "off": false}, Evaluated(ctx))
+}
+
+func TestTaskOverrides(t *testing.T) {
+	repo := &github.Repo{Owner: "owner", Name: "repo"}
+	c := newTestChecker(map[string]Experiment{
+		"exp": {Disabled: true},
+	})
+
+	ctx := ContextWithOverrides(context.Background(), map[string]bool{"exp": true, "other": false})
+	assert.True(t, c.IsActiveForAnalysis(ctx, "exp", repo, true))
+	assert.False(t, c.IsActiveForAnalysis(ctx, "other", repo, true))
+	assert.False(t, c.IsActiveForAnalysis(context.Background(), "exp", repo, true))
+}
@@ -0,0 +1,72 @@
+package experiments
+
+import (
+	"context"
+	"sync"
+)
+
+type evaluationsKeyType string
+
+const (
+	evaluationsKey evaluationsKeyType = "experiments evaluations"
+	overridesKey   evaluationsKeyType = "experiments overrides"
+)
+
+type evaluations struct {
+	mu     sync.Mutex
+	active map[string]bool
+}
+
+// ContextWithEvaluations records experiments evaluated for an analysis: they are stamped into its result
+func ContextWithEvaluations(ctx context.Context) context.Context {
+	return context.WithValue(ctx, evaluationsKey, &evaluations{active: map[string]bool{}})
+}
+
+// recordEvaluation returns true if it's the first evaluation of the experiment for the analysis
+// or its activity changed: exposures are tracked once per analysis. Evaluations aren't deduplicated
+// in contexts without recorded evaluations.
+func recordEvaluation(ctx context.Context, name string, active bool) bool {
+	e, ok := ctx.Value(evaluationsKey).(*evaluations)
+	if !ok {
+		return true
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	prev, evaluated := e.active[name]
+	e.active[name] = active
+	return !evaluated || prev != active
+}
+
+// Evaluated returns activity of experiments evaluated in the context, it's nil if they aren't recorded
+func Evaluated(ctx context.Context) map[string]bool {
+	e, ok := ctx.Value(evaluationsKey).(*evaluations)
+	if !ok {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ret := make(map[string]bool, len(e.active))
+	for name, active := range e.active {
+		ret[name] = active
+	}
+	return ret
+}
+
+// ContextWithOverrides sets experiments of a task: they take precedence over configured experiments
+func ContextWithOverrides(ctx context.Context, overrides map[string]bool) context.Context {
+	if len(overrides) == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, overridesKey, overrides)
+}
+
+func getOverride(ctx context.Context, name string) (bool, bool) {
+	overrides, _ := ctx.Value(overridesKey).(map[string]bool)
+	active, ok := overrides[name]
+	return active, ok
+}
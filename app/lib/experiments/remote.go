@@ -0,0 +1,96 @@
+package experiments
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/lib/apiclient"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+)
+
+// Experiment is a rollout configuration of one experiment
+type Experiment struct {
+	// Disabled is a kill switch: it disables experiment regardless of other settings
+	Disabled bool
+	ForPulls bool
+	Percent  int
+
+	// BucketVersion is a version of bucketing of repos, see Bucket: changing it reshuffles the rollout
+	BucketVersion int `json:",omitempty"`
+
+	Repos  []string
+	Owners []string
+
+	DenyRepos  []string
+	DenyOwners []string
+}
+
+// RemoteSource keeps experiments fetched from the API
+type RemoteSource struct {
+	api *apiclient.Client
+	log logutil.Log
+
+	mu          sync.RWMutex
+	experiments map[string]Experiment
+}
+
+var defaultRemoteSource = &RemoteSource{}
+
+func NewRemoteSource(client httputils.Client, log logutil.Log) *RemoteSource {
+	return &RemoteSource{
+		api: apiclient.New(client),
+		log: log,
+	}
+}
+
+// Get returns false if the experiment wasn't fetched: env config must be used then
+func (s *RemoteSource) Get(name string) (*Experiment, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.experiments[name]
+	if !ok {
+		return nil, false
+	}
+
+	return &e, true
+}
+
+func (s *RemoteSource) Refresh(ctx context.Context) error {
+	var experiments map[string]Experiment
+	if err := s.api.GetWorkerExperiments(ctx, &experiments); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.experiments = experiments
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *RemoteSource) runRefresher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Refresh(ctx); err != nil {
+			// keep the last fetched experiments: env config is used for not fetched ones
+			s.log.Warnf("Failed to refresh experiments from the API: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunAPISync periodically fetches experiments from the API for all checkers
+func RunAPISync(ctx context.Context, client httputils.Client, log logutil.Log, interval time.Duration) {
+	s := NewRemoteSource(client, log)
+	defaultRemoteSource = s
+	go s.runRefresher(ctx, interval)
+}
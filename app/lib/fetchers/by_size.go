@@ -0,0 +1,33 @@
+package fetchers
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+)
+
+// BySize chooses the fetch strategy by the size of the repo: large repos are fetched by a tarball
+type BySize struct {
+	clone, tarball Fetcher
+	tarballFromKB  int
+}
+
+// NewBySize returns the fetcher using tarball for repos since tarballFromKB,
+// tarballs are never used if it's zero
+func NewBySize(clone, tarball Fetcher, tarballFromKB int) *BySize {
+	return &BySize{
+		clone:         clone,
+		tarball:       tarball,
+		tarballFromKB: tarballFromKB,
+	}
+}
+
+func (f BySize) Fetch(ctx context.Context, repo *Repo, exec executors.Executor) error {
+	if f.tarballFromKB == 0 || repo.SizeKB < f.tarballFromKB || repo.TarballURL == "" {
+		return f.clone.Fetch(ctx, repo, exec)
+	}
+
+	analytics.Log(ctx).Infof("Fetching repo of %dKB by tarball", repo.SizeKB)
+	return f.tarball.Fetch(ctx, repo, exec)
+}
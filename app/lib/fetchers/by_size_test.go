@@ -0,0 +1,35 @@
+package fetchers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBySize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := executors.NewMockExecutor(ctrl)
+	clone := NewMockFetcher(ctrl)
+	tarball := NewMockFetcher(ctrl)
+	f := NewBySize(clone, tarball, 1024)
+
+	small := &Repo{SizeKB: 100, TarballURL: "https://tarball"}
+	clone.EXPECT().Fetch(gomock.Any(), small, exec).Return(nil)
+	assert.NoError(t, f.Fetch(context.Background(), small, exec))
+
+	large := &Repo{SizeKB: 2048, TarballURL: "https://tarball"}
+	tarball.EXPECT().Fetch(gomock.Any(), large, exec).Return(nil)
+	assert.NoError(t, f.Fetch(context.Background(), large, exec))
+
+	noTarball := &Repo{SizeKB: 2048}
+	clone.EXPECT().Fetch(gomock.Any(), noTarball, exec).Return(nil)
+	assert.NoError(t, f.Fetch(context.Background(), noTarball, exec))
+
+	clone.EXPECT().Fetch(gomock.Any(), large, exec).Return(nil)
+	assert.NoError(t, NewBySize(clone, tarball, 0).Fetch(context.Background(), large, exec), "tarballs are disabled")
+}
@@ -2,11 +2,13 @@ package fetchers
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/golangci/golangci-worker/app/analytics"
-
 	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/golangci/golangci-worker/app/lib/redact"
 	"github.com/pkg/errors"
 )
 
@@ -19,27 +21,75 @@ func NewGit() *Git {
 }
 
 func (gf Git) Fetch(ctx context.Context, repo *Repo, exec executors.Executor) error {
+	if p := httputils.TrustedCABundlePath(); p != "" {
+		exec = exec.WithEnv("GIT_SSL_CAINFO", p)
+	}
+
 	args := []string{"clone", "-q", "--depth", "1", "--branch",
 		repo.Ref, repo.CloneURL, "."}
 	if out, err := exec.Run(ctx, "git", args...); err != nil {
-		noBranchOrRepo := strings.Contains(err.Error(), "could not read Username for") ||
-			strings.Contains(err.Error(), "Could not find remote branch")
-		if noBranchOrRepo {
-			return errors.Wrap(ErrNoBranchOrRepo, err.Error())
+		return cloneError(err, args, out)
+	}
+
+	moved, err := fetchCommit(ctx, repo, exec)
+	if err != nil {
+		return err
+	}
+	if moved {
+		if out, err := exec.Run(ctx, "git", "checkout", "-q", repo.CommitSHA); err != nil {
+			return errors.Wrapf(err, "can't checkout commit %s: %s", repo.CommitSHA, out)
 		}
+	}
+
+	updateSubmodules(ctx, exec)
+	return nil
+}
+
+func cloneError(err error, args []string, out string) error {
+	noBranchOrRepo := strings.Contains(err.Error(), "could not read Username for") ||
+		strings.Contains(err.Error(), "Could not find remote branch")
+	if noBranchOrRepo {
+		return errors.Wrap(ErrNoBranchOrRepo, redact.URLCredentials(err.Error()))
+	}
 
-		return errors.Wrapf(err, "can't run git cmd %v: %s", args, out)
+	// the clone URL of a private repo contains the access token
+	return errors.Wrapf(err, "can't run git cmd %s: %s", redact.URLCredentials(fmt.Sprint(args)), redact.URLCredentials(out))
+}
+
+// fetchCommit fetches CommitSHA of the repo if the cloned branch moved since the commit,
+// it returns true if the commit must be checked out
+func fetchCommit(ctx context.Context, repo *Repo, exec executors.Executor, fetchOpts ...string) (bool, error) {
+	if repo.CommitSHA == "" {
+		return false, nil
+	}
+
+	head, err := exec.Run(ctx, "git", "rev-parse", "HEAD")
+	if err != nil {
+		return false, errors.Wrapf(err, "can't get head of %s: %s", repo.Ref, head)
+	}
+	if strings.TrimSpace(head) == repo.CommitSHA {
+		return false, nil
+	}
+
+	analytics.Log(ctx).Infof("Branch %s moved from %s to %s, fetching the commit",
+		repo.Ref, repo.CommitSHA, strings.TrimSpace(head))
+	args := append([]string{"fetch", "-q", "--depth", "1"}, fetchOpts...)
+	args = append(args, "origin", repo.CommitSHA)
+	if out, err := exec.Run(ctx, "git", args...); err != nil {
+		return false, errors.Wrapf(err, "can't fetch commit %s of branch %s: %s", repo.CommitSHA, repo.Ref, out)
 	}
 
+	return true, nil
+}
+
+// updateSubmodules doesn't fail fetching: analysis of the repo without submodules is still useful
+func updateSubmodules(ctx context.Context, exec executors.Executor) {
 	// some repos have deps in submodules, e.g. https://github.com/orbs-network/orbs-network-go
 	if out, err := exec.Run(ctx, "git", "submodule", "init"); err != nil {
 		analytics.Log(ctx).Warnf("Failed to init git submodule: %s, %s", err, out)
-		return nil
+		return
 	}
 	if out, err := exec.Run(ctx, "git", "submodule", "update", "--init", "--recursive"); err != nil {
 		analytics.Log(ctx).Warnf("Failed to update git submodule: %s, %s", err, out)
-		return nil
 	}
-
-	return nil
 }
@@ -2,9 +2,11 @@ package fetchers
 
 import (
 	"context"
+	"errors"
 	"io/ioutil"
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	"github.com/golangci/golangci-worker/app/lib/executors"
 	"github.com/stretchr/testify/assert"
 )
@@ -30,3 +32,40 @@ func TestGitOnTestRepo(t *testing.T) {
 	assert.Equal(t, "README.md", files[1].Name())
 	assert.Equal(t, "main.go", files[2].Name())
 }
+
+func TestGitChecksOutCommitOfMovedBranch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := executors.NewMockExecutor(ctrl)
+	repo := &Repo{
+		CloneURL:  "https://github.com/golangci/test.git",
+		Ref:       "master",
+		CommitSHA: "sha1",
+	}
+	cloneArgs := []interface{}{"clone", "-q", "--depth", "1", "--branch", "master", repo.CloneURL, "."}
+
+	gomock.InOrder(
+		exec.EXPECT().Run(gomock.Any(), "git", cloneArgs...).Return("", nil),
+		exec.EXPECT().Run(gomock.Any(), "git", "rev-parse", "HEAD").Return("sha1\n", nil),
+	)
+	expectSubmodules(exec)
+	assert.NoError(t, NewGit().Fetch(context.Background(), repo, exec), "branch didn't move")
+
+	gomock.InOrder(
+		exec.EXPECT().Run(gomock.Any(), "git", cloneArgs...).Return("", nil),
+		exec.EXPECT().Run(gomock.Any(), "git", "rev-parse", "HEAD").Return("sha2\n", nil),
+		exec.EXPECT().Run(gomock.Any(), "git", "fetch", "-q", "--depth", "1", "origin", "sha1").Return("", nil),
+		exec.EXPECT().Run(gomock.Any(), "git", "checkout", "-q", "sha1").Return("", nil),
+	)
+	expectSubmodules(exec)
+	assert.NoError(t, NewGit().Fetch(context.Background(), repo, exec))
+
+	gomock.InOrder(
+		exec.EXPECT().Run(gomock.Any(), "git", cloneArgs...).Return("", nil),
+		exec.EXPECT().Run(gomock.Any(), "git", "rev-parse", "HEAD").Return("sha2\n", nil),
+		exec.EXPECT().Run(gomock.Any(), "git", "fetch", "-q", "--depth", "1", "origin", "sha1").
+			Return("fatal: remote error: upload-pack: not our ref sha1", errors.New("exit status 128")),
+	)
+	assert.Error(t, NewGit().Fetch(context.Background(), repo, exec), "the reported commit must be analyzed")
+}
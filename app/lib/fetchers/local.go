@@ -0,0 +1,26 @@
+package fetchers
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/pkg/errors"
+)
+
+// Local copies the repo from the local directory instead of cloning, repo.Ref is ignored
+type Local struct {
+	dir string
+}
+
+func NewLocal(dir string) *Local {
+	return &Local{dir: dir}
+}
+
+func (lf Local) Fetch(ctx context.Context, repo *Repo, exec executors.Executor) error {
+	args := []string{"-a", lf.dir + "/.", "."}
+	if out, err := executors.Unrestricted(exec).Run(ctx, "cp", args...); err != nil {
+		return errors.Wrapf(err, "can't copy local repo %s: %s", lf.dir, out)
+	}
+
+	return nil
+}
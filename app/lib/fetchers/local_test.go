@@ -0,0 +1,35 @@
+package fetchers
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocal(t *testing.T) {
+	src, err := ioutil.TempDir("", "local.fetcher")
+	assert.NoError(t, err)
+	defer os.RemoveAll(src)
+
+	assert.NoError(t, os.Mkdir(filepath.Join(src, "pkg"), os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, "pkg", "main.go"), []byte("package pkg"), os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, ".golangci.yml"), nil, os.ModePerm))
+
+	exec, err := executors.NewTempDirShell("test.local")
+	assert.NoError(t, err)
+	defer exec.Clean()
+
+	err = NewLocal(src).Fetch(context.Background(), &Repo{Ref: "ignored"}, exec)
+	assert.NoError(t, err)
+
+	files, err := ioutil.ReadDir(exec.WorkDir())
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, ".golangci.yml", files[0].Name())
+	assert.Equal(t, "pkg", files[1].Name())
+}
@@ -0,0 +1,94 @@
+package fetchers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/pkg/errors"
+)
+
+// filterIgnoredWarning is printed by git if the server doesn't support partial clones: all blobs are fetched then
+const filterIgnoredWarning = "filtering not recognized by server"
+
+// PartialGit clones large repos without blobs (--filter=blob:none): blobs are fetched on demand by checkout,
+// only blobs of SparsePaths of the repo are fetched if they are set. Smaller repos and repos which
+// can't be cloned partially (e.g. by old git) are fetched by the full fetcher.
+type PartialGit struct {
+	full   Fetcher
+	fromKB int
+}
+
+// NewPartialGit returns the fetcher cloning repos since fromKB partially,
+// repos of unknown size are cloned partially too; partial clones are never used if fromKB is zero
+func NewPartialGit(full Fetcher, fromKB int) *PartialGit {
+	return &PartialGit{
+		full:   full,
+		fromKB: fromKB,
+	}
+}
+
+func (f PartialGit) Fetch(ctx context.Context, repo *Repo, exec executors.Executor) error {
+	if f.fromKB == 0 || (repo.SizeKB != 0 && repo.SizeKB < f.fromKB) {
+		return f.full.Fetch(ctx, repo, exec)
+	}
+
+	if p := httputils.TrustedCABundlePath(); p != "" {
+		exec = exec.WithEnv("GIT_SSL_CAINFO", p)
+	}
+
+	args := []string{"clone", "-q", "--depth", "1", "--filter=blob:none", "--no-checkout", "--branch",
+		repo.Ref, repo.CloneURL, "."}
+	out, err := exec.Run(ctx, "git", args...)
+	if err != nil {
+		if cerr := cloneError(err, args, out); errors.Cause(cerr) == ErrNoBranchOrRepo {
+			return cerr
+		}
+
+		analytics.Log(ctx).Warnf("Partial clone failed, fall back to full clone: %s, %s", err, out)
+		return f.full.Fetch(ctx, repo, exec)
+	}
+	if strings.Contains(out, filterIgnoredWarning) {
+		analytics.Log(ctx).Infof("Server doesn't support partial clones, the repo was cloned fully")
+	}
+
+	if len(repo.SparsePaths) != 0 {
+		setSparseCheckout(ctx, repo.SparsePaths, exec)
+	}
+
+	ref := repo.Ref
+	moved, err := fetchCommit(ctx, repo, exec, "--filter=blob:none")
+	if err != nil {
+		return err
+	}
+	if moved {
+		ref = repo.CommitSHA
+	}
+
+	// blobs of the checked out files are fetched here
+	if out, err = exec.Run(ctx, "git", "checkout", "-q", ref); err != nil {
+		return errors.Wrapf(err, "can't checkout %s of partial clone: %s", ref, out)
+	}
+
+	updateSubmodules(ctx, exec)
+	return nil
+}
+
+// setSparseCheckout limits checkout to dirs and files of the repo root (cone mode),
+// all files are checked out if it fails
+func setSparseCheckout(ctx context.Context, dirs []string, exec executors.Executor) {
+	if out, err := exec.Run(ctx, "git", "sparse-checkout", "init", "--cone"); err != nil {
+		analytics.Log(ctx).Warnf("Can't init sparse checkout, check out all files: %s, %s", err, out)
+		return
+	}
+
+	args := append([]string{"sparse-checkout", "set"}, dirs...)
+	if out, err := exec.Run(ctx, "git", args...); err != nil {
+		analytics.Log(ctx).Warnf("Can't set sparse checkout of %v, check out all files: %s, %s", dirs, err, out)
+		if out, err = exec.Run(ctx, "git", "sparse-checkout", "disable"); err != nil {
+			analytics.Log(ctx).Warnf("Can't disable sparse checkout: %s, %s", err, out)
+		}
+	}
+}
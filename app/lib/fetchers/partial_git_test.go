@@ -0,0 +1,94 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var partialCloneArgs = []interface{}{"clone", "-q", "--depth", "1", "--filter=blob:none", "--no-checkout", "--branch",
+	"master", "https://github.com/golangci/test.git", "."}
+
+func expectSubmodules(exec *executors.MockExecutor) {
+	exec.EXPECT().Run(gomock.Any(), "git", "submodule", "init").Return("", nil)
+	exec.EXPECT().Run(gomock.Any(), "git", "submodule", "update", "--init", "--recursive").Return("", nil)
+}
+
+func TestPartialGitSparse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := executors.NewMockExecutor(ctrl)
+	repo := &Repo{
+		CloneURL:    "https://github.com/golangci/test.git",
+		Ref:         "master",
+		SizeKB:      4096,
+		SparsePaths: []string{"services/api", "pkg"},
+	}
+
+	gomock.InOrder(
+		exec.EXPECT().Run(gomock.Any(), "git", partialCloneArgs...).Return("", nil),
+		exec.EXPECT().Run(gomock.Any(), "git", "sparse-checkout", "init", "--cone").Return("", nil),
+		exec.EXPECT().Run(gomock.Any(), "git", "sparse-checkout", "set", "services/api", "pkg").Return("", nil),
+		exec.EXPECT().Run(gomock.Any(), "git", "checkout", "-q", "master").Return("", nil),
+	)
+	expectSubmodules(exec)
+
+	assert.NoError(t, NewPartialGit(NewMockFetcher(ctrl), 1024).Fetch(context.Background(), repo, exec))
+}
+
+func TestPartialGitFallback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := executors.NewMockExecutor(ctrl)
+	full := NewMockFetcher(ctrl)
+	f := NewPartialGit(full, 1024)
+	repo := &Repo{
+		CloneURL: "https://github.com/golangci/test.git",
+		Ref:      "master",
+	}
+
+	exec.EXPECT().Run(gomock.Any(), "git", partialCloneArgs...).
+		Return("error: unknown option `filter'", errors.New("exit status 129"))
+	full.EXPECT().Fetch(gomock.Any(), repo, exec).Return(nil)
+	assert.NoError(t, f.Fetch(context.Background(), repo, exec), "old git can't clone partially")
+
+	exec.EXPECT().Run(gomock.Any(), "git", partialCloneArgs...).
+		Return("", errors.New("warning: Could not find remote branch master to clone"))
+	assert.Equal(t, ErrNoBranchOrRepo, pkgerrors.Cause(f.Fetch(context.Background(), repo, exec)), "missing branches aren't retried")
+
+	small := &Repo{SizeKB: 100}
+	full.EXPECT().Fetch(gomock.Any(), small, exec).Return(nil)
+	assert.NoError(t, f.Fetch(context.Background(), small, exec))
+
+	full.EXPECT().Fetch(gomock.Any(), repo, exec).Return(nil)
+	assert.NoError(t, NewPartialGit(full, 0).Fetch(context.Background(), repo, exec), "partial clones are disabled")
+}
+
+func TestPartialGitMovedBranch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := executors.NewMockExecutor(ctrl)
+	repo := &Repo{
+		CloneURL:  "https://github.com/golangci/test.git",
+		Ref:       "master",
+		CommitSHA: "sha1",
+	}
+
+	gomock.InOrder(
+		exec.EXPECT().Run(gomock.Any(), "git", partialCloneArgs...).Return("", nil),
+		exec.EXPECT().Run(gomock.Any(), "git", "rev-parse", "HEAD").Return("sha2\n", nil),
+		exec.EXPECT().Run(gomock.Any(), "git", "fetch", "-q", "--depth", "1", "--filter=blob:none", "origin", "sha1").Return("", nil),
+		exec.EXPECT().Run(gomock.Any(), "git", "checkout", "-q", "sha1").Return("", nil),
+	)
+	expectSubmodules(exec)
+
+	assert.NoError(t, NewPartialGit(NewMockFetcher(ctrl), 1024).Fetch(context.Background(), repo, exec))
+}
@@ -4,4 +4,17 @@ type Repo struct {
 	CloneURL string
 	Ref      string
 	FullPath string
+
+	// CommitSHA is checked out instead of the head of Ref if it's set: results are reported
+	// for the commit and the branch can move before the repo is cloned
+	CommitSHA string
+
+	// SizeKB is a size of the repo reported by the provider, it's zero if it's unknown
+	SizeKB int
+
+	// TarballURL is an archive of the ref, it's empty if the provider doesn't have archives
+	TarballURL string
+
+	// SparsePaths are dirs checked out by partial clones, all files are checked out if it's empty
+	SparsePaths []string
 }
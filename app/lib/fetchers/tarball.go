@@ -0,0 +1,48 @@
+package fetchers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/pkg/errors"
+)
+
+const tarballFile = ".golangci.repo.tar.gz"
+
+// Tarball downloads and extracts the archive of the ref: it's faster than cloning for large repos,
+// but the workspace has neither git history nor submodules
+type Tarball struct{}
+
+func NewTarball() *Tarball {
+	return &Tarball{}
+}
+
+func (tf Tarball) Fetch(ctx context.Context, repo *Repo, exec executors.Executor) error {
+	if repo.TarballURL == "" {
+		return errors.New("no tarball url of the repo")
+	}
+
+	curlArgs := []string{"-sSfL", "-o", tarballFile}
+	if p := httputils.TrustedCABundlePath(); p != "" {
+		curlArgs = append(curlArgs, "--cacert", p)
+	}
+	if out, err := executors.Unrestricted(exec).Run(ctx, "curl", append(curlArgs, repo.TarballURL)...); err != nil {
+		if strings.Contains(err.Error(), "returned error: 404") {
+			return errors.Wrap(ErrNoBranchOrRepo, err.Error())
+		}
+		return errors.Wrapf(err, "can't download tarball: %s", out)
+	}
+
+	// archives of GitHub have the only top-level dir: {owner}-{name}-{sha}
+	if out, err := executors.Unrestricted(exec).Run(ctx, "tar", "-xzf", tarballFile, "--strip-components=1"); err != nil {
+		return errors.Wrapf(err, "can't extract tarball: %s", out)
+	}
+
+	if out, err := executors.Unrestricted(exec).Run(ctx, "rm", "-f", tarballFile); err != nil {
+		return errors.Wrapf(err, "can't remove tarball: %s", out)
+	}
+
+	return nil
+}
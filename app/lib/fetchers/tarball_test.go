@@ -0,0 +1,25 @@
+package fetchers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTarball(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := executors.NewMockExecutor(ctrl)
+	gomock.InOrder(
+		exec.EXPECT().Run(gomock.Any(), "curl", "-sSfL", "-o", tarballFile, "https://tarball").Return("", nil),
+		exec.EXPECT().Run(gomock.Any(), "tar", "-xzf", tarballFile, "--strip-components=1").Return("", nil),
+		exec.EXPECT().Run(gomock.Any(), "rm", "-f", tarballFile).Return("", nil),
+	)
+	assert.NoError(t, NewTarball().Fetch(context.Background(), &Repo{TarballURL: "https://tarball"}, exec))
+
+	assert.Error(t, NewTarball().Fetch(context.Background(), &Repo{}, exec))
+}
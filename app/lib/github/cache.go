@@ -0,0 +1,175 @@
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	commentsCacheTTL = time.Minute
+	scopesCacheTTL   = 10 * time.Minute
+)
+
+// Cache keeps responses of idempotent GitHub reads: it's shared by worker instances
+type Cache interface {
+	// Get returns nil value if there is no such key
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Delete(key string) error
+}
+
+// Cached unmarshals the cached json value of key into dest, on a miss it fills dest by fetch and caches it.
+// Errors of the cache aren't returned: GitHub is requested instead.
+func Cached(cache Cache, key string, ttl time.Duration, dest interface{}, fetch func() error) error {
+	if cache == nil {
+		return fetch()
+	}
+
+	value, err := cache.Get(key)
+	if err != nil {
+		logrus.Warnf("Can't get %s from github cache: %s", key, err)
+	} else if value != nil {
+		if err = json.Unmarshal(value, dest); err == nil {
+			return nil
+		}
+		logrus.Warnf("Can't unmarshal cached %s: %s", key, err)
+	}
+
+	if err = fetch(); err != nil {
+		return err // errors aren't cached
+	}
+
+	if value, err = json.Marshal(dest); err != nil {
+		logrus.Warnf("Can't marshal %s for github cache: %s", key, err)
+		return nil
+	}
+	if err = cache.Set(key, value, ttl); err != nil {
+		logrus.Warnf("Can't save %s to github cache: %s", key, err)
+	}
+
+	return nil
+}
+
+// RedisCache is a Cache stored in Redis
+type RedisCache struct {
+	pool *redis.Pool
+}
+
+func NewRedisCache(redisURL string) *RedisCache {
+	return &RedisCache{
+		pool: &redis.Pool{
+			MaxIdle:     10,
+			IdleTimeout: 4 * time.Minute,
+			Dial: func() (redis.Conn, error) {
+				return redis.DialURL(redisURL)
+			},
+		},
+	}
+}
+
+func (c RedisCache) Get(key string) ([]byte, error) {
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	value, err := redis.Bytes(conn.Do("GET", key))
+	if err == redis.ErrNil {
+		return nil, nil
+	}
+
+	return value, err
+}
+
+func (c RedisCache) Set(key string, value []byte, ttl time.Duration) error {
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("SET", key, value, "PX", int64(ttl/time.Millisecond))
+	return err
+}
+
+func (c RedisCache) Delete(key string) error {
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("DEL", key)
+	return err
+}
+
+var defaultCache Cache
+var defaultCacheOnce sync.Once
+
+// DefaultCache returns the Redis cache if GITHUB_CACHE=1, it returns nil if caching is disabled
+func DefaultCache() Cache {
+	defaultCacheOnce.Do(func() {
+		if os.Getenv("GITHUB_CACHE") != "1" || os.Getenv("REDIS_URL") == "" {
+			return
+		}
+
+		defaultCache = NewRedisCache(fmt.Sprintf("%s/2", os.Getenv("REDIS_URL"))) // separate DB #2 for the cache
+	})
+
+	return defaultCache
+}
+
+// CachingClient caches idempotent reads of Client for short TTLs: it saves the rate limit
+// for organizations with many concurrent pull requests. Pull requests and patches aren't cached:
+// they must reflect the latest push.
+type CachingClient struct {
+	Client
+	cache Cache
+}
+
+// NewCachingClient returns c itself if cache is nil
+func NewCachingClient(c Client, cache Cache) Client {
+	if cache == nil {
+		return c
+	}
+
+	return &CachingClient{Client: c, cache: cache}
+}
+
+// cacheKey includes the token hash: data fetched by one token must not be returned for another token
+func cacheKey(kind string, c *Context) string {
+	h := sha256.Sum256([]byte(c.GithubAccessToken))
+	return fmt.Sprintf("github:%s:%s#%d:%s", kind, c.Repo.FullName(), c.PullRequestNumber, hex.EncodeToString(h[:8]))
+}
+
+func (cc CachingClient) GetPullRequestComments(ctx context.Context, c *Context) ([]*PullRequestComment, error) {
+	var ret []*PullRequestComment
+	err := Cached(cc.cache, cacheKey("comments", c), commentsCacheTTL, &ret, func() (err error) {
+		ret, err = cc.Client.GetPullRequestComments(ctx, c)
+		return err
+	})
+	return ret, err
+}
+
+func (cc CachingClient) GetTokenScopes(ctx context.Context, c *Context) (*TokenScopes, error) {
+	var ret *TokenScopes
+	tokenCtx := &Context{GithubAccessToken: c.GithubAccessToken} // scopes don't depend on the repo
+	err := Cached(cc.cache, cacheKey("scopes", tokenCtx), scopesCacheTTL, &ret, func() (err error) {
+		ret, err = cc.Client.GetTokenScopes(ctx, c)
+		return err
+	})
+	return ret, err
+}
+
+func (cc CachingClient) CreateReview(ctx context.Context, c *Context, review *Review) error {
+	if err := cc.Client.CreateReview(ctx, c, review); err != nil {
+		return err
+	}
+
+	// the next analysis must see the new comments to not duplicate them
+	if err := cc.cache.Delete(cacheKey("comments", c)); err != nil {
+		logrus.Warnf("Can't invalidate cached comments of %s#%d: %s", c.Repo.FullName(), c.PullRequestNumber, err)
+	}
+	return nil
+}
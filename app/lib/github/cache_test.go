@@ -0,0 +1,64 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type mapCache map[string][]byte
+
+func (c mapCache) Get(key string) ([]byte, error) {
+	return c[key], nil
+}
+
+func (c mapCache) Set(key string, value []byte, _ time.Duration) error {
+	c[key] = value
+	return nil
+}
+
+func (c mapCache) Delete(key string) error {
+	delete(c, key)
+	return nil
+}
+
+func TestCachingClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	c := &FakeContext
+	comments := []*PullRequestComment{{Path: "main.go", Line: 3, Side: SideRight, Body: "issue"}}
+
+	mc := NewMockClient(ctrl)
+	mc.EXPECT().GetPullRequestComments(ctx, c).Return(nil, errors.New("rate limit"))
+	mc.EXPECT().GetPullRequestComments(ctx, c).Return(comments, nil).Times(2)
+	mc.EXPECT().CreateReview(ctx, c, gomock.Any()).Return(nil)
+
+	cache := mapCache{}
+	cc := NewCachingClient(mc, cache)
+
+	_, err := cc.GetPullRequestComments(ctx, c)
+	assert.Error(t, err) // errors aren't cached
+
+	for i := 0; i < 2; i++ {
+		got, err := cc.GetPullRequestComments(ctx, c)
+		assert.NoError(t, err)
+		assert.Equal(t, comments, got)
+	}
+
+	otherToken := *c
+	otherToken.GithubAccessToken = "other"
+	assert.NotEqual(t, cacheKey("comments", c), cacheKey("comments", &otherToken))
+
+	assert.NoError(t, cc.CreateReview(ctx, c, &Review{}))
+	got, err := cc.GetPullRequestComments(ctx, c) // invalidated by the review
+	assert.NoError(t, err)
+	assert.Equal(t, comments, got)
+
+	assert.Equal(t, mc, NewCachingClient(mc, nil))
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/cenkalti/backoff"
@@ -32,10 +33,16 @@ const (
 
 type Client interface {
 	GetPullRequest(ctx context.Context, c *Context) (*gh.PullRequest, error)
-	GetPullRequestComments(ctx context.Context, c *Context) ([]*gh.PullRequestComment, error)
+	GetPullRequestComments(ctx context.Context, c *Context) ([]*PullRequestComment, error)
+	GetPullRequestComment(ctx context.Context, c *Context, id int64) (*PullRequestComment, error)
+	GetPullRequestReviews(ctx context.Context, c *Context) ([]*PullRequestReview, error)
 	GetPullRequestPatch(ctx context.Context, c *Context) (string, error)
-	CreateReview(ctx context.Context, c *Context, review *gh.PullRequestReviewRequest) error
+	RebuildPullRequestPatch(ctx context.Context, c *Context) (*RebuiltPatch, error)
+	GetTokenScopes(ctx context.Context, c *Context) (*TokenScopes, error)
+	CreateReview(ctx context.Context, c *Context, review *Review) error
 	SetCommitStatus(ctx context.Context, c *Context, ref string, status Status, desc, url string) error
+	GetReleaseByTag(ctx context.Context, c *Context, tag string) (*gh.RepositoryRelease, error)
+	EditReleaseBody(ctx context.Context, c *Context, id int, body string) error
 }
 
 type MyClient struct{}
@@ -99,9 +106,16 @@ func (gc *MyClient) GetPullRequest(ctx context.Context, c *Context) (*gh.PullReq
 	return retPR, nil
 }
 
-func (gc *MyClient) CreateReview(ctx context.Context, c *Context, review *gh.PullRequestReviewRequest) error {
-	_, _, err := c.GetClient(ctx).PullRequests.CreateReview(ctx, c.Repo.Owner, c.Repo.Name, c.PullRequestNumber, review)
+// CreateReview uses the line/side API: go-github supports only positions in the diff
+func (gc *MyClient) CreateReview(ctx context.Context, c *Context, review *Review) error {
+	client := c.GetClient(ctx)
+	u := fmt.Sprintf("repos/%s/%s/pulls/%d/reviews", c.Repo.Owner, c.Repo.Name, c.PullRequestNumber)
+	req, err := client.NewRequest(http.MethodPost, u, review)
 	if err != nil {
+		return fmt.Errorf("can't make github review request: %s", err)
+	}
+
+	if _, err = client.Do(ctx, req, nil); err != nil {
 		if terr := transformGithubError(err); terr != nil {
 			return terr
 		}
@@ -120,7 +134,7 @@ func (gc *MyClient) GetPullRequestPatch(ctx context.Context, c *Context) (string
 		raw, _, err := c.GetClient(ctx).PullRequests.GetRaw(ctx, c.Repo.Owner, c.Repo.Name,
 			c.PullRequestNumber, opts)
 		if err != nil {
-			return err
+			return permanentIfDiffTooLarge(err)
 		}
 
 		ret = raw
@@ -128,6 +142,10 @@ func (gc *MyClient) GetPullRequestPatch(ctx context.Context, c *Context) (string
 	}
 
 	if err := retryGet(f); err != nil {
+		if isDiffTooLarge(err) {
+			logrus.Infof("Diff of pull request is too large: %s", err)
+			return "", ErrDiffTooLarge
+		}
 		if terr := transformGithubError(err); terr != nil {
 			return "", terr
 		}
@@ -142,7 +160,7 @@ func (gc *MyClient) SetCommitStatus(ctx context.Context, c *Context, ref string,
 	rs := &gh.RepoStatus{
 		Description: gh.String(desc),
 		State:       gh.String(string(status)),
-		Context:     gh.String("GolangCI"),
+		Context:     gh.String(c.GetStatusContext()),
 	}
 	if url != "" {
 		rs.TargetURL = gh.String(url)
@@ -159,17 +177,20 @@ func (gc *MyClient) SetCommitStatus(ctx context.Context, c *Context, ref string,
 	return nil
 }
 
-func (gc *MyClient) GetPullRequestComments(ctx context.Context, c *Context) ([]*gh.PullRequestComment, error) {
-	var ret []*gh.PullRequestComment
+func (gc *MyClient) GetPullRequestComments(ctx context.Context, c *Context) ([]*PullRequestComment, error) {
+	var ret []*PullRequestComment
 
 	f := func() error {
-		opt := &gh.PullRequestListCommentsOptions{
-			ListOptions: gh.ListOptions{
-				PerPage: 100, // max allowed value, TODO: fetch all comments if >100
-			},
-		}
-		comments, _, err := c.GetClient(ctx).PullRequests.ListComments(ctx, c.Repo.Owner, c.Repo.Name, c.PullRequestNumber, opt)
+		client := c.GetClient(ctx)
+		// max allowed value, TODO: fetch all comments if >100
+		u := fmt.Sprintf("repos/%s/%s/pulls/%d/comments?per_page=100", c.Repo.Owner, c.Repo.Name, c.PullRequestNumber)
+		req, err := client.NewRequest(http.MethodGet, u, nil)
 		if err != nil {
+			return backoff.Permanent(err)
+		}
+
+		var comments []*PullRequestComment
+		if _, err = client.Do(ctx, req, &comments); err != nil {
 			return err
 		}
 
@@ -187,3 +208,163 @@ func (gc *MyClient) GetPullRequestComments(ctx context.Context, c *Context) ([]*
 
 	return ret, nil
 }
+
+func (gc *MyClient) GetPullRequestReviews(ctx context.Context, c *Context) ([]*PullRequestReview, error) {
+	var ret []*PullRequestReview
+
+	f := func() error {
+		client := c.GetClient(ctx)
+		// max allowed value, TODO: fetch all reviews if >100
+		u := fmt.Sprintf("repos/%s/%s/pulls/%d/reviews?per_page=100", c.Repo.Owner, c.Repo.Name, c.PullRequestNumber)
+		req, err := client.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+
+		var reviews []*PullRequestReview
+		if _, err = client.Do(ctx, req, &reviews); err != nil {
+			return err
+		}
+
+		ret = reviews
+		return nil
+	}
+
+	if err := retryGet(f); err != nil {
+		if terr := transformGithubError(err); terr != nil {
+			return nil, terr
+		}
+
+		return nil, fmt.Errorf("can't get pull request %d reviews from github: %s", c.PullRequestNumber, err)
+	}
+
+	return ret, nil
+}
+
+func (gc *MyClient) GetPullRequestComment(ctx context.Context, c *Context, id int64) (*PullRequestComment, error) {
+	var ret *PullRequestComment
+
+	f := func() error {
+		client := c.GetClient(ctx)
+		u := fmt.Sprintf("repos/%s/%s/pulls/comments/%d", c.Repo.Owner, c.Repo.Name, id)
+		req, err := client.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+
+		var comment PullRequestComment
+		if _, err = client.Do(ctx, req, &comment); err != nil {
+			return err
+		}
+
+		ret = &comment
+		return nil
+	}
+
+	if err := retryGet(f); err != nil {
+		if terr := transformGithubError(err); terr != nil {
+			return nil, terr
+		}
+
+		return nil, fmt.Errorf("can't get pull request comment %d from github: %s", id, err)
+	}
+
+	return ret, nil
+}
+
+func (gc *MyClient) GetReleaseByTag(ctx context.Context, c *Context, tag string) (*gh.RepositoryRelease, error) {
+	var ret *gh.RepositoryRelease
+
+	f := func() error {
+		rel, _, err := c.GetClient(ctx).Repositories.GetReleaseByTag(ctx, c.Repo.Owner, c.Repo.Name, tag)
+		if err != nil {
+			return err
+		}
+
+		ret = rel
+		return nil
+	}
+
+	if err := retryGet(f); err != nil {
+		if terr := transformGithubError(err); terr != nil {
+			return nil, terr
+		}
+
+		return nil, fmt.Errorf("can't get release of tag %s from github: %s", tag, err)
+	}
+
+	return ret, nil
+}
+
+// EditReleaseBody replaces only the body: other fields of the release aren't changed
+func (gc *MyClient) EditReleaseBody(ctx context.Context, c *Context, id int, body string) error {
+	rel := &gh.RepositoryRelease{Body: gh.String(body)}
+	_, _, err := c.GetClient(ctx).Repositories.EditRelease(ctx, c.Repo.Owner, c.Repo.Name, id, rel)
+	if err != nil {
+		if terr := transformGithubError(err); terr != nil {
+			return terr
+		}
+
+		return fmt.Errorf("can't edit release %d: %s", id, err)
+	}
+
+	return nil
+}
+
+// TokenScopes are OAuth scopes of the access token
+type TokenScopes struct {
+	// Known is false if the token has no OAuth scopes, e.g. it's a GitHub App installation token
+	Known  bool
+	Scopes []string
+}
+
+func (ts TokenScopes) Has(scope string) bool {
+	for _, s := range ts.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (gc *MyClient) GetTokenScopes(ctx context.Context, c *Context) (*TokenScopes, error) {
+	var ret *TokenScopes
+
+	f := func() error {
+		// rate limit request doesn't count against the rate limit and returns scopes header
+		_, resp, err := c.GetClient(ctx).RateLimits(ctx)
+		if err != nil {
+			return err
+		}
+
+		ret = parseTokenScopes(resp.Header)
+		return nil
+	}
+
+	if err := retryGet(f); err != nil {
+		if terr := transformGithubError(err); terr != nil {
+			return nil, terr
+		}
+
+		return nil, fmt.Errorf("can't get token scopes from github: %s", err)
+	}
+
+	return ret, nil
+}
+
+func parseTokenScopes(h http.Header) *TokenScopes {
+	const scopesHeader = "X-OAuth-Scopes"
+	if _, ok := h[http.CanonicalHeaderKey(scopesHeader)]; !ok {
+		return &TokenScopes{}
+	}
+
+	ret := &TokenScopes{Known: true}
+	for _, s := range strings.Split(h.Get(scopesHeader), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			ret.Scopes = append(ret.Scopes, s)
+		}
+	}
+
+	return ret
+}
@@ -1,6 +1,7 @@
 // Code generated by MockGen. DO NOT EDIT.
 // Source: client.go
 
+// Package github is a generated GoMock package.
 package github
 
 import (
@@ -29,69 +30,146 @@ func NewMockClient(ctrl *gomock.Controller) *MockClient {
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use
-func (_m *MockClient) EXPECT() *MockClientMockRecorder {
-	return _m.recorder
+func (m *MockClient) EXPECT() *MockClientMockRecorder {
+	return m.recorder
 }
 
 // GetPullRequest mocks base method
-func (_m *MockClient) GetPullRequest(ctx context.Context, c *Context) (*github.PullRequest, error) {
-	ret := _m.ctrl.Call(_m, "GetPullRequest", ctx, c)
+func (m *MockClient) GetPullRequest(ctx context.Context, c *Context) (*github.PullRequest, error) {
+	ret := m.ctrl.Call(m, "GetPullRequest", ctx, c)
 	ret0, _ := ret[0].(*github.PullRequest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPullRequest indicates an expected call of GetPullRequest
-func (_mr *MockClientMockRecorder) GetPullRequest(arg0, arg1 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "GetPullRequest", reflect.TypeOf((*MockClient)(nil).GetPullRequest), arg0, arg1)
+func (mr *MockClientMockRecorder) GetPullRequest(ctx, c interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPullRequest", reflect.TypeOf((*MockClient)(nil).GetPullRequest), ctx, c)
 }
 
 // GetPullRequestComments mocks base method
-func (_m *MockClient) GetPullRequestComments(ctx context.Context, c *Context) ([]*github.PullRequestComment, error) {
-	ret := _m.ctrl.Call(_m, "GetPullRequestComments", ctx, c)
-	ret0, _ := ret[0].([]*github.PullRequestComment)
+func (m *MockClient) GetPullRequestComments(ctx context.Context, c *Context) ([]*PullRequestComment, error) {
+	ret := m.ctrl.Call(m, "GetPullRequestComments", ctx, c)
+	ret0, _ := ret[0].([]*PullRequestComment)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPullRequestComments indicates an expected call of GetPullRequestComments
-func (_mr *MockClientMockRecorder) GetPullRequestComments(arg0, arg1 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "GetPullRequestComments", reflect.TypeOf((*MockClient)(nil).GetPullRequestComments), arg0, arg1)
+func (mr *MockClientMockRecorder) GetPullRequestComments(ctx, c interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPullRequestComments", reflect.TypeOf((*MockClient)(nil).GetPullRequestComments), ctx, c)
+}
+
+// GetPullRequestComment mocks base method
+func (m *MockClient) GetPullRequestComment(ctx context.Context, c *Context, id int64) (*PullRequestComment, error) {
+	ret := m.ctrl.Call(m, "GetPullRequestComment", ctx, c, id)
+	ret0, _ := ret[0].(*PullRequestComment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPullRequestComment indicates an expected call of GetPullRequestComment
+func (mr *MockClientMockRecorder) GetPullRequestComment(ctx, c, id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPullRequestComment", reflect.TypeOf((*MockClient)(nil).GetPullRequestComment), ctx, c, id)
+}
+
+// GetPullRequestReviews mocks base method
+func (m *MockClient) GetPullRequestReviews(ctx context.Context, c *Context) ([]*PullRequestReview, error) {
+	ret := m.ctrl.Call(m, "GetPullRequestReviews", ctx, c)
+	ret0, _ := ret[0].([]*PullRequestReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPullRequestReviews indicates an expected call of GetPullRequestReviews
+func (mr *MockClientMockRecorder) GetPullRequestReviews(ctx, c interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPullRequestReviews", reflect.TypeOf((*MockClient)(nil).GetPullRequestReviews), ctx, c)
 }
 
 // GetPullRequestPatch mocks base method
-func (_m *MockClient) GetPullRequestPatch(ctx context.Context, c *Context) (string, error) {
-	ret := _m.ctrl.Call(_m, "GetPullRequestPatch", ctx, c)
+func (m *MockClient) GetPullRequestPatch(ctx context.Context, c *Context) (string, error) {
+	ret := m.ctrl.Call(m, "GetPullRequestPatch", ctx, c)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPullRequestPatch indicates an expected call of GetPullRequestPatch
-func (_mr *MockClientMockRecorder) GetPullRequestPatch(arg0, arg1 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "GetPullRequestPatch", reflect.TypeOf((*MockClient)(nil).GetPullRequestPatch), arg0, arg1)
+func (mr *MockClientMockRecorder) GetPullRequestPatch(ctx, c interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPullRequestPatch", reflect.TypeOf((*MockClient)(nil).GetPullRequestPatch), ctx, c)
+}
+
+// RebuildPullRequestPatch mocks base method
+func (m *MockClient) RebuildPullRequestPatch(ctx context.Context, c *Context) (*RebuiltPatch, error) {
+	ret := m.ctrl.Call(m, "RebuildPullRequestPatch", ctx, c)
+	ret0, _ := ret[0].(*RebuiltPatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RebuildPullRequestPatch indicates an expected call of RebuildPullRequestPatch
+func (mr *MockClientMockRecorder) RebuildPullRequestPatch(ctx, c interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebuildPullRequestPatch", reflect.TypeOf((*MockClient)(nil).RebuildPullRequestPatch), ctx, c)
+}
+
+// GetTokenScopes mocks base method
+func (m *MockClient) GetTokenScopes(ctx context.Context, c *Context) (*TokenScopes, error) {
+	ret := m.ctrl.Call(m, "GetTokenScopes", ctx, c)
+	ret0, _ := ret[0].(*TokenScopes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTokenScopes indicates an expected call of GetTokenScopes
+func (mr *MockClientMockRecorder) GetTokenScopes(ctx, c interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTokenScopes", reflect.TypeOf((*MockClient)(nil).GetTokenScopes), ctx, c)
 }
 
 // CreateReview mocks base method
-func (_m *MockClient) CreateReview(ctx context.Context, c *Context, review *github.PullRequestReviewRequest) error {
-	ret := _m.ctrl.Call(_m, "CreateReview", ctx, c, review)
+func (m *MockClient) CreateReview(ctx context.Context, c *Context, review *Review) error {
+	ret := m.ctrl.Call(m, "CreateReview", ctx, c, review)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateReview indicates an expected call of CreateReview
-func (_mr *MockClientMockRecorder) CreateReview(arg0, arg1, arg2 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "CreateReview", reflect.TypeOf((*MockClient)(nil).CreateReview), arg0, arg1, arg2)
+func (mr *MockClientMockRecorder) CreateReview(ctx, c, review interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReview", reflect.TypeOf((*MockClient)(nil).CreateReview), ctx, c, review)
 }
 
 // SetCommitStatus mocks base method
-func (_m *MockClient) SetCommitStatus(ctx context.Context, c *Context, ref string, status Status, desc string, url string) error {
-	ret := _m.ctrl.Call(_m, "SetCommitStatus", ctx, c, ref, status, desc, url)
+func (m *MockClient) SetCommitStatus(ctx context.Context, c *Context, ref string, status Status, desc, url string) error {
+	ret := m.ctrl.Call(m, "SetCommitStatus", ctx, c, ref, status, desc, url)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetCommitStatus indicates an expected call of SetCommitStatus
-func (_mr *MockClientMockRecorder) SetCommitStatus(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "SetCommitStatus", reflect.TypeOf((*MockClient)(nil).SetCommitStatus), arg0, arg1, arg2, arg3, arg4, arg5)
+func (mr *MockClientMockRecorder) SetCommitStatus(ctx, c, ref, status, desc, url interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCommitStatus", reflect.TypeOf((*MockClient)(nil).SetCommitStatus), ctx, c, ref, status, desc, url)
+}
+
+// GetReleaseByTag mocks base method
+func (m *MockClient) GetReleaseByTag(ctx context.Context, c *Context, tag string) (*github.RepositoryRelease, error) {
+	ret := m.ctrl.Call(m, "GetReleaseByTag", ctx, c, tag)
+	ret0, _ := ret[0].(*github.RepositoryRelease)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReleaseByTag indicates an expected call of GetReleaseByTag
+func (mr *MockClientMockRecorder) GetReleaseByTag(ctx, c, tag interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReleaseByTag", reflect.TypeOf((*MockClient)(nil).GetReleaseByTag), ctx, c, tag)
+}
+
+// EditReleaseBody mocks base method
+func (m *MockClient) EditReleaseBody(ctx context.Context, c *Context, id int, body string) error {
+	ret := m.ctrl.Call(m, "EditReleaseBody", ctx, c, id, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EditReleaseBody indicates an expected call of EditReleaseBody
+func (mr *MockClientMockRecorder) EditReleaseBody(ctx, c, id, body interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditReleaseBody", reflect.TypeOf((*MockClient)(nil).EditReleaseBody), ctx, c, id, body)
 }
@@ -3,9 +3,13 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 
+	"github.com/golangci/golangci-worker/app/lib/httputils"
 	"github.com/google/go-github/github"
 	gh "github.com/google/go-github/github"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
 )
 
@@ -17,21 +21,69 @@ func (r Repo) FullName() string {
 	return fmt.Sprintf("%s/%s", r.Owner, r.Name)
 }
 
+const defaultStatusContext = "GolangCI"
+
 type Context struct {
 	Repo              Repo
 	GithubAccessToken string
 	PullRequestNumber int
+
+	// StatusContext distinguishes commit statuses, e.g. of projects of a monorepo: it's GolangCI if empty
+	StatusContext string `json:",omitempty"`
+
+	// DeployKey is a sealed SSH key private repos are cloned by instead of the token, see deploykeys.Open
+	DeployKey string `json:",omitempty"`
+
+	// Path is a subdirectory of the repo (e.g. backend) the analysis is restricted to: only its packages are analyzed
+	// and only its changes and issues are reported. The whole repo is analyzed if it's empty.
+	Path string `json:",omitempty"`
+
+	// WorkDirEncryption is a mode of the encrypted work dir of the analysis (tmpfs or luks), it's set
+	// for organizations requiring encryption at rest of their code
+	WorkDirEncryption string `json:",omitempty"`
+
+	// APIURL is the GitHub API URL with a trailing slash, e.g. of githubfake.Server: api.github.com is used if empty.
+	// It isn't serialized into tasks: the token must not be sent to hosts from tasks.
+	APIURL string `json:"-"`
+}
+
+func (c Context) GetStatusContext() string {
+	if c.StatusContext == "" {
+		return defaultStatusContext
+	}
+
+	return c.StatusContext
 }
 
 func (c Context) GetClient(ctx context.Context) *github.Client {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: c.GithubAccessToken},
 	)
+	// oauth2 wraps the transport of this client
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: httputils.Transport()})
 	tc := oauth2.NewClient(ctx, ts)
-	return github.NewClient(tc)
+	client := github.NewClient(tc)
+	if c.APIURL != "" {
+		u, err := url.Parse(c.APIURL)
+		if err != nil {
+			logrus.Warnf("Invalid github api url %q, use the default one: %s", c.APIURL, err)
+		} else {
+			client.BaseURL = u
+		}
+	}
+
+	return client
+}
+
+// SSHCloneURL returns the clone URL of the repo for deploy keys
+func SSHCloneURL(r Repo) string {
+	return fmt.Sprintf("git@github.com:%s/%s.git", r.Owner, r.Name)
 }
 
 func (c Context) GetCloneURL(repo *gh.Repository) string {
+	if repo.GetPrivate() && c.DeployKey != "" {
+		return SSHCloneURL(c.Repo)
+	}
 	if repo.GetPrivate() {
 		return fmt.Sprintf("https://%s@github.com/%s/%s.git",
 			c.GithubAccessToken, // it's already the private token
@@ -41,6 +93,20 @@ func (c Context) GetCloneURL(repo *gh.Repository) string {
 	return repo.GetCloneURL()
 }
 
+// GetTarballURL returns the archive of the repo at the ref, see GetCloneURL about private repos.
+// It's empty for private repos with deploy keys: they must be cloned.
+func (c Context) GetTarballURL(repo *gh.Repository, ref string) string {
+	if repo.GetPrivate() && c.DeployKey != "" {
+		return ""
+	}
+	if repo.GetPrivate() {
+		return fmt.Sprintf("https://%s@api.github.com/repos/%s/%s/tarball/%s",
+			c.GithubAccessToken, c.Repo.Owner, c.Repo.Name, ref)
+	}
+
+	return fmt.Sprintf("https://api.github.com/repos/%s/tarball/%s", repo.GetFullName(), ref)
+}
+
 var FakeContext = Context{
 	Repo: Repo{
 		Owner: "owner",
@@ -0,0 +1,501 @@
+// Package githubfake is an in-memory GitHub API server for hermetic tests of processors and reporters:
+// it implements endpoints used by github.MyClient and records commit statuses and reviews created by them.
+package githubfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golangci/golangci-worker/app/lib/diffanchor"
+	"github.com/golangci/golangci-worker/app/lib/github"
+	gh "github.com/google/go-github/github"
+)
+
+// Token is the access token of contexts returned by Server.Context
+const Token = "githubfake-token"
+
+// Status is a commit status set by the worker
+type Status struct {
+	Ref         string
+	State       github.Status
+	Description string
+	TargetURL   string
+	Context     string
+}
+
+type pullRequest struct {
+	pr       *gh.PullRequest
+	patch    string
+	comments []*comment
+}
+
+type comment struct {
+	ID int64 `json:"id"`
+	github.PullRequestComment
+}
+
+type repo struct {
+	prs      map[int]*pullRequest
+	statuses []Status
+	reviews  map[int][]github.Review
+	releases map[string]*gh.RepositoryRelease // by tag
+}
+
+// Server is a fake of the GitHub API, it's safe for concurrent use
+type Server struct {
+	srv *httptest.Server
+
+	mu            sync.Mutex
+	repos         map[string]*repo // by owner/name
+	scopes        []string         // nil if the token has no OAuth scopes
+	nextCommentID int64
+	maxDiffFiles  int // 0 if diffs of any size are served
+}
+
+// NewServer starts the server, it must be closed by Close
+func NewServer() *Server {
+	s := &Server{
+		repos:         map[string]*repo{},
+		nextCommentID: 1,
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// URL is the API URL with a trailing slash
+func (s *Server) URL() string {
+	return s.srv.URL + "/"
+}
+
+// Context returns the context of the pull request of the repo on the server
+func (s *Server) Context(owner, name string, prNumber int) *github.Context {
+	return &github.Context{
+		Repo:              github.Repo{Owner: owner, Name: name},
+		GithubAccessToken: Token,
+		PullRequestNumber: prNumber,
+		APIURL:            s.URL(),
+	}
+}
+
+func (s *Server) repo(owner, name string) *repo {
+	key := owner + "/" + name
+	r := s.repos[key]
+	if r == nil {
+		r = &repo{
+			prs:      map[int]*pullRequest{},
+			reviews:  map[int][]github.Review{},
+			releases: map[string]*gh.RepositoryRelease{},
+		}
+		s.repos[key] = r
+	}
+
+	return r
+}
+
+// AddPullRequest adds or replaces the pull request with the patch (unified diff)
+func (s *Server) AddPullRequest(owner, name string, pr *gh.PullRequest, patch string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.repo(owner, name).prs[pr.GetNumber()] = &pullRequest{pr: pr, patch: patch}
+}
+
+// SetLabels sets labels of the added pull request: they are returned as a part of it
+func (s *Server) SetLabels(owner, name string, prNumber int, labels ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pr := s.repo(owner, name).prs[prNumber]; pr != nil {
+		pr.pr.Labels = nil
+		for _, l := range labels {
+			pr.pr.Labels = append(pr.pr.Labels, &gh.Label{Name: gh.String(l)})
+		}
+	}
+}
+
+// SetTokenScopes sets OAuth scopes of the token, by default the token has no scopes like an app token
+func (s *Server) SetTokenScopes(scopes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scopes = append([]string{}, scopes...)
+}
+
+// SetMaxDiffFiles makes the server refuse with 406 to serve diffs changing more than max files
+// like GitHub does for too large diffs, 0 removes the limit
+func (s *Server) SetMaxDiffFiles(max int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.maxDiffFiles = max
+}
+
+// AddRelease adds or replaces the release of its tag
+func (s *Server) AddRelease(owner, name string, rel *gh.RepositoryRelease) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.repo(owner, name).releases[rel.GetTagName()] = rel
+}
+
+// Statuses returns commit statuses set in the repo in order of setting
+func (s *Server) Statuses(owner, name string) []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Status{}, s.repo(owner, name).statuses...)
+}
+
+// Reviews returns reviews created in the pull request
+func (s *Server) Reviews(owner, name string, prNumber int) []github.Review {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]github.Review{}, s.repo(owner, name).reviews[prNumber]...)
+}
+
+// Release returns the release of the tag or nil
+func (s *Server) Release(owner, name, tag string) *gh.RepositoryRelease {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.repo(owner, name).releases[tag]
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": msg})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+Token {
+		writeError(w, http.StatusUnauthorized, "Bad credentials")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) == 1 && parts[0] == "rate_limit" && r.Method == http.MethodGet {
+		s.serveRateLimit(w)
+		return
+	}
+
+	if len(parts) < 4 || parts[0] != "repos" {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	s.serveRepo(w, r, s.repo(parts[1], parts[2]), parts[3:])
+}
+
+func (s *Server) serveRateLimit(w http.ResponseWriter) {
+	if s.scopes != nil {
+		w.Header().Set("X-OAuth-Scopes", strings.Join(s.scopes, ", "))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"resources": map[string]interface{}{}})
+}
+
+// serveRepo serves path parts after repos/{owner}/{name}
+func (s *Server) serveRepo(w http.ResponseWriter, r *http.Request, rp *repo, parts []string) {
+	route := r.Method + " " + strings.Join(parts, "/")
+	switch {
+	case r.Method == http.MethodPost && len(parts) == 2 && parts[0] == "statuses":
+		s.createStatus(w, r, rp, parts[1])
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "pulls":
+		s.getPullRequest(w, r, rp, parts[1])
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "pulls" && parts[1] == "comments":
+		s.getComment(w, rp, parts[2])
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "pulls" && parts[2] == "comments":
+		s.withPR(w, rp, parts[1], func(_ int, pr *pullRequest) {
+			writeJSON(w, http.StatusOK, pr.comments)
+		})
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "pulls" && parts[2] == "files":
+		s.withPR(w, rp, parts[1], func(_ int, pr *pullRequest) {
+			listFiles(w, r, splitPatch(pr.patch))
+		})
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "pulls" && parts[2] == "reviews":
+		s.withPR(w, rp, parts[1], func(num int, _ *pullRequest) {
+			writeJSON(w, http.StatusOK, listReviews(rp.reviews[num]))
+		})
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "pulls" && parts[2] == "reviews":
+		s.withPR(w, rp, parts[1], func(num int, pr *pullRequest) {
+			s.createReview(w, r, rp, num, pr)
+		})
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "releases" && parts[1] == "tags":
+		if rel := rp.releases[parts[2]]; rel != nil {
+			writeJSON(w, http.StatusOK, rel)
+		} else {
+			writeError(w, http.StatusNotFound, "Not Found")
+		}
+	case r.Method == http.MethodPatch && len(parts) == 2 && parts[0] == "releases":
+		s.editRelease(w, r, rp, parts[1])
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Not Found: %s", route))
+	}
+}
+
+func (s *Server) withPR(w http.ResponseWriter, rp *repo, numStr string, f func(num int, pr *pullRequest)) {
+	num, err := strconv.Atoi(numStr)
+	if err != nil || rp.prs[num] == nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	f(num, rp.prs[num])
+}
+
+func (s *Server) getPullRequest(w http.ResponseWriter, r *http.Request, rp *repo, numStr string) {
+	s.withPR(w, rp, numStr, func(_ int, pr *pullRequest) {
+		if strings.HasSuffix(r.Header.Get("Accept"), ".diff") {
+			if s.maxDiffFiles != 0 && len(splitPatch(pr.patch)) > s.maxDiffFiles {
+				writeError(w, http.StatusNotAcceptable, "Sorry, the diff exceeded the maximum number of files")
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte(pr.patch))
+			return
+		}
+
+		writeJSON(w, http.StatusOK, pr.pr)
+	})
+}
+
+func (s *Server) getComment(w http.ResponseWriter, rp *repo, idStr string) {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	for _, pr := range rp.prs {
+		for _, c := range pr.comments {
+			if c.ID == id {
+				writeJSON(w, http.StatusOK, c)
+				return
+			}
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "Not Found")
+}
+
+func (s *Server) createStatus(w http.ResponseWriter, r *http.Request, rp *repo, ref string) {
+	var rs gh.RepoStatus
+	if err := json.NewDecoder(r.Body).Decode(&rs); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rp.statuses = append(rp.statuses, Status{
+		Ref:         ref,
+		State:       github.Status(rs.GetState()),
+		Description: rs.GetDescription(),
+		TargetURL:   rs.GetTargetURL(),
+		Context:     rs.GetContext(),
+	})
+	writeJSON(w, http.StatusCreated, rs)
+}
+
+// createReview validates the review like GitHub: comments must be on lines of the patch
+func (s *Server) createReview(w http.ResponseWriter, r *http.Request, rp *repo, num int, pr *pullRequest) {
+	var review github.Review
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch review.Event {
+	case github.ReviewEventComment, github.ReviewEventRequestChanges, github.ReviewEventApprove:
+	default:
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("invalid review event %q", review.Event))
+		return
+	}
+
+	lines := changedLines(pr.patch)
+	for _, c := range review.Comments {
+		if !lines[lineKey{path: c.Path, line: c.Line, side: c.Side}] {
+			writeError(w, http.StatusUnprocessableEntity,
+				fmt.Sprintf("Line could not be resolved: %s:%d (%s)", c.Path, c.Line, c.Side))
+			return
+		}
+	}
+
+	rp.reviews[num] = append(rp.reviews[num], review)
+	for _, c := range review.Comments {
+		pr.comments = append(pr.comments, &comment{
+			ID: s.nextCommentID,
+			PullRequestComment: github.PullRequestComment{
+				Path: c.Path,
+				Line: c.Line,
+				Side: c.Side,
+				Body: c.Body,
+			},
+		})
+		s.nextCommentID++
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"id": len(rp.reviews[num]), "state": review.Event})
+}
+
+// reviewStates maps events of created reviews to states of listed reviews
+var reviewStates = map[string]string{
+	github.ReviewEventComment:        github.ReviewStateCommented,
+	github.ReviewEventRequestChanges: github.ReviewStateChangesRequested,
+	github.ReviewEventApprove:        github.ReviewStateApproved,
+}
+
+func listReviews(reviews []github.Review) []github.PullRequestReview {
+	ret := []github.PullRequestReview{}
+	for _, r := range reviews {
+		ret = append(ret, github.PullRequestReview{
+			CommitID: r.CommitID,
+			State:    reviewStates[r.Event],
+			Body:     r.Body,
+		})
+	}
+	return ret
+}
+
+func (s *Server) editRelease(w http.ResponseWriter, r *http.Request, rp *repo, idStr string) {
+	var edit gh.RepositoryRelease
+	if err := json.NewDecoder(r.Body).Decode(&edit); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	for _, rel := range rp.releases {
+		if strconv.Itoa(rel.GetID()) == idStr {
+			if edit.Body != nil {
+				rel.Body = edit.Body
+			}
+			writeJSON(w, http.StatusOK, rel)
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "Not Found")
+}
+
+type lineKey struct {
+	path string
+	line int
+	side github.Side
+}
+
+// splitPatch splits the patch into files like GitHub lists them: without git headers
+func splitPatch(patch string) []*gh.CommitFile {
+	var files []*gh.CommitFile
+	var cur *gh.CommitFile
+	var body []string
+	flush := func() {
+		if cur != nil {
+			if len(body) != 0 {
+				cur.Patch = gh.String(strings.Join(body, "\n"))
+			}
+			files = append(files, cur)
+		}
+		body = nil
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(patch, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			cur = &gh.CommitFile{Status: gh.String("modified")}
+			if i := strings.Index(line, " b/"); i != -1 { // binary files have only this header
+				cur.Filename = gh.String(line[i+len(" b/"):])
+			}
+		case cur == nil:
+		case len(body) != 0:
+			body = append(body, line)
+		case strings.HasPrefix(line, "@@"):
+			body = append(body, line)
+		case strings.HasPrefix(line, "new file mode"):
+			cur.Status = gh.String("added")
+		case strings.HasPrefix(line, "deleted file mode"):
+			cur.Status = gh.String("removed")
+		case strings.HasPrefix(line, "--- a/"):
+			cur.Filename = gh.String(strings.TrimPrefix(line, "--- a/"))
+		case strings.HasPrefix(line, "+++ b/"):
+			cur.Filename = gh.String(strings.TrimPrefix(line, "+++ b/"))
+		}
+	}
+	flush()
+
+	return files
+}
+
+// listFiles writes the requested page of files with a Link header to the next page,
+// files over the limit of GitHub aren't listed
+func listFiles(w http.ResponseWriter, r *http.Request, files []*gh.CommitFile) {
+	if len(files) > github.MaxListedPullRequestFiles {
+		files = files[:github.MaxListedPullRequestFiles]
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage < 1 {
+		perPage = 30
+	}
+
+	from := (page - 1) * perPage
+	if from > len(files) {
+		from = len(files)
+	}
+	to := from + perPage
+	if to >= len(files) {
+		to = len(files)
+	} else {
+		next := *r.URL
+		q := next.Query()
+		q.Set("page", strconv.Itoa(page+1))
+		next.RawQuery = q.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.String()))
+	}
+
+	writeJSON(w, http.StatusOK, append([]*gh.CommitFile{}, files[from:to]...))
+}
+
+// changedLines returns lines of hunks of the patch: GitHub allows review comments only on them
+func changedLines(patch string) map[lineKey]bool {
+	ret := map[lineKey]bool{}
+	p, err := diffanchor.Parse(patch)
+	if err != nil {
+		return ret
+	}
+
+	for _, f := range p.Files {
+		for _, h := range f.Hunks {
+			for _, l := range h.Lines {
+				if l.Kind != diffanchor.Deleted {
+					ret[lineKey{path: f.Path, line: l.NewLine, side: github.SideRight}] = true
+				}
+				if l.Kind != diffanchor.Added {
+					ret[lineKey{path: f.OldPath, line: l.OldLine, side: github.SideLeft}] = true
+				}
+			}
+		}
+	}
+
+	return ret
+}
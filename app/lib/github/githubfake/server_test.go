@@ -0,0 +1,216 @@
+package githubfake
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/lib/github"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	gh "github.com/google/go-github/github"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPatch = `diff --git a/main.go b/main.go
+index 1..2 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@
+ package main
+-var a = 1
++var a = 2
++var b = 3
+ func main() {}
+`
+
+func newTestServer() (*Server, *github.Context) {
+	s := NewServer()
+	s.AddPullRequest("golangci", "repo", &gh.PullRequest{
+		Number: gh.Int(7),
+		Head:   &gh.PullRequestBranch{SHA: gh.String("sha")},
+	}, testPatch)
+	return s, s.Context("golangci", "repo", 7)
+}
+
+func TestPullRequest(t *testing.T) {
+	s, c := newTestServer()
+	defer s.Close()
+	s.SetLabels("golangci", "repo", 7, "wip")
+
+	ctx := context.Background()
+	gc := github.NewMyClient()
+	pr, err := gc.GetPullRequest(ctx, c)
+	assert.NoError(t, err)
+	assert.Equal(t, "sha", pr.GetHead().GetSHA())
+	if assert.Len(t, pr.Labels, 1) {
+		assert.Equal(t, "wip", pr.Labels[0].GetName())
+	}
+
+	patch, err := gc.GetPullRequestPatch(ctx, c)
+	assert.NoError(t, err)
+	assert.Equal(t, testPatch, patch)
+}
+
+func testLargePatch(files int) string {
+	var b strings.Builder
+	for i := 0; i < files; i++ {
+		fmt.Fprintf(&b, "diff --git a/f%d.go b/f%d.go\nindex 1..2 100644\n--- a/f%d.go\n+++ b/f%d.go\n@@ -1 +1 @@\n-a\n+b\n",
+			i, i, i, i)
+	}
+	b.WriteString("diff --git a/new.go b/new.go\nnew file mode 100755\nindex 0..1\n--- /dev/null\n+++ b/new.go\n@@ -0,0 +1 @@\n+c\n")
+	b.WriteString("diff --git a/old.go b/old.go\ndeleted file mode 100644\nindex 1..0\n--- a/old.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-d\n")
+	b.WriteString("diff --git a/logo.png b/logo.png\nindex 1..2 100644\nBinary files a/logo.png and b/logo.png differ\n")
+	return b.String()
+}
+
+func TestTooLargePatchIsRebuiltFromFiles(t *testing.T) {
+	patch := testLargePatch(150)
+	s := NewServer()
+	defer s.Close()
+	s.AddPullRequest("golangci", "repo", &gh.PullRequest{Number: gh.Int(7)}, patch)
+	s.SetMaxDiffFiles(100)
+
+	gc, c := github.NewMyClient(), s.Context("golangci", "repo", 7)
+	_, err := gc.GetPullRequestPatch(context.Background(), c)
+	assert.Equal(t, github.ErrDiffTooLarge, err)
+	rebuilt, err := gc.RebuildPullRequestPatch(context.Background(), c)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// index and mode lines and binary files aren't listed by the API
+	var want []string
+	for _, section := range strings.SplitAfter(patch, "\n") {
+		if !strings.HasPrefix(section, "index ") && !strings.Contains(section, " mode ") &&
+			!strings.Contains(section, "logo.png") {
+			want = append(want, section)
+		}
+	}
+	assert.Equal(t, strings.Join(want, ""), rebuilt.Patch)
+	assert.Equal(t, []string{"logo.png"}, rebuilt.SkippedFiles)
+	assert.False(t, rebuilt.Capped)
+}
+
+func TestTooLargePatchIsCapped(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.AddPullRequest("golangci", "repo", &gh.PullRequest{Number: gh.Int(7)}, testLargePatch(github.MaxListedPullRequestFiles))
+	s.SetMaxDiffFiles(100)
+
+	gc, c := github.NewMyClient(), s.Context("golangci", "repo", 7)
+	_, err := gc.GetPullRequestPatch(context.Background(), c)
+	assert.Equal(t, github.ErrDiffTooLarge, err)
+	rebuilt, err := gc.RebuildPullRequestPatch(context.Background(), c)
+	if assert.NoError(t, err) {
+		assert.True(t, rebuilt.Capped)
+		assert.Empty(t, rebuilt.SkippedFiles, "logo.png isn't listed")
+	}
+}
+
+func TestStatusesAndReviews(t *testing.T) {
+	s, c := newTestServer()
+	defer s.Close()
+
+	ctx := context.Background()
+	gc := github.NewMyClient()
+	assert.NoError(t, gc.SetCommitStatus(ctx, c, "sha", github.StatusPending, "reviewing", ""))
+	assert.NoError(t, gc.SetCommitStatus(ctx, c, "sha", github.StatusSuccess, "no issues", "https://golangci.com"))
+	assert.Equal(t, []Status{
+		{Ref: "sha", State: github.StatusPending, Description: "reviewing", Context: "GolangCI"},
+		{Ref: "sha", State: github.StatusSuccess, Description: "no issues", TargetURL: "https://golangci.com", Context: "GolangCI"},
+	}, s.Statuses("golangci", "repo"))
+
+	review := &github.Review{
+		CommitID: "sha",
+		Event:    github.ReviewEventComment,
+		Comments: []github.ReviewComment{
+			{Path: "main.go", Line: 3, Side: github.SideRight, Body: "b is unused"},
+			{Path: "main.go", Line: 2, Side: github.SideLeft, Body: "a was 1"},
+		},
+	}
+	assert.NoError(t, gc.CreateReview(ctx, c, review))
+	assert.Equal(t, []github.Review{*review}, s.Reviews("golangci", "repo", 7))
+
+	comments, err := gc.GetPullRequestComments(ctx, c)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 2)
+	comment, err := gc.GetPullRequestComment(ctx, c, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "b is unused", comment.Body)
+}
+
+func TestReviewCommentOutOfPatch(t *testing.T) {
+	s, c := newTestServer()
+	defer s.Close()
+
+	err := github.NewMyClient().CreateReview(context.Background(), c, &github.Review{
+		Event:    github.ReviewEventComment,
+		Comments: []github.ReviewComment{{Path: "main.go", Line: 10, Side: github.SideRight, Body: "issue"}},
+	})
+	assert.Error(t, err)
+	assert.Empty(t, s.Reviews("golangci", "repo", 7))
+}
+
+func TestTokenScopesAndAuth(t *testing.T) {
+	s, c := newTestServer()
+	defer s.Close()
+
+	ctx := context.Background()
+	gc := github.NewMyClient()
+	scopes, err := gc.GetTokenScopes(ctx, c)
+	assert.NoError(t, err)
+	assert.False(t, scopes.Known)
+
+	s.SetTokenScopes("repo", "read:org")
+	scopes, err = gc.GetTokenScopes(ctx, c)
+	assert.NoError(t, err)
+	assert.Equal(t, &github.TokenScopes{Known: true, Scopes: []string{"repo", "read:org"}}, scopes)
+
+	c.GithubAccessToken = "invalid"
+	assert.Equal(t, github.ErrUnauthorized, gc.SetCommitStatus(ctx, c, "sha", github.StatusPending, "", ""))
+}
+
+func TestRelease(t *testing.T) {
+	s, c := newTestServer()
+	defer s.Close()
+	s.AddRelease("golangci", "repo", &gh.RepositoryRelease{ID: gh.Int(3), TagName: gh.String("v1.0.0")})
+
+	ctx := context.Background()
+	gc := github.NewMyClient()
+	rel, err := gc.GetReleaseByTag(ctx, c, "v1.0.0")
+	assert.NoError(t, err)
+	assert.NoError(t, gc.EditReleaseBody(ctx, c, rel.GetID(), "notes"))
+	assert.Equal(t, "notes", s.Release("golangci", "repo", "v1.0.0").GetBody())
+}
+
+func TestReplayOfRecordedInteractions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "githubfake")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	s, c := newTestServer()
+	rec, err := httputils.NewCassetteRecorder(httputils.CassetteRecord, path, httputils.Transport(), httputils.NewScrubber(Token))
+	assert.NoError(t, err)
+	restore := httputils.UseCassette(rec)
+
+	ctx := context.Background()
+	gc := github.NewMyClient()
+	recorded, err := gc.GetPullRequestPatch(ctx, c)
+	assert.NoError(t, err)
+	restore()
+	s.Close()
+
+	rep, err := httputils.NewCassetteRecorder(httputils.CassetteReplay, path, nil, httputils.NewScrubber(Token))
+	assert.NoError(t, err)
+	defer httputils.UseCassette(rep)()
+
+	replayed, err := gc.GetPullRequestPatch(ctx, c)
+	assert.NoError(t, err)
+	assert.Equal(t, recorded, replayed)
+	assert.Empty(t, rep.Unused())
+}
@@ -0,0 +1,132 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cenkalti/backoff"
+	gh "github.com/google/go-github/github"
+	"github.com/sirupsen/logrus"
+)
+
+// patchFilesPerPage is the size of the chunks of changed files
+// fetched to rebuild a patch the diff endpoint refused to serve.
+const patchFilesPerPage = 100
+
+// MaxListedPullRequestFiles is the max count of files GitHub lists for a pull request
+const MaxListedPullRequestFiles = 3000
+
+// ErrDiffTooLarge is returned by GetPullRequestPatch if GitHub refused to render the diff of the
+// too large pull request: the patch can be rebuilt by RebuildPullRequestPatch
+var ErrDiffTooLarge = errors.New("diff of pull request is too large")
+
+// RebuiltPatch is the patch of the too large pull request rebuilt from listed files. It's provisional:
+// GitHub lists at most MaxListedPullRequestFiles files, omits patches of binary and large files
+// and doesn't return file modes. The full diff should be made from the clone.
+type RebuiltPatch struct {
+	Patch string
+
+	// Capped is true if the listing reached MaxListedPullRequestFiles: more files could be changed
+	Capped bool
+
+	// SkippedFiles are listed files without a patch, they aren't in Patch
+	SkippedFiles []string
+}
+
+func (p RebuiltPatch) String() string {
+	return fmt.Sprintf("patch is rebuilt from listed files: capped %t, %d files without patch",
+		p.Capped, len(p.SkippedFiles))
+}
+
+// isDiffTooLarge reports whether github refused to render the diff of a pull request
+// because it's too large: it responds with 406 in this case.
+func isDiffTooLarge(err error) bool {
+	if er, ok := err.(*gh.ErrorResponse); ok {
+		return er.Response != nil && er.Response.StatusCode == http.StatusNotAcceptable
+	}
+
+	return false
+}
+
+// RebuildPullRequestPatch joins patches of changed files listed in chunks: it's used
+// if GetPullRequestPatch returned ErrDiffTooLarge
+func (gc *MyClient) RebuildPullRequestPatch(ctx context.Context, c *Context) (*RebuiltPatch, error) {
+	var files []*gh.CommitFile
+	opts := &gh.ListOptions{PerPage: patchFilesPerPage}
+	for {
+		var pageFiles []*gh.CommitFile
+		var resp *gh.Response
+		f := func() error {
+			var err error
+			pageFiles, resp, err = c.GetClient(ctx).PullRequests.ListFiles(ctx, c.Repo.Owner, c.Repo.Name,
+				c.PullRequestNumber, opts)
+			return err
+		}
+		if err := retryGet(f); err != nil {
+			if terr := transformGithubError(err); terr != nil {
+				return nil, terr
+			}
+
+			return nil, fmt.Errorf("can't list files of pull request: %s", err)
+		}
+
+		files = append(files, pageFiles...)
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	ret := buildPatchFromFiles(files)
+	ret.Capped = len(files) >= MaxListedPullRequestFiles
+	if ret.Capped || len(ret.SkippedFiles) != 0 {
+		logrus.Warnf("Rebuilt patch of %s#%d is incomplete: %d files listed, %d files without patch",
+			c.Repo.FullName(), c.PullRequestNumber, len(files), len(ret.SkippedFiles))
+	}
+	return ret, nil
+}
+
+// buildPatchFromFiles joins per-file patches into a git-style diff. Files without a patch
+// are skipped. Modes of files are unknown: headers have no modes, /dev/null marks added and removed files.
+func buildPatchFromFiles(files []*gh.CommitFile) *RebuiltPatch {
+	var b strings.Builder
+	ret := &RebuiltPatch{}
+	for _, f := range files {
+		name := f.GetFilename()
+		if f.GetPatch() == "" {
+			ret.SkippedFiles = append(ret.SkippedFiles, name)
+			continue
+		}
+
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\n", name, name)
+		switch f.GetStatus() {
+		case "added":
+			fmt.Fprintf(&b, "--- /dev/null\n+++ b/%s\n", name)
+		case "removed":
+			fmt.Fprintf(&b, "--- a/%s\n+++ /dev/null\n", name)
+		default:
+			// renamed files are kept under their new name: this client
+			// doesn't know previous file names
+			fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", name, name)
+		}
+
+		b.WriteString(f.GetPatch())
+		if !strings.HasSuffix(f.GetPatch(), "\n") {
+			b.WriteString("\n")
+		}
+	}
+
+	ret.Patch = b.String()
+	return ret
+}
+
+func permanentIfDiffTooLarge(err error) error {
+	if isDiffTooLarge(err) {
+		return &backoff.PermanentError{Err: err}
+	}
+
+	return err
+}
@@ -0,0 +1,58 @@
+package github
+
+// Side of the diff: deleted lines are on the left side
+type Side string
+
+const (
+	SideLeft  Side = "LEFT"
+	SideRight Side = "RIGHT"
+)
+
+// ReviewComment is anchored to a line of a file instead of a position in the diff:
+// it stays on the same line after next pushes
+type ReviewComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Side Side   `json:"side"`
+	Body string `json:"body"`
+
+	// StartLine is set for multi-line comments: the comment spans lines since StartLine to Line
+	StartLine int  `json:"start_line,omitempty"`
+	StartSide Side `json:"start_side,omitempty"`
+}
+
+// Events of reviews: changes requested by a review block merging if the branch protection requires approvals
+const (
+	ReviewEventComment        = "COMMENT"
+	ReviewEventRequestChanges = "REQUEST_CHANGES"
+	ReviewEventApprove        = "APPROVE"
+)
+
+// States of existing reviews
+const (
+	ReviewStateApproved         = "APPROVED"
+	ReviewStateChangesRequested = "CHANGES_REQUESTED"
+	ReviewStateCommented        = "COMMENTED"
+)
+
+type Review struct {
+	CommitID string          `json:"commit_id"`
+	Body     string          `json:"body"`
+	Event    string          `json:"event"`
+	Comments []ReviewComment `json:"comments"`
+}
+
+// PullRequestComment is an existing review comment, Line is zero for comments on outdated code
+type PullRequestComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Side Side   `json:"side"`
+	Body string `json:"body"`
+}
+
+// PullRequestReview is an existing review of a pull request
+type PullRequestReview struct {
+	CommitID string `json:"commit_id"`
+	State    string `json:"state"`
+	Body     string `json:"body"`
+}
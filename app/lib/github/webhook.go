@@ -0,0 +1,83 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	gh "github.com/google/go-github/github"
+)
+
+const (
+	// WebhookSignatureHeader is HMAC-SHA256 of the webhook body by the webhook secret: sha256=<hex>
+	WebhookSignatureHeader = "X-Hub-Signature-256"
+
+	WebhookEventHeader    = "X-GitHub-Event"
+	WebhookDeliveryHeader = "X-GitHub-Delivery"
+)
+
+var (
+	ErrNoWebhookSignature      = errors.New("no webhook signature")
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+)
+
+// VerifyWebhookSignature checks the X-Hub-Signature-256 header of the webhook body by any of secrets:
+// both the current and the previous secrets are set during rotation
+func VerifyWebhookSignature(body []byte, signature string, secrets ...string) error {
+	if signature == "" {
+		return ErrNoWebhookSignature
+	}
+
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || !strings.HasPrefix(signature, "sha256=") {
+		return ErrInvalidWebhookSignature
+	}
+
+	for _, secret := range secrets {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body) //nolint:errcheck
+		if hmac.Equal(got, mac.Sum(nil)) {
+			return nil
+		}
+	}
+
+	return ErrInvalidWebhookSignature
+}
+
+// PullRequestEvent is a pull_request webhook event
+type PullRequestEvent struct {
+	Action string
+	Repo   Repo
+	Number int
+	Head   string // SHA of the head commit
+
+	// Merged is set for closed pull requests which were merged
+	Merged bool
+}
+
+// ParsePullRequestEvent parses the body of the pull_request webhook event
+func ParsePullRequestEvent(body []byte) (*PullRequestEvent, error) {
+	var e gh.PullRequestEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, fmt.Errorf("can't parse pull request event: %s", err)
+	}
+
+	if e.GetRepo().GetOwner().GetLogin() == "" || e.GetRepo().GetName() == "" || e.GetNumber() == 0 {
+		return nil, errors.New("no repo or number in pull request event")
+	}
+
+	return &PullRequestEvent{
+		Action: e.GetAction(),
+		Repo: Repo{
+			Owner: e.GetRepo().GetOwner().GetLogin(),
+			Name:  e.GetRepo().GetName(),
+		},
+		Number: e.GetNumber(),
+		Head:   e.GetPullRequest().GetHead().GetSHA(),
+		Merged: e.GetPullRequest().GetMerged(),
+	}, nil
+}
@@ -0,0 +1,38 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	// the example of GitHub docs
+	body := []byte("Hello, World!")
+	sig := "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+	secret := "It's a Secret to Everybody"
+
+	assert.NoError(t, VerifyWebhookSignature(body, sig, secret))
+	assert.NoError(t, VerifyWebhookSignature(body, sig, "new secret", secret), "the previous secret is accepted")
+	assert.Equal(t, ErrInvalidWebhookSignature, VerifyWebhookSignature(body, sig, "other"))
+	assert.Equal(t, ErrInvalidWebhookSignature, VerifyWebhookSignature([]byte("Hello"), sig, secret))
+	assert.Equal(t, ErrInvalidWebhookSignature, VerifyWebhookSignature(body, "sha1=757107ea", secret))
+	assert.Equal(t, ErrNoWebhookSignature, VerifyWebhookSignature(body, "", secret))
+}
+
+func TestParsePullRequestEvent(t *testing.T) {
+	e, err := ParsePullRequestEvent([]byte(`{"action": "synchronize", "number": 3,
+		"pull_request": {"head": {"sha": "abc"}},
+		"repository": {"name": "worker", "owner": {"login": "golangci"}}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, &PullRequestEvent{Action: "synchronize", Repo: Repo{Owner: "golangci", Name: "worker"},
+		Number: 3, Head: "abc"}, e)
+
+	e, err = ParsePullRequestEvent([]byte(`{"action": "closed", "number": 3, "pull_request": {"merged": true},
+		"repository": {"name": "worker", "owner": {"login": "golangci"}}}`))
+	assert.NoError(t, err)
+	assert.True(t, e.Merged)
+
+	_, err = ParsePullRequestEvent([]byte(`{"action": "opened"}`))
+	assert.Error(t, err)
+}
@@ -41,7 +41,7 @@ func (w *Go) Setup(ctx context.Context, repo *fetchers.Repo, projectPathParts ..
 		projectPathParts = newProjectPathParts
 	}
 
-	if _, err := w.exec.Run(ctx, "find", ".", "-delete"); err != nil {
+	if _, err := executors.Unrestricted(w.exec).Run(ctx, "find", ".", "-delete"); err != nil {
 		analytics.Log(ctx).Warnf("Failed to cleanup after repo info fetcher: %s", err)
 	}
 
@@ -49,7 +49,7 @@ func (w *Go) Setup(ctx context.Context, repo *fetchers.Repo, projectPathParts ..
 	wdParts := []string{gopath, "src"}
 	wdParts = append(wdParts, projectPathParts...)
 	wd := filepath.Join(wdParts...)
-	if out, err := w.exec.Run(ctx, "mkdir", "-p", wd); err != nil {
+	if out, err := executors.Unrestricted(w.exec).Run(ctx, "mkdir", "-p", wd); err != nil {
 		return fmt.Errorf("can't create project dir %q: %s, %s", wd, err, out)
 	}
 
@@ -72,12 +72,12 @@ func (w Go) Gopath() string {
 
 func (w Go) FetchDeps(ctx context.Context, fullRepoPath string) (*ensuredeps.Result, error) {
 	cleanupPath := filepath.Join("/app", "cleanup.sh")
-	out, err := w.exec.Run(ctx, "bash", cleanupPath)
+	out, err := executors.Unrestricted(w.exec).Run(ctx, "bash", cleanupPath)
 	if err != nil {
 		return nil, fmt.Errorf("can't call /app/cleanup.sh: %s, %s", err, out)
 	}
 
-	out, err = w.exec.Run(ctx, "ensuredeps", "--repo", fullRepoPath)
+	out, err = executors.Unrestricted(w.exec).Run(ctx, "ensuredeps", "--repo", fullRepoPath)
 	if err != nil {
 		return nil, fmt.Errorf("can't ensuredeps --repo %s: %s, %s", fullRepoPath, err, out)
 	}
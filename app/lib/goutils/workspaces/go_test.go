@@ -0,0 +1,42 @@
+package workspaces
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/analyze/repoinfo"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/fetchers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoPrepareInRestrictedExecutor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	defer os.Setenv("EXECUTOR_COMMANDS_MODE", os.Getenv("EXECUTOR_COMMANDS_MODE"))
+	os.Setenv("EXECUTOR_COMMANDS_MODE", "allowlist")
+
+	ctx := context.Background()
+	repo := &fetchers.Repo{FullPath: "github.com/golangci/test"}
+	e := executors.NewMockExecutor(ctrl)
+	exec := executors.RestrictedFromEnv(e)
+
+	infoFetcher := repoinfo.NewMockFetcher(ctrl)
+	infoFetcher.EXPECT().Fetch(ctx, repo, exec).Return(&repoinfo.Info{}, nil)
+
+	e.EXPECT().WorkDir().Return("/goapp").AnyTimes()
+	e.EXPECT().SetEnv("GOPATH", "/goapp")
+	e.EXPECT().WithWorkDir("/goapp/src/github.com/golangci/test").Return(e)
+	e.EXPECT().Run(ctx, "find", ".", "-delete").Return("", nil)
+	e.EXPECT().Run(ctx, "mkdir", "-p", "/goapp/src/github.com/golangci/test").Return("", nil)
+	e.EXPECT().Run(ctx, "bash", "/app/cleanup.sh").Return("", nil)
+	e.EXPECT().Run(ctx, "ensuredeps", "--repo", repo.FullPath).Return("{}", nil)
+
+	w := NewGo(exec, infoFetcher)
+	assert.NoError(t, w.Setup(ctx, repo, "github.com", "golangci", "test"))
+	_, err := w.FetchDeps(ctx, repo.FullPath)
+	assert.NoError(t, err)
+}
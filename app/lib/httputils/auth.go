@@ -0,0 +1,22 @@
+package httputils
+
+import "sync"
+
+var authToken string
+var authTokenLock sync.RWMutex
+
+// SetAuthToken sets the bearer token for all requests of clients without own token,
+// e.g. credentials of a self-hosted worker received at registration
+func SetAuthToken(token string) {
+	authTokenLock.Lock()
+	defer authTokenLock.Unlock()
+
+	authToken = token
+}
+
+func getAuthToken() string {
+	authTokenLock.RLock()
+	defer authTokenLock.RUnlock()
+
+	return authToken
+}
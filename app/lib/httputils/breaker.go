@@ -0,0 +1,95 @@
+package httputils
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open: too many recent failures of the host")
+
+// breaker opens after threshold consecutive failures and fails requests fast during cooldown;
+// after cooldown one trial request is allowed: its success closes the breaker. Every allowed request
+// must report its outcome by onSuccess, onFailure or onCancel: the trial slot is held until then.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	trialing bool
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+func (b *breaker) isOpen() bool {
+	return b.failures >= b.threshold
+}
+
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.isOpen() {
+		return nil
+	}
+
+	if b.trialing || b.now().Sub(b.openedAt) < b.cooldown {
+		return ErrCircuitOpen
+	}
+
+	b.trialing = true // half-open
+	return nil
+}
+
+func (b *breaker) onSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.trialing = false
+}
+
+func (b *breaker) onFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trialing = false
+	if b.isOpen() {
+		b.openedAt = b.now()
+	}
+}
+
+// onCancel releases the trial slot of a request cancelled by its caller: it says nothing about the host
+func (b *breaker) onCancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trialing = false
+}
+
+var breakers = map[string]*breaker{}
+var breakersLock sync.Mutex
+
+func getBreaker(host string) *breaker {
+	breakersLock.Lock()
+	defer breakersLock.Unlock()
+
+	b := breakers[host]
+	if b == nil {
+		opts := getDefaultOptions()
+		b = newBreaker(opts.breakerThreshold, opts.breakerCooldown)
+		breakers[host] = b
+	}
+
+	return b
+}
@@ -0,0 +1,428 @@
+package httputils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// CassetteMode is the mode of a cassette recorder
+type CassetteMode string
+
+const (
+	// CassetteRecord makes real requests and records them with responses
+	CassetteRecord CassetteMode = "record"
+
+	// CassetteReplay returns recorded responses without requests
+	CassetteReplay CassetteMode = "replay"
+)
+
+const scrubbed = "[scrubbed]"
+
+// CassetteBody is a body of a request or a response, binary bodies (e.g. gzipped) are in base64
+type CassetteBody struct {
+	Text     string `json:",omitempty"`
+	Encoding string `json:",omitempty"` // base64 or empty
+}
+
+func newCassetteBody(b []byte) CassetteBody {
+	if utf8.Valid(b) {
+		return CassetteBody{Text: string(b)}
+	}
+
+	return CassetteBody{Text: base64.StdEncoding.EncodeToString(b), Encoding: "base64"}
+}
+
+func (b CassetteBody) bytes() ([]byte, error) {
+	if b.Encoding == "base64" {
+		return base64.StdEncoding.DecodeString(b.Text)
+	}
+
+	return []byte(b.Text), nil
+}
+
+type CassetteRequest struct {
+	Method string
+	URL    string
+	Header http.Header `json:",omitempty"`
+	Body   CassetteBody
+}
+
+type CassetteResponse struct {
+	StatusCode int
+	Header     http.Header `json:",omitempty"`
+	Body       CassetteBody
+}
+
+// Interaction is a request and its response, secrets are scrubbed from both
+type Interaction struct {
+	Request  CassetteRequest
+	Response CassetteResponse
+}
+
+// Cassette is a recording of http interactions, it's saved as json
+type Cassette struct {
+	Interactions []Interaction
+}
+
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read cassette: %s", err)
+	}
+
+	var c Cassette
+	if err = json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("can't parse cassette %s: %s", path, err)
+	}
+
+	return &c, nil
+}
+
+// Save writes the cassette atomically: a replay never reads a partially written cassette
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("can't marshal cassette: %s", err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("can't create temp cassette file: %s", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("can't write cassette: %s", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("can't write cassette: %s", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// secretHeaders are never recorded
+var secretHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie",
+	"X-Golangci-Signature", "X-Golangci-Timestamp"}
+
+var secretParamRe = regexp.MustCompile(`(?i)\b((?:access_|client_|private_)?(?:token|secret|password|key))=[^&\s"]+`)
+var urlUserinfoRe = regexp.MustCompile(`(https?://)[^/@\s"]+@`)
+
+// Scrubber removes secrets from recorded interactions: known secret values, userinfo of URLs
+// (e.g. tokens in clone URLs) and secret query params
+type Scrubber struct {
+	secrets []string
+}
+
+// NewScrubber returns the scrubber of the values, empty and short values are ignored: they would corrupt recordings
+func NewScrubber(secrets ...string) *Scrubber {
+	s := &Scrubber{}
+	for _, v := range secrets {
+		if len(v) >= 8 {
+			s.secrets = append(s.secrets, v)
+		}
+	}
+
+	// longer secrets first: a secret can contain another one
+	sort.Slice(s.secrets, func(i, j int) bool {
+		return len(s.secrets[i]) > len(s.secrets[j])
+	})
+	return s
+}
+
+var secretEnvRe = regexp.MustCompile(`TOKEN|SECRET|KEY|PASSWORD`)
+
+// NewScrubberFromEnv scrubs values of env vars with secret-like names, e.g. GITHUB_REVIEWER_ACCESS_TOKEN
+func NewScrubberFromEnv() *Scrubber {
+	var secrets []string
+	for _, kv := range os.Environ() {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 && secretEnvRe.MatchString(parts[0]) {
+			secrets = append(secrets, parts[1])
+		}
+	}
+
+	return NewScrubber(secrets...)
+}
+
+func (s *Scrubber) Scrub(text string) string {
+	for _, v := range s.secrets {
+		text = strings.Replace(text, v, scrubbed, -1)
+	}
+	text = urlUserinfoRe.ReplaceAllString(text, "${1}"+scrubbed+"@")
+	return secretParamRe.ReplaceAllString(text, "${1}="+scrubbed)
+}
+
+func (s *Scrubber) scrubHeader(h http.Header) http.Header {
+	ret := http.Header{}
+	for k, vs := range h {
+		if isSecretHeader(k) {
+			continue
+		}
+		for _, v := range vs {
+			ret.Add(k, s.Scrub(v))
+		}
+	}
+
+	return ret
+}
+
+func isSecretHeader(name string) bool {
+	for _, h := range secretHeaders {
+		if http.CanonicalHeaderKey(name) == h {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Scrubber) scrubBody(b []byte) CassetteBody {
+	if !utf8.Valid(b) {
+		return newCassetteBody(b) // binary bodies can't be scrubbed, they are gzipped API requests without secrets
+	}
+
+	return CassetteBody{Text: s.Scrub(string(scrubJSON(b)))}
+}
+
+var secretFieldRe = regexp.MustCompile(`(?i)(token|secret|password|authorization|key)$`)
+
+// scrubJSON replaces values of fields with secret-like names in json bodies, e.g. tokens of Vault
+// responses and task payloads: their values are unknown to the scrubber. Other bodies are returned as is.
+func scrubJSON(b []byte) []byte {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil || d.More() {
+		return b
+	}
+
+	if !scrubJSONValue(v) {
+		return b // keep the recorded body byte-to-byte
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return b
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+// scrubJSONValue scrubs v in place, it returns true if something was scrubbed
+func scrubJSONValue(v interface{}) bool {
+	changed := false
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for k, fv := range vv {
+			if fv != nil && secretFieldRe.MatchString(k) {
+				vv[k] = scrubbed
+				changed = true
+				continue
+			}
+			changed = scrubJSONValue(fv) || changed
+		}
+	case []interface{}:
+		for _, ev := range vv {
+			changed = scrubJSONValue(ev) || changed
+		}
+	}
+
+	return changed
+}
+
+// CassetteRecorder is a transport recording interactions to a cassette file or replaying them from it.
+// Requests are matched by the method and the scrubbed URL in order of recording: bodies aren't matched,
+// they can contain times and change with the pipeline.
+type CassetteRecorder struct {
+	mode     CassetteMode
+	path     string
+	next     http.RoundTripper
+	scrubber *Scrubber
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewCassetteRecorder returns the recorder of the cassette at the path, requests are made by next in the record mode
+func NewCassetteRecorder(mode CassetteMode, path string, next http.RoundTripper, scrubber *Scrubber) (*CassetteRecorder, error) {
+	r := &CassetteRecorder{
+		mode:     mode,
+		path:     path,
+		next:     next,
+		scrubber: scrubber,
+		cassette: &Cassette{},
+	}
+
+	switch mode {
+	case CassetteRecord:
+	case CassetteReplay:
+		c, err := LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = c
+		r.used = make([]bool, len(c.Interactions))
+	default:
+		return nil, fmt.Errorf("invalid cassette mode %q", mode)
+	}
+
+	return r, nil
+}
+
+func (r *CassetteRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	if r.mode == CassetteReplay {
+		return r.replay(req)
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err // transport errors aren't recorded
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	in := Interaction{
+		Request: CassetteRequest{
+			Method: req.Method,
+			URL:    r.scrubber.Scrub(req.URL.String()),
+			Header: r.scrubber.scrubHeader(req.Header),
+			Body:   r.scrubber.scrubBody(reqBody),
+		},
+		Response: CassetteResponse{
+			StatusCode: resp.StatusCode,
+			Header:     r.scrubber.scrubHeader(resp.Header),
+			Body:       r.scrubber.scrubBody(respBody),
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, in)
+	if err = r.cassette.Save(r.path); err != nil {
+		return nil, fmt.Errorf("can't record interaction: %s", err)
+	}
+
+	return resp, nil
+}
+
+func (r *CassetteRecorder) replay(req *http.Request) (*http.Response, error) {
+	reqURL := r.scrubber.Scrub(req.URL.String())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || in.Request.Method != req.Method || in.Request.URL != reqURL {
+			continue
+		}
+
+		body, err := in.Response.Body.bytes()
+		if err != nil {
+			return nil, fmt.Errorf("can't decode recorded body of %s %s: %s", req.Method, reqURL, err)
+		}
+
+		r.used[i] = true
+		header := http.Header{}
+		for k, vs := range in.Response.Header {
+			header[k] = append([]string{}, vs...)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no recorded interaction for %s %s in cassette %s", req.Method, reqURL, r.path)
+}
+
+// Unused returns recorded requests which weren't replayed: regression tests check the pipeline makes all of them
+func (r *CassetteRecorder) Unused() []CassetteRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ret []CassetteRequest
+	for i, in := range r.cassette.Interactions {
+		if r.mode == CassetteReplay && !r.used[i] {
+			ret = append(ret, in.Request)
+		}
+	}
+
+	return ret
+}
+
+// cassetteFromEnv returns the recorder set by HTTP_CASSETTE_RECORD or HTTP_CASSETTE_REPLAY (a cassette path) or nil
+func cassetteFromEnv(next http.RoundTripper) (*CassetteRecorder, error) {
+	mode, path := CassetteRecord, os.Getenv("HTTP_CASSETTE_RECORD")
+	if path == "" {
+		mode, path = CassetteReplay, os.Getenv("HTTP_CASSETTE_REPLAY")
+	}
+	if path == "" {
+		return nil, nil
+	}
+
+	return NewCassetteRecorder(mode, path, next, NewScrubberFromEnv())
+}
+
+// errTransport fails all requests: requests mustn't be made unrecorded if the cassette is broken
+type errTransport struct {
+	err error
+}
+
+func (t errTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+var activeCassette *CassetteRecorder
+var activeCassetteMu sync.Mutex
+
+// UseCassette makes Transport record or replay by the recorder until restore is called, it's used by regression tests
+func UseCassette(r *CassetteRecorder) (restore func()) {
+	activeCassetteMu.Lock()
+	defer activeCassetteMu.Unlock()
+
+	prev := activeCassette
+	activeCassette = r
+	return func() {
+		activeCassetteMu.Lock()
+		defer activeCassetteMu.Unlock()
+		activeCassette = prev
+	}
+}
@@ -1,11 +1,19 @@
 package httputils
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
+	"time"
 
+	"github.com/cenkalti/backoff"
 	"github.com/golangci/golangci-worker/app/analytics"
+	"github.com/golangci/golangci-worker/app/lib/signing"
 	"github.com/levigross/grequests"
 )
 
@@ -13,46 +21,297 @@ import (
 
 type Client interface {
 	Get(ctx context.Context, url string) (io.ReadCloser, error)
+
+	// GetIfNoneMatch makes a conditional request: body is nil if the resource still matches etag,
+	// the returned etag is the etag of the current resource
+	GetIfNoneMatch(ctx context.Context, url, etag string) (body io.ReadCloser, newETag string, err error)
 	Put(ctx context.Context, url string, jsonObj interface{}) error
+	Post(ctx context.Context, url string, jsonObj interface{}) error
+
+	// Patch sends a JSON merge patch (RFC 7386): only changed fields of the resource
+	Patch(ctx context.Context, url string, patch json.RawMessage) error
+	PostWithResponse(ctx context.Context, url string, jsonObj interface{}) (io.ReadCloser, error)
+	Delete(ctx context.Context, url string) error
+}
+
+// GrequestsClient retries idempotent requests failed with connection errors and 5xx codes,
+// requests to a host are failed fast while its circuit breaker is open.
+type GrequestsClient struct {
+	// Timeout of one request attempt, HTTP_TIMEOUT env var (30s by default) is used if it's zero
+	Timeout time.Duration
+
+	// MaxRetries after the first attempt, zero turns retries off; HTTP_MAX_RETRIES env var (3 by default)
+	// is used if it's nil. POST and PATCH requests aren't idempotent: they are never retried.
+	MaxRetries *int
+
+	// Signer signs requests, the signer configured by API_SIGNING_KEY env vars is used if it's nil
+	Signer *signing.Signer
+
+	// AuthToken is sent as a bearer token, the token set by SetAuthToken is used if it's empty
+	AuthToken string
+
+	// GzipFromBytes is the min size of request bodies compressed by gzip,
+	// HTTP_GZIP_FROM_KB env var (compression is disabled by default) is used if it's zero
+	GzipFromBytes int
 }
 
-type GrequestsClient struct{}
+var _ Client = GrequestsClient{}
+
+// StatusError is returned for responses with error codes, 5xx codes are returned after retries
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("got error code from %q: %d", e.URL, e.StatusCode)
+}
 
 func (c GrequestsClient) Get(ctx context.Context, url string) (io.ReadCloser, error) {
-	resp, err := grequests.Get(url, &grequests.RequestOptions{
-		Context: ctx,
-	})
+	resp, err := c.do(ctx, http.MethodGet, url, nil, nil)
 	if err != nil {
-		return nil, fmt.Errorf("unable to make GET http request %q: %s", url, err)
+		return nil, err
 	}
 
-	if !resp.Ok {
-		if cerr := resp.Close(); cerr != nil {
-			analytics.Log(ctx).Warnf("Can't close %q response: %s", url, cerr)
-		}
+	return resp, nil
+}
 
-		return nil, fmt.Errorf("got error code from %q: %d", url, resp.StatusCode)
+func (c GrequestsClient) GetIfNoneMatch(ctx context.Context, url, etag string) (io.ReadCloser, string, error) {
+	var hdrs map[string]string
+	if etag != "" {
+		hdrs = map[string]string{"If-None-Match": etag}
 	}
 
-	return resp, nil
+	resp, err := c.do(ctx, http.MethodGet, url, nil, hdrs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		closeResponse(ctx, url, resp)
+		return nil, etag, nil
+	}
+
+	return resp, resp.Header.Get("ETag"), nil
 }
 
 func (c GrequestsClient) Put(ctx context.Context, url string, jsonObj interface{}) error {
-	resp, err := grequests.Put(url, &grequests.RequestOptions{
-		Context: ctx,
-		JSON:    jsonObj,
-	})
+	return c.doAndClose(ctx, http.MethodPut, url, jsonObj)
+}
+
+func (c GrequestsClient) Post(ctx context.Context, url string, jsonObj interface{}) error {
+	return c.doAndClose(ctx, http.MethodPost, url, jsonObj)
+}
+
+func (c GrequestsClient) Patch(ctx context.Context, url string, patch json.RawMessage) error {
+	resp, err := c.do(ctx, http.MethodPatch, url, patch, map[string]string{"Content-Type": mergePatchContentType})
 	if err != nil {
-		return fmt.Errorf("unable to make PUT http request %q: %s", url, err)
+		return err
 	}
 
-	if !resp.Ok {
-		if cerr := resp.Close(); cerr != nil {
-			analytics.Log(ctx).Warnf("Can't close %q response: %s", url, cerr)
-		}
+	closeResponse(ctx, url, resp)
+	return nil
+}
 
-		return fmt.Errorf("got error code from %q: %d", url, resp.StatusCode)
+func (c GrequestsClient) PostWithResponse(ctx context.Context, url string, jsonObj interface{}) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodPost, url, jsonObj, nil)
+	if err != nil {
+		return nil, err
 	}
 
+	return resp, nil
+}
+
+func (c GrequestsClient) Delete(ctx context.Context, url string) error {
+	return c.doAndClose(ctx, http.MethodDelete, url, nil)
+}
+
+func (c GrequestsClient) doAndClose(ctx context.Context, method, url string, jsonObj interface{}) error {
+	resp, err := c.do(ctx, method, url, jsonObj, nil)
+	if err != nil {
+		return err
+	}
+
+	closeResponse(ctx, url, resp)
 	return nil
 }
+
+func (c GrequestsClient) getOptions() options {
+	ret := getDefaultOptions()
+	if c.Timeout != 0 {
+		ret.timeout = c.Timeout
+	}
+	if c.MaxRetries != nil {
+		ret.maxRetries = *c.MaxRetries
+	}
+	if c.Signer != nil {
+		ret.signer = c.Signer
+	}
+	if c.GzipFromBytes != 0 {
+		ret.gzipFromBytes = c.GzipFromBytes
+	}
+	ret.authToken = c.AuthToken
+	if ret.authToken == "" {
+		ret.authToken = getAuthToken()
+	}
+
+	return ret
+}
+
+// do returns responses with 2xx codes and 304 (only conditional requests can get it)
+func (c GrequestsClient) do(ctx context.Context, method, reqURL string, jsonObj interface{},
+	hdrs map[string]string) (*grequests.Response, error) {
+	opts := c.getOptions()
+	breaker := getBreaker(getHost(reqURL))
+
+	var body []byte
+	if jsonObj != nil {
+		var err error
+		if body, err = json.Marshal(jsonObj); err != nil {
+			return nil, fmt.Errorf("can't marshal json for %s http request %q: %s", method, reqURL, err)
+		}
+	}
+
+	// the compressed body is signed: it's what the API receives
+	var contentEncoding string
+	if opts.gzipFromBytes != 0 && len(body) >= opts.gzipFromBytes {
+		var err error
+		if body, err = gzipBody(body); err != nil {
+			return nil, fmt.Errorf("can't compress body of %s http request %q: %s", method, reqURL, err)
+		}
+		contentEncoding = "gzip"
+	}
+
+	var resp *grequests.Response
+	attempt := func() error {
+		if err := breaker.allow(); err != nil {
+			return backoff.Permanent(fmt.Errorf("unable to make %s http request %q: %s", method, reqURL, err))
+		}
+
+		ro := &grequests.RequestOptions{
+			Context: ctx,
+			HTTPClient: &http.Client{
+				Transport: Transport(),
+				Timeout:   opts.timeout,
+			},
+			Headers: buildHeaders(opts, method, reqURL, body),
+		}
+		for k, v := range hdrs {
+			ro.Headers[k] = v
+		}
+		if contentEncoding != "" {
+			ro.Headers["Content-Encoding"] = contentEncoding
+		}
+		if body != nil {
+			ro.RequestBody = bytes.NewReader(body)
+		}
+
+		r, err := grequests.Req(method, reqURL, ro)
+		if err != nil {
+			err = fmt.Errorf("unable to make %s http request %q: %s", method, reqURL, err)
+			if ctx.Err() != nil { // not a fault of the host
+				breaker.onCancel()
+				return backoff.Permanent(err)
+			}
+
+			breaker.onFailure()
+			return err
+		}
+
+		if r.StatusCode >= http.StatusInternalServerError {
+			breaker.onFailure()
+			closeResponse(ctx, reqURL, r)
+			return StatusError{URL: reqURL, StatusCode: r.StatusCode}
+		}
+
+		breaker.onSuccess()
+		if !r.Ok && r.StatusCode != http.StatusNotModified {
+			closeResponse(ctx, reqURL, r)
+			return backoff.Permanent(StatusError{URL: reqURL, StatusCode: r.StatusCode})
+		}
+
+		resp = r
+		return nil
+	}
+
+	maxRetries := opts.maxRetries
+	if !isIdempotent(method) || maxRetries < 0 {
+		maxRetries = 0 // e.g. a retried POST can create a resource twice
+	}
+
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = opts.initialRetryInterval
+	bo := backoff.WithContext(backoff.WithMaxRetries(b, uint64(maxRetries)), ctx)
+	notify := func(err error, wait time.Duration) {
+		analytics.Log(ctx).Infof("Retrying http request in %s: %s", wait, err)
+	}
+	if err := backoff.RetryNotify(attempt, bo, notify); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+
+	return false
+}
+
+const mergePatchContentType = "application/merge-patch+json"
+
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func closeResponse(ctx context.Context, url string, resp *grequests.Response) {
+	if err := resp.Close(); err != nil {
+		analytics.Log(ctx).Warnf("Can't close %q response: %s", url, err)
+	}
+}
+
+func buildHeaders(opts options, method, reqURL string, body []byte) map[string]string {
+	ret := map[string]string{}
+	if body != nil {
+		ret["Content-Type"] = "application/json"
+	}
+
+	if opts.authToken != "" {
+		ret["Authorization"] = "Bearer " + opts.authToken
+	}
+
+	if signer := opts.signer; signer != nil {
+		path := reqURL
+		if u, err := url.Parse(reqURL); err == nil {
+			path = u.RequestURI()
+		}
+
+		// sign on every attempt: timestamp must be fresh
+		for k, v := range signer.Headers(method, path, body) {
+			ret[k] = v
+		}
+	}
+
+	return ret
+}
+
+func getHost(reqURL string) string {
+	u, err := url.Parse(reqURL)
+	if err != nil {
+		return reqURL
+	}
+
+	return u.Host
+}
@@ -1,10 +1,12 @@
 // Code generated by MockGen. DO NOT EDIT.
 // Source: client.go
 
+// Package httputils is a generated GoMock package.
 package httputils
 
 import (
 	context "context"
+	json "encoding/json"
 	gomock "github.com/golang/mock/gomock"
 	io "io"
 	reflect "reflect"
@@ -29,31 +31,94 @@ func NewMockClient(ctrl *gomock.Controller) *MockClient {
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use
-func (_m *MockClient) EXPECT() *MockClientMockRecorder {
-	return _m.recorder
+func (m *MockClient) EXPECT() *MockClientMockRecorder {
+	return m.recorder
 }
 
 // Get mocks base method
-func (_m *MockClient) Get(ctx context.Context, url string) (io.ReadCloser, error) {
-	ret := _m.ctrl.Call(_m, "Get", ctx, url)
+func (m *MockClient) Get(ctx context.Context, url string) (io.ReadCloser, error) {
+	ret := m.ctrl.Call(m, "Get", ctx, url)
 	ret0, _ := ret[0].(io.ReadCloser)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get
-func (_mr *MockClientMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "Get", reflect.TypeOf((*MockClient)(nil).Get), arg0, arg1)
+func (mr *MockClientMockRecorder) Get(ctx, url interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockClient)(nil).Get), ctx, url)
+}
+
+// GetIfNoneMatch mocks base method
+func (m *MockClient) GetIfNoneMatch(ctx context.Context, url, etag string) (io.ReadCloser, string, error) {
+	ret := m.ctrl.Call(m, "GetIfNoneMatch", ctx, url, etag)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetIfNoneMatch indicates an expected call of GetIfNoneMatch
+func (mr *MockClientMockRecorder) GetIfNoneMatch(ctx, url, etag interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIfNoneMatch", reflect.TypeOf((*MockClient)(nil).GetIfNoneMatch), ctx, url, etag)
 }
 
 // Put mocks base method
-func (_m *MockClient) Put(ctx context.Context, url string, jsonObj interface{}) error {
-	ret := _m.ctrl.Call(_m, "Put", ctx, url, jsonObj)
+func (m *MockClient) Put(ctx context.Context, url string, jsonObj interface{}) error {
+	ret := m.ctrl.Call(m, "Put", ctx, url, jsonObj)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Put indicates an expected call of Put
-func (_mr *MockClientMockRecorder) Put(arg0, arg1, arg2 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "Put", reflect.TypeOf((*MockClient)(nil).Put), arg0, arg1, arg2)
+func (mr *MockClientMockRecorder) Put(ctx, url, jsonObj interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockClient)(nil).Put), ctx, url, jsonObj)
+}
+
+// Post mocks base method
+func (m *MockClient) Post(ctx context.Context, url string, jsonObj interface{}) error {
+	ret := m.ctrl.Call(m, "Post", ctx, url, jsonObj)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Post indicates an expected call of Post
+func (mr *MockClientMockRecorder) Post(ctx, url, jsonObj interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Post", reflect.TypeOf((*MockClient)(nil).Post), ctx, url, jsonObj)
+}
+
+// Patch mocks base method
+func (m *MockClient) Patch(ctx context.Context, url string, patch json.RawMessage) error {
+	ret := m.ctrl.Call(m, "Patch", ctx, url, patch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Patch indicates an expected call of Patch
+func (mr *MockClientMockRecorder) Patch(ctx, url, patch interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockClient)(nil).Patch), ctx, url, patch)
+}
+
+// PostWithResponse mocks base method
+func (m *MockClient) PostWithResponse(ctx context.Context, url string, jsonObj interface{}) (io.ReadCloser, error) {
+	ret := m.ctrl.Call(m, "PostWithResponse", ctx, url, jsonObj)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostWithResponse indicates an expected call of PostWithResponse
+func (mr *MockClientMockRecorder) PostWithResponse(ctx, url, jsonObj interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostWithResponse", reflect.TypeOf((*MockClient)(nil).PostWithResponse), ctx, url, jsonObj)
+}
+
+// Delete mocks base method
+func (m *MockClient) Delete(ctx context.Context, url string) error {
+	ret := m.ctrl.Call(m, "Delete", ctx, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockClientMockRecorder) Delete(ctx, url interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockClient)(nil).Delete), ctx, url)
 }
@@ -0,0 +1,213 @@
+package httputils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/lib/signing"
+	"github.com/stretchr/testify/assert"
+)
+
+var testMaxRetries = 2
+
+var testClient = GrequestsClient{
+	Timeout:    time.Second,
+	MaxRetries: &testMaxRetries,
+}
+
+func newTestServer(codes ...int) (*httptest.Server, *int32) {
+	var calls int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		code := codes[len(codes)-1]
+		if int(n) <= len(codes) {
+			code = codes[n-1]
+		}
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(r.Method))
+	}))
+
+	return s, &calls
+}
+
+func TestRetryOn5xx(t *testing.T) {
+	s, calls := newTestServer(http.StatusBadGateway, http.StatusOK)
+	defer s.Close()
+
+	body, err := testClient.Get(context.Background(), s.URL)
+	assert.NoError(t, err)
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodGet, string(data))
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+}
+
+func TestNoRetryOn4xx(t *testing.T) {
+	s, calls := newTestServer(http.StatusNotFound)
+	defer s.Close()
+
+	err := testClient.Post(context.Background(), s.URL, map[string]string{})
+	assert.Equal(t, StatusError{URL: s.URL, StatusCode: http.StatusNotFound}, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	s, calls := newTestServer(http.StatusInternalServerError)
+	defer s.Close()
+
+	assert.Error(t, testClient.Delete(context.Background(), s.URL))
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
+
+func TestNoRetryOfPost(t *testing.T) {
+	s, calls := newTestServer(http.StatusInternalServerError)
+	defer s.Close()
+
+	assert.Error(t, testClient.Post(context.Background(), s.URL, map[string]string{}))
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestZeroMaxRetries(t *testing.T) {
+	s, calls := newTestServer(http.StatusInternalServerError)
+	defer s.Close()
+
+	noRetries := 0
+	client := GrequestsClient{Timeout: time.Second, MaxRetries: &noRetries}
+	assert.Error(t, client.Delete(context.Background(), s.URL))
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	b := newBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	assert.NoError(t, b.allow())
+	b.onFailure()
+	assert.NoError(t, b.allow())
+	b.onFailure()
+	assert.Equal(t, ErrCircuitOpen, b.allow())
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.allow())               // trial request
+	assert.Equal(t, ErrCircuitOpen, b.allow()) // only one trial
+	b.onFailure()
+	assert.Equal(t, ErrCircuitOpen, b.allow())
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.allow())
+	b.onCancel() // the cancelled trial request releases the slot
+	assert.NoError(t, b.allow())
+	b.onSuccess()
+	assert.NoError(t, b.allow())
+}
+
+func TestSignedRequest(t *testing.T) {
+	signer := signing.NewSigner("key")
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		err := signer.Verify(r.Method, r.URL.RequestURI(), body,
+			r.Header.Get(signing.TimestampHeader), r.Header.Get(signing.SignatureHeader))
+		if err != nil || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer s.Close()
+
+	c := testClient
+	c.Signer = signer
+	assert.NoError(t, c.Put(context.Background(), s.URL+"/v1/state?x=1", map[string]string{"Status": "ok"}))
+
+	c.Signer = signing.NewSigner("other")
+	assert.Error(t, c.Put(context.Background(), s.URL+"/v1/state", map[string]string{"Status": "ok"}))
+}
+
+func TestAuthToken(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer s.Close()
+
+	c := testClient
+	assert.Error(t, c.Delete(context.Background(), s.URL))
+
+	c.AuthToken = "token"
+	assert.NoError(t, c.Delete(context.Background(), s.URL))
+
+	SetAuthToken("token")
+	defer SetAuthToken("")
+	assert.NoError(t, testClient.Delete(context.Background(), s.URL))
+}
+
+func TestGetIfNoneMatch(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v2"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v2"`)
+		_, _ = w.Write([]byte("config"))
+	}))
+	defer s.Close()
+
+	body, etag, err := testClient.GetIfNoneMatch(context.Background(), s.URL, `"v1"`)
+	assert.NoError(t, err)
+	assert.Equal(t, `"v2"`, etag)
+	data, err := ioutil.ReadAll(body)
+	assert.NoError(t, err)
+	assert.NoError(t, body.Close())
+	assert.Equal(t, "config", string(data))
+
+	body, etag, err = testClient.GetIfNoneMatch(context.Background(), s.URL, `"v2"`)
+	assert.NoError(t, err)
+	assert.Nil(t, body, "not modified")
+	assert.Equal(t, `"v2"`, etag)
+}
+
+func TestGzipAndPatch(t *testing.T) {
+	signer := signing.NewSigner("key")
+	var got []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		err := signer.Verify(r.Method, r.URL.RequestURI(), body,
+			r.Header.Get(signing.TimestampHeader), r.Header.Get(signing.SignatureHeader))
+		if err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, zerr := gzip.NewReader(bytes.NewReader(body))
+			if zerr != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body, _ = ioutil.ReadAll(zr)
+		}
+		got = append(got, r.Method+" "+r.Header.Get("Content-Type")+" "+r.Header.Get("Content-Encoding")+" "+string(body))
+	}))
+	defer s.Close()
+
+	c := testClient
+	c.Signer = signer
+	c.GzipFromBytes = 20
+	assert.NoError(t, c.Put(context.Background(), s.URL, map[string]string{"Status": "processing"}))
+	assert.NoError(t, c.Patch(context.Background(), s.URL, json.RawMessage(`{"A":1}`)))
+
+	assert.Equal(t, []string{
+		`PUT application/json gzip {"Status":"processing"}`,
+		`PATCH application/merge-patch+json  {"A":1}`,
+	}, got)
+}
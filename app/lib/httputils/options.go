@@ -0,0 +1,50 @@
+package httputils
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golangci/golangci-shared/pkg/config"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/lib/signing"
+)
+
+type options struct {
+	timeout              time.Duration
+	maxRetries           int
+	initialRetryInterval time.Duration
+
+	breakerThreshold int
+	breakerCooldown  time.Duration
+
+	// signer is nil if requests aren't signed
+	signer *signing.Signer
+
+	// authToken is empty if requests aren't authorized by a token
+	authToken string
+
+	// gzipFromBytes is the min size of request bodies compressed by gzip, bodies aren't compressed if it's zero
+	gzipFromBytes int
+}
+
+var defaultOptions options
+var defaultOptionsOnce sync.Once
+
+func getDefaultOptions() options {
+	defaultOptionsOnce.Do(func() {
+		log := logutil.NewStderrLog("http")
+		cfg := config.NewEnvConfig(log)
+
+		defaultOptions = options{
+			timeout:              cfg.GetDuration("HTTP_TIMEOUT", 30*time.Second),
+			maxRetries:           cfg.GetInt("HTTP_MAX_RETRIES", 3),
+			initialRetryInterval: cfg.GetDuration("HTTP_RETRY_INTERVAL", 500*time.Millisecond),
+			breakerThreshold:     cfg.GetInt("HTTP_BREAKER_THRESHOLD", 5),
+			breakerCooldown:      cfg.GetDuration("HTTP_BREAKER_COOLDOWN", 30*time.Second),
+			signer:               signing.NewSignerFromEnv(),
+			gzipFromBytes:        cfg.GetInt("HTTP_GZIP_FROM_KB", 0) * 1024,
+		}
+	})
+
+	return defaultOptions
+}
@@ -0,0 +1,149 @@
+package httputils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var transport http.RoundTripper
+var transportOnce sync.Once
+
+// CABundlePath returns path to the PEM bundle of CAs trusted in addition to system ones,
+// it's needed behind TLS-intercepting proxies
+func CABundlePath() string {
+	return os.Getenv("CA_BUNDLE_PATH")
+}
+
+// systemCABundles are PEM bundles of system CAs of popular distros, the first existing one is used
+var systemCABundles = []string{
+	"/etc/ssl/certs/ca-certificates.crt",                // Debian, Ubuntu, Alpine
+	"/etc/pki/tls/certs/ca-bundle.crt",                  // Fedora, RHEL
+	"/etc/pki/ca-trust/extracted/pem/tls-ca-bundle.pem", // CentOS
+	"/etc/ssl/ca-bundle.pem",                            // OpenSUSE
+	"/etc/ssl/cert.pem",                                 // macOS
+}
+
+var trustedCABundlePath string
+var trustedCABundleOnce sync.Once
+
+// TrustedCABundlePath returns path to the PEM bundle of system CAs and CAs from CABundlePath for git
+// and curl: unlike Transport they trust only CAs of the given bundle, public hosts must keep working.
+// It's empty if CABundlePath isn't set.
+func TrustedCABundlePath() string {
+	p := CABundlePath()
+	if p == "" {
+		return ""
+	}
+
+	trustedCABundleOnce.Do(func() {
+		var err error
+		trustedCABundlePath, err = writeTrustedCABundle(p, systemCABundles)
+		if err != nil {
+			logrus.Warnf("Can't build CA bundle with system CAs, trust only %s: %s", p, err)
+			trustedCABundlePath = p
+		}
+	})
+
+	return trustedCABundlePath
+}
+
+func writeTrustedCABundle(bundlePath string, systemPaths []string) (string, error) {
+	custom, err := ioutil.ReadFile(bundlePath)
+	if err != nil {
+		return "", err
+	}
+
+	var system []byte
+	for _, sp := range systemPaths {
+		if system, err = ioutil.ReadFile(sp); err == nil {
+			break
+		}
+	}
+	if len(system) == 0 {
+		return "", fmt.Errorf("no system CA bundle in %v", systemPaths)
+	}
+
+	f, err := ioutil.TempFile("", "golangci-ca-bundle")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err = f.Write(append(append(system, '\n'), custom...)); err != nil {
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// Transport is a transport for all outbound requests: it honors HTTPS_PROXY, HTTP_PROXY
+// and NO_PROXY env vars and trusts CAs from CABundlePath. Requests are recorded to or replayed
+// from a cassette if HTTP_CASSETTE_RECORD or HTTP_CASSETTE_REPLAY is set, see UseCassette.
+func Transport() http.RoundTripper {
+	activeCassetteMu.Lock()
+	c := activeCassette
+	activeCassetteMu.Unlock()
+	if c != nil {
+		return c
+	}
+
+	transportOnce.Do(func() {
+		t := &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		}
+
+		if p := CABundlePath(); p != "" {
+			pool, err := loadCertPool(p)
+			if err != nil {
+				logrus.Warnf("Can't load CA bundle, use system CAs only: %s", err)
+			} else {
+				t.TLSClientConfig = &tls.Config{RootCAs: pool}
+			}
+		}
+
+		transport = t
+		if r, err := cassetteFromEnv(t); err != nil {
+			logrus.Errorf("Can't use http cassette, all requests will fail: %s", err)
+			transport = errTransport{err: err}
+		} else if r != nil {
+			transport = r
+		}
+	})
+
+	return transport
+}
+
+func loadCertPool(bundlePath string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	pem, err := ioutil.ReadFile(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM certificates in %s", bundlePath)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,32 @@
+package httputils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteTrustedCABundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ca")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	custom := filepath.Join(dir, "custom.pem")
+	system := filepath.Join(dir, "system.pem")
+	assert.NoError(t, ioutil.WriteFile(custom, []byte("custom CA\n"), 0600))
+	assert.NoError(t, ioutil.WriteFile(system, []byte("system CAs"), 0600))
+
+	p, err := writeTrustedCABundle(custom, []string{filepath.Join(dir, "missing.pem"), system})
+	assert.NoError(t, err)
+	defer os.Remove(p)
+
+	content, err := ioutil.ReadFile(p)
+	assert.NoError(t, err)
+	assert.Equal(t, "system CAs\ncustom CA\n", string(content))
+
+	_, err = writeTrustedCABundle(custom, []string{filepath.Join(dir, "missing.pem")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,307 @@
+package i18n
+
+// MsgID is a key of a message in the catalog, messages are fmt formats.
+// Plural messages have keys with suffixes of plural forms: .one, .few and .other.
+type MsgID string
+
+// Commit status descriptions: GitHub truncates them to 140 characters
+const (
+	StatusReviewing        MsgID = "status.reviewing"
+	StatusNoIssues         MsgID = "status.no_issues"
+	StatusIssuesFound      MsgID = "status.issues_found" // plural
+	StatusIssuesDelta      MsgID = "status.issues_delta" // plural by new issues
+	StatusScoreExceeds     MsgID = "status.score_exceeds"
+	StatusScoreWithin      MsgID = "status.score_within"
+	StatusPreliminary      MsgID = "status.preliminary"
+	StatusStrictWarnings   MsgID = "status.strict_warnings" // plural
+	StatusPRMerged         MsgID = "status.pr_merged"
+	StatusPRClosed         MsgID = "status.pr_closed"
+	StatusNotGoRepo        MsgID = "status.not_go_repo"
+	StatusNoGoFiles        MsgID = "status.no_go_files"
+	StatusDisabled         MsgID = "status.disabled"
+	StatusNoProjectChanges MsgID = "status.no_project_changes"
+)
+
+// Parts of review comments
+const (
+	CommentFromLinter   MsgID = "comment.from_linter"
+	CommentIntroducedIn MsgID = "comment.introduced_in"
+	CommentExplain      MsgID = "comment.explain"
+	CommentSeeAlso      MsgID = "comment.see_also"
+)
+
+// Summary comments of reviews
+const (
+	SummaryNewcomer           MsgID = "summary.newcomer"
+	SummaryNoLintConfig       MsgID = "summary.no_lint_config"
+	SummaryDeprecatedSettings MsgID = "summary.deprecated_settings"
+	SummaryDeprecatedSetting  MsgID = "summary.deprecated_setting"
+	SummaryChangesRequested   MsgID = "summary.changes_requested"
+	SummaryApproved           MsgID = "summary.approved"
+)
+
+// Warnings shown on the analysis page
+const (
+	WarnTimedOutLinters     MsgID = "warn.timed_out_linters"
+	WarnIssuesCapped        MsgID = "warn.issues_capped"
+	WarnFailedLinters       MsgID = "warn.failed_linters"
+	WarnPRMerged            MsgID = "warn.pr_merged"
+	WarnPRClosed            MsgID = "warn.pr_closed"
+	WarnRebuiltPatch        MsgID = "warn.rebuilt_patch"
+	WarnMoreFiles           MsgID = "warn.more_files"
+	WarnReleaseReport       MsgID = "warn.release_report"
+	WarnFetchDeps           MsgID = "warn.fetch_deps"
+	WarnPrepareStep         MsgID = "warn.prepare_step"
+	WarnSandboxed           MsgID = "warn.sandboxed"
+	WarnCommitStatus        MsgID = "warn.commit_status"
+	WarnProjectStatus       MsgID = "warn.project_status"
+	WarnAllPathsSkipped     MsgID = "warn.all_paths_skipped"
+	WarnNoChangesInPath     MsgID = "warn.no_changes_in_path"
+	WarnSkippedByLabel      MsgID = "warn.skipped_by_label"
+	WarnKillSwitch          MsgID = "warn.kill_switch"
+	WarnBudgetHardCap       MsgID = "warn.budget_hard_cap"
+	WarnBudgetSoftCap       MsgID = "warn.budget_soft_cap"
+	WarnGenerateNotVerified MsgID = "warn.generate_not_verified"
+	WarnGenerateFailed      MsgID = "warn.generate_failed"
+	WarnSBOMFailed          MsgID = "warn.sbom_failed"
+	WarnFormatPolicyFailed  MsgID = "warn.format_policy_failed"
+	WarnAPIBaseNotFound     MsgID = "warn.api_base_not_found"
+	WarnAPICompareFailed    MsgID = "warn.api_compare_failed"
+	WarnOutputTruncated     MsgID = "warn.output_truncated"
+)
+
+var catalog = map[Lang]map[MsgID]string{
+	English: {
+		StatusReviewing:                 "GolangCI is reviewing your Pull Request...",
+		StatusNoIssues:                  "No issues found!",
+		StatusIssuesFound + ".one":      "%d issue found",
+		StatusIssuesFound + ".other":    "%d issues found",
+		StatusIssuesDelta + ".one":      "%d new issue, %d pre-existing",
+		StatusIssuesDelta + ".other":    "%d new issues, %d pre-existing",
+		StatusScoreExceeds:              "%s, score %d exceeds %d",
+		StatusScoreWithin:               "%s, score %d is within %d",
+		StatusPreliminary:               "Preliminary: %s by fast linters, full analysis is running...",
+		StatusStrictWarnings + ".one":   "%d warning in strict mode",
+		StatusStrictWarnings + ".other": "%d warnings in strict mode",
+		StatusPRMerged:                  "Pull Request is already merged",
+		StatusPRClosed:                  "Pull Request is already closed",
+		StatusNotGoRepo:                 "Repo isn't written in Go",
+		StatusNoGoFiles:                 "No Go files to analyze",
+		StatusDisabled:                  "Analysis is disabled",
+		StatusNoProjectChanges:          "No changes in the project",
+
+		CommentFromLinter:   " (from `%s`)",
+		CommentIntroducedIn: " (introduced in %s)",
+		CommentExplain:      "Explain this issue",
+		CommentSeeAlso:      "See also %s",
+
+		SummaryNewcomer: "Thank you for your first contribution, @%s! " +
+			"GolangCI found a few things worth a look: they don't block merging, feel free to ask maintainers about them.",
+		SummaryNoLintConfig:       "This repo has no golangci-lint config. Here is a `.golangci.yml` suggested for the project:",
+		SummaryDeprecatedSettings: "`%s` has deprecated settings:",
+		SummaryDeprecatedSetting:  "- `%s` (line %d): %s",
+		SummaryChangesRequested:   "GolangCI found issues that must be fixed before merging.",
+		SummaryApproved:           "GolangCI found no issues blocking merging.",
+
+		WarnTimedOutLinters:     "Analysis is partial: %s timed out, its issues aren't reported",
+		WarnIssuesCapped:        "Analysis is partial: it was stopped at the limit of issues, fix found issues to see others",
+		WarnFailedLinters:       "Analysis is partial: %s failed, its issues aren't reported",
+		WarnPRMerged:            "Pull Request is already merged, skip analysis",
+		WarnPRClosed:            "Pull Request is already closed, skip analysis",
+		WarnRebuiltPatch:        "The diff of the pull request is too large: %d changed files have no diff on GitHub and aren't analyzed: %s",
+		WarnMoreFiles:           " and %d more",
+		WarnReleaseReport:       "Can't post the report into the GitHub Release of the tag",
+		WarnFetchDeps:           "Fetch deps: %s: %s",
+		WarnPrepareStep:         "%s error: %s",
+		WarnSandboxed:           "Dependencies weren't fetched and build steps weren't run: %s",
+		WarnCommitStatus:        "Can't set github commit status",
+		WarnProjectStatus:       "Can't set github commit status of project %s",
+		WarnAllPathsSkipped:     "All changed files match skip paths of the repo config",
+		WarnNoChangesInPath:     "No files were changed in the analysis path %s",
+		WarnSkippedByLabel:      "Analysis was skipped by label %s",
+		WarnKillSwitch:          "Analysis of the repo is disabled by GolangCI operators: %s",
+		WarnBudgetHardCap:       "Organization used %d of %d compute minutes of the plan: only changed packages were analyzed",
+		WarnBudgetSoftCap:       "Organization used %d compute minutes, it's more than %d minutes of the plan",
+		WarnGenerateNotVerified: "Generated files aren't verified: go generate runs only in isolated containers",
+		WarnGenerateFailed:      "Can't verify generated files: go generate failed",
+		WarnSBOMFailed:          "Can't generate SBOM: check the SBOM format in the config",
+		WarnFormatPolicyFailed:  "Can't check the formatting policy: formatters failed",
+		WarnAPIBaseNotFound:     "Can't find the base revision to compare the exported API with",
+		WarnAPICompareFailed:    "Can't compare the exported API with the base revision",
+		WarnOutputTruncated:     "Output of %s is too large: %s of it was truncated",
+	},
+	Russian: {
+		StatusReviewing:                 "GolangCI проверяет ваш Pull Request...",
+		StatusNoIssues:                  "Проблем не найдено!",
+		StatusIssuesFound + ".one":      "%d проблема найдена",
+		StatusIssuesFound + ".few":      "%d проблемы найдены",
+		StatusIssuesFound + ".other":    "%d проблем найдено",
+		StatusIssuesDelta + ".one":      "%d новая проблема, %d уже были",
+		StatusIssuesDelta + ".few":      "%d новые проблемы, %d уже были",
+		StatusIssuesDelta + ".other":    "%d новых проблем, %d уже были",
+		StatusScoreExceeds:              "%s, оценка %d превышает %d",
+		StatusScoreWithin:               "%s, оценка %d не превышает %d",
+		StatusPreliminary:               "Предварительно: %s быстрыми линтерами, идёт полный анализ...",
+		StatusStrictWarnings + ".one":   "%d предупреждение в строгом режиме",
+		StatusStrictWarnings + ".few":   "%d предупреждения в строгом режиме",
+		StatusStrictWarnings + ".other": "%d предупреждений в строгом режиме",
+		StatusPRMerged:                  "Pull Request уже слит",
+		StatusPRClosed:                  "Pull Request уже закрыт",
+		StatusNotGoRepo:                 "Репозиторий написан не на Go",
+		StatusNoGoFiles:                 "Нет Go-файлов для анализа",
+		StatusDisabled:                  "Анализ отключён",
+		StatusNoProjectChanges:          "В проекте нет изменений",
+
+		CommentFromLinter:   " (линтер `%s`)",
+		CommentIntroducedIn: " (добавлено в %s)",
+		CommentExplain:      "Подробнее о проблеме",
+		CommentSeeAlso:      "См. также %s",
+
+		SummaryNewcomer: "Спасибо за ваш первый вклад, @%s! " +
+			"GolangCI нашёл несколько мест, на которые стоит взглянуть: они не блокируют слияние, не стесняйтесь спросить о них мейнтейнеров.",
+		SummaryNoLintConfig:       "В репозитории нет конфига golangci-lint. Вот `.golangci.yml`, предлагаемый для проекта:",
+		SummaryDeprecatedSettings: "В `%s` есть устаревшие настройки:",
+		SummaryDeprecatedSetting:  "- `%s` (строка %d): %s",
+		SummaryChangesRequested:   "GolangCI нашёл проблемы, которые нужно исправить перед слиянием.",
+		SummaryApproved:           "GolangCI не нашёл проблем, блокирующих слияние.",
+
+		WarnTimedOutLinters:     "Анализ неполный: превышено время работы %s, их проблемы не показаны",
+		WarnIssuesCapped:        "Анализ неполный: он остановлен на лимите проблем, исправьте найденные, чтобы увидеть остальные",
+		WarnFailedLinters:       "Анализ неполный: %s завершился с ошибкой, его проблемы не показаны",
+		WarnPRMerged:            "Pull Request уже слит, анализ пропущен",
+		WarnPRClosed:            "Pull Request уже закрыт, анализ пропущен",
+		WarnRebuiltPatch:        "Diff Pull Request слишком большой: %d изменённых файлов не имеют diff на GitHub и не проанализированы: %s",
+		WarnMoreFiles:           " и ещё %d",
+		WarnReleaseReport:       "Не удалось опубликовать отчёт в GitHub Release тега",
+		WarnFetchDeps:           "Загрузка зависимостей: %s: %s",
+		WarnPrepareStep:         "%s: ошибка: %s",
+		WarnSandboxed:           "Зависимости не загружены и шаги сборки не запущены: %s",
+		WarnCommitStatus:        "Не удалось установить статус коммита на GitHub",
+		WarnProjectStatus:       "Не удалось установить статус коммита проекта %s на GitHub",
+		WarnAllPathsSkipped:     "Все изменённые файлы подпадают под пропускаемые пути конфига репозитория",
+		WarnNoChangesInPath:     "В пути анализа %s нет изменённых файлов",
+		WarnSkippedByLabel:      "Анализ пропущен из-за метки %s",
+		WarnKillSwitch:          "Анализ репозитория отключён операторами GolangCI: %s",
+		WarnBudgetHardCap:       "Организация использовала %d из %d минут вычислений тарифа: проанализированы только изменённые пакеты",
+		WarnBudgetSoftCap:       "Организация использовала %d минут вычислений, это больше %d минут тарифа",
+		WarnGenerateNotVerified: "Сгенерированные файлы не проверены: go generate запускается только в изолированных контейнерах",
+		WarnGenerateFailed:      "Не удалось проверить сгенерированные файлы: go generate завершился с ошибкой",
+		WarnSBOMFailed:          "Не удалось сгенерировать SBOM: проверьте формат SBOM в конфиге",
+		WarnFormatPolicyFailed:  "Не удалось проверить политику форматирования: форматтеры завершились с ошибкой",
+		WarnAPIBaseNotFound:     "Не найдена базовая ревизия для сравнения экспортируемого API",
+		WarnAPICompareFailed:    "Не удалось сравнить экспортируемый API с базовой ревизией",
+		WarnOutputTruncated:     "Вывод %s слишком большой: %s из него обрезано",
+	},
+	Chinese: {
+		StatusReviewing:                 "GolangCI 正在审查您的 Pull Request...",
+		StatusNoIssues:                  "未发现问题！",
+		StatusIssuesFound + ".other":    "发现 %d 个问题",
+		StatusIssuesDelta + ".other":    "%d 个新问题，%d 个已存在",
+		StatusScoreExceeds:              "%s，评分 %d 超过 %d",
+		StatusScoreWithin:               "%s，评分 %d 未超过 %d",
+		StatusPreliminary:               "初步结果：快速 linter %s，完整分析进行中...",
+		StatusStrictWarnings + ".other": "严格模式下有 %d 个警告",
+		StatusPRMerged:                  "Pull Request 已合并",
+		StatusPRClosed:                  "Pull Request 已关闭",
+		StatusNotGoRepo:                 "仓库不是用 Go 编写的",
+		StatusNoGoFiles:                 "没有可分析的 Go 文件",
+		StatusDisabled:                  "分析已禁用",
+		StatusNoProjectChanges:          "项目没有变更",
+
+		CommentFromLinter:   "（来自 `%s`）",
+		CommentIntroducedIn: "（引入于 %s）",
+		CommentExplain:      "问题说明",
+		CommentSeeAlso:      "另见 %s",
+
+		SummaryNewcomer: "感谢您的首次贡献，@%s！" +
+			"GolangCI 发现了一些值得关注的地方：它们不会阻止合并，欢迎向维护者咨询。",
+		SummaryNoLintConfig:       "此仓库没有 golangci-lint 配置。以下是为项目建议的 `.golangci.yml`：",
+		SummaryDeprecatedSettings: "`%s` 包含已弃用的设置：",
+		SummaryDeprecatedSetting:  "- `%s`（第 %d 行）：%s",
+		SummaryChangesRequested:   "GolangCI 发现了合并前必须修复的问题。",
+		SummaryApproved:           "GolangCI 未发现阻止合并的问题。",
+
+		WarnTimedOutLinters:     "分析不完整：%s 超时，其问题未报告",
+		WarnIssuesCapped:        "分析不完整：问题数量达到上限后已停止，修复已发现的问题以查看其余问题",
+		WarnFailedLinters:       "分析不完整：%s 运行失败，其问题未报告",
+		WarnPRMerged:            "Pull Request 已合并，跳过分析",
+		WarnPRClosed:            "Pull Request 已关闭，跳过分析",
+		WarnRebuiltPatch:        "Pull Request 的 diff 过大：%d 个变更文件在 GitHub 上没有 diff，未被分析：%s",
+		WarnMoreFiles:           "，另有 %d 个",
+		WarnReleaseReport:       "无法将报告发布到该标签的 GitHub Release",
+		WarnFetchDeps:           "获取依赖：%s：%s",
+		WarnPrepareStep:         "%s 出错：%s",
+		WarnSandboxed:           "未获取依赖，也未运行构建步骤：%s",
+		WarnCommitStatus:        "无法设置 GitHub 提交状态",
+		WarnProjectStatus:       "无法设置项目 %s 的 GitHub 提交状态",
+		WarnAllPathsSkipped:     "所有变更文件都匹配仓库配置中的跳过路径",
+		WarnNoChangesInPath:     "分析路径 %s 中没有文件变更",
+		WarnSkippedByLabel:      "分析因标签 %s 被跳过",
+		WarnKillSwitch:          "GolangCI 运维人员已禁用该仓库的分析：%s",
+		WarnBudgetHardCap:       "组织已使用 %d 分钟计算时间（套餐共 %d 分钟）：仅分析了变更的包",
+		WarnBudgetSoftCap:       "组织已使用 %d 分钟计算时间，超过了套餐的 %d 分钟",
+		WarnGenerateNotVerified: "未验证生成的文件：go generate 仅在隔离容器中运行",
+		WarnGenerateFailed:      "无法验证生成的文件：go generate 运行失败",
+		WarnSBOMFailed:          "无法生成 SBOM：请检查配置中的 SBOM 格式",
+		WarnFormatPolicyFailed:  "无法检查格式化策略：格式化工具运行失败",
+		WarnAPIBaseNotFound:     "找不到用于比较导出 API 的基准版本",
+		WarnAPICompareFailed:    "无法将导出 API 与基准版本进行比较",
+		WarnOutputTruncated:     "%s 的输出过大：已截断 %s",
+	},
+	Japanese: {
+		StatusReviewing:                 "GolangCI が Pull Request をレビューしています...",
+		StatusNoIssues:                  "問題は見つかりませんでした！",
+		StatusIssuesFound + ".other":    "%d 件の問題が見つかりました",
+		StatusIssuesDelta + ".other":    "新しい問題 %d 件、既存 %d 件",
+		StatusScoreExceeds:              "%s、スコア %d が %d を超えています",
+		StatusScoreWithin:               "%s、スコア %d は %d 以内です",
+		StatusPreliminary:               "暫定: 高速 linter で%s、完全な解析を実行中...",
+		StatusStrictWarnings + ".other": "strict モードで %d 件の警告",
+		StatusPRMerged:                  "Pull Request はマージ済みです",
+		StatusPRClosed:                  "Pull Request はクローズ済みです",
+		StatusNotGoRepo:                 "リポジトリは Go で書かれていません",
+		StatusNoGoFiles:                 "解析対象の Go ファイルがありません",
+		StatusDisabled:                  "解析は無効です",
+		StatusNoProjectChanges:          "プロジェクトに変更はありません",
+
+		CommentFromLinter:   "（`%s` による指摘）",
+		CommentIntroducedIn: "（%s で導入）",
+		CommentExplain:      "この問題の説明",
+		CommentSeeAlso:      "関連箇所: %s",
+
+		SummaryNewcomer: "初めてのコントリビューションありがとうございます、@%s さん！" +
+			"GolangCI がいくつか確認すべき点を見つけました。マージはブロックされません。気軽にメンテナーに質問してください。",
+		SummaryNoLintConfig:       "このリポジトリには golangci-lint の設定がありません。プロジェクト向けに提案する `.golangci.yml` です:",
+		SummaryDeprecatedSettings: "`%s` に非推奨の設定があります:",
+		SummaryDeprecatedSetting:  "- `%s`（%d 行目）: %s",
+		SummaryChangesRequested:   "GolangCI がマージ前に修正が必要な問題を見つけました。",
+		SummaryApproved:           "GolangCI はマージをブロックする問題を見つけませんでした。",
+
+		WarnTimedOutLinters:     "解析は不完全です: %s がタイムアウトしたため、その問題は報告されていません",
+		WarnIssuesCapped:        "解析は不完全です: 問題の上限に達したため停止しました。見つかった問題を修正すると残りが表示されます",
+		WarnFailedLinters:       "解析は不完全です: %s が失敗したため、その問題は報告されていません",
+		WarnPRMerged:            "Pull Request はマージ済みのため、解析をスキップしました",
+		WarnPRClosed:            "Pull Request はクローズ済みのため、解析をスキップしました",
+		WarnRebuiltPatch:        "Pull Request の diff が大きすぎます: 変更された %d 個のファイルは GitHub に diff がないため解析されていません: %s",
+		WarnMoreFiles:           " ほか %d 個",
+		WarnReleaseReport:       "タグの GitHub Release にレポートを投稿できません",
+		WarnFetchDeps:           "依存関係の取得: %s: %s",
+		WarnPrepareStep:         "%s のエラー: %s",
+		WarnSandboxed:           "依存関係は取得されず、ビルドステップも実行されませんでした: %s",
+		WarnCommitStatus:        "GitHub のコミットステータスを設定できません",
+		WarnProjectStatus:       "プロジェクト %s の GitHub コミットステータスを設定できません",
+		WarnAllPathsSkipped:     "変更されたすべてのファイルがリポジトリ設定のスキップパスに一致します",
+		WarnNoChangesInPath:     "解析パス %s に変更されたファイルはありません",
+		WarnSkippedByLabel:      "ラベル %s により解析はスキップされました",
+		WarnKillSwitch:          "GolangCI のオペレーターによりリポジトリの解析は無効化されています: %s",
+		WarnBudgetHardCap:       "組織はプランの計算時間のうち %d 分を使用しました（上限 %d 分）: 変更されたパッケージのみ解析しました",
+		WarnBudgetSoftCap:       "組織は計算時間を %d 分使用しました。プランの %d 分を超えています",
+		WarnGenerateNotVerified: "生成ファイルは検証されていません: go generate は隔離されたコンテナでのみ実行されます",
+		WarnGenerateFailed:      "生成ファイルを検証できません: go generate が失敗しました",
+		WarnSBOMFailed:          "SBOM を生成できません: 設定の SBOM 形式を確認してください",
+		WarnFormatPolicyFailed:  "フォーマットポリシーを確認できません: フォーマッターが失敗しました",
+		WarnAPIBaseNotFound:     "エクスポートされた API を比較するベースリビジョンが見つかりません",
+		WarnAPICompareFailed:    "エクスポートされた API をベースリビジョンと比較できません",
+		WarnOutputTruncated:     "%s の出力が大きすぎます: %s が切り詰められました",
+	},
+}
@@ -0,0 +1,105 @@
+// Package i18n is the message catalog of texts the bot posts to GitHub: commit status descriptions,
+// review comments and summaries. Logs and errors aren't localized.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Lang string
+
+const (
+	English  Lang = "en"
+	Russian  Lang = "ru"
+	Chinese  Lang = "zh"
+	Japanese Lang = "ja"
+)
+
+// Langs are supported languages, English is the default one
+var Langs = []Lang{English, Russian, Chinese, Japanese}
+
+// ParseLang returns the supported language by its code, e.g. ru or zh-CN
+func ParseLang(code string) (Lang, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i != -1 {
+		code = code[:i] // regions aren't distinguished
+	}
+
+	for _, l := range Langs {
+		if string(l) == code {
+			return l, true
+		}
+	}
+
+	return English, false
+}
+
+// Printer formats messages of the catalog in the language: messages missing in the language are printed in English.
+// The zero value prints English.
+type Printer struct {
+	lang Lang
+}
+
+// NewPrinter returns the printer of the language code, unsupported languages are printed in English
+func NewPrinter(code string) Printer {
+	lang, _ := ParseLang(code)
+	return Printer{lang: lang}
+}
+
+func (p Printer) Lang() Lang {
+	if p.lang == "" {
+		return English
+	}
+
+	return p.lang
+}
+
+func (p Printer) lookup(id MsgID) string {
+	if msg, ok := catalog[p.Lang()][id]; ok {
+		return msg
+	}
+
+	return catalog[English][id]
+}
+
+// Sprintf formats the message by its format of the catalog
+func (p Printer) Sprintf(id MsgID, args ...interface{}) string {
+	return fmt.Sprintf(p.lookup(id), args...)
+}
+
+// Plural formats the message with the count n by its plural form in the language, n is the first argument
+func (p Printer) Plural(id MsgID, n int, args ...interface{}) string {
+	form := MsgID(fmt.Sprintf("%s.%s", id, pluralForm(p.Lang(), n)))
+	if _, ok := catalog[p.Lang()][form]; !ok {
+		form = MsgID(fmt.Sprintf("%s.%s", id, pluralOther))
+	}
+
+	return p.Sprintf(form, append([]interface{}{n}, args...)...)
+}
+
+const (
+	pluralOne   = "one"
+	pluralFew   = "few"
+	pluralOther = "other"
+)
+
+// pluralForm returns the CLDR plural category of n: Chinese and Japanese have no plural forms,
+// Russian has one, few and many (many is stored as other)
+func pluralForm(lang Lang, n int) string {
+	switch lang {
+	case English:
+		if n == 1 {
+			return pluralOne
+		}
+	case Russian:
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return pluralOne
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return pluralFew
+		}
+	}
+
+	return pluralOther
+}
@@ -0,0 +1,62 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLang(t *testing.T) {
+	l, ok := ParseLang("zh-CN")
+	assert.True(t, ok)
+	assert.Equal(t, Chinese, l)
+
+	l, ok = ParseLang(" RU ")
+	assert.True(t, ok)
+	assert.Equal(t, Russian, l)
+
+	l, ok = ParseLang("de")
+	assert.False(t, ok)
+	assert.Equal(t, English, l)
+}
+
+func TestPlural(t *testing.T) {
+	en := Printer{}
+	assert.Equal(t, "1 issue found", en.Plural(StatusIssuesFound, 1))
+	assert.Equal(t, "0 issues found", en.Plural(StatusIssuesFound, 0))
+
+	ru := NewPrinter("ru")
+	assert.Equal(t, "21 проблема найдена", ru.Plural(StatusIssuesFound, 21))
+	assert.Equal(t, "3 проблемы найдены", ru.Plural(StatusIssuesFound, 3))
+	assert.Equal(t, "12 проблем найдено", ru.Plural(StatusIssuesFound, 12))
+	assert.Equal(t, "5 проблем найдено", ru.Plural(StatusIssuesFound, 5))
+
+	assert.Equal(t, "发现 1 个问题", NewPrinter("zh").Plural(StatusIssuesFound, 1))
+}
+
+func TestCatalogIsComplete(t *testing.T) {
+	for _, lang := range Langs {
+		for id, enMsg := range catalog[English] {
+			msg, ok := catalog[lang][id]
+			if !ok {
+				base := MsgID(string(id[:strings.LastIndex(string(id), ".")]))
+				_, ok = catalog[lang][base+".other"]
+				assert.True(t, ok && strings.HasSuffix(string(id), ".one"), "%s: no message %s", lang, id)
+				continue
+			}
+
+			assert.Equal(t, strings.Count(enMsg, "%"), strings.Count(msg, "%"), "%s: args of message %s", lang, id)
+		}
+	}
+}
+
+func TestStatusesFitGitHubLimit(t *testing.T) {
+	for _, lang := range Langs {
+		for id, msg := range catalog[lang] {
+			if strings.HasPrefix(string(id), "status.") {
+				assert.True(t, len([]rune(msg)) <= 100, "%s: message %s is too long", lang, id)
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package janitor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Container is a docker container of an analysis
+type Container struct {
+	ID        string
+	CreatedAt time.Time
+	SizeBytes int64 // size of the writable layer
+}
+
+type Docker interface {
+	List(ctx context.Context) ([]Container, error)
+	Remove(ctx context.Context, id string) error
+}
+
+// dockerCLI manages containers matching the filter by the docker CLI of the host
+type dockerCLI struct {
+	filter string
+}
+
+const dockerCreatedAtLayout = "2006-01-02 15:04:05 -0700 MST"
+
+func (d dockerCLI) List(ctx context.Context) ([]Container, error) {
+	out, err := exec.CommandContext(ctx, "docker", "ps", "--all", "--size", "--no-trunc", "--filter", d.filter,
+		"--format", "{{.ID}}\t{{.CreatedAt}}\t{{.Size}}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("can't list docker containers: %s", err)
+	}
+
+	return parseDockerPS(string(out))
+}
+
+func (d dockerCLI) Remove(ctx context.Context, id string) error {
+	if out, err := exec.CommandContext(ctx, "docker", "rm", "--force", "--volumes", id).CombinedOutput(); err != nil {
+		return fmt.Errorf("can't remove docker container %s: %s, %s", id, err, out)
+	}
+
+	return nil
+}
+
+func parseDockerPS(out string) ([]Container, error) {
+	var ret []Container
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid docker ps line %q", line)
+		}
+
+		createdAt, err := time.Parse(dockerCreatedAtLayout, fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid creation time of container %s: %s", fields[0], err)
+		}
+
+		ret = append(ret, Container{
+			ID:        fields[0],
+			CreatedAt: createdAt,
+			SizeBytes: parseDockerSize(fields[2]),
+		})
+	}
+
+	return ret, nil
+}
+
+var dockerSizeUnits = []struct {
+	suffix string
+	mult   float64
+}{
+	// longer suffixes first: all of them end with B
+	{"kB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12}, {"B", 1},
+}
+
+// parseDockerSize parses sizes like "12.3MB (virtual 800MB)" of docker ps, unknown sizes are zero
+func parseDockerSize(s string) int64 {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, " "); i != -1 {
+		s = s[:i]
+	}
+
+	for _, u := range dockerSizeUnits {
+		if !strings.HasSuffix(s, u.suffix) {
+			continue
+		}
+
+		v, err := strconv.ParseFloat(strings.TrimSuffix(s, u.suffix), 64)
+		if err != nil {
+			return 0
+		}
+		return int64(v * u.mult)
+	}
+
+	return 0
+}
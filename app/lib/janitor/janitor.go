@@ -0,0 +1,231 @@
+// Package janitor removes artifacts of crashed analyses: local temp workspaces and files of the worker
+// and docker containers of analyses. Analyses clean them up themselves, but not if the worker crashes.
+package janitor
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golangci/golangci-shared/pkg/config"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/metrics"
+)
+
+const (
+	reclaimedBytesGauge = "golangci_worker_janitor_reclaimed_bytes"
+	removedGauge        = "golangci_worker_janitor_removed"
+
+	defaultTTL      = 6 * time.Hour
+	defaultInterval = time.Hour
+)
+
+// tempPrefixes are prefixes of temp dirs and files of the worker: workspaces, patches, deploy keys
+// and hidden dirs of ssh-agents of deploy keys made next to work dirs
+var tempPrefixes = []string{"golangci.", "golangci-", ".golangci-deploykey-"}
+
+type Janitor struct {
+	tmpRoot string
+	ttl     time.Duration
+	docker  Docker // nil if containers aren't cleaned
+	mounts  Mounts
+	log     logutil.Log
+	now     func() time.Time
+}
+
+// New returns the janitor of entries of tmpRoot and containers of docker older than ttl, docker can be nil
+func New(tmpRoot string, ttl time.Duration, docker Docker, log logutil.Log) *Janitor {
+	return &Janitor{
+		tmpRoot: tmpRoot,
+		ttl:     ttl,
+		docker:  docker,
+		mounts:  hostMounts{},
+		log:     log,
+		now:     time.Now,
+	}
+}
+
+// Sweep removes artifacts older than the ttl once
+func (j *Janitor) Sweep(ctx context.Context) {
+	j.sweepTemp(ctx)
+	if j.docker != nil {
+		j.sweepContainers(ctx)
+	}
+}
+
+func isWorkerTemp(name string) bool {
+	for _, p := range tempPrefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// releaseMounts unmounts filesystems under path and closes the LUKS volume of the encrypted work dir:
+// RemoveAll would delete files of mounted work dirs and fail on mount points, volumes would stay open
+func (j *Janitor) releaseMounts(ctx context.Context, path string, isDir bool) error {
+	mounts, err := j.mounts.List()
+	if err != nil {
+		return err
+	}
+
+	var under []string
+	for _, m := range mounts {
+		if m == path || strings.HasPrefix(m, path+string(filepath.Separator)) {
+			under = append(under, m)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(under))) // nested mounts first
+	for _, m := range under {
+		if err = j.mounts.Unmount(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	if !isDir {
+		return nil
+	}
+
+	volume, err := ioutil.ReadFile(filepath.Join(path, executors.EncryptedVolumeFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// code of analyses can write the file: close only volumes of encrypted work dirs
+	name := strings.TrimSpace(string(volume))
+	if !executors.EncryptedVolumeRe.MatchString(name) {
+		j.log.Warnf("Invalid volume name %q in %s, don't close it", name, path)
+		return nil
+	}
+	return j.mounts.CloseVolume(ctx, name)
+}
+
+func (j *Janitor) sweepTemp(ctx context.Context) {
+	entries, err := ioutil.ReadDir(j.tmpRoot)
+	if err != nil {
+		j.log.Warnf("Can't read temp dir %s: %s", j.tmpRoot, err)
+		return
+	}
+
+	deadline := j.now().Add(-j.ttl)
+	removed, reclaimed := 0, int64(0)
+	for _, e := range entries {
+		// mtime of a dir changes only on changes of its direct entries: running analyses
+		// are younger than the ttl anyway, it's much longer than analysis timeouts
+		if !isWorkerTemp(e.Name()) || !e.ModTime().Before(deadline) {
+			continue
+		}
+
+		path := filepath.Join(j.tmpRoot, e.Name())
+		if err = j.releaseMounts(ctx, path, e.IsDir()); err != nil {
+			j.log.Warnf("Can't release mounts of orphaned %s: %s", path, err)
+			continue
+		}
+
+		size := diskUsage(path)
+		if err = os.RemoveAll(path); err != nil {
+			j.log.Warnf("Can't remove orphaned %s: %s", path, err)
+			continue
+		}
+
+		removed++
+		reclaimed += size
+	}
+
+	j.report("temp", removed, reclaimed)
+}
+
+func (j *Janitor) sweepContainers(ctx context.Context) {
+	containers, err := j.docker.List(ctx)
+	if err != nil {
+		j.log.Warnf("Can't list containers: %s", err)
+		return
+	}
+
+	deadline := j.now().Add(-j.ttl)
+	removed, reclaimed := 0, int64(0)
+	for _, c := range containers {
+		if !c.CreatedAt.Before(deadline) {
+			continue
+		}
+
+		if err = j.docker.Remove(ctx, c.ID); err != nil {
+			j.log.Warnf("Can't remove orphaned container: %s", err)
+			continue
+		}
+
+		removed++
+		reclaimed += c.SizeBytes
+	}
+
+	j.report("container", removed, reclaimed)
+}
+
+func (j *Janitor) report(kind string, removed int, reclaimed int64) {
+	if removed == 0 {
+		return
+	}
+
+	j.log.Infof("Removed %d orphaned %s artifacts, reclaimed %d bytes", removed, kind, reclaimed)
+	labels := metrics.Labels{"kind": kind}
+	metrics.AddGauge(removedGauge, labels, float64(removed))
+	metrics.AddGauge(reclaimedBytesGauge, labels, float64(reclaimed))
+}
+
+// diskUsage is a total size of files of the path, unreadable files are skipped
+func diskUsage(path string) int64 {
+	var ret int64
+	_ = filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			ret += info.Size()
+		}
+		return nil
+	})
+
+	return ret
+}
+
+// Run sweeps by the interval until ctx is done
+func (j *Janitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		j.Sweep(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunFromEnv runs the janitor in the background unless JANITOR_DISABLED=1. Containers are cleaned
+// only if JANITOR_DOCKER_FILTER selects containers of analyses on the docker daemon of the host.
+func RunFromEnv(ctx context.Context) {
+	log := logutil.NewStderrLog("janitor")
+	log.SetLevel(logutil.LogLevelInfo)
+	cfg := config.NewEnvConfig(log)
+	if cfg.GetBool("JANITOR_DISABLED", false) {
+		return
+	}
+
+	var docker Docker
+	if filter := cfg.GetString("JANITOR_DOCKER_FILTER"); filter != "" {
+		docker = dockerCLI{filter: filter}
+	}
+
+	j := New(executors.TmpRoot(), cfg.GetDuration("JANITOR_TTL", defaultTTL), docker, log)
+	go j.Run(ctx, cfg.GetDuration("JANITOR_INTERVAL", defaultInterval))
+}
@@ -0,0 +1,140 @@
+package janitor
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDocker struct {
+	containers []Container
+	removed    []string
+}
+
+func (d *fakeDocker) List(context.Context) ([]Container, error) {
+	return d.containers, nil
+}
+
+func (d *fakeDocker) Remove(_ context.Context, id string) error {
+	d.removed = append(d.removed, id)
+	return nil
+}
+
+type fakeMounts struct {
+	mounted []string
+	calls   []string
+}
+
+func (m *fakeMounts) List() ([]string, error) {
+	return m.mounted, nil
+}
+
+func (m *fakeMounts) Unmount(_ context.Context, mnt string) error {
+	m.calls = append(m.calls, "umount "+mnt)
+	return nil
+}
+
+func (m *fakeMounts) CloseVolume(_ context.Context, name string) error {
+	m.calls = append(m.calls, "close "+name)
+	return nil
+}
+
+func TestSweep(t *testing.T) {
+	root, err := ioutil.TempDir("", "janitor")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	now := time.Now()
+	old := now.Add(-7 * time.Hour)
+	mk := func(name string, mtime time.Time) {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(path, 0700))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(path, "main.go"), []byte("package main"), 0600))
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+	mk("golangci.pr123", old)
+	mk("golangci.pr456", now) // a running analysis
+	mk("other-service", old)  // not of the worker
+	mk(".golangci-deploykey-0123", old)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "golangci-deploykey1"), []byte("key"), 0600))
+	require.NoError(t, os.Chtimes(filepath.Join(root, "golangci-deploykey1"), old, old))
+
+	docker := &fakeDocker{containers: []Container{
+		{ID: "old", CreatedAt: old, SizeBytes: 100},
+		{ID: "new", CreatedAt: now},
+	}}
+	j := New(root, 6*time.Hour, docker, logutil.NewStderrLog("test"))
+	j.now = func() time.Time { return now }
+	j.mounts = &fakeMounts{}
+	j.Sweep(context.Background())
+
+	entries, err := ioutil.ReadDir(root)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"golangci.pr456", "other-service"}, names)
+	assert.Equal(t, []string{"old"}, docker.removed)
+}
+
+func TestSweepReleasesEncryptedWorkDirs(t *testing.T) {
+	root, err := ioutil.TempDir("", "janitor")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	old := time.Now().Add(-7 * time.Hour)
+	wd := filepath.Join(root, "golangci.pr123")
+	require.NoError(t, os.MkdirAll(filepath.Join(wd, "encrypted", "work"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(wd, executors.EncryptedVolumeFile),
+		[]byte("golangci-0123456789abcdef"), 0600))
+	require.NoError(t, os.Chtimes(wd, old, old))
+
+	mounts := &fakeMounts{mounted: []string{"/", filepath.Join(wd, "encrypted"), filepath.Join(wd, "encrypted-key"),
+		wd + "-other"}}
+	j := New(root, 6*time.Hour, nil, logutil.NewStderrLog("test"))
+	j.mounts = mounts
+	j.Sweep(context.Background())
+
+	assert.Equal(t, []string{
+		"umount " + filepath.Join(wd, "encrypted-key"),
+		"umount " + filepath.Join(wd, "encrypted"),
+		"close golangci-0123456789abcdef",
+	}, mounts.calls)
+	_, err = os.Stat(wd)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestParseMounts(t *testing.T) {
+	content := "tmpfs /tmp/golangci.pr1/encrypted tmpfs rw,nosuid 0 0\n" +
+		"/dev/mapper/golangci-1 /tmp/golangci.pr\\0402/encrypted ext4 rw 0 0\n"
+	assert.Equal(t, []string{"/tmp/golangci.pr1/encrypted", "/tmp/golangci.pr 2/encrypted"}, parseMounts(content))
+}
+
+func TestParseDockerPS(t *testing.T) {
+	out := "abc\t2018-11-20 10:00:00 +0000 UTC\t12.5MB (virtual 800MB)\n" +
+		"def\t2018-11-20 11:00:00 +0300 MSK\t0B\n"
+	containers, err := parseDockerPS(out)
+	require.NoError(t, err)
+	require.Len(t, containers, 2)
+
+	assert.Equal(t, "abc", containers[0].ID)
+	assert.Equal(t, int64(12500000), containers[0].SizeBytes)
+	assert.True(t, containers[0].CreatedAt.Equal(time.Date(2018, 11, 20, 10, 0, 0, 0, time.UTC)))
+	assert.Equal(t, int64(0), containers[1].SizeBytes)
+
+	containers, err = parseDockerPS("")
+	assert.NoError(t, err)
+	assert.Empty(t, containers)
+
+	_, err = parseDockerPS("abc\tyesterday\t1kB")
+	assert.Error(t, err)
+}
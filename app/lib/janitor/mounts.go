@@ -0,0 +1,83 @@
+package janitor
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Mounts are filesystems of the host: encrypted work dirs of crashed analyses stay mounted
+type Mounts interface {
+	List() ([]string, error)
+	Unmount(ctx context.Context, mnt string) error
+	CloseVolume(ctx context.Context, name string) error
+}
+
+// hostMounts manages mounts by passwordless sudo like encrypted work dirs of executors do
+type hostMounts struct{}
+
+const procMounts = "/proc/self/mounts"
+
+func (hostMounts) List() ([]string, error) {
+	content, err := ioutil.ReadFile(procMounts)
+	if os.IsNotExist(err) {
+		return nil, nil // not linux: encrypted work dirs aren't supported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't read mounts: %s", err)
+	}
+
+	return parseMounts(string(content)), nil
+}
+
+func (hostMounts) Unmount(ctx context.Context, mnt string) error {
+	if out, err := exec.CommandContext(ctx, "sudo", "-n", "umount", "--lazy", mnt).CombinedOutput(); err != nil {
+		return fmt.Errorf("can't unmount %s: %s, %s", mnt, err, out)
+	}
+
+	return nil
+}
+
+func (hostMounts) CloseVolume(ctx context.Context, name string) error {
+	out, err := exec.CommandContext(ctx, "sudo", "-n", "cryptsetup", "close", "--deferred", name).CombinedOutput()
+	if err != nil && !strings.Contains(string(out), "is not active") {
+		return fmt.Errorf("can't close volume %s: %s, %s", name, err, out)
+	}
+
+	return nil
+}
+
+// parseMounts returns mount points of the mounts table, spaces and other chars are octal escapes in it
+func parseMounts(content string) []string {
+	var ret []string
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		ret = append(ret, unescapeMountPoint(fields[1]))
+	}
+
+	return ret
+}
+
+func unescapeMountPoint(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if c, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
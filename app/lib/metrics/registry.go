@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type Labels map[string]string
+
+func (l Labels) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%q", k, l[k]))
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+type Registry struct {
+	mu     sync.Mutex
+	gauges map[string]float64
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		gauges: map[string]float64{},
+	}
+}
+
+func (r *Registry) SetGauge(name string, labels Labels, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.gauges[name+labels.String()] = value
+}
+
+func (r *Registry) AddGauge(name string, labels Labels, delta float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.gauges[name+labels.String()] += delta
+}
+
+// WriteTo writes all gauges in the prometheus text exposition format
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	keys := make([]string, 0, len(r.gauges))
+	for k := range r.gauges {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "%s %g\n", k, r.gauges[k])
+	}
+	r.mu.Unlock()
+
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = r.WriteTo(w)
+}
+
+var defaultRegistry = NewRegistry()
+
+func Default() *Registry {
+	return defaultRegistry
+}
+
+func SetGauge(name string, labels Labels, value float64) {
+	defaultRegistry.SetGauge(name, labels, value)
+}
+
+func AddGauge(name string, labels Labels, delta float64) {
+	defaultRegistry.AddGauge(name, labels, delta)
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelsString(t *testing.T) {
+	assert.Equal(t, "", Labels{}.String())
+	assert.Equal(t, `{a="1",queue="default"}`, Labels{"queue": "default", "a": "1"}.String())
+	assert.Equal(t, `{q="say \"hi\""}`, Labels{"q": `say "hi"`}.String())
+}
+
+func TestRegistryWriteTo(t *testing.T) {
+	r := NewRegistry()
+	r.SetGauge("depth", Labels{"queue": "b"}, 3)
+	r.SetGauge("depth", Labels{"queue": "a"}, 1)
+	r.SetGauge("depth", Labels{"queue": "a"}, 2) // replaces the value
+	r.AddGauge("running", nil, 1)
+	r.AddGauge("running", nil, 0.5)
+
+	var b bytes.Buffer
+	n, err := r.WriteTo(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(b.Len()), n)
+	assert.Equal(t, "depth{queue=\"a\"} 2\ndepth{queue=\"b\"} 3\nrunning 1.5\n", b.String())
+}
+
+func TestRegistryServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	r.SetGauge("age_seconds", nil, 30)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain; version=0.0.4", w.Header().Get("Content-Type"))
+	assert.Equal(t, "age_seconds 30\n", w.Body.String())
+}
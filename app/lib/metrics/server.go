@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RunServerIfConfigured serves metrics on METRICS_ADDR in the background, it's a no-op if it isn't set
+func RunServerIfConfigured() {
+	addr := os.Getenv("METRICS_ADDR")
+	if addr == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", defaultRegistry)
+
+	go func() {
+		logrus.Infof("Serving metrics on %s/metrics", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			logrus.Warnf("Metrics server failed: %s", err)
+		}
+	}()
+}
@@ -0,0 +1,150 @@
+package queue
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/sirupsen/logrus"
+)
+
+// OrgHeader is a task header with the organization (owner) of the analyzed repo
+const OrgHeader = "Org"
+
+func GetOrg(signature *tasks.Signature) string {
+	org, _ := signature.Headers[OrgHeader].(string)
+	return org
+}
+
+// FairCandidate is a task available for consuming, candidates are passed in FIFO order
+type FairCandidate struct {
+	ID  int64
+	Org string
+}
+
+// FairScheduler interleaves tasks of organizations by weighted fair queuing: an organization
+// pushing hundreds of PRs doesn't delay feedback for others. Every organization has a virtual finish
+// time advancing by 1/weight for every consumed task, the task of the organization with the smallest
+// start time is consumed first; tasks of an organization are consumed in FIFO order.
+// Idle organizations don't accumulate credit: their start time is never less than the virtual time.
+// The state is kept in memory of the worker: workers are fair independently.
+type FairScheduler struct {
+	weights map[string]float64
+
+	mu       sync.Mutex
+	vtime    float64            // start time of the last consumed task
+	finishes map[string]float64 // virtual finish times of organizations, only times after vtime are kept
+}
+
+// NewFairScheduler returns the scheduler with weights of organizations, the default weight is 1
+func NewFairScheduler(weights map[string]float64) *FairScheduler {
+	return &FairScheduler{
+		weights:  weights,
+		finishes: map[string]float64{},
+	}
+}
+
+func (s *FairScheduler) weight(org string) float64 {
+	if w, ok := s.weights[org]; ok && w > 0 {
+		return w
+	}
+
+	return 1
+}
+
+func (s *FairScheduler) start(org string) float64 {
+	if f, ok := s.finishes[org]; ok && f > s.vtime {
+		return f
+	}
+
+	return s.vtime
+}
+
+// Order returns candidates in the order they should be tried to be consumed: the first candidate which
+// can be taken (e.g. isn't locked by another worker) must be passed to Consumed
+func (s *FairScheduler) Order(candidates []FairCandidate) []FairCandidate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type orgTasks struct {
+		start float64
+		tasks []FairCandidate
+	}
+	var orgs []*orgTasks
+	byOrg := map[string]*orgTasks{}
+	for _, c := range candidates {
+		ot := byOrg[c.Org]
+		if ot == nil {
+			ot = &orgTasks{start: s.start(c.Org)}
+			byOrg[c.Org] = ot
+			orgs = append(orgs, ot)
+		}
+		ot.tasks = append(ot.tasks, c)
+	}
+
+	// a stable insertion sort: ties are broken by the age of the first task of organizations
+	for i := 1; i < len(orgs); i++ {
+		for j := i; j > 0 && orgs[j].start < orgs[j-1].start; j-- {
+			orgs[j], orgs[j-1] = orgs[j-1], orgs[j]
+		}
+	}
+
+	ret := make([]FairCandidate, 0, len(candidates))
+	for _, ot := range orgs {
+		ret = append(ret, ot.tasks...)
+	}
+	return ret
+}
+
+// Consumed advances the virtual time by the consumed task of the organization
+func (s *FairScheduler) Consumed(org string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := s.start(org)
+	s.finishes[org] = start + 1/s.weight(org)
+	s.vtime = start
+
+	for o, f := range s.finishes {
+		if f <= s.vtime {
+			delete(s.finishes, o) // it's the same as the missing time
+		}
+	}
+}
+
+// ParseOrgWeights parses weights like "golangci=2,bigorg=0.5"
+func ParseOrgWeights(s string) (map[string]float64, error) {
+	ret := map[string]float64{}
+	for _, kv := range strings.Split(s, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid org weight %q: must be org=weight", kv)
+		}
+
+		w, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || w <= 0 {
+			return nil, fmt.Errorf("invalid weight of org %s: %q", parts[0], parts[1])
+		}
+		ret[parts[0]] = w
+	}
+
+	return ret, nil
+}
+
+func getOrgWeights() map[string]float64 {
+	weights, err := ParseOrgWeights(os.Getenv("QUEUE_ORG_WEIGHTS"))
+	if err != nil {
+		logrus.Warnf("Ignore QUEUE_ORG_WEIGHTS: %s", err)
+		return nil
+	}
+
+	return weights
+}
@@ -0,0 +1,63 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/stretchr/testify/assert"
+)
+
+// consumeAll consumes candidates one by one like the broker does and returns orgs in the order of consuming
+func consumeAll(s *FairScheduler, candidates []FairCandidate) []string {
+	var ret []string
+	for len(candidates) != 0 {
+		next := s.Order(candidates)[0]
+		s.Consumed(next.Org)
+		ret = append(ret, next.Org)
+
+		for i, c := range candidates {
+			if c.ID == next.ID {
+				candidates = append(candidates[:i], candidates[i+1:]...)
+				break
+			}
+		}
+	}
+	return ret
+}
+
+func TestFairSchedulerInterleavesOrgs(t *testing.T) {
+	candidates := []FairCandidate{{1, "big"}, {2, "big"}, {3, "big"}, {4, "big"}, {5, "small"}, {6, "other"}}
+	assert.Equal(t, []string{"big", "small", "other", "big", "big", "big"},
+		consumeAll(NewFairScheduler(nil), candidates))
+}
+
+func TestFairSchedulerWeights(t *testing.T) {
+	candidates := []FairCandidate{{1, "a"}, {2, "a"}, {3, "a"}, {4, "a"}, {5, "b"}, {6, "b"}}
+	assert.Equal(t, []string{"a", "b", "a", "a", "b", "a"},
+		consumeAll(NewFairScheduler(map[string]float64{"a": 2}), candidates))
+}
+
+func TestFairSchedulerIdleOrgDoesntAccumulateCredit(t *testing.T) {
+	s := NewFairScheduler(nil)
+	consumeAll(s, []FairCandidate{{1, "a"}, {2, "a"}, {3, "a"}})
+
+	// b was idle while a was served: b goes first but isn't served three times in a row
+	assert.Equal(t, []string{"b", "a", "b", "a"},
+		consumeAll(s, []FairCandidate{{4, "a"}, {5, "a"}, {6, "b"}, {7, "b"}}))
+}
+
+func TestParseOrgWeights(t *testing.T) {
+	w, err := ParseOrgWeights(" golangci=2, bigorg=0.5,")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"golangci": 2, "bigorg": 0.5}, w)
+
+	_, err = ParseOrgWeights("golangci")
+	assert.Error(t, err)
+	_, err = ParseOrgWeights("golangci=0")
+	assert.Error(t, err)
+}
+
+func TestGetOrg(t *testing.T) {
+	assert.Equal(t, "golangci", GetOrg(&tasks.Signature{Headers: tasks.Headers{OrgHeader: "golangci"}}))
+	assert.Equal(t, "", GetOrg(&tasks.Signature{}), "tasks of old producers have no org")
+}
@@ -0,0 +1,85 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/lib/metrics"
+)
+
+const (
+	// maxClockSkew is a max tolerated difference between clocks of the producer and the worker:
+	// a bit negative latency is considered zero, more negative one is considered invalid
+	maxClockSkew = 2 * time.Minute
+
+	// maxLatency is larger than any real time in queue: tasks are retried with 10 minutes timeout
+	maxLatency = 24 * time.Hour
+
+	latencyGauge = "golangci_worker_queue_latency_seconds"
+
+	LatencySourceTask = "task"
+	LatencySourceDB   = "db"
+)
+
+type enqueuedAtKeyType string
+
+const enqueuedAtKey enqueuedAtKeyType = "enqueued at"
+
+func ContextWithEnqueuedAt(ctx context.Context, enqueuedAt time.Time) context.Context {
+	return context.WithValue(ctx, enqueuedAtKey, enqueuedAt)
+}
+
+// EnqueuedAtFromContext returns zero time if the task had no enqueue timestamp
+func EnqueuedAtFromContext(ctx context.Context) time.Time {
+	t, _ := ctx.Value(enqueuedAtKey).(time.Time)
+	return t
+}
+
+// EnqueuedAtFromTaskArg converts the optional trailing task arg with unix time in milliseconds
+func EnqueuedAtFromTaskArg(enqueuedAtMs []int64) time.Time {
+	if len(enqueuedAtMs) == 0 || enqueuedAtMs[0] <= 0 {
+		return time.Time{} // task was sent by an old producer
+	}
+
+	return time.Unix(0, enqueuedAtMs[0]*int64(time.Millisecond))
+}
+
+// TaskArgNow returns the current time for the enqueue timestamp task arg
+func TaskArgNow() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
+
+func guardSkew(d time.Duration) (time.Duration, bool) {
+	if d < -maxClockSkew || d > maxLatency {
+		return 0, false
+	}
+
+	if d < 0 {
+		return 0, true
+	}
+
+	return d, true
+}
+
+// GetLatency computes time in queue by the enqueue timestamp of the task, it falls back
+// to the creation time of the analysis in the DB (zero if unknown). Both are set by other hosts:
+// latencies impossible because of clocks drift are ignored.
+func GetLatency(enqueuedAt, dbCreatedAt, now time.Time) (time.Duration, string, bool) {
+	if !enqueuedAt.IsZero() {
+		if d, ok := guardSkew(now.Sub(enqueuedAt)); ok {
+			return d, LatencySourceTask, true
+		}
+	}
+
+	if !dbCreatedAt.IsZero() {
+		if d, ok := guardSkew(now.Sub(dbCreatedAt)); ok {
+			return d, LatencySourceDB, true
+		}
+	}
+
+	return 0, "", false
+}
+
+func ExportLatency(taskName, source string, d time.Duration) {
+	metrics.SetGauge(latencyGauge, metrics.Labels{"task": taskName, "source": source}, d.Seconds())
+}
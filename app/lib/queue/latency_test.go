@@ -0,0 +1,41 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLatency(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		name        string
+		enqueuedAt  time.Time
+		dbCreatedAt time.Time
+		latency     time.Duration
+		source      string
+		ok          bool
+	}{
+		{"task time", now.Add(-time.Minute), now.Add(-time.Hour), time.Minute, LatencySourceTask, true},
+		{"small skew", now.Add(time.Minute), time.Time{}, 0, LatencySourceTask, true},
+		{"big skew falls back to db", now.Add(time.Hour), now.Add(-5 * time.Second), 5 * time.Second, LatencySourceDB, true},
+		{"old producer", time.Time{}, now.Add(-time.Second), time.Second, LatencySourceDB, true},
+		{"both invalid", now.Add(time.Hour), now.Add(-48 * time.Hour), 0, "", false},
+		{"unknown", time.Time{}, time.Time{}, 0, "", false},
+	}
+
+	for _, tc := range cases {
+		d, source, ok := GetLatency(tc.enqueuedAt, tc.dbCreatedAt, now)
+		assert.Equal(t, tc.latency, d, tc.name)
+		assert.Equal(t, tc.source, source, tc.name)
+		assert.Equal(t, tc.ok, ok, tc.name)
+	}
+}
+
+func TestEnqueuedAtFromTaskArg(t *testing.T) {
+	assert.True(t, EnqueuedAtFromTaskArg(nil).IsZero())
+
+	now := TaskArgNow()
+	assert.Equal(t, now, EnqueuedAtFromTaskArg([]int64{now}).UnixNano()/int64(time.Millisecond))
+}
@@ -0,0 +1,356 @@
+package queue
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1/brokers"
+	"github.com/RichardKnop/machinery/v1/config"
+	"github.com/RichardKnop/machinery/v1/retry"
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// postgresChannel is a LISTEN/NOTIFY channel: a notification with the queue name is sent for every published task
+const postgresChannel = "queue_tasks"
+
+// postgresPollInterval is an interval of polling for delayed tasks (e.g. retries) and lost notifications
+const postgresPollInterval = 5 * time.Second
+
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS queue_tasks (
+	id BIGSERIAL PRIMARY KEY,
+	queue TEXT NOT NULL,
+	name TEXT NOT NULL,
+	eta TIMESTAMPTZ NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	signature JSONB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS queue_tasks_queue_eta_idx ON queue_tasks (queue, eta);
+ALTER TABLE queue_tasks ADD COLUMN IF NOT EXISTS org TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS queue_tasks_queue_org_eta_idx ON queue_tasks (queue, org, eta, id);
+`
+
+func getPostgresURL() string {
+	return os.Getenv("QUEUE_DATABASE_URL")
+}
+
+// PostgresBroker is a machinery broker keeping tasks in a Postgres table: small self-hosted deployments
+// don't have to run Redis. A task is locked by SELECT ... FOR UPDATE SKIP LOCKED in a transaction
+// while it's processed and deleted on commit: tasks of crashed workers are unlocked and consumed again.
+// LISTEN/NOTIFY wakes idle workers up. Tasks of organizations are interleaved by the fair scheduler.
+type PostgresBroker struct {
+	brokers.Broker
+
+	url   string
+	sched *FairScheduler
+
+	dbMu sync.Mutex
+	db   *sql.DB
+
+	stopChan      chan struct{}
+	stopOnce      sync.Once
+	retryStopChan chan int
+	retryFunc     func(chan int)
+	consumingWG   sync.WaitGroup
+}
+
+func NewPostgresBroker(cnf *config.Config, url string) *PostgresBroker {
+	return &PostgresBroker{
+		Broker:        brokers.New(cnf),
+		url:           url,
+		sched:         NewFairScheduler(getOrgWeights()),
+		stopChan:      make(chan struct{}),
+		retryStopChan: make(chan int, 1),
+		retryFunc:     retry.Closure(),
+	}
+}
+
+var _ brokers.Interface = &PostgresBroker{}
+
+// open connects to the database and creates the table on the first successful call
+func (b *PostgresBroker) open() (*sql.DB, error) {
+	b.dbMu.Lock()
+	defer b.dbMu.Unlock()
+
+	if b.db != nil {
+		return b.db, nil
+	}
+
+	db, err := sql.Open("postgres", b.url)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't open postgres")
+	}
+	if _, err = db.Exec(postgresSchema); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "can't create queue table")
+	}
+
+	b.db = db
+	return db, nil
+}
+
+// taskETA returns the time since the task can be consumed: tasks without ETA can be consumed immediately
+func taskETA(signature *tasks.Signature, now time.Time) time.Time {
+	if signature.ETA != nil && signature.ETA.After(now) {
+		return *signature.ETA
+	}
+
+	return now
+}
+
+// Publish inserts the task and notifies listeners in one statement: notifications are sent on commit
+func (b *PostgresBroker) Publish(signature *tasks.Signature) error {
+	brokers.AdjustRoutingKey(b, signature)
+
+	msg, err := json.Marshal(signature)
+	if err != nil {
+		return fmt.Errorf("JSON marshal error: %s", err)
+	}
+
+	db, err := b.open()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`WITH t AS (
+		INSERT INTO queue_tasks (queue, name, eta, signature, org) VALUES ($1, $2, $3, $4, $5) RETURNING queue
+	) SELECT pg_notify($6, queue) FROM t`,
+		signature.RoutingKey, signature.Name, taskETA(signature, time.Now().UTC()), msg, GetOrg(signature), postgresChannel)
+	if err != nil {
+		return errors.Wrapf(err, "can't insert task %s", signature.UUID)
+	}
+
+	return nil
+}
+
+// GetPendingTasks returns a slice of task signatures waiting in the queue
+func (b *PostgresBroker) GetPendingTasks(queue string) ([]*tasks.Signature, error) {
+	if queue == "" {
+		queue = b.GetConfig().DefaultQueue
+	}
+
+	db, err := b.open()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT signature FROM queue_tasks WHERE queue = $1 ORDER BY eta, id LIMIT 10`, queue)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't select tasks of queue %s", queue)
+	}
+	defer rows.Close()
+
+	var ret []*tasks.Signature
+	for rows.Next() {
+		var msg []byte
+		if err = rows.Scan(&msg); err != nil {
+			return nil, err
+		}
+
+		signature := new(tasks.Signature)
+		if err = json.Unmarshal(msg, signature); err != nil {
+			return nil, err
+		}
+		ret = append(ret, signature)
+	}
+
+	return ret, rows.Err()
+}
+
+// StartConsuming runs concurrency consuming loops till StopConsuming: it returns after running tasks are finished
+func (b *PostgresBroker) StartConsuming(consumerTag string, concurrency int, p brokers.TaskProcessor) (bool, error) {
+	select {
+	case <-b.stopChan:
+		return false, nil // stopped while the broker was retrying
+	default:
+	}
+
+	db, err := b.open()
+	if err != nil {
+		b.retryFunc(b.retryStopChan)
+		return true, err
+	}
+
+	listener := pq.NewListener(b.url, time.Second, time.Minute, nil)
+	defer listener.Close()
+	if err = listener.Listen(postgresChannel); err != nil {
+		b.retryFunc(b.retryStopChan)
+		return true, errors.Wrap(err, "can't listen for tasks")
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	logrus.Infof("[*] Waiting for tasks of queue %s in postgres", b.GetConfig().DefaultQueue)
+	b.consumingWG.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer b.consumingWG.Done()
+			b.consume(db, listener.Notify, p)
+		}()
+	}
+	b.consumingWG.Wait()
+
+	return false, nil
+}
+
+// consume processes tasks while there are available ones and waits for notifications otherwise
+func (b *PostgresBroker) consume(db *sql.DB, notify <-chan *pq.Notification, p brokers.TaskProcessor) {
+	for {
+		select {
+		case <-b.stopChan:
+			return
+		default:
+		}
+
+		consumed, err := b.consumeOne(db, p)
+		if err != nil {
+			logrus.Warnf("Can't consume task from postgres: %s", err)
+		}
+		if consumed {
+			continue
+		}
+
+		select {
+		case <-b.stopChan:
+			return
+		case <-notify:
+		case <-time.After(postgresPollInterval):
+		}
+	}
+}
+
+// candidates returns the oldest available task of every organization in the order of the fair scheduler:
+// a backlog of one organization can't hide tasks of others from the scheduler
+func (b *PostgresBroker) candidates(db *sql.DB) ([]FairCandidate, error) {
+	// unregistered tasks are left for other workers; tasks being processed are locked FOR UPDATE:
+	// FOR SHARE skips them, its locks are released right after the statement.
+	// Candidates are passed to the scheduler in FIFO order.
+	rows, err := db.Query(`SELECT id, org FROM (
+			SELECT DISTINCT ON (org) id, org, eta FROM (
+				SELECT id, org, eta FROM queue_tasks
+				WHERE queue = $1 AND eta <= now() AND name = ANY($2)
+				FOR SHARE SKIP LOCKED
+			) available ORDER BY org, eta, id
+		) oldest ORDER BY eta, id`,
+		b.GetConfig().DefaultQueue, pq.Array(b.GetRegisteredTaskNames()))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't select tasks")
+	}
+	defer rows.Close()
+
+	var ret []FairCandidate
+	for rows.Next() {
+		var c FairCandidate
+		if err = rows.Scan(&c.ID, &c.Org); err != nil {
+			return nil, err
+		}
+		ret = append(ret, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return b.sched.Order(ret), nil
+}
+
+// consumeOne locks the next task, processes it and deletes it: it returns false if there are no available tasks
+func (b *PostgresBroker) consumeOne(db *sql.DB, p brokers.TaskProcessor) (bool, error) {
+	candidates, err := b.candidates(db)
+	if err != nil || len(candidates) == 0 {
+		return false, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return false, errors.Wrap(err, "can't begin transaction")
+	}
+	defer tx.Rollback() // nolint:errcheck
+
+	// candidates can be taken by other workers since they were selected
+	var c FairCandidate
+	var msg []byte
+	for _, c = range candidates {
+		err = tx.QueryRow(`SELECT signature FROM queue_tasks WHERE id = $1 FOR UPDATE SKIP LOCKED`, c.ID).Scan(&msg)
+		if err == nil {
+			break
+		}
+		if err != sql.ErrNoRows {
+			return false, errors.Wrapf(err, "can't lock task %d", c.ID)
+		}
+	}
+	if err == sql.ErrNoRows {
+		return true, nil // all candidates were taken by other workers: select again
+	}
+	b.sched.Consumed(c.Org)
+
+	signature := new(tasks.Signature)
+	if err = json.Unmarshal(msg, signature); err != nil {
+		logrus.Error(brokers.NewErrCouldNotUnmarshaTaskSignature(msg, err))
+	} else {
+		logrus.Infof("Received new task %s (%s)", signature.UUID, signature.Name)
+		// failures are retried by the worker with a new task: the task is deleted anyway
+		if err = p.Process(signature); err != nil {
+			logrus.Errorf("Processing of task %s failed: %s", signature.UUID, err)
+		}
+	}
+
+	if _, err = tx.Exec(`DELETE FROM queue_tasks WHERE id = $1`, c.ID); err != nil {
+		return true, errors.Wrapf(err, "can't delete task %d", c.ID)
+	}
+	if err = tx.Commit(); err != nil {
+		return true, errors.Wrapf(err, "can't delete task %d", c.ID)
+	}
+
+	return true, nil
+}
+
+// StopConsuming stops taking new tasks and waits for running ones, it can be called many times
+func (b *PostgresBroker) StopConsuming() {
+	b.stopOnce.Do(func() {
+		close(b.stopChan)
+		select {
+		case b.retryStopChan <- 1:
+		default:
+		}
+	})
+	b.consumingWG.Wait()
+}
+
+// GetStats returns depth of the queue and age of its oldest task, delayed tasks are counted separately
+func (b *PostgresBroker) GetStats(queueName string) (*Stats, error) {
+	db, err := b.open()
+	if err != nil {
+		return nil, err
+	}
+
+	var depth, delayed int
+	var oldest pq.NullTime
+	err = db.QueryRow(`
+		SELECT count(*) FILTER (WHERE eta <= now()), min(created_at) FILTER (WHERE eta <= now()),
+			count(*) FILTER (WHERE eta > now())
+		FROM queue_tasks WHERE queue = $1`, queueName).
+		Scan(&depth, &oldest, &delayed)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get stats of queue %s", queueName)
+	}
+
+	ret := &Stats{
+		Queue:   queueName,
+		Depth:   depth,
+		Delayed: delayed,
+	}
+	if oldest.Valid {
+		ret.OldestAge = time.Since(oldest.Time)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,30 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1/config"
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskETA(t *testing.T) {
+	now := time.Now().UTC()
+	later := now.Add(time.Minute)
+	earlier := now.Add(-time.Minute)
+
+	assert.Equal(t, now, taskETA(&tasks.Signature{}, now))
+	assert.Equal(t, later, taskETA(&tasks.Signature{ETA: &later}, now))
+	assert.Equal(t, now, taskETA(&tasks.Signature{ETA: &earlier}, now), "overdue tasks are consumed immediately")
+}
+
+func TestPostgresBrokerStoppedBeforeStart(t *testing.T) {
+	b := NewPostgresBroker(&config.Config{DefaultQueue: DefaultQueueName}, "postgres://invalid")
+	b.StopConsuming()
+	b.StopConsuming()
+
+	retry, err := b.StartConsuming("worker", 1, nil)
+	assert.False(t, retry)
+	assert.NoError(t, err)
+}
@@ -12,16 +12,30 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const DefaultQueueName = "machinery_tasks"
+
 var server *machinery.Server
+var postgresBroker *PostgresBroker
+var redisBroker *FairRedisBroker
 var initOnce sync.Once
+var queueName = DefaultQueueName
+
+func getRedisURL() string {
+	return fmt.Sprintf("%s/1", os.Getenv("REDIS_URL")) // use separate DB #1 for queue
+}
 
 func initServer() {
-	redisURL := fmt.Sprintf("%s/1", os.Getenv("REDIS_URL")) // use separate DB #1 for queue
-	logrus.Infof("REDIS_URL=%q", redisURL)
+	if pgURL := getPostgresURL(); pgURL != "" {
+		initPostgresServer(pgURL)
+		return
+	}
+
+	redisURL := getRedisURL()
+	logrus.Infof("REDIS_URL=%q, tasks of organizations are interleaved by the fair scheduler", redisURL)
 
 	cnf := &config.Config{
 		Broker:          redisURL,
-		DefaultQueue:    "machinery_tasks",
+		DefaultQueue:    queueName,
 		ResultBackend:   redisURL,
 		ResultsExpireIn: int((7 * 24 * time.Hour).Seconds()), // store results for 1 week
 	}
@@ -31,6 +45,37 @@ func initServer() {
 	if err != nil {
 		log.Fatalf("Can't init machinery queue server: %s", err)
 	}
+
+	// machinery's broker publishes tasks and keeps delayed ones, the fair broker only consumes them
+	redisBroker = NewFairRedisBroker(server.GetBroker(), redisURL, getPrefetchWindow())
+	server.SetBroker(redisBroker)
+}
+
+// initPostgresServer makes a server with the postgres broker: machinery can't make custom brokers,
+// eager broker and backend are only placeholders for the server constructor
+func initPostgresServer(pgURL string) {
+	cnf := &config.Config{
+		Broker:        "eager",
+		DefaultQueue:  queueName,
+		ResultBackend: "eager",
+	}
+
+	var err error
+	server, err = machinery.NewServer(cnf)
+	if err != nil {
+		log.Fatalf("Can't init machinery queue server: %s", err)
+	}
+
+	logrus.Infof("Using postgres queue, tasks of organizations are interleaved by the fair scheduler")
+	cnf.Broker = "postgres"
+	postgresBroker = NewPostgresBroker(cnf, pgURL)
+	server.SetBroker(postgresBroker)
+	server.SetBackend(nopBackend{}) // results of tasks aren't read
+}
+
+// SetQueueName overrides the queue to consume, it must be called before Init
+func SetQueueName(name string) {
+	queueName = name
 }
 
 func Init() {
@@ -40,3 +85,9 @@ func Init() {
 func GetServer() *machinery.Server {
 	return server
 }
+
+// RedeliversInterrupted returns true if tasks interrupted by a crash of the worker are consumed again:
+// the postgres broker deletes tasks only after processing, redis brokers lose them
+func RedeliversInterrupted() bool {
+	return postgresBroker != nil
+}
@@ -0,0 +1,329 @@
+package queue
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1/brokers"
+	"github.com/RichardKnop/machinery/v1/retry"
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/garyburd/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// redisDelayedTasksKey is the sorted set of machinery's redis broker with tasks delayed by ETA (e.g. retries)
+const redisDelayedTasksKey = "delayed_tasks"
+
+// redisPollTimeout is a timeout of waiting for new tasks in seconds: stops are checked between waits
+const redisPollTimeout = 1
+
+const defaultPrefetchWindow = 20
+
+// bufferedTask is a prefetched task with the message it was published by
+type bufferedTask struct {
+	signature *tasks.Signature
+	msg       []byte
+}
+
+// fairBuffer keeps prefetched tasks and returns them in the order of the fair scheduler
+type fairBuffer struct {
+	sched  *FairScheduler
+	nextID int64
+	tasks  map[int64]bufferedTask
+	order  []FairCandidate // in FIFO order
+}
+
+func newFairBuffer(sched *FairScheduler) *fairBuffer {
+	return &fairBuffer{
+		sched: sched,
+		tasks: map[int64]bufferedTask{},
+	}
+}
+
+func (fb *fairBuffer) len() int {
+	return len(fb.order)
+}
+
+func (fb *fairBuffer) push(t bufferedTask) {
+	fb.nextID++
+	fb.tasks[fb.nextID] = t
+	fb.order = append(fb.order, FairCandidate{ID: fb.nextID, Org: GetOrg(t.signature)})
+}
+
+// pop returns the next task by the fair scheduler, it's false if the buffer is empty
+func (fb *fairBuffer) pop() (bufferedTask, bool) {
+	if len(fb.order) == 0 {
+		return bufferedTask{}, false
+	}
+
+	next := fb.sched.Order(fb.order)[0]
+	fb.sched.Consumed(next.Org)
+	for i, c := range fb.order {
+		if c.ID == next.ID {
+			fb.order = append(fb.order[:i], fb.order[i+1:]...)
+			break
+		}
+	}
+
+	t := fb.tasks[next.ID]
+	delete(fb.tasks, next.ID)
+	return t, true
+}
+
+// drain removes all tasks in FIFO order
+func (fb *fairBuffer) drain() []bufferedTask {
+	ret := make([]bufferedTask, 0, len(fb.order))
+	for _, c := range fb.order {
+		ret = append(ret, fb.tasks[c.ID])
+		delete(fb.tasks, c.ID)
+	}
+	fb.order = nil
+	return ret
+}
+
+// FairRedisBroker consumes the redis queue of machinery in the order of the fair scheduler: it prefetches
+// a window of ready tasks from the head of the queue and consumes tasks of organizations interleaved.
+// Publishing is left to machinery's broker, so producers aren't changed. Prefetched tasks are hidden
+// from other workers and are lost on crashes like running ones: the window must be small. Tasks left
+// in the window on stop are pushed back to the head of the queue.
+type FairRedisBroker struct {
+	brokers.Interface // machinery's redis broker
+
+	pool   *redis.Pool
+	window int
+
+	mu  sync.Mutex
+	buf *fairBuffer
+
+	stopChan      chan struct{}
+	stopOnce      sync.Once
+	retryStopChan chan int
+	retryFunc     func(chan int)
+	consumingWG   sync.WaitGroup
+}
+
+var _ brokers.Interface = &FairRedisBroker{}
+
+func NewFairRedisBroker(b brokers.Interface, redisURL string, window int) *FairRedisBroker {
+	return &FairRedisBroker{
+		Interface: b,
+		pool: &redis.Pool{
+			MaxIdle:     3,
+			IdleTimeout: 240 * time.Second,
+			Dial: func() (redis.Conn, error) {
+				return redis.DialURL(redisURL)
+			},
+		},
+		window:        window,
+		buf:           newFairBuffer(NewFairScheduler(getOrgWeights())),
+		stopChan:      make(chan struct{}),
+		retryStopChan: make(chan int, 1),
+		retryFunc:     retry.Closure(),
+	}
+}
+
+func (b *FairRedisBroker) queue() string {
+	return b.GetConfig().DefaultQueue
+}
+
+// StartConsuming runs concurrency consuming loops till StopConsuming: it returns after running tasks are finished
+func (b *FairRedisBroker) StartConsuming(consumerTag string, concurrency int, p brokers.TaskProcessor) (bool, error) {
+	select {
+	case <-b.stopChan:
+		return false, nil
+	default:
+	}
+
+	conn := b.pool.Get()
+	_, err := conn.Do("PING")
+	conn.Close()
+	if err != nil {
+		b.retryFunc(b.retryStopChan)
+		return true, errors.Wrap(err, "can't connect to redis")
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	logrus.Infof("[*] Waiting for tasks of queue %s in redis, prefetch window is %d", b.queue(), b.window)
+	b.consumingWG.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer b.consumingWG.Done()
+			b.consume(p)
+		}()
+	}
+	b.consumingWG.Wait()
+
+	return false, nil
+}
+
+func (b *FairRedisBroker) consume(p brokers.TaskProcessor) {
+	for {
+		select {
+		case <-b.stopChan:
+			return
+		default:
+		}
+
+		t, err := b.next()
+		if err != nil {
+			logrus.Warnf("Can't consume task from redis: %s", err)
+		}
+		if t == nil {
+			if err != nil {
+				select {
+				case <-b.stopChan:
+					return
+				case <-time.After(time.Second):
+				}
+			}
+			continue
+		}
+
+		logrus.Infof("Received new task %s (%s)", t.UUID, t.Name)
+		if err = p.Process(t); err != nil {
+			logrus.Errorf("Processing of task %s failed: %s", t.UUID, err)
+		}
+	}
+}
+
+// next fills the window and returns its next task, it's nil if there are no ready tasks
+func (b *FairRedisBroker) next() (*tasks.Signature, error) {
+	conn := b.pool.Get()
+	defer conn.Close()
+
+	b.mu.Lock()
+	err := b.fill(conn)
+	t, ok := b.buf.pop()
+	b.mu.Unlock()
+	if ok {
+		return t.signature, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the window is empty: wait for a new task
+	reply, err := redis.ByteSlices(conn.Do("BLPOP", b.queue(), redisPollTimeout))
+	if err == redis.ErrNil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't pop task of queue %s", b.queue())
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.accept(conn, reply[1])
+	if t, ok = b.buf.pop(); ok {
+		return t.signature, nil
+	}
+	return nil, nil
+}
+
+// fill prefetches due delayed tasks and ready tasks while the window isn't full: retries are interleaved too
+func (b *FairRedisBroker) fill(conn redis.Conn) error {
+	// attempts are limited: tasks returned to the queue by accept can be popped again
+	for i := 0; i < b.window && b.buf.len() < b.window; i++ {
+		now := time.Now().UTC().UnixNano()
+		msgs, err := redis.ByteSlices(conn.Do("ZRANGEBYSCORE", redisDelayedTasksKey, 0, now, "LIMIT", 0, 1))
+		if err != nil {
+			return errors.Wrap(err, "can't get delayed tasks")
+		}
+		if len(msgs) == 0 {
+			break
+		}
+
+		removed, err := redis.Int(conn.Do("ZREM", redisDelayedTasksKey, msgs[0]))
+		if err != nil {
+			return errors.Wrap(err, "can't take delayed task")
+		}
+		if removed == 1 { // otherwise it was taken by another worker
+			b.accept(conn, msgs[0])
+		}
+	}
+
+	for i := 0; i < b.window && b.buf.len() < b.window; i++ {
+		msg, err := redis.Bytes(conn.Do("LPOP", b.queue()))
+		if err == redis.ErrNil {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "can't pop task of queue %s", b.queue())
+		}
+
+		b.accept(conn, msg)
+	}
+
+	return nil
+}
+
+// accept puts the task into the window: tasks of other queues and unregistered tasks are returned
+// to the tail of their queues for other workers
+func (b *FairRedisBroker) accept(conn redis.Conn, msg []byte) {
+	signature := new(tasks.Signature)
+	if err := json.Unmarshal(msg, signature); err != nil {
+		logrus.Error(brokers.NewErrCouldNotUnmarshaTaskSignature(msg, err))
+		return
+	}
+
+	queue := signature.RoutingKey
+	if queue == "" {
+		queue = b.queue()
+	}
+	if queue != b.queue() || !b.IsTaskRegistered(signature.Name) {
+		if _, err := conn.Do("RPUSH", queue, msg); err != nil {
+			logrus.Errorf("Can't return task %s to queue %s: %s", signature.UUID, queue, err)
+		}
+		return
+	}
+
+	b.buf.push(bufferedTask{signature: signature, msg: msg})
+}
+
+// requeueBuffered pushes tasks of the window back to the head of the queue in the same order
+func (b *FairRedisBroker) requeueBuffered() {
+	b.mu.Lock()
+	buffered := b.buf.drain()
+	b.mu.Unlock()
+	if len(buffered) == 0 {
+		return
+	}
+
+	conn := b.pool.Get()
+	defer conn.Close()
+	for i := len(buffered) - 1; i >= 0; i-- {
+		if _, err := conn.Do("LPUSH", b.queue(), buffered[i].msg); err != nil {
+			logrus.Errorf("Can't return prefetched task %s to queue %s: %s", buffered[i].signature.UUID, b.queue(), err)
+		}
+	}
+}
+
+// StopConsuming stops taking new tasks, waits for running ones and returns prefetched ones to the queue,
+// it can be called many times
+func (b *FairRedisBroker) StopConsuming() {
+	b.stopOnce.Do(func() {
+		close(b.stopChan)
+		select {
+		case b.retryStopChan <- 1:
+		default:
+		}
+	})
+	b.consumingWG.Wait()
+	b.requeueBuffered()
+}
+
+// getPrefetchWindow returns QUEUE_PREFETCH_WINDOW: the number of ready tasks the fair scheduler chooses from
+func getPrefetchWindow() int {
+	if n, err := strconv.Atoi(os.Getenv("QUEUE_PREFETCH_WINDOW")); err == nil && n > 0 {
+		return n
+	}
+
+	return defaultPrefetchWindow
+}
@@ -0,0 +1,52 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/stretchr/testify/assert"
+)
+
+func testOrgTask(uuid, org string) bufferedTask {
+	return bufferedTask{
+		signature: &tasks.Signature{UUID: uuid, Headers: tasks.Headers{OrgHeader: org}},
+		msg:       []byte(uuid),
+	}
+}
+
+func TestFairBufferInterleavesOrgs(t *testing.T) {
+	fb := newFairBuffer(NewFairScheduler(nil))
+	for _, task := range []bufferedTask{testOrgTask("1", "big"), testOrgTask("2", "big"), testOrgTask("3", "big"),
+		testOrgTask("4", "small"), testOrgTask("5", "other")} {
+		fb.push(task)
+	}
+
+	var consumed []string
+	for {
+		task, ok := fb.pop()
+		if !ok {
+			break
+		}
+		consumed = append(consumed, task.signature.UUID)
+	}
+	assert.Equal(t, []string{"1", "4", "5", "2", "3"}, consumed)
+	assert.Equal(t, 0, fb.len())
+}
+
+func TestFairBufferDrainKeepsFIFOOrder(t *testing.T) {
+	fb := newFairBuffer(NewFairScheduler(nil))
+	fb.push(testOrgTask("1", "big"))
+	fb.push(testOrgTask("2", "big"))
+	fb.push(testOrgTask("3", "small"))
+
+	task, _ := fb.pop()
+	assert.Equal(t, "1", task.signature.UUID)
+
+	drained := fb.drain()
+	if assert.Len(t, drained, 2) {
+		assert.Equal(t, []byte("2"), drained[0].msg)
+		assert.Equal(t, []byte("3"), drained[1].msg)
+	}
+	_, ok := fb.pop()
+	assert.False(t, ok)
+}
@@ -0,0 +1,122 @@
+package queue
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/garyburd/redigo/redis"
+	"github.com/pkg/errors"
+)
+
+// EnqueuedAtHeader is a task header with the unix time (in seconds) of enqueuing
+const EnqueuedAtHeader = "EnqueuedAt"
+
+type Stats struct {
+	Queue string
+
+	// Depth is the count of tasks which can be consumed now
+	Depth int
+
+	// OldestAge is zero if the queue is empty or the oldest task has no enqueue time
+	OldestAge time.Duration
+
+	// Delayed is the count of tasks waiting for their ETA (e.g. retries): they aren't counted in Depth
+	// and OldestAge, a big count of them means many retries
+	Delayed int
+}
+
+func GetStats(queueName string) (*Stats, error) {
+	if postgresBroker != nil {
+		return postgresBroker.GetStats(queueName)
+	}
+
+	conn, err := redis.DialURL(getRedisURL())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to redis")
+	}
+	defer conn.Close()
+
+	return getRedisStats(conn, queueName)
+}
+
+func getRedisStats(conn redis.Conn, queueName string) (*Stats, error) {
+	delayed, err := countRedisDelayedTasks(conn, queueName)
+	if err != nil {
+		return nil, err
+	}
+
+	depth, err := redis.Int(conn.Do("LLEN", queueName))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get length of queue %s", queueName)
+	}
+
+	ret := &Stats{
+		Queue:   queueName,
+		Depth:   depth,
+		Delayed: delayed,
+	}
+	if depth == 0 {
+		return ret, nil
+	}
+
+	// machinery pushes to the tail and pops from the head: the oldest task is the first one
+	oldest, err := redis.Bytes(conn.Do("LINDEX", queueName, 0))
+	if err != nil {
+		if err == redis.ErrNil { // was consumed after LLEN
+			return ret, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get the oldest task of queue %s", queueName)
+	}
+
+	var signature tasks.Signature
+	if err = json.Unmarshal(oldest, &signature); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal task signature")
+	}
+
+	if enqueuedAt := GetEnqueuedAt(&signature); !enqueuedAt.IsZero() {
+		ret.OldestAge = time.Since(enqueuedAt)
+	}
+
+	return ret, nil
+}
+
+// countRedisDelayedTasks counts tasks of the queue in the sorted set of delayed tasks: the set is shared
+// by all queues, tasks due but not moved to the queue yet are counted too
+func countRedisDelayedTasks(conn redis.Conn, queueName string) (int, error) {
+	msgs, err := redis.ByteSlices(conn.Do("ZRANGE", redisDelayedTasksKey, 0, -1))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get delayed tasks")
+	}
+
+	n := 0
+	for _, msg := range msgs {
+		var signature tasks.Signature
+		if err := json.Unmarshal(msg, &signature); err != nil {
+			continue // it's consumed with an error too
+		}
+		if signature.RoutingKey == queueName {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// GetEnqueuedAt returns the time from EnqueuedAtHeader, it's zero for tasks of old producers
+func GetEnqueuedAt(signature *tasks.Signature) time.Time {
+	v, ok := signature.Headers[EnqueuedAtHeader]
+	if !ok {
+		return time.Time{}
+	}
+
+	// headers are json-decoded: numbers become float64
+	switch t := v.(type) {
+	case float64:
+		return time.Unix(int64(t), 0)
+	case int64:
+		return time.Unix(t, 0)
+	default:
+		return time.Time{}
+	}
+}
@@ -0,0 +1,103 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/garyburd/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRedisConn keeps lists and sorted sets of machinery's redis broker: only commands of stats are supported
+type fakeRedisConn struct {
+	redis.Conn
+	lists map[string][][]byte
+}
+
+func (c fakeRedisConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	key := args[0].(string)
+	switch cmd {
+	case "LLEN":
+		return int64(len(c.lists[key])), nil
+	case "LINDEX":
+		i := args[1].(int)
+		if i >= len(c.lists[key]) {
+			return nil, nil
+		}
+		return c.lists[key][i], nil
+	case "ZRANGE":
+		var ret []interface{}
+		for _, msg := range c.lists[key] {
+			ret = append(ret, msg)
+		}
+		return ret, nil
+	default:
+		return nil, fmt.Errorf("unsupported command %s", cmd)
+	}
+}
+
+func testSignatureMsg(t *testing.T, queueName string, enqueuedAt time.Time) []byte {
+	signature := tasks.Signature{RoutingKey: queueName, Headers: tasks.Headers{}}
+	if !enqueuedAt.IsZero() {
+		signature.Headers[EnqueuedAtHeader] = enqueuedAt.Unix()
+	}
+
+	msg, err := json.Marshal(signature)
+	assert.NoError(t, err)
+	return msg
+}
+
+func TestGetRedisStats(t *testing.T) {
+	enqueuedAt := time.Now().Add(-time.Minute)
+	conn := fakeRedisConn{lists: map[string][][]byte{
+		"q": {testSignatureMsg(t, "q", enqueuedAt), testSignatureMsg(t, "q", time.Now())},
+		redisDelayedTasksKey: {
+			testSignatureMsg(t, "q", time.Now()),
+			testSignatureMsg(t, "other", time.Now()),
+			[]byte("invalid"),
+		},
+	}}
+
+	stats, err := getRedisStats(conn, "q")
+	assert.NoError(t, err)
+	assert.Equal(t, "q", stats.Queue)
+	assert.Equal(t, 2, stats.Depth)
+	assert.Equal(t, 1, stats.Delayed)
+	assert.InDelta(t, time.Minute.Seconds(), stats.OldestAge.Seconds(), 2)
+}
+
+func TestGetRedisStatsOfEmptyQueue(t *testing.T) {
+	conn := fakeRedisConn{lists: map[string][][]byte{
+		redisDelayedTasksKey: {testSignatureMsg(t, "q", time.Time{})},
+	}}
+
+	stats, err := getRedisStats(conn, "q")
+	assert.NoError(t, err)
+	assert.Equal(t, &Stats{Queue: "q", Delayed: 1}, stats)
+}
+
+func TestGetRedisStatsWithoutEnqueueTime(t *testing.T) {
+	conn := fakeRedisConn{lists: map[string][][]byte{
+		"q": {testSignatureMsg(t, "q", time.Time{})},
+	}}
+
+	stats, err := getRedisStats(conn, "q")
+	assert.NoError(t, err)
+	assert.Equal(t, &Stats{Queue: "q", Depth: 1}, stats)
+}
+
+func TestGetEnqueuedAt(t *testing.T) {
+	at := time.Unix(1542700000, 0)
+	assert.Equal(t, at, GetEnqueuedAt(&tasks.Signature{Headers: tasks.Headers{EnqueuedAtHeader: float64(at.Unix())}}))
+	assert.Equal(t, at, GetEnqueuedAt(&tasks.Signature{Headers: tasks.Headers{EnqueuedAtHeader: at.Unix()}}))
+	assert.True(t, GetEnqueuedAt(&tasks.Signature{}).IsZero())
+	assert.True(t, GetEnqueuedAt(&tasks.Signature{Headers: tasks.Headers{EnqueuedAtHeader: "1542700000"}}).IsZero())
+
+	// headers are json-decoded by brokers
+	var signature tasks.Signature
+	assert.NoError(t, json.Unmarshal(testSignatureMsg(t, "q", at), &signature))
+	assert.Equal(t, at, GetEnqueuedAt(&signature))
+}
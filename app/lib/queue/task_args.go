@@ -0,0 +1,177 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/golangci/golangci-worker/app/lib/usage"
+)
+
+// OptionalTaskArgs are trailing args of tasks: old producers don't send them or send only a part of them.
+// The order of args is fixed: enqueue time, feature overrides, usage plan, deploy key, analysis path, work dir encryption.
+type OptionalTaskArgs struct {
+	EnqueuedAt time.Time
+
+	// Features override experiments for the task: name -> is active
+	Features map[string]bool
+
+	// Plan is nil if the organization isn't limited
+	Plan *usage.Plan
+
+	// DeployKey is a sealed SSH key to clone the private repo by (see deploykeys.Open), it's empty if
+	// the repo is cloned by the token
+	DeployKey string
+
+	// Path is a subdirectory of the repo the analysis is restricted to, it's empty for the whole repo
+	Path string
+
+	// WorkDirEncryption is a mode of the encrypted work dir of the analysis set for the organization
+	// (see executors.WorkDirEncryption), the work dir isn't encrypted if it's empty
+	WorkDirEncryption string
+}
+
+// BuildOptionalTaskArgs returns trailing args of a task enqueued now
+func BuildOptionalTaskArgs(features map[string]bool, plan *usage.Plan, deployKey, path, workDirEncryption string) []tasks.Arg {
+	ret := []tasks.Arg{
+		{
+			Type:  "int64",
+			Value: TaskArgNow(),
+		},
+	}
+	if len(features) == 0 && plan == nil && deployKey == "" && path == "" && workDirEncryption == "" {
+		return ret
+	}
+
+	ret = append(ret, tasks.Arg{
+		Type:  "string",
+		Value: jsonTaskArg(features),
+	})
+	if plan == nil && deployKey == "" && path == "" && workDirEncryption == "" {
+		return ret
+	}
+
+	ret = append(ret, tasks.Arg{
+		Type:  "string",
+		Value: jsonTaskArg(plan),
+	})
+	if deployKey == "" && path == "" && workDirEncryption == "" {
+		return ret
+	}
+
+	ret = append(ret, tasks.Arg{
+		Type:  "string",
+		Value: deployKey,
+	})
+	if path == "" && workDirEncryption == "" {
+		return ret
+	}
+
+	ret = append(ret, tasks.Arg{
+		Type:  "string",
+		Value: path,
+	})
+	if workDirEncryption == "" {
+		return ret
+	}
+
+	return append(ret, tasks.Arg{
+		Type:  "string",
+		Value: workDirEncryption,
+	})
+}
+
+// OptionalTaskArgsNow returns trailing args for direct calls of consumers
+func OptionalTaskArgsNow(features map[string]bool, plan *usage.Plan, deployKey, path, workDirEncryption string) []interface{} {
+	var ret []interface{}
+	for _, arg := range BuildOptionalTaskArgs(features, plan, deployKey, path, workDirEncryption) {
+		ret = append(ret, arg.Value)
+	}
+	return ret
+}
+
+// jsonTaskArg returns an empty string for nil values
+func jsonTaskArg(v interface{}) string {
+	if reflect.ValueOf(v).IsNil() {
+		return ""
+	}
+
+	ret, _ := json.Marshal(v) // maps of bools and plans can't fail
+	return string(ret)
+}
+
+func parseJSONTaskArg(arg interface{}, name string, dest interface{}) error {
+	s, ok := arg.(string)
+	if !ok {
+		return fmt.Errorf("invalid type %T of %s task arg", arg, name)
+	}
+	if s == "" {
+		return nil
+	}
+
+	if err := json.Unmarshal([]byte(s), dest); err != nil {
+		return fmt.Errorf("invalid %s task arg %q: %s", name, s, err)
+	}
+
+	return nil
+}
+
+// ParseOptionalTaskArgs parses trailing task args, an error is returned for invalid args:
+// args before the invalid one are parsed
+func ParseOptionalTaskArgs(args []interface{}) (*OptionalTaskArgs, error) {
+	ret := &OptionalTaskArgs{}
+	if len(args) == 0 {
+		return ret, nil
+	}
+
+	enqueuedAtMs, ok := args[0].(int64)
+	if !ok {
+		return ret, fmt.Errorf("invalid type %T of enqueue time task arg", args[0])
+	}
+	ret.EnqueuedAt = EnqueuedAtFromTaskArg([]int64{enqueuedAtMs})
+	if len(args) == 1 {
+		return ret, nil
+	}
+
+	if err := parseJSONTaskArg(args[1], "features", &ret.Features); err != nil {
+		return ret, err
+	}
+	if len(args) == 2 {
+		return ret, nil
+	}
+
+	if err := parseJSONTaskArg(args[2], "plan", &ret.Plan); err != nil {
+		return ret, err
+	}
+	if len(args) == 3 {
+		return ret, nil
+	}
+
+	deployKey, ok := args[3].(string)
+	if !ok {
+		return ret, fmt.Errorf("invalid type %T of deploy key task arg", args[3])
+	}
+	ret.DeployKey = deployKey
+	if len(args) == 4 {
+		return ret, nil
+	}
+
+	path, ok := args[4].(string)
+	if !ok {
+		return ret, fmt.Errorf("invalid type %T of path task arg", args[4])
+	}
+	ret.Path = path
+	if len(args) == 5 {
+		return ret, nil
+	}
+
+	workDirEncryption, ok := args[5].(string)
+	if !ok {
+		return ret, fmt.Errorf("invalid type %T of work dir encryption task arg", args[5])
+	}
+	ret.WorkDirEncryption = workDirEncryption
+
+	return ret, nil
+}
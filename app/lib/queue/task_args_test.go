@@ -0,0 +1,57 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/lib/usage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOptionalTaskArgs(t *testing.T) {
+	args, err := ParseOptionalTaskArgs(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &OptionalTaskArgs{}, args)
+
+	now := TaskArgNow()
+	args, err = ParseOptionalTaskArgs([]interface{}{now}) // old producer
+	assert.NoError(t, err)
+	assert.Equal(t, now, args.EnqueuedAt.UnixNano()/1e6)
+	assert.Nil(t, args.Features)
+
+	features := map[string]bool{"new_pr_prepare": true, "use_container_executor": false}
+	args, err = ParseOptionalTaskArgs(OptionalTaskArgsNow(features, nil, "", "", ""))
+	assert.NoError(t, err)
+	assert.Equal(t, features, args.Features)
+	assert.False(t, args.EnqueuedAt.IsZero())
+
+	plan := &usage.Plan{UsedSeconds: 10, HardCapSeconds: 5}
+	args, err = ParseOptionalTaskArgs(OptionalTaskArgsNow(nil, plan, "", "", ""))
+	assert.NoError(t, err)
+	assert.Nil(t, args.Features)
+	assert.Equal(t, plan, args.Plan)
+	assert.Empty(t, args.DeployKey)
+
+	args, err = ParseOptionalTaskArgs(OptionalTaskArgsNow(nil, nil, "deploykey:v1:sealed", "", ""))
+	assert.NoError(t, err)
+	assert.Nil(t, args.Plan)
+	assert.Equal(t, "deploykey:v1:sealed", args.DeployKey)
+	assert.Empty(t, args.Path)
+
+	args, err = ParseOptionalTaskArgs(OptionalTaskArgsNow(nil, nil, "", "backend", ""))
+	assert.NoError(t, err)
+	assert.Empty(t, args.DeployKey)
+	assert.Equal(t, "backend", args.Path)
+	assert.Empty(t, args.WorkDirEncryption)
+
+	args, err = ParseOptionalTaskArgs(OptionalTaskArgsNow(nil, nil, "", "", "luks"))
+	assert.NoError(t, err)
+	assert.Empty(t, args.Path)
+	assert.Equal(t, "luks", args.WorkDirEncryption)
+
+	args, err = ParseOptionalTaskArgs([]interface{}{now, "{"})
+	assert.Error(t, err)
+	assert.False(t, args.EnqueuedAt.IsZero())
+
+	_, err = ParseOptionalTaskArgs([]interface{}{"x"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package selfcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+type Result struct {
+	Name   string
+	Output string
+	Err    error
+}
+
+func Run(ctx context.Context, checks []Check) []Result {
+	var ret []Result
+	for _, c := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		out, err := c.Run(checkCtx)
+		cancel()
+
+		ret = append(ret, Result{
+			Name:   c.Name,
+			Output: out,
+			Err:    err,
+		})
+	}
+
+	return ret
+}
+
+// PrintReport prints results and returns true if all checks passed
+func PrintReport(w io.Writer, results []Result) bool {
+	ok := true
+	for _, r := range results {
+		if r.Err != nil {
+			ok = false
+			fmt.Fprintf(w, "[FAIL] %s: %s\n", r.Name, r.Err)
+			continue
+		}
+
+		if r.Output == "" {
+			fmt.Fprintf(w, "[ OK ] %s\n", r.Name)
+		} else {
+			fmt.Fprintf(w, "[ OK ] %s: %s\n", r.Name, r.Output)
+		}
+	}
+
+	return ok
+}
@@ -0,0 +1,60 @@
+package selfcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	var deadlines []bool
+	record := func(out string, err error) func(ctx context.Context) (string, error) {
+		return func(ctx context.Context) (string, error) {
+			_, ok := ctx.Deadline()
+			deadlines = append(deadlines, ok)
+			return out, err
+		}
+	}
+
+	checkErr := errors.New("broken")
+	results := Run(context.Background(), []Check{
+		{Name: "first", Run: record("v1", nil)},
+		{Name: "second", Run: record("", checkErr)},
+		{Name: "third", Run: record("", nil)}, // failures don't stop next checks
+	})
+	assert.Equal(t, []Result{
+		{Name: "first", Output: "v1"},
+		{Name: "second", Err: checkErr},
+		{Name: "third"},
+	}, results)
+	assert.Equal(t, []bool{true, true, true}, deadlines, "every check must be limited by time")
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := Run(ctx, []Check{{Name: "slow", Run: func(ctx context.Context) (string, error) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(time.Minute):
+			return "", nil
+		}
+	}}})
+	assert.Equal(t, []Result{{Name: "slow", Err: context.Canceled}}, results)
+}
+
+func TestPrintReport(t *testing.T) {
+	var b bytes.Buffer
+	assert.True(t, PrintReport(&b, []Result{{Name: "env vars"}, {Name: "git binary", Output: "git version 2.19.1"}}))
+	assert.Equal(t, "[ OK ] env vars\n[ OK ] git binary: git version 2.19.1\n", b.String())
+
+	b.Reset()
+	assert.False(t, PrintReport(&b, []Result{{Name: "broker", Err: errors.New("failed to ping redis")}, {Name: "env vars"}}))
+	assert.Equal(t, "[FAIL] broker: failed to ping redis\n[ OK ] env vars\n", b.String())
+}
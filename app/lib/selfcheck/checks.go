@@ -0,0 +1,160 @@
+package selfcheck
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/golangci/golangci-worker/app/lib/executors"
+	"github.com/golangci/golangci-worker/app/lib/fetchers"
+	"github.com/golangci/golangci-worker/app/lib/runmode"
+	_ "github.com/lib/pq" // postgres driver of the postgres queue
+	"github.com/pkg/errors"
+)
+
+const defaultCloneCheckRepo = "https://github.com/golangci/golangci-worker.git"
+
+func DefaultChecks() []Check {
+	return []Check{
+		{Name: "env vars", Run: checkEnv},
+		{Name: "executor config", Run: checkExecutorConfig},
+		{Name: "broker", Run: checkBroker},
+		{Name: "git binary", Run: binaryVersionCheck("git", "--version")},
+		{Name: "golangci-lint binary", Run: binaryVersionCheck("golangci-lint", "--version")},
+		{Name: "clone of public repo", Run: checkClone},
+	}
+}
+
+func checkEnv(_ context.Context) (string, error) {
+	required := []string{"API_URL", "WEB_ROOT"}
+	if os.Getenv("QUEUE_DATABASE_URL") == "" {
+		required = append(required, "REDIS_URL")
+	}
+	if runmode.IsProduction() {
+		required = append(required, "AMPLITUDE_API_KEY", "MIXPANEL_API_KEY")
+	}
+
+	var missing []string
+	for _, k := range required {
+		if os.Getenv(k) == "" {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) != 0 {
+		return "", fmt.Errorf("missing env vars: %s", strings.Join(missing, ", "))
+	}
+
+	for _, k := range []string{"API_URL", "WEB_ROOT"} {
+		u, err := url.Parse(os.Getenv(k))
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid url in %s", k)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return "", fmt.Errorf("%s must be an absolute url, got %q", k, os.Getenv(k))
+		}
+		if strings.HasSuffix(u.Path, "/") {
+			return "", fmt.Errorf("%s shouldn't end with /", k)
+		}
+	}
+
+	return "", nil
+}
+
+func checkExecutorConfig(_ context.Context) (string, error) {
+	if os.Getenv("ORCHESTRATOR_ADDR") != "" {
+		if os.Getenv("ORCHESTRATOR_TOKEN") == "" {
+			return "", errors.New("ORCHESTRATOR_ADDR is set without ORCHESTRATOR_TOKEN")
+		}
+		return "container executor is configured", nil
+	}
+
+	for _, k := range []string{"REMOTE_SHELL_USER", "REMOTE_SHELL_HOST", "REMOTE_SHELL_KEY_FILE_PATH"} {
+		if os.Getenv(k) == "" {
+			return "", fmt.Errorf("neither container executor nor remote shell executor (%s) are configured", k)
+		}
+	}
+
+	keyPath := os.Getenv("REMOTE_SHELL_KEY_FILE_PATH")
+	if _, err := os.Stat(keyPath); err != nil {
+		return "", errors.Wrapf(err, "invalid REMOTE_SHELL_KEY_FILE_PATH")
+	}
+
+	return "remote shell executor is configured", nil
+}
+
+func checkBroker(ctx context.Context) (string, error) {
+	if pgURL := os.Getenv("QUEUE_DATABASE_URL"); pgURL != "" {
+		return checkPostgresBroker(ctx, pgURL)
+	}
+
+	conn, err := redis.DialURL(os.Getenv("REDIS_URL"))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to connect to redis")
+	}
+	defer conn.Close()
+
+	if _, err = conn.Do("PING"); err != nil {
+		return "", errors.Wrap(err, "failed to ping redis")
+	}
+
+	return "", nil
+}
+
+func checkPostgresBroker(ctx context.Context, pgURL string) (string, error) {
+	db, err := sql.Open("postgres", pgURL)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open postgres")
+	}
+	defer db.Close()
+
+	if err = db.PingContext(ctx); err != nil {
+		return "", errors.Wrap(err, "failed to ping postgres")
+	}
+
+	return "postgres queue", nil
+}
+
+func binaryVersionCheck(name string, args ...string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to run %s %s: %s", name, strings.Join(args, " "), out)
+		}
+
+		version := strings.TrimSpace(string(out))
+		expEnvKey := strings.ToUpper(strings.Replace(name, "-", "_", -1)) + "_VERSION"
+		if exp := os.Getenv(expEnvKey); exp != "" && !strings.Contains(version, exp) {
+			return "", fmt.Errorf("expected version %s (%s), got %q", exp, expEnvKey, version)
+		}
+
+		return version, nil
+	}
+}
+
+func checkClone(ctx context.Context) (string, error) {
+	cloneURL := os.Getenv("SELF_CHECK_REPO")
+	if cloneURL == "" {
+		cloneURL = defaultCloneCheckRepo
+	}
+
+	exec, err := executors.NewTempDirShell("selfcheck")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to make temp dir shell")
+	}
+	defer exec.Clean()
+
+	repo := &fetchers.Repo{
+		CloneURL: cloneURL,
+		Ref:      "master",
+	}
+	if err = fetchers.NewGit().Fetch(ctx, repo, exec); err != nil {
+		return "", errors.Wrapf(err, "failed to clone %s", cloneURL)
+	}
+
+	return cloneURL, nil
+}
@@ -0,0 +1,129 @@
+package selfcheck
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setEnv sets env vars (empty values unset them), the returned func restores them
+func setEnv(vars map[string]string) func() {
+	prev := map[string]string{}
+	for k, v := range vars {
+		prev[k] = os.Getenv(k)
+		if v == "" {
+			os.Unsetenv(k)
+		} else {
+			os.Setenv(k, v)
+		}
+	}
+
+	return func() {
+		for k, v := range prev {
+			os.Setenv(k, v)
+		}
+	}
+}
+
+func TestCheckEnv(t *testing.T) {
+	valid := map[string]string{
+		"API_URL":            "https://api.golangci.com",
+		"WEB_ROOT":           "https://golangci.com",
+		"REDIS_URL":          "redis://localhost:6379",
+		"QUEUE_DATABASE_URL": "",
+		"GO_ENV":             "",
+		"AMPLITUDE_API_KEY":  "",
+		"MIXPANEL_API_KEY":   "",
+	}
+	defer setEnv(valid)()
+
+	_, err := checkEnv(context.Background())
+	assert.NoError(t, err)
+
+	for k, v := range map[string]string{
+		"REDIS_URL": "",                      // required without the postgres queue
+		"GO_ENV":    "prod",                  // analytics keys are required in production
+		"API_URL":   "api.golangci.com",      // not absolute
+		"WEB_ROOT":  "https://golangci.com/", // trailing slash
+	} {
+		restore := setEnv(map[string]string{k: v})
+		_, err = checkEnv(context.Background())
+		assert.Error(t, err, k)
+		restore()
+	}
+
+	defer setEnv(map[string]string{"REDIS_URL": "", "QUEUE_DATABASE_URL": "postgres://localhost/queue"})()
+	_, err = checkEnv(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestCheckExecutorConfig(t *testing.T) {
+	keyFile, err := ioutil.TempFile("", "selfcheck-key")
+	if !assert.NoError(t, err) {
+		return
+	}
+	keyFile.Close()
+	defer os.Remove(keyFile.Name())
+
+	defer setEnv(map[string]string{
+		"ORCHESTRATOR_ADDR":          "",
+		"ORCHESTRATOR_TOKEN":         "",
+		"REMOTE_SHELL_USER":          "worker",
+		"REMOTE_SHELL_HOST":          "executor",
+		"REMOTE_SHELL_KEY_FILE_PATH": keyFile.Name(),
+	})()
+
+	out, err := checkExecutorConfig(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "remote shell executor is configured", out)
+
+	restore := setEnv(map[string]string{"REMOTE_SHELL_KEY_FILE_PATH": keyFile.Name() + ".missing"})
+	_, err = checkExecutorConfig(context.Background())
+	assert.Error(t, err)
+	restore()
+
+	restore = setEnv(map[string]string{"REMOTE_SHELL_HOST": ""})
+	_, err = checkExecutorConfig(context.Background())
+	assert.Error(t, err)
+	restore()
+
+	// the container executor takes precedence
+	defer setEnv(map[string]string{"ORCHESTRATOR_ADDR": "orchestrator:8080"})()
+	_, err = checkExecutorConfig(context.Background())
+	assert.Error(t, err, "the token is required")
+
+	defer setEnv(map[string]string{"ORCHESTRATOR_TOKEN": "token"})()
+	out, err = checkExecutorConfig(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "container executor is configured", out)
+}
+
+func TestBinaryVersionCheck(t *testing.T) {
+	defer setEnv(map[string]string{"ECHO_VERSION": ""})()
+
+	check := binaryVersionCheck("echo", "golangci-lint has version 1.12.2")
+	out, err := check(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "golangci-lint has version 1.12.2", out)
+
+	defer setEnv(map[string]string{"ECHO_VERSION": "1.12.2"})()
+	_, err = check(context.Background())
+	assert.NoError(t, err)
+
+	defer setEnv(map[string]string{"ECHO_VERSION": "1.13.0"})()
+	_, err = check(context.Background())
+	assert.Error(t, err, "unexpected version")
+
+	_, err = binaryVersionCheck("golangci-selfcheck-missing-binary", "--version")(context.Background())
+	assert.Error(t, err)
+}
+
+func TestCheckBrokerFailure(t *testing.T) {
+	defer setEnv(map[string]string{"QUEUE_DATABASE_URL": "", "REDIS_URL": "redis://127.0.0.1:1"})()
+
+	_, err := checkBroker(context.Background())
+	assert.Error(t, err, "nothing listens on the port")
+}
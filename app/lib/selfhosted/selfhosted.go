@@ -0,0 +1,96 @@
+package selfhosted
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golangci/golangci-worker/app/lib/apiclient"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/pkg/errors"
+)
+
+// Registration describes a self-hosted worker for the API
+type Registration struct {
+	Name         string
+	Version      string
+	Capabilities []string
+}
+
+// Credentials are scoped to the organization of the self-hosted worker
+type Credentials struct {
+	WorkerID string
+	Token    string
+	Org      string
+
+	// Queue is the queue with tasks of the organization: the worker never consumes the shared queue,
+	// it would receive tasks and tokens of other organizations
+	Queue string
+}
+
+var active *Credentials
+var activeLock sync.RWMutex
+
+// IsEnabled returns true if the worker is run by a customer: SELF_HOSTED=1
+func IsEnabled() bool {
+	return os.Getenv("SELF_HOSTED") == "1"
+}
+
+// Register exchanges the registration token (SELF_HOSTED_REGISTRATION_TOKEN)
+// for credentials of the worker
+func Register(ctx context.Context, client httputils.Client, reg *Registration) (*Credentials, error) {
+	var creds Credentials
+	if err := apiclient.New(client).RegisterWorker(ctx, reg, &creds); err != nil {
+		return nil, errors.Wrap(err, "failed to register worker")
+	}
+
+	if creds.Token == "" || creds.Org == "" || creds.Queue == "" {
+		return nil, fmt.Errorf("got incomplete credentials for worker %s: token, org or queue is empty", creds.WorkerID)
+	}
+
+	return &creds, nil
+}
+
+// RegisterFromEnv registers the worker with the registration token from env
+func RegisterFromEnv(ctx context.Context, reg *Registration) (*Credentials, error) {
+	token := os.Getenv("SELF_HOSTED_REGISTRATION_TOKEN")
+	if token == "" {
+		return nil, errors.New("no SELF_HOSTED_REGISTRATION_TOKEN")
+	}
+
+	if reg.Name == "" {
+		reg.Name = os.Getenv("WORKER_NAME")
+	}
+	if reg.Name == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, errors.Wrap(err, "can't get hostname for worker name, set WORKER_NAME")
+		}
+		reg.Name = hostname
+	}
+
+	return Register(ctx, httputils.GrequestsClient{AuthToken: token}, reg)
+}
+
+// Activate makes all requests use the credentials and restricts processed tasks to the organization
+func Activate(creds *Credentials) {
+	activeLock.Lock()
+	defer activeLock.Unlock()
+
+	active = creds
+	httputils.SetAuthToken(creds.Token)
+}
+
+// IsOwnerAllowed returns false for tasks of other organizations than the self-hosted worker's one
+func IsOwnerAllowed(owner string) bool {
+	activeLock.RLock()
+	defer activeLock.RUnlock()
+
+	if active == nil { // not self-hosted
+		return true
+	}
+
+	return strings.EqualFold(active.Org, owner)
+}
@@ -0,0 +1,65 @@
+package selfhosted
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reg := &Registration{Name: "w1", Version: "v1", Capabilities: []string{"analyzeV2"}}
+	resp := `{"WorkerID": "id", "Token": "t", "Org": "golangci", "Queue": "org_golangci"}`
+
+	client := httputils.NewMockClient(ctrl)
+	client.EXPECT().PostWithResponse(gomock.Any(), gomock.Any(), reg).
+		Return(ioutil.NopCloser(strings.NewReader(resp)), nil)
+
+	creds, err := Register(context.Background(), client, reg)
+	assert.NoError(t, err)
+	assert.Equal(t, &Credentials{WorkerID: "id", Token: "t", Org: "golangci", Queue: "org_golangci"}, creds)
+}
+
+func TestRegisterIncompleteCredentials(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := httputils.NewMockClient(ctrl)
+	client.EXPECT().PostWithResponse(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(ioutil.NopCloser(strings.NewReader(`{"WorkerID": "id"}`)), nil)
+
+	_, err := Register(context.Background(), client, &Registration{})
+	assert.Error(t, err)
+}
+
+func TestRegisterWithoutOrgQueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := httputils.NewMockClient(ctrl)
+	client.EXPECT().PostWithResponse(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(ioutil.NopCloser(strings.NewReader(`{"WorkerID": "id", "Token": "t", "Org": "golangci"}`)), nil)
+
+	_, err := Register(context.Background(), client, &Registration{})
+	assert.Error(t, err, "the shared queue must not be consumed")
+}
+
+func TestIsOwnerAllowed(t *testing.T) {
+	assert.True(t, IsOwnerAllowed("anyone"))
+
+	Activate(&Credentials{Token: "t", Org: "golangci"})
+	defer func() {
+		active = nil
+		httputils.SetAuthToken("")
+	}()
+
+	assert.True(t, IsOwnerAllowed("GolangCI"))
+	assert.False(t, IsOwnerAllowed("other"))
+}
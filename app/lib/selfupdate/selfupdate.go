@@ -0,0 +1,140 @@
+// Package selfupdate coordinates deploys of workers with the API: the API signals the min required
+// version of workers, outdated workers stop consuming tasks and exit to be restarted on the new image.
+package selfupdate
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/lib/apiclient"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+)
+
+// ExitCodeOutdated is an exit code of outdated workers: supervisors restart them on the new image
+// instead of treating the exit as a crash
+const ExitCodeOutdated = 75
+
+// Requirement is a version handshake of the API
+type Requirement struct {
+	// MinWorkerVersion is a tag (e.g. v1.5.0), workers of older versions must exit; empty means any version
+	MinWorkerVersion string
+}
+
+// Fetcher fetches the current requirement of the API
+type Fetcher interface {
+	Fetch(ctx context.Context) (*Requirement, error)
+}
+
+type APIFetcher struct {
+	api *apiclient.Client
+}
+
+func NewAPIFetcher(client httputils.Client) *APIFetcher {
+	return &APIFetcher{
+		api: apiclient.New(client),
+	}
+}
+
+func (f APIFetcher) Fetch(ctx context.Context) (*Requirement, error) {
+	var req Requirement
+	if err := f.api.GetWorkerVersion(ctx, &req); err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// parseVersion parses vX.Y.Z prefix of versions made by git describe (e.g. v1.4.2-3-gabc1234),
+// missing minor and patch versions are zeros
+func parseVersion(s string) ([3]int, bool) {
+	var ret [3]int
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.IndexAny(s, "-+"); i != -1 {
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > len(ret) {
+		return ret, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return ret, false
+		}
+		ret[i] = n
+	}
+
+	return ret, true
+}
+
+// IsOutdated returns true if the current version is older than the min one: dev builds and
+// builds without tags are never outdated
+func IsOutdated(current, min string) bool {
+	if min == "" {
+		return false
+	}
+
+	cur, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	minVer, ok := parseVersion(min)
+	if !ok {
+		return false
+	}
+
+	for i := range cur {
+		if cur[i] != minVer[i] {
+			return cur[i] < minVer[i]
+		}
+	}
+
+	return false
+}
+
+// Watcher periodically checks whether the worker is outdated
+type Watcher struct {
+	Fetcher  Fetcher
+	Current  string
+	Interval time.Duration
+	Log      logutil.Log
+}
+
+// Check fetches the requirement: failures of the API don't stop workers
+func (w Watcher) Check(ctx context.Context) bool {
+	req, err := w.Fetcher.Fetch(ctx)
+	if err != nil {
+		w.Log.Warnf("Can't fetch the required worker version: %s", err)
+		return false
+	}
+
+	if !IsOutdated(w.Current, req.MinWorkerVersion) {
+		return false
+	}
+
+	w.Log.Warnf("Worker version %s is older than the required %s", w.Current, req.MinWorkerVersion)
+	return true
+}
+
+// Run calls onOutdated once the worker is outdated, it returns after that or on the context cancellation
+func (w Watcher) Run(ctx context.Context, onOutdated func()) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if w.Check(ctx) {
+			onOutdated()
+			return
+		}
+	}
+}
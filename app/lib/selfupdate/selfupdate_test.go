@@ -0,0 +1,75 @@
+package selfupdate
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-shared/pkg/logutil"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsOutdated(t *testing.T) {
+	assert.True(t, IsOutdated("v1.4.2", "v1.5.0"))
+	assert.True(t, IsOutdated("v1.4.2-3-gabc1234", "v1.4.3"))
+	assert.True(t, IsOutdated("v1", "v1.0.1"))
+	assert.False(t, IsOutdated("v1.5.0", "v1.5.0"))
+	assert.False(t, IsOutdated("v1.10.0", "v1.9.0"))
+	assert.False(t, IsOutdated("v1.4.2", ""))
+	assert.False(t, IsOutdated("dev", "v1.5.0"), "dev builds are never outdated")
+	assert.False(t, IsOutdated("abc1234", "v1.5.0"), "builds without tags are never outdated")
+	assert.False(t, IsOutdated("v1.4.2", "latest"))
+}
+
+func TestAPIFetcher(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := httputils.NewMockClient(ctrl)
+	client.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(ioutil.NopCloser(strings.NewReader(`{"MinWorkerVersion": "v1.5.0"}`)), nil)
+
+	req, err := NewAPIFetcher(client).Fetch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &Requirement{MinWorkerVersion: "v1.5.0"}, req)
+}
+
+type fakeFetcher struct {
+	reqs []*Requirement
+}
+
+func (f *fakeFetcher) Fetch(ctx context.Context) (*Requirement, error) {
+	if len(f.reqs) == 0 {
+		return nil, errors.New("no more requirements")
+	}
+
+	req := f.reqs[0]
+	f.reqs = f.reqs[1:]
+	if req == nil {
+		return nil, errors.New("api is down")
+	}
+	return req, nil
+}
+
+func TestWatcher(t *testing.T) {
+	f := &fakeFetcher{reqs: []*Requirement{{}, nil, {MinWorkerVersion: "v1.0.0"}, {MinWorkerVersion: "v2.0.0"}}}
+	w := Watcher{Fetcher: f, Current: "v1.2.0", Interval: time.Millisecond, Log: logutil.NewStderrLog("test")}
+
+	calls := 0
+	w.Run(context.Background(), func() { calls++ })
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, f.reqs, "api failures don't stop the worker")
+}
+
+func TestWatcherCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w := Watcher{Fetcher: &fakeFetcher{}, Current: "v1.2.0", Interval: time.Hour, Log: logutil.NewStderrLog("test")}
+	w.Run(ctx, func() { t.Fatal("outdated") })
+}
@@ -0,0 +1,199 @@
+// Package shadow runs a candidate implementation of a stage in the shadow of the stable one:
+// only the output of the stable implementation is used, the candidate output is compared with it.
+// It makes rollouts of refactors (new workspace installer, new runner) safe.
+package shadow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"time"
+)
+
+// Func is an implementation of a stage, its output must be json-encodable to be compared
+type Func func(ctx context.Context) (interface{}, error)
+
+// Report is recorded for offline comparison of implementations
+type Report struct {
+	Name string
+
+	StableDuration    time.Duration
+	CandidateDuration time.Duration
+
+	// CandidateError is set if the candidate failed, diffs aren't computed then
+	CandidateError string `json:",omitempty"`
+
+	Diffs []Diff `json:",omitempty"`
+}
+
+func (r Report) HasDiffs() bool {
+	return r.CandidateError != "" || len(r.Diffs) != 0
+}
+
+// Diff is a difference of outputs by a json path, e.g. Env.GOPATH
+type Diff struct {
+	Path string
+
+	// Stable and Candidate are set for differing values, nil means no value
+	Stable    interface{} `json:",omitempty"`
+	Candidate interface{} `json:",omitempty"`
+
+	// Added and Removed are set for arrays: they are compared as multisets
+	Added   []interface{} `json:",omitempty"`
+	Removed []interface{} `json:",omitempty"`
+}
+
+// Run runs the stable implementation and then the candidate one if the stable one succeeded.
+// Failures and panics of the candidate never affect the returned output and error.
+func Run(ctx context.Context, name string, stable, candidate Func) (interface{}, *Report, error) {
+	startedAt := time.Now()
+	stableOut, err := stable(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &Report{
+		Name:           name,
+		StableDuration: time.Since(startedAt),
+	}
+
+	startedAt = time.Now()
+	candidateOut, err := runSafe(ctx, candidate)
+	report.CandidateDuration = time.Since(startedAt)
+	if err != nil {
+		report.CandidateError = err.Error()
+		return stableOut, report, nil
+	}
+
+	report.Diffs, err = Compare(stableOut, candidateOut)
+	if err != nil {
+		report.CandidateError = fmt.Sprintf("can't compare outputs: %s", err)
+	}
+	return stableOut, report, nil
+}
+
+func runSafe(ctx context.Context, f Func) (out interface{}, err error) {
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = fmt.Errorf("panic: %s, %s", rerr, debug.Stack())
+		}
+	}()
+
+	return f(ctx)
+}
+
+func normalize(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret interface{}
+	if err = json.Unmarshal(data, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Compare returns structured diffs of json representations of outputs
+func Compare(stable, candidate interface{}) ([]Diff, error) {
+	s, err := normalize(stable)
+	if err != nil {
+		return nil, err
+	}
+	c, err := normalize(candidate)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []Diff
+	compare("", s, c, &ret)
+	return ret, nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func compare(path string, s, c interface{}, diffs *[]Diff) {
+	switch sv := s.(type) {
+	case map[string]interface{}:
+		cv, ok := c.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := map[string]bool{}
+		for k := range sv {
+			keys[k] = true
+		}
+		for k := range cv {
+			keys[k] = true
+		}
+		sortedKeys := make([]string, 0, len(keys))
+		for k := range keys {
+			sortedKeys = append(sortedKeys, k)
+		}
+		sort.Strings(sortedKeys)
+
+		for _, k := range sortedKeys {
+			compare(joinPath(path, k), sv[k], cv[k], diffs)
+		}
+		return
+	case []interface{}:
+		cv, ok := c.([]interface{})
+		if !ok && c != nil {
+			break
+		}
+
+		added, removed := diffMultisets(sv, cv)
+		if len(added) != 0 || len(removed) != 0 {
+			*diffs = append(*diffs, Diff{Path: path, Added: added, Removed: removed})
+		}
+		return
+	}
+
+	if sj, cj := mustEncode(s), mustEncode(c); sj != cj {
+		*diffs = append(*diffs, Diff{Path: path, Stable: s, Candidate: c})
+	}
+}
+
+func mustEncode(v interface{}) string {
+	data, _ := json.Marshal(v) // v is decoded json: it can't fail
+	return string(data)
+}
+
+func diffMultisets(stable, candidate []interface{}) (added, removed []interface{}) {
+	count := map[string]int{}
+	for _, v := range stable {
+		count[mustEncode(v)]++
+	}
+	for _, v := range candidate {
+		k := mustEncode(v)
+		if count[k] > 0 {
+			count[k]--
+			continue
+		}
+		added = append(added, v)
+	}
+
+	candidateCount := map[string]int{}
+	for _, v := range candidate {
+		candidateCount[mustEncode(v)]++
+	}
+	for _, v := range stable {
+		k := mustEncode(v)
+		if candidateCount[k] > 0 {
+			candidateCount[k]--
+			continue
+		}
+		removed = append(removed, v)
+	}
+
+	return added, removed
+}
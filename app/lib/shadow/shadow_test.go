@@ -0,0 +1,54 @@
+package shadow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type output struct {
+	Issues []string
+	Env    map[string]string
+	Files  int
+}
+
+func TestCompare(t *testing.T) {
+	stable := output{Issues: []string{"a", "b", "b"}, Env: map[string]string{"GOPATH": "/go", "GOOS": "linux"}, Files: 3}
+	candidate := output{Issues: []string{"b", "c"}, Env: map[string]string{"GOPATH": "/gopath", "GOOS": "linux", "GOFLAGS": "-mod=vendor"}, Files: 3}
+
+	diffs, err := Compare(stable, candidate)
+	assert.NoError(t, err)
+	assert.Equal(t, []Diff{
+		{Path: "Env.GOFLAGS", Candidate: "-mod=vendor"},
+		{Path: "Env.GOPATH", Stable: "/go", Candidate: "/gopath"},
+		{Path: "Issues", Added: []interface{}{"c"}, Removed: []interface{}{"a", "b"}},
+	}, diffs)
+
+	diffs, err = Compare(stable, stable)
+	assert.NoError(t, err)
+	assert.Empty(t, diffs)
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	stable := func(context.Context) (interface{}, error) { return output{Files: 1}, nil }
+
+	out, report, err := Run(ctx, "prepare", stable, func(context.Context) (interface{}, error) {
+		panic("bug")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, output{Files: 1}, out)
+	assert.Contains(t, report.CandidateError, "panic: bug")
+	assert.True(t, report.HasDiffs())
+
+	_, report, err = Run(ctx, "prepare", stable, stable)
+	assert.NoError(t, err)
+	assert.False(t, report.HasDiffs())
+
+	stableErr := errors.New("failed")
+	_, report, err = Run(ctx, "prepare", func(context.Context) (interface{}, error) { return nil, stableErr }, stable)
+	assert.Equal(t, stableErr, err)
+	assert.Nil(t, report)
+}
@@ -0,0 +1,118 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TimestampHeader = "X-Golangci-Timestamp"
+	SignatureHeader = "X-Golangci-Signature"
+
+	signatureVersion = "v1"
+
+	// MaxClockSkew is the max allowed difference between signing and verification time:
+	// signed requests can't be replayed later
+	MaxClockSkew = 5 * time.Minute
+)
+
+var (
+	ErrNoSignature      = errors.New("no signature")
+	ErrExpiredSignature = errors.New("signature timestamp is out of the allowed window")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Signer signs requests of the worker to the API by HMAC of the shared secret keys.
+// During rotation of a key both the current and the previous keys are set: the request
+// is signed by both and the API accepts it regardless of which key it already has.
+type Signer struct {
+	keys []string
+	now  func() time.Time
+}
+
+func NewSigner(keys ...string) *Signer {
+	ret := &Signer{now: time.Now}
+	for _, k := range keys {
+		if k != "" {
+			ret.keys = append(ret.keys, k)
+		}
+	}
+
+	return ret
+}
+
+// NewSignerFromEnv uses API_SIGNING_KEY and API_SIGNING_KEY_PREVIOUS, it returns nil if no keys are set
+func NewSignerFromEnv() *Signer {
+	s := NewSigner(os.Getenv("API_SIGNING_KEY"), os.Getenv("API_SIGNING_KEY_PREVIOUS"))
+	if len(s.keys) == 0 {
+		return nil
+	}
+
+	return s
+}
+
+func buildPayload(method, path, timestamp string, body []byte) []byte {
+	return []byte(strings.Join([]string{method, path, timestamp, string(body)}, "\n"))
+}
+
+func sign(key string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	_, _ = mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Headers returns headers to add to the request: path must include the query
+func (s Signer) Headers(method, path string, body []byte) map[string]string {
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	payload := buildPayload(method, path, timestamp, body)
+
+	var sigs []string
+	for _, k := range s.keys {
+		sigs = append(sigs, fmt.Sprintf("%s=%s", signatureVersion, sign(k, payload)))
+	}
+
+	return map[string]string{
+		TimestampHeader: timestamp,
+		SignatureHeader: strings.Join(sigs, ","),
+	}
+}
+
+// Verify checks that at least one of signatures was made by one of keys of the signer
+func (s Signer) Verify(method, path string, body []byte, timestamp, signatures string) error {
+	if timestamp == "" || signatures == "" {
+		return ErrNoSignature
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid signature timestamp %q: %s", timestamp, err)
+	}
+
+	skew := s.now().Sub(time.Unix(ts, 0))
+	if skew > MaxClockSkew || skew < -MaxClockSkew {
+		return ErrExpiredSignature
+	}
+
+	payload := buildPayload(method, path, timestamp, body)
+	for _, sig := range strings.Split(signatures, ",") {
+		parts := strings.SplitN(sig, "=", 2)
+		if len(parts) != 2 || parts[0] != signatureVersion {
+			continue
+		}
+
+		for _, k := range s.keys {
+			if hmac.Equal([]byte(parts[1]), []byte(sign(k, payload))) {
+				return nil
+			}
+		}
+	}
+
+	return ErrInvalidSignature
+}
@@ -0,0 +1,35 @@
+package signing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"Status":"processed"}`)
+	worker := NewSigner("new", "old")
+	h := worker.Headers("PUT", "/v1/repos/a/b", body)
+
+	verify := func(api *Signer, method string) error {
+		return api.Verify(method, "/v1/repos/a/b", body, h[TimestampHeader], h[SignatureHeader])
+	}
+
+	// API can have any of rotated keys
+	assert.NoError(t, verify(NewSigner("old"), "PUT"))
+	assert.NoError(t, verify(NewSigner("new", "old"), "PUT"))
+	assert.Equal(t, ErrInvalidSignature, verify(NewSigner("other"), "PUT"))
+	assert.Equal(t, ErrInvalidSignature, verify(NewSigner("new"), "POST"))
+
+	assert.Equal(t, ErrNoSignature, NewSigner("new").Verify("PUT", "/", nil, "", ""))
+}
+
+func TestExpiredSignature(t *testing.T) {
+	worker := NewSigner("key")
+	worker.now = func() time.Time { return time.Now().Add(-MaxClockSkew - time.Minute) }
+	h := worker.Headers("GET", "/", nil)
+
+	err := NewSigner("key").Verify("GET", "/", nil, h[TimestampHeader], h[SignatureHeader])
+	assert.Equal(t, ErrExpiredSignature, err)
+}
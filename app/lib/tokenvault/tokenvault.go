@@ -0,0 +1,137 @@
+// Package tokenvault resolves references to GitHub tokens: tasks carry references instead of raw tokens,
+// tokens are leased from Vault for the time of the task and aren't stored by the broker.
+package tokenvault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/pkg/errors"
+)
+
+// refPrefix marks token references in the token arg of tasks: old producers send raw tokens there
+const refPrefix = "vault:"
+
+// Ref returns a reference to the token stored by the secret path, e.g. secret/github/tenants/42
+func Ref(path string) string {
+	return refPrefix + path
+}
+
+// IsRef returns true if the token arg of a task is a reference, not a raw token
+func IsRef(token string) bool {
+	return strings.HasPrefix(token, refPrefix)
+}
+
+// Lease of a token: it must be released after the task
+type Lease struct {
+	Token string
+
+	// ID is empty for tokens without leases, e.g. tokens stored in kv engines
+	ID string
+
+	Duration time.Duration
+}
+
+type Resolver interface {
+	Resolve(ctx context.Context, ref string) (*Lease, error)
+	Release(ctx context.Context, lease *Lease) error
+}
+
+// VaultResolver reads tokens from Vault by VAULT_ADDR and VAULT_TOKEN env vars
+type VaultResolver struct {
+	client httputils.Client
+	addr   string
+}
+
+func NewVaultResolver(client httputils.Client) *VaultResolver {
+	return &VaultResolver{
+		client: client,
+		addr:   strings.TrimSuffix(os.Getenv("VAULT_ADDR"), "/"),
+	}
+}
+
+// NewDefaultVaultResolver returns a resolver authenticated by VAULT_TOKEN
+func NewDefaultVaultResolver() *VaultResolver {
+	return NewVaultResolver(httputils.GrequestsClient{AuthToken: os.Getenv("VAULT_TOKEN")})
+}
+
+type secretResponse struct {
+	LeaseID       string `json:"lease_id"`
+	LeaseDuration int    `json:"lease_duration"`
+	Data          struct {
+		Token string `json:"token"`
+
+		// Data is set by the kv v2 engine: it nests secrets
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	} `json:"data"`
+}
+
+func secretPath(ref string) (string, error) {
+	if !IsRef(ref) {
+		return "", fmt.Errorf("%q isn't a token reference", ref)
+	}
+
+	path := strings.Trim(strings.TrimPrefix(ref, refPrefix), "/")
+	if path == "" || strings.Contains(path, "..") {
+		return "", fmt.Errorf("invalid secret path %q", path)
+	}
+
+	return path, nil
+}
+
+func (r VaultResolver) Resolve(ctx context.Context, ref string) (*Lease, error) {
+	if r.addr == "" {
+		return nil, errors.New("no VAULT_ADDR to resolve token references")
+	}
+
+	path, err := secretPath(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	bodyReader, err := r.client.Get(ctx, fmt.Sprintf("%s/v1/%s", r.addr, path))
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read secret %s", path)
+	}
+	defer bodyReader.Close()
+
+	var resp secretResponse
+	if err = json.NewDecoder(bodyReader).Decode(&resp); err != nil {
+		return nil, errors.Wrap(err, "can't read json body")
+	}
+
+	token := resp.Data.Token
+	if token == "" {
+		token = resp.Data.Data.Token
+	}
+	if token == "" {
+		return nil, fmt.Errorf("no token in secret %s", path)
+	}
+
+	return &Lease{
+		Token:    token,
+		ID:       resp.LeaseID,
+		Duration: time.Duration(resp.LeaseDuration) * time.Second,
+	}, nil
+}
+
+// Release revokes the lease: tokens of finished tasks become useless if they leak from the worker
+func (r VaultResolver) Release(ctx context.Context, lease *Lease) error {
+	if lease.ID == "" {
+		return nil
+	}
+
+	url := fmt.Sprintf("%s/v1/sys/leases/revoke", r.addr)
+	if err := r.client.Put(ctx, url, map[string]string{"lease_id": lease.ID}); err != nil {
+		return errors.Wrapf(err, "can't revoke lease %s", lease.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package tokenvault
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRef(t *testing.T) {
+	assert.True(t, IsRef(Ref("secret/github/tenants/42")))
+	assert.False(t, IsRef("0123456789abcdef"))
+	assert.False(t, IsRef(""))
+}
+
+func TestSecretPath(t *testing.T) {
+	path, err := secretPath(Ref("/secret/github/42/"))
+	assert.NoError(t, err)
+	assert.Equal(t, "secret/github/42", path)
+
+	_, err = secretPath(Ref("secret/../sys/policy"))
+	assert.Error(t, err)
+	_, err = secretPath(Ref(""))
+	assert.Error(t, err)
+	_, err = secretPath("token")
+	assert.Error(t, err)
+}
+
+func newTestResolver(client httputils.Client) *VaultResolver {
+	return &VaultResolver{client: client, addr: "https://vault"}
+}
+
+func TestResolveLeasedToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := httputils.NewMockClient(ctrl)
+	client.EXPECT().Get(gomock.Any(), "https://vault/v1/github/token/tenant-42").
+		Return(ioutil.NopCloser(strings.NewReader(
+			`{"lease_id": "github/token/tenant-42/abc", "lease_duration": 600, "data": {"token": "ghs_1"}}`)), nil)
+
+	lease, err := newTestResolver(client).Resolve(context.Background(), Ref("github/token/tenant-42"))
+	assert.NoError(t, err)
+	assert.Equal(t, &Lease{Token: "ghs_1", ID: "github/token/tenant-42/abc", Duration: 10 * time.Minute}, lease)
+}
+
+func TestResolveKVToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := httputils.NewMockClient(ctrl)
+	client.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(ioutil.NopCloser(strings.NewReader(`{"data": {"data": {"token": "ghs_2"}}}`)), nil)
+
+	lease, err := newTestResolver(client).Resolve(context.Background(), Ref("secret/data/42"))
+	assert.NoError(t, err)
+	assert.Equal(t, &Lease{Token: "ghs_2"}, lease)
+}
+
+func TestResolveNoToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := httputils.NewMockClient(ctrl)
+	client.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(ioutil.NopCloser(strings.NewReader(`{"data": {}}`)), nil)
+
+	_, err := newTestResolver(client).Resolve(context.Background(), Ref("secret/data/42"))
+	assert.Error(t, err)
+}
+
+func TestRelease(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := httputils.NewMockClient(ctrl)
+	client.EXPECT().Put(gomock.Any(), "https://vault/v1/sys/leases/revoke", map[string]string{"lease_id": "l1"}).Return(nil)
+
+	r := newTestResolver(client)
+	assert.NoError(t, r.Release(context.Background(), &Lease{Token: "ghs_1", ID: "l1"}))
+	assert.NoError(t, r.Release(context.Background(), &Lease{Token: "ghs_2"}), "no revocation without lease")
+}
@@ -0,0 +1,100 @@
+package usage
+
+import (
+	"context"
+
+	"github.com/golangci/golangci-worker/app/lib/apiclient"
+	"github.com/golangci/golangci-worker/app/lib/httputils"
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+)
+
+//go:generate mockgen -package usage -source usage.go -destination usage_mock.go
+
+// Plan is the compute budget of an organization in the billing period, it's passed in tasks by the API
+type Plan struct {
+	// UsedSeconds are compute seconds used by the organization before the analysis
+	UsedSeconds int
+
+	// SoftCapSeconds produces a public warning, HardCapSeconds limits analyses to changed packages.
+	// Zero means no cap.
+	SoftCapSeconds int `json:",omitempty"`
+	HardCapSeconds int `json:",omitempty"`
+
+	// MaxRepoSizeMB is a limit of repo size of the plan, zero means no limit
+	MaxRepoSizeMB int `json:",omitempty"`
+}
+
+func isExceeded(used, limit int) bool {
+	return limit > 0 && used >= limit
+}
+
+func (p *Plan) IsSoftCapExceeded() bool {
+	return p != nil && isExceeded(p.UsedSeconds, p.SoftCapSeconds)
+}
+
+func (p *Plan) IsHardCapExceeded() bool {
+	return p != nil && isExceeded(p.UsedSeconds, p.HardCapSeconds)
+}
+
+// Warning is a public text about the exceeded cap, it's empty if no cap is exceeded
+func (p *Plan) Warning(msg i18n.Printer) string {
+	switch {
+	case p.IsHardCapExceeded():
+		return msg.Sprintf(i18n.WarnBudgetHardCap, p.UsedSeconds/60, p.HardCapSeconds/60)
+	case p.IsSoftCapExceeded():
+		return msg.Sprintf(i18n.WarnBudgetSoftCap, p.UsedSeconds/60, p.SoftCapSeconds/60)
+	default:
+		return ""
+	}
+}
+
+type planKeyType string
+
+const planKey planKeyType = "usage plan"
+
+func ContextWithPlan(ctx context.Context, p *Plan) context.Context {
+	if p == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, planKey, p)
+}
+
+// PlanFromContext returns nil if the task has no plan: the organization isn't limited
+func PlanFromContext(ctx context.Context) *Plan {
+	p, _ := ctx.Value(planKey).(*Plan)
+	return p
+}
+
+// Record is compute usage of an analysis for usage-based billing
+type Record struct {
+	AnalysisGUID   string
+	Kind           string // pr or repo
+	ComputeSeconds int
+	Partial        bool // the hard cap was exceeded
+}
+
+type Reporter interface {
+	Report(ctx context.Context, owner, name string, r *Record) error
+}
+
+type APIReporter struct {
+	api *apiclient.Client
+}
+
+func NewAPIReporter(client httputils.Client) *APIReporter {
+	return &APIReporter{
+		api: apiclient.New(client),
+	}
+}
+
+func (r APIReporter) Report(ctx context.Context, owner, name string, rec *Record) error {
+	return r.api.ReportUsage(ctx, owner, name, rec.AnalysisGUID, rec)
+}
+
+// NopReporter doesn't report usage, e.g. of local analyses
+type NopReporter struct{}
+
+func (NopReporter) Report(ctx context.Context, owner, name string, rec *Record) error {
+	return nil
+}
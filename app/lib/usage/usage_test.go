@@ -0,0 +1,34 @@
+package usage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golangci/golangci-worker/app/lib/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanCaps(t *testing.T) {
+	var noPlan *Plan
+	assert.False(t, noPlan.IsHardCapExceeded())
+	assert.Empty(t, noPlan.Warning(i18n.Printer{}))
+
+	p := &Plan{UsedSeconds: 600, SoftCapSeconds: 300, HardCapSeconds: 1200}
+	assert.True(t, p.IsSoftCapExceeded())
+	assert.False(t, p.IsHardCapExceeded())
+	assert.Equal(t, "Organization used 10 compute minutes, it's more than 5 minutes of the plan", p.Warning(i18n.Printer{}))
+
+	p.UsedSeconds = 1200
+	assert.True(t, p.IsHardCapExceeded())
+	assert.Contains(t, p.Warning(i18n.Printer{}), "only changed packages were analyzed")
+	assert.Contains(t, p.Warning(i18n.NewPrinter("ru")), "проанализированы только изменённые пакеты")
+
+	assert.False(t, (&Plan{UsedSeconds: 1e6}).IsHardCapExceeded(), "zero cap means no cap")
+}
+
+func TestPlanContext(t *testing.T) {
+	assert.Nil(t, PlanFromContext(context.Background()))
+
+	p := &Plan{HardCapSeconds: 60}
+	assert.Equal(t, p, PlanFromContext(ContextWithPlan(context.Background(), p)))
+}